@@ -5,16 +5,13 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
-
-	"golang.org/x/sync/errgroup"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
-	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/user"
-	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -55,12 +52,17 @@ func (hs *HTTPServer) AdminCreateUser(c *models.ReqContext) response.Response {
 		}
 	}
 
-	if len(cmd.Password) < 4 {
-		return response.Error(400, "Password is missing or too short", nil)
+	if len(cmd.Password) == 0 {
+		return response.Error(400, "Password is missing", nil)
 	}
 
 	usr, err := hs.Login.CreateUser(cmd)
 	if err != nil {
+		var policyErr *user.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			return passwordPolicyErrorResponse(policyErr)
+		}
+
 		if errors.Is(err, models.ErrOrgNotFound) {
 			return response.Error(400, err.Error(), nil)
 		}
@@ -108,28 +110,20 @@ func (hs *HTTPServer) AdminUpdateUserPassword(c *models.ReqContext) response.Res
 		return response.Error(http.StatusBadRequest, "id is invalid", err)
 	}
 
-	if len(form.Password) < 4 {
-		return response.Error(400, "New password too short", nil)
-	}
-
-	userQuery := user.GetUserByIDQuery{ID: userID}
-
-	usr, err := hs.userService.GetByID(c.Req.Context(), &userQuery)
-	if err != nil {
-		return response.Error(500, "Could not read user from database", err)
-	}
-
-	passwordHashed, err := util.EncodePassword(form.Password, usr.Salt)
-	if err != nil {
-		return response.Error(500, "Could not encode password", err)
-	}
-
 	cmd := user.ChangeUserPasswordCommand{
 		UserID:      userID,
-		NewPassword: passwordHashed,
+		NewPassword: form.Password,
+		IsReset:     true,
 	}
 
 	if err := hs.userService.ChangePassword(c.Req.Context(), &cmd); err != nil {
+		var policyErr *user.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			return passwordPolicyErrorResponse(policyErr)
+		}
+		if errors.Is(err, user.ErrUserNotFound) {
+			return response.Error(404, user.ErrUserNotFound.Error(), nil)
+		}
 		return response.Error(500, "Failed to update user password", err)
 	}
 
@@ -171,10 +165,50 @@ func (hs *HTTPServer) AdminUpdateUserPermissions(c *models.ReqContext) response.
 	return response.Success("User permissions updated")
 }
 
+// swagger:route POST /admin/users/{user_id}/permissions/transfer admin_users adminTransferGrafanaAdmin
+//
+// Transfer the Grafana server admin permission of a user to another user.
+//
+// The other user is granted the permission and the user loses it in a single operation, so the last Grafana server admin can hand it over.
+// Only works with Basic Authentication (username and password). See introduction for an explanation.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users.permissions:update` and scope `global.users:*`.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) AdminTransferGrafanaAdmin(c *models.ReqContext) response.Response {
+	form := dtos.AdminTransferGrafanaAdminForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	err = hs.userService.TransferGrafanaAdmin(c.Req.Context(), userID, form.UserID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return response.Error(http.StatusNotFound, user.ErrUserNotFound.Error(), nil)
+		}
+		if errors.Is(err, user.ErrInvalidGrafanaAdminTransfer) {
+			return response.Error(http.StatusBadRequest, user.ErrInvalidGrafanaAdminTransfer.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to transfer the Grafana server admin permission", err)
+	}
+
+	return response.Success("Grafana server admin permission transferred")
+}
+
 // swagger:route DELETE /admin/users/{user_id} admin_users adminDeleteUser
 //
 // Delete global User.
 //
+// The resources created by the user can be reassigned to a successor given with `successorId`.
 // If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:delete` and scope `global.users:*`.
 //
 // Security:
@@ -182,6 +216,7 @@ func (hs *HTTPServer) AdminUpdateUserPermissions(c *models.ReqContext) response.
 //
 // Responses:
 // 200: okResponse
+// 400: badRequestError
 // 401: unauthorisedError
 // 403: forbiddenError
 // 404: notFoundError
@@ -193,74 +228,320 @@ func (hs *HTTPServer) AdminDeleteUser(c *models.ReqContext) response.Response {
 	}
 
 	cmd := user.DeleteUserCommand{UserID: userID}
+	if successorID := c.Query("successorId"); successorID != "" {
+		if cmd.SuccessorID, err = strconv.ParseInt(successorID, 10, 64); err != nil {
+			return response.Error(http.StatusBadRequest, "successorId is invalid", err)
+		}
+	}
 
 	if err := hs.userService.Delete(c.Req.Context(), &cmd); err != nil {
 		if errors.Is(err, user.ErrUserNotFound) {
 			return response.Error(404, user.ErrUserNotFound.Error(), nil)
 		}
+		if errors.Is(err, user.ErrInvalidSuccessor) {
+			return response.Error(http.StatusBadRequest, err.Error(), nil)
+		}
 		return response.Error(500, "Failed to delete user", err)
 	}
 
-	g, ctx := errgroup.WithContext(c.Req.Context())
-	g.Go(func() error {
-		if err := hs.starService.DeleteByUser(ctx, cmd.UserID); err != nil {
-			return err
+	// the user is soft deleted and keeps its data until it is purged, only its sessions are revoked
+	if err := hs.userAuthService.DeleteToken(c.Req.Context(), cmd.UserID); err != nil {
+		return response.Error(500, "Failed to delete user", err)
+	}
+
+	return response.Success("User deleted")
+}
+
+// swagger:route POST /admin/users/{user_id}/restore admin_users adminRestoreUser
+//
+// Restore a deleted global User.
+//
+// Deleted users can be restored until they are permanently deleted, after the `deleted_user_retention_duration` configured in the `[users]` section.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:delete` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (hs *HTTPServer) AdminRestoreUser(c *models.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	if err := hs.userService.Restore(c.Req.Context(), &user.RestoreUserCommand{UserID: userID}); err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return response.Error(404, "Deleted user not found", nil)
 		}
-		return nil
-	})
-	g.Go(func() error {
-		if err := hs.orgService.DeleteUserFromAll(ctx, cmd.UserID); err != nil {
-			return err
+		if errors.Is(err, user.ErrUserAlreadyExists) {
+			return response.Error(http.StatusConflict, "Login or email of the deleted user is taken by another user", nil)
 		}
-		return nil
-	})
-	g.Go(func() error {
-		if err := hs.DashboardService.DeleteACLByUser(ctx, cmd.UserID); err != nil {
-			return err
+		return response.Error(500, "Failed to restore user", err)
+	}
+
+	return response.Success("User restored")
+}
+
+// swagger:route GET /admin/users/{user_id}/attributes admin_users adminGetUserAttributes
+//
+// Get the attributes of a user.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:read` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getUserAttributesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) AdminGetUserAttributes(c *models.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	attributes, err := hs.userService.GetAttributes(c.Req.Context(), &user.GetUserAttributesQuery{UserID: userID})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return response.Error(404, user.ErrUserNotFound.Error(), nil)
 		}
-		return nil
+		return response.Error(500, "Failed to get user attributes", err)
+	}
+
+	return response.JSON(http.StatusOK, attributes)
+}
+
+// swagger:route GET /admin/users/{user_id}/audit admin_users adminGetUserAuditEntries
+//
+// Get the audit log of a user.
+//
+// Returns the changes made to the profile, permissions, status and password of a user, most recent first.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:read` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getUserAuditEntriesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminGetUserAuditEntries(c *models.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	result, err := hs.userService.GetAuditEntries(c.Req.Context(), &user.GetAuditEntriesQuery{
+		UserID: userID,
+		Action: user.AuditAction(c.Query("action")),
+		Page:   c.QueryInt("page"),
+		Limit:  c.QueryInt("perpage"),
 	})
-	g.Go(func() error {
-		if err := hs.preferenceService.DeleteByUser(ctx, cmd.UserID); err != nil {
-			return err
+	if err != nil {
+		return response.Error(500, "Failed to get user audit log", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route GET /admin/users/login-history admin_users adminGetLoginHistory
+//
+// Get the login history.
+//
+// Returns the successful and failed logins of users, most recent first. Failed logins of unknown users have no user ID.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:read` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getLoginHistoryResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminGetLoginHistory(c *models.ReqContext) response.Response {
+	query := &user.GetLoginHistoryQuery{
+		UserID:     c.QueryInt64("userId"),
+		AuthModule: c.Query("authModule"),
+		Result:     user.LoginResult(c.Query("result")),
+		Page:       c.QueryInt("page"),
+		Limit:      c.QueryInt("perpage"),
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.UnixMilli(from)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.UnixMilli(to)
+	}
+
+	result, err := hs.userService.GetLoginHistory(c.Req.Context(), query)
+	if err != nil {
+		return response.Error(500, "Failed to get login history", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route PUT /admin/users/{user_id}/attributes/{key} admin_users adminSetUserAttribute
+//
+// Set an attribute of a user.
+//
+// Attributes are key-value pairs attached to users, such as cost center identifiers. An empty value removes the attribute.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:write` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) AdminSetUserAttribute(c *models.ReqContext) response.Response {
+	form := dtos.AdminSetUserAttributeForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	cmd := user.SetUserAttributeCommand{UserID: userID, Key: web.Params(c.Req)[":key"], Value: form.Value}
+	if err := hs.userService.SetAttribute(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, user.ErrInvalidAttribute) {
+			return response.Error(http.StatusBadRequest, user.ErrInvalidAttribute.Error(), nil)
 		}
-		return nil
-	})
-	g.Go(func() error {
-		if err := hs.teamGuardian.DeleteByUser(ctx, cmd.UserID); err != nil {
-			return err
+		if errors.Is(err, user.ErrUserNotFound) {
+			return response.Error(404, user.ErrUserNotFound.Error(), nil)
 		}
-		return nil
-	})
-	g.Go(func() error {
-		if err := hs.userAuthService.Delete(ctx, cmd.UserID); err != nil {
-			return err
+		return response.Error(500, "Failed to set user attribute", err)
+	}
+
+	return response.Success("User attribute set")
+}
+
+// swagger:route PUT /admin/users/{user_id}/field-locks admin_users adminSetUserFieldLocks
+//
+// Lock the profile fields of a user.
+//
+// Locks or unlocks the name, email and login of a user. Users can't change their locked fields themselves.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:write` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) AdminSetUserFieldLocks(c *models.ReqContext) response.Response {
+	cmd := user.SetUserFieldLocksCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	cmd.UserID = userID
+
+	if err := hs.userService.SetFieldLocks(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return response.Error(404, user.ErrUserNotFound.Error(), nil)
 		}
-		return nil
-	})
-	g.Go(func() error {
-		if err := hs.userAuthService.DeleteToken(ctx, cmd.UserID); err != nil {
-			return err
+		return response.Error(500, "Failed to set user field locks", err)
+	}
+
+	return response.Success("User field locks set")
+}
+
+// swagger:route GET /admin/users/conflicts admin_users adminGetUserConflicts
+//
+// Get the user conflicts.
+//
+// Returns the pairs of users whose logins or emails only differ by their case. They need to be resolved before enabling case insensitive logins.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:read` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getUserConflictsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminGetUserConflicts(c *models.ReqContext) response.Response {
+	conflicts, err := hs.userService.GetConflicts(c.Req.Context())
+	if err != nil {
+		return response.Error(500, "Failed to get user conflicts", err)
+	}
+
+	return response.JSON(http.StatusOK, conflicts)
+}
+
+// swagger:route POST /admin/users/conflicts/resolve admin_users adminResolveUserConflict
+//
+// Resolve a user conflict.
+//
+// Keeps the canonical user of a conflict, and either merges the conflicting user into it or disables the conflicting user.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:delete` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) AdminResolveUserConflict(c *models.ReqContext) response.Response {
+	form := dtos.AdminResolveUserConflictForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	cmd := user.ResolveUserConflictCommand{
+		CanonicalUserID:   form.CanonicalUserID,
+		ConflictingUserID: form.ConflictingUserID,
+		Resolution:        user.ConflictResolution(form.Resolution),
+	}
+	if err := hs.userService.ResolveConflict(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, user.ErrInvalidResolution) || errors.Is(err, user.ErrNoUserConflict) {
+			return response.Error(http.StatusBadRequest, err.Error(), nil)
 		}
-		return nil
-	})
-	g.Go(func() error {
-		if err := hs.QuotaService.DeleteByUser(ctx, cmd.UserID); err != nil {
-			return err
+		if errors.Is(err, user.ErrUserNotFound) {
+			return response.Error(404, user.ErrUserNotFound.Error(), nil)
 		}
-		return nil
-	})
-	g.Go(func() error {
-		if err := hs.accesscontrolService.DeleteUserPermissions(ctx, accesscontrol.GlobalOrgID, cmd.UserID); err != nil {
-			return err
+		return response.Error(500, "Failed to resolve user conflict", err)
+	}
+
+	// merged users are deleted with their sessions, disabled ones are logged out
+	if cmd.Resolution == user.ConflictResolutionDisable {
+		if err := hs.AuthTokenService.RevokeAllUserTokens(c.Req.Context(), cmd.ConflictingUserID); err != nil {
+			return response.Error(500, "Failed to resolve user conflict", err)
 		}
-		return nil
-	})
-	if err := g.Wait(); err != nil {
-		return response.Error(500, "Failed to delete user", err)
 	}
 
-	return response.Success("User deleted")
+	return response.Success("User conflict resolved")
 }
 
 // swagger:route POST /admin/users/{user_id}/disable admin_users adminDisableUser
@@ -344,6 +625,38 @@ func (hs *HTTPServer) AdminEnableUser(c *models.ReqContext) response.Response {
 	return response.Success("User enabled")
 }
 
+// swagger:route POST /admin/users/{user_id}/unlock admin_users adminUnlockUser
+//
+// Unlock user.
+//
+// Unlocks a user locked out after too many failed logins and resets its failed logins.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:enable` and scope `global.users:1` (userIDScope).
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) AdminUnlockUser(c *models.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	if err := hs.userService.ResetLockout(c.Req.Context(), userID); err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return response.Error(404, user.ErrUserNotFound.Error(), nil)
+		}
+		return response.Error(500, "Failed to unlock user", err)
+	}
+
+	return response.Success("User unlocked")
+}
+
 // swagger:route POST /admin/users/{user_id}/logout admin_users adminLogoutUser
 //
 // Logout user revokes all auth tokens (devices) for the user. User of issued auth tokens (devices) will no longer be logged in and will be required to authenticate again upon next activity.
@@ -437,6 +750,114 @@ type AdminDeleteUserParams struct {
 	// in:path
 	// required:true
 	UserID int64 `json:"user_id"`
+	// ID of the user the resources created by the deleted user are reassigned to
+	// in:query
+	// required:false
+	SuccessorID int64 `json:"successorId"`
+}
+
+// swagger:parameters adminResolveUserConflict
+type AdminResolveUserConflictParams struct {
+	// in:body
+	// required:true
+	Body dtos.AdminResolveUserConflictForm `json:"body"`
+}
+
+// swagger:parameters adminUnlockUser
+type AdminUnlockUserParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"user_id"`
+}
+
+// swagger:parameters adminRestoreUser
+type AdminRestoreUserParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"user_id"`
+}
+
+// swagger:parameters adminGetUserAttributes
+type AdminGetUserAttributesParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"user_id"`
+}
+
+// swagger:parameters adminGetUserAuditEntries
+type AdminGetUserAuditEntriesParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"user_id"`
+	// Only return the entries of this action
+	// in:query
+	// required:false
+	// enum: update,update-permissions,disable,enable,change-password,impersonate,verify-email,lock-fields
+	Action string `json:"action"`
+	// in:query
+	// required:false
+	// default: 1
+	Page int `json:"page"`
+	// in:query
+	// required:false
+	// default: 100
+	PerPage int `json:"perpage"`
+}
+
+// swagger:parameters adminGetLoginHistory
+type AdminGetLoginHistoryParams struct {
+	// Only return the logins of this user
+	// in:query
+	// required:false
+	UserID int64 `json:"userId"`
+	// Only return the logins with this authentication module, such as grafana, ldap or oauth_github
+	// in:query
+	// required:false
+	AuthModule string `json:"authModule"`
+	// Only return the logins with this result
+	// in:query
+	// required:false
+	// enum: success,failure
+	Result string `json:"result"`
+	// Only return the logins since this time, in epoch milliseconds
+	// in:query
+	// required:false
+	From int64 `json:"from"`
+	// Only return the logins before this time, in epoch milliseconds
+	// in:query
+	// required:false
+	To int64 `json:"to"`
+	// in:query
+	// required:false
+	// default: 1
+	Page int `json:"page"`
+	// in:query
+	// required:false
+	// default: 100
+	PerPage int `json:"perpage"`
+}
+
+// swagger:parameters adminSetUserFieldLocks
+type AdminSetUserFieldLocksParams struct {
+	// in:body
+	// required:true
+	Body user.SetUserFieldLocksCommand `json:"body"`
+	// in:path
+	// required:true
+	UserID int64 `json:"user_id"`
+}
+
+// swagger:parameters adminSetUserAttribute
+type AdminSetUserAttributeParams struct {
+	// in:body
+	// required:true
+	Body dtos.AdminSetUserAttributeForm `json:"body"`
+	// in:path
+	// required:true
+	UserID int64 `json:"user_id"`
+	// in:path
+	// required:true
+	Key string `json:"key"`
 }
 
 // swagger:parameters adminEnableUser
@@ -494,6 +915,16 @@ type AdminUpdateUserPermissionsParams struct {
 	UserID int64 `json:"user_id"`
 }
 
+// swagger:parameters adminTransferGrafanaAdmin
+type AdminTransferGrafanaAdminParams struct {
+	// in:body
+	// required:true
+	Body dtos.AdminTransferGrafanaAdminForm `json:"body"`
+	// in:path
+	// required:true
+	UserID int64 `json:"user_id"`
+}
+
 // swagger:response adminCreateUserResponse
 type AdminCreateUserResponseResponse struct {
 	// in:body
@@ -505,3 +936,27 @@ type AdminGetUserAuthTokensResponse struct {
 	// in:body
 	Body []*models.UserToken `json:"body"`
 }
+
+// swagger:response getUserConflictsResponse
+type GetUserConflictsResponse struct {
+	// in:body
+	Body []*user.UserConflict `json:"body"`
+}
+
+// swagger:response getUserAuditEntriesResponse
+type GetUserAuditEntriesResponse struct {
+	// in:body
+	Body *user.AuditEntriesResult `json:"body"`
+}
+
+// swagger:response getLoginHistoryResponse
+type GetLoginHistoryResponse struct {
+	// in:body
+	Body *user.LoginHistoryResult `json:"body"`
+}
+
+// swagger:response getUserAttributesResponse
+type GetUserAttributesResponse struct {
+	// in:body
+	Body map[string]string `json:"body"`
+}
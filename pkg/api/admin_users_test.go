@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -20,6 +21,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/sqlstore/mockstore"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/services/userauth/userauthtest"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -159,6 +161,244 @@ func TestAdminAPIEndpoint(t *testing.T) {
 				require.NoError(t, err)
 				assert.Equal(t, "user not found", respJSON.Get("message").MustString())
 			})
+
+		adminDeleteUserScenario(t, "Should soft delete the user", "/api/admin/users/42",
+			"/api/admin/users/:id", func(sc *scenarioContext) {
+				sc.fakeReqWithParams("DELETE", sc.url, map[string]string{}).exec()
+
+				assert.Equal(t, 200, sc.resp.Code)
+			})
+
+		adminDeleteUserScenario(t, "Should return bad request when the successor is invalid", "/api/admin/users/42",
+			"/api/admin/users/:id", func(sc *scenarioContext) {
+				sc.userService.(*usertest.FakeUserService).ExpectedError = user.ErrInvalidSuccessor
+				sc.fakeReqWithParams("DELETE", sc.url, map[string]string{"successorId": "42"}).exec()
+
+				assert.Equal(t, 400, sc.resp.Code)
+			})
+
+		adminDeleteUserScenario(t, "Should return bad request when the successor id is not a number", "/api/admin/users/42",
+			"/api/admin/users/:id", func(sc *scenarioContext) {
+				sc.fakeReqWithParams("DELETE", sc.url, map[string]string{"successorId": "admin"}).exec()
+
+				assert.Equal(t, 400, sc.resp.Code)
+			})
+	})
+
+	t.Run("When a server admin attempts to restore a user", func(t *testing.T) {
+		adminRestoreUserScenario(t, "Should restore the user", "/api/admin/users/42/restore",
+			"/api/admin/users/:id/restore", nil, func(sc *scenarioContext) {
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+				assert.Equal(t, 200, sc.resp.Code)
+			})
+
+		adminRestoreUserScenario(t, "Should return not found when the user is not deleted", "/api/admin/users/42/restore",
+			"/api/admin/users/:id/restore", user.ErrUserNotFound, func(sc *scenarioContext) {
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+				assert.Equal(t, 404, sc.resp.Code)
+				respJSON, err := simplejson.NewJson(sc.resp.Body.Bytes())
+				require.NoError(t, err)
+				assert.Equal(t, "Deleted user not found", respJSON.Get("message").MustString())
+			})
+	})
+
+	t.Run("When a server admin attempts to unlock a user", func(t *testing.T) {
+		adminUnlockUserScenario(t, "Should unlock the user", "/api/admin/users/42/unlock",
+			"/api/admin/users/:id/unlock", nil, func(sc *scenarioContext) {
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+				assert.Equal(t, 200, sc.resp.Code)
+			})
+
+		adminUnlockUserScenario(t, "Should return not found when the user does not exist", "/api/admin/users/42/unlock",
+			"/api/admin/users/:id/unlock", user.ErrUserNotFound, func(sc *scenarioContext) {
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+				assert.Equal(t, 404, sc.resp.Code)
+			})
+	})
+
+	t.Run("When a server admin resolves user conflicts", func(t *testing.T) {
+		userService := usertest.NewUserServiceFake()
+		userService.ExpectedConflicts = []*user.UserConflict{{
+			Users:         [2]user.ConflictingUserDTO{{ID: 1, Login: "user"}, {ID: 2, Login: "User"}},
+			LoginConflict: true,
+		}}
+		adminUserConflictsScenario(t, "Should return the user conflicts", "GET", "/api/admin/users/conflicts",
+			dtos.AdminResolveUserConflictForm{}, userService, auth.NewFakeUserAuthTokenService(), func(sc *scenarioContext) {
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+
+				assert.Equal(t, 200, sc.resp.Code)
+				respJSON, err := simplejson.NewJson(sc.resp.Body.Bytes())
+				require.NoError(t, err)
+				assert.Equal(t, "User", respJSON.GetIndex(0).Get("users").GetIndex(1).Get("login").MustString())
+				assert.True(t, respJSON.GetIndex(0).Get("loginConflict").MustBool())
+			})
+
+		var revokedUserID int64
+		authTokenService := auth.NewFakeUserAuthTokenService()
+		authTokenService.RevokeAllUserTokensProvider = func(ctx context.Context, userID int64) error {
+			revokedUserID = userID
+			return nil
+		}
+		form := dtos.AdminResolveUserConflictForm{CanonicalUserID: 1, ConflictingUserID: 2, Resolution: "disable"}
+		adminUserConflictsScenario(t, "Should disable and log out the conflicting user", "POST", "/api/admin/users/conflicts/resolve",
+			form, usertest.NewUserServiceFake(), authTokenService, func(sc *scenarioContext) {
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+				assert.Equal(t, 200, sc.resp.Code)
+				assert.Equal(t, int64(2), revokedUserID)
+			})
+
+		userService = usertest.NewUserServiceFake()
+		userService.ExpectedError = user.ErrNoUserConflict
+		adminUserConflictsScenario(t, "Should return bad request when the users are not in conflict", "POST", "/api/admin/users/conflicts/resolve",
+			form, userService, auth.NewFakeUserAuthTokenService(), func(sc *scenarioContext) {
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+				assert.Equal(t, 400, sc.resp.Code)
+			})
+	})
+
+	t.Run("When a server admin manages user attributes", func(t *testing.T) {
+		userService := usertest.NewUserServiceFake()
+		userService.ExpectedAttributes = map[string]string{"cost_center": "cc-42"}
+		adminUserAttributesScenario(t, "Should return the attributes of the user", "GET", "/api/admin/users/42/attributes",
+			"/api/admin/users/:id/attributes", dtos.AdminSetUserAttributeForm{}, userService, func(sc *scenarioContext) {
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+
+				assert.Equal(t, 200, sc.resp.Code)
+				respJSON, err := simplejson.NewJson(sc.resp.Body.Bytes())
+				require.NoError(t, err)
+				assert.Equal(t, "cc-42", respJSON.Get("cost_center").MustString())
+			})
+
+		adminUserAttributesScenario(t, "Should set the attribute", "PUT", "/api/admin/users/42/attributes/cost_center",
+			"/api/admin/users/:id/attributes/:key", dtos.AdminSetUserAttributeForm{Value: "cc-42"}, usertest.NewUserServiceFake(), func(sc *scenarioContext) {
+				sc.fakeReqWithParams("PUT", sc.url, map[string]string{}).exec()
+
+				assert.Equal(t, 200, sc.resp.Code)
+			})
+
+		userService = usertest.NewUserServiceFake()
+		userService.ExpectedError = user.ErrInvalidAttribute
+		adminUserAttributesScenario(t, "Should reject invalid attributes", "PUT", "/api/admin/users/42/attributes/cost_center",
+			"/api/admin/users/:id/attributes/:key", dtos.AdminSetUserAttributeForm{Value: "cc-42"}, userService, func(sc *scenarioContext) {
+				sc.fakeReqWithParams("PUT", sc.url, map[string]string{}).exec()
+
+				assert.Equal(t, 400, sc.resp.Code)
+			})
+
+		userService = usertest.NewUserServiceFake()
+		userService.ExpectedError = user.ErrUserNotFound
+		adminUserAttributesScenario(t, "Should return not found when the user does not exist", "PUT", "/api/admin/users/42/attributes/cost_center",
+			"/api/admin/users/:id/attributes/:key", dtos.AdminSetUserAttributeForm{Value: "cc-42"}, userService, func(sc *scenarioContext) {
+				sc.fakeReqWithParams("PUT", sc.url, map[string]string{}).exec()
+
+				assert.Equal(t, 404, sc.resp.Code)
+			})
+	})
+
+	t.Run("When a server admin locks the profile fields of a user", func(t *testing.T) {
+		adminSetUserFieldLocksScenario(t, "Should set the field locks", "/api/admin/users/42/field-locks",
+			"/api/admin/users/:id/field-locks", usertest.NewUserServiceFake(), func(sc *scenarioContext) {
+				sc.fakeReqWithParams("PUT", sc.url, map[string]string{}).exec()
+
+				assert.Equal(t, 200, sc.resp.Code)
+			})
+
+		userService := usertest.NewUserServiceFake()
+		userService.ExpectedError = user.ErrUserNotFound
+		adminSetUserFieldLocksScenario(t, "Should return not found when the user does not exist", "/api/admin/users/42/field-locks",
+			"/api/admin/users/:id/field-locks", userService, func(sc *scenarioContext) {
+				sc.fakeReqWithParams("PUT", sc.url, map[string]string{}).exec()
+
+				assert.Equal(t, 404, sc.resp.Code)
+			})
+	})
+
+	t.Run("When a server admin gets the audit log of a user", func(t *testing.T) {
+		userService := usertest.NewUserServiceFake()
+		userService.ExpectedAuditEntries = &user.AuditEntriesResult{
+			TotalCount: 1,
+			Entries: []*user.AuditEntry{{
+				ID:         1,
+				UserID:     42,
+				ActorLogin: "admin",
+				Action:     user.AuditActionUpdate,
+				Changes:    []user.AuditChange{{Field: "email", OldValue: "old@example.com", NewValue: "new@example.com"}},
+			}},
+			Page:    1,
+			PerPage: 100,
+		}
+		adminGetUserAuditEntriesScenario(t, "Should return the audit entries of the user", "/api/admin/users/42/audit",
+			"/api/admin/users/:id/audit", userService, func(sc *scenarioContext) {
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{"action": "update"}).exec()
+
+				assert.Equal(t, 200, sc.resp.Code)
+				respJSON, err := simplejson.NewJson(sc.resp.Body.Bytes())
+				require.NoError(t, err)
+				assert.Equal(t, 1, respJSON.Get("totalCount").MustInt())
+				entry := respJSON.Get("entries").GetIndex(0)
+				assert.Equal(t, "update", entry.Get("action").MustString())
+				assert.Equal(t, "new@example.com", entry.Get("changes").GetIndex(0).Get("newValue").MustString())
+			})
+
+		adminGetUserAuditEntriesScenario(t, "Should return bad request when the id is invalid", "/api/admin/users/invalid/audit",
+			"/api/admin/users/:id/audit", usertest.NewUserServiceFake(), func(sc *scenarioContext) {
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+
+				assert.Equal(t, 400, sc.resp.Code)
+			})
+	})
+
+	t.Run("When a server admin gets the login history", func(t *testing.T) {
+		userService := usertest.NewUserServiceFake()
+		userService.ExpectedLoginHistory = &user.LoginHistoryResult{
+			TotalCount: 1,
+			Entries:    []*user.LoginHistoryEntry{{ID: 1, UserID: 42, Login: "user", AuthModule: "grafana", Result: user.LoginResultFailure}},
+			Page:       1,
+			PerPage:    100,
+		}
+		adminGetLoginHistoryScenario(t, "Should return the logins", "/api/admin/users/login-history",
+			"/api/admin/users/login-history", userService, func(sc *scenarioContext) {
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{"result": "failure", "from": "1666000000000"}).exec()
+
+				assert.Equal(t, 200, sc.resp.Code)
+				respJSON, err := simplejson.NewJson(sc.resp.Body.Bytes())
+				require.NoError(t, err)
+				assert.Equal(t, 1, respJSON.Get("totalCount").MustInt())
+				assert.Equal(t, "failure", respJSON.Get("entries").GetIndex(0).Get("result").MustString())
+			})
+	})
+
+	t.Run("When a server admin transfers the Grafana server admin permission", func(t *testing.T) {
+		adminTransferGrafanaAdminScenario(t, "Should transfer the permission", "/api/admin/users/1/permissions/transfer",
+			"/api/admin/users/:id/permissions/transfer", usertest.NewUserServiceFake(), func(sc *scenarioContext) {
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+				assert.Equal(t, 200, sc.resp.Code)
+			})
+
+		userService := usertest.NewUserServiceFake()
+		userService.ExpectedError = user.ErrInvalidGrafanaAdminTransfer
+		adminTransferGrafanaAdminScenario(t, "Should return bad request when the transfer is invalid", "/api/admin/users/1/permissions/transfer",
+			"/api/admin/users/:id/permissions/transfer", userService, func(sc *scenarioContext) {
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+				assert.Equal(t, 400, sc.resp.Code)
+			})
+
+		userService = usertest.NewUserServiceFake()
+		userService.ExpectedError = user.ErrUserNotFound
+		adminTransferGrafanaAdminScenario(t, "Should return not found when a user does not exist", "/api/admin/users/1/permissions/transfer",
+			"/api/admin/users/:id/permissions/transfer", userService, func(sc *scenarioContext) {
+				sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+				assert.Equal(t, 404, sc.resp.Code)
+			})
 	})
 
 	t.Run("When a server admin attempts to create a user", func(t *testing.T) {
@@ -375,8 +615,9 @@ func adminDisableUserScenario(t *testing.T, desc string, action string, url stri
 
 func adminDeleteUserScenario(t *testing.T, desc string, url string, routePattern string, fn scenarioFunc) {
 	hs := HTTPServer{
-		SQLStore:    mockstore.NewSQLStoreMock(),
-		userService: usertest.NewUserServiceFake(),
+		SQLStore:        mockstore.NewSQLStoreMock(),
+		userService:     usertest.NewUserServiceFake(),
+		userAuthService: userauthtest.NewFakeUserAuthService(),
 	}
 	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
 		sc := setupScenarioContext(t, url)
@@ -396,6 +637,192 @@ func adminDeleteUserScenario(t *testing.T, desc string, url string, routePattern
 	})
 }
 
+func adminRestoreUserScenario(t *testing.T, desc string, url string, routePattern string, restoreErr error, fn scenarioFunc) {
+	userService := usertest.NewUserServiceFake()
+	userService.ExpectedError = restoreErr
+	hs := HTTPServer{
+		SQLStore:    mockstore.NewSQLStoreMock(),
+		userService: userService,
+	}
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserID = testUserID
+
+			return hs.AdminRestoreUser(c)
+		})
+
+		sc.m.Post(routePattern, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
+
+func adminUnlockUserScenario(t *testing.T, desc string, url string, routePattern string, unlockErr error, fn scenarioFunc) {
+	userService := usertest.NewUserServiceFake()
+	userService.ExpectedError = unlockErr
+	hs := HTTPServer{
+		SQLStore:    mockstore.NewSQLStoreMock(),
+		userService: userService,
+	}
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserID = testUserID
+
+			return hs.AdminUnlockUser(c)
+		})
+
+		sc.m.Post(routePattern, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
+
+func adminUserConflictsScenario(t *testing.T, desc string, method string, url string, form dtos.AdminResolveUserConflictForm, userService user.Service, authTokenService *auth.FakeUserAuthTokenService, fn scenarioFunc) {
+	hs := HTTPServer{
+		SQLStore:         mockstore.NewSQLStoreMock(),
+		AuthTokenService: authTokenService,
+		userService:      userService,
+	}
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserID = testUserID
+
+			if method == "GET" {
+				return hs.AdminGetUserConflicts(c)
+			}
+			c.Req.Body = mockRequestBody(form)
+			c.Req.Header.Add("Content-Type", "application/json")
+			return hs.AdminResolveUserConflict(c)
+		})
+
+		if method == "GET" {
+			sc.m.Get(url, sc.defaultHandler)
+		} else {
+			sc.m.Post(url, sc.defaultHandler)
+		}
+
+		fn(sc)
+	})
+}
+
+func adminUserAttributesScenario(t *testing.T, desc string, method string, url string, routePattern string, form dtos.AdminSetUserAttributeForm, userService user.Service, fn scenarioFunc) {
+	hs := HTTPServer{
+		SQLStore:    mockstore.NewSQLStoreMock(),
+		userService: userService,
+	}
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserID = testUserID
+
+			if method == "GET" {
+				return hs.AdminGetUserAttributes(c)
+			}
+			c.Req.Body = mockRequestBody(form)
+			c.Req.Header.Add("Content-Type", "application/json")
+			return hs.AdminSetUserAttribute(c)
+		})
+
+		if method == "GET" {
+			sc.m.Get(routePattern, sc.defaultHandler)
+		} else {
+			sc.m.Put(routePattern, sc.defaultHandler)
+		}
+
+		fn(sc)
+	})
+}
+
+func adminSetUserFieldLocksScenario(t *testing.T, desc string, url string, routePattern string, userService user.Service, fn scenarioFunc) {
+	hs := HTTPServer{
+		SQLStore:    mockstore.NewSQLStoreMock(),
+		userService: userService,
+	}
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserID = testUserID
+			c.Req.Body = mockRequestBody(user.SetUserFieldLocksCommand{Email: true, Login: true})
+			c.Req.Header.Add("Content-Type", "application/json")
+			return hs.AdminSetUserFieldLocks(c)
+		})
+
+		sc.m.Put(routePattern, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
+
+func adminGetUserAuditEntriesScenario(t *testing.T, desc string, url string, routePattern string, userService user.Service, fn scenarioFunc) {
+	hs := HTTPServer{
+		SQLStore:    mockstore.NewSQLStoreMock(),
+		userService: userService,
+	}
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserID = testUserID
+
+			return hs.AdminGetUserAuditEntries(c)
+		})
+
+		sc.m.Get(routePattern, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
+
+func adminGetLoginHistoryScenario(t *testing.T, desc string, url string, routePattern string, userService user.Service, fn scenarioFunc) {
+	hs := HTTPServer{
+		SQLStore:    mockstore.NewSQLStoreMock(),
+		userService: userService,
+	}
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserID = testUserID
+
+			return hs.AdminGetLoginHistory(c)
+		})
+
+		sc.m.Get(routePattern, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
+
+func adminTransferGrafanaAdminScenario(t *testing.T, desc string, url string, routePattern string, userService user.Service, fn scenarioFunc) {
+	hs := HTTPServer{
+		SQLStore:    mockstore.NewSQLStoreMock(),
+		userService: userService,
+	}
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserID = testUserID
+
+			c.Req.Body = mockRequestBody(dtos.AdminTransferGrafanaAdminForm{UserID: 2})
+			c.Req.Header.Add("Content-Type", "application/json")
+			return hs.AdminTransferGrafanaAdmin(c)
+		})
+
+		sc.m.Post(routePattern, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
+
 func adminCreateUserScenario(t *testing.T, desc string, url string, routePattern string, cmd dtos.AdminCreateUserForm, fn scenarioFunc) {
 	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
 		hs := HTTPServer{
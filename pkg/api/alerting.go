@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/pagination"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -102,7 +103,7 @@ func (hs *HTTPServer) GetAlerts(c *models.ReqContext) response.Response {
 			Title:        dashboardQuery,
 			Tags:         dashboardTags,
 			SignedInUser: c.SignedInUser,
-			Limit:        1000,
+			Pagination:   pagination.Page{PerPage: 1000},
 			OrgId:        c.OrgID,
 			DashboardIds: dashboardIDs,
 			Type:         string(models.DashHitDB),
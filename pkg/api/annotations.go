@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/pagination"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -32,6 +33,11 @@ import (
 // 401: unauthorisedError
 // 500: internalServerError
 func (hs *HTTPServer) GetAnnotations(c *models.ReqContext) response.Response {
+	page, err := pagination.NewPage(c.QueryInt64("page"), c.QueryInt64("limit"), c.Query("cursor"), c.Query("order"))
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
 	query := &annotations.ItemQuery{
 		From:         c.QueryInt64("from"),
 		To:           c.QueryInt64("to"),
@@ -41,7 +47,10 @@ func (hs *HTTPServer) GetAnnotations(c *models.ReqContext) response.Response {
 		DashboardId:  c.QueryInt64("dashboardId"),
 		DashboardUid: c.Query("dashboardUID"),
 		PanelId:      c.QueryInt64("panelId"),
-		Limit:        c.QueryInt64("limit"),
+		Limit:        page.PerPage,
+		Page:         page.Page,
+		Cursor:       page.Cursor,
+		Order:        page.Order,
 		Tags:         c.QueryStrings("tags"),
 		Type:         c.Query("type"),
 		MatchAny:     c.QueryBool("matchAny"),
@@ -685,6 +694,20 @@ type GetAnnotationsParams struct {
 	// in:query
 	// required:false
 	Limit int64 `json:"limit"`
+	// Page of results to return, pages being limit annotations long.
+	// in:query
+	// required:false
+	// default:1
+	Page int64 `json:"page"`
+	// Cursor of the first annotation to return, takes precedence over page.
+	// in:query
+	// required:false
+	Cursor string `json:"cursor"`
+	// Order of the annotations by time, most recent first by default.
+	// in:query
+	// required:false
+	// enum: asc,desc
+	Order string `json:"order"`
 	// Use this to filter organization annotations. Organization annotations are annotations from an annotation data source that are not connected specifically to a dashboard or panel. You can filter by multiple tags.
 	// in:query
 	// required:false
@@ -126,6 +126,7 @@ func (hs *HTTPServer) AddAPIKey(c *models.ReqContext) response.Response {
 	}
 
 	cmd.OrgId = c.OrgID
+	cmd.UserID = c.UserID
 
 	newKeyInfo, err := apikeygen.New(cmd.OrgId, cmd.Name)
 	if err != nil {
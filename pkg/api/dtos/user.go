@@ -29,6 +29,20 @@ type AdminUpdateUserPermissionsForm struct {
 	IsGrafanaAdmin bool `json:"isGrafanaAdmin"`
 }
 
+type AdminTransferGrafanaAdminForm struct {
+	UserID int64 `json:"userId" binding:"Required"`
+}
+
+type AdminSetUserAttributeForm struct {
+	Value string `json:"value"`
+}
+
+type AdminResolveUserConflictForm struct {
+	CanonicalUserID   int64  `json:"canonicalUserId" binding:"Required"`
+	ConflictingUserID int64  `json:"conflictingUserId" binding:"Required"`
+	Resolution        string `json:"resolution" binding:"Required"`
+}
+
 type SendResetPasswordEmailForm struct {
 	UserOrEmail string `json:"userOrEmail" binding:"Required"`
 }
@@ -7,7 +7,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/mockstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
@@ -29,12 +31,48 @@ func TestHealthAPI_Version(t *testing.T) {
 		{
 			"database": "ok",
 			"version": "7.4.0",
-			"commit": "59906ab1bf"
+			"commit": "59906ab1bf",
+			"databasePool": {
+				"maxOpenConnections": 0,
+				"openConnections": 0,
+				"inUse": 0,
+				"idle": 0,
+				"waitCount": 0,
+				"waitDurationMs": 0,
+				"maxIdleClosed": 0,
+				"maxIdleTimeClosed": 0,
+				"maxLifetimeClosed": 0,
+				"saturated": false
+			}
 		}
 	`
 	require.JSONEq(t, expectedBody, rec.Body.String())
 }
 
+func TestHealthAPI_DatabasePool(t *testing.T) {
+	m, hs := setupHealthAPITestEnvironment(t)
+	hs.SQLStore.(*mockstore.SQLStoreMock).ExpectedPoolStats = sqlstore.PoolStats{
+		MaxOpenConnections: 10,
+		OpenConnections:    10,
+		InUse:              10,
+		WaitCount:          3,
+		WaitDurationMs:     1500,
+		Saturated:          true,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+
+	require.Equal(t, 200, rec.Code)
+	data, err := simplejson.NewJson(rec.Body.Bytes())
+	require.NoError(t, err)
+	pool := data.Get("databasePool")
+	require.Equal(t, 10, pool.Get("inUse").MustInt())
+	require.Equal(t, 1500, pool.Get("waitDurationMs").MustInt())
+	require.True(t, pool.Get("saturated").MustBool())
+}
+
 func TestHealthAPI_AnonymousHideVersion(t *testing.T) {
 	m, hs := setupHealthAPITestEnvironment(t)
 	hs.Cfg.AnonymousHideVersion = true
@@ -361,6 +361,7 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 		hs.log.Debug("Using provided listener")
 	}
 	hs.registerRoutes()
+	hs.HooksService.AddLoginHook(hs.recordLoginHistory)
 
 	// Register access control scope resolver for annotations
 	hs.AccessControl.RegisterScopeAttributeResolver(AnnotationTypeScopeResolver(hs.annotationsRepo))
@@ -671,6 +672,8 @@ func (hs *HTTPServer) apiHealthHandler(ctx *web.Context) {
 	if !hs.Cfg.AnonymousHideVersion {
 		data.Set("version", hs.Cfg.BuildVersion)
 		data.Set("commit", hs.Cfg.BuildCommit)
+		// the connection pool statistics show its saturation before the queries start timing out
+		data.Set("databasePool", hs.SQLStore.GetPoolStats())
 	}
 
 	if !hs.databaseHealthy(ctx.Req.Context()) {
@@ -220,11 +220,23 @@ func (hs *HTTPServer) LoginPost(c *models.ReqContext) response.Response {
 			return resp
 		}
 
+		// Do not expose the lockout either, it would tell attackers the user exists
+		if errors.Is(err, login.ErrUserLocked) {
+			hs.log.Warn("User is locked", "user", cmd.User)
+			return resp
+		}
+
 		if errors.Is(err, login.ErrNoAuthProvider) {
 			resp = response.Error(http.StatusInternalServerError, "No authorization providers enabled", err)
 			return resp
 		}
 
+		// The password was valid, users are told to verify their email address
+		if errors.Is(err, user.ErrEmailNotVerified) {
+			resp = response.Error(http.StatusUnauthorized, "Verify your email address to log in", err)
+			return resp
+		}
+
 		// Do not expose disabled status,
 		// just show incorrect user credentials error (see #17947)
 		if errors.Is(err, login.ErrUserDisabled) {
@@ -267,8 +279,8 @@ func (hs *HTTPServer) LoginPost(c *models.ReqContext) response.Response {
 	return resp
 }
 
-func (hs *HTTPServer) loginUserWithUser(user *user.User, c *models.ReqContext) error {
-	if user == nil {
+func (hs *HTTPServer) loginUserWithUser(usr *user.User, c *models.ReqContext) error {
+	if usr == nil {
 		return errors.New("could not login user")
 	}
 
@@ -281,17 +293,49 @@ func (hs *HTTPServer) loginUserWithUser(user *user.User, c *models.ReqContext) e
 
 	hs.log.Debug("Got IP address from client address", "addr", addr, "ip", ip)
 	ctx := context.WithValue(c.Req.Context(), models.RequestURIKey{}, c.Req.RequestURI)
-	userToken, err := hs.AuthTokenService.CreateToken(ctx, user, ip, c.Req.UserAgent())
+	userToken, err := hs.AuthTokenService.CreateToken(ctx, usr, ip, c.Req.UserAgent())
 	if err != nil {
 		return fmt.Errorf("%v: %w", "failed to create auth token", err)
 	}
 	c.UserToken = userToken
 
-	hs.log.Info("Successful Login", "User", user.Email)
+	client := &user.LoginClient{UserAgent: c.Req.UserAgent()}
+	if ip != nil {
+		client.IP = ip.String()
+	}
+	if err := hs.userService.UpdateLastSeenAt(ctx, &user.UpdateUserLastSeenAtCommand{UserID: usr.ID, Login: client}); err != nil {
+		hs.log.Warn("Failed to record the last login of the user", "userId", usr.ID, "error", err)
+	}
+
+	hs.log.Info("Successful Login", "User", usr.Email)
 	cookies.WriteSessionCookie(c, hs.Cfg, userToken.UnhashedToken, hs.Cfg.LoginMaxLifetime)
 	return nil
 }
 
+// recordLoginHistory records the successful and failed logins run through the login hooks in the login history
+func (hs *HTTPServer) recordLoginHistory(info *models.LoginInfo, c *models.ReqContext) {
+	cmd := &user.RecordLoginCommand{
+		Login:      info.LoginUsername,
+		AuthModule: info.AuthModule,
+		Result:     user.LoginResultSuccess,
+	}
+	if info.Error != nil {
+		cmd.Result = user.LoginResultFailure
+	}
+	if info.User != nil {
+		cmd.UserID, cmd.Login = info.User.ID, info.User.Login
+	} else if cmd.Login == "" {
+		cmd.Login = info.ExternalUser.Login
+		if cmd.Login == "" {
+			cmd.Login = info.ExternalUser.Email
+		}
+	}
+
+	if err := hs.userService.RecordLogin(c.Req.Context(), cmd); err != nil {
+		hs.log.Warn("Failed to record login", "login", cmd.Login, "err", err)
+	}
+}
+
 func (hs *HTTPServer) Logout(c *models.ReqContext) {
 	// If SAML is enabled and this is a SAML user use saml logout
 	if hs.samlSingleLogoutEnabled() {
@@ -32,6 +32,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -324,6 +325,7 @@ func TestLoginPostRedirect(t *testing.T) {
 		HooksService:     &hooks.HooksService{},
 		License:          &licensing.OSSLicensingService{},
 		AuthTokenService: auth.NewFakeUserAuthTokenService(),
+		userService:      usertest.NewUserServiceFake(),
 	}
 	hs.Cfg.CookieSecure = true
 
@@ -565,6 +567,7 @@ func setupAuthProxyLoginTest(t *testing.T, enableLoginToken bool) *scenarioConte
 		SettingsProvider: &setting.OSSImpl{Cfg: sc.cfg},
 		License:          &licensing.OSSLicensingService{},
 		AuthTokenService: auth.NewFakeUserAuthTokenService(),
+		userService:      usertest.NewUserServiceFake(),
 		log:              log.New("hello"),
 		SocialService:    &mockSocialService{},
 	}
@@ -603,6 +606,7 @@ func TestLoginPostRunLokingHook(t *testing.T) {
 		Cfg:              setting.NewCfg(),
 		License:          &licensing.OSSLicensingService{},
 		AuthTokenService: auth.NewFakeUserAuthTokenService(),
+		userService:      usertest.NewUserServiceFake(),
 		HooksService:     hookService,
 	}
 
@@ -646,6 +650,16 @@ func TestLoginPostRunLokingHook(t *testing.T) {
 				Error:      login.ErrUserDisabled,
 			},
 		},
+		{
+			desc:       "email not verified",
+			authModule: "grafana",
+			authErr:    user.ErrEmailNotVerified,
+			info: models.LoginInfo{
+				AuthModule: "grafana",
+				HTTPStatus: 401,
+				Error:      user.ErrEmailNotVerified,
+			},
+		},
 		{
 			desc:       "valid Grafana user",
 			authUser:   testUser,
@@ -688,6 +702,37 @@ func TestLoginPostRunLokingHook(t *testing.T) {
 	}
 }
 
+func TestLoginPostRecordsLoginHistory(t *testing.T) {
+	sc := setupScenarioContext(t, "/login")
+	userService := usertest.NewUserServiceFake()
+	hs := &HTTPServer{
+		log:              log.New("test"),
+		Cfg:              setting.NewCfg(),
+		License:          &licensing.OSSLicensingService{},
+		AuthTokenService: auth.NewFakeUserAuthTokenService(),
+		userService:      userService,
+		HooksService:     &hooks.HooksService{},
+	}
+	hs.HooksService.AddLoginHook(hs.recordLoginHistory)
+
+	sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+		c.Req.Header.Set("Content-Type", "application/json")
+		c.Req.Body = io.NopCloser(bytes.NewBufferString(`{"user":"admin","password":"admin"}`))
+		return hs.LoginPost(c)
+	})
+	sc.m.Post(sc.url, sc.defaultHandler)
+
+	hs.authenticator = &fakeAuthenticator{nil, "", login.ErrInvalidCredentials}
+	sc.fakeReqNoAssertions("POST", sc.url).exec()
+	hs.authenticator = &fakeAuthenticator{&user.User{ID: 42, Login: "admin"}, "grafana", nil}
+	sc.fakeReqNoAssertions("POST", sc.url).exec()
+
+	require.Equal(t, []*user.RecordLoginCommand{
+		{Login: "admin", Result: user.LoginResultFailure},
+		{UserID: 42, Login: "admin", AuthModule: "grafana", Result: user.LoginResultSuccess},
+	}, userService.RecordedLogins)
+}
+
 type mockSocialService struct {
 	oAuthInfo       *social.OAuthInfo
 	oAuthInfos      map[string]*social.OAuthInfo
@@ -244,6 +244,10 @@ func (hs *HTTPServer) CompleteInvite(c *models.ReqContext) response.Response {
 
 	usr, err := hs.Login.CreateUser(cmd)
 	if err != nil {
+		var policyErr *user.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			return passwordPolicyErrorResponse(policyErr)
+		}
 		if errors.Is(err, user.ErrUserAlreadyExists) {
 			return response.Error(412, fmt.Sprintf("User with email '%s' or username '%s' already exists", completeInvite.Email, completeInvite.Username), err)
 		}
@@ -53,6 +53,14 @@ func (hs *HTTPServer) SendResetPasswordEmail(c *models.ReqContext) response.Resp
 	return response.Success("Email sent")
 }
 
+// passwordPolicyErrorResponse lists the rules of the password policy a password failed
+func passwordPolicyErrorResponse(policyErr *user.PasswordPolicyError) response.Response {
+	return response.JSON(http.StatusBadRequest, util.DynMap{
+		"message":     "Password does not satisfy the password policy",
+		"failedRules": policyErr.FailedRules,
+	})
+}
+
 func (hs *HTTPServer) ResetPassword(c *models.ReqContext) response.Response {
 	form := dtos.ResetUserPasswordForm{}
 	if err := web.Bind(c.Req, &form); err != nil {
@@ -82,15 +90,17 @@ func (hs *HTTPServer) ResetPassword(c *models.ReqContext) response.Response {
 		return response.Error(400, "New password is too short", nil)
 	}
 
-	cmd := user.ChangeUserPasswordCommand{}
-	cmd.UserID = query.Result.ID
-	var err error
-	cmd.NewPassword, err = util.EncodePassword(form.NewPassword, query.Result.Salt)
-	if err != nil {
-		return response.Error(500, "Failed to encode password", err)
+	cmd := user.ChangeUserPasswordCommand{
+		UserID:      query.Result.ID,
+		NewPassword: form.NewPassword,
+		IsReset:     true,
 	}
 
 	if err := hs.userService.ChangePassword(c.Req.Context(), &cmd); err != nil {
+		var policyErr *user.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			return passwordPolicyErrorResponse(policyErr)
+		}
 		return response.Error(500, "Failed to change user password", err)
 	}
 
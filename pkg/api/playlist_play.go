@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/playlist"
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 func (hs *HTTPServer) populateDashboardsByID(ctx context.Context, dashboardByIDs []int64, dashboardIDOrder map[int64]int) (dtos.PlaylistDashboardsSlice, error) {
@@ -46,7 +47,7 @@ func (hs *HTTPServer) populateDashboardsByTag(ctx context.Context, orgID int64,
 			Title:        "",
 			Tags:         []string{tag},
 			SignedInUser: signedInUser,
-			Limit:        100,
+			Pagination:   pagination.Page{PerPage: 100},
 			IsStarred:    false,
 			OrgId:        orgID,
 		}
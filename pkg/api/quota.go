@@ -1,11 +1,13 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -97,7 +99,7 @@ func (hs *HTTPServer) UpdateOrgQuota(c *models.ReqContext) response.Response {
 // - basic:
 //
 // Responses:
-// 200: getQuotaResponse
+// 200: getUserQuotasResponse
 // 401: unauthorisedError
 // 403: forbiddenError
 // 404: notFoundError
@@ -108,7 +110,7 @@ func (hs *HTTPServer) UpdateOrgQuota(c *models.ReqContext) response.Response {
 // Fetch user quota.
 //
 // Responses:
-// 200: getQuotaResponse
+// 200: getUserQuotasResponse
 // 401: unauthorisedError
 // 403: forbiddenError
 // 404: notFoundError
@@ -123,13 +125,12 @@ func (hs *HTTPServer) GetUserQuotas(c *models.ReqContext) response.Response {
 		return response.Error(http.StatusBadRequest, "id is invalid", err)
 	}
 
-	query := models.GetUserQuotasQuery{UserId: id}
-
-	if err := hs.SQLStore.GetUserQuotas(c.Req.Context(), &query); err != nil {
-		return response.Error(500, "Failed to get org quotas", err)
+	quotas, err := hs.userService.GetQuotas(c.Req.Context(), &user.GetQuotasQuery{UserID: id, UnifiedAlertingEnabled: hs.Cfg.UnifiedAlerting.IsEnabled()})
+	if err != nil {
+		return response.Error(500, "Failed to get user quotas", err)
 	}
 
-	return response.JSON(http.StatusOK, query.Result)
+	return response.JSON(http.StatusOK, quotas)
 }
 
 // swagger:route PUT /admin/users/{user_id}/quotas/{quota_target} admin_users updateUserQuota
@@ -162,14 +163,13 @@ func (hs *HTTPServer) UpdateUserQuota(c *models.ReqContext) response.Response {
 	}
 	cmd.Target = web.Params(c.Req)[":target"]
 
-	if _, ok := setting.Quota.User.ToMap()[cmd.Target]; !ok {
-		return response.Error(404, "Invalid quota target", nil)
-	}
-
-	if err := hs.SQLStore.UpdateUserQuota(c.Req.Context(), &cmd); err != nil {
-		return response.Error(500, "Failed to update org quotas", err)
+	if err := hs.userService.SetQuota(c.Req.Context(), &user.SetQuotaCommand{UserID: cmd.UserId, Target: cmd.Target, Limit: cmd.Limit}); err != nil {
+		if errors.Is(err, user.ErrInvalidQuotaTarget) {
+			return response.Error(404, "Invalid quota target", nil)
+		}
+		return response.Error(500, "Failed to update user quota", err)
 	}
-	return response.Success("Organization quota updated")
+	return response.Success("User quota updated")
 }
 
 // swagger:parameters updateUserQuota
@@ -212,8 +212,14 @@ type UpdateOrgQuotaParam struct {
 	OrgID int64 `json:"org_id"`
 }
 
+// swagger:response getUserQuotasResponse
+type GetUserQuotasResponse struct {
+	// in:body
+	Body []*user.Quota `json:"body"`
+}
+
 // swagger:response getQuotaResponse
 type GetQuotaResponseResponse struct {
 	// in:body
-	Body []*models.UserQuotaDTO `json:"body"`
+	Body []*models.OrgQuotaDTO `json:"body"`
 }
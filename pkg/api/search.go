@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 // swagger:route GET /search search search
@@ -24,13 +25,16 @@ func (hs *HTTPServer) Search(c *models.ReqContext) response.Response {
 	query := c.Query("query")
 	tags := c.QueryStrings("tag")
 	starred := c.Query("starred")
-	limit := c.QueryInt64("limit")
-	page := c.QueryInt64("page")
 	dashboardType := c.Query("type")
 	sort := c.Query("sort")
 	permission := models.PERMISSION_VIEW
 
-	if limit > 5000 {
+	page, err := pagination.NewPage(c.QueryInt64("page"), c.QueryInt64("limit"), c.Query("cursor"), "")
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
+	if page.PerPage > 5000 {
 		return response.Error(422, "Limit is above maximum allowed (5000), use page parameter to access hits beyond limit", nil)
 	}
 
@@ -68,8 +72,7 @@ func (hs *HTTPServer) Search(c *models.ReqContext) response.Response {
 		Title:         query,
 		Tags:          tags,
 		SignedInUser:  c.SignedInUser,
-		Limit:         limit,
-		Page:          page,
+		Pagination:    page,
 		IsStarred:     starred == "true",
 		OrgId:         c.OrgID,
 		DashboardIds:  dbIDs,
@@ -80,7 +83,7 @@ func (hs *HTTPServer) Search(c *models.ReqContext) response.Response {
 		Sort:          sort,
 	}
 
-	err := hs.SearchService.SearchHandler(c.Req.Context(), &searchQuery)
+	err = hs.SearchService.SearchHandler(c.Req.Context(), &searchQuery)
 	if err != nil {
 		return response.Error(500, "Search failed", err)
 	}
@@ -198,6 +201,10 @@ type SearchParams struct {
 	// in:query
 	// required: false
 	Page int64 `json:"page"`
+	// Use this parameter to continue from a cursor instead of a page number. Takes precedence over page.
+	// in:query
+	// required: false
+	Cursor string `json:"cursor"`
 	// Set to `Edit` to return dashboards/folders that the user can edit
 	// in:query
 	// required: false
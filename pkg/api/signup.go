@@ -94,6 +94,10 @@ func (hs *HTTPServer) SignUpStep2(c *models.ReqContext) response.Response {
 
 	usr, err := hs.Login.CreateUser(createUserCmd)
 	if err != nil {
+		var policyErr *user.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			return passwordPolicyErrorResponse(policyErr)
+		}
 		if errors.Is(err, user.ErrUserAlreadyExists) {
 			return response.Error(401, "User with same email address already exists", nil)
 		}
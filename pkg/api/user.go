@@ -115,6 +115,7 @@ func (hs *HTTPServer) GetUserByLoginOrEmail(c *models.ReqContext) response.Respo
 // 200: okResponse
 // 401: unauthorisedError
 // 403: forbiddenError
+// 409: conflictError
 // 500: internalServerError
 func (hs *HTTPServer) UpdateSignedInUser(c *models.ReqContext) response.Response {
 	cmd := user.UpdateUserCommand{}
@@ -130,6 +131,7 @@ func (hs *HTTPServer) UpdateSignedInUser(c *models.ReqContext) response.Response
 		}
 	}
 	cmd.UserID = c.UserID
+	cmd.UserInitiated = true
 	return hs.handleUpdateUser(c.Req.Context(), cmd)
 }
 
@@ -144,6 +146,7 @@ func (hs *HTTPServer) UpdateSignedInUser(c *models.ReqContext) response.Response
 // 401: unauthorisedError
 // 403: forbiddenError
 // 404: notFoundError
+// 409: conflictError
 // 500: internalServerError
 func (hs *HTTPServer) UpdateUser(c *models.ReqContext) response.Response {
 	cmd := user.UpdateUserCommand{}
@@ -194,6 +197,12 @@ func (hs *HTTPServer) handleUpdateUser(ctx context.Context, cmd user.UpdateUserC
 		if errors.Is(err, user.ErrCaseInsensitive) {
 			return response.Error(http.StatusConflict, "Update would result in user login conflict", err)
 		}
+		if errors.Is(err, user.ErrUserVersionConflict) {
+			return response.Error(http.StatusConflict, "User was changed by someone else, reload it and try again", err)
+		}
+		if errors.Is(err, user.ErrFieldLocked) {
+			return response.Error(http.StatusForbidden, err.Error(), nil)
+		}
 		return response.Error(http.StatusInternalServerError, "Failed to update user", err)
 	}
 
@@ -391,12 +400,12 @@ func (hs *HTTPServer) ChangeUserPassword(c *models.ReqContext) response.Response
 
 	userQuery := user.GetUserByIDQuery{ID: c.UserID}
 
-	user, err := hs.userService.GetByID(c.Req.Context(), &userQuery)
+	usr, err := hs.userService.GetByID(c.Req.Context(), &userQuery)
 	if err != nil {
 		return response.Error(500, "Could not read user from database", err)
 	}
 
-	getAuthQuery := models.GetAuthInfoQuery{UserId: user.ID}
+	getAuthQuery := models.GetAuthInfoQuery{UserId: usr.ID}
 	if err := hs.authInfoService.GetAuthInfo(c.Req.Context(), &getAuthQuery); err == nil {
 		authModule := getAuthQuery.Result.AuthModule
 		if authModule == login.LDAPAuthModule || authModule == login.AuthProxyAuthModule {
@@ -404,29 +413,30 @@ func (hs *HTTPServer) ChangeUserPassword(c *models.ReqContext) response.Response
 		}
 	}
 
-	passwordHashed, err := util.EncodePassword(cmd.OldPassword, user.Salt)
-	if err != nil {
-		return response.Error(500, "Failed to encode password", err)
-	}
-	if passwordHashed != user.Password {
-		return response.Error(401, "Invalid old password", nil)
-	}
-
 	password := models.Password(cmd.NewPassword)
 	if password.IsWeak() {
 		return response.Error(400, "New password is too short", nil)
 	}
 
 	cmd.UserID = c.UserID
-	cmd.NewPassword, err = util.EncodePassword(cmd.NewPassword, user.Salt)
-	if err != nil {
-		return response.Error(500, "Failed to encode password", err)
-	}
-
 	if err := hs.userService.ChangePassword(c.Req.Context(), &cmd); err != nil {
+		var policyErr *user.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			return passwordPolicyErrorResponse(policyErr)
+		}
+		if errors.Is(err, user.ErrInvalidOldPassword) {
+			return response.Error(401, "Invalid old password", nil)
+		}
 		return response.Error(500, "Failed to change user password", err)
 	}
 
+	// the sessions of the user are revoked when its password changes, the current one is replaced by a new one
+	if c.UserToken != nil {
+		if err := hs.loginUserWithUser(usr, c); err != nil {
+			return response.Error(500, "Failed to renew the session of the user", err)
+		}
+	}
+
 	return response.Success("User password changed")
 }
 
@@ -514,10 +524,74 @@ type SearchUsersWithPagingParams struct {
 	// required:false
 	// default:1
 	Page int64 `json:"page"`
+	// Cursor returned as nextCursor by the previous page, takes precedence over page
+	// in:query
+	// required:false
+	Cursor string `json:"cursor"`
+	// Order of the users, sorted by login and email
+	// in:query
+	// required:false
+	// enum: asc,desc
+	Order string `json:"order"`
 	// Query allows return results where the query value is contained in one of the name, login or email fields. Query values with spaces need to be URL encoded e.g. query=Jane%20Doe
 	// in:query
 	// required:false
 	Query string `json:"query"`
+	// Only return users last seen before this time, in epoch milliseconds
+	// in:query
+	// required:false
+	LastSeenBefore int64 `json:"lastSeenBefore"`
+	// Only return users last seen at or after this time, in epoch milliseconds
+	// in:query
+	// required:false
+	LastSeenAfter int64 `json:"lastSeenAfter"`
+	// Only return users whose most recent login was through this auth module, e.g. oauth_okta
+	// in:query
+	// required:false
+	AuthModule string `json:"authModule"`
+	// Only return users with the attribute set to the value, as key:value. Can be repeated to match several attributes
+	// in:query
+	// required:false
+	Attribute []string `json:"attribute"`
+	// Return the organizations of the users and their roles in them
+	// in:query
+	// required:false
+	Orgs bool `json:"orgs"`
+}
+
+// swagger:parameters exportUsers
+type ExportUsersParams struct {
+	// Format of the export
+	// in:query
+	// required:false
+	// enum: csv,json
+	// default:csv
+	Format string `json:"format"`
+	// Order of the users, sorted by login and email
+	// in:query
+	// required:false
+	// enum: asc,desc
+	Order string `json:"order"`
+	// Query allows return results where the query value is contained in one of the name, login or email fields. Query values with spaces need to be URL encoded e.g. query=Jane%20Doe
+	// in:query
+	// required:false
+	Query string `json:"query"`
+	// Only return users last seen before this time, in epoch milliseconds
+	// in:query
+	// required:false
+	LastSeenBefore int64 `json:"lastSeenBefore"`
+	// Only return users last seen at or after this time, in epoch milliseconds
+	// in:query
+	// required:false
+	LastSeenAfter int64 `json:"lastSeenAfter"`
+	// Only return users whose most recent login was through this auth module, e.g. oauth_okta
+	// in:query
+	// required:false
+	AuthModule string `json:"authModule"`
+	// Only return users with the attribute set to the value, as key:value. Can be repeated to match several attributes
+	// in:query
+	// required:false
+	Attribute []string `json:"attribute"`
 }
 
 // swagger:parameters updateSignedInUser
@@ -590,6 +664,13 @@ type UpdateUserParams struct {
 	UserID int64 `json:"user_id"`
 }
 
+// swagger:response exportUsersResponse
+type ExportUsersResponse struct {
+	// The users, as CSV with a header row or as a JSON array
+	// in: body
+	Body []byte `json:"body"`
+}
+
 // swagger:response searchUsersResponse
 type SearchUsersResponse struct {
 	// The response message
@@ -29,6 +29,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/user/userimpl"
 	"github.com/grafana/grafana/pkg/services/user/usertest"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 func TestUserAPIEndpoint_userLoggedIn(t *testing.T) {
@@ -45,7 +46,7 @@ func TestUserAPIEndpoint_userLoggedIn(t *testing.T) {
 			{Name: "user1"},
 			{Name: "user2"},
 		},
-		TotalCount: 2,
+		Result: pagination.Result{TotalCount: 2},
 	}
 	mock := mockstore.NewSQLStoreMock()
 	userMock := usertest.NewUserServiceFake()
@@ -68,7 +69,7 @@ func TestUserAPIEndpoint_userLoggedIn(t *testing.T) {
 		}
 		user, err := sqlStore.CreateUser(context.Background(), createUserCmd)
 		require.Nil(t, err)
-		hs.userService = userimpl.ProvideService(sqlStore, nil, sc.cfg, nil, nil)
+		hs.userService = userimpl.ProvideService(sqlStore, nil, sc.cfg, nil, nil, nil, nil)
 
 		sc.handlerFunc = hs.GetUserByID
 
@@ -12,7 +12,6 @@ import (
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/user"
-	"github.com/grafana/grafana/pkg/util"
 )
 
 const AdminUserId = 1
@@ -40,21 +39,10 @@ func resetPasswordCommand(c utils.CommandLine, runner runner.Runner) error {
 		return fmt.Errorf("new password is too short")
 	}
 
-	userQuery := user.GetUserByIDQuery{ID: AdminUserId}
-
-	usr, err := runner.UserService.GetByID(context.Background(), &userQuery)
-	if err != nil {
-		return fmt.Errorf("could not read user from database. Error: %v", err)
-	}
-
-	passwordHashed, err := util.EncodePassword(newPassword, usr.Salt)
-	if err != nil {
-		return err
-	}
-
 	cmd := user.ChangeUserPasswordCommand{
 		UserID:      AdminUserId,
-		NewPassword: passwordHashed,
+		NewPassword: newPassword,
+		IsReset:     true,
 	}
 
 	if err := runner.UserService.ChangePassword(context.Background(), &cmd); err != nil {
@@ -96,6 +96,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	publicdashboardsApi "github.com/grafana/grafana/pkg/services/publicdashboards/api"
 	publicdashboardsStore "github.com/grafana/grafana/pkg/services/publicdashboards/database"
+	publicdashboardsFaults "github.com/grafana/grafana/pkg/services/publicdashboards/faults"
 	publicdashboardsService "github.com/grafana/grafana/pkg/services/publicdashboards/service"
 	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
@@ -125,6 +126,7 @@ import (
 	teamguardianManager "github.com/grafana/grafana/pkg/services/teamguardian/manager"
 	"github.com/grafana/grafana/pkg/services/thumbs"
 	"github.com/grafana/grafana/pkg/services/updatechecker"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/services/user/userimpl"
 	"github.com/grafana/grafana/pkg/services/userauth/userauthimpl"
 	"github.com/grafana/grafana/pkg/setting"
@@ -316,10 +318,25 @@ var wireSet = wire.NewSet(
 	dashverimpl.ProvideService,
 	publicdashboardsService.ProvideService,
 	wire.Bind(new(publicdashboards.Service), new(*publicdashboardsService.PublicDashboardServiceImpl)),
+	wire.Bind(new(publicdashboards.QueryDataExecutor), new(*query.Service)),
+	publicdashboardsFaults.ProvideInjector,
+	publicdashboardsService.ProvideGeoIPResolver,
+	wire.Bind(new(publicdashboards.GeoIPResolver), new(*publicdashboardsService.CIDRGeoIPResolver)),
 	publicdashboardsStore.ProvideStore,
 	wire.Bind(new(publicdashboards.Store), new(*publicdashboardsStore.PublicDashboardStoreImpl)),
+	publicdashboardsStore.ProvidePlaylistStore,
+	wire.Bind(new(publicdashboards.PlaylistStore), new(*publicdashboardsStore.PublicPlaylistStoreImpl)),
+	publicdashboardsStore.ProvideFolderStore,
+	wire.Bind(new(publicdashboards.FolderStore), new(*publicdashboardsStore.PublicFolderStoreImpl)),
+	publicdashboardsStore.ProvideEmailSessionStore,
+	wire.Bind(new(publicdashboards.EmailSessionStore), new(*publicdashboardsStore.EmailSessionStoreImpl)),
+	publicdashboardsStore.ProvideReportStore,
+	wire.Bind(new(publicdashboards.ReportStore), new(*publicdashboardsStore.ReportStoreImpl)),
+	publicdashboardsService.ProvideLastUsedTracker,
+	publicdashboardsService.ProvideUsageTracker,
 	publicdashboardsApi.ProvideApi,
 	userimpl.ProvideService,
+	wire.Bind(new(user.Service), new(*userimpl.Service)),
 	orgimpl.ProvideService,
 	teamimpl.ProvideService,
 	userauthimpl.ProvideService,
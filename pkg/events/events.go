@@ -27,6 +27,37 @@ type UserCreated struct {
 	Email     string    `json:"email"`
 }
 
+// UserDeleted is published when a user is deleted from the instance
+type UserDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	Id        int64     `json:"id"`
+}
+
+// UsersDeleted is published once when users are deleted in a batch
+type UsersDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	Ids       []int64   `json:"ids"`
+}
+
+// UsersDisabled is published once when users are disabled in a batch
+type UsersDisabled struct {
+	Timestamp time.Time `json:"timestamp"`
+	Ids       []int64   `json:"ids"`
+}
+
+// OrgUserUpdated is published when a user is added to an organization, removed from it, or its role changes
+type OrgUserUpdated struct {
+	Timestamp time.Time `json:"timestamp"`
+	OrgId     int64     `json:"orgId"`
+	UserId    int64     `json:"userId"`
+}
+
+// UserPasswordChanged is published when the password of a user changes, the sessions of the user are revoked
+type UserPasswordChanged struct {
+	Timestamp time.Time `json:"timestamp"`
+	Id        int64     `json:"id"`
+}
+
 type SignUpStarted struct {
 	Timestamp time.Time `json:"timestamp"`
 	Email     string    `json:"email"`
@@ -71,6 +102,16 @@ type DataSourceCreated struct {
 	OrgID     int64     `json:"org_id"`
 }
 
+// DashboardFolderChanged is published when a dashboard is moved to another folder. Folder IDs are 0 for the
+// General folder
+type DashboardFolderChanged struct {
+	Timestamp    time.Time `json:"timestamp"`
+	OrgID        int64     `json:"org_id"`
+	DashboardUID string    `json:"dashboard_uid"`
+	OldFolderID  int64     `json:"old_folder_id"`
+	NewFolderID  int64     `json:"new_folder_id"`
+}
+
 type FolderTitleUpdated struct {
 	Timestamp time.Time `json:"timestamp"`
 	Title     string    `json:"name"`
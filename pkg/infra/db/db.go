@@ -15,15 +15,22 @@ type DB interface {
 	WithTransactionalDbSession(ctx context.Context, callback sqlstore.DBTransactionFunc) error
 	WithDbSession(ctx context.Context, callback sqlstore.DBTransactionFunc) error
 	WithNewDbSession(ctx context.Context, callback sqlstore.DBTransactionFunc) error
+	WithReplicaSession(ctx context.Context, callback sqlstore.DBTransactionFunc) error
 	GetDialect() migrator.Dialect
 	GetDBType() core.DbType
 	GetSqlxSession() *session.SessionDB
+	GetPoolStats() sqlstore.PoolStats
+	RegisterPartitionedTable(table sqlstore.PartitionedTable)
+	RotatePartitions(ctx context.Context) error
 	InTransaction(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type Session = sqlstore.DBSession
 type SQLBuilder = sqlstore.SQLBuilder
 type InitTestDBOpt = sqlstore.InitTestDBOpt
+type BulkOpSettings = sqlstore.BulkOpSettings
+type PoolStats = sqlstore.PoolStats
+type PartitionedTable = sqlstore.PartitionedTable
 
 var InitTestDB = sqlstore.InitTestDB
 var InitTestDBwithCfg = sqlstore.InitTestDBWithCfg
@@ -12,7 +12,8 @@ import (
 )
 
 type FakeDB struct {
-	ExpectedError error
+	ExpectedError     error
+	ExpectedPoolStats sqlstore.PoolStats
 }
 
 func NewFakeDB() *FakeDB {
@@ -31,6 +32,10 @@ func (f *FakeDB) WithNewDbSession(ctx context.Context, callback sqlstore.DBTrans
 	return f.ExpectedError
 }
 
+func (f *FakeDB) WithReplicaSession(ctx context.Context, callback sqlstore.DBTransactionFunc) error {
+	return f.ExpectedError
+}
+
 func (f *FakeDB) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
 	return f.ExpectedError
 }
@@ -47,6 +52,16 @@ func (f *FakeDB) GetSqlxSession() *session.SessionDB {
 	return nil
 }
 
+func (f *FakeDB) GetPoolStats() sqlstore.PoolStats {
+	return f.ExpectedPoolStats
+}
+
+func (f *FakeDB) RegisterPartitionedTable(table sqlstore.PartitionedTable) {}
+
+func (f *FakeDB) RotatePartitions(ctx context.Context) error {
+	return f.ExpectedError
+}
+
 // TODO: service-specific methods not yet split out ; to be removed
 func (f *FakeDB) UpdateTempUserWithEmailSent(ctx context.Context, cmd *models.UpdateTempUserWithEmailSentCommand) error {
 	return f.ExpectedError
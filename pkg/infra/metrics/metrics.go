@@ -108,6 +108,9 @@ var (
 
 	// MPublicDashboardDatasourceQuerySuccess is a metric counter for successful queries labelled by datasource
 	MPublicDashboardDatasourceQuerySuccess *prometheus.CounterVec
+
+	// MInactiveUsersDeactivated is a metric counter for users disabled after being inactive
+	MInactiveUsersDeactivated prometheus.Counter
 )
 
 // Timers
@@ -429,6 +432,12 @@ func init() {
 		Namespace: ExporterName,
 	}, []string{"datasource", "status"}, map[string][]string{"status": pubdash.QueryResultStatuses})
 
+	MInactiveUsersDeactivated = metricutil.NewCounterStartingAtZero(prometheus.CounterOpts{
+		Name:      "inactive_users_deactivated_total",
+		Help:      "counter for users disabled after being inactive",
+		Namespace: ExporterName,
+	})
+
 	MStatTotalDashboards = prometheus.NewGauge(prometheus.GaugeOpts{
 		Name:      "stat_totals_dashboard",
 		Help:      "total amount of dashboards",
@@ -675,5 +684,6 @@ func initMetricVars() {
 		MStatTotalPublicDashboards,
 		MPublicDashboardRequestCount,
 		MPublicDashboardDatasourceQuerySuccess,
+		MInactiveUsersDeactivated,
 	)
 }
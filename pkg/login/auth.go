@@ -21,6 +21,7 @@ var (
 	ErrTooManyLoginAttempts  = errors.New("too many consecutive incorrect login attempts for user - login for user temporarily blocked")
 	ErrPasswordEmpty         = errors.New("no password provided")
 	ErrUserDisabled          = errors.New("user is disabled")
+	ErrUserLocked            = errors.New("user is locked after too many failed logins")
 	ErrAbsoluteRedirectTo    = errors.New("absolute URLs are not allowed for redirect_to cookie value")
 	ErrInvalidRedirectTo     = errors.New("invalid redirect_to cookie value")
 	ErrForbiddenRedirectTo   = errors.New("forbidden redirect_to cookie value")
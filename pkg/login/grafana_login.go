@@ -3,6 +3,7 @@ package login
 import (
 	"context"
 	"crypto/subtle"
+	"errors"
 
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -33,9 +34,33 @@ var loginUsingGrafanaDB = func(ctx context.Context, query *models.LoginUserQuery
 		return ErrUserDisabled
 	}
 
+	locked, err := userService.IsLocked(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return ErrUserLocked
+	}
+
 	if err := validatePassword(query.Password, user.Password, user.Salt); err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			if err := userService.RecordFailedLogin(ctx, user.ID); err != nil {
+				loginLogger.Error("Failed to record failed login", "userId", user.ID, "err", err)
+			}
+		}
 		return err
 	}
+
+	// checked once the password is validated, so it does not tell whether users exist
+	if err := userService.CheckEmailVerified(ctx, user); err != nil {
+		return err
+	}
+
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := userService.ResetLockout(ctx, user.ID); err != nil {
+			loginLogger.Error("Failed to reset failed logins", "userId", user.ID, "err", err)
+		}
+	}
 	query.User = user
 	return nil
 }
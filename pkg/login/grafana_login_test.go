@@ -54,6 +54,26 @@ func TestLoginUsingGrafanaDB(t *testing.T) {
 		assert.False(t, sc.validatePasswordCalled)
 		assert.Nil(t, sc.loginUserQuery.User)
 	})
+
+	grafanaLoginScenario(t, "When login with locked user", func(sc *grafanaLoginScenarioContext) {
+		sc.withValidCredentials()
+		sc.userService.ExpectedLocked = true
+		err := loginUsingGrafanaDB(context.Background(), sc.loginUserQuery, sc.userService)
+		require.ErrorIs(t, err, ErrUserLocked)
+
+		assert.False(t, sc.validatePasswordCalled)
+		assert.Nil(t, sc.loginUserQuery.User)
+	})
+
+	grafanaLoginScenario(t, "When login with unverified email address", func(sc *grafanaLoginScenarioContext) {
+		sc.withValidCredentials()
+		sc.userService.ExpectedEmailNotVerified = true
+		err := loginUsingGrafanaDB(context.Background(), sc.loginUserQuery, sc.userService)
+		require.ErrorIs(t, err, user.ErrEmailNotVerified)
+
+		assert.True(t, sc.validatePasswordCalled)
+		assert.Nil(t, sc.loginUserQuery.User)
+	})
 }
 
 type grafanaLoginScenarioContext struct {
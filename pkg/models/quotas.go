@@ -30,13 +30,6 @@ type OrgQuotaDTO struct {
 	Used   int64  `json:"used"`
 }
 
-type UserQuotaDTO struct {
-	UserId int64  `json:"user_id"`
-	Target string `json:"target"`
-	Limit  int64  `json:"limit"`
-	Used   int64  `json:"used"`
-}
-
 type GlobalQuotaDTO struct {
 	Target string `json:"target"`
 	Limit  int64  `json:"limit"`
@@ -57,20 +50,6 @@ type GetOrgQuotasQuery struct {
 	Result                 []*OrgQuotaDTO
 }
 
-type GetUserQuotaByTargetQuery struct {
-	Target                 string
-	UserId                 int64
-	Default                int64
-	UnifiedAlertingEnabled bool
-	Result                 *UserQuotaDTO
-}
-
-type GetUserQuotasQuery struct {
-	UserId                 int64
-	UnifiedAlertingEnabled bool
-	Result                 []*UserQuotaDTO
-}
-
 type GetGlobalQuotaByTargetQuery struct {
 	Target                 string
 	Default                int64
@@ -5,6 +5,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 type SortOption struct {
@@ -30,8 +31,7 @@ type FindPersistedDashboardsQuery struct {
 	Type          string
 	FolderIds     []int64
 	Tags          []string
-	Limit         int64
-	Page          int64
+	Pagination    pagination.Page
 	Permission    PermissionType
 	Sort          SortOption
 
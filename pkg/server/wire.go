@@ -103,6 +103,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	publicdashboardsApi "github.com/grafana/grafana/pkg/services/publicdashboards/api"
 	publicdashboardsStore "github.com/grafana/grafana/pkg/services/publicdashboards/database"
+	publicdashboardsFaults "github.com/grafana/grafana/pkg/services/publicdashboards/faults"
 	publicdashboardsService "github.com/grafana/grafana/pkg/services/publicdashboards/service"
 	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
@@ -141,6 +142,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/thumbs"
 	"github.com/grafana/grafana/pkg/services/thumbs/dashboardthumbsimpl"
 	"github.com/grafana/grafana/pkg/services/updatechecker"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/services/user/userimpl"
 	"github.com/grafana/grafana/pkg/services/userauth/userauthimpl"
 	"github.com/grafana/grafana/pkg/setting"
@@ -346,10 +348,26 @@ var wireBasicSet = wire.NewSet(
 	dashverimpl.ProvideService,
 	publicdashboardsService.ProvideService,
 	wire.Bind(new(publicdashboards.Service), new(*publicdashboardsService.PublicDashboardServiceImpl)),
+	publicdashboardsFaults.ProvideInjector,
+	publicdashboardsFaults.ProvideQueryDataExecutor,
+	publicdashboardsService.ProvideGeoIPResolver,
+	wire.Bind(new(publicdashboards.GeoIPResolver), new(*publicdashboardsService.CIDRGeoIPResolver)),
 	publicdashboardsStore.ProvideStore,
-	wire.Bind(new(publicdashboards.Store), new(*publicdashboardsStore.PublicDashboardStoreImpl)),
+	publicdashboardsFaults.ProvideStore,
+	publicdashboardsStore.ProvidePlaylistStore,
+	wire.Bind(new(publicdashboards.PlaylistStore), new(*publicdashboardsStore.PublicPlaylistStoreImpl)),
+	publicdashboardsStore.ProvideFolderStore,
+	wire.Bind(new(publicdashboards.FolderStore), new(*publicdashboardsStore.PublicFolderStoreImpl)),
+	publicdashboardsStore.ProvideEmailSessionStore,
+	wire.Bind(new(publicdashboards.EmailSessionStore), new(*publicdashboardsStore.EmailSessionStoreImpl)),
+	publicdashboardsStore.ProvideReportStore,
+	wire.Bind(new(publicdashboards.ReportStore), new(*publicdashboardsStore.ReportStoreImpl)),
+	publicdashboardsService.ProvideReportDigestService,
+	publicdashboardsService.ProvideLastUsedTracker,
+	publicdashboardsService.ProvideUsageTracker,
 	publicdashboardsApi.ProvideApi,
 	userimpl.ProvideService,
+	wire.Bind(new(user.Service), new(*userimpl.Service)),
 	orgimpl.ProvideService,
 	grpccontext.ProvideContextHandler,
 	grpcserver.ProvideService,
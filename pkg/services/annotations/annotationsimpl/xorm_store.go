@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/tag"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 var timeNow = time.Now
@@ -243,12 +244,11 @@ func (r *xormRepositoryImpl) Get(ctx context.Context, query *annotations.ItemQue
 			params = append(params, acArgs...)
 		}
 
-		if query.Limit == 0 {
-			query.Limit = 100
-		}
+		page := query.Pagination()
+		order := page.Order.OrDefault(pagination.SortDescending).SQL()
 
 		// order of ORDER BY arguments match the order of a sql index for performance
-		sql.WriteString(" ORDER BY a.org_id, a.epoch_end DESC, a.epoch DESC" + r.db.GetDialect().Limit(query.Limit) + " ) dt on dt.id = annotation.id")
+		sql.WriteString(" ORDER BY a.org_id, a.epoch_end " + order + ", a.epoch " + order + r.db.GetDialect().LimitOffset(page.PerPage, page.Offset()) + " ) dt on dt.id = annotation.id")
 		if err := sess.SQL(sql.String(), params...).Find(&items); err != nil {
 			items = nil
 			return err
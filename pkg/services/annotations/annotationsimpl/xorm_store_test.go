@@ -23,6 +23,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 func TestIntegrationAnnotations(t *testing.T) {
@@ -174,6 +175,29 @@ func TestIntegrationAnnotations(t *testing.T) {
 			assert.Equal(t, annotation2.Id, items[0].Id)
 		})
 
+		t.Run("Can page through annotations sorted by time", func(t *testing.T) {
+			items, err := repo.Get(context.Background(), &annotations.ItemQuery{
+				OrgId:        1,
+				Limit:        1,
+				Page:         2,
+				Order:        pagination.SortAscending,
+				SignedInUser: testUser,
+			})
+			require.NoError(t, err)
+			require.Len(t, items, 1)
+			assert.Equal(t, organizationAnnotation1.Id, items[0].Id)
+
+			items, err = repo.Get(context.Background(), &annotations.ItemQuery{
+				OrgId:        1,
+				Limit:        2,
+				Cursor:       pagination.NewCursor(1),
+				SignedInUser: testUser,
+			})
+			require.NoError(t, err)
+			require.Len(t, items, 2)
+			assert.ElementsMatch(t, []int64{globalAnnotation2.Id, organizationAnnotation1.Id}, []int64{items[0].Id, items[1].Id})
+		})
+
 		t.Run("Should not find any when item is outside time range", func(t *testing.T) {
 			items, err := repo.Get(context.Background(), &annotations.ItemQuery{
 				OrgId:        1,
@@ -3,6 +3,7 @@ package annotations
 import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 type ItemQuery struct {
@@ -21,6 +22,16 @@ type ItemQuery struct {
 	SignedInUser *user.SignedInUser
 
 	Limit int64 `json:"limit"`
+	// Page, Cursor and Order page through the annotations, Limit being the size of the pages. Annotations are sorted
+	// by time, most recent first by default
+	Page   int64                `json:"page"`
+	Cursor pagination.Cursor    `json:"cursor"`
+	Order  pagination.SortOrder `json:"order"`
+}
+
+// Pagination returns the page of annotations selected by the query, of 100 annotations unless a limit is set
+func (q *ItemQuery) Pagination() pagination.Page {
+	return pagination.Page{Page: q.Page, PerPage: q.Limit, Cursor: q.Cursor, Order: q.Order}.WithDefaults(100, 0)
 }
 
 // TagsQuery is the query for a tags search.
@@ -108,6 +108,7 @@ func (ss *sqlStore) AddAPIKey(ctx context.Context, cmd *apikey.AddCommand) error
 			Expires:          expires,
 			ServiceAccountId: cmd.ServiceAccountID,
 			IsRevoked:        &isRevoked,
+			CreatedBy:        cmd.UserID,
 		}
 
 		if _, err := sess.Insert(&t); err != nil {
@@ -27,6 +27,7 @@ type APIKey struct {
 	Expires          *int64       `db:"expires"`
 	ServiceAccountId *int64       `db:"service_account_id"`
 	IsRevoked        *bool        `xorm:"is_revoked" db:"is_revoked"`
+	CreatedBy        int64        `xorm:"created_by" db:"created_by"`
 }
 
 func (k APIKey) TableName() string { return "api_key" }
@@ -39,6 +40,7 @@ type AddCommand struct {
 	Key              string       `json:"-"`
 	SecondsToLive    int64        `json:"secondsToLive"`
 	ServiceAccountID *int64       `json:"-"`
+	UserID           int64        `json:"-"`
 
 	Result *APIKey `json:"-"`
 }
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/serverlock"
@@ -24,13 +26,15 @@ var getTime = time.Now
 const urgentRotateTime = 1 * time.Minute
 
 func ProvideUserAuthTokenService(sqlStore db.DB, serverLockService *serverlock.ServerLockService,
-	cfg *setting.Cfg) *UserAuthTokenService {
+	cfg *setting.Cfg, bus bus.Bus) *UserAuthTokenService {
 	s := &UserAuthTokenService{
 		SQLStore:          sqlStore,
 		ServerLockService: serverLockService,
 		Cfg:               cfg,
 		log:               log.New("auth"),
 	}
+	bus.AddEventListener(s.handleUsersDisabled)
+	bus.AddEventListener(s.handleUserPasswordChanged)
 	return s
 }
 
@@ -361,6 +365,16 @@ func (s *UserAuthTokenService) RevokeAllUserTokens(ctx context.Context, userId i
 	})
 }
 
+// handleUsersDisabled revokes the sessions of disabled users
+func (s *UserAuthTokenService) handleUsersDisabled(ctx context.Context, e *events.UsersDisabled) error {
+	return s.BatchRevokeAllUserTokens(ctx, e.Ids)
+}
+
+// handleUserPasswordChanged revokes the sessions of users whose password changed
+func (s *UserAuthTokenService) handleUserPasswordChanged(ctx context.Context, e *events.UserPasswordChanged) error {
+	return s.RevokeAllUserTokens(ctx, e.Id)
+}
+
 func (s *UserAuthTokenService) BatchRevokeAllUserTokens(ctx context.Context, userIds []int64) error {
 	return s.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *db.Session) error {
 		if len(userIds) == 0 {
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
@@ -160,6 +161,36 @@ func TestUserAuthToken(t *testing.T) {
 				}
 			})
 		})
+
+		t.Run("When users are disabled", func(t *testing.T) {
+			t.Run("Revokes their tokens", func(t *testing.T) {
+				_, err := ctx.tokenService.CreateToken(context.Background(), user,
+					net.ParseIP("192.168.10.11"), "some user agent")
+				require.Nil(t, err)
+
+				err = ctx.tokenService.handleUsersDisabled(context.Background(), &events.UsersDisabled{Ids: []int64{user.ID}})
+				require.Nil(t, err)
+
+				tokens, err := ctx.tokenService.GetUserTokens(context.Background(), user.ID)
+				require.Nil(t, err)
+				require.Equal(t, 0, len(tokens))
+			})
+		})
+
+		t.Run("When the password of a user changes", func(t *testing.T) {
+			t.Run("Revokes its tokens", func(t *testing.T) {
+				_, err := ctx.tokenService.CreateToken(context.Background(), user,
+					net.ParseIP("192.168.10.11"), "some user agent")
+				require.Nil(t, err)
+
+				err = ctx.tokenService.handleUserPasswordChanged(context.Background(), &events.UserPasswordChanged{Id: user.ID})
+				require.Nil(t, err)
+
+				tokens, err := ctx.tokenService.GetUserTokens(context.Background(), user.ID)
+				require.Nil(t, err)
+				require.Equal(t, 0, len(tokens))
+			})
+		})
 	})
 
 	t.Run("expires correctly", func(t *testing.T) {
@@ -25,13 +25,15 @@ import (
 	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	tempuser "github.com/grafana/grafana/pkg/services/temp_user"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
 	shortURLService shorturls.Service, sqlstore db.DB, queryHistoryService queryhistory.Service,
 	dashboardVersionService dashver.Service, dashSnapSvc dashboardsnapshots.Service, deleteExpiredImageService *image.DeleteExpiredService,
-	loginAttemptService loginattempt.Service, tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner) *CleanUpService {
+	loginAttemptService loginattempt.Service, tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner,
+	userService user.Service) *CleanUpService {
 	s := &CleanUpService{
 		Cfg:                       cfg,
 		ServerLockService:         serverLockService,
@@ -46,6 +48,7 @@ func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockSe
 		tempUserService:           tempUserService,
 		tracer:                    tracer,
 		annotationCleaner:         annotationCleaner,
+		userService:               userService,
 	}
 	return s
 }
@@ -64,6 +67,7 @@ type CleanUpService struct {
 	loginAttemptService       loginattempt.Service
 	tempUserService           tempuser.Service
 	annotationCleaner         annotations.Cleaner
+	userService               user.Service
 }
 
 type cleanUpJob struct {
@@ -107,6 +111,10 @@ func (srv *CleanUpService) clean(ctx context.Context) {
 		{"delete stale short URLs", srv.deleteStaleShortURLs},
 		{"delete stale query history", srv.deleteStaleQueryHistory},
 		{"delete old login attempts", srv.deleteOldLoginAttempts},
+		{"purge deleted users", srv.purgeDeletedUsers},
+		{"purge login history", srv.purgeLoginHistory},
+		{"deactivate inactive users", srv.deactivateInactiveUsers},
+		{"rotate table partitions", srv.rotateTablePartitions},
 	}
 
 	logger := srv.log.FromContext(ctx)
@@ -269,6 +277,98 @@ func (srv *CleanUpService) expireOldUserInvites(ctx context.Context) {
 	}
 }
 
+func (srv *CleanUpService) purgeDeletedUsers(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	err := srv.ServerLockService.LockAndExecute(ctx, "purge deleted users",
+		time.Minute*10, func(context.Context) {
+			srv.purgeDeletedUsersWithoutLock(ctx)
+		})
+	if err != nil {
+		logger.Error("failed to lock and execute purge of deleted users", "error", err)
+	}
+}
+
+func (srv *CleanUpService) purgeDeletedUsersWithoutLock(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	cmd := user.PurgeDeletedUsersCommand{
+		OlderThan: time.Now().Add(-srv.Cfg.DeletedUserRetention),
+	}
+	if err := srv.userService.PurgeDeletedUsers(ctx, &cmd); err != nil {
+		logger.Error("Problem purging deleted users", "error", err.Error())
+	} else {
+		logger.Debug("Purged deleted users", "users purged", cmd.PurgedUsers)
+	}
+}
+
+func (srv *CleanUpService) rotateTablePartitions(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	err := srv.ServerLockService.LockAndExecute(ctx, "rotate table partitions",
+		time.Minute*10, func(context.Context) {
+			if err := srv.store.RotatePartitions(ctx); err != nil {
+				logger.Error("Problem rotating table partitions", "error", err)
+			}
+		})
+	if err != nil {
+		logger.Error("failed to lock and execute rotation of table partitions", "error", err)
+	}
+}
+
+func (srv *CleanUpService) purgeLoginHistory(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	if srv.Cfg.LoginHistoryRetention == 0 {
+		return
+	}
+
+	err := srv.ServerLockService.LockAndExecute(ctx, "purge login history",
+		time.Minute*10, func(context.Context) {
+			srv.purgeLoginHistoryWithoutLock(ctx)
+		})
+	if err != nil {
+		logger.Error("failed to lock and execute purge of login history", "error", err)
+	}
+}
+
+func (srv *CleanUpService) purgeLoginHistoryWithoutLock(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	cmd := user.PurgeLoginHistoryCommand{
+		OlderThan: time.Now().Add(-srv.Cfg.LoginHistoryRetention),
+	}
+	if err := srv.userService.PurgeLoginHistory(ctx, &cmd); err != nil {
+		logger.Error("Problem purging login history", "error", err.Error())
+	} else {
+		logger.Debug("Purged login history", "logins purged", cmd.PurgedLogins)
+	}
+}
+
+func (srv *CleanUpService) deactivateInactiveUsers(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	if srv.Cfg.InactiveUserDeactivationThreshold == 0 {
+		return
+	}
+
+	err := srv.ServerLockService.LockAndExecute(ctx, "deactivate inactive users",
+		time.Minute*10, func(context.Context) {
+			srv.deactivateInactiveUsersWithoutLock(ctx)
+		})
+	if err != nil {
+		logger.Error("failed to lock and execute deactivation of inactive users", "error", err)
+	}
+}
+
+func (srv *CleanUpService) deactivateInactiveUsersWithoutLock(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	cmd := user.DeactivateInactiveUsersCommand{
+		InactiveSince: time.Now().Add(-srv.Cfg.InactiveUserDeactivationThreshold),
+		Exclusions:    srv.Cfg.InactiveUserDeactivationExclusions,
+		DryRun:        srv.Cfg.InactiveUserDeactivationDryRun,
+	}
+	if err := srv.userService.DeactivateInactiveUsers(ctx, &cmd); err != nil {
+		logger.Error("Problem deactivating inactive users", "error", err.Error())
+	} else {
+		logger.Debug("Deactivated inactive users", "users deactivated", len(cmd.DeactivatedUsers), "dry run", cmd.DryRun)
+	}
+}
+
 func (srv *CleanUpService) deleteStaleShortURLs(ctx context.Context) {
 	logger := srv.log.FromContext(ctx)
 	cmd := models.DeleteShortUrlCommand{
@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/services/comments/commentmodel"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 func commentsToDto(items []*commentmodel.Comment, userMap map[int64]*commentmodel.CommentUser) []*commentmodel.CommentDto {
@@ -149,8 +150,7 @@ func (s *Service) Get(ctx context.Context, orgID int64, signedInUser *user.Signe
 	// NOTE: probably replace with comment and user table join.
 	query := &user.SearchUsersQuery{
 		Query:        "",
-		Page:         0,
-		Limit:        len(userIds),
+		Pagination:   pagination.Page{PerPage: int64(len(userIds))},
 		SignedInUser: signedInUser,
 		Filters:      []user.Filter{NewIDFilter(userIds)},
 	}
@@ -171,8 +171,8 @@ func (h *ContextHandler) Middleware(next http.Handler) http.Handler {
 				{Num: reqContext.UserID}},
 		)
 
-		// update last seen every 5min
-		if reqContext.ShouldUpdateLastSeenAt() {
+		// update last seen every last_seen_update_interval
+		if reqContext.ShouldUpdateLastSeenAt(h.Cfg.UserLastSeenUpdateInterval) {
 			reqContext.Logger.Debug("Updating last user_seen_at", "user_id", reqContext.UserID)
 			if err := h.userService.UpdateLastSeenAt(mContext.Req.Context(), &user.UpdateUserLastSeenAtCommand{UserID: reqContext.UserID}); err != nil {
 				reqContext.Logger.Error("Failed to update last_seen_at", "error", err)
@@ -15,7 +15,9 @@ type DashboardService interface {
 	FindDashboards(ctx context.Context, query *models.FindPersistedDashboardsQuery) ([]DashboardSearchProjection, error)
 	GetDashboard(ctx context.Context, query *models.GetDashboardQuery) error
 	GetDashboardACLInfoList(ctx context.Context, query *models.GetDashboardACLInfoListQuery) error
+	GetDashboardMeta(ctx context.Context, orgID int64, uid string) (*DashboardMeta, error)
 	GetDashboards(ctx context.Context, query *models.GetDashboardsQuery) error
+	GetDashboardsByUIDs(ctx context.Context, query *GetDashboardsByUIDsQuery) (*GetDashboardsByUIDsResult, error)
 	GetDashboardTags(ctx context.Context, query *models.GetDashboardTagsQuery) error
 	GetDashboardUIDById(ctx context.Context, query *models.GetDashboardRefByIdQuery) error
 	HasAdminPermissionInDashboardsOrFolders(ctx context.Context, query *models.HasAdminPermissionInDashboardsOrFoldersQuery) error
@@ -56,8 +58,12 @@ type Store interface {
 	FindDashboards(ctx context.Context, query *models.FindPersistedDashboardsQuery) ([]DashboardSearchProjection, error)
 	GetDashboard(ctx context.Context, query *models.GetDashboardQuery) (*models.Dashboard, error)
 	GetDashboardACLInfoList(ctx context.Context, query *models.GetDashboardACLInfoListQuery) error
+	// GetDashboardMeta retrieves the metadata of a dashboard without loading its JSON data.
+	GetDashboardMeta(ctx context.Context, orgID int64, uid string) (*DashboardMeta, error)
 	GetDashboardUIDById(ctx context.Context, query *models.GetDashboardRefByIdQuery) error
 	GetDashboards(ctx context.Context, query *models.GetDashboardsQuery) error
+	// GetDashboardsByUIDs retrieves the dashboards of an org identified by UID in a single query.
+	GetDashboardsByUIDs(ctx context.Context, query *GetDashboardsByUIDsQuery) (*GetDashboardsByUIDsResult, error)
 	// GetDashboardsByPluginID retrieves dashboards identified by plugin.
 	GetDashboardsByPluginID(ctx context.Context, query *models.GetDashboardsByPluginIdQuery) error
 	GetDashboardTags(ctx context.Context, query *models.GetDashboardTagsQuery) error
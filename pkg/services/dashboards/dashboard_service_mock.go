@@ -105,6 +105,29 @@ func (_m *FakeDashboardService) GetDashboardACLInfoList(ctx context.Context, que
 	return r0
 }
 
+// GetDashboardMeta provides a mock function with given fields: ctx, orgID, uid
+func (_m *FakeDashboardService) GetDashboardMeta(ctx context.Context, orgID int64, uid string) (*DashboardMeta, error) {
+	ret := _m.Called(ctx, orgID, uid)
+
+	var r0 *DashboardMeta
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) *DashboardMeta); ok {
+		r0 = rf(ctx, orgID, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DashboardMeta)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, orgID, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDashboardTags provides a mock function with given fields: ctx, query
 func (_m *FakeDashboardService) GetDashboardTags(ctx context.Context, query *models.GetDashboardTagsQuery) error {
 	ret := _m.Called(ctx, query)
@@ -147,6 +170,29 @@ func (_m *FakeDashboardService) GetDashboards(ctx context.Context, query *models
 	return r0
 }
 
+// GetDashboardsByUIDs provides a mock function with given fields: ctx, query
+func (_m *FakeDashboardService) GetDashboardsByUIDs(ctx context.Context, query *GetDashboardsByUIDsQuery) (*GetDashboardsByUIDsResult, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 *GetDashboardsByUIDsResult
+	if rf, ok := ret.Get(0).(func(context.Context, *GetDashboardsByUIDsQuery) *GetDashboardsByUIDsResult); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*GetDashboardsByUIDsResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *GetDashboardsByUIDsQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // HasAdminPermissionInDashboardsOrFolders provides a mock function with given fields: ctx, query
 func (_m *FakeDashboardService) HasAdminPermissionInDashboardsOrFolders(ctx context.Context, query *models.HasAdminPermissionInDashboardsOrFoldersQuery) error {
 	ret := _m.Called(ctx, query)
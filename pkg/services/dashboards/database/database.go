@@ -8,6 +8,7 @@ import (
 
 	"xorm.io/xorm"
 
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
@@ -421,6 +422,7 @@ func saveDashboard(sess *db.Session, cmd *models.SaveDashboardCommand, emitEntit
 		userId = -1
 	}
 
+	var folderChanged *events.DashboardFolderChanged
 	if dash.Id > 0 {
 		var existing models.Dashboard
 		dashWithIdExists, err := sess.Where("id=? AND org_id=?", dash.Id, dash.OrgId).Get(&existing)
@@ -431,6 +433,15 @@ func saveDashboard(sess *db.Session, cmd *models.SaveDashboardCommand, emitEntit
 			return dashboards.ErrDashboardNotFound
 		}
 
+		if !dash.IsFolder && dash.FolderId != existing.FolderId {
+			folderChanged = &events.DashboardFolderChanged{
+				OrgID:        dash.OrgId,
+				DashboardUID: existing.Uid,
+				OldFolderID:  existing.FolderId,
+				NewFolderID:  dash.FolderId,
+			}
+		}
+
 		// check for is someone else has written in between
 		if dash.Version != existing.Version {
 			if cmd.Overwrite {
@@ -523,6 +534,11 @@ func saveDashboard(sess *db.Session, cmd *models.SaveDashboardCommand, emitEntit
 
 	cmd.Result = dash
 
+	if folderChanged != nil {
+		folderChanged.Timestamp = dash.Updated
+		sess.PublishAfterCommit(folderChanged)
+	}
+
 	if emitEntityEvent {
 		_, err := sess.Insert(createEntityEvent(dash, store.EntityEventTypeUpdate))
 		if err != nil {
@@ -883,6 +899,47 @@ func (d *DashboardStore) GetDashboard(ctx context.Context, query *models.GetDash
 	return query.Result, err
 }
 
+func (d *DashboardStore) GetDashboardMeta(ctx context.Context, orgID int64, uid string) (*dashboards.DashboardMeta, error) {
+	if uid == "" {
+		return nil, dashboards.ErrDashboardIdentifierNotSet
+	}
+
+	var meta dashboards.DashboardMeta
+	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		rawSQL := `SELECT
+			dashboard.id,
+			dashboard.uid,
+			dashboard.org_id,
+			dashboard.title,
+			dashboard.slug,
+			dashboard.is_folder,
+			dashboard.folder_id,
+			folder.uid AS folder_uid,
+			folder.title AS folder_title,
+			dashboard.version,
+			dashboard.created,
+			dashboard.updated
+		FROM dashboard
+		LEFT OUTER JOIN dashboard AS folder ON folder.id = dashboard.folder_id
+		WHERE dashboard.org_id = ? AND dashboard.uid = ?`
+
+		exists, err := sess.SQL(rawSQL, orgID, uid).Get(&meta)
+		if err != nil {
+			return err
+		} else if !exists {
+			return dashboards.ErrDashboardNotFound
+		}
+
+		meta.Tags = make([]string, 0)
+		return sess.Table("dashboard_tag").Where("dashboard_id = ?", meta.ID).Cols("term").OrderBy("term").Find(&meta.Tags)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &meta, nil
+}
+
 func (d *DashboardStore) GetDashboardUIDById(ctx context.Context, query *models.GetDashboardRefByIdQuery) error {
 	return d.store.WithDbSession(ctx, func(sess *db.Session) error {
 		var rawSQL = `SELECT uid, slug from dashboard WHERE Id=?`
@@ -977,27 +1034,78 @@ func (d *DashboardStore) FindDashboards(ctx context.Context, query *models.FindP
 	var res []dashboards.DashboardSearchProjection
 	sb := &searchstore.Builder{Dialect: d.store.GetDialect(), Filters: filters}
 
-	limit := query.Limit
-	if limit < 1 {
-		limit = 1000
+	sql, params := sb.ToSQL(query.Pagination.WithDefaults(1000, 0))
+
+	err := d.store.WithReplicaSession(ctx, func(sess *db.Session) error {
+		return sess.SQL(sql, params...).Find(&res)
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
-	page := query.Page
-	if page < 1 {
-		page = 1
+	return res, nil
+}
+
+// dashboardUIDsBatchSize is the number of dashboard UIDs fetched per query, it keeps the IN clause below the limit of
+// 999 variables per statement of SQLite
+const dashboardUIDsBatchSize = 500
+
+func (d *DashboardStore) GetDashboardsByUIDs(ctx context.Context, query *dashboards.GetDashboardsByUIDsQuery) (*dashboards.GetDashboardsByUIDsResult, error) {
+	result := &dashboards.GetDashboardsByUIDsResult{
+		Dashboards: make(map[string]*models.Dashboard, len(query.UIDs)),
+		Errors:     make(map[string]error),
 	}
 
-	sql, params := sb.ToSQL(limit, page)
+	uids := make([]string, 0, len(query.UIDs))
+	for _, uid := range query.UIDs {
+		if uid == "" {
+			result.Errors[uid] = dashboards.ErrDashboardIdentifierNotSet
+			continue
+		}
+		uids = append(uids, uid)
+	}
+	if len(uids) == 0 {
+		return result, nil
+	}
 
 	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
-		return sess.SQL(sql, params...).Find(&res)
-	})
+		for start := 0; start < len(uids); start += dashboardUIDsBatchSize {
+			end := start + dashboardUIDsBatchSize
+			if end > len(uids) {
+				end = len(uids)
+			}
 
+			var found = make([]*models.Dashboard, 0, end-start)
+			session := sess.Where("org_id = ?", query.OrgID).In("uid", uids[start:end])
+			if !query.WithData {
+				session = session.Omit("data")
+			}
+			if err := session.Find(&found); err != nil {
+				return err
+			}
+
+			for _, dashboard := range found {
+				if query.WithData {
+					dashboard.SetId(dashboard.Id)
+					dashboard.SetUid(dashboard.Uid)
+				}
+				result.Dashboards[dashboard.Uid] = dashboard
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return res, nil
+	for _, uid := range uids {
+		if _, ok := result.Dashboards[uid]; !ok {
+			result.Errors[uid] = dashboards.ErrDashboardNotFound
+		}
+	}
+
+	return result, nil
 }
 
 func (d *DashboardStore) GetDashboardTags(ctx context.Context, query *models.GetDashboardTagsQuery) error {
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -26,6 +27,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 func TestIntegrationDashboardDataAccess(t *testing.T) {
@@ -120,6 +122,107 @@ func TestIntegrationDashboardDataAccess(t *testing.T) {
 		require.False(t, query.Result.IsFolder)
 	})
 
+	t.Run("Should be able to get dashboard meta by uid", func(t *testing.T) {
+		setup()
+		meta, err := dashboardStore.GetDashboardMeta(context.Background(), 1, savedDash.Uid)
+		require.NoError(t, err)
+
+		require.Equal(t, savedDash.Id, meta.ID)
+		require.Equal(t, savedDash.Uid, meta.UID)
+		require.Equal(t, int64(1), meta.OrgID)
+		require.Equal(t, "test dash 23", meta.Title)
+		require.Equal(t, "test-dash-23", meta.Slug)
+		require.False(t, meta.IsFolder)
+		require.Equal(t, savedFolder.Id, meta.FolderID)
+		require.Equal(t, savedFolder.Uid, meta.FolderUID)
+		require.Equal(t, savedFolder.Title, meta.FolderTitle)
+		require.Equal(t, []string{"prod", "webapp"}, meta.Tags)
+		require.Equal(t, savedDash.Version, meta.Version)
+		require.False(t, meta.Updated.IsZero())
+	})
+
+	t.Run("Should get dashboard meta of a dashboard in the general folder", func(t *testing.T) {
+		setup()
+		meta, err := dashboardStore.GetDashboardMeta(context.Background(), 1, savedDash2.Uid)
+		require.NoError(t, err)
+
+		require.Equal(t, "test dash 67", meta.Title)
+		require.EqualValues(t, 0, meta.FolderID)
+		require.Empty(t, meta.FolderUID)
+		require.Equal(t, []string{"prod"}, meta.Tags)
+	})
+
+	t.Run("Should return not found error when getting dashboard meta of a dashboard in another org", func(t *testing.T) {
+		setup()
+		_, err := dashboardStore.GetDashboardMeta(context.Background(), 2, savedDash.Uid)
+		require.ErrorIs(t, err, dashboards.ErrDashboardNotFound)
+	})
+
+	t.Run("Should be able to get dashboards by uids with per uid errors", func(t *testing.T) {
+		setup()
+		result, err := dashboardStore.GetDashboardsByUIDs(context.Background(), &dashboards.GetDashboardsByUIDsQuery{
+			OrgID:    1,
+			UIDs:     []string{savedDash.Uid, savedDash2.Uid, "unknown", ""},
+			WithData: true,
+		})
+		require.NoError(t, err)
+
+		require.Len(t, result.Dashboards, 2)
+		require.Equal(t, "test dash 23", result.Dashboards[savedDash.Uid].Title)
+		require.Equal(t, savedDash.Uid, result.Dashboards[savedDash.Uid].Data.Get("uid").MustString())
+		require.Equal(t, "test dash 67", result.Dashboards[savedDash2.Uid].Title)
+
+		require.Len(t, result.Errors, 2)
+		require.ErrorIs(t, result.Errors["unknown"], dashboards.ErrDashboardNotFound)
+		require.ErrorIs(t, result.Errors[""], dashboards.ErrDashboardIdentifierNotSet)
+	})
+
+	t.Run("Should not load dashboard data when getting dashboards by uids without data", func(t *testing.T) {
+		setup()
+		result, err := dashboardStore.GetDashboardsByUIDs(context.Background(), &dashboards.GetDashboardsByUIDsQuery{
+			OrgID: 1,
+			UIDs:  []string{savedDash.Uid},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, result.Dashboards, 1)
+		require.Equal(t, "test dash 23", result.Dashboards[savedDash.Uid].Title)
+		require.Nil(t, result.Dashboards[savedDash.Uid].Data)
+		require.Empty(t, result.Errors)
+	})
+
+	t.Run("Should not get dashboards by uids of another org", func(t *testing.T) {
+		setup()
+		result, err := dashboardStore.GetDashboardsByUIDs(context.Background(), &dashboards.GetDashboardsByUIDsQuery{
+			OrgID: 2,
+			UIDs:  []string{savedDash.Uid},
+		})
+		require.NoError(t, err)
+
+		require.Empty(t, result.Dashboards)
+		require.ErrorIs(t, result.Errors[savedDash.Uid], dashboards.ErrDashboardNotFound)
+	})
+
+	t.Run("Should be able to get dashboards by more uids than fit in a single query", func(t *testing.T) {
+		setup()
+		uids := []string{savedDash.Uid}
+		for i := 0; i < 1200; i++ {
+			uids = append(uids, fmt.Sprintf("missing-%d", i))
+		}
+		uids = append(uids, savedDash2.Uid)
+
+		result, err := dashboardStore.GetDashboardsByUIDs(context.Background(), &dashboards.GetDashboardsByUIDsQuery{
+			OrgID: 1,
+			UIDs:  uids,
+		})
+		require.NoError(t, err)
+
+		require.Len(t, result.Dashboards, 2)
+		require.Contains(t, result.Dashboards, savedDash.Uid)
+		require.Contains(t, result.Dashboards, savedDash2.Uid)
+		require.Len(t, result.Errors, 1200)
+	})
+
 	t.Run("Should be able to get a dashboard UID by ID", func(t *testing.T) {
 		setup()
 		query := models.GetDashboardRefByIdQuery{Id: savedDash.Id}
@@ -225,6 +328,38 @@ func TestIntegrationDashboardDataAccess(t *testing.T) {
 		require.False(t, query.Result.Updated.IsZero())
 	})
 
+	t.Run("Should publish an event when a dashboard is moved to another folder", func(t *testing.T) {
+		setup()
+		var published []*events.DashboardFolderChanged
+		sqlStore.Bus().AddEventListener(func(ctx context.Context, e *events.DashboardFolderChanged) error {
+			published = append(published, e)
+			return nil
+		})
+
+		save := func(folderId int64) {
+			_, err := dashboardStore.SaveDashboard(context.Background(), models.SaveDashboardCommand{
+				OrgId: 1,
+				Dashboard: simplejson.NewFromAny(map[string]interface{}{
+					"id":    savedDash2.Id,
+					"title": savedDash2.Title,
+				}),
+				FolderId:  folderId,
+				Overwrite: true,
+			})
+			require.NoError(t, err)
+		}
+
+		save(savedFolder.Id)
+		// saving without moving does not publish anything
+		save(savedFolder.Id)
+
+		require.Len(t, published, 1)
+		require.Equal(t, int64(1), published[0].OrgID)
+		require.Equal(t, savedDash2.Uid, published[0].DashboardUID)
+		require.Equal(t, int64(0), published[0].OldFolderID)
+		require.Equal(t, savedFolder.Id, published[0].NewFolderID)
+	})
+
 	t.Run("Should be able to delete empty folder", func(t *testing.T) {
 		setup()
 		emptyFolder := insertTestDashboard(t, dashboardStore, "2 test dash folder", 1, 0, true, "prod", "webapp")
@@ -411,8 +546,8 @@ func TestIntegrationDashboardDataAccess(t *testing.T) {
 	t.Run("Should be able to limit find results", func(t *testing.T) {
 		setup()
 		query := models.FindPersistedDashboardsQuery{
-			OrgId: 1,
-			Limit: 1,
+			OrgId:      1,
+			Pagination: pagination.Page{PerPage: 1},
 			SignedInUser: &user.SignedInUser{
 				OrgID:   1,
 				OrgRole: org.RoleEditor,
@@ -432,9 +567,8 @@ func TestIntegrationDashboardDataAccess(t *testing.T) {
 	t.Run("Should be able to find results beyond limit using paging", func(t *testing.T) {
 		setup()
 		query := models.FindPersistedDashboardsQuery{
-			OrgId: 1,
-			Limit: 1,
-			Page:  2,
+			OrgId:      1,
+			Pagination: pagination.Page{Page: 2, PerPage: 1},
 			SignedInUser: &user.SignedInUser{
 				OrgID:   1,
 				OrgRole: org.RoleEditor,
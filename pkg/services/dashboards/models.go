@@ -29,3 +29,35 @@ type DashboardSearchProjection struct {
 	FolderTitle string
 	SortMeta    int64
 }
+
+// DashboardMeta is the metadata of a dashboard, without its JSON data.
+type DashboardMeta struct {
+	ID          int64  `xorm:"id"`
+	UID         string `xorm:"uid"`
+	OrgID       int64  `xorm:"org_id"`
+	Title       string
+	Slug        string
+	IsFolder    bool
+	FolderID    int64  `xorm:"folder_id"`
+	FolderUID   string `xorm:"folder_uid"`
+	FolderTitle string
+	Tags        []string `xorm:"-"`
+	Version     int
+	Created     time.Time
+	Updated     time.Time
+}
+
+// GetDashboardsByUIDsQuery is used to fetch many dashboards of an org at once.
+// The JSON data of the dashboards is only loaded when WithData is set.
+type GetDashboardsByUIDsQuery struct {
+	OrgID    int64
+	UIDs     []string
+	WithData bool
+}
+
+// GetDashboardsByUIDsResult holds the dashboards found by UID, and the error of
+// every UID that could not be fetched.
+type GetDashboardsByUIDsResult struct {
+	Dashboards map[string]*models.Dashboard
+	Errors     map[string]error
+}
@@ -496,6 +496,14 @@ func (dr *DashboardServiceImpl) GetDashboard(ctx context.Context, query *models.
 	return err
 }
 
+func (dr *DashboardServiceImpl) GetDashboardMeta(ctx context.Context, orgID int64, uid string) (*dashboards.DashboardMeta, error) {
+	return dr.dashboardStore.GetDashboardMeta(ctx, orgID, uid)
+}
+
+func (dr *DashboardServiceImpl) GetDashboardsByUIDs(ctx context.Context, query *dashboards.GetDashboardsByUIDsQuery) (*dashboards.GetDashboardsByUIDsResult, error) {
+	return dr.dashboardStore.GetDashboardsByUIDs(ctx, query)
+}
+
 func (dr *DashboardServiceImpl) GetDashboardUIDById(ctx context.Context, query *models.GetDashboardRefByIdQuery) error {
 	return dr.dashboardStore.GetDashboardUIDById(ctx, query)
 }
@@ -2,7 +2,6 @@ package dashverimpl
 
 import (
 	"context"
-	"strings"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
@@ -58,14 +57,8 @@ func (ss *sqlStore) GetBatch(ctx context.Context, cmd *dashver.DeleteExpiredVers
 func (ss *sqlStore) DeleteBatch(ctx context.Context, cmd *dashver.DeleteExpiredVersionsCommand, versionIdsToDelete []interface{}) (int64, error) {
 	var deleted int64
 	err := ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
-		deleteExpiredSQL := `DELETE FROM dashboard_version WHERE id IN (?` + strings.Repeat(",?", len(versionIdsToDelete)-1) + `)`
-		sqlOrArgs := append([]interface{}{deleteExpiredSQL}, versionIdsToDelete...)
-		expiredResponse, err := sess.Exec(sqlOrArgs...)
-		if err != nil {
-			return err
-		}
-
-		deleted, err = expiredResponse.RowsAffected()
+		var err error
+		deleted, err = sess.BulkDelete("dashboard_version", "id", versionIdsToDelete, db.BulkOpSettings{})
 		return err
 	})
 	return deleted, err
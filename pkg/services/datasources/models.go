@@ -53,6 +53,8 @@ type DataSource struct {
 	SecureJsonData    map[string][]byte `json:"secureJsonData"`
 	ReadOnly          bool              `json:"readOnly"`
 	Uid               string            `json:"uid"`
+	// swagger:ignore
+	CreatedBy int64 `json:"-"`
 
 	Created time.Time `json:"created,omitempty"`
 	Updated time.Time `json:"updated,omitempty"`
@@ -211,6 +211,7 @@ func (ss *SqlStore) AddDataSource(ctx context.Context, cmd *datasources.AddDataS
 			Version:         1,
 			ReadOnly:        cmd.ReadOnly,
 			Uid:             cmd.Uid,
+			CreatedBy:       cmd.UserId,
 		}
 
 		if _, err := sess.Insert(ds); err != nil {
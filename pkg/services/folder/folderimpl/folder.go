@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 type Service struct {
@@ -62,11 +63,10 @@ func (s *Service) GetFolders(ctx context.Context, user *user.SignedInUser, orgID
 		SignedInUser: user,
 		DashboardIds: make([]int64, 0),
 		FolderIds:    make([]int64, 0),
-		Limit:        limit,
+		Pagination:   pagination.Page{Page: page, PerPage: limit},
 		OrgId:        orgID,
 		Type:         "dash-folder",
 		Permission:   models.PERMISSION_VIEW,
-		Page:         page,
 	}
 
 	if err := s.searchService.SearchHandler(ctx, &searchQuery); err != nil {
@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 var (
@@ -25,14 +26,16 @@ func ProvideService(
 	authInfoService login.AuthInfoService,
 	accessControl accesscontrol.Service,
 	orgService org.Service,
+	cfg *setting.Cfg,
 ) *Implementation {
 	s := &Implementation{
-		SQLStore:        sqlStore,
-		userService:     userService,
-		QuotaService:    quotaService,
-		AuthInfoService: authInfoService,
-		accessControl:   accessControl,
-		orgService:      orgService,
+		SQLStore:         sqlStore,
+		userService:      userService,
+		QuotaService:     quotaService,
+		AuthInfoService:  authInfoService,
+		accessControl:    accessControl,
+		orgService:       orgService,
+		lockSyncedFields: cfg.LockSyncedProfileFields,
 	}
 	return s
 }
@@ -45,6 +48,8 @@ type Implementation struct {
 	TeamSync        login.TeamSyncFunc
 	accessControl   accesscontrol.Service
 	orgService      org.Service
+	// lockSyncedFields locks the profile fields provided by external logins, so users can't change them
+	lockSyncedFields bool
 }
 
 // CreateUser creates inserts a new one.
@@ -142,6 +147,12 @@ func (ls *Implementation) UpsertUser(ctx context.Context, cmd *models.UpsertUser
 		}
 	}
 
+	if ls.lockSyncedFields {
+		if errLock := ls.syncFieldLocks(ctx, cmd.Result, extUser); errLock != nil {
+			return errLock
+		}
+	}
+
 	if errSyncRole := ls.syncOrgRoles(ctx, cmd.Result, extUser); errSyncRole != nil {
 		return errSyncRole
 	}
@@ -218,9 +229,10 @@ func (ls *Implementation) createUser(extUser *models.ExternalUserInfo) (*user.Us
 }
 
 func (ls *Implementation) updateUser(ctx context.Context, usr *user.User, extUser *models.ExternalUserInfo) error {
-	// sync user info
+	// sync user info, unless the user was changed since it was read
 	updateCmd := &user.UpdateUserCommand{
-		UserID: usr.ID,
+		UserID:  usr.ID,
+		Version: usr.Version,
 	}
 
 	needsUpdate := false
@@ -250,6 +262,22 @@ func (ls *Implementation) updateUser(ctx context.Context, usr *user.User, extUse
 	return ls.userService.Update(ctx, updateCmd)
 }
 
+// syncFieldLocks locks the profile fields of a user provided by its external login, and unlocks the others
+func (ls *Implementation) syncFieldLocks(ctx context.Context, usr *user.User, extUser *models.ExternalUserInfo) error {
+	lockCmd := &user.SetUserFieldLocksCommand{
+		UserID: usr.ID,
+		Name:   extUser.Name != "",
+		Email:  extUser.Email != "",
+		Login:  extUser.Login != "",
+	}
+	if lockCmd.Name == usr.NameLocked && lockCmd.Email == usr.EmailLocked && lockCmd.Login == usr.LoginLocked {
+		return nil
+	}
+
+	logger.Debug("Syncing user field locks", "id", usr.ID, "locks", lockCmd)
+	return ls.userService.SetFieldLocks(ctx, lockCmd)
+}
+
 func (ls *Implementation) updateUserAuth(ctx context.Context, user *user.User, extUser *models.ExternalUserInfo) error {
 	updateCmd := &models.UpdateAuthInfoCommand{
 		AuthModule: extUser.AuthModule,
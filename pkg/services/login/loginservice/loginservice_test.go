@@ -114,6 +114,31 @@ func Test_teamSync(t *testing.T) {
 	})
 }
 
+func Test_syncFieldLocks(t *testing.T) {
+	authInfoMock := &logintest.AuthInfoServiceFake{}
+	userService := usertest.NewUserServiceFake()
+	login := Implementation{
+		QuotaService:     &quotaimpl.Service{},
+		AuthInfoService:  authInfoMock,
+		userService:      userService,
+		lockSyncedFields: true,
+	}
+
+	email := "test_user@example.org"
+	upsertCmd := &models.UpsertUserCommand{ExternalUser: &models.ExternalUserInfo{Email: email, Login: "test_user"},
+		UserLookupParams: models.UserLookupParams{Email: &email}}
+	authInfoMock.ExpectedUser = &user.User{ID: 1, Email: email, Name: "test_user", Login: "test_user"}
+
+	require.NoError(t, login.UpsertUser(context.Background(), upsertCmd))
+	require.Len(t, userService.FieldLocks, 1)
+	assert.Equal(t, &user.SetUserFieldLocksCommand{UserID: 1, Email: true, Login: true}, userService.FieldLocks[0])
+
+	// locks which are already set are left alone
+	authInfoMock.ExpectedUser = &user.User{ID: 1, Email: email, Name: "test_user", Login: "test_user", EmailLocked: true, LoginLocked: true}
+	require.NoError(t, login.UpsertUser(context.Background(), upsertCmd))
+	require.Len(t, userService.FieldLocks, 1)
+}
+
 func createSimpleUser() user.User {
 	user := user.User{
 		ID: 1,
@@ -292,7 +292,6 @@ func (st DBstore) GetUserVisibleNamespaces(ctx context.Context, orgID int64, use
 		OrgId:        orgID,
 		SignedInUser: user,
 		Type:         searchstore.TypeAlertFolder,
-		Limit:        -1,
 		Permission:   models.PERMISSION_VIEW,
 		Sort:         models.SortOption{},
 		Filters: []interface{}{
@@ -303,7 +302,7 @@ func (st DBstore) GetUserVisibleNamespaces(ctx context.Context, orgID int64, use
 	var page int64 = 1
 	for {
 		query := searchQuery
-		query.Page = page
+		query.Pagination.Page = page
 		proj, err := st.DashboardService.FindDashboards(ctx, &query)
 		if err != nil {
 			return nil, err
@@ -376,6 +376,12 @@ func (ss *sqlStore) AddOrgUser(ctx context.Context, cmd *org.AddOrgUserCommand)
 			return err
 		}
 
+		sess.PublishAfterCommit(&events.OrgUserUpdated{
+			Timestamp: time.Now(),
+			OrgId:     cmd.OrgID,
+			UserId:    cmd.UserID,
+		})
+
 		var userOrgs []*org.UserOrgDTO
 		sess.Table("org_user")
 		sess.Join("INNER", "org", "org_user.org_id=org.id")
@@ -424,6 +430,12 @@ func (ss *sqlStore) UpdateOrgUser(ctx context.Context, cmd *org.UpdateOrgUserCom
 			return err
 		}
 
+		sess.PublishAfterCommit(&events.OrgUserUpdated{
+			Timestamp: time.Now(),
+			OrgId:     cmd.OrgID,
+			UserId:    cmd.UserID,
+		})
+
 		return validateOneAdminLeftInOrg(cmd.OrgID, sess)
 	})
 }
@@ -462,6 +474,9 @@ func (ss *sqlStore) GetOrgUsers(ctx context.Context, query *org.GetOrgUsersQuery
 		whereConditions = append(whereConditions, fmt.Sprintf("%s.is_service_account = ?", ss.dialect.Quote("user")))
 		whereParams = append(whereParams, ss.dialect.BooleanStr(false))
 
+		// soft deleted users keep their memberships until they are purged
+		whereConditions = append(whereConditions, fmt.Sprintf("%s.deleted_at IS NULL", ss.dialect.Quote("user")))
+
 		if query.User == nil {
 			ss.log.Warn("Query user not set for filtering.")
 		}
@@ -553,6 +568,7 @@ func (ss *sqlStore) SearchOrgUsers(ctx context.Context, query *org.SearchOrgUser
 		whereParams = append(whereParams, query.OrgID)
 
 		whereConditions = append(whereConditions, fmt.Sprintf("%s.is_service_account = %s", ss.dialect.Quote("user"), ss.dialect.BooleanStr(false)))
+		whereConditions = append(whereConditions, fmt.Sprintf("%s.deleted_at IS NULL", ss.dialect.Quote("user")))
 
 		if !accesscontrol.IsDisabled(ss.cfg) {
 			acFilter, err := accesscontrol.Filter(query.User, "org_user.user_id", "users:id:", accesscontrol.ActionOrgUsersRead)
@@ -668,6 +684,12 @@ func (ss *sqlStore) RemoveOrgUser(ctx context.Context, cmd *org.RemoveOrgUserCom
 			return err
 		}
 
+		sess.PublishAfterCommit(&events.OrgUserUpdated{
+			Timestamp: time.Now(),
+			OrgId:     cmd.OrgID,
+			UserId:    cmd.UserID,
+		})
+
 		// check user other orgs and update user current org
 		var userOrgs []*models.UserOrgDTO
 		sess.Table("org_user")
@@ -731,7 +753,15 @@ func (ss *sqlStore) deleteUserInTransaction(sess *db.Session, cmd *models.Delete
 		}
 	}
 
-	return deleteUserAccessControl(sess, cmd.UserId)
+	if err := deleteUserAccessControl(sess, cmd.UserId); err != nil {
+		return err
+	}
+
+	sess.PublishAfterCommit(&events.UserDeleted{
+		Timestamp: time.Now(),
+		Id:        usr.ID,
+	})
+	return nil
 }
 
 func deleteUserAccessControl(sess *db.Session, userID int64) error {
@@ -785,6 +815,10 @@ func (ss *sqlStore) userDeletions() []string {
 		"DELETE FROM user_auth WHERE user_id = ?",
 		"DELETE FROM user_auth_token WHERE user_id = ?",
 		"DELETE FROM quota WHERE user_id = ?",
+		"DELETE FROM user_attribute WHERE user_id = ?",
+		"DELETE FROM user_password_history WHERE user_id = ?",
+		"DELETE FROM user_email_verification WHERE user_id = ?",
+		"DELETE FROM user_label WHERE user_id = ?",
 	}
 	return deletes
 }
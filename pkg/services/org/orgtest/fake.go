@@ -20,6 +20,9 @@ type FakeOrgService struct {
 	ExpectedOrgUsers             []*org.OrgUserDTO
 	ExpectedSearchOrgUsersResult *org.SearchOrgUsersQueryResult
 	ExpectedOrgListResponse      OrgListResponse
+
+	// InsertedOrgUsers are the memberships inserted with InsertOrgUser
+	InsertedOrgUsers []*org.OrgUser
 }
 
 func NewOrgServiceFake() *FakeOrgService {
@@ -35,6 +38,7 @@ func (f *FakeOrgService) Insert(ctx context.Context, cmd *org.OrgUser) (int64, e
 }
 
 func (f *FakeOrgService) InsertOrgUser(ctx context.Context, cmd *org.OrgUser) (int64, error) {
+	f.InsertedOrgUsers = append(f.InsertedOrgUsers, cmd)
 	return f.ExpectedOrgUserID, f.ExpectedError
 }
 
@@ -3,23 +3,32 @@ package api
 import (
 	"context"
 	"errors"
+	"net"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/middleware/cookies"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/faults"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/internal/tokens"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/pagination"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -28,7 +37,10 @@ type Api struct {
 	RouteRegister          routing.RouteRegister
 	AccessControl          accesscontrol.AccessControl
 	Features               *featuremgmt.FeatureManager
+	Tracer                 tracing.Tracer
+	Faults                 *faults.Injector
 	Log                    log.Logger
+	TrustedProxies         []*net.IPNet
 }
 
 func ProvideApi(
@@ -36,13 +48,20 @@ func ProvideApi(
 	rr routing.RouteRegister,
 	ac accesscontrol.AccessControl,
 	features *featuremgmt.FeatureManager,
+	tracer tracing.Tracer,
+	faultInjector *faults.Injector,
+	cfg *setting.Cfg,
 ) *Api {
+	logger := log.New("publicdashboards.api")
 	api := &Api{
 		PublicDashboardService: pd,
 		RouteRegister:          rr,
 		AccessControl:          ac,
 		Features:               features,
-		Log:                    log.New("publicdashboards.api"),
+		Tracer:                 tracer,
+		Faults:                 faultInjector,
+		Log:                    logger,
+		TrustedProxies:         parseTrustedProxies(logger, cfg.PublicDashboards.GeoIPTrustedProxies),
 	}
 
 	// attach api if PublicDashboards feature flag is enabled
@@ -62,13 +81,34 @@ func (api *Api) RegisterAPIEndpoints() {
 	// circular dependency
 
 	// public endpoints
-	api.RouteRegister.Get("/api/public/dashboards/:accessToken", routing.Wrap(api.GetPublicDashboard))
-	api.RouteRegister.Post("/api/public/dashboards/:accessToken/panels/:panelId/query", routing.Wrap(api.QueryPublicDashboard))
-	api.RouteRegister.Get("/api/public/dashboards/:accessToken/annotations", routing.Wrap(api.GetAnnotations))
+	requiresEmailSession := RequiresEmailSession(api.PublicDashboardService)
+	setViewerIP := SetViewerIPOnContext(api.TrustedProxies)
+	api.RouteRegister.Get("/api/public/dashboards/:accessToken", setViewerIP, requiresEmailSession, routing.Wrap(api.GetPublicDashboard))
+	api.RouteRegister.Post("/api/public/dashboards/:accessToken/panels/:panelId/query", setViewerIP, requiresEmailSession, routing.Wrap(api.QueryPublicDashboard))
+	api.RouteRegister.Get("/api/public/dashboards/:accessToken/annotations", setViewerIP, requiresEmailSession, routing.Wrap(api.GetAnnotations))
+	api.RouteRegister.Post("/api/public/dashboards/:accessToken/magic-link", setViewerIP, routing.Wrap(api.RequestPublicDashboardMagicLink))
+	api.RouteRegister.Post("/api/public/dashboards/:accessToken/session", setViewerIP, routing.Wrap(api.CreatePublicDashboardSession))
+	api.RouteRegister.Get("/api/public/playlists/:accessToken", setViewerIP, routing.Wrap(api.GetPublicPlaylist))
+	api.RouteRegister.Get("/api/public/folders/:accessToken", setViewerIP, routing.Wrap(api.GetPublicFolder))
 
 	// List Public Dashboards
 	api.RouteRegister.Get("/api/dashboards/public", middleware.ReqSignedIn, routing.Wrap(api.ListPublicDashboards))
 
+	// List Public Dashboards of all orgs
+	api.RouteRegister.Get("/api/admin/dashboards/public", middleware.ReqGrafanaAdmin, routing.Wrap(api.ListAllPublicDashboards))
+
+	// List recent public dashboard query executions
+	api.RouteRegister.Get("/api/admin/dashboards/public/queries", middleware.ReqGrafanaAdmin, routing.Wrap(api.ListPublicDashboardQueryExecutions))
+
+	// Fault injection, only available in the development environment
+	if api.Faults != nil {
+		api.RouteRegister.Group("/api/admin/dashboards/public/faults", func(faultRoute routing.RouteRegister) {
+			faultRoute.Get("/", middleware.ReqGrafanaAdmin, routing.Wrap(api.GetPublicDashboardFaults))
+			faultRoute.Put("/", middleware.ReqGrafanaAdmin, routing.Wrap(api.SetPublicDashboardFaults))
+			faultRoute.Delete("/", middleware.ReqGrafanaAdmin, routing.Wrap(api.ResetPublicDashboardFaults))
+		})
+	}
+
 	// Create/Update Public Dashboard
 	uidScope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(accesscontrol.Parameter(":uid"))
 	api.RouteRegister.Get("/api/dashboards/uid/:uid/public-config",
@@ -78,6 +118,36 @@ func (api *Api) RegisterAPIEndpoints() {
 	api.RouteRegister.Post("/api/dashboards/uid/:uid/public-config",
 		auth(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
 		routing.Wrap(api.SavePublicDashboardConfig))
+
+	api.RouteRegister.Post("/api/dashboards/uid/:uid/public-config/owner",
+		auth(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.TransferPublicDashboardOwnership))
+
+	// Public Playlists
+	api.RouteRegister.Group("/api/dashboards/public/playlists", func(playlistRoute routing.RouteRegister) {
+		playlistRoute.Get("/", middleware.ReqSignedIn, routing.Wrap(api.ListPublicPlaylists))
+		playlistRoute.Get("/:uid", middleware.ReqSignedIn, routing.Wrap(api.GetPublicPlaylistConfig))
+		playlistRoute.Post("/", auth(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite)), routing.Wrap(api.CreatePublicPlaylist))
+		playlistRoute.Put("/:uid", auth(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite)), routing.Wrap(api.UpdatePublicPlaylist))
+		playlistRoute.Delete("/:uid", auth(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite)), routing.Wrap(api.DeletePublicPlaylist))
+	})
+
+	// Report subscriptions of public dashboard owners
+	api.RouteRegister.Group("/api/dashboards/public/report-subscription", func(reportRoute routing.RouteRegister) {
+		reportRoute.Get("/", middleware.ReqSignedIn, routing.Wrap(api.GetPublicDashboardReportSubscription))
+		reportRoute.Put("/", middleware.ReqSignedIn, routing.Wrap(api.SavePublicDashboardReportSubscription))
+		reportRoute.Delete("/", middleware.ReqSignedIn, routing.Wrap(api.DeletePublicDashboardReportSubscription))
+	})
+
+	// Public Folders
+	folderUidScope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(accesscontrol.Parameter(":uid"))
+	api.RouteRegister.Get("/api/folders/:uid/public-config",
+		auth(middleware.ReqSignedIn, accesscontrol.EvalPermission(dashboards.ActionFoldersRead, folderUidScope)),
+		routing.Wrap(api.GetPublicFolderConfig))
+
+	api.RouteRegister.Post("/api/folders/:uid/public-config",
+		auth(middleware.ReqOrgAdmin, accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite)),
+		routing.Wrap(api.SavePublicFolderConfig))
 }
 
 // GetPublicDashboard Gets public dashboard
@@ -97,6 +167,8 @@ func (api *Api) GetPublicDashboard(c *models.ReqContext) response.Response {
 		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "GetPublicDashboard: failed to get public dashboard", err)
 	}
 
+	api.PublicDashboardService.RecordView(c.Req.Context(), pubdash, !c.IsSignedIn)
+
 	meta := dtos.DashboardMeta{
 		Slug:                       dash.Slug,
 		Type:                       models.DashTypeDB,
@@ -116,19 +188,76 @@ func (api *Api) GetPublicDashboard(c *models.ReqContext) response.Response {
 
 	dto := dtos.DashboardFullWithMeta{Meta: meta, Dashboard: dash.Data}
 
+	setCacheControl(c, pubdash.CacheControl())
+
 	return response.JSON(http.StatusOK, dto)
 }
 
 // ListPublicDashboards Gets list of public dashboards for an org
 // GET /api/dashboards/public
 func (api *Api) ListPublicDashboards(c *models.ReqContext) response.Response {
-	resp, err := api.PublicDashboardService.FindAll(c.Req.Context(), c.SignedInUser, c.OrgID)
+	page, err := pagination.NewPage(c.QueryInt64("page"), c.QueryInt64("perpage"), c.Query("cursor"), "")
+	if err != nil {
+		return api.handleError(c.Req.Context(), http.StatusBadRequest, "ListPublicDashboards: invalid pagination", err)
+	}
+
+	resp, err := api.PublicDashboardService.FindAll(c.Req.Context(), c.SignedInUser, c.OrgID, page.WithDefaults(0, 0))
 	if err != nil {
 		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "ListPublicDashboards: failed to list public dashboards", err)
 	}
 	return response.JSON(http.StatusOK, resp)
 }
 
+// ListAllPublicDashboards Gets list of public dashboards across all orgs
+// GET /api/admin/dashboards/public
+func (api *Api) ListAllPublicDashboards(c *models.ReqContext) response.Response {
+	resp, err := api.PublicDashboardService.FindAllGlobal(c.Req.Context())
+	if err != nil {
+		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "ListAllPublicDashboards: failed to list public dashboards", err)
+	}
+	return response.JSON(http.StatusOK, resp)
+}
+
+// ListPublicDashboardQueryExecutions Gets the recent public dashboard query executions of this instance,
+// optionally filtered by request ID
+// GET /api/admin/dashboards/public/queries
+func (api *Api) ListPublicDashboardQueryExecutions(c *models.ReqContext) response.Response {
+	resp, err := api.PublicDashboardService.FindQueryExecutions(c.Req.Context(), c.Query("requestId"))
+	if err != nil {
+		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "ListPublicDashboardQueryExecutions: failed to list query executions", err)
+	}
+	return response.JSON(http.StatusOK, resp)
+}
+
+// GetPublicDashboardFaults Gets the faults injected in the public dashboard store and query layers
+// GET /api/admin/dashboards/public/faults
+func (api *Api) GetPublicDashboardFaults(c *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, api.Faults.Config())
+}
+
+// SetPublicDashboardFaults Sets the faults injected in the public dashboard store and query layers
+// PUT /api/admin/dashboards/public/faults
+func (api *Api) SetPublicDashboardFaults(c *models.ReqContext) response.Response {
+	config := faults.Config{}
+	if err := web.Bind(c.Req, &config); err != nil {
+		return response.Error(http.StatusBadRequest, "SetPublicDashboardFaults: bad request data", err)
+	}
+
+	if err := api.Faults.SetConfig(config); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
+	api.Log.FromContext(c.Req.Context()).Warn("Injecting faults in public dashboards", "config", config)
+	return response.JSON(http.StatusOK, config)
+}
+
+// ResetPublicDashboardFaults Stops injecting faults in the public dashboard store and query layers
+// DELETE /api/admin/dashboards/public/faults
+func (api *Api) ResetPublicDashboardFaults(c *models.ReqContext) response.Response {
+	api.Faults.Reset()
+	return response.Success("Public dashboard faults reset")
+}
+
 // GetPublicDashboardConfig Gets public dashboard configuration for dashboard
 // GET /api/dashboards/uid/:uid/public-config
 func (api *Api) GetPublicDashboardConfig(c *models.ReqContext) response.Response {
@@ -139,7 +268,8 @@ func (api *Api) GetPublicDashboardConfig(c *models.ReqContext) response.Response
 	return response.JSON(http.StatusOK, pdc)
 }
 
-// SavePublicDashboardConfig Sets public dashboard configuration for dashboard
+// SavePublicDashboardConfig Sets public dashboard configuration for dashboard. With the dryRun query parameter, the
+// configuration is validated and returned without being saved
 // POST /api/dashboards/uid/:uid/public-config
 func (api *Api) SavePublicDashboardConfig(c *models.ReqContext) response.Response {
 	// exit if we don't have a valid dashboardUid
@@ -162,6 +292,15 @@ func (api *Api) SavePublicDashboardConfig(c *models.ReqContext) response.Respons
 		PublicDashboard: pubdash,
 	}
 
+	// Preview the public dashboard without saving it
+	if c.QueryBool("dryRun") {
+		preview, err := api.PublicDashboardService.Preview(c.Req.Context(), c.SignedInUser, &dto)
+		if err != nil {
+			return api.handleError(c.Req.Context(), http.StatusInternalServerError, "SavePublicDashboardConfig: failed to preview public dashboard configuration", err)
+		}
+		return response.JSON(http.StatusOK, preview)
+	}
+
 	// Save the public dashboard
 	pubdash, err := api.PublicDashboardService.Save(c.Req.Context(), c.SignedInUser, &dto)
 	if err != nil {
@@ -171,6 +310,27 @@ func (api *Api) SavePublicDashboardConfig(c *models.ReqContext) response.Respons
 	return response.JSON(http.StatusOK, pubdash)
 }
 
+// TransferPublicDashboardOwnership Transfers the ownership of the public dashboard of a dashboard to another user
+// POST /api/dashboards/uid/:uid/public-config/owner
+func (api *Api) TransferPublicDashboardOwnership(c *models.ReqContext) response.Response {
+	dto := &TransferPublicDashboardOwnershipDTO{}
+	if err := web.Bind(c.Req, dto); err != nil {
+		return response.Error(http.StatusBadRequest, "TransferPublicDashboardOwnership: bad request data", err)
+	}
+
+	// Always set the orgID and userID from the session
+	dto.DashboardUid = web.Params(c.Req)[":uid"]
+	dto.OrgId = c.OrgID
+	dto.UserId = c.UserID
+
+	pubdash, err := api.PublicDashboardService.TransferOwnership(c.Req.Context(), c.SignedInUser, dto)
+	if err != nil {
+		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "TransferPublicDashboardOwnership: failed to transfer public dashboard ownership", err)
+	}
+
+	return response.JSON(http.StatusOK, pubdash)
+}
+
 // QueryPublicDashboard returns all results for a given panel on a public dashboard
 // POST /api/public/dashboard/:accessToken/panels/:panelId/query
 func (api *Api) QueryPublicDashboard(c *models.ReqContext) response.Response {
@@ -189,9 +349,26 @@ func (api *Api) QueryPublicDashboard(c *models.ReqContext) response.Response {
 		return response.Error(http.StatusBadRequest, "QueryPublicDashboard: bad request data", err)
 	}
 
-	resp, err := api.PublicDashboardService.GetQueryDataResponse(c.Req.Context(), c.SkipCache, reqDTO, panelId, accessToken)
+	// reuse the request ID sent by the client if it is safe, so viewers can report it to support
+	requestId := SanitizeQueryRequestId(c.Req.Header.Get(QueryRequestIdHeader))
+	if requestId == "" {
+		requestId = util.GenerateShortUID()
+	}
+	c.Resp.Header().Set(QueryRequestIdHeader, requestId)
+	ctx := WithQueryRequestId(c.Req.Context(), requestId)
+
+	ctx, span := api.Tracer.Start(ctx, "publicdashboards.QueryPublicDashboard")
+	defer span.End()
+	span.SetAttributes("request_id", requestId, attribute.String("request_id", requestId))
+
+	resp, err := api.PublicDashboardService.GetQueryDataResponse(ctx, c.SkipCache, reqDTO, panelId, accessToken)
 	if err != nil {
-		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "QueryPublicDashboard: error running public dashboard panel queries", err)
+		return api.handleError(ctx, http.StatusInternalServerError, "QueryPublicDashboard: error running public dashboard panel queries", err)
+	}
+
+	// failed queries are not cached, the next viewers retry them
+	if !hasQueryErrors(resp) {
+		api.setCacheControlByAccessToken(ctx, c, accessToken)
 	}
 
 	return toJsonStreamingResponse(api.Features, resp)
@@ -216,9 +393,229 @@ func (api *Api) GetAnnotations(c *models.ReqContext) response.Response {
 		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "error getting public dashboard annotations", err)
 	}
 
+	api.setCacheControlByAccessToken(c.Req.Context(), c, accessToken)
+
 	return response.JSON(http.StatusOK, annotations)
 }
 
+// RequestPublicDashboardMagicLink emails a sign in link to a viewer of an email-gated public dashboard
+// POST /api/public/dashboards/:accessToken/magic-link
+func (api *Api) RequestPublicDashboardMagicLink(c *models.ReqContext) response.Response {
+	accessToken := web.Params(c.Req)[":accessToken"]
+	if !tokens.IsValidAccessToken(accessToken) {
+		return response.Error(http.StatusBadRequest, "Invalid Access Token", nil)
+	}
+
+	dto := RequestMagicLinkDTO{}
+	if err := web.Bind(c.Req, &dto); err != nil {
+		return response.Error(http.StatusBadRequest, "RequestPublicDashboardMagicLink: bad request data", err)
+	}
+
+	if err := api.PublicDashboardService.RequestMagicLink(c.Req.Context(), accessToken, dto.Email); err != nil {
+		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "RequestPublicDashboardMagicLink: failed to send magic link", err)
+	}
+
+	// same answer whether the address is allowed or not, so the allowlist cannot be probed
+	return response.Success("If this email address can view the dashboard, a sign in link has been sent to it")
+}
+
+// CreatePublicDashboardSession verifies a magic link of an email-gated public dashboard and sets the session cookie
+// POST /api/public/dashboards/:accessToken/session
+func (api *Api) CreatePublicDashboardSession(c *models.ReqContext) response.Response {
+	accessToken := web.Params(c.Req)[":accessToken"]
+	if !tokens.IsValidAccessToken(accessToken) {
+		return response.Error(http.StatusBadRequest, "Invalid Access Token", nil)
+	}
+
+	dto := VerifyMagicLinkDTO{}
+	if err := web.Bind(c.Req, &dto); err != nil {
+		return response.Error(http.StatusBadRequest, "CreatePublicDashboardSession: bad request data", err)
+	}
+
+	session, err := api.PublicDashboardService.VerifyMagicLink(c.Req.Context(), accessToken, dto.Code)
+	if err != nil {
+		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "CreatePublicDashboardSession: failed to verify magic link", err)
+	}
+
+	maxAge := int(time.Until(session.ExpiresAt).Seconds())
+	cookies.WriteCookie(c.Resp, PublicDashboardSessionCookiePrefix+accessToken, session.Token, maxAge, nil)
+
+	return response.JSON(http.StatusOK, map[string]interface{}{
+		"email":     session.Email,
+		"expiresAt": session.ExpiresAt,
+	})
+}
+
+// GetPublicPlaylist returns the public dashboards to rotate through for a public playlist
+// GET /api/public/playlists/:accessToken
+func (api *Api) GetPublicPlaylist(c *models.ReqContext) response.Response {
+	accessToken := web.Params(c.Req)[":accessToken"]
+	if !tokens.IsValidAccessToken(accessToken) {
+		return response.Error(http.StatusBadRequest, "Invalid Access Token", nil)
+	}
+
+	payload, err := api.PublicDashboardService.GetPlaylistViewerPayload(c.Req.Context(), accessToken)
+	if err != nil {
+		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "GetPublicPlaylist: failed to get public playlist", err)
+	}
+
+	return response.JSON(http.StatusOK, payload)
+}
+
+// ListPublicPlaylists Gets list of public playlists for an org
+// GET /api/dashboards/public/playlists
+func (api *Api) ListPublicPlaylists(c *models.ReqContext) response.Response {
+	resp, err := api.PublicDashboardService.FindAllPlaylists(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "ListPublicPlaylists: failed to list public playlists", err)
+	}
+	return response.JSON(http.StatusOK, resp)
+}
+
+// GetPublicPlaylistConfig Gets a public playlist with its items
+// GET /api/dashboards/public/playlists/:uid
+func (api *Api) GetPublicPlaylistConfig(c *models.ReqContext) response.Response {
+	playlist, err := api.PublicDashboardService.FindPlaylist(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "GetPublicPlaylistConfig: failed to get public playlist", err)
+	}
+	return response.JSON(http.StatusOK, playlist)
+}
+
+// CreatePublicPlaylist Creates a public playlist
+// POST /api/dashboards/public/playlists
+func (api *Api) CreatePublicPlaylist(c *models.ReqContext) response.Response {
+	return api.savePublicPlaylist(c, "")
+}
+
+// UpdatePublicPlaylist Updates the name and items of a public playlist
+// PUT /api/dashboards/public/playlists/:uid
+func (api *Api) UpdatePublicPlaylist(c *models.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if !util.IsValidShortUID(uid) {
+		return response.Error(http.StatusBadRequest, "UpdatePublicPlaylist: invalid uid", nil)
+	}
+	return api.savePublicPlaylist(c, uid)
+}
+
+func (api *Api) savePublicPlaylist(c *models.ReqContext, uid string) response.Response {
+	playlist := &PublicPlaylist{}
+	if err := web.Bind(c.Req, playlist); err != nil {
+		return response.Error(http.StatusBadRequest, "SavePublicPlaylist: bad request data", err)
+	}
+
+	// Always set the orgID and userID from the session
+	dto := SavePublicPlaylistDTO{
+		Uid:            uid,
+		OrgId:          c.OrgID,
+		UserId:         c.UserID,
+		PublicPlaylist: playlist,
+	}
+
+	playlist, err := api.PublicDashboardService.SavePlaylist(c.Req.Context(), c.SignedInUser, &dto)
+	if err != nil {
+		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "SavePublicPlaylist: failed to save public playlist", err)
+	}
+
+	return response.JSON(http.StatusOK, playlist)
+}
+
+// DeletePublicPlaylist Deletes a public playlist
+// DELETE /api/dashboards/public/playlists/:uid
+func (api *Api) DeletePublicPlaylist(c *models.ReqContext) response.Response {
+	err := api.PublicDashboardService.DeletePlaylist(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "DeletePublicPlaylist: failed to delete public playlist", err)
+	}
+	return response.Success("Public playlist deleted")
+}
+
+// GetPublicDashboardReportSubscription Gets the report subscription of the signed in user
+// GET /api/dashboards/public/report-subscription
+func (api *Api) GetPublicDashboardReportSubscription(c *models.ReqContext) response.Response {
+	subscription, err := api.PublicDashboardService.FindReportSubscription(c.Req.Context(), c.SignedInUser)
+	if err != nil {
+		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "GetPublicDashboardReportSubscription: failed to get report subscription", err)
+	}
+	return response.JSON(http.StatusOK, subscription)
+}
+
+// SavePublicDashboardReportSubscription Subscribes the signed in user to the weekly usage and error digest of the
+// public dashboards they created
+// PUT /api/dashboards/public/report-subscription
+func (api *Api) SavePublicDashboardReportSubscription(c *models.ReqContext) response.Response {
+	subscription, err := api.PublicDashboardService.SaveReportSubscription(c.Req.Context(), c.SignedInUser)
+	if err != nil {
+		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "SavePublicDashboardReportSubscription: failed to save report subscription", err)
+	}
+	return response.JSON(http.StatusOK, subscription)
+}
+
+// DeletePublicDashboardReportSubscription Unsubscribes the signed in user from the report digest
+// DELETE /api/dashboards/public/report-subscription
+func (api *Api) DeletePublicDashboardReportSubscription(c *models.ReqContext) response.Response {
+	err := api.PublicDashboardService.DeleteReportSubscription(c.Req.Context(), c.SignedInUser)
+	if err != nil {
+		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "DeletePublicDashboardReportSubscription: failed to delete report subscription", err)
+	}
+	return response.Success("Report subscription deleted")
+}
+
+// GetPublicFolder returns the dashboards of a public folder with the access tokens to view them
+// GET /api/public/folders/:accessToken
+func (api *Api) GetPublicFolder(c *models.ReqContext) response.Response {
+	accessToken := web.Params(c.Req)[":accessToken"]
+	if !tokens.IsValidAccessToken(accessToken) {
+		return response.Error(http.StatusBadRequest, "Invalid Access Token", nil)
+	}
+
+	payload, err := api.PublicDashboardService.GetPublicFolderViewerPayload(c.Req.Context(), accessToken)
+	if err != nil {
+		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "GetPublicFolder: failed to get public folder", err)
+	}
+
+	return response.JSON(http.StatusOK, payload)
+}
+
+// GetPublicFolderConfig Gets the public folder configuration of a folder
+// GET /api/folders/:uid/public-config
+func (api *Api) GetPublicFolderConfig(c *models.ReqContext) response.Response {
+	folder, err := api.PublicDashboardService.FindPublicFolder(c.Req.Context(), c.OrgID, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "GetPublicFolderConfig: failed to get public folder config", err)
+	}
+	return response.JSON(http.StatusOK, folder)
+}
+
+// SavePublicFolderConfig Sets the public folder configuration of a folder
+// POST /api/folders/:uid/public-config
+func (api *Api) SavePublicFolderConfig(c *models.ReqContext) response.Response {
+	folderUid := web.Params(c.Req)[":uid"]
+	if !util.IsValidShortUID(folderUid) {
+		return response.Error(http.StatusBadRequest, "SavePublicFolderConfig: invalid folder uid", nil)
+	}
+
+	folder := &PublicFolder{}
+	if err := web.Bind(c.Req, folder); err != nil {
+		return response.Error(http.StatusBadRequest, "SavePublicFolderConfig: bad request data", err)
+	}
+
+	// Always set the orgID and userID from the session
+	dto := SavePublicFolderDTO{
+		FolderUid:    folderUid,
+		OrgId:        c.OrgID,
+		UserId:       c.UserID,
+		PublicFolder: folder,
+	}
+
+	folder, err := api.PublicDashboardService.SavePublicFolder(c.Req.Context(), c.SignedInUser, &dto)
+	if err != nil {
+		return api.handleError(c.Req.Context(), http.StatusInternalServerError, "SavePublicFolderConfig: failed to save public folder config", err)
+	}
+
+	return response.JSON(http.StatusOK, folder)
+}
+
 // util to help us unpack dashboard and publicdashboard errors or use default http code and message
 // we should look to do some future refactoring of these errors as publicdashboard err is the same as a dashboarderr, just defined in a
 // different package.
@@ -229,7 +626,7 @@ func (api *Api) handleError(ctx context.Context, code int, message string, err e
 
 	// handle public dashboard error
 	if ok := errors.As(err, &publicDashboardErr); ok {
-		return response.Error(publicDashboardErr.StatusCode, publicDashboardErr.Error(), publicDashboardErr)
+		return publicDashboardErrResponse(ctx, publicDashboardErr)
 	}
 
 	// handle dashboard errors as well
@@ -241,6 +638,52 @@ func (api *Api) handleError(ctx context.Context, code int, message string, err e
 	return response.Error(code, message, err)
 }
 
+// publicDashboardErrResponse renders a public dashboard error with its public message and status so
+// clients can react to the kind of error without parsing the message
+func publicDashboardErrResponse(ctx context.Context, err PublicDashboardErr) response.Response {
+	data := map[string]interface{}{
+		"message": err.Public(),
+		"status":  err.Status,
+	}
+	// viewers report the request ID so the error can be found in the backend logs
+	if requestId := QueryRequestIdFromContext(ctx); requestId != "" {
+		data["requestId"] = requestId
+	}
+	if setting.Env != setting.Prod {
+		data["error"] = err.Error()
+	}
+
+	return response.JSON(err.StatusCode, data)
+}
+
+// setCacheControl sets the Cache-Control header of a public response, leaving the default when it should not be cached
+func setCacheControl(c *models.ReqContext, cacheControl string) {
+	if cacheControl != "" {
+		c.Resp.Header().Set("Cache-Control", cacheControl)
+	}
+}
+
+// setCacheControlByAccessToken sets the Cache-Control header of a public response from the configuration of its
+// public dashboard. Responses are sent uncached when the configuration cannot be read
+func (api *Api) setCacheControlByAccessToken(ctx context.Context, c *models.ReqContext, accessToken string) {
+	cacheControl, err := api.PublicDashboardService.GetCacheControlByAccessToken(ctx, accessToken)
+	if err != nil {
+		api.Log.FromContext(ctx).Warn("Failed to get public dashboard cache control", "error", err)
+		return
+	}
+
+	setCacheControl(c, cacheControl)
+}
+
+func hasQueryErrors(qdr *backend.QueryDataResponse) bool {
+	for _, res := range qdr.Responses {
+		if res.Error != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // Copied from pkg/api/metrics.go
 func toJsonStreamingResponse(features *featuremgmt.FeatureManager, qdr *backend.QueryDataResponse) response.Response {
 	statusWhenError := http.StatusBadRequest
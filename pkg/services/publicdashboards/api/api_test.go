@@ -6,8 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -21,7 +23,9 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
 	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
 	"github.com/grafana/grafana/pkg/services/annotations/annotationstest"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -29,14 +33,19 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	datasourcesService "github.com/grafana/grafana/pkg/services/datasources/service"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	publicdashboardsStore "github.com/grafana/grafana/pkg/services/publicdashboards/database"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/faults"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	publicdashboardsService "github.com/grafana/grafana/pkg/services/publicdashboards/service"
 	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/pagination"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -47,7 +56,9 @@ var userViewerRBAC = &user.SignedInUser{UserID: 4, OrgID: 1, OrgRole: org.RoleVi
 var anonymousUser *user.SignedInUser
 
 type JsonErrResponse struct {
-	Error string `json:"error"`
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
 }
 
 func TestAPIGetAnnotations(t *testing.T) {
@@ -97,6 +108,8 @@ func TestAPIGetAnnotations(t *testing.T) {
 			cfg := setting.NewCfg()
 			cfg.RBACEnabled = false
 			service := publicdashboards.NewFakePublicDashboardService(t)
+			service.On("CheckEmailSession", mock.Anything, mock.AnythingOfType("string"), "").Return(nil).Maybe()
+			service.On("GetCacheControlByAccessToken", mock.Anything, mock.AnythingOfType("string")).Return("", nil).Maybe()
 
 			if test.ExpectedServiceCalled {
 				service.On("FindAnnotations", mock.Anything, mock.Anything, mock.AnythingOfType("string")).
@@ -167,19 +180,23 @@ func TestAPIFeatureFlag(t *testing.T) {
 }
 
 func TestAPIListPublicDashboard(t *testing.T) {
-	successResp := []PublicDashboardListResponse{
-		{
-			Uid:          "1234asdfasdf",
-			AccessToken:  "asdfasdf",
-			DashboardUid: "abc1234",
-			IsEnabled:    true,
+	successResp := &PublicDashboardListResponseWithPagination{
+		Result: pagination.Result{TotalCount: 1, Page: 1},
+		PublicDashboards: []PublicDashboardListResponse{
+			{
+				Uid:          "1234asdfasdf",
+				AccessToken:  "asdfasdf",
+				DashboardUid: "abc1234",
+				IsEnabled:    true,
+			},
 		},
 	}
 
 	testCases := []struct {
 		Name                 string
 		User                 *user.SignedInUser
-		Response             []PublicDashboardListResponse
+		Query                string
+		Response             *PublicDashboardListResponseWithPagination
 		ResponseErr          error
 		ExpectedHttpResponse int
 	}{
@@ -204,12 +221,20 @@ func TestAPIListPublicDashboard(t *testing.T) {
 			ResponseErr:          errors.New("error, service broken"),
 			ExpectedHttpResponse: http.StatusInternalServerError,
 		},
+		{
+			Name:                 "Rejects invalid pagination cursors",
+			User:                 userViewer,
+			Query:                "?cursor=invalid",
+			Response:             successResp,
+			ResponseErr:          nil,
+			ExpectedHttpResponse: http.StatusBadRequest,
+		},
 	}
 
 	for _, test := range testCases {
 		t.Run(test.Name, func(t *testing.T) {
 			service := publicdashboards.NewFakePublicDashboardService(t)
-			service.On("FindAll", mock.Anything, mock.Anything, mock.Anything).
+			service.On("FindAll", mock.Anything, mock.Anything, mock.Anything, pagination.Page{Page: 1}).
 				Return(test.Response, test.ResponseErr).Maybe()
 
 			cfg := setting.NewCfg()
@@ -217,14 +242,15 @@ func TestAPIListPublicDashboard(t *testing.T) {
 			features := featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards)
 			testServer := setupTestServer(t, cfg, features, service, nil, test.User)
 
-			response := callAPI(testServer, http.MethodGet, "/api/dashboards/public", nil, t)
+			response := callAPI(testServer, http.MethodGet, "/api/dashboards/public"+test.Query, nil, t)
 			assert.Equal(t, test.ExpectedHttpResponse, response.Code)
 
 			if test.ExpectedHttpResponse == http.StatusOK {
-				var jsonResp []PublicDashboardListResponse
+				var jsonResp PublicDashboardListResponseWithPagination
 				err := json.Unmarshal(response.Body.Bytes(), &jsonResp)
 				require.NoError(t, err)
-				assert.Equal(t, jsonResp[0].Uid, "1234asdfasdf")
+				assert.Equal(t, jsonResp.PublicDashboards[0].Uid, "1234asdfasdf")
+				assert.Equal(t, int64(1), jsonResp.TotalCount)
 			}
 
 			if test.ResponseErr != nil {
@@ -238,6 +264,532 @@ func TestAPIListPublicDashboard(t *testing.T) {
 	}
 }
 
+func TestAPIListAllPublicDashboards(t *testing.T) {
+	grafanaAdmin := &user.SignedInUser{UserID: 5, OrgID: 1, OrgRole: org.RoleAdmin, Login: "testGrafanaAdmin", IsGrafanaAdmin: true}
+
+	testCases := []struct {
+		Name                 string
+		User                 *user.SignedInUser
+		ExpectedHttpResponse int
+	}{
+		{
+			Name:                 "Org admin cannot list public dashboards of all orgs",
+			User:                 userAdmin,
+			ExpectedHttpResponse: http.StatusForbidden,
+		},
+		{
+			Name:                 "Grafana admin can list public dashboards of all orgs",
+			User:                 grafanaAdmin,
+			ExpectedHttpResponse: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			service := publicdashboards.NewFakePublicDashboardService(t)
+			service.On("FindAllGlobal", mock.Anything).
+				Return([]PublicDashboardGlobalListResponse{{Uid: "1234asdfasdf", OrgId: 2, OrgName: "other org"}}, nil).Maybe()
+
+			cfg := setting.NewCfg()
+			cfg.RBACEnabled = false
+			features := featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards)
+			testServer := setupTestServer(t, cfg, features, service, nil, test.User)
+
+			response := callAPI(testServer, http.MethodGet, "/api/admin/dashboards/public", nil, t)
+			assert.Equal(t, test.ExpectedHttpResponse, response.Code)
+
+			if test.ExpectedHttpResponse == http.StatusOK {
+				var jsonResp []PublicDashboardGlobalListResponse
+				err := json.Unmarshal(response.Body.Bytes(), &jsonResp)
+				require.NoError(t, err)
+				assert.Equal(t, "other org", jsonResp[0].OrgName)
+			} else {
+				service.AssertNotCalled(t, "FindAllGlobal")
+			}
+		})
+	}
+}
+
+func TestAPIListPublicDashboardQueryExecutions(t *testing.T) {
+	grafanaAdmin := &user.SignedInUser{UserID: 5, OrgID: 1, OrgRole: org.RoleAdmin, Login: "testGrafanaAdmin", IsGrafanaAdmin: true}
+
+	testCases := []struct {
+		Name                 string
+		User                 *user.SignedInUser
+		ExpectedHttpResponse int
+	}{
+		{
+			Name:                 "Org admin cannot list public dashboard query executions",
+			User:                 userAdmin,
+			ExpectedHttpResponse: http.StatusForbidden,
+		},
+		{
+			Name:                 "Grafana admin can list public dashboard query executions",
+			User:                 grafanaAdmin,
+			ExpectedHttpResponse: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			service := publicdashboards.NewFakePublicDashboardService(t)
+			service.On("FindQueryExecutions", mock.Anything, "abc123").
+				Return([]PublicDashboardQueryExecution{{RequestId: "abc123", PanelId: 2}}, nil).Maybe()
+
+			cfg := setting.NewCfg()
+			cfg.RBACEnabled = false
+			features := featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards)
+			testServer := setupTestServer(t, cfg, features, service, nil, test.User)
+
+			response := callAPI(testServer, http.MethodGet, "/api/admin/dashboards/public/queries?requestId=abc123", nil, t)
+			assert.Equal(t, test.ExpectedHttpResponse, response.Code)
+
+			if test.ExpectedHttpResponse == http.StatusOK {
+				var jsonResp []PublicDashboardQueryExecution
+				err := json.Unmarshal(response.Body.Bytes(), &jsonResp)
+				require.NoError(t, err)
+				require.Len(t, jsonResp, 1)
+				assert.Equal(t, "abc123", jsonResp[0].RequestId)
+			} else {
+				service.AssertNotCalled(t, "FindQueryExecutions")
+			}
+		})
+	}
+}
+
+func TestAPIPublicDashboardFaults(t *testing.T) {
+	grafanaAdmin := &user.SignedInUser{UserID: 5, OrgID: 1, OrgRole: org.RoleAdmin, Login: "testGrafanaAdmin", IsGrafanaAdmin: true}
+	cfg := setting.NewCfg()
+	cfg.RBACEnabled = false
+	features := featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards)
+
+	t.Run("Fault injection endpoints are not registered without an injector", func(t *testing.T) {
+		testServer := setupTestServer(t, cfg, features, publicdashboards.NewFakePublicDashboardService(t), nil, grafanaAdmin)
+
+		response := callAPI(testServer, http.MethodGet, "/api/admin/dashboards/public/faults", nil, t)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("Org admin cannot inject faults", func(t *testing.T) {
+		injector := faults.NewInjector()
+		testServer := setupTestServerWithFaults(t, cfg, features, publicdashboards.NewFakePublicDashboardService(t), nil, userAdmin, injector)
+
+		response := callAPI(testServer, http.MethodPut, "/api/admin/dashboards/public/faults", strings.NewReader(`{"errorRate": 1}`), t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+		assert.Equal(t, faults.Config{}, injector.Config())
+	})
+
+	t.Run("Grafana admin can set, get and reset the faults", func(t *testing.T) {
+		injector := faults.NewInjector()
+		testServer := setupTestServerWithFaults(t, cfg, features, publicdashboards.NewFakePublicDashboardService(t), nil, grafanaAdmin, injector)
+
+		response := callAPI(testServer, http.MethodPut, "/api/admin/dashboards/public/faults", strings.NewReader(`{"latencyMs": 10, "errorRate": 0.5, "operations": ["QueryData"]}`), t)
+		require.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, faults.Config{LatencyMs: 10, ErrorRate: 0.5, Operations: []string{"QueryData"}}, injector.Config())
+
+		response = callAPI(testServer, http.MethodGet, "/api/admin/dashboards/public/faults", nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+		var jsonResp faults.Config
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &jsonResp))
+		assert.Equal(t, 0.5, jsonResp.ErrorRate)
+
+		response = callAPI(testServer, http.MethodDelete, "/api/admin/dashboards/public/faults", nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, faults.Config{}, injector.Config())
+	})
+
+	t.Run("Invalid faults are rejected", func(t *testing.T) {
+		injector := faults.NewInjector()
+		testServer := setupTestServerWithFaults(t, cfg, features, publicdashboards.NewFakePublicDashboardService(t), nil, grafanaAdmin, injector)
+
+		response := callAPI(testServer, http.MethodPut, "/api/admin/dashboards/public/faults", strings.NewReader(`{"errorRate": 2}`), t)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Equal(t, faults.Config{}, injector.Config())
+	})
+}
+
+func TestAPIGetPublicPlaylist(t *testing.T) {
+	validAccessToken := "e71fe2bc8c2d4d1d9fb6e4e9c36de8a1"
+
+	testCases := []struct {
+		Name                 string
+		AccessToken          string
+		ExpectedHttpResponse int
+		PayloadResult        *PublicPlaylistViewerPayload
+		PayloadErr           error
+	}{
+		{
+			Name:                 "Anonymous user can get the viewer payload of a public playlist",
+			AccessToken:          validAccessToken,
+			ExpectedHttpResponse: http.StatusOK,
+			PayloadResult: &PublicPlaylistViewerPayload{
+				Name:  "lobby",
+				Items: []PublicPlaylistViewerPayloadItem{{AccessToken: validAccessToken, Title: "first", DwellSeconds: 60}},
+			},
+		},
+		{
+			Name:                 "Returns 400 for an invalid access token",
+			AccessToken:          "SomeInvalidAccessToken",
+			ExpectedHttpResponse: http.StatusBadRequest,
+		},
+		{
+			Name:                 "Returns 404 for an unknown public playlist",
+			AccessToken:          validAccessToken,
+			ExpectedHttpResponse: http.StatusNotFound,
+			PayloadErr:           ErrPublicPlaylistNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			service := publicdashboards.NewFakePublicDashboardService(t)
+			service.On("GetPlaylistViewerPayload", mock.Anything, test.AccessToken).
+				Return(test.PayloadResult, test.PayloadErr).Maybe()
+
+			cfg := setting.NewCfg()
+			cfg.RBACEnabled = false
+			features := featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards)
+			testServer := setupTestServer(t, cfg, features, service, nil, anonymousUser)
+
+			response := callAPI(testServer, http.MethodGet, fmt.Sprintf("/api/public/playlists/%s", test.AccessToken), nil, t)
+			assert.Equal(t, test.ExpectedHttpResponse, response.Code)
+
+			if test.ExpectedHttpResponse == http.StatusOK {
+				var jsonResp PublicPlaylistViewerPayload
+				err := json.Unmarshal(response.Body.Bytes(), &jsonResp)
+				require.NoError(t, err)
+				assert.Equal(t, *test.PayloadResult, jsonResp)
+			}
+		})
+	}
+}
+
+func TestAPISavePublicPlaylist(t *testing.T) {
+	testCases := []struct {
+		Name                 string
+		User                 *user.SignedInUser
+		Method               string
+		Path                 string
+		ExpectedHttpResponse int
+		SaveErr              error
+	}{
+		{
+			Name:                 "Admin can create a public playlist",
+			User:                 userAdmin,
+			Method:               http.MethodPost,
+			Path:                 "/api/dashboards/public/playlists",
+			ExpectedHttpResponse: http.StatusOK,
+		},
+		{
+			Name:                 "Admin can update a public playlist",
+			User:                 userAdmin,
+			Method:               http.MethodPut,
+			Path:                 "/api/dashboards/public/playlists/playlist1",
+			ExpectedHttpResponse: http.StatusOK,
+		},
+		{
+			Name:                 "Viewer cannot create a public playlist",
+			User:                 userViewer,
+			Method:               http.MethodPost,
+			Path:                 "/api/dashboards/public/playlists",
+			ExpectedHttpResponse: http.StatusForbidden,
+		},
+		{
+			Name:                 "Returns 400 when an item is not a public dashboard of the org",
+			User:                 userAdmin,
+			Method:               http.MethodPost,
+			Path:                 "/api/dashboards/public/playlists",
+			ExpectedHttpResponse: http.StatusBadRequest,
+			SaveErr:              ErrPublicPlaylistInvalidItem,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			service := publicdashboards.NewFakePublicDashboardService(t)
+			service.On("SavePlaylist", mock.Anything, mock.Anything, mock.AnythingOfType("*models.SavePublicPlaylistDTO")).
+				Return(&PublicPlaylist{Uid: "playlist1", Name: "lobby"}, test.SaveErr).Maybe()
+
+			cfg := setting.NewCfg()
+			cfg.RBACEnabled = false
+			features := featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards)
+			testServer := setupTestServer(t, cfg, features, service, nil, test.User)
+
+			response := callAPI(testServer, test.Method, test.Path, strings.NewReader(`{"name": "lobby", "items": []}`), t)
+			assert.Equal(t, test.ExpectedHttpResponse, response.Code)
+
+			if test.ExpectedHttpResponse == http.StatusForbidden {
+				service.AssertNotCalled(t, "SavePlaylist")
+			}
+		})
+	}
+}
+
+func TestAPIGetPublicFolder(t *testing.T) {
+	validAccessToken := "e71fe2bc8c2d4d1d9fb6e4e9c36de8a1"
+
+	testCases := []struct {
+		Name                 string
+		AccessToken          string
+		ExpectedHttpResponse int
+		PayloadResult        *PublicFolderViewerPayload
+		PayloadErr           error
+	}{
+		{
+			Name:                 "Anonymous user can list the dashboards of a public folder",
+			AccessToken:          validAccessToken,
+			ExpectedHttpResponse: http.StatusOK,
+			PayloadResult: &PublicFolderViewerPayload{
+				Title:      "status",
+				Dashboards: []PublicFolderViewerPayloadDashboard{{AccessToken: validAccessToken, Title: "api"}},
+			},
+		},
+		{
+			Name:                 "Returns 400 for an invalid access token",
+			AccessToken:          "SomeInvalidAccessToken",
+			ExpectedHttpResponse: http.StatusBadRequest,
+		},
+		{
+			Name:                 "Returns 404 for a disabled public folder",
+			AccessToken:          validAccessToken,
+			ExpectedHttpResponse: http.StatusNotFound,
+			PayloadErr:           ErrPublicFolderDisabled,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			service := publicdashboards.NewFakePublicDashboardService(t)
+			service.On("GetPublicFolderViewerPayload", mock.Anything, test.AccessToken).
+				Return(test.PayloadResult, test.PayloadErr).Maybe()
+
+			cfg := setting.NewCfg()
+			cfg.RBACEnabled = false
+			features := featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards)
+			testServer := setupTestServer(t, cfg, features, service, nil, anonymousUser)
+
+			response := callAPI(testServer, http.MethodGet, fmt.Sprintf("/api/public/folders/%s", test.AccessToken), nil, t)
+			assert.Equal(t, test.ExpectedHttpResponse, response.Code)
+
+			if test.ExpectedHttpResponse == http.StatusOK {
+				var jsonResp PublicFolderViewerPayload
+				err := json.Unmarshal(response.Body.Bytes(), &jsonResp)
+				require.NoError(t, err)
+				assert.Equal(t, *test.PayloadResult, jsonResp)
+			}
+		})
+	}
+}
+
+func TestAPISavePublicFolderConfig(t *testing.T) {
+	testCases := []struct {
+		Name                 string
+		User                 *user.SignedInUser
+		ExpectedHttpResponse int
+		SaveErr              error
+	}{
+		{
+			Name:                 "Admin can share a folder publicly",
+			User:                 userAdmin,
+			ExpectedHttpResponse: http.StatusOK,
+		},
+		{
+			Name:                 "Viewer cannot share a folder publicly",
+			User:                 userViewer,
+			ExpectedHttpResponse: http.StatusForbidden,
+		},
+		{
+			Name:                 "Returns 404 when the folder does not exist",
+			User:                 userAdmin,
+			ExpectedHttpResponse: http.StatusNotFound,
+			SaveErr:              ErrPublicFolderFolderNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			service := publicdashboards.NewFakePublicDashboardService(t)
+			service.On("SavePublicFolder", mock.Anything, mock.Anything, mock.AnythingOfType("*models.SavePublicFolderDTO")).
+				Return(&PublicFolder{Uid: "pubfolder1", FolderUid: "folder1", IsEnabled: true}, test.SaveErr).Maybe()
+
+			cfg := setting.NewCfg()
+			cfg.RBACEnabled = false
+			features := featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards)
+			testServer := setupTestServer(t, cfg, features, service, nil, test.User)
+
+			response := callAPI(testServer, http.MethodPost, "/api/folders/folder1/public-config", strings.NewReader(`{"isEnabled": true}`), t)
+			assert.Equal(t, test.ExpectedHttpResponse, response.Code)
+
+			if test.ExpectedHttpResponse == http.StatusForbidden {
+				service.AssertNotCalled(t, "SavePublicFolder")
+			}
+		})
+	}
+}
+
+func TestAPIRequestPublicDashboardMagicLink(t *testing.T) {
+	validAccessToken := "e71fe2bc8c2d4d1d9fb6e4e9c36de8a1"
+
+	testCases := []struct {
+		Name                 string
+		AccessToken          string
+		ExpectedHttpResponse int
+		RequestErr           error
+	}{
+		{
+			Name:                 "Anonymous user can request a magic link",
+			AccessToken:          validAccessToken,
+			ExpectedHttpResponse: http.StatusOK,
+		},
+		{
+			Name:                 "Returns 400 for an invalid access token",
+			AccessToken:          "SomeInvalidAccessToken",
+			ExpectedHttpResponse: http.StatusBadRequest,
+		},
+		{
+			Name:                 "Returns 400 for an invalid email address",
+			AccessToken:          validAccessToken,
+			ExpectedHttpResponse: http.StatusBadRequest,
+			RequestErr:           ErrPublicDashboardInvalidEmail,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			service := publicdashboards.NewFakePublicDashboardService(t)
+			service.On("RequestMagicLink", mock.Anything, test.AccessToken, "viewer@example.com").
+				Return(test.RequestErr).Maybe()
+
+			cfg := setting.NewCfg()
+			cfg.RBACEnabled = false
+			features := featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards)
+			testServer := setupTestServer(t, cfg, features, service, nil, anonymousUser)
+
+			response := callAPI(testServer, http.MethodPost, fmt.Sprintf("/api/public/dashboards/%s/magic-link", test.AccessToken), strings.NewReader(`{"email": "viewer@example.com"}`), t)
+			assert.Equal(t, test.ExpectedHttpResponse, response.Code)
+		})
+	}
+}
+
+func TestAPICreatePublicDashboardSession(t *testing.T) {
+	validAccessToken := "e71fe2bc8c2d4d1d9fb6e4e9c36de8a1"
+
+	t.Run("Sets the session cookie when the magic link is valid", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("VerifyMagicLink", mock.Anything, validAccessToken, "code").
+			Return(&PublicDashboardSessionToken{Token: "sessionToken", Email: "viewer@example.com", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+		cfg := setting.NewCfg()
+		cfg.RBACEnabled = false
+		features := featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards)
+		testServer := setupTestServer(t, cfg, features, service, nil, anonymousUser)
+
+		response := callAPI(testServer, http.MethodPost, fmt.Sprintf("/api/public/dashboards/%s/session", validAccessToken), strings.NewReader(`{"code": "code"}`), t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		cookies := response.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, PublicDashboardSessionCookiePrefix+validAccessToken, cookies[0].Name)
+		assert.Equal(t, "sessionToken", cookies[0].Value)
+		assert.True(t, cookies[0].HttpOnly)
+	})
+
+	t.Run("Returns 401 when the magic link is invalid", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("VerifyMagicLink", mock.Anything, validAccessToken, "code").
+			Return(nil, ErrPublicDashboardMagicLinkInvalid)
+
+		cfg := setting.NewCfg()
+		cfg.RBACEnabled = false
+		features := featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards)
+		testServer := setupTestServer(t, cfg, features, service, nil, anonymousUser)
+
+		response := callAPI(testServer, http.MethodPost, fmt.Sprintf("/api/public/dashboards/%s/session", validAccessToken), strings.NewReader(`{"code": "code"}`), t)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+		assert.Empty(t, response.Result().Cookies())
+	})
+}
+
+func TestAPIPublicDashboardReportSubscription(t *testing.T) {
+	testCases := []struct {
+		Name                 string
+		Method               string
+		User                 *user.SignedInUser
+		ServiceMethod        string
+		ServiceErr           error
+		ExpectedHttpResponse int
+	}{
+		{
+			Name:                 "Signed in user can get their subscription",
+			Method:               http.MethodGet,
+			User:                 userViewer,
+			ServiceMethod:        "FindReportSubscription",
+			ExpectedHttpResponse: http.StatusOK,
+		},
+		{
+			Name:                 "Returns 404 when the user is not subscribed",
+			Method:               http.MethodGet,
+			User:                 userViewer,
+			ServiceMethod:        "FindReportSubscription",
+			ServiceErr:           ErrPublicDashboardReportSubscriptionNotFound,
+			ExpectedHttpResponse: http.StatusNotFound,
+		},
+		{
+			Name:                 "Signed in user can subscribe",
+			Method:               http.MethodPut,
+			User:                 userAdmin,
+			ServiceMethod:        "SaveReportSubscription",
+			ExpectedHttpResponse: http.StatusOK,
+		},
+		{
+			Name:                 "Returns 422 when SMTP is not configured",
+			Method:               http.MethodPut,
+			User:                 userAdmin,
+			ServiceMethod:        "SaveReportSubscription",
+			ServiceErr:           ErrPublicDashboardReportEmailNotConfigured,
+			ExpectedHttpResponse: http.StatusUnprocessableEntity,
+		},
+		{
+			Name:                 "Signed in user can unsubscribe",
+			Method:               http.MethodDelete,
+			User:                 userAdmin,
+			ServiceMethod:        "DeleteReportSubscription",
+			ExpectedHttpResponse: http.StatusOK,
+		},
+		{
+			Name:                 "Anonymous user cannot subscribe",
+			Method:               http.MethodPut,
+			User:                 anonymousUser,
+			ExpectedHttpResponse: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			service := publicdashboards.NewFakePublicDashboardService(t)
+			switch test.ServiceMethod {
+			case "DeleteReportSubscription":
+				service.On(test.ServiceMethod, mock.Anything, test.User).Return(test.ServiceErr)
+			case "":
+			default:
+				var subscription *PublicDashboardReportSubscription
+				if test.ServiceErr == nil {
+					subscription = &PublicDashboardReportSubscription{Id: 1, OrgId: test.User.OrgID, UserId: test.User.UserID, Email: "owner@example.com"}
+				}
+				service.On(test.ServiceMethod, mock.Anything, test.User).Return(subscription, test.ServiceErr)
+			}
+
+			cfg := setting.NewCfg()
+			cfg.RBACEnabled = false
+			features := featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards)
+			testServer := setupTestServer(t, cfg, features, service, nil, test.User)
+
+			response := callAPI(testServer, test.Method, "/api/dashboards/public/report-subscription", nil, t)
+			assert.Equal(t, test.ExpectedHttpResponse, response.Code)
+		})
+	}
+}
+
 func TestAPIGetPublicDashboard(t *testing.T) {
 	DashboardUid := "dashboard-abcd1234"
 
@@ -269,6 +821,22 @@ func TestAPIGetPublicDashboard(t *testing.T) {
 			Err:                  ErrPublicDashboardNotFound,
 			FixedErrorResponse:   "",
 		},
+		{
+			Name:                 "It should return 404 if public dashboard is disabled",
+			AccessToken:          validAccessToken,
+			ExpectedHttpResponse: http.StatusNotFound,
+			DashboardResult:      nil,
+			Err:                  ErrPublicDashboardDisabled,
+			FixedErrorResponse:   "",
+		},
+		{
+			Name:                 "It should return 429 if public dashboard is rate limited",
+			AccessToken:          validAccessToken,
+			ExpectedHttpResponse: http.StatusTooManyRequests,
+			DashboardResult:      nil,
+			Err:                  ErrPublicDashboardRateLimited,
+			FixedErrorResponse:   "",
+		},
 		{
 			Name:                 "It should return 400 if it is an invalid access token",
 			AccessToken:          "SomeInvalidAccessToken",
@@ -284,6 +852,8 @@ func TestAPIGetPublicDashboard(t *testing.T) {
 			service := publicdashboards.NewFakePublicDashboardService(t)
 			service.On("FindPublicDashboardAndDashboardByAccessToken", mock.Anything, mock.AnythingOfType("string")).
 				Return(&PublicDashboard{}, test.DashboardResult, test.Err).Maybe()
+			service.On("CheckEmailSession", mock.Anything, mock.AnythingOfType("string"), "").Return(nil).Maybe()
+			service.On("RecordView", mock.Anything, mock.Anything, true).Maybe()
 
 			cfg := setting.NewCfg()
 			cfg.RBACEnabled = false
@@ -314,6 +884,7 @@ func TestAPIGetPublicDashboard(t *testing.T) {
 				assert.Equal(t, false, dashResp.Meta.CanEdit)
 				assert.Equal(t, false, dashResp.Meta.CanDelete)
 				assert.Equal(t, false, dashResp.Meta.CanSave)
+				service.AssertCalled(t, "RecordView", mock.Anything, mock.Anything, true)
 			} else if test.FixedErrorResponse != "" {
 				require.Equal(t, test.ExpectedHttpResponse, response.Code)
 				require.JSONEq(t, "{\"message\":\"Invalid Access Token\"}", response.Body.String())
@@ -322,6 +893,11 @@ func TestAPIGetPublicDashboard(t *testing.T) {
 				err := json.Unmarshal(response.Body.Bytes(), &errResp)
 				require.NoError(t, err)
 				assert.Equal(t, test.Err.Error(), errResp.Error)
+
+				var pdErr PublicDashboardErr
+				require.ErrorAs(t, test.Err, &pdErr)
+				assert.Equal(t, pdErr.Public(), errResp.Message)
+				assert.Equal(t, pdErr.Status, errResp.Status)
 			}
 		})
 	}
@@ -543,6 +1119,83 @@ func TestApiSavePublicDashboardConfig(t *testing.T) {
 	}
 }
 
+func TestApiSavePublicDashboardConfigDryRun(t *testing.T) {
+	t.Run("returns the preview without saving", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("Preview", mock.Anything, mock.Anything, mock.AnythingOfType("*models.SavePublicDashboardConfigDTO")).
+			Return(&PublicDashboard{Uid: "preview", IsEnabled: true}, nil)
+
+		cfg := setting.NewCfg()
+		cfg.RBACEnabled = false
+		testServer := setupTestServer(t, cfg, featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, userAdmin)
+
+		response := callAPI(testServer, http.MethodPost, "/api/dashboards/uid/1/public-config?dryRun=true", strings.NewReader(`{ "isEnabled": true }`), t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var jsonResp PublicDashboard
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &jsonResp))
+		assert.Equal(t, "preview", jsonResp.Uid)
+		service.AssertNotCalled(t, "Save")
+	})
+
+	t.Run("returns the validation errors", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("Preview", mock.Anything, mock.Anything, mock.AnythingOfType("*models.SavePublicDashboardConfigDTO")).
+			Return(nil, ErrPublicDashboardHasTemplateVariables)
+
+		cfg := setting.NewCfg()
+		cfg.RBACEnabled = false
+		testServer := setupTestServer(t, cfg, featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, userAdmin)
+
+		response := callAPI(testServer, http.MethodPost, "/api/dashboards/uid/1/public-config?dryRun=true", strings.NewReader(`{ "isEnabled": true }`), t)
+		assert.Equal(t, ErrPublicDashboardHasTemplateVariables.StatusCode, response.Code)
+	})
+}
+
+func TestApiTransferPublicDashboardOwnership(t *testing.T) {
+	t.Run("transfers the ownership to the new owner", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("TransferOwnership", mock.Anything, mock.Anything, mock.MatchedBy(func(dto *TransferPublicDashboardOwnershipDTO) bool {
+			return dto.DashboardUid == "1" && dto.NewOwnerId == 8 && dto.OrgId == userAdmin.OrgID && dto.UserId == userAdmin.UserID
+		})).Return(&PublicDashboard{Uid: "pubdash", CreatedBy: 8}, nil)
+
+		cfg := setting.NewCfg()
+		cfg.RBACEnabled = false
+		testServer := setupTestServer(t, cfg, featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, userAdmin)
+
+		response := callAPI(testServer, http.MethodPost, "/api/dashboards/uid/1/public-config/owner", strings.NewReader(`{ "newOwnerId": 8 }`), t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var jsonResp PublicDashboard
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &jsonResp))
+		assert.Equal(t, int64(8), jsonResp.CreatedBy)
+	})
+
+	t.Run("returns the errors of the service", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("TransferOwnership", mock.Anything, mock.Anything, mock.Anything).Return(nil, ErrPublicDashboardOwnerForbidden)
+
+		cfg := setting.NewCfg()
+		cfg.RBACEnabled = false
+		testServer := setupTestServer(t, cfg, featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, userAdmin)
+
+		response := callAPI(testServer, http.MethodPost, "/api/dashboards/uid/1/public-config/owner", strings.NewReader(`{ "newOwnerId": 8 }`), t)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("is forbidden to viewers", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+
+		cfg := setting.NewCfg()
+		cfg.RBACEnabled = false
+		testServer := setupTestServer(t, cfg, featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, userViewer)
+
+		response := callAPI(testServer, http.MethodPost, "/api/dashboards/uid/1/public-config/owner", strings.NewReader(`{ "newOwnerId": 8 }`), t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+		service.AssertNotCalled(t, "TransferOwnership")
+	})
+}
+
 // `/public/dashboards/:uid/query“ endpoint test
 func TestAPIQueryPublicDashboard(t *testing.T) {
 	mockedResponse := &backend.QueryDataResponse{
@@ -598,6 +1251,8 @@ func TestAPIQueryPublicDashboard(t *testing.T) {
 
 	setup := func(enabled bool) (*web.Mux, *publicdashboards.FakePublicDashboardService) {
 		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("CheckEmailSession", mock.Anything, mock.AnythingOfType("string"), "").Return(nil).Maybe()
+		service.On("GetCacheControlByAccessToken", mock.Anything, mock.AnythingOfType("string")).Return("", nil).Maybe()
 		cfg := setting.NewCfg()
 		cfg.RBACEnabled = false
 
@@ -655,6 +1310,51 @@ func TestAPIQueryPublicDashboard(t *testing.T) {
 		require.Equal(t, http.StatusOK, resp.Code)
 	})
 
+	t.Run("Returns a generated request id", func(t *testing.T) {
+		server, fakeDashboardService := setup(true)
+		fakeDashboardService.On("GetQueryDataResponse", mock.Anything, true, mock.Anything, int64(2), validAccessToken).Return(mockedResponse, nil)
+
+		resp := callAPI(server, http.MethodPost, getValidQueryPath(validAccessToken), strings.NewReader("{}"), t)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		requestId := resp.Header().Get(QueryRequestIdHeader)
+		require.NotEmpty(t, requestId)
+		ctx := fakeDashboardService.Calls[len(fakeDashboardService.Calls)-1].Arguments.Get(0).(context.Context)
+		assert.Equal(t, requestId, QueryRequestIdFromContext(ctx))
+	})
+
+	t.Run("Reuses the request id sent by the client when it is valid", func(t *testing.T) {
+		server, fakeDashboardService := setup(true)
+		fakeDashboardService.On("GetQueryDataResponse", mock.Anything, true, mock.Anything, int64(2), validAccessToken).Return(mockedResponse, nil)
+
+		req, err := http.NewRequest(http.MethodPost, getValidQueryPath(validAccessToken), strings.NewReader("{}"))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(QueryRequestIdHeader, "viewer-request-1")
+		resp := httptest.NewRecorder()
+		server.ServeHTTP(resp, req)
+
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "viewer-request-1", resp.Header().Get(QueryRequestIdHeader))
+	})
+
+	t.Run("Replaces the request id sent by the client when it is invalid", func(t *testing.T) {
+		server, fakeDashboardService := setup(true)
+		fakeDashboardService.On("GetQueryDataResponse", mock.Anything, true, mock.Anything, int64(2), validAccessToken).Return(mockedResponse, nil)
+
+		req, err := http.NewRequest(http.MethodPost, getValidQueryPath(validAccessToken), strings.NewReader("{}"))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(QueryRequestIdHeader, "<script>alert(1)</script>")
+		resp := httptest.NewRecorder()
+		server.ServeHTTP(resp, req)
+
+		require.Equal(t, http.StatusOK, resp.Code)
+		requestId := resp.Header().Get(QueryRequestIdHeader)
+		assert.NotEmpty(t, requestId)
+		assert.NotEqual(t, "<script>alert(1)</script>", requestId)
+	})
+
 	t.Run("Status code is 500 when the query fails", func(t *testing.T) {
 		server, fakeDashboardService := setup(true)
 		fakeDashboardService.On("GetQueryDataResponse", mock.Anything, true, mock.Anything, int64(2), validAccessToken).Return(&backend.QueryDataResponse{}, fmt.Errorf("error"))
@@ -662,6 +1362,75 @@ func TestAPIQueryPublicDashboard(t *testing.T) {
 		resp := callAPI(server, http.MethodPost, getValidQueryPath(validAccessToken), strings.NewReader("{}"), t)
 		require.Equal(t, http.StatusInternalServerError, resp.Code)
 	})
+
+	t.Run("Returns the request id in the error payload", func(t *testing.T) {
+		server, fakeDashboardService := setup(true)
+		fakeDashboardService.On("GetQueryDataResponse", mock.Anything, true, mock.Anything, int64(2), validAccessToken).Return(nil, ErrPublicDashboardRateLimited)
+
+		req, err := http.NewRequest(http.MethodPost, getValidQueryPath(validAccessToken), strings.NewReader("{}"))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(QueryRequestIdHeader, "viewer-request-1")
+		resp := httptest.NewRecorder()
+		server.ServeHTTP(resp, req)
+
+		require.Equal(t, http.StatusTooManyRequests, resp.Code)
+		var errResp map[string]interface{}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &errResp))
+		assert.Equal(t, "viewer-request-1", errResp["requestId"])
+	})
+}
+
+func TestAPIPublicDashboardCacheControl(t *testing.T) {
+	setup := func(t *testing.T) (*web.Mux, *publicdashboards.FakePublicDashboardService) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("CheckEmailSession", mock.Anything, mock.AnythingOfType("string"), "").Return(nil).Maybe()
+		service.On("RecordView", mock.Anything, mock.Anything, true).Maybe()
+		service.On("GetCacheControlByAccessToken", mock.Anything, validAccessToken).Return("public, max-age=60", nil).Maybe()
+
+		cfg := setting.NewCfg()
+		cfg.RBACEnabled = false
+		return setupTestServer(t, cfg, featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, anonymousUser), service
+	}
+
+	t.Run("sets the cache control of the public dashboard", func(t *testing.T) {
+		server, service := setup(t)
+		service.On("FindPublicDashboardAndDashboardByAccessToken", mock.Anything, validAccessToken).
+			Return(&PublicDashboard{MaxAge: 60, StaleWhileRevalidate: 30}, &models.Dashboard{Data: simplejson.New()}, nil)
+
+		resp := callAPI(server, http.MethodGet, fmt.Sprintf("/api/public/dashboards/%s", validAccessToken), nil, t)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "public, max-age=60, stale-while-revalidate=30", resp.Header().Get("Cache-Control"))
+	})
+
+	t.Run("sets the cache control of panel queries", func(t *testing.T) {
+		server, service := setup(t)
+		service.On("GetQueryDataResponse", mock.Anything, true, mock.Anything, int64(2), validAccessToken).
+			Return(&backend.QueryDataResponse{Responses: backend.Responses{"A": {}}}, nil)
+
+		resp := callAPI(server, http.MethodPost, getValidQueryPath(validAccessToken), strings.NewReader("{}"), t)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "public, max-age=60", resp.Header().Get("Cache-Control"))
+	})
+
+	t.Run("does not cache failed panel queries", func(t *testing.T) {
+		server, service := setup(t)
+		service.On("GetQueryDataResponse", mock.Anything, true, mock.Anything, int64(2), validAccessToken).
+			Return(&backend.QueryDataResponse{Responses: backend.Responses{"A": {Error: errors.New("failed")}}}, nil)
+
+		resp := callAPI(server, http.MethodPost, getValidQueryPath(validAccessToken), strings.NewReader("{}"), t)
+		assert.Empty(t, resp.Header().Get("Cache-Control"))
+		service.AssertNotCalled(t, "GetCacheControlByAccessToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("sets the cache control of annotations", func(t *testing.T) {
+		server, service := setup(t)
+		service.On("FindAnnotations", mock.Anything, mock.Anything, validAccessToken).Return([]AnnotationEvent{}, nil)
+
+		resp := callAPI(server, http.MethodGet, fmt.Sprintf("/api/public/dashboards/%s/annotations", validAccessToken), nil, t)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "public, max-age=60", resp.Header().Get("Cache-Control"))
+	})
 }
 
 func getValidQueryPath(accessToken string) string {
@@ -731,7 +1500,7 @@ func TestIntegrationUnauthenticatedUserCanGetPubdashPanelQueryData(t *testing.T)
 	cfg := setting.NewCfg()
 	ac := acmock.New()
 	cfg.RBACEnabled = false
-	service := publicdashboardsService.ProvideService(cfg, store, qds, annotationsService, ac)
+	service := publicdashboardsService.ProvideService(cfg, store, publicdashboardsStore.ProvidePlaylistStore(db), publicdashboardsStore.ProvideFolderStore(db), publicdashboardsStore.ProvideEmailSessionStore(db), publicdashboardsStore.ProvideReportStore(db), notifications.MockNotificationService(), qds, annotationsService, ac, &usagestats.UsageStatsMock{T: t}, &publicdashboardsService.CIDRGeoIPResolver{}, cacheService, plugins.FakePluginStore{PluginList: []plugins.PluginDTO{{JSONData: plugins.JSONData{ID: datasources.DS_MYSQL, Backend: true}}}}, publicdashboardsService.ProvideLastUsedTracker(featuremgmt.WithFeatures(), store), publicdashboardsService.ProvideUsageTracker(featuremgmt.WithFeatures(), publicdashboardsStore.ProvideReportStore(db)), usertest.NewUserServiceFake(), orgtest.NewOrgServiceFake(), dashboards.NewFakeDashboardService(t), db.Bus())
 	pubdash, err := service.Save(context.Background(), &user.SignedInUser{}, savePubDashboardCmd)
 	require.NoError(t, err)
 
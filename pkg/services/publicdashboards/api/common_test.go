@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -27,6 +28,7 @@ import (
 	datasourceService "github.com/grafana/grafana/pkg/services/datasources/service"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/faults"
 	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
@@ -40,6 +42,20 @@ func setupTestServer(
 	service publicdashboards.Service,
 	db db.DB,
 	user *user.SignedInUser,
+) *web.Mux {
+	return setupTestServerWithFaults(t, cfg, features, service, db, user, nil)
+}
+
+// setupTestServerWithFaults builds a test server whose fault injection endpoints are registered when the injector is
+// not nil
+func setupTestServerWithFaults(
+	t *testing.T,
+	cfg *setting.Cfg,
+	features *featuremgmt.FeatureManager,
+	service publicdashboards.Service,
+	db db.DB,
+	user *user.SignedInUser,
+	faultInjector *faults.Injector,
 ) *web.Mux {
 	// build router to register routes
 	rr := routing.NewRouteRegister()
@@ -68,7 +84,7 @@ func setupTestServer(
 
 	// build api, this will mount the routes at the same time if
 	// featuremgmt.FlagPublicDashboard is enabled
-	ProvideApi(service, rr, ac, features)
+	ProvideApi(service, rr, ac, features, tracing.InitializeTracerForTest(), faultInjector, cfg)
 
 	// connect routes to mux
 	rr.Register(m.Router)
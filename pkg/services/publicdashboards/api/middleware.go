@@ -1,12 +1,18 @@
 package api
 
 import (
+	"errors"
+	"net"
 	"net/http"
+	"strings"
 
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/infra/network"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/internal/tokens"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -33,6 +39,81 @@ func SetPublicDashboardFlag(c *models.ReqContext) {
 	c.IsPublicDashboardView = true
 }
 
+// SetViewerIPOnContext Adds the IP address of the viewer to the request context, so the service can enforce the
+// country restrictions of public dashboards. The forwarding headers can be set by anyone, so they are only honoured
+// for requests coming from one of the trusted proxies
+func SetViewerIPOnContext(trustedProxies []*net.IPNet) func(c *models.ReqContext) {
+	return func(c *models.ReqContext) {
+		ip := viewerIP(c.Req, trustedProxies)
+		if ip == nil {
+			return
+		}
+
+		c.Req = c.Req.WithContext(WithViewerIP(c.Req.Context(), ip))
+	}
+}
+
+// viewerIP returns the address of the connection, or when it is a trusted proxy the last address of the
+// X-Forwarded-For header that was not added by a trusted proxy. Returns nil when an address can't be parsed
+func viewerIP(req *http.Request, trustedProxies []*net.IPNet) net.IP {
+	ip, err := network.GetIPFromAddress(req.RemoteAddr)
+	if err != nil {
+		return nil
+	}
+
+	forwardedFor := strings.Join(req.Header.Values("X-Forwarded-For"), ",")
+	if !isTrustedProxy(ip, trustedProxies) || forwardedFor == "" {
+		return ip
+	}
+
+	// every proxy appends the address it received the request from, so the addresses are read from the end
+	forwarded := strings.Split(forwardedFor, ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip, err = network.GetIPFromAddress(strings.TrimSpace(forwarded[i]))
+		if err != nil {
+			return nil
+		}
+		if !isTrustedProxy(ip, trustedProxies) {
+			return ip
+		}
+	}
+
+	return ip
+}
+
+func isTrustedProxy(ip net.IP, trustedProxies []*net.IPNet) bool {
+	for _, proxy := range trustedProxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTrustedProxies parses the IP addresses and CIDR networks of the trusted proxies. Invalid entries are
+// logged and skipped
+func parseTrustedProxies(logger log.Logger, proxies []string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		cidr := proxy
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() == nil {
+				cidr += "/128"
+			} else {
+				cidr += "/32"
+			}
+		}
+
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			logger.Warn("Ignoring invalid trusted proxy of public dashboards", "proxy", proxy, "error", err)
+			continue
+		}
+		networks = append(networks, ipNet)
+	}
+	return networks
+}
+
 // RequiresExistingAccessToken Middleware to enforce that a public dashboards exists before continuing to handler. This
 // method will query the database to ensure that it exists.
 // Use when we want to enforce a public dashboard is valid on an endpoint we do not maintain
@@ -62,6 +143,31 @@ func RequiresExistingAccessToken(publicDashboardService publicdashboards.Service
 	}
 }
 
+// RequiresEmailSession Middleware to enforce that viewers of email-gated public dashboards have signed in through a
+// magic link. Requests for public dashboards that are not email-gated pass through
+func RequiresEmailSession(publicDashboardService publicdashboards.Service) func(c *models.ReqContext) {
+	return func(c *models.ReqContext) {
+		accessToken, ok := web.Params(c.Req)[":accessToken"]
+		if !ok || !tokens.IsValidAccessToken(accessToken) {
+			// invalid access tokens are rejected by the handlers
+			return
+		}
+
+		err := publicDashboardService.CheckEmailSession(c.Req.Context(), accessToken, c.GetCookie(PublicDashboardSessionCookiePrefix+accessToken))
+		if err == nil {
+			return
+		}
+
+		var publicDashboardErr PublicDashboardErr
+		if errors.As(err, &publicDashboardErr) {
+			publicDashboardErrResponse(c.Req.Context(), publicDashboardErr).WriteTo(c)
+			return
+		}
+
+		c.JsonApiErr(http.StatusInternalServerError, "Failed to check public dashboard session", err)
+	}
+}
+
 func CountPublicDashboardRequest() func(c *models.ReqContext) {
 	return func(c *models.ReqContext) {
 		metrics.MPublicDashboardRequestCount.Inc()
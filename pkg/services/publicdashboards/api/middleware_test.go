@@ -8,9 +8,11 @@ import (
 
 	"errors"
 
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/internal/tokens"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/web"
 	"github.com/stretchr/testify/assert"
@@ -152,11 +154,99 @@ func TestSetPublicDashboardFlag(t *testing.T) {
 	})
 }
 
+func TestSetViewerIPOnContext(t *testing.T) {
+	trustedProxies := parseTrustedProxies(log.New("test.logger"), []string{"10.0.0.0/8", "192.0.2.1", "invalid"})
+	require.Len(t, trustedProxies, 2)
+
+	tests := []struct {
+		Name         string
+		RemoteAddr   string
+		ForwardedFor []string
+		ExpectedIP   string
+		ExpectedNoIP bool
+	}{
+		{Name: "Uses the address of the connection", RemoteAddr: "198.51.100.1:1234", ExpectedIP: "198.51.100.1"},
+		{Name: "Ignores forwarding headers of untrusted clients", RemoteAddr: "198.51.100.1:1234", ForwardedFor: []string{"203.0.113.1"}, ExpectedIP: "198.51.100.1"},
+		{Name: "Uses the address forwarded by a trusted proxy", RemoteAddr: "10.0.0.1:1234", ForwardedFor: []string{"203.0.113.1"}, ExpectedIP: "203.0.113.1"},
+		{Name: "Ignores the addresses set by the viewer before the trusted proxies", RemoteAddr: "10.0.0.1:1234", ForwardedFor: []string{"203.0.113.1, 198.51.100.1", "192.0.2.1"}, ExpectedIP: "198.51.100.1"},
+		{Name: "Uses the address of a trusted proxy without forwarding headers", RemoteAddr: "192.0.2.1:1234", ExpectedIP: "192.0.2.1"},
+		{Name: "Sets no address when a forwarded address is invalid", RemoteAddr: "10.0.0.1:1234", ForwardedFor: []string{"unknown"}, ExpectedNoIP: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			ctx, _ := runMw(t, nil, "GET", "/api/public/dashboards/myaccesstoken", nil, func(c *models.ReqContext) {
+				c.Req.RemoteAddr = tt.RemoteAddr
+				for _, forwardedFor := range tt.ForwardedFor {
+					c.Req.Header.Add("X-Forwarded-For", forwardedFor)
+				}
+				SetViewerIPOnContext(trustedProxies)(c)
+			})
+
+			ip := ViewerIPFromContext(ctx.Req.Context())
+			if tt.ExpectedNoIP {
+				assert.Nil(t, ip)
+				return
+			}
+			assert.Equal(t, tt.ExpectedIP, ip.String())
+		})
+	}
+}
+
 // This is a helper to test middleware. It handles creating a
 // proper models.ReqContext, setting web parameters, executing middleware, and
 // returning a response. Response will default to result of
 // httptest.NewRecorder() return value and will only change if modified by the
 // middlware as this will no accept a handler method
+func TestRequiresEmailSession(t *testing.T) {
+	tests := []struct {
+		Name                 string
+		AccessToken          string
+		CheckErr             error
+		ExpectCheck          bool
+		ExpectedResponseCode int
+	}{
+		{
+			Name:                 "Passes through when the session gives access",
+			AccessToken:          validAccessToken,
+			ExpectCheck:          true,
+			ExpectedResponseCode: http.StatusOK,
+		},
+		{
+			Name:                 "Passes through invalid access tokens without checking the session",
+			AccessToken:          "invalidAccessToken",
+			ExpectedResponseCode: http.StatusOK,
+		},
+		{
+			Name:                 "Returns 401 when the public dashboard requires an email session",
+			AccessToken:          validAccessToken,
+			CheckErr:             ErrPublicDashboardSessionRequired,
+			ExpectCheck:          true,
+			ExpectedResponseCode: http.StatusUnauthorized,
+		},
+		{
+			Name:                 "Returns 500 when public dashboard service gives an error",
+			AccessToken:          validAccessToken,
+			CheckErr:             fmt.Errorf("db error"),
+			ExpectCheck:          true,
+			ExpectedResponseCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			publicdashboardService := publicdashboards.NewFakePublicDashboardService(t)
+			if tt.ExpectCheck {
+				publicdashboardService.On("CheckEmailSession", mock.Anything, tt.AccessToken, "").Return(tt.CheckErr)
+			}
+			params := map[string]string{":accessToken": tt.AccessToken}
+			mw := RequiresEmailSession(publicdashboardService)
+			_, resp := runMw(t, &models.ReqContext{Logger: log.New("test")}, "GET", "/public/dashboards/"+tt.AccessToken, params, mw)
+			require.Equal(t, tt.ExpectedResponseCode, resp.Code)
+		})
+	}
+}
+
 func runMw(t *testing.T, ctx *models.ReqContext, httpmethod string, path string, webparams map[string]string, mw func(c *models.ReqContext)) (*models.ReqContext, *httptest.ResponseRecorder) {
 	// create valid request context and set 0 values if they don't exist
 	if ctx == nil {
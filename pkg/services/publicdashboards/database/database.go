@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -37,10 +38,10 @@ func ProvideStore(sqlStore db.DB) *PublicDashboardStoreImpl {
 func (d *PublicDashboardStoreImpl) FindAll(ctx context.Context, orgId int64) ([]PublicDashboardListResponse, error) {
 	resp := make([]PublicDashboardListResponse, 0)
 
-	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+	err := d.sqlStore.WithReplicaSession(ctx, func(sess *db.Session) error {
 		sess.Table("dashboard_public").
 			Join("LEFT", "dashboard", "dashboard.uid = dashboard_public.dashboard_uid AND dashboard.org_id = dashboard_public.org_id").
-			Cols("dashboard_public.uid", "dashboard_public.access_token", "dashboard_public.dashboard_uid", "dashboard_public.is_enabled", "dashboard.title").
+			Cols("dashboard_public.uid", "dashboard_public.access_token", "dashboard_public.dashboard_uid", "dashboard_public.is_enabled", "dashboard_public.last_used_at", "dashboard_public.creator_deleted", "dashboard.title").
 			Where("dashboard_public.org_id = ?", orgId).
 			OrderBy(" is_enabled DESC, dashboard.title IS NULL, dashboard.title ASC")
 
@@ -55,6 +56,27 @@ func (d *PublicDashboardStoreImpl) FindAll(ctx context.Context, orgId int64) ([]
 	return resp, nil
 }
 
+// FindAllGlobal Returns a list of public dashboards across all orgs
+func (d *PublicDashboardStoreImpl) FindAllGlobal(ctx context.Context) ([]PublicDashboardGlobalListResponse, error) {
+	resp := make([]PublicDashboardGlobalListResponse, 0)
+
+	err := d.sqlStore.WithReplicaSession(ctx, func(sess *db.Session) error {
+		sess.Table("dashboard_public").
+			Join("LEFT", "dashboard", "dashboard.uid = dashboard_public.dashboard_uid AND dashboard.org_id = dashboard_public.org_id").
+			Join("LEFT", "org", "org.id = dashboard_public.org_id").
+			Select("dashboard_public.uid, dashboard_public.access_token, dashboard_public.dashboard_uid, dashboard_public.is_enabled, dashboard_public.last_used_at, dashboard_public.creator_deleted, dashboard_public.org_id, dashboard.title, org.name AS org_name").
+			OrderBy("dashboard_public.org_id ASC, is_enabled DESC, dashboard.title IS NULL, dashboard.title ASC")
+
+		return sess.Find(&resp)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
 func (d *PublicDashboardStoreImpl) FindDashboard(ctx context.Context, dashboardUid string, orgId int64) (*models.Dashboard, error) {
 	dashboard := &models.Dashboard{Uid: dashboardUid, OrgId: orgId}
 	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
@@ -121,6 +143,54 @@ func (d *PublicDashboardStoreImpl) FindByAccessToken(ctx context.Context, access
 	return pdRes, err
 }
 
+// accessTokensBatchSize is the number of access tokens or dashboard uids looked up per query, it keeps the IN clause
+// below the limit of 999 variables per statement of SQLite
+const accessTokensBatchSize = 500
+
+// FindByAccessTokens Returns the public dashboards matching the access tokens, by access token. Access tokens not
+// matching any public dashboard are left out
+func (d *PublicDashboardStoreImpl) FindByAccessTokens(ctx context.Context, accessTokens []string) (map[string]*PublicDashboard, error) {
+	result := make(map[string]*PublicDashboard, len(accessTokens))
+
+	unique := make([]interface{}, 0, len(accessTokens))
+	seen := make(map[string]bool, len(accessTokens))
+	for _, accessToken := range accessTokens {
+		if accessToken != "" && !seen[accessToken] {
+			unique = append(unique, accessToken)
+			seen[accessToken] = true
+		}
+	}
+
+	if len(unique) == 0 {
+		return result, nil
+	}
+
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		for start := 0; start < len(unique); start += accessTokensBatchSize {
+			end := start + accessTokensBatchSize
+			if end > len(unique) {
+				end = len(unique)
+			}
+
+			pubdashes := make([]*PublicDashboard, 0, end-start)
+			if err := sess.In("access_token", unique[start:end]...).Find(&pubdashes); err != nil {
+				return err
+			}
+
+			for _, pubdash := range pubdashes {
+				result[pubdash.AccessToken] = pubdash
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // FindByDashboardUid Retrieves public dashboard configuration by dashboard uid
 func (d *PublicDashboardStoreImpl) FindByDashboardUid(ctx context.Context, orgId int64, dashboardUid string) (*PublicDashboard, error) {
 	if dashboardUid == "" {
@@ -148,6 +218,50 @@ func (d *PublicDashboardStoreImpl) FindByDashboardUid(ctx context.Context, orgId
 	return pdRes, err
 }
 
+// FindByDashboardUids Returns the public dashboards of the dashboards of an org, by dashboard uid. Dashboards without
+// a public dashboard are left out
+func (d *PublicDashboardStoreImpl) FindByDashboardUids(ctx context.Context, orgId int64, dashboardUids []string) (map[string]*PublicDashboard, error) {
+	result := make(map[string]*PublicDashboard, len(dashboardUids))
+
+	unique := make([]interface{}, 0, len(dashboardUids))
+	seen := make(map[string]bool, len(dashboardUids))
+	for _, dashboardUid := range dashboardUids {
+		if dashboardUid != "" && !seen[dashboardUid] {
+			unique = append(unique, dashboardUid)
+			seen[dashboardUid] = true
+		}
+	}
+
+	if len(unique) == 0 {
+		return result, nil
+	}
+
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		for start := 0; start < len(unique); start += accessTokensBatchSize {
+			end := start + accessTokensBatchSize
+			if end > len(unique) {
+				end = len(unique)
+			}
+
+			pubdashes := make([]*PublicDashboard, 0, end-start)
+			if err := sess.Where("org_id = ?", orgId).In("dashboard_uid", unique[start:end]...).Find(&pubdashes); err != nil {
+				return err
+			}
+
+			for _, pubdash := range pubdashes {
+				result[pubdash.DashboardUid] = pubdash
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // Save Persists public dashboard configuration
 func (d *PublicDashboardStoreImpl) Save(ctx context.Context, cmd SavePublicDashboardConfigCommand) error {
 	if cmd.PublicDashboard.DashboardUid == "" {
@@ -155,7 +269,13 @@ func (d *PublicDashboardStoreImpl) Save(ctx context.Context, cmd SavePublicDashb
 	}
 
 	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
-		_, err := sess.UseBool("is_enabled").Insert(&cmd.PublicDashboard)
+		sess.UseBool("is_enabled")
+		// configurations not setting the footer get the column default, the footer is shown
+		if cmd.PublicDashboard.ShowFooter == nil {
+			sess.Omit("show_footer")
+		}
+
+		_, err := sess.Insert(&cmd.PublicDashboard)
 		if err != nil {
 			return err
 		}
@@ -174,9 +294,52 @@ func (d *PublicDashboardStoreImpl) Update(ctx context.Context, cmd SavePublicDas
 			return err
 		}
 
-		_, err = sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, time_settings = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
+		emailAllowlistJSON, err := cmd.PublicDashboard.EmailAllowlist.ToDB()
+		if err != nil {
+			return err
+		}
+
+		allowedCountriesJSON, err := cmd.PublicDashboard.AllowedCountries.ToDB()
+		if err != nil {
+			return err
+		}
+
+		blockedCountriesJSON, err := cmd.PublicDashboard.BlockedCountries.ToDB()
+		if err != nil {
+			return err
+		}
+
+		panelCostHintsJSON, err := cmd.PublicDashboard.PanelCostHints.ToDB()
+		if err != nil {
+			return err
+		}
+
+		annotationsDisabledPanelsJSON, err := cmd.PublicDashboard.AnnotationsDisabledPanels.ToDB()
+		if err != nil {
+			return err
+		}
+
+		previousPeriodPanelsJSON, err := cmd.PublicDashboard.PreviousPeriodPanels.ToDB()
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, annotations_disabled_panels = ?, previous_period_panels = ?, show_time_picker = ?, show_annotations_toggle = ?, show_footer = COALESCE(?, show_footer), email_gated = ?, email_allowlist = ?, allowed_countries = ?, blocked_countries = ?, panel_cost_hints = ?, max_age = ?, stale_while_revalidate = ?, shared_by_folder_uid = ?, time_settings = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
 			cmd.PublicDashboard.IsEnabled,
 			cmd.PublicDashboard.AnnotationsEnabled,
+			string(annotationsDisabledPanelsJSON),
+			string(previousPeriodPanelsJSON),
+			cmd.PublicDashboard.ShowTimePicker,
+			cmd.PublicDashboard.ShowAnnotationsToggle,
+			cmd.PublicDashboard.ShowFooter,
+			cmd.PublicDashboard.EmailGated,
+			string(emailAllowlistJSON),
+			string(allowedCountriesJSON),
+			string(blockedCountriesJSON),
+			string(panelCostHintsJSON),
+			cmd.PublicDashboard.MaxAge,
+			cmd.PublicDashboard.StaleWhileRevalidate,
+			cmd.PublicDashboard.SharedByFolderUid,
 			string(timeSettingsJSON),
 			cmd.PublicDashboard.UpdatedBy,
 			cmd.PublicDashboard.UpdatedAt.UTC().Format("2006-01-02 15:04:05"),
@@ -192,6 +355,80 @@ func (d *PublicDashboardStoreImpl) Update(ctx context.Context, cmd SavePublicDas
 	return err
 }
 
+// UpdateLastUsedAt records when the access tokens of public dashboards were last used, keyed by public dashboard uid.
+// Older timestamps never overwrite more recent ones, so concurrent instances can flush in any order
+func (d *PublicDashboardStoreImpl) UpdateLastUsedAt(ctx context.Context, lastUsed map[string]time.Time) error {
+	if len(lastUsed) == 0 {
+		return nil
+	}
+
+	return d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for uid, usedAt := range lastUsed {
+			usedAtStr := usedAt.UTC().Format("2006-01-02 15:04:05")
+			_, err := sess.Exec("UPDATE dashboard_public SET last_used_at = ? WHERE uid = ? AND (last_used_at IS NULL OR last_used_at < ?)", usedAtStr, uid, usedAtStr)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateOwner sets the user owning a public dashboard
+func (d *PublicDashboardStoreImpl) UpdateOwner(ctx context.Context, cmd TransferPublicDashboardOwnershipCommand) error {
+	return d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE dashboard_public SET created_by = ?, creator_deleted = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
+			cmd.CreatedBy,
+			false,
+			cmd.UpdatedBy,
+			cmd.UpdatedAt.UTC().Format("2006-01-02 15:04:05"),
+			cmd.Uid)
+		return err
+	})
+}
+
+// FindByCreatedBy Returns the public dashboards created by a user, in all orgs
+func (d *PublicDashboardStoreImpl) FindByCreatedBy(ctx context.Context, userId int64) ([]*PublicDashboard, error) {
+	pubdashes := make([]*PublicDashboard, 0)
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("created_by = ?", userId).Find(&pubdashes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pubdashes, nil
+}
+
+// FindSharedByFolders Returns the enabled public dashboards of an org created by public folders
+func (d *PublicDashboardStoreImpl) FindSharedByFolders(ctx context.Context, orgId int64) ([]*PublicDashboard, error) {
+	pubdashes := make([]*PublicDashboard, 0)
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND shared_by_folder_uid != '' AND is_enabled = ?", orgId, true).Find(&pubdashes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pubdashes, nil
+}
+
+// FlagCreatorDeleted marks the creator of a public dashboard as deleted
+func (d *PublicDashboardStoreImpl) FlagCreatorDeleted(ctx context.Context, uid string) error {
+	return d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE dashboard_public SET creator_deleted = ? WHERE uid = ?", true, uid)
+		return err
+	})
+}
+
+// Delete deletes a public dashboard
+func (d *PublicDashboardStoreImpl) Delete(ctx context.Context, uid string) error {
+	return d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM dashboard_public WHERE uid = ?", uid)
+		return err
+	})
+}
+
 // ExistsEnabledByDashboardUid Responds true if there is an enabled public dashboard for a dashboard uid
 func (d *PublicDashboardStoreImpl) ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error) {
 	hasPublicDashboard := false
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -64,6 +65,29 @@ func TestIntegrationListPublicDashboard(t *testing.T) {
 	assert.Equal(t, resp[2].Uid, c.Uid)
 }
 
+func TestIntegrationListAllPublicDashboardsGlobal(t *testing.T) {
+	sqlStore, cfg := db.InitTestDBwithCfg(t, db.InitTestDBOpt{FeatureFlags: []string{featuremgmt.FlagPublicDashboards}})
+	dashboardStore := dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore, cfg))
+	publicdashboardStore := ProvideStore(sqlStore)
+
+	aDash := insertTestDashboard(t, dashboardStore, "a", 1, 0, true)
+	bDash := insertTestDashboard(t, dashboardStore, "b", 2, 0, true)
+
+	a := insertPublicDashboard(t, publicdashboardStore, aDash.Uid, 1, true)
+	b := insertPublicDashboard(t, publicdashboardStore, bDash.Uid, 2, false)
+
+	resp, err := publicdashboardStore.FindAllGlobal(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, resp, 2)
+	assert.Equal(t, a.Uid, resp[0].Uid)
+	assert.Equal(t, int64(1), resp[0].OrgId)
+	assert.Equal(t, "a", resp[0].Title)
+	assert.Equal(t, b.Uid, resp[1].Uid)
+	assert.Equal(t, int64(2), resp[1].OrgId)
+	assert.Equal(t, "b", resp[1].Title)
+}
+
 func TestIntegrationFindDashboard(t *testing.T) {
 	var sqlStore db.DB
 	var cfg *setting.Cfg
@@ -244,6 +268,7 @@ func TestIntegrationFindByDashboardUid(t *testing.T) {
 
 	t.Run("returns along with public dashboard when exists", func(t *testing.T) {
 		setup()
+		showFooter := true
 		cmd := SavePublicDashboardConfigCommand{
 			PublicDashboard: PublicDashboard{
 				IsEnabled:    true,
@@ -253,6 +278,7 @@ func TestIntegrationFindByDashboardUid(t *testing.T) {
 				TimeSettings: DefaultTimeSettings,
 				CreatedAt:    DefaultTime,
 				CreatedBy:    7,
+				ShowFooter:   &showFooter,
 			},
 		}
 
@@ -316,6 +342,10 @@ func TestIntegrationSavePublicDashboard(t *testing.T) {
 		// verify we have a valid uid
 		assert.True(t, util.IsValidShortUID(pubdash.Uid))
 
+		// the footer is shown when not set
+		require.NotNil(t, pubdash.ShowFooter)
+		assert.True(t, *pubdash.ShowFooter)
+
 		// verify we didn't update all dashboards
 		pubdash2, err := publicdashboardStore.FindByDashboardUid(context.Background(), savedDashboard2.OrgId, savedDashboard2.Uid)
 		require.NoError(t, err)
@@ -390,6 +420,7 @@ func TestIntegrationUpdatePublicDashboard(t *testing.T) {
 		})
 		require.NoError(t, err)
 
+		showFooter := false
 		updatedPublicDashboard := PublicDashboard{
 			Uid:                pdUid,
 			DashboardUid:       savedDashboard.Uid,
@@ -397,8 +428,20 @@ func TestIntegrationUpdatePublicDashboard(t *testing.T) {
 			IsEnabled:          false,
 			AnnotationsEnabled: true,
 			TimeSettings:       &TimeSettings{From: "now-8", To: "now"},
-			UpdatedAt:          time.Now().UTC().Round(time.Second),
-			UpdatedBy:          8,
+
+			AnnotationsDisabledPanels: PanelIdList{2, 3},
+			PreviousPeriodPanels:      PanelIdList{1},
+			MaxAge:                    60,
+			StaleWhileRevalidate:      30,
+			UpdatedAt:                 time.Now().UTC().Round(time.Second),
+			UpdatedBy:                 8,
+
+			ShowTimePicker:        true,
+			ShowAnnotationsToggle: true,
+			ShowFooter:            &showFooter,
+
+			EmailGated:     true,
+			EmailAllowlist: EmailAllowlist{"viewer@example.com", "grafana.com"},
 		}
 		// update initial record
 		err = publicdashboardStore.Update(context.Background(), SavePublicDashboardConfigCommand{
@@ -415,6 +458,15 @@ func TestIntegrationUpdatePublicDashboard(t *testing.T) {
 		// UseBool with xorm
 		assert.Equal(t, updatedPublicDashboard.IsEnabled, pdRetrieved.IsEnabled)
 		assert.Equal(t, updatedPublicDashboard.AnnotationsEnabled, pdRetrieved.AnnotationsEnabled)
+		assert.Equal(t, updatedPublicDashboard.AnnotationsDisabledPanels, pdRetrieved.AnnotationsDisabledPanels)
+		assert.Equal(t, updatedPublicDashboard.PreviousPeriodPanels, pdRetrieved.PreviousPeriodPanels)
+		assert.Equal(t, updatedPublicDashboard.MaxAge, pdRetrieved.MaxAge)
+		assert.Equal(t, updatedPublicDashboard.StaleWhileRevalidate, pdRetrieved.StaleWhileRevalidate)
+		assert.Equal(t, updatedPublicDashboard.ShowTimePicker, pdRetrieved.ShowTimePicker)
+		assert.Equal(t, updatedPublicDashboard.ShowAnnotationsToggle, pdRetrieved.ShowAnnotationsToggle)
+		assert.Equal(t, updatedPublicDashboard.ShowFooter, pdRetrieved.ShowFooter)
+		assert.Equal(t, updatedPublicDashboard.EmailGated, pdRetrieved.EmailGated)
+		assert.Equal(t, updatedPublicDashboard.EmailAllowlist, pdRetrieved.EmailAllowlist)
 
 		// not updated dashboard shouldn't have changed
 		pdNotUpdatedRetrieved, err := publicdashboardStore.FindByDashboardUid(context.Background(), anotherSavedDashboard.OrgId, anotherSavedDashboard.Uid)
@@ -423,6 +475,206 @@ func TestIntegrationUpdatePublicDashboard(t *testing.T) {
 		assert.NotEqual(t, updatedPublicDashboard.IsEnabled, pdNotUpdatedRetrieved.IsEnabled)
 		assert.NotEqual(t, updatedPublicDashboard.AnnotationsEnabled, pdNotUpdatedRetrieved.AnnotationsEnabled)
 	})
+
+	t.Run("keeps the footer when the update doesn't set it", func(t *testing.T) {
+		setup()
+
+		showFooter := false
+		err := publicdashboardStore.Save(context.Background(), SavePublicDashboardConfigCommand{
+			PublicDashboard: PublicDashboard{
+				Uid:          "asdf1234",
+				DashboardUid: savedDashboard.Uid,
+				OrgId:        savedDashboard.OrgId,
+				IsEnabled:    true,
+				CreatedAt:    DefaultTime,
+				CreatedBy:    7,
+				AccessToken:  "NOTAREALUUID",
+				ShowFooter:   &showFooter,
+			},
+		})
+		require.NoError(t, err)
+
+		err = publicdashboardStore.Update(context.Background(), SavePublicDashboardConfigCommand{
+			PublicDashboard: PublicDashboard{
+				Uid:          "asdf1234",
+				IsEnabled:    true,
+				TimeSettings: DefaultTimeSettings,
+				UpdatedAt:    time.Now().UTC().Round(time.Second),
+				UpdatedBy:    8,
+			},
+		})
+		require.NoError(t, err)
+
+		pdRetrieved, err := publicdashboardStore.FindByDashboardUid(context.Background(), savedDashboard.OrgId, savedDashboard.Uid)
+		require.NoError(t, err)
+		require.NotNil(t, pdRetrieved.ShowFooter)
+		assert.False(t, *pdRetrieved.ShowFooter)
+	})
+}
+
+func TestIntegrationUpdateLastUsedAt(t *testing.T) {
+	var sqlStore db.DB
+	var cfg *setting.Cfg
+	var dashboardStore *dashboardsDB.DashboardStore
+	var publicdashboardStore *PublicDashboardStoreImpl
+	var savedDashboard *models.Dashboard
+
+	setup := func() {
+		sqlStore, cfg = db.InitTestDBwithCfg(t)
+		dashboardStore = dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore, cfg))
+		publicdashboardStore = ProvideStore(sqlStore)
+		savedDashboard = insertTestDashboard(t, dashboardStore, "testDashie", 1, 0, true)
+	}
+
+	t.Run("records the last use of public dashboards and lists it", func(t *testing.T) {
+		setup()
+		pubdash := insertPublicDashboard(t, publicdashboardStore, savedDashboard.Uid, savedDashboard.OrgId, true)
+		unusedDashboard := insertTestDashboard(t, dashboardStore, "unused", 1, 0, true)
+		unused := insertPublicDashboard(t, publicdashboardStore, unusedDashboard.Uid, unusedDashboard.OrgId, true)
+		assert.Nil(t, pubdash.LastUsedAt)
+
+		usedAt := time.Now().UTC().Round(time.Second)
+		err := publicdashboardStore.UpdateLastUsedAt(context.Background(), map[string]time.Time{pubdash.Uid: usedAt})
+		require.NoError(t, err)
+
+		retrieved, err := publicdashboardStore.Find(context.Background(), pubdash.Uid)
+		require.NoError(t, err)
+		require.NotNil(t, retrieved.LastUsedAt)
+		assert.True(t, usedAt.Equal(*retrieved.LastUsedAt))
+
+		list, err := publicdashboardStore.FindAll(context.Background(), savedDashboard.OrgId)
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		for _, item := range list {
+			if item.Uid == unused.Uid {
+				assert.Nil(t, item.LastUsedAt)
+			} else {
+				require.NotNil(t, item.LastUsedAt)
+				assert.True(t, usedAt.Equal(*item.LastUsedAt))
+			}
+		}
+	})
+
+	t.Run("does not overwrite a more recent use", func(t *testing.T) {
+		setup()
+		pubdash := insertPublicDashboard(t, publicdashboardStore, savedDashboard.Uid, savedDashboard.OrgId, true)
+
+		usedAt := time.Now().UTC().Round(time.Second)
+		err := publicdashboardStore.UpdateLastUsedAt(context.Background(), map[string]time.Time{pubdash.Uid: usedAt})
+		require.NoError(t, err)
+		err = publicdashboardStore.UpdateLastUsedAt(context.Background(), map[string]time.Time{pubdash.Uid: usedAt.Add(-time.Hour)})
+		require.NoError(t, err)
+
+		retrieved, err := publicdashboardStore.Find(context.Background(), pubdash.Uid)
+		require.NoError(t, err)
+		require.NotNil(t, retrieved.LastUsedAt)
+		assert.True(t, usedAt.Equal(*retrieved.LastUsedAt))
+	})
+
+	t.Run("is not overwritten when the configuration is updated", func(t *testing.T) {
+		setup()
+		pubdash := insertPublicDashboard(t, publicdashboardStore, savedDashboard.Uid, savedDashboard.OrgId, true)
+
+		err := publicdashboardStore.UpdateLastUsedAt(context.Background(), map[string]time.Time{pubdash.Uid: time.Now()})
+		require.NoError(t, err)
+
+		pubdash.UpdatedAt = time.Now()
+		err = publicdashboardStore.Update(context.Background(), SavePublicDashboardConfigCommand{PublicDashboard: *pubdash})
+		require.NoError(t, err)
+
+		retrieved, err := publicdashboardStore.Find(context.Background(), pubdash.Uid)
+		require.NoError(t, err)
+		assert.NotNil(t, retrieved.LastUsedAt)
+	})
+}
+
+func TestIntegrationUpdateOwner(t *testing.T) {
+	sqlStore, cfg := db.InitTestDBwithCfg(t)
+	dashboardStore := dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore, cfg))
+	publicdashboardStore := ProvideStore(sqlStore)
+	savedDashboard := insertTestDashboard(t, dashboardStore, "testDashie", 1, 0, true)
+	pubdash := insertPublicDashboard(t, publicdashboardStore, savedDashboard.Uid, savedDashboard.OrgId, true)
+
+	err := publicdashboardStore.UpdateOwner(context.Background(), TransferPublicDashboardOwnershipCommand{
+		Uid:       pubdash.Uid,
+		CreatedBy: 8,
+		UpdatedBy: 9,
+		UpdatedAt: DefaultTime,
+	})
+	require.NoError(t, err)
+
+	retrieved, err := publicdashboardStore.Find(context.Background(), pubdash.Uid)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), retrieved.CreatedBy)
+	assert.Equal(t, int64(9), retrieved.UpdatedBy)
+	assert.Equal(t, DefaultTime, retrieved.UpdatedAt)
+	assert.Equal(t, pubdash.AccessToken, retrieved.AccessToken)
+	assert.Equal(t, pubdash.IsEnabled, retrieved.IsEnabled)
+}
+
+func TestIntegrationDeletedCreator(t *testing.T) {
+	var sqlStore db.DB
+	var cfg *setting.Cfg
+	var dashboardStore *dashboardsDB.DashboardStore
+	var publicdashboardStore *PublicDashboardStoreImpl
+	var savedDashboard *models.Dashboard
+
+	setup := func() {
+		sqlStore, cfg = db.InitTestDBwithCfg(t)
+		dashboardStore = dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore, cfg))
+		publicdashboardStore = ProvideStore(sqlStore)
+		savedDashboard = insertTestDashboard(t, dashboardStore, "testDashie", 1, 0, true)
+	}
+
+	t.Run("finds the public dashboards created by a user", func(t *testing.T) {
+		setup()
+		pubdash := insertPublicDashboard(t, publicdashboardStore, savedDashboard.Uid, savedDashboard.OrgId, true)
+		otherOrgPubdash := insertPublicDashboard(t, publicdashboardStore, "otherDashboard", 2, true)
+
+		pubdashes, err := publicdashboardStore.FindByCreatedBy(context.Background(), 1)
+		require.NoError(t, err)
+		require.Len(t, pubdashes, 2)
+		assert.ElementsMatch(t, []string{pubdash.Uid, otherOrgPubdash.Uid}, []string{pubdashes[0].Uid, pubdashes[1].Uid})
+
+		pubdashes, err = publicdashboardStore.FindByCreatedBy(context.Background(), 2)
+		require.NoError(t, err)
+		assert.Empty(t, pubdashes)
+	})
+
+	t.Run("flags the creator as deleted until the ownership is transferred", func(t *testing.T) {
+		setup()
+		pubdash := insertPublicDashboard(t, publicdashboardStore, savedDashboard.Uid, savedDashboard.OrgId, true)
+
+		require.NoError(t, publicdashboardStore.FlagCreatorDeleted(context.Background(), pubdash.Uid))
+
+		retrieved, err := publicdashboardStore.Find(context.Background(), pubdash.Uid)
+		require.NoError(t, err)
+		assert.True(t, retrieved.CreatorDeleted)
+		assert.True(t, retrieved.IsEnabled)
+
+		list, err := publicdashboardStore.FindAll(context.Background(), savedDashboard.OrgId)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.True(t, list[0].CreatorDeleted)
+
+		err = publicdashboardStore.UpdateOwner(context.Background(), TransferPublicDashboardOwnershipCommand{Uid: pubdash.Uid, CreatedBy: 8, UpdatedAt: DefaultTime})
+		require.NoError(t, err)
+
+		retrieved, err = publicdashboardStore.Find(context.Background(), pubdash.Uid)
+		require.NoError(t, err)
+		assert.False(t, retrieved.CreatorDeleted)
+	})
+
+	t.Run("deletes public dashboards", func(t *testing.T) {
+		setup()
+		pubdash := insertPublicDashboard(t, publicdashboardStore, savedDashboard.Uid, savedDashboard.OrgId, true)
+
+		require.NoError(t, publicdashboardStore.Delete(context.Background(), pubdash.Uid))
+
+		retrieved, err := publicdashboardStore.Find(context.Background(), pubdash.Uid)
+		require.NoError(t, err)
+		assert.Nil(t, retrieved)
+	})
 }
 
 func TestIntegrationGetOrgIdByAccessToken(t *testing.T) {
@@ -489,6 +741,134 @@ func TestIntegrationGetOrgIdByAccessToken(t *testing.T) {
 	})
 }
 
+func TestIntegrationFindByAccessTokens(t *testing.T) {
+	var dashboardStore *dashboardsDB.DashboardStore
+	var publicdashboardStore *PublicDashboardStoreImpl
+
+	setup := func() {
+		sqlStore, cfg := db.InitTestDBwithCfg(t)
+		dashboardStore = dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore, cfg))
+		publicdashboardStore = ProvideStore(sqlStore)
+	}
+
+	t.Run("FindByAccessTokens returns the public dashboards by access token", func(t *testing.T) {
+		setup()
+		enabled := insertPublicDashboard(t, publicdashboardStore, insertTestDashboard(t, dashboardStore, "enabled", 1, 0, false).Uid, 1, true)
+		disabled := insertPublicDashboard(t, publicdashboardStore, insertTestDashboard(t, dashboardStore, "disabled", 2, 0, false).Uid, 2, false)
+
+		found, err := publicdashboardStore.FindByAccessTokens(context.Background(), []string{enabled.AccessToken, disabled.AccessToken, enabled.AccessToken, "nonExistentAccessToken", ""})
+		require.NoError(t, err)
+
+		require.Len(t, found, 2)
+		assert.Equal(t, enabled.Uid, found[enabled.AccessToken].Uid)
+		assert.Equal(t, disabled.Uid, found[disabled.AccessToken].Uid)
+		assert.Equal(t, int64(2), found[disabled.AccessToken].OrgId)
+	})
+
+	t.Run("FindByAccessTokens looks up more access tokens than fit in a single query", func(t *testing.T) {
+		setup()
+		pubdash := insertPublicDashboard(t, publicdashboardStore, insertTestDashboard(t, dashboardStore, "last", 1, 0, false).Uid, 1, true)
+
+		accessTokens := make([]string, 0, 2*accessTokensBatchSize)
+		for i := 0; i < 2*accessTokensBatchSize; i++ {
+			accessTokens = append(accessTokens, fmt.Sprintf("accessToken%d", i))
+		}
+		accessTokens = append(accessTokens, pubdash.AccessToken)
+
+		found, err := publicdashboardStore.FindByAccessTokens(context.Background(), accessTokens)
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, pubdash.Uid, found[pubdash.AccessToken].Uid)
+	})
+
+	t.Run("FindByAccessTokens returns nothing without access tokens", func(t *testing.T) {
+		setup()
+
+		found, err := publicdashboardStore.FindByAccessTokens(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+}
+
+func TestIntegrationFindByDashboardUids(t *testing.T) {
+	var dashboardStore *dashboardsDB.DashboardStore
+	var publicdashboardStore *PublicDashboardStoreImpl
+
+	setup := func() {
+		sqlStore, cfg := db.InitTestDBwithCfg(t)
+		dashboardStore = dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore, cfg))
+		publicdashboardStore = ProvideStore(sqlStore)
+	}
+
+	t.Run("FindByDashboardUids returns the public dashboards of the org by dashboard uid", func(t *testing.T) {
+		setup()
+		enabled := insertPublicDashboard(t, publicdashboardStore, insertTestDashboard(t, dashboardStore, "enabled", 1, 0, false).Uid, 1, true)
+		disabled := insertPublicDashboard(t, publicdashboardStore, insertTestDashboard(t, dashboardStore, "disabled", 1, 0, false).Uid, 1, false)
+		otherOrg := insertPublicDashboard(t, publicdashboardStore, insertTestDashboard(t, dashboardStore, "other org", 2, 0, false).Uid, 2, true)
+		unshared := insertTestDashboard(t, dashboardStore, "unshared", 1, 0, false)
+
+		found, err := publicdashboardStore.FindByDashboardUids(context.Background(), 1, []string{enabled.DashboardUid, disabled.DashboardUid, otherOrg.DashboardUid, unshared.Uid, ""})
+		require.NoError(t, err)
+
+		require.Len(t, found, 2)
+		assert.Equal(t, enabled.Uid, found[enabled.DashboardUid].Uid)
+		assert.Equal(t, disabled.Uid, found[disabled.DashboardUid].Uid)
+	})
+
+	t.Run("FindByDashboardUids returns nothing without dashboard uids", func(t *testing.T) {
+		setup()
+
+		found, err := publicdashboardStore.FindByDashboardUids(context.Background(), 1, nil)
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+
+	t.Run("a dashboard has a single public dashboard", func(t *testing.T) {
+		setup()
+		dashboard := insertTestDashboard(t, dashboardStore, "shared", 1, 0, false)
+		insertPublicDashboard(t, publicdashboardStore, dashboard.Uid, 1, true)
+
+		err := publicdashboardStore.Save(context.Background(), SavePublicDashboardConfigCommand{
+			PublicDashboard: PublicDashboard{
+				Uid:          util.GenerateShortUID(),
+				DashboardUid: dashboard.Uid,
+				OrgId:        1,
+				TimeSettings: &TimeSettings{},
+				CreatedBy:    1,
+				CreatedAt:    time.Now(),
+				AccessToken:  "duplicateAccessToken",
+			},
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestIntegrationFindSharedByFolders(t *testing.T) {
+	sqlStore, cfg := db.InitTestDBwithCfg(t)
+	dashboardStore := dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore, cfg))
+	publicdashboardStore := ProvideStore(sqlStore)
+
+	insertSharedByFolder := func(t *testing.T, title string, orgId int64, isEnabled bool, sharedByFolderUid string) *PublicDashboard {
+		t.Helper()
+		pubdash := insertPublicDashboard(t, publicdashboardStore, insertTestDashboard(t, dashboardStore, title, orgId, 0, false).Uid, orgId, isEnabled)
+		pubdash.SharedByFolderUid = sharedByFolderUid
+		require.NoError(t, publicdashboardStore.Update(context.Background(), SavePublicDashboardConfigCommand{PublicDashboard: *pubdash}))
+		return pubdash
+	}
+
+	shared := insertSharedByFolder(t, "shared", 1, true, "folder1")
+	insertSharedByFolder(t, "disabled", 1, false, "folder1")
+	insertSharedByFolder(t, "own", 1, true, "")
+	insertSharedByFolder(t, "other org", 2, true, "folder2")
+
+	found, err := publicdashboardStore.FindSharedByFolders(context.Background(), 1)
+	require.NoError(t, err)
+
+	require.Len(t, found, 1)
+	assert.Equal(t, shared.Uid, found[0].Uid)
+	assert.Equal(t, "folder1", found[0].SharedByFolderUid)
+}
+
 // helper function to insert a dashboard
 func insertTestDashboard(t *testing.T, dashboardStore *dashboardsDB.DashboardStore, title string, orgId int64,
 	folderId int64, isFolder bool, tags ...interface{}) *models.Dashboard {
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+// Define the storage implementation of the magic links and sessions of email-gated public dashboards. We're
+// generating the mock implementation automatically
+type EmailSessionStoreImpl struct {
+	sqlStore db.DB
+	log      log.Logger
+}
+
+var EmailSessionLogPrefix = "publicdashboards.email_session.store"
+
+// Gives us a compile time error if our database does not adhere to contract of
+// the interface
+var _ publicdashboards.EmailSessionStore = (*EmailSessionStoreImpl)(nil)
+
+// Factory used by wire to dependency injection
+func ProvideEmailSessionStore(sqlStore db.DB) *EmailSessionStoreImpl {
+	return &EmailSessionStoreImpl{
+		sqlStore: sqlStore,
+		log:      log.New(EmailSessionLogPrefix),
+	}
+}
+
+// SaveMagicLink Persists a magic link and removes the expired ones
+func (d *EmailSessionStoreImpl) SaveMagicLink(ctx context.Context, link *PublicDashboardMagicLink) error {
+	return d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("DELETE FROM dashboard_public_magic_link WHERE expires_at < ?", time.Now()); err != nil {
+			return err
+		}
+
+		_, err := sess.Insert(link)
+		return err
+	})
+}
+
+// ConsumeMagicLink Returns the magic link of a token hash and deletes it so it can only be used once. Returns nil
+// if not found
+func (d *EmailSessionStoreImpl) ConsumeMagicLink(ctx context.Context, tokenHash string) (*PublicDashboardMagicLink, error) {
+	link := &PublicDashboardMagicLink{TokenHash: tokenHash}
+
+	var found bool
+	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		found, err = sess.Get(link)
+		if err != nil || !found {
+			return err
+		}
+
+		// another request may have consumed the link in the meantime
+		affected, err := sess.Delete(&PublicDashboardMagicLink{TokenHash: tokenHash})
+		if err != nil {
+			return err
+		}
+		found = affected > 0
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return link, nil
+}
+
+// SaveSession Persists a session and removes the expired ones
+func (d *EmailSessionStoreImpl) SaveSession(ctx context.Context, session *PublicDashboardSession) error {
+	return d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("DELETE FROM dashboard_public_session WHERE expires_at < ?", time.Now()); err != nil {
+			return err
+		}
+
+		_, err := sess.Insert(session)
+		return err
+	})
+}
+
+// FindSession Returns the session of a token hash or nil if not found
+func (d *EmailSessionStoreImpl) FindSession(ctx context.Context, tokenHash string) (*PublicDashboardSession, error) {
+	session := &PublicDashboardSession{TokenHash: tokenHash}
+
+	var found bool
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		found, err = sess.Get(session)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return session, nil
+}
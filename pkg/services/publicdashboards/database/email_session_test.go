@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+func TestIntegrationEmailSessionStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	var emailSessionStore *EmailSessionStoreImpl
+
+	setup := func() {
+		emailSessionStore = ProvideEmailSessionStore(db.InitTestDB(t))
+	}
+
+	t.Run("ConsumeMagicLink returns a magic link only once", func(t *testing.T) {
+		setup()
+
+		err := emailSessionStore.SaveMagicLink(context.Background(), &PublicDashboardMagicLink{
+			TokenHash:          "hash",
+			PublicDashboardUid: "pubdash1",
+			Email:              "viewer@example.com",
+			ExpiresAt:          time.Now().Add(time.Minute),
+			CreatedAt:          time.Now(),
+		})
+		require.NoError(t, err)
+
+		link, err := emailSessionStore.ConsumeMagicLink(context.Background(), "hash")
+		require.NoError(t, err)
+		require.NotNil(t, link)
+		assert.Equal(t, "pubdash1", link.PublicDashboardUid)
+		assert.Equal(t, "viewer@example.com", link.Email)
+
+		link, err = emailSessionStore.ConsumeMagicLink(context.Background(), "hash")
+		require.NoError(t, err)
+		assert.Nil(t, link)
+	})
+
+	t.Run("SaveMagicLink removes expired magic links", func(t *testing.T) {
+		setup()
+
+		err := emailSessionStore.SaveMagicLink(context.Background(), &PublicDashboardMagicLink{
+			TokenHash:          "expired",
+			PublicDashboardUid: "pubdash1",
+			Email:              "viewer@example.com",
+			ExpiresAt:          time.Now().Add(-time.Minute),
+			CreatedAt:          time.Now().Add(-time.Hour),
+		})
+		require.NoError(t, err)
+
+		err = emailSessionStore.SaveMagicLink(context.Background(), &PublicDashboardMagicLink{
+			TokenHash:          "hash",
+			PublicDashboardUid: "pubdash1",
+			Email:              "viewer@example.com",
+			ExpiresAt:          time.Now().Add(time.Minute),
+			CreatedAt:          time.Now(),
+		})
+		require.NoError(t, err)
+
+		link, err := emailSessionStore.ConsumeMagicLink(context.Background(), "expired")
+		require.NoError(t, err)
+		assert.Nil(t, link)
+	})
+
+	t.Run("FindSession returns the session of a token hash", func(t *testing.T) {
+		setup()
+
+		err := emailSessionStore.SaveSession(context.Background(), &PublicDashboardSession{
+			TokenHash:          "hash",
+			PublicDashboardUid: "pubdash1",
+			Email:              "viewer@example.com",
+			ExpiresAt:          time.Now().Add(time.Hour),
+			CreatedAt:          time.Now(),
+		})
+		require.NoError(t, err)
+
+		session, err := emailSessionStore.FindSession(context.Background(), "hash")
+		require.NoError(t, err)
+		require.NotNil(t, session)
+		assert.Equal(t, "pubdash1", session.PublicDashboardUid)
+		assert.Equal(t, "viewer@example.com", session.Email)
+
+		// the session can be used several times
+		session, err = emailSessionStore.FindSession(context.Background(), "hash")
+		require.NoError(t, err)
+		assert.NotNil(t, session)
+
+		session, err = emailSessionStore.FindSession(context.Background(), "unknown")
+		require.NoError(t, err)
+		assert.Nil(t, session)
+	})
+}
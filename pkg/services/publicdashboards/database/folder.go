@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+// Define the storage implementation of public folders. We're generating the mock implementation
+// automatically
+type PublicFolderStoreImpl struct {
+	sqlStore db.DB
+	log      log.Logger
+}
+
+var FolderLogPrefix = "publicdashboards.folder.store"
+
+// Gives us a compile time error if our database does not adhere to contract of
+// the interface
+var _ publicdashboards.FolderStore = (*PublicFolderStoreImpl)(nil)
+
+// Factory used by wire to dependency injection
+func ProvideFolderStore(sqlStore db.DB) *PublicFolderStoreImpl {
+	return &PublicFolderStoreImpl{
+		sqlStore: sqlStore,
+		log:      log.New(FolderLogPrefix),
+	}
+}
+
+// Find Returns the public folder configuration of a folder or nil if not found
+func (d *PublicFolderStoreImpl) Find(ctx context.Context, orgId int64, folderUid string) (*PublicFolder, error) {
+	if folderUid == "" {
+		return nil, nil
+	}
+
+	return d.find(ctx, &PublicFolder{OrgId: orgId, FolderUid: folderUid})
+}
+
+// FindByAccessToken Returns a public folder configuration by access token or nil if not found
+func (d *PublicFolderStoreImpl) FindByAccessToken(ctx context.Context, accessToken string) (*PublicFolder, error) {
+	if accessToken == "" {
+		return nil, ErrPublicDashboardIdentifierNotSet
+	}
+
+	return d.find(ctx, &PublicFolder{AccessToken: accessToken})
+}
+
+func (d *PublicFolderStoreImpl) find(ctx context.Context, folder *PublicFolder) (*PublicFolder, error) {
+	var found bool
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		found, err = sess.Get(folder)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return folder, nil
+}
+
+// FindDashboards Returns the dashboards directly inside a folder ordered by title
+func (d *PublicFolderStoreImpl) FindDashboards(ctx context.Context, orgId int64, folderId int64) ([]*models.Dashboard, error) {
+	dashboards := make([]*models.Dashboard, 0)
+
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND folder_id = ?", orgId, folderId).
+			Where("is_folder = " + d.sqlStore.GetDialect().BooleanStr(false)).
+			OrderBy("title ASC").
+			Find(&dashboards)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return dashboards, nil
+}
+
+// Save Persists a public folder configuration
+func (d *PublicFolderStoreImpl) Save(ctx context.Context, folder *PublicFolder) error {
+	return d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(folder)
+		return err
+	})
+}
+
+// Update updates whether an existing public folder is enabled
+func (d *PublicFolderStoreImpl) Update(ctx context.Context, folder *PublicFolder) error {
+	return d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", folder.OrgId, folder.Uid).
+			Cols("is_enabled", "updated_by", "updated_at").
+			Update(folder)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrPublicFolderNotFound
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	dashboardsDB "github.com/grafana/grafana/pkg/services/dashboards/database"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/internal/tokens"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestIntegrationPublicFolder(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	var dashboardStore *dashboardsDB.DashboardStore
+	var folderStore *PublicFolderStoreImpl
+
+	setup := func() {
+		sqlStore, cfg := db.InitTestDBwithCfg(t)
+		dashboardStore = dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore, cfg))
+		folderStore = ProvideFolderStore(sqlStore)
+	}
+
+	t.Run("Save persists the public folder", func(t *testing.T) {
+		setup()
+		folder := insertPublicFolder(t, folderStore, "folder1", 1, true)
+
+		found, err := folderStore.Find(context.Background(), 1, "folder1")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, folder.Uid, found.Uid)
+		assert.True(t, found.IsEnabled)
+
+		found, err = folderStore.FindByAccessToken(context.Background(), folder.AccessToken)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "folder1", found.FolderUid)
+	})
+
+	t.Run("Find returns nil for a folder of another org", func(t *testing.T) {
+		setup()
+		insertPublicFolder(t, folderStore, "folder1", 1, true)
+
+		found, err := folderStore.Find(context.Background(), 2, "folder1")
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("Update changes whether the public folder is enabled", func(t *testing.T) {
+		setup()
+		folder := insertPublicFolder(t, folderStore, "folder1", 1, true)
+
+		err := folderStore.Update(context.Background(), &PublicFolder{Uid: folder.Uid, OrgId: 1, IsEnabled: false, UpdatedBy: 2, UpdatedAt: DefaultTime})
+		require.NoError(t, err)
+
+		found, err := folderStore.Find(context.Background(), 1, "folder1")
+		require.NoError(t, err)
+		assert.False(t, found.IsEnabled)
+		assert.Equal(t, folder.AccessToken, found.AccessToken)
+		assert.Equal(t, int64(2), found.UpdatedBy)
+	})
+
+	t.Run("Update returns ErrPublicFolderNotFound for a public folder of another org", func(t *testing.T) {
+		setup()
+		folder := insertPublicFolder(t, folderStore, "folder1", 1, true)
+
+		err := folderStore.Update(context.Background(), &PublicFolder{Uid: folder.Uid, OrgId: 2})
+		require.ErrorIs(t, err, ErrPublicFolderNotFound)
+	})
+
+	t.Run("FindDashboards returns the dashboards directly inside the folder by title", func(t *testing.T) {
+		setup()
+		folder := insertTestDashboard(t, dashboardStore, "status", 1, 0, true)
+		insertTestDashboard(t, dashboardStore, "b", 1, folder.Id, false)
+		insertTestDashboard(t, dashboardStore, "a", 1, folder.Id, false)
+		insertTestDashboard(t, dashboardStore, "nested folder", 1, folder.Id, true)
+		insertTestDashboard(t, dashboardStore, "outside", 1, 0, false)
+
+		dashboards, err := folderStore.FindDashboards(context.Background(), 1, folder.Id)
+		require.NoError(t, err)
+		require.Len(t, dashboards, 2)
+		assert.Equal(t, "a", dashboards[0].Title)
+		assert.Equal(t, "b", dashboards[1].Title)
+	})
+}
+
+// helper function to insert a public folder
+func insertPublicFolder(t *testing.T, folderStore *PublicFolderStoreImpl, folderUid string, orgId int64, isEnabled bool) *PublicFolder {
+	t.Helper()
+
+	accessToken, err := tokens.GenerateAccessToken()
+	require.NoError(t, err)
+
+	folder := &PublicFolder{
+		Uid:         util.GenerateShortUID(),
+		FolderUid:   folderUid,
+		OrgId:       orgId,
+		IsEnabled:   isEnabled,
+		AccessToken: accessToken,
+		CreatedBy:   1,
+		CreatedAt:   DefaultTime,
+	}
+
+	err = folderStore.Save(context.Background(), folder)
+	require.NoError(t, err)
+
+	return folder
+}
@@ -0,0 +1,156 @@
+package database
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+// Define the storage implementation of public playlists. We're generating the mock implementation
+// automatically
+type PublicPlaylistStoreImpl struct {
+	sqlStore db.DB
+	log      log.Logger
+}
+
+var PlaylistLogPrefix = "publicdashboards.playlist.store"
+
+// Gives us a compile time error if our database does not adhere to contract of
+// the interface
+var _ publicdashboards.PlaylistStore = (*PublicPlaylistStoreImpl)(nil)
+
+// Factory used by wire to dependency injection
+func ProvidePlaylistStore(sqlStore db.DB) *PublicPlaylistStoreImpl {
+	return &PublicPlaylistStoreImpl{
+		sqlStore: sqlStore,
+		log:      log.New(PlaylistLogPrefix),
+	}
+}
+
+// FindAll Returns the public playlists of an org, without their items
+func (d *PublicPlaylistStoreImpl) FindAll(ctx context.Context, orgId int64) ([]PublicPlaylist, error) {
+	resp := make([]PublicPlaylist, 0)
+
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgId).OrderBy("name ASC").Find(&resp)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Find Returns a public playlist with its items by Uid or nil if not found
+func (d *PublicPlaylistStoreImpl) Find(ctx context.Context, orgId int64, uid string) (*PublicPlaylist, error) {
+	if uid == "" {
+		return nil, nil
+	}
+
+	return d.find(ctx, &PublicPlaylist{OrgId: orgId, Uid: uid})
+}
+
+// FindByAccessToken Returns a public playlist with its items by access token or nil if not found
+func (d *PublicPlaylistStoreImpl) FindByAccessToken(ctx context.Context, accessToken string) (*PublicPlaylist, error) {
+	if accessToken == "" {
+		return nil, ErrPublicDashboardIdentifierNotSet
+	}
+
+	return d.find(ctx, &PublicPlaylist{AccessToken: accessToken})
+}
+
+func (d *PublicPlaylistStoreImpl) find(ctx context.Context, playlist *PublicPlaylist) (*PublicPlaylist, error) {
+	var found bool
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		found, err = sess.Get(playlist)
+		if err != nil || !found {
+			return err
+		}
+
+		playlist.Items = make([]PublicPlaylistItem, 0)
+		return sess.Where("playlist_uid = ?", playlist.Uid).OrderBy("position ASC").Find(&playlist.Items)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return playlist, nil
+}
+
+// Save Persists a public playlist and its items
+func (d *PublicPlaylistStoreImpl) Save(ctx context.Context, playlist *PublicPlaylist) error {
+	return d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Insert(playlist); err != nil {
+			return err
+		}
+
+		return insertPlaylistItems(sess, playlist)
+	})
+}
+
+// Update updates the name and replaces the items of an existing public playlist
+func (d *PublicPlaylistStoreImpl) Update(ctx context.Context, playlist *PublicPlaylist) error {
+	return d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", playlist.OrgId, playlist.Uid).
+			Cols("name", "updated_by", "updated_at").
+			Update(playlist)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrPublicPlaylistNotFound
+		}
+
+		if _, err := sess.Exec("DELETE FROM dashboard_public_playlist_item WHERE playlist_uid = ?", playlist.Uid); err != nil {
+			return err
+		}
+
+		return insertPlaylistItems(sess, playlist)
+	})
+}
+
+// Delete removes a public playlist and its items
+func (d *PublicPlaylistStoreImpl) Delete(ctx context.Context, orgId int64, uid string) error {
+	return d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM dashboard_public_playlist WHERE org_id = ? AND uid = ?", orgId, uid)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrPublicPlaylistNotFound
+		}
+
+		_, err = sess.Exec("DELETE FROM dashboard_public_playlist_item WHERE playlist_uid = ?", uid)
+		return err
+	})
+}
+
+func insertPlaylistItems(sess *db.Session, playlist *PublicPlaylist) error {
+	if len(playlist.Items) == 0 {
+		return nil
+	}
+
+	for i := range playlist.Items {
+		playlist.Items[i].Id = 0
+		playlist.Items[i].PlaylistUid = playlist.Uid
+		playlist.Items[i].Position = i
+	}
+
+	_, err := sess.Insert(&playlist.Items)
+	return err
+}
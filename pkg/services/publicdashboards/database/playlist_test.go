@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/internal/tokens"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestIntegrationPublicPlaylist(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	var sqlStore db.DB
+	var playlistStore *PublicPlaylistStoreImpl
+
+	setup := func() {
+		sqlStore = db.InitTestDB(t, db.InitTestDBOpt{FeatureFlags: []string{featuremgmt.FlagPublicDashboards}})
+		playlistStore = ProvidePlaylistStore(sqlStore)
+	}
+
+	t.Run("Save persists the playlist and its items in order", func(t *testing.T) {
+		setup()
+		playlist := insertPublicPlaylist(t, playlistStore, "lobby", 1, "token1", "token2")
+
+		found, err := playlistStore.Find(context.Background(), 1, playlist.Uid)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "lobby", found.Name)
+		assert.Equal(t, playlist.AccessToken, found.AccessToken)
+		require.Len(t, found.Items, 2)
+		assert.Equal(t, "token1", found.Items[0].AccessToken)
+		assert.Equal(t, int64(30), found.Items[0].DwellSeconds)
+		assert.Equal(t, "token2", found.Items[1].AccessToken)
+
+		found, err = playlistStore.FindByAccessToken(context.Background(), playlist.AccessToken)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, playlist.Uid, found.Uid)
+		assert.Len(t, found.Items, 2)
+	})
+
+	t.Run("Find returns nil when the playlist belongs to another org", func(t *testing.T) {
+		setup()
+		playlist := insertPublicPlaylist(t, playlistStore, "lobby", 1, "token1")
+
+		found, err := playlistStore.Find(context.Background(), 2, playlist.Uid)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("FindAll returns the playlists of the org by name", func(t *testing.T) {
+		setup()
+		insertPublicPlaylist(t, playlistStore, "b", 1)
+		insertPublicPlaylist(t, playlistStore, "a", 1)
+		insertPublicPlaylist(t, playlistStore, "other org", 2)
+
+		playlists, err := playlistStore.FindAll(context.Background(), 1)
+		require.NoError(t, err)
+		require.Len(t, playlists, 2)
+		assert.Equal(t, "a", playlists[0].Name)
+		assert.Equal(t, "b", playlists[1].Name)
+	})
+
+	t.Run("Update replaces the name and items", func(t *testing.T) {
+		setup()
+		playlist := insertPublicPlaylist(t, playlistStore, "lobby", 1, "token1", "token2")
+
+		err := playlistStore.Update(context.Background(), &PublicPlaylist{
+			Uid:       playlist.Uid,
+			OrgId:     1,
+			Name:      "hall",
+			UpdatedBy: 2,
+			UpdatedAt: DefaultTime,
+			Items:     []PublicPlaylistItem{{AccessToken: "token3", DwellSeconds: 10}},
+		})
+		require.NoError(t, err)
+
+		found, err := playlistStore.Find(context.Background(), 1, playlist.Uid)
+		require.NoError(t, err)
+		assert.Equal(t, "hall", found.Name)
+		assert.Equal(t, playlist.AccessToken, found.AccessToken)
+		assert.Equal(t, int64(2), found.UpdatedBy)
+		require.Len(t, found.Items, 1)
+		assert.Equal(t, "token3", found.Items[0].AccessToken)
+	})
+
+	t.Run("Update returns ErrPublicPlaylistNotFound for a playlist of another org", func(t *testing.T) {
+		setup()
+		playlist := insertPublicPlaylist(t, playlistStore, "lobby", 1, "token1")
+
+		err := playlistStore.Update(context.Background(), &PublicPlaylist{Uid: playlist.Uid, OrgId: 2, Name: "hall"})
+		require.ErrorIs(t, err, ErrPublicPlaylistNotFound)
+	})
+
+	t.Run("Delete removes the playlist and its items", func(t *testing.T) {
+		setup()
+		playlist := insertPublicPlaylist(t, playlistStore, "lobby", 1, "token1")
+
+		err := playlistStore.Delete(context.Background(), 1, playlist.Uid)
+		require.NoError(t, err)
+
+		found, err := playlistStore.Find(context.Background(), 1, playlist.Uid)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+
+		var items []PublicPlaylistItem
+		err = sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			return sess.Where("playlist_uid = ?", playlist.Uid).Find(&items)
+		})
+		require.NoError(t, err)
+		assert.Empty(t, items)
+
+		err = playlistStore.Delete(context.Background(), 1, playlist.Uid)
+		require.ErrorIs(t, err, ErrPublicPlaylistNotFound)
+	})
+}
+
+// helper function to insert a public playlist
+func insertPublicPlaylist(t *testing.T, playlistStore *PublicPlaylistStoreImpl, name string, orgId int64, itemAccessTokens ...string) *PublicPlaylist {
+	t.Helper()
+
+	accessToken, err := tokens.GenerateAccessToken()
+	require.NoError(t, err)
+
+	playlist := &PublicPlaylist{
+		Uid:         util.GenerateShortUID(),
+		OrgId:       orgId,
+		Name:        name,
+		AccessToken: accessToken,
+		CreatedBy:   1,
+		CreatedAt:   DefaultTime,
+	}
+	for _, itemAccessToken := range itemAccessTokens {
+		playlist.Items = append(playlist.Items, PublicPlaylistItem{AccessToken: itemAccessToken, DwellSeconds: 30})
+	}
+
+	err = playlistStore.Save(context.Background(), playlist)
+	require.NoError(t, err)
+
+	return playlist
+}
@@ -0,0 +1,258 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+// Define the storage implementation of the usage counters and report subscriptions of public dashboards. We're
+// generating the mock implementation automatically
+type ReportStoreImpl struct {
+	sqlStore db.DB
+	log      log.Logger
+}
+
+var ReportLogPrefix = "publicdashboards.report.store"
+
+// Gives us a compile time error if our database does not adhere to contract of
+// the interface
+var _ publicdashboards.ReportStore = (*ReportStoreImpl)(nil)
+
+// Factory used by wire to dependency injection
+func ProvideReportStore(sqlStore db.DB) *ReportStoreImpl {
+	return &ReportStoreImpl{
+		sqlStore: sqlStore,
+		log:      log.New(ReportLogPrefix),
+	}
+}
+
+// RecordUsage Adds the query and error counts of usage to the usage of its public dashboard for its day
+func (d *ReportStoreImpl) RecordUsage(ctx context.Context, usage *PublicDashboardUsage) error {
+	return d.incrementUsage(ctx, &PublicDashboardUsage{
+		PublicDashboardUid: usage.PublicDashboardUid,
+		OrgId:              usage.OrgId,
+		Day:                usage.Day,
+		QueryCount:         usage.QueryCount,
+		ErrorCount:         usage.ErrorCount,
+	})
+}
+
+// RecordView Counts a view of a public dashboard, and whether the viewer was anonymous, for the day
+func (d *ReportStoreImpl) RecordView(ctx context.Context, orgId int64, publicDashboardUid string, day string, anonymous bool) error {
+	usage := &PublicDashboardUsage{PublicDashboardUid: publicDashboardUid, OrgId: orgId, Day: day, ViewCount: 1}
+	if anonymous {
+		usage.AnonymousViewCount = 1
+	}
+
+	return d.incrementUsage(ctx, usage)
+}
+
+// incrementUsage adds the counters of usage to the usage of the public dashboard for the day, creating it if needed.
+// When another instance creates the usage of the day first, the insert violates the unique index and the counters
+// are added to the created usage instead
+func (d *ReportStoreImpl) incrementUsage(ctx context.Context, usage *PublicDashboardUsage) error {
+	return d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		update := func() (int64, error) {
+			res, err := sess.Exec("UPDATE dashboard_public_usage SET query_count = query_count + ?, error_count = error_count + ?, "+
+				"view_count = view_count + ?, anonymous_view_count = anonymous_view_count + ? WHERE public_dashboard_uid = ? AND day = ?",
+				usage.QueryCount, usage.ErrorCount, usage.ViewCount, usage.AnonymousViewCount, usage.PublicDashboardUid, usage.Day)
+			if err != nil {
+				return 0, err
+			}
+			return res.RowsAffected()
+		}
+
+		affected, err := update()
+		if err != nil || affected > 0 {
+			return err
+		}
+
+		_, err = sess.Insert(usage)
+		if err != nil && d.sqlStore.GetDialect().IsUniqueConstraintViolation(err) {
+			_, err = update()
+		}
+		return err
+	})
+}
+
+// GetUsageStats Returns the usage of all public dashboards between two days, the last one excluded
+func (d *ReportStoreImpl) GetUsageStats(ctx context.Context, fromDay string, toDay string) (*PublicDashboardUsageStats, error) {
+	stats := &PublicDashboardUsageStats{}
+
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.SQL("SELECT COALESCE(SUM(view_count), 0) AS view_count, COALESCE(SUM(anonymous_view_count), 0) AS anonymous_view_count, "+
+			"COALESCE(SUM(query_count), 0) AS query_count, COALESCE(SUM(error_count), 0) AS error_count, "+
+			"COUNT(DISTINCT CASE WHEN view_count > 0 THEN public_dashboard_uid END) AS viewed_count "+
+			"FROM dashboard_public_usage WHERE day >= ? AND day < ?", fromDay, toDay).Get(stats)
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.SQL("SELECT COALESCE(MAX(views), 0) FROM (SELECT SUM(view_count) AS views FROM dashboard_public_usage "+
+			"WHERE day >= ? AND day < ? GROUP BY public_dashboard_uid) usage_by_dashboard", fromDay, toDay).Get(&stats.TopViewCount)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// FindUsageSummaries Returns the usage of the public dashboards a user created between two days, the last one
+// excluded. Public dashboards without usage are listed with zero counts
+func (d *ReportStoreImpl) FindUsageSummaries(ctx context.Context, orgId int64, userId int64, fromDay string, toDay string) ([]PublicDashboardUsageSummary, error) {
+	summaries := make([]PublicDashboardUsageSummary, 0)
+
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("dashboard_public").
+			Join("INNER", "dashboard", "dashboard.uid = dashboard_public.dashboard_uid AND dashboard.org_id = dashboard_public.org_id").
+			Join("LEFT", "dashboard_public_usage", "dashboard_public_usage.public_dashboard_uid = dashboard_public.uid AND dashboard_public_usage.day >= ? AND dashboard_public_usage.day < ?", fromDay, toDay).
+			Select("dashboard_public.uid AS public_dashboard_uid, dashboard_public.dashboard_uid, dashboard.title, dashboard_public.is_enabled, "+
+				"COALESCE(SUM(dashboard_public_usage.query_count), 0) AS query_count, COALESCE(SUM(dashboard_public_usage.error_count), 0) AS error_count").
+			Where("dashboard_public.org_id = ? AND dashboard_public.created_by = ?", orgId, userId).
+			GroupBy("dashboard_public.uid, dashboard_public.dashboard_uid, dashboard.title, dashboard_public.is_enabled").
+			OrderBy("dashboard.title ASC").
+			Find(&summaries)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+// RecordPanelLatency Counts a query of a panel of a public dashboard and adds its duration for the day
+func (d *ReportStoreImpl) RecordPanelLatency(ctx context.Context, orgId int64, publicDashboardUid string, panelId int64, day string, durationMs int64) error {
+	return d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE dashboard_public_panel_usage SET query_count = query_count + 1, duration_ms = duration_ms + ? "+
+			"WHERE public_dashboard_uid = ? AND panel_id = ? AND day = ?", durationMs, publicDashboardUid, panelId, day)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil || affected > 0 {
+			return err
+		}
+
+		_, err = sess.Insert(&PublicDashboardPanelUsage{
+			PublicDashboardUid: publicDashboardUid,
+			OrgId:              orgId,
+			PanelId:            panelId,
+			Day:                day,
+			QueryCount:         1,
+			DurationMs:         durationMs,
+		})
+		return err
+	})
+}
+
+// FindPanelLatencies Returns the average query duration in milliseconds of the panels of a public dashboard
+// between two days, the last one excluded. Panels without queries are not listed
+func (d *ReportStoreImpl) FindPanelLatencies(ctx context.Context, publicDashboardUid string, fromDay string, toDay string) (map[int64]int64, error) {
+	var rows []struct {
+		PanelId    int64 `xorm:"panel_id"`
+		QueryCount int64 `xorm:"query_count"`
+		DurationMs int64 `xorm:"duration_ms"`
+	}
+
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL("SELECT panel_id, SUM(query_count) AS query_count, SUM(duration_ms) AS duration_ms FROM dashboard_public_panel_usage "+
+			"WHERE public_dashboard_uid = ? AND day >= ? AND day < ? GROUP BY panel_id", publicDashboardUid, fromDay, toDay).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	latencies := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		if row.QueryCount > 0 {
+			latencies[row.PanelId] = row.DurationMs / row.QueryCount
+		}
+	}
+
+	return latencies, nil
+}
+
+// FindSubscription Returns the report subscription of a user or nil if not found
+func (d *ReportStoreImpl) FindSubscription(ctx context.Context, orgId int64, userId int64) (*PublicDashboardReportSubscription, error) {
+	subscription := &PublicDashboardReportSubscription{OrgId: orgId, UserId: userId}
+
+	var found bool
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		found, err = sess.Get(subscription)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return subscription, nil
+}
+
+// FindDueSubscriptions Returns the report subscriptions whose last digest was sent before the given time
+func (d *ReportStoreImpl) FindDueSubscriptions(ctx context.Context, sentBefore time.Time) ([]*PublicDashboardReportSubscription, error) {
+	subscriptions := make([]*PublicDashboardReportSubscription, 0)
+
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("last_sent_at <= ?", sentBefore).OrderBy("id ASC").Find(&subscriptions)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+// SaveSubscription Persists a report subscription
+func (d *ReportStoreImpl) SaveSubscription(ctx context.Context, subscription *PublicDashboardReportSubscription) error {
+	return d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(subscription)
+		return err
+	})
+}
+
+// UpdateSubscription Updates the email address and last digest of a report subscription
+func (d *ReportStoreImpl) UpdateSubscription(ctx context.Context, subscription *PublicDashboardReportSubscription) error {
+	return d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.ID(subscription.Id).Cols("email", "last_sent_at").Update(subscription)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrPublicDashboardReportSubscriptionNotFound
+		}
+
+		return nil
+	})
+}
+
+// DeleteSubscription Deletes the report subscription of a user
+func (d *ReportStoreImpl) DeleteSubscription(ctx context.Context, orgId int64, userId int64) error {
+	return d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Delete(&PublicDashboardReportSubscription{OrgId: orgId, UserId: userId})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrPublicDashboardReportSubscriptionNotFound
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,216 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	dashboardsDB "github.com/grafana/grafana/pkg/services/dashboards/database"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
+)
+
+func TestIntegrationReportStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	var dashboardStore *dashboardsDB.DashboardStore
+	var publicdashboardStore *PublicDashboardStoreImpl
+	var reportStore *ReportStoreImpl
+
+	setup := func() {
+		sqlStore, cfg := db.InitTestDBwithCfg(t)
+		dashboardStore = dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore, cfg))
+		publicdashboardStore = ProvideStore(sqlStore)
+		reportStore = ProvideReportStore(sqlStore)
+	}
+
+	t.Run("FindUsageSummaries sums the usage of the public dashboards of a user over the period", func(t *testing.T) {
+		setup()
+		busy := insertPublicDashboard(t, publicdashboardStore, insertTestDashboard(t, dashboardStore, "busy", 1, 0, false).Uid, 1, true)
+		idle := insertPublicDashboard(t, publicdashboardStore, insertTestDashboard(t, dashboardStore, "idle", 1, 0, false).Uid, 1, false)
+
+		ctx := context.Background()
+		require.NoError(t, reportStore.RecordUsage(ctx, newUsage(1, busy.Uid, "2022-10-01", false)))
+		require.NoError(t, reportStore.RecordUsage(ctx, newUsage(1, busy.Uid, "2022-10-01", true)))
+		require.NoError(t, reportStore.RecordUsage(ctx, newUsage(1, busy.Uid, "2022-10-03", false)))
+		// outside of the period
+		require.NoError(t, reportStore.RecordUsage(ctx, newUsage(1, busy.Uid, "2022-09-30", true)))
+		require.NoError(t, reportStore.RecordUsage(ctx, newUsage(1, busy.Uid, "2022-10-08", true)))
+
+		summaries, err := reportStore.FindUsageSummaries(ctx, 1, 1, "2022-10-01", "2022-10-08")
+		require.NoError(t, err)
+		require.Len(t, summaries, 2)
+
+		assert.Equal(t, busy.Uid, summaries[0].PublicDashboardUid)
+		assert.Equal(t, "busy", summaries[0].Title)
+		assert.True(t, summaries[0].IsEnabled)
+		assert.Equal(t, int64(3), summaries[0].QueryCount)
+		assert.Equal(t, int64(1), summaries[0].ErrorCount)
+
+		assert.Equal(t, idle.Uid, summaries[1].PublicDashboardUid)
+		assert.False(t, summaries[1].IsEnabled)
+		assert.Equal(t, int64(0), summaries[1].QueryCount)
+		assert.Equal(t, int64(0), summaries[1].ErrorCount)
+	})
+
+	t.Run("RecordUsage adds the counts to the usage of the day", func(t *testing.T) {
+		setup()
+		pubdash := insertPublicDashboard(t, publicdashboardStore, insertTestDashboard(t, dashboardStore, "busy", 1, 0, false).Uid, 1, true)
+
+		ctx := context.Background()
+		require.NoError(t, reportStore.RecordUsage(ctx, &PublicDashboardUsage{OrgId: 1, PublicDashboardUid: pubdash.Uid, Day: "2022-10-01", QueryCount: 5, ErrorCount: 2}))
+		require.NoError(t, reportStore.RecordUsage(ctx, &PublicDashboardUsage{OrgId: 1, PublicDashboardUid: pubdash.Uid, Day: "2022-10-01", QueryCount: 3}))
+
+		summaries, err := reportStore.FindUsageSummaries(ctx, 1, 1, "2022-10-01", "2022-10-02")
+		require.NoError(t, err)
+		require.Len(t, summaries, 1)
+		assert.Equal(t, int64(8), summaries[0].QueryCount)
+		assert.Equal(t, int64(2), summaries[0].ErrorCount)
+	})
+
+	t.Run("FindUsageSummaries only returns the public dashboards created by the user", func(t *testing.T) {
+		setup()
+		insertPublicDashboard(t, publicdashboardStore, insertTestDashboard(t, dashboardStore, "mine", 1, 0, false).Uid, 1, true)
+
+		summaries, err := reportStore.FindUsageSummaries(context.Background(), 1, 2, "2022-10-01", "2022-10-08")
+		require.NoError(t, err)
+		assert.Empty(t, summaries)
+
+		summaries, err = reportStore.FindUsageSummaries(context.Background(), 2, 1, "2022-10-01", "2022-10-08")
+		require.NoError(t, err)
+		assert.Empty(t, summaries)
+	})
+
+	t.Run("GetUsageStats sums the views and queries of all public dashboards over the period", func(t *testing.T) {
+		setup()
+		ctx := context.Background()
+		require.NoError(t, reportStore.RecordView(ctx, 1, "pubdash1", "2022-10-01", true))
+		require.NoError(t, reportStore.RecordView(ctx, 1, "pubdash1", "2022-10-01", true))
+		require.NoError(t, reportStore.RecordView(ctx, 1, "pubdash1", "2022-10-01", false))
+		require.NoError(t, reportStore.RecordUsage(ctx, newUsage(1, "pubdash1", "2022-10-01", true)))
+		require.NoError(t, reportStore.RecordView(ctx, 2, "pubdash2", "2022-10-01", true))
+		require.NoError(t, reportStore.RecordUsage(ctx, newUsage(2, "pubdash2", "2022-10-01", false)))
+		require.NoError(t, reportStore.RecordUsage(ctx, newUsage(2, "pubdash3", "2022-10-01", false)))
+		// outside of the period
+		require.NoError(t, reportStore.RecordView(ctx, 1, "pubdash1", "2022-10-02", true))
+
+		stats, err := reportStore.GetUsageStats(ctx, "2022-10-01", "2022-10-02")
+		require.NoError(t, err)
+		assert.Equal(t, &PublicDashboardUsageStats{
+			ViewCount:          4,
+			AnonymousViewCount: 3,
+			QueryCount:         3,
+			ErrorCount:         1,
+			ViewedCount:        2,
+			TopViewCount:       3,
+		}, stats)
+
+		stats, err = reportStore.GetUsageStats(ctx, "2022-09-01", "2022-09-02")
+		require.NoError(t, err)
+		assert.Equal(t, &PublicDashboardUsageStats{}, stats)
+	})
+
+	t.Run("FindPanelLatencies averages the query durations of the panels over the period", func(t *testing.T) {
+		setup()
+		ctx := context.Background()
+		require.NoError(t, reportStore.RecordPanelLatency(ctx, 1, "pubdash1", 1, "2022-10-01", 100))
+		require.NoError(t, reportStore.RecordPanelLatency(ctx, 1, "pubdash1", 1, "2022-10-01", 300))
+		require.NoError(t, reportStore.RecordPanelLatency(ctx, 1, "pubdash1", 1, "2022-10-02", 800))
+		require.NoError(t, reportStore.RecordPanelLatency(ctx, 1, "pubdash1", 2, "2022-10-02", 50))
+		// other public dashboard
+		require.NoError(t, reportStore.RecordPanelLatency(ctx, 1, "pubdash2", 1, "2022-10-01", 9000))
+		// outside of the period
+		require.NoError(t, reportStore.RecordPanelLatency(ctx, 1, "pubdash1", 2, "2022-10-08", 9000))
+
+		latencies, err := reportStore.FindPanelLatencies(ctx, "pubdash1", "2022-10-01", "2022-10-08")
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]int64{1: 400, 2: 50}, latencies)
+
+		latencies, err = reportStore.FindPanelLatencies(ctx, "pubdash1", "2022-09-01", "2022-09-02")
+		require.NoError(t, err)
+		assert.Empty(t, latencies)
+	})
+
+	t.Run("FindDueSubscriptions returns the subscriptions whose last digest was sent before the given time", func(t *testing.T) {
+		setup()
+		now := time.Now().Truncate(time.Second)
+		due := insertReportSubscription(t, reportStore, 1, 1, now.Add(-8*24*time.Hour))
+		insertReportSubscription(t, reportStore, 1, 2, now.Add(-time.Hour))
+
+		subscriptions, err := reportStore.FindDueSubscriptions(context.Background(), now.Add(-7*24*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, subscriptions, 1)
+		assert.Equal(t, due.Id, subscriptions[0].Id)
+		assert.Equal(t, "user1@example.com", subscriptions[0].Email)
+	})
+
+	t.Run("UpdateSubscription changes the email address and last digest", func(t *testing.T) {
+		setup()
+		now := time.Now().Truncate(time.Second)
+		subscription := insertReportSubscription(t, reportStore, 1, 1, now.Add(-8*24*time.Hour))
+
+		subscription.Email = "new@example.com"
+		subscription.LastSentAt = now
+		require.NoError(t, reportStore.UpdateSubscription(context.Background(), subscription))
+
+		found, err := reportStore.FindSubscription(context.Background(), 1, 1)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "new@example.com", found.Email)
+		assert.True(t, now.Equal(found.LastSentAt))
+	})
+
+	t.Run("DeleteSubscription deletes the subscription of the user only", func(t *testing.T) {
+		setup()
+		insertReportSubscription(t, reportStore, 1, 1, time.Now())
+		insertReportSubscription(t, reportStore, 1, 2, time.Now())
+
+		require.NoError(t, reportStore.DeleteSubscription(context.Background(), 1, 1))
+
+		found, err := reportStore.FindSubscription(context.Background(), 1, 1)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+
+		found, err = reportStore.FindSubscription(context.Background(), 1, 2)
+		require.NoError(t, err)
+		assert.NotNil(t, found)
+
+		err = reportStore.DeleteSubscription(context.Background(), 1, 1)
+		require.ErrorIs(t, err, ErrPublicDashboardReportSubscriptionNotFound)
+	})
+}
+
+// helper function to insert a report subscription
+func insertReportSubscription(t *testing.T, reportStore *ReportStoreImpl, orgId int64, userId int64, lastSentAt time.Time) *PublicDashboardReportSubscription {
+	t.Helper()
+
+	subscription := &PublicDashboardReportSubscription{
+		OrgId:      orgId,
+		UserId:     userId,
+		Email:      fmt.Sprintf("user%d@example.com", userId),
+		LastSentAt: lastSentAt,
+		CreatedAt:  lastSentAt,
+	}
+
+	err := reportStore.SaveSubscription(context.Background(), subscription)
+	require.NoError(t, err)
+
+	return subscription
+}
+
+// helper function to build the usage of a single query
+func newUsage(orgId int64, publicDashboardUid string, day string, failed bool) *PublicDashboardUsage {
+	usage := &PublicDashboardUsage{OrgId: orgId, PublicDashboardUid: publicDashboardUid, Day: day, QueryCount: 1}
+	if failed {
+		usage.ErrorCount = 1
+	}
+	return usage
+}
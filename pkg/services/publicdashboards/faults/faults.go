@@ -0,0 +1,143 @@
+// Package faults injects latency, errors and partial failures in the store and query layers of public dashboards, so
+// their resilience can be verified from tests and, in development, from the admin API. Faults are never injected
+// outside of the development environment.
+package faults
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ErrInjectedFault is returned by the operations failed by the injector
+var ErrInjectedFault = errors.New("publicdashboards: injected fault")
+
+// Config describes the faults to inject. The zero value injects none
+type Config struct {
+	// latency added before every operation
+	LatencyMs int64 `json:"latencyMs"`
+	// probability, between 0 and 1, of an operation to fail with ErrInjectedFault
+	ErrorRate float64 `json:"errorRate"`
+	// probability, between 0 and 1, of each query of a successful query request to fail with ErrInjectedFault
+	PartialFailureRate float64 `json:"partialFailureRate"`
+	// names of the operations faults are injected in, such as "FindByAccessToken" or "QueryData". All operations when
+	// empty
+	Operations []string `json:"operations"`
+}
+
+// Validate returns an error if the latency is negative or a rate is not a probability
+func (c Config) Validate() error {
+	if c.LatencyMs < 0 {
+		return fmt.Errorf("latencyMs must not be negative")
+	}
+	if c.ErrorRate < 0 || c.ErrorRate > 1 {
+		return fmt.Errorf("errorRate must be between 0 and 1")
+	}
+	if c.PartialFailureRate < 0 || c.PartialFailureRate > 1 {
+		return fmt.Errorf("partialFailureRate must be between 0 and 1")
+	}
+	return nil
+}
+
+func (c Config) appliesTo(operation string) bool {
+	if len(c.Operations) == 0 {
+		return true
+	}
+	for _, o := range c.Operations {
+		if o == operation {
+			return true
+		}
+	}
+	return false
+}
+
+// Injector holds the faults currently injected. It is safe for concurrent use, and a nil Injector injects no fault
+type Injector struct {
+	mu     sync.RWMutex
+	config Config
+	random func() float64
+}
+
+// ProvideInjector Factory for method used by wire to inject dependencies. Faults can only be injected in the
+// development environment, the injector is nil otherwise
+func ProvideInjector(cfg *setting.Cfg) *Injector {
+	if cfg == nil || cfg.Env != setting.Dev {
+		return nil
+	}
+	return NewInjector()
+}
+
+// NewInjector returns an injector injecting no fault until configured
+func NewInjector() *Injector {
+	return &Injector{
+		// nolint:gosec
+		// We can ignore the gosec G404 warning since faults don't need a cryptographically secure random source
+		random: rand.Float64,
+	}
+}
+
+// Config returns the faults currently injected
+func (i *Injector) Config() Config {
+	if i == nil {
+		return Config{}
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.config
+}
+
+// SetConfig replaces the faults injected
+func (i *Injector) SetConfig(config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.config = config
+	return nil
+}
+
+// Reset stops injecting faults
+func (i *Injector) Reset() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.config = Config{}
+}
+
+// before is called before every operation. It waits for the configured latency and returns ErrInjectedFault when the
+// operation has to fail
+func (i *Injector) before(ctx context.Context, operation string) error {
+	config := i.Config()
+	if !config.appliesTo(operation) {
+		return nil
+	}
+
+	if config.LatencyMs > 0 {
+		timer := time.NewTimer(time.Duration(config.LatencyMs) * time.Millisecond)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if config.ErrorRate > 0 && i.random() < config.ErrorRate {
+		return fmt.Errorf("%s: %w", operation, ErrInjectedFault)
+	}
+	return nil
+}
+
+// partialFailure returns true when one of the results of a successful operation has to fail
+func (i *Injector) partialFailure(operation string) bool {
+	config := i.Config()
+	return config.appliesTo(operation) && config.PartialFailureRate > 0 && i.random() < config.PartialFailureRate
+}
@@ -0,0 +1,127 @@
+package faults
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// newTestInjector returns an injector whose random source always returns the given value
+func newTestInjector(t *testing.T, config Config, random float64) *Injector {
+	injector := NewInjector()
+	injector.random = func() float64 { return random }
+	require.NoError(t, injector.SetConfig(config))
+	return injector
+}
+
+func TestProvideInjector(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.Env = setting.Prod
+	assert.Nil(t, ProvideInjector(cfg))
+
+	cfg.Env = setting.Dev
+	assert.NotNil(t, ProvideInjector(cfg))
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{LatencyMs: 100, ErrorRate: 1, PartialFailureRate: 0.5}.Validate())
+	assert.Error(t, Config{LatencyMs: -1}.Validate())
+	assert.Error(t, Config{ErrorRate: 1.5}.Validate())
+	assert.Error(t, Config{PartialFailureRate: -0.1}.Validate())
+}
+
+func TestStore(t *testing.T) {
+	t.Run("fails the operations with ErrInjectedFault", func(t *testing.T) {
+		store := publicdashboards.NewFakePublicDashboardStore(t)
+		faultyStore := NewStore(store, newTestInjector(t, Config{ErrorRate: 0.5}, 0.2))
+
+		_, err := faultyStore.FindByAccessToken(context.Background(), "abc123")
+		require.ErrorIs(t, err, ErrInjectedFault)
+		store.AssertNotCalled(t, "FindByAccessToken")
+	})
+
+	t.Run("calls the store when the operation does not fail", func(t *testing.T) {
+		store := publicdashboards.NewFakePublicDashboardStore(t)
+		store.On("FindByAccessToken", mock.Anything, "abc123").Return(&PublicDashboard{Uid: "pubdash"}, nil)
+		faultyStore := NewStore(store, newTestInjector(t, Config{ErrorRate: 0.5}, 0.8))
+
+		pubdash, err := faultyStore.FindByAccessToken(context.Background(), "abc123")
+		require.NoError(t, err)
+		assert.Equal(t, "pubdash", pubdash.Uid)
+	})
+
+	t.Run("only injects faults in the configured operations", func(t *testing.T) {
+		store := publicdashboards.NewFakePublicDashboardStore(t)
+		store.On("Find", mock.Anything, "pubdash").Return(&PublicDashboard{Uid: "pubdash"}, nil)
+		faultyStore := NewStore(store, newTestInjector(t, Config{ErrorRate: 1, Operations: []string{"FindByAccessToken"}}, 0))
+
+		_, err := faultyStore.Find(context.Background(), "pubdash")
+		require.NoError(t, err)
+		_, err = faultyStore.FindByAccessToken(context.Background(), "abc123")
+		require.ErrorIs(t, err, ErrInjectedFault)
+	})
+
+	t.Run("adds latency until the context is done", func(t *testing.T) {
+		store := publicdashboards.NewFakePublicDashboardStore(t)
+		faultyStore := NewStore(store, newTestInjector(t, Config{LatencyMs: time.Hour.Milliseconds()}, 1))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := faultyStore.FindByAccessToken(ctx, "abc123")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("reset stops injecting faults", func(t *testing.T) {
+		store := publicdashboards.NewFakePublicDashboardStore(t)
+		store.On("ExistsEnabledByAccessToken", mock.Anything, "abc123").Return(true, nil)
+		injector := newTestInjector(t, Config{ErrorRate: 1}, 0)
+		injector.Reset()
+
+		exists, err := NewStore(store, injector).ExistsEnabledByAccessToken(context.Background(), "abc123")
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+}
+
+func TestQueryDataExecutor(t *testing.T) {
+	newExecutor := func(t *testing.T) *publicdashboards.FakeQueryDataExecutor {
+		executor := publicdashboards.NewFakeQueryDataExecutor(t)
+		executor.On("QueryData", mock.Anything, mock.Anything, false, mock.Anything).Return(&backend.QueryDataResponse{
+			Responses: backend.Responses{"A": {}, "B": {}},
+		}, nil).Maybe()
+		return executor
+	}
+
+	t.Run("fails the request with ErrInjectedFault", func(t *testing.T) {
+		executor := newExecutor(t)
+
+		_, err := NewQueryDataExecutor(executor, newTestInjector(t, Config{ErrorRate: 1}, 0)).QueryData(context.Background(), nil, false, dtos.MetricRequest{})
+		require.ErrorIs(t, err, ErrInjectedFault)
+		executor.AssertNotCalled(t, "QueryData")
+	})
+
+	t.Run("fails the queries of successful requests", func(t *testing.T) {
+		res, err := NewQueryDataExecutor(newExecutor(t), newTestInjector(t, Config{PartialFailureRate: 0.5}, 0.2)).QueryData(context.Background(), nil, false, dtos.MetricRequest{})
+		require.NoError(t, err)
+		assert.ErrorIs(t, res.Responses["A"].Error, ErrInjectedFault)
+		assert.ErrorIs(t, res.Responses["B"].Error, ErrInjectedFault)
+	})
+
+	t.Run("leaves the responses untouched without faults", func(t *testing.T) {
+		res, err := NewQueryDataExecutor(newExecutor(t), NewInjector()).QueryData(context.Background(), nil, false, dtos.MetricRequest{})
+		require.NoError(t, err)
+		assert.NoError(t, res.Responses["A"].Error)
+		assert.NoError(t, res.Responses["B"].Error)
+	})
+}
@@ -0,0 +1,53 @@
+package faults
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// QueryDataExecutor wraps a query executor, injecting faults before every request and failing some of the queries of
+// successful requests
+type QueryDataExecutor struct {
+	executor publicdashboards.QueryDataExecutor
+	injector *Injector
+}
+
+var _ publicdashboards.QueryDataExecutor = (*QueryDataExecutor)(nil)
+
+// ProvideQueryDataExecutor Factory for method used by wire to inject dependencies. The executor is only wrapped when
+// faults can be injected
+func ProvideQueryDataExecutor(executor *query.Service, injector *Injector) publicdashboards.QueryDataExecutor {
+	if injector == nil {
+		return executor
+	}
+	return NewQueryDataExecutor(executor, injector)
+}
+
+// NewQueryDataExecutor wraps the executor with the faults of the injector
+func NewQueryDataExecutor(executor publicdashboards.QueryDataExecutor, injector *Injector) *QueryDataExecutor {
+	return &QueryDataExecutor{executor: executor, injector: injector}
+}
+
+func (e *QueryDataExecutor) QueryData(ctx context.Context, user *user.SignedInUser, skipCache bool, reqDTO dtos.MetricRequest) (*backend.QueryDataResponse, error) {
+	if err := e.injector.before(ctx, "QueryData"); err != nil {
+		return nil, err
+	}
+
+	res, err := e.executor.QueryData(ctx, user, skipCache, reqDTO)
+	if err != nil || res == nil {
+		return res, err
+	}
+
+	for refId := range res.Responses {
+		if e.injector.partialFailure("QueryData") {
+			res.Responses[refId] = backend.DataResponse{Error: ErrInjectedFault}
+		}
+	}
+	return res, nil
+}
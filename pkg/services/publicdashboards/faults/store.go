@@ -0,0 +1,166 @@
+package faults
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/database"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+// Store wraps a public dashboard store, injecting faults before every call
+type Store struct {
+	store    publicdashboards.Store
+	injector *Injector
+}
+
+var _ publicdashboards.Store = (*Store)(nil)
+
+// ProvideStore Factory for method used by wire to inject dependencies. The store is only wrapped when faults can be
+// injected
+func ProvideStore(store *database.PublicDashboardStoreImpl, injector *Injector) publicdashboards.Store {
+	if injector == nil {
+		return store
+	}
+	return NewStore(store, injector)
+}
+
+// NewStore wraps the store with the faults of the injector
+func NewStore(store publicdashboards.Store, injector *Injector) *Store {
+	return &Store{store: store, injector: injector}
+}
+
+func (s *Store) Find(ctx context.Context, uid string) (*PublicDashboard, error) {
+	if err := s.injector.before(ctx, "Find"); err != nil {
+		return nil, err
+	}
+	return s.store.Find(ctx, uid)
+}
+
+func (s *Store) FindByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, error) {
+	if err := s.injector.before(ctx, "FindByAccessToken"); err != nil {
+		return nil, err
+	}
+	return s.store.FindByAccessToken(ctx, accessToken)
+}
+
+func (s *Store) FindByAccessTokens(ctx context.Context, accessTokens []string) (map[string]*PublicDashboard, error) {
+	if err := s.injector.before(ctx, "FindByAccessTokens"); err != nil {
+		return nil, err
+	}
+	return s.store.FindByAccessTokens(ctx, accessTokens)
+}
+
+func (s *Store) FindByDashboardUid(ctx context.Context, orgId int64, dashboardUid string) (*PublicDashboard, error) {
+	if err := s.injector.before(ctx, "FindByDashboardUid"); err != nil {
+		return nil, err
+	}
+	return s.store.FindByDashboardUid(ctx, orgId, dashboardUid)
+}
+
+func (s *Store) FindByDashboardUids(ctx context.Context, orgId int64, dashboardUids []string) (map[string]*PublicDashboard, error) {
+	if err := s.injector.before(ctx, "FindByDashboardUids"); err != nil {
+		return nil, err
+	}
+	return s.store.FindByDashboardUids(ctx, orgId, dashboardUids)
+}
+
+func (s *Store) FindDashboard(ctx context.Context, dashboardUid string, orgId int64) (*models.Dashboard, error) {
+	if err := s.injector.before(ctx, "FindDashboard"); err != nil {
+		return nil, err
+	}
+	return s.store.FindDashboard(ctx, dashboardUid, orgId)
+}
+
+func (s *Store) FindAll(ctx context.Context, orgId int64) ([]PublicDashboardListResponse, error) {
+	if err := s.injector.before(ctx, "FindAll"); err != nil {
+		return nil, err
+	}
+	return s.store.FindAll(ctx, orgId)
+}
+
+func (s *Store) FindAllGlobal(ctx context.Context) ([]PublicDashboardGlobalListResponse, error) {
+	if err := s.injector.before(ctx, "FindAllGlobal"); err != nil {
+		return nil, err
+	}
+	return s.store.FindAllGlobal(ctx)
+}
+
+func (s *Store) Save(ctx context.Context, cmd SavePublicDashboardConfigCommand) error {
+	if err := s.injector.before(ctx, "Save"); err != nil {
+		return err
+	}
+	return s.store.Save(ctx, cmd)
+}
+
+func (s *Store) Update(ctx context.Context, cmd SavePublicDashboardConfigCommand) error {
+	if err := s.injector.before(ctx, "Update"); err != nil {
+		return err
+	}
+	return s.store.Update(ctx, cmd)
+}
+
+func (s *Store) UpdateLastUsedAt(ctx context.Context, lastUsed map[string]time.Time) error {
+	if err := s.injector.before(ctx, "UpdateLastUsedAt"); err != nil {
+		return err
+	}
+	return s.store.UpdateLastUsedAt(ctx, lastUsed)
+}
+
+func (s *Store) UpdateOwner(ctx context.Context, cmd TransferPublicDashboardOwnershipCommand) error {
+	if err := s.injector.before(ctx, "UpdateOwner"); err != nil {
+		return err
+	}
+	return s.store.UpdateOwner(ctx, cmd)
+}
+
+func (s *Store) FindByCreatedBy(ctx context.Context, userId int64) ([]*PublicDashboard, error) {
+	if err := s.injector.before(ctx, "FindByCreatedBy"); err != nil {
+		return nil, err
+	}
+	return s.store.FindByCreatedBy(ctx, userId)
+}
+
+func (s *Store) FindSharedByFolders(ctx context.Context, orgId int64) ([]*PublicDashboard, error) {
+	if err := s.injector.before(ctx, "FindSharedByFolders"); err != nil {
+		return nil, err
+	}
+	return s.store.FindSharedByFolders(ctx, orgId)
+}
+
+func (s *Store) FlagCreatorDeleted(ctx context.Context, uid string) error {
+	if err := s.injector.before(ctx, "FlagCreatorDeleted"); err != nil {
+		return err
+	}
+	return s.store.FlagCreatorDeleted(ctx, uid)
+}
+
+func (s *Store) Delete(ctx context.Context, uid string) error {
+	if err := s.injector.before(ctx, "Delete"); err != nil {
+		return err
+	}
+	return s.store.Delete(ctx, uid)
+}
+
+func (s *Store) GetOrgIdByAccessToken(ctx context.Context, accessToken string) (int64, error) {
+	if err := s.injector.before(ctx, "GetOrgIdByAccessToken"); err != nil {
+		return 0, err
+	}
+	return s.store.GetOrgIdByAccessToken(ctx, accessToken)
+}
+
+func (s *Store) ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error) {
+	if err := s.injector.before(ctx, "ExistsEnabledByAccessToken"); err != nil {
+		return false, err
+	}
+	return s.store.ExistsEnabledByAccessToken(ctx, accessToken)
+}
+
+func (s *Store) ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error) {
+	if err := s.injector.before(ctx, "ExistsEnabledByDashboardUid"); err != nil {
+		return false, err
+	}
+	return s.store.ExistsEnabledByDashboardUid(ctx, dashboardUid)
+}
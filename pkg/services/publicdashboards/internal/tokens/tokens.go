@@ -1,22 +1,98 @@
 package tokens
 
 import (
-	"fmt"
+	"crypto/rand"
+	"hash/crc32"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// Access token formats
+//
+// Legacy access tokens are uuids formatted without dashes. Access tokens v2 are made of a version prefix, random
+// base62 characters and the base62 encoded CRC32 checksum of the prefix and random characters, so malformed tokens
+// can be rejected without a database lookup. Both formats are 32 characters long.
+const (
+	AccessTokenVersionUnknown = 0
+	AccessTokenVersionLegacy  = 1
+	AccessTokenVersionV2      = 2
 
-	"github.com/google/uuid"
+	accessTokenLength         = 32
+	accessTokenV2Prefix       = "pd2_"
+	accessTokenChecksumLength = 6
+	accessTokenRandomLength   = accessTokenLength - len(accessTokenV2Prefix) - accessTokenChecksumLength
 )
 
-// GenerateAccessToken generates an uuid formatted without dashes to use as access token
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+var legacyAccessTokenPattern = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+
+// GenerateAccessToken generates an access token in the latest format
 func GenerateAccessToken() (string, error) {
-	token, err := uuid.NewRandom()
+	random, err := randomBase62(accessTokenRandomLength)
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("%x", token[:]), nil
+	return accessTokenV2Prefix + random + accessTokenChecksum(accessTokenV2Prefix+random), nil
 }
 
-// IsValidAccessToken asserts that an accessToken is a valid uuid
+// IsValidAccessToken asserts that an accessToken is either a legacy access token or a well-formed access token v2
+// with a matching checksum
 func IsValidAccessToken(token string) bool {
-	_, err := uuid.Parse(token)
-	return err == nil
+	return AccessTokenVersion(token) != AccessTokenVersionUnknown
+}
+
+// AccessTokenVersion returns the format version of a valid access token, or AccessTokenVersionUnknown if the
+// token is malformed
+func AccessTokenVersion(token string) int {
+	if len(token) != accessTokenLength {
+		return AccessTokenVersionUnknown
+	}
+
+	if legacyAccessTokenPattern.MatchString(token) {
+		return AccessTokenVersionLegacy
+	}
+
+	if !strings.HasPrefix(token, accessTokenV2Prefix) {
+		return AccessTokenVersionUnknown
+	}
+
+	payload, checksum := token[:accessTokenLength-accessTokenChecksumLength], token[accessTokenLength-accessTokenChecksumLength:]
+	for _, c := range token[len(accessTokenV2Prefix):] {
+		if !strings.ContainsRune(base62Alphabet, c) {
+			return AccessTokenVersionUnknown
+		}
+	}
+	if accessTokenChecksum(payload) != checksum {
+		return AccessTokenVersionUnknown
+	}
+
+	return AccessTokenVersionV2
+}
+
+// accessTokenChecksum returns the CRC32 checksum of the payload encoded in base62 and padded to a fixed length
+func accessTokenChecksum(payload string) string {
+	checksum := crc32.ChecksumIEEE([]byte(payload))
+
+	encoded := make([]byte, accessTokenChecksumLength)
+	for i := accessTokenChecksumLength - 1; i >= 0; i-- {
+		encoded[i] = base62Alphabet[checksum%62]
+		checksum /= 62
+	}
+	return string(encoded)
+}
+
+func randomBase62(length int) (string, error) {
+	max := big.NewInt(int64(len(base62Alphabet)))
+
+	random := make([]byte, length)
+	for i := range random {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		random[i] = base62Alphabet[n.Int64()]
+	}
+	return string(random), nil
 }
@@ -19,6 +19,17 @@ func TestGenerateAccessToken(t *testing.T) {
 	t.Run("no - ", func(t *testing.T) {
 		assert.False(t, strings.Contains("-", accessToken))
 	})
+
+	t.Run("version prefix", func(t *testing.T) {
+		assert.True(t, strings.HasPrefix(accessToken, "pd2_"))
+		assert.Equal(t, AccessTokenVersionV2, AccessTokenVersion(accessToken))
+	})
+
+	t.Run("unique", func(t *testing.T) {
+		other, err := GenerateAccessToken()
+		require.NoError(t, err)
+		assert.NotEqual(t, accessToken, other)
+	})
 }
 
 func TestValidAccessToken(t *testing.T) {
@@ -27,6 +38,11 @@ func TestValidAccessToken(t *testing.T) {
 		assert.True(t, IsValidAccessToken(uuid))
 	})
 
+	t.Run("true for legacy access tokens", func(t *testing.T) {
+		assert.True(t, IsValidAccessToken("e71950f3e7c44f0a9de1c6b5bc4a9f80"))
+		assert.Equal(t, AccessTokenVersionLegacy, AccessTokenVersion("e71950f3e7c44f0a9de1c6b5bc4a9f80"))
+	})
+
 	t.Run("false when blank", func(t *testing.T) {
 		assert.False(t, IsValidAccessToken(""))
 	})
@@ -35,4 +51,33 @@ func TestValidAccessToken(t *testing.T) {
 		// too long
 		assert.False(t, IsValidAccessToken("0123456789012345678901234567890123456789"))
 	})
+
+	t.Run("false when the checksum does not match", func(t *testing.T) {
+		accessToken, err := GenerateAccessToken()
+		require.NoError(t, err)
+
+		// change a random character
+		tampered := []byte(accessToken)
+		if tampered[10] == 'a' {
+			tampered[10] = 'b'
+		} else {
+			tampered[10] = 'a'
+		}
+		assert.False(t, IsValidAccessToken(string(tampered)))
+	})
+
+	t.Run("false for malformed access tokens", func(t *testing.T) {
+		for _, token := range []string{
+			// dashed uuid
+			"e71950f3-e7c4-4f0a-9de1-c6b5bc4a9f80",
+			// unknown version
+			"pd3_HKOY3jNwkq5tS0tuRDBOjs3HHmEL",
+			// not base62
+			"pd2_HKOY3jNwkq5tS0t-RDBOjs3HHmEL",
+			// not hex
+			"g71950f3e7c44f0a9de1c6b5bc4a9f80",
+		} {
+			assert.False(t, IsValidAccessToken(token), token)
+		}
+	})
 }
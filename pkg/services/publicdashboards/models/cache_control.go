@@ -0,0 +1,37 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxCacheControlSeconds bounds the max age and stale while revalidate durations of public dashboards to a year
+const MaxCacheControlSeconds = 365 * 24 * 60 * 60
+
+var ErrPublicDashboardInvalidCacheControl = PublicDashboardErr{
+	Reason:        "cache control durations must be between 0 and a year",
+	StatusCode:    400,
+	Status:        ErrStatusBadRequest,
+	PublicMessage: "Cache control durations must be between 0 and a year, in seconds",
+}
+
+// CacheControl returns the Cache-Control header of the public responses of the public dashboard, or an empty string
+// when they should not be cached. Responses of public dashboards restricted to some viewers are only cached by their
+// browsers, shared caches could serve them to other viewers
+func (pd PublicDashboard) CacheControl() string {
+	if pd.MaxAge == 0 && pd.StaleWhileRevalidate == 0 {
+		return ""
+	}
+
+	directives := []string{"public"}
+	if pd.EmailGated || pd.HasCountryRestrictions() {
+		directives[0] = "private"
+	}
+
+	directives = append(directives, fmt.Sprintf("max-age=%d", pd.MaxAge))
+	if pd.StaleWhileRevalidate > 0 {
+		directives = append(directives, fmt.Sprintf("stale-while-revalidate=%d", pd.StaleWhileRevalidate))
+	}
+
+	return strings.Join(directives, ", ")
+}
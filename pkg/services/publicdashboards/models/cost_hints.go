@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+// Cost classes of the panels of a public dashboard
+const (
+	PanelCostLow    = "low"
+	PanelCostMedium = "medium"
+	PanelCostHigh   = "high"
+)
+
+// PanelCostHint estimates how expensive it is to serve a panel of a public dashboard, so owners can trim expensive
+// panels before publishing. TypicalLatencyMs is the average duration of the panel queries over the last days, or 0
+// if the panel was not queried yet
+type PanelCostHint struct {
+	PanelId          int64    `json:"panelId"`
+	QueryCount       int      `json:"queryCount"`
+	DatasourceTypes  []string `json:"datasourceTypes"`
+	TypicalLatencyMs int64    `json:"typicalLatencyMs"`
+	Cost             string   `json:"cost"`
+}
+
+// PanelCostHints lists the cost hints of the panels of a public dashboard, computed when it is saved
+type PanelCostHints []PanelCostHint
+
+func (h *PanelCostHints) FromDB(data []byte) error {
+	return json.Unmarshal(data, h)
+}
+
+func (h PanelCostHints) ToDB() ([]byte, error) {
+	return json.Marshal(h)
+}
+
+// PublicDashboardPanelUsage counts the queries of a panel of a public dashboard and their total duration for a day
+type PublicDashboardPanelUsage struct {
+	Id                 int64  `xorm:"pk autoincr 'id'"`
+	PublicDashboardUid string `xorm:"public_dashboard_uid"`
+	OrgId              int64  `xorm:"org_id"`
+	PanelId            int64  `xorm:"panel_id"`
+	Day                string `xorm:"day"`
+	QueryCount         int64  `xorm:"query_count"`
+	DurationMs         int64  `xorm:"duration_ms"`
+}
+
+func (u PublicDashboardPanelUsage) TableName() string {
+	return "dashboard_public_panel_usage"
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+)
+
+var (
+	ErrPublicDashboardInvalidCountryList = PublicDashboardErr{
+		Reason:        "country lists must contain ISO 3166-1 alpha-2 country codes",
+		StatusCode:    400,
+		Status:        ErrStatusBadRequest,
+		PublicMessage: "Country lists must contain two letter country codes",
+	}
+	ErrPublicDashboardCountryRestricted = PublicDashboardErr{
+		Reason:        "public dashboard is not available in the country of the viewer",
+		StatusCode:    403,
+		Status:        ErrStatusForbidden,
+		PublicMessage: "This dashboard is not available in your country",
+	}
+)
+
+// CountryList lists ISO 3166-1 alpha-2 country codes, such as "FR" or "US"
+type CountryList []string
+
+func (l *CountryList) FromDB(data []byte) error {
+	return json.Unmarshal(data, l)
+}
+
+func (l CountryList) ToDB() ([]byte, error) {
+	return json.Marshal(l)
+}
+
+// Contains reports whether the list contains the country. Comparison is case insensitive
+func (l CountryList) Contains(country string) bool {
+	for _, entry := range l {
+		if strings.EqualFold(entry, country) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasCountryRestrictions reports whether viewers of the public dashboard are restricted by country
+func (pd PublicDashboard) HasCountryRestrictions() bool {
+	return len(pd.AllowedCountries) > 0 || len(pd.BlockedCountries) > 0
+}
+
+// AllowsCountry reports whether viewers from the country can view the public dashboard. Blocked countries take
+// precedence over allowed ones, and an unknown country is only allowed when there are no restrictions
+func (pd PublicDashboard) AllowsCountry(country string) bool {
+	if !pd.HasCountryRestrictions() {
+		return true
+	}
+	if country == "" || pd.BlockedCountries.Contains(country) {
+		return false
+	}
+	return len(pd.AllowedCountries) == 0 || pd.AllowedCountries.Contains(country)
+}
+
+type viewerIPKey struct{}
+
+// WithViewerIP returns a copy of ctx carrying the IP address of the viewer of a public dashboard
+func WithViewerIP(ctx context.Context, ip net.IP) context.Context {
+	return context.WithValue(ctx, viewerIPKey{}, ip)
+}
+
+// ViewerIPFromContext returns the IP address of the viewer of a public dashboard, if any
+func ViewerIPFromContext(ctx context.Context) net.IP {
+	ip, _ := ctx.Value(viewerIPKey{}).(net.IP)
+	return ip
+}
@@ -0,0 +1,31 @@
+package models
+
+var (
+	ErrPublicDashboardDatasourceNotFound = PublicDashboardErr{
+		Reason:        "data source of the query not found",
+		StatusCode:    422,
+		Status:        ErrStatusUnsupportedFeature,
+		PublicMessage: "The data source of this query no longer exists",
+	}
+	ErrPublicDashboardDatasourcePluginNotInstalled = PublicDashboardErr{
+		Reason:        "data source plugin of the query is not installed",
+		StatusCode:    422,
+		Status:        ErrStatusUnsupportedFeature,
+		PublicMessage: "The plugin of the data source of this query is not installed",
+	}
+	ErrPublicDashboardDatasourceNotSupported = PublicDashboardErr{
+		Reason:        "data source type of the query is not supported by public dashboards",
+		StatusCode:    422,
+		Status:        ErrStatusUnsupportedFeature,
+		PublicMessage: "This data source type is not supported by public dashboards",
+	}
+)
+
+// UnsupportedDatasourceNotice explains why the query of a refId was not run. It is returned in the custom metadata
+// of the frame of the query, so public viewers and dashboard owners can tell why a panel is empty
+type UnsupportedDatasourceNotice struct {
+	Status         string `json:"status"`
+	Reason         string `json:"reason"`
+	DatasourceUid  string `json:"datasourceUid"`
+	DatasourceType string `json:"datasourceType,omitempty"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// Policies applied to the public dashboards of deleted users
+const (
+	// DeletedCreatorPolicyFlag keeps the public dashboards and flags their creator as deleted
+	DeletedCreatorPolicyFlag = "flag"
+	// DeletedCreatorPolicyReassign makes the first admin of the organization the owner of the public dashboards
+	DeletedCreatorPolicyReassign = "reassign"
+	// DeletedCreatorPolicyDisable disables the public dashboards and flags their creator as deleted
+	DeletedCreatorPolicyDisable = "disable"
+	// DeletedCreatorPolicyDelete deletes the public dashboards
+	DeletedCreatorPolicyDelete = "delete"
+)
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+var (
+	ErrPublicDashboardInvalidEmail = PublicDashboardErr{
+		Reason:        "invalid email address",
+		StatusCode:    400,
+		Status:        ErrStatusBadRequest,
+		PublicMessage: "Invalid email address",
+	}
+	ErrPublicDashboardInvalidEmailAllowlist = PublicDashboardErr{
+		Reason:        "email allowlist entries must be email addresses or domains",
+		StatusCode:    400,
+		Status:        ErrStatusBadRequest,
+		PublicMessage: "Email allowlist entries must be email addresses or domains",
+	}
+	ErrPublicDashboardEmailNotConfigured = PublicDashboardErr{
+		Reason:        "email-gated public dashboards require SMTP to be enabled",
+		StatusCode:    422,
+		Status:        ErrStatusUnsupportedFeature,
+		PublicMessage: "Email sharing requires SMTP to be configured",
+	}
+	ErrPublicDashboardMagicLinkInvalid = PublicDashboardErr{
+		Reason:        "magic link is invalid or has expired",
+		StatusCode:    401,
+		Status:        ErrStatusUnauthorized,
+		PublicMessage: "This sign in link is invalid or has expired",
+	}
+	ErrPublicDashboardSessionRequired = PublicDashboardErr{
+		Reason:        "public dashboard requires an email session",
+		StatusCode:    401,
+		Status:        ErrStatusUnauthorized,
+		PublicMessage: "Sign in with your email address to view this dashboard",
+	}
+)
+
+// PublicDashboardSessionCookiePrefix is the prefix of the cookie holding the session of an email-gated public
+// dashboard. The access token of the public dashboard is appended so a viewer can hold sessions for several of them
+const PublicDashboardSessionCookiePrefix = "grafana_pubdash_session_"
+
+// EmailAllowlist lists who can view an email-gated public dashboard. Entries are either email addresses or
+// domains, written as "example.com" or "@example.com"
+type EmailAllowlist []string
+
+func (a *EmailAllowlist) FromDB(data []byte) error {
+	return json.Unmarshal(data, a)
+}
+
+func (a EmailAllowlist) ToDB() ([]byte, error) {
+	return json.Marshal(a)
+}
+
+// Allows reports whether the allowlist contains the email address or its domain. Comparison is case insensitive
+func (a EmailAllowlist) Allows(email string) bool {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return false
+	}
+	domain := email[at+1:]
+
+	for _, entry := range a {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == email || strings.TrimPrefix(entry, "@") == domain {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PublicDashboardMagicLink is a single use link emailed to a viewer of an email-gated public dashboard. Only the
+// hash of the token is stored
+type PublicDashboardMagicLink struct {
+	TokenHash          string    `xorm:"pk token_hash"`
+	PublicDashboardUid string    `xorm:"public_dashboard_uid"`
+	Email              string    `xorm:"email"`
+	ExpiresAt          time.Time `xorm:"expires_at"`
+	CreatedAt          time.Time `xorm:"created_at"`
+}
+
+func (ml PublicDashboardMagicLink) TableName() string {
+	return "dashboard_public_magic_link"
+}
+
+// PublicDashboardSession is created when a viewer opens a magic link and gives access to a single email-gated
+// public dashboard until it expires. Only the hash of the token is stored
+type PublicDashboardSession struct {
+	TokenHash          string    `xorm:"pk token_hash"`
+	PublicDashboardUid string    `xorm:"public_dashboard_uid"`
+	Email              string    `xorm:"email"`
+	ExpiresAt          time.Time `xorm:"expires_at"`
+	CreatedAt          time.Time `xorm:"created_at"`
+}
+
+func (s PublicDashboardSession) TableName() string {
+	return "dashboard_public_session"
+}
+
+// PublicDashboardSessionToken is handed to the viewer once a magic link has been verified
+type PublicDashboardSessionToken struct {
+	Token     string
+	Email     string
+	ExpiresAt time.Time
+}
+
+type RequestMagicLinkDTO struct {
+	Email string `json:"email"`
+}
+
+type VerifyMagicLinkDTO struct {
+	Code string `json:"code"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+)
+
+var (
+	ErrPublicFolderNotFound = PublicDashboardErr{
+		Reason:        "public folder not found",
+		StatusCode:    404,
+		Status:        ErrStatusNotFound,
+		PublicMessage: "Public folder not found",
+	}
+	// disabled public folders are answered like unknown ones, as disabled public dashboards
+	ErrPublicFolderDisabled = PublicDashboardErr{
+		Reason:        "public folder is disabled",
+		StatusCode:    404,
+		Status:        ErrStatusNotFound,
+		PublicMessage: "Public folder not found",
+	}
+	ErrPublicFolderFolderNotFound = PublicDashboardErr{
+		Reason:        "folder not found",
+		StatusCode:    404,
+		Status:        ErrStatusNotFound,
+		PublicMessage: "Folder not found",
+	}
+)
+
+// PublicFolder shares every dashboard of a folder publicly. Public dashboards are created for the dashboards of the
+// folder when the public folder is saved
+type PublicFolder struct {
+	Uid         string `json:"uid" xorm:"pk uid"`
+	FolderUid   string `json:"folderUid" xorm:"folder_uid"`
+	OrgId       int64  `json:"-" xorm:"org_id"` // Don't ever marshal orgId to Json
+	IsEnabled   bool   `json:"isEnabled" xorm:"is_enabled"`
+	AccessToken string `json:"accessToken" xorm:"access_token"`
+
+	CreatedBy int64 `json:"createdBy" xorm:"created_by"`
+	UpdatedBy int64 `json:"updatedBy" xorm:"updated_by"`
+
+	CreatedAt time.Time `json:"createdAt" xorm:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" xorm:"updated_at"`
+}
+
+func (pf PublicFolder) TableName() string {
+	return "dashboard_public_folder"
+}
+
+// PublicFolderViewerPayload is what an unauthenticated viewer gets when opening a public folder. Dashboards of the
+// folder whose public dashboard has been disabled, or that cannot be shared publicly, are left out
+type PublicFolderViewerPayload struct {
+	Title      string                               `json:"title"`
+	Dashboards []PublicFolderViewerPayloadDashboard `json:"dashboards"`
+}
+
+type PublicFolderViewerPayloadDashboard struct {
+	AccessToken string `json:"accessToken"`
+	Title       string `json:"title"`
+}
+
+// DTO for transforming user input in the api
+type SavePublicFolderDTO struct {
+	FolderUid    string
+	OrgId        int64
+	UserId       int64
+	PublicFolder *PublicFolder
+}
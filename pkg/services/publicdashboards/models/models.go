@@ -5,16 +5,24 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/coremodel/dashboard"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
-// PublicDashboardErr represents a dashboard error.
+// PublicDashboardErr represents a public dashboard error.
+//
+// StatusCode is the HTTP status code returned by the API, Status is a stable
+// machine readable identifier clients can react to, Reason is the internal
+// description that gets logged and PublicMessage is what is shown to the
+// end user.
 type PublicDashboardErr struct {
-	StatusCode int
-	Status     string
-	Reason     string
+	StatusCode    int
+	Status        string
+	Reason        string
+	PublicMessage string
 }
 
 // Error returns the error message.
@@ -25,45 +33,110 @@ func (e PublicDashboardErr) Error() string {
 	return "Dashboard Error"
 }
 
+// Public returns the message that is safe to show to the end user.
+func (e PublicDashboardErr) Public() string {
+	if e.PublicMessage != "" {
+		return e.PublicMessage
+	}
+	return e.Error()
+}
+
 const QuerySuccess = "success"
 const QueryFailure = "failure"
 
 var QueryResultStatuses = []string{QuerySuccess, QueryFailure}
 
+// Statuses of public dashboard errors
+const (
+	ErrStatusInternal           = "internal-error"
+	ErrStatusBadRequest         = "bad-request"
+	ErrStatusNotFound           = "not-found"
+	ErrStatusRateLimited        = "rate-limited"
+	ErrStatusUnsupportedFeature = "unsupported-feature"
+	ErrStatusUnauthorized       = "unauthorized"
+	ErrStatusForbidden          = "forbidden"
+)
+
 var (
 	ErrPublicDashboardFailedGenerateUniqueUid = PublicDashboardErr{
-		Reason:     "failed to generate unique public dashboard id",
-		StatusCode: 500,
+		Reason:        "failed to generate unique public dashboard id",
+		StatusCode:    500,
+		Status:        ErrStatusInternal,
+		PublicMessage: "Failed to create public dashboard",
 	}
 	ErrPublicDashboardFailedGenerateAccessToken = PublicDashboardErr{
-		Reason:     "failed to create public dashboard",
-		StatusCode: 500,
+		Reason:        "failed to create public dashboard",
+		StatusCode:    500,
+		Status:        ErrStatusInternal,
+		PublicMessage: "Failed to create public dashboard",
 	}
 	ErrPublicDashboardNotFound = PublicDashboardErr{
-		Reason:     "public dashboard not found",
-		StatusCode: 404,
-		Status:     "not-found",
+		Reason:        "public dashboard not found",
+		StatusCode:    404,
+		Status:        ErrStatusNotFound,
+		PublicMessage: "Public dashboard not found",
 	}
 	ErrPublicDashboardPanelNotFound = PublicDashboardErr{
-		Reason:     "panel not found in dashboard",
-		StatusCode: 404,
-		Status:     "not-found",
+		Reason:        "panel not found in dashboard",
+		StatusCode:    404,
+		Status:        ErrStatusNotFound,
+		PublicMessage: "Panel not found",
+	}
+	// disabled public dashboards are logged as such but answered like unknown ones, so a disabled link does not
+	// reveal that it once shared a dashboard
+	ErrPublicDashboardDisabled = PublicDashboardErr{
+		Reason:        "public dashboard is disabled",
+		StatusCode:    404,
+		Status:        ErrStatusNotFound,
+		PublicMessage: "Public dashboard not found",
+	}
+	ErrPublicDashboardRateLimited = PublicDashboardErr{
+		Reason:        "public dashboard rate limit exceeded",
+		StatusCode:    429,
+		Status:        ErrStatusRateLimited,
+		PublicMessage: "Too many requests, please try again later",
+	}
+	ErrPublicDashboardUnsupportedFeature = PublicDashboardErr{
+		Reason:        "feature not supported by public dashboards",
+		StatusCode:    422,
+		Status:        ErrStatusUnsupportedFeature,
+		PublicMessage: "This feature is not supported by public dashboards",
+	}
+	ErrPublicDashboardTransformationNotSupported = PublicDashboardErr{
+		Reason:        "panel transformation not supported server-side",
+		StatusCode:    422,
+		Status:        ErrStatusUnsupportedFeature,
+		PublicMessage: "The transformations of this panel can only be applied in the browser",
 	}
 	ErrPublicDashboardIdentifierNotSet = PublicDashboardErr{
-		Reason:     "no Uid for public dashboard specified",
-		StatusCode: 400,
+		Reason:        "no Uid for public dashboard specified",
+		StatusCode:    400,
+		Status:        ErrStatusBadRequest,
+		PublicMessage: "No public dashboard uid specified",
 	}
 	ErrPublicDashboardHasTemplateVariables = PublicDashboardErr{
-		Reason:     "public dashboard has template variables",
-		StatusCode: 422,
+		Reason:        "public dashboard has template variables",
+		StatusCode:    422,
+		Status:        ErrStatusUnsupportedFeature,
+		PublicMessage: "Public dashboards do not support template variables",
 	}
 	ErrPublicDashboardBadRequest = PublicDashboardErr{
-		Reason:     "bad Request",
-		StatusCode: 400,
+		Reason:        "bad Request",
+		StatusCode:    400,
+		Status:        ErrStatusBadRequest,
+		PublicMessage: "Bad request",
 	}
 	ErrNoPanelQueriesFound = PublicDashboardErr{
-		Reason:     "failed to extract queries from panel",
-		StatusCode: 400,
+		Reason:        "failed to extract queries from panel",
+		StatusCode:    400,
+		Status:        ErrStatusBadRequest,
+		PublicMessage: "Failed to extract queries from panel",
+	}
+	ErrPublicDashboardQueryFailed = PublicDashboardErr{
+		Reason:        "failed to query data source",
+		StatusCode:    400,
+		Status:        ErrStatusBadRequest,
+		PublicMessage: "Failed to query data source",
 	}
 )
 
@@ -76,11 +149,47 @@ type PublicDashboard struct {
 	AccessToken        string        `json:"accessToken" xorm:"access_token"`
 	AnnotationsEnabled bool          `json:"annotationsEnabled" xorm:"annotations_enabled"`
 
+	// panels annotations are not shown on, on top of the dashboard-wide toggle
+	AnnotationsDisabledPanels PanelIdList `json:"annotationsDisabledPanels" xorm:"annotations_disabled_panels"`
+
+	// panels viewers can compare to the previous period
+	PreviousPeriodPanels PanelIdList `json:"previousPeriodPanels" xorm:"previous_period_panels"`
+
+	// display preferences for the public dashboard chrome
+	ShowTimePicker        bool `json:"showTimePicker" xorm:"show_time_picker"`
+	ShowAnnotationsToggle bool `json:"showAnnotationsToggle" xorm:"show_annotations_toggle"`
+	// the footer is shown unless turned off, nil when a configuration doesn't set it
+	ShowFooter *bool `json:"showFooter" xorm:"show_footer"`
+
+	// email-gated access, viewers have to open a magic link sent to an allowed address
+	EmailGated     bool           `json:"emailGated" xorm:"email_gated"`
+	EmailAllowlist EmailAllowlist `json:"emailAllowlist" xorm:"email_allowlist"`
+
+	// country restrictions for organizations with data residency requirements, evaluated before queries run
+	AllowedCountries CountryList `json:"allowedCountries" xorm:"allowed_countries"`
+	BlockedCountries CountryList `json:"blockedCountries" xorm:"blocked_countries"`
+
+	// caching hints of the public responses, in seconds, for CDNs fronting public dashboards
+	MaxAge               int64 `json:"maxAge" xorm:"max_age"`
+	StaleWhileRevalidate int64 `json:"staleWhileRevalidate" xorm:"stale_while_revalidate"`
+
+	// cost hints of the panels, computed when the configuration is saved
+	PanelCostHints PanelCostHints `json:"panelCostHints" xorm:"panel_cost_hints"`
+
+	// uid of the folder whose public folder created the public dashboard, empty once its owner saved it
+	SharedByFolderUid string `json:"sharedByFolderUid" xorm:"shared_by_folder_uid"`
+
 	CreatedBy int64 `json:"createdBy" xorm:"created_by"`
 	UpdatedBy int64 `json:"updatedBy" xorm:"updated_by"`
 
+	// set when the user who created the public dashboard was deleted, until its ownership is transferred
+	CreatorDeleted bool `json:"creatorDeleted" xorm:"creator_deleted"`
+
 	CreatedAt time.Time `json:"createdAt" xorm:"created_at"`
 	UpdatedAt time.Time `json:"updatedAt" xorm:"updated_at"`
+
+	// last time the access token was resolved, recorded with a coarse granularity. Nil if it was never used
+	LastUsedAt *time.Time `json:"lastUsedAt" xorm:"last_used_at"`
 }
 
 // Alias the generated type
@@ -110,11 +219,55 @@ func (pd PublicDashboard) TableName() string {
 }
 
 type PublicDashboardListResponse struct {
-	Uid          string `json:"uid" xorm:"uid"`
-	AccessToken  string `json:"accessToken" xorm:"access_token"`
-	Title        string `json:"title" xorm:"title"`
-	DashboardUid string `json:"dashboardUid" xorm:"dashboard_uid"`
-	IsEnabled    bool   `json:"isEnabled" xorm:"is_enabled"`
+	Uid            string     `json:"uid" xorm:"uid"`
+	AccessToken    string     `json:"accessToken" xorm:"access_token"`
+	Title          string     `json:"title" xorm:"title"`
+	DashboardUid   string     `json:"dashboardUid" xorm:"dashboard_uid"`
+	IsEnabled      bool       `json:"isEnabled" xorm:"is_enabled"`
+	LastUsedAt     *time.Time `json:"lastUsedAt" xorm:"last_used_at"`
+	CreatorDeleted bool       `json:"creatorDeleted" xorm:"creator_deleted"`
+}
+
+// PublicDashboardListResponseWithPagination is a page of the public dashboards of an org
+type PublicDashboardListResponseWithPagination struct {
+	pagination.Result
+	PublicDashboards []PublicDashboardListResponse `json:"publicDashboards"`
+}
+
+// PublicDashboardGlobalListResponse is a PublicDashboardListResponse including
+// the org the public dashboard belongs to. Only used for instance-wide listings
+type PublicDashboardGlobalListResponse struct {
+	Uid            string     `json:"uid" xorm:"uid"`
+	AccessToken    string     `json:"accessToken" xorm:"access_token"`
+	Title          string     `json:"title" xorm:"title"`
+	DashboardUid   string     `json:"dashboardUid" xorm:"dashboard_uid"`
+	IsEnabled      bool       `json:"isEnabled" xorm:"is_enabled"`
+	LastUsedAt     *time.Time `json:"lastUsedAt" xorm:"last_used_at"`
+	CreatorDeleted bool       `json:"creatorDeleted" xorm:"creator_deleted"`
+	OrgId          int64      `json:"orgId" xorm:"org_id"`
+	OrgName        string     `json:"orgName" xorm:"org_name"`
+}
+
+// PublicDashboardQueryExecution is a record of the queries run for a public dashboard panel. It lets
+// support correlate the request ID returned to a viewer with the queries sent to the data sources
+type PublicDashboardQueryExecution struct {
+	RequestId          string                               `json:"requestId"`
+	PublicDashboardUid string                               `json:"publicDashboardUid"`
+	DashboardUid       string                               `json:"dashboardUid"`
+	OrgId              int64                                `json:"orgId"`
+	PanelId            int64                                `json:"panelId"`
+	Queries            []PublicDashboardQueryExecutionQuery `json:"queries"`
+	StartedAt          time.Time                            `json:"startedAt"`
+	DurationMs         int64                                `json:"durationMs"`
+	Status             string                               `json:"status"`
+	Error              string                               `json:"error,omitempty"`
+}
+
+type PublicDashboardQueryExecutionQuery struct {
+	RefId          string           `json:"refId"`
+	DatasourceUid  string           `json:"datasourceUid"`
+	DatasourceType string           `json:"datasourceType"`
+	Model          *simplejson.Json `json:"model"`
 }
 
 type TimeSettings struct {
@@ -161,6 +314,12 @@ type SavePublicDashboardConfigDTO struct {
 type PublicDashboardQueryDTO struct {
 	IntervalMs    int64
 	MaxDataPoints int64
+	// ApplyTransformations applies the transformations of the panel to the response, for clients not running
+	// them in the browser
+	ApplyTransformations bool
+	// ComparePreviousPeriod adds the frames of the period preceding the time range of the dashboard, aligned on it.
+	// Only available on the panels the owner enabled it on
+	ComparePreviousPeriod bool
 }
 
 type AnnotationsQueryDTO struct {
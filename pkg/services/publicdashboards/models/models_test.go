@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
@@ -48,3 +49,46 @@ func TestBuildTimeSettings(t *testing.T) {
 		})
 	}
 }
+
+func TestSanitizeQueryRequestId(t *testing.T) {
+	assert.Equal(t, "abc-123_XYZ", SanitizeQueryRequestId("abc-123_XYZ"))
+	assert.Equal(t, "", SanitizeQueryRequestId(""))
+	assert.Equal(t, "", SanitizeQueryRequestId("abc 123"))
+	assert.Equal(t, "", SanitizeQueryRequestId("<script>"))
+	assert.Equal(t, "", SanitizeQueryRequestId(strings.Repeat("a", 65)))
+}
+
+func TestEmailAllowlistAllows(t *testing.T) {
+	allowlist := EmailAllowlist{"viewer@example.com", "grafana.com", "@Example.org"}
+
+	assert.True(t, allowlist.Allows("viewer@example.com"))
+	assert.True(t, allowlist.Allows(" Viewer@Example.com "))
+	assert.True(t, allowlist.Allows("anyone@grafana.com"))
+	assert.True(t, allowlist.Allows("anyone@example.org"))
+	assert.False(t, allowlist.Allows("other@example.com"))
+	assert.False(t, allowlist.Allows("anyone@sub.grafana.com"))
+	assert.False(t, allowlist.Allows("grafana.com"))
+	assert.False(t, allowlist.Allows(""))
+	assert.False(t, EmailAllowlist{}.Allows("viewer@example.com"))
+}
+
+func TestAnnotationsEnabledForPanel(t *testing.T) {
+	pubdash := PublicDashboard{AnnotationsEnabled: true, AnnotationsDisabledPanels: PanelIdList{2}}
+
+	assert.True(t, pubdash.AnnotationsEnabledForPanel(1))
+	assert.False(t, pubdash.AnnotationsEnabledForPanel(2))
+	assert.True(t, pubdash.AnnotationsEnabledForPanel(0))
+
+	pubdash.AnnotationsEnabled = false
+	assert.False(t, pubdash.AnnotationsEnabledForPanel(1))
+	assert.False(t, pubdash.AnnotationsEnabledForPanel(0))
+}
+
+func TestCacheControl(t *testing.T) {
+	assert.Equal(t, "", PublicDashboard{}.CacheControl())
+	assert.Equal(t, "public, max-age=60", PublicDashboard{MaxAge: 60}.CacheControl())
+	assert.Equal(t, "public, max-age=60, stale-while-revalidate=30", PublicDashboard{MaxAge: 60, StaleWhileRevalidate: 30}.CacheControl())
+	assert.Equal(t, "public, max-age=0, stale-while-revalidate=30", PublicDashboard{StaleWhileRevalidate: 30}.CacheControl())
+	assert.Equal(t, "private, max-age=60", PublicDashboard{MaxAge: 60, EmailGated: true}.CacheControl())
+	assert.Equal(t, "private, max-age=60", PublicDashboard{MaxAge: 60, BlockedCountries: CountryList{"FR"}}.CacheControl())
+}
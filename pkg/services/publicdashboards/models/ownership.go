@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+var (
+	ErrPublicDashboardOwnerNotFound = PublicDashboardErr{
+		Reason:        "new owner of the public dashboard not found in the organization",
+		StatusCode:    400,
+		Status:        ErrStatusBadRequest,
+		PublicMessage: "New owner not found",
+	}
+	ErrPublicDashboardOwnerForbidden = PublicDashboardErr{
+		Reason:        "new owner of the public dashboard is not allowed to manage it",
+		StatusCode:    400,
+		Status:        ErrStatusBadRequest,
+		PublicMessage: "New owner is not allowed to manage the public dashboard",
+	}
+)
+
+// TransferPublicDashboardOwnershipDTO is the request transferring the ownership of the public dashboard of a dashboard
+type TransferPublicDashboardOwnershipDTO struct {
+	DashboardUid string
+	OrgId        int64
+	UserId       int64
+	NewOwnerId   int64 `json:"newOwnerId"`
+}
+
+// TransferPublicDashboardOwnershipCommand sets the creator of a public dashboard at the database layer
+type TransferPublicDashboardOwnershipCommand struct {
+	Uid       string
+	CreatedBy int64
+	UpdatedBy int64
+	UpdatedAt time.Time
+}
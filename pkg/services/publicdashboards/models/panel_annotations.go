@@ -0,0 +1,42 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+var ErrPublicDashboardInvalidPanelIdList = PublicDashboardErr{
+	Reason:        "panel id lists must contain positive panel ids",
+	StatusCode:    400,
+	Status:        ErrStatusBadRequest,
+	PublicMessage: "Panel id lists must contain positive panel ids",
+}
+
+// PanelIdList lists ids of panels of the dashboard
+type PanelIdList []int64
+
+func (l *PanelIdList) FromDB(data []byte) error {
+	return json.Unmarshal(data, l)
+}
+
+func (l PanelIdList) ToDB() ([]byte, error) {
+	return json.Marshal(l)
+}
+
+// Contains reports whether the list contains the panel id
+func (l PanelIdList) Contains(panelId int64) bool {
+	for _, entry := range l {
+		if entry == panelId {
+			return true
+		}
+	}
+	return false
+}
+
+// AnnotationsEnabledForPanel reports whether annotations are shown on the panel. Annotations without a panel are
+// shown on every panel and are only disabled by the dashboard-wide toggle
+func (pd PublicDashboard) AnnotationsEnabledForPanel(panelId int64) bool {
+	if !pd.AnnotationsEnabled {
+		return false
+	}
+	return panelId == 0 || !pd.AnnotationsDisabledPanels.Contains(panelId)
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+)
+
+var (
+	ErrPublicPlaylistNotFound = PublicDashboardErr{
+		Reason:        "public playlist not found",
+		StatusCode:    404,
+		Status:        ErrStatusNotFound,
+		PublicMessage: "Public playlist not found",
+	}
+	ErrPublicPlaylistNameNotSet = PublicDashboardErr{
+		Reason:        "public playlist name not set",
+		StatusCode:    400,
+		Status:        ErrStatusBadRequest,
+		PublicMessage: "Public playlist name is required",
+	}
+	ErrPublicPlaylistInvalidItem = PublicDashboardErr{
+		Reason:        "public playlist item does not reference a public dashboard of the org",
+		StatusCode:    400,
+		Status:        ErrStatusBadRequest,
+		PublicMessage: "Public playlist items must reference public dashboards of the organization",
+	}
+	ErrPublicPlaylistInvalidDwellTime = PublicDashboardErr{
+		Reason:        "public playlist item dwell time is too short",
+		StatusCode:    400,
+		Status:        ErrStatusBadRequest,
+		PublicMessage: "Public playlist items must be displayed at least 5 seconds",
+	}
+)
+
+// MinPublicPlaylistDwellSeconds is the shortest time a public dashboard can be displayed by a public playlist
+const MinPublicPlaylistDwellSeconds = 5
+
+// DefaultPublicPlaylistDwellSeconds is used for items without dwell time
+const DefaultPublicPlaylistDwellSeconds = 60
+
+// PublicPlaylist is an ordered list of public dashboards that can be viewed through a single public link
+type PublicPlaylist struct {
+	Uid         string `json:"uid" xorm:"pk uid"`
+	OrgId       int64  `json:"-" xorm:"org_id"` // Don't ever marshal orgId to Json
+	Name        string `json:"name" xorm:"name"`
+	AccessToken string `json:"accessToken" xorm:"access_token"`
+
+	Items []PublicPlaylistItem `json:"items" xorm:"-"`
+
+	CreatedBy int64 `json:"createdBy" xorm:"created_by"`
+	UpdatedBy int64 `json:"updatedBy" xorm:"updated_by"`
+
+	CreatedAt time.Time `json:"createdAt" xorm:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" xorm:"updated_at"`
+}
+
+func (pp PublicPlaylist) TableName() string {
+	return "dashboard_public_playlist"
+}
+
+// PublicPlaylistItem is a public dashboard of a public playlist, referenced by its access token
+type PublicPlaylistItem struct {
+	Id           int64  `json:"-" xorm:"pk autoincr 'id'"`
+	PlaylistUid  string `json:"-" xorm:"playlist_uid"`
+	AccessToken  string `json:"accessToken" xorm:"access_token"`
+	DwellSeconds int64  `json:"dwellSeconds" xorm:"dwell_seconds"`
+	Position     int    `json:"-" xorm:"position"`
+}
+
+func (ppi PublicPlaylistItem) TableName() string {
+	return "dashboard_public_playlist_item"
+}
+
+// PublicPlaylistViewerPayload is what an unauthenticated viewer gets to rotate through the public dashboards of a
+// public playlist. Items of public dashboards that are disabled or missing are left out
+type PublicPlaylistViewerPayload struct {
+	Name  string                            `json:"name"`
+	Items []PublicPlaylistViewerPayloadItem `json:"items"`
+}
+
+type PublicPlaylistViewerPayloadItem struct {
+	AccessToken  string `json:"accessToken"`
+	Title        string `json:"title"`
+	DwellSeconds int64  `json:"dwellSeconds"`
+}
+
+// DTO for transforming user input in the api
+type SavePublicPlaylistDTO struct {
+	Uid            string
+	OrgId          int64
+	UserId         int64
+	PublicPlaylist *PublicPlaylist
+}
@@ -0,0 +1,17 @@
+package models
+
+// PreviousPeriodLabel labels the fields of the frames of the previous period, returned with the frames of the
+// current period when comparing to the previous period
+const PreviousPeriodLabel = "previous period"
+
+var ErrPublicDashboardPreviousPeriodNotEnabled = PublicDashboardErr{
+	Reason:        "comparison to the previous period is not enabled on the panel",
+	StatusCode:    403,
+	Status:        ErrStatusForbidden,
+	PublicMessage: "Comparison to the previous period is not enabled on this panel",
+}
+
+// ComparesPreviousPeriodOnPanel reports whether viewers can compare the panel to the previous period
+func (pd PublicDashboard) ComparesPreviousPeriodOnPanel(panelId int64) bool {
+	return pd.PreviousPeriodPanels.Contains(panelId)
+}
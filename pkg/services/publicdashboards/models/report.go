@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+)
+
+var (
+	ErrPublicDashboardReportSubscriptionNotFound = PublicDashboardErr{
+		Reason:        "report subscription not found",
+		StatusCode:    404,
+		Status:        ErrStatusNotFound,
+		PublicMessage: "Report subscription not found",
+	}
+	ErrPublicDashboardReportNoEmail = PublicDashboardErr{
+		Reason:        "user has no email address to send reports to",
+		StatusCode:    400,
+		Status:        ErrStatusBadRequest,
+		PublicMessage: "Your user needs an email address to subscribe to reports",
+	}
+	ErrPublicDashboardReportEmailNotConfigured = PublicDashboardErr{
+		Reason:        "report subscriptions require SMTP to be enabled",
+		StatusCode:    422,
+		Status:        ErrStatusUnsupportedFeature,
+		PublicMessage: "Report subscriptions require SMTP to be configured",
+	}
+)
+
+// UsageDayFormat is the layout of the days public dashboard usage is counted by
+const UsageDayFormat = "2006-01-02"
+
+// PublicDashboardReportSubscription subscribes a user to the weekly usage and error digest of the public
+// dashboards they created. LastSentAt is the end of the period covered by the previous digest
+type PublicDashboardReportSubscription struct {
+	Id         int64     `json:"id" xorm:"pk autoincr 'id'"`
+	OrgId      int64     `json:"orgId" xorm:"org_id"`
+	UserId     int64     `json:"userId" xorm:"user_id"`
+	Email      string    `json:"email" xorm:"email"`
+	LastSentAt time.Time `json:"lastSentAt" xorm:"last_sent_at"`
+	CreatedAt  time.Time `json:"createdAt" xorm:"created_at"`
+}
+
+func (s PublicDashboardReportSubscription) TableName() string {
+	return "dashboard_public_report_subscription"
+}
+
+// PublicDashboardUsage counts the views and queries of a public dashboard, and how many of them were made by
+// anonymous viewers or failed, for a day
+type PublicDashboardUsage struct {
+	Id                 int64  `xorm:"pk autoincr 'id'"`
+	PublicDashboardUid string `xorm:"public_dashboard_uid"`
+	OrgId              int64  `xorm:"org_id"`
+	Day                string `xorm:"day"`
+	QueryCount         int64  `xorm:"query_count"`
+	ErrorCount         int64  `xorm:"error_count"`
+	ViewCount          int64  `xorm:"view_count"`
+	AnonymousViewCount int64  `xorm:"anonymous_view_count"`
+}
+
+func (u PublicDashboardUsage) TableName() string {
+	return "dashboard_public_usage"
+}
+
+// PublicDashboardUsageSummary aggregates the usage of a public dashboard over the period of a digest
+type PublicDashboardUsageSummary struct {
+	PublicDashboardUid string `json:"publicDashboardUid" xorm:"public_dashboard_uid"`
+	DashboardUid       string `json:"dashboardUid" xorm:"dashboard_uid"`
+	Title              string `json:"title" xorm:"title"`
+	IsEnabled          bool   `json:"isEnabled" xorm:"is_enabled"`
+	QueryCount         int64  `json:"queryCount" xorm:"query_count"`
+	ErrorCount         int64  `json:"errorCount" xorm:"error_count"`
+}
+
+// PublicDashboardReportDigest is the usage and error summary emailed to a subscribed user
+type PublicDashboardReportDigest struct {
+	From       time.Time
+	To         time.Time
+	Dashboards []PublicDashboardUsageSummary
+	QueryCount int64
+	ErrorCount int64
+}
+
+// PublicDashboardUsageStats aggregates the usage of all public dashboards of the instance for the usage stats report
+type PublicDashboardUsageStats struct {
+	ViewCount          int64 `xorm:"view_count"`
+	AnonymousViewCount int64 `xorm:"anonymous_view_count"`
+	QueryCount         int64 `xorm:"query_count"`
+	ErrorCount         int64 `xorm:"error_count"`
+	// ViewedCount is the number of public dashboards viewed at least once
+	ViewedCount int64 `xorm:"viewed_count"`
+	// TopViewCount is the number of views of the most viewed public dashboard
+	TopViewCount int64 `xorm:"top_view_count"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// QueryRequestIdHeader is the header carrying the request ID of a public dashboard query
+const QueryRequestIdHeader = "X-Request-Id"
+
+var validQueryRequestId = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+type queryRequestIdKey struct{}
+
+func init() {
+	// every log line of a public dashboard query carries its request ID, so the ID reported by a viewer can be
+	// matched to the backend logs
+	log.RegisterContextualLogProvider(func(ctx context.Context) ([]interface{}, bool) {
+		if requestId := QueryRequestIdFromContext(ctx); requestId != "" {
+			return []interface{}{"requestId", requestId}, true
+		}
+		return nil, false
+	})
+}
+
+// SanitizeQueryRequestId returns the request ID if it is safe to be logged and returned to
+// viewers, or an empty string otherwise
+func SanitizeQueryRequestId(requestId string) string {
+	if !validQueryRequestId.MatchString(requestId) {
+		return ""
+	}
+	return requestId
+}
+
+// WithQueryRequestId returns a copy of ctx carrying the request ID of a public dashboard query
+func WithQueryRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, queryRequestIdKey{}, requestId)
+}
+
+// QueryRequestIdFromContext returns the request ID of a public dashboard query, if any
+func QueryRequestIdFromContext(ctx context.Context) string {
+	requestId, _ := ctx.Value(queryRequestIdKey{}).(string)
+	return requestId
+}
@@ -9,6 +9,8 @@ import (
 
 	dtos "github.com/grafana/grafana/pkg/api/dtos"
 
+	pagination "github.com/grafana/grafana/pkg/util/pagination"
+
 	mock "github.com/stretchr/testify/mock"
 
 	models "github.com/grafana/grafana/pkg/services/publicdashboards/models"
@@ -23,6 +25,48 @@ type FakePublicDashboardService struct {
 	mock.Mock
 }
 
+// CheckEmailSession provides a mock function with given fields: ctx, accessToken, sessionToken
+func (_m *FakePublicDashboardService) CheckEmailSession(ctx context.Context, accessToken string, sessionToken string) error {
+	ret := _m.Called(ctx, accessToken, sessionToken)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, accessToken, sessionToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeletePlaylist provides a mock function with given fields: ctx, orgId, uid
+func (_m *FakePublicDashboardService) DeletePlaylist(ctx context.Context, orgId int64, uid string) error {
+	ret := _m.Called(ctx, orgId, uid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(ctx, orgId, uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteReportSubscription provides a mock function with given fields: ctx, u
+func (_m *FakePublicDashboardService) DeleteReportSubscription(ctx context.Context, u *user.SignedInUser) error {
+	ret := _m.Called(ctx, u)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser) error); ok {
+		r0 = rf(ctx, u)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ExistsEnabledByAccessToken provides a mock function with given fields: ctx, accessToken
 func (_m *FakePublicDashboardService) ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error) {
 	ret := _m.Called(ctx, accessToken)
@@ -65,22 +109,68 @@ func (_m *FakePublicDashboardService) ExistsEnabledByDashboardUid(ctx context.Co
 	return r0, r1
 }
 
-// FindAll provides a mock function with given fields: ctx, u, orgId
-func (_m *FakePublicDashboardService) FindAll(ctx context.Context, u *user.SignedInUser, orgId int64) ([]models.PublicDashboardListResponse, error) {
-	ret := _m.Called(ctx, u, orgId)
+// FindAll provides a mock function with given fields: ctx, u, orgId, page
+func (_m *FakePublicDashboardService) FindAll(ctx context.Context, u *user.SignedInUser, orgId int64, page pagination.Page) (*models.PublicDashboardListResponseWithPagination, error) {
+	ret := _m.Called(ctx, u, orgId, page)
 
-	var r0 []models.PublicDashboardListResponse
-	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, int64) []models.PublicDashboardListResponse); ok {
-		r0 = rf(ctx, u, orgId)
+	var r0 *models.PublicDashboardListResponseWithPagination
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, int64, pagination.Page) *models.PublicDashboardListResponseWithPagination); ok {
+		r0 = rf(ctx, u, orgId, page)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.PublicDashboardListResponse)
+			r0 = ret.Get(0).(*models.PublicDashboardListResponseWithPagination)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, int64) error); ok {
-		r1 = rf(ctx, u, orgId)
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, int64, pagination.Page) error); ok {
+		r1 = rf(ctx, u, orgId, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindAllGlobal provides a mock function with given fields: ctx
+func (_m *FakePublicDashboardService) FindAllGlobal(ctx context.Context) ([]models.PublicDashboardGlobalListResponse, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []models.PublicDashboardGlobalListResponse
+	if rf, ok := ret.Get(0).(func(context.Context) []models.PublicDashboardGlobalListResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PublicDashboardGlobalListResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindAllPlaylists provides a mock function with given fields: ctx, orgId
+func (_m *FakePublicDashboardService) FindAllPlaylists(ctx context.Context, orgId int64) ([]models.PublicPlaylist, error) {
+	ret := _m.Called(ctx, orgId)
+
+	var r0 []models.PublicPlaylist
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []models.PublicPlaylist); ok {
+		r0 = rf(ctx, orgId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PublicPlaylist)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, orgId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -157,6 +247,29 @@ func (_m *FakePublicDashboardService) FindDashboard(ctx context.Context, dashboa
 	return r0, r1
 }
 
+// FindPlaylist provides a mock function with given fields: ctx, orgId, uid
+func (_m *FakePublicDashboardService) FindPlaylist(ctx context.Context, orgId int64, uid string) (*models.PublicPlaylist, error) {
+	ret := _m.Called(ctx, orgId, uid)
+
+	var r0 *models.PublicPlaylist
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) *models.PublicPlaylist); ok {
+		r0 = rf(ctx, orgId, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicPlaylist)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, orgId, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindPublicDashboardAndDashboardByAccessToken provides a mock function with given fields: ctx, accessToken
 func (_m *FakePublicDashboardService) FindPublicDashboardAndDashboardByAccessToken(ctx context.Context, accessToken string) (*models.PublicDashboard, *pkgmodels.Dashboard, error) {
 	ret := _m.Called(ctx, accessToken)
@@ -189,6 +302,96 @@ func (_m *FakePublicDashboardService) FindPublicDashboardAndDashboardByAccessTok
 	return r0, r1, r2
 }
 
+// FindPublicFolder provides a mock function with given fields: ctx, orgId, folderUid
+func (_m *FakePublicDashboardService) FindPublicFolder(ctx context.Context, orgId int64, folderUid string) (*models.PublicFolder, error) {
+	ret := _m.Called(ctx, orgId, folderUid)
+
+	var r0 *models.PublicFolder
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) *models.PublicFolder); ok {
+		r0 = rf(ctx, orgId, folderUid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicFolder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, orgId, folderUid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindQueryExecutions provides a mock function with given fields: ctx, requestId
+func (_m *FakePublicDashboardService) FindQueryExecutions(ctx context.Context, requestId string) ([]models.PublicDashboardQueryExecution, error) {
+	ret := _m.Called(ctx, requestId)
+
+	var r0 []models.PublicDashboardQueryExecution
+	if rf, ok := ret.Get(0).(func(context.Context, string) []models.PublicDashboardQueryExecution); ok {
+		r0 = rf(ctx, requestId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PublicDashboardQueryExecution)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, requestId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindReportSubscription provides a mock function with given fields: ctx, u
+func (_m *FakePublicDashboardService) FindReportSubscription(ctx context.Context, u *user.SignedInUser) (*models.PublicDashboardReportSubscription, error) {
+	ret := _m.Called(ctx, u)
+
+	var r0 *models.PublicDashboardReportSubscription
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser) *models.PublicDashboardReportSubscription); ok {
+		r0 = rf(ctx, u)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboardReportSubscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser) error); ok {
+		r1 = rf(ctx, u)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCacheControlByAccessToken provides a mock function with given fields: ctx, accessToken
+func (_m *FakePublicDashboardService) GetCacheControlByAccessToken(ctx context.Context, accessToken string) (string, error) {
+	ret := _m.Called(ctx, accessToken)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, accessToken)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, accessToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMetricRequest provides a mock function with given fields: ctx, dashboard, publicDashboard, panelId, reqDTO
 func (_m *FakePublicDashboardService) GetMetricRequest(ctx context.Context, dashboard *pkgmodels.Dashboard, publicDashboard *models.PublicDashboard, panelId int64, reqDTO models.PublicDashboardQueryDTO) (dtos.MetricRequest, error) {
 	ret := _m.Called(ctx, dashboard, publicDashboard, panelId, reqDTO)
@@ -231,6 +434,52 @@ func (_m *FakePublicDashboardService) GetOrgIdByAccessToken(ctx context.Context,
 	return r0, r1
 }
 
+// GetPlaylistViewerPayload provides a mock function with given fields: ctx, accessToken
+func (_m *FakePublicDashboardService) GetPlaylistViewerPayload(ctx context.Context, accessToken string) (*models.PublicPlaylistViewerPayload, error) {
+	ret := _m.Called(ctx, accessToken)
+
+	var r0 *models.PublicPlaylistViewerPayload
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.PublicPlaylistViewerPayload); ok {
+		r0 = rf(ctx, accessToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicPlaylistViewerPayload)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, accessToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPublicFolderViewerPayload provides a mock function with given fields: ctx, accessToken
+func (_m *FakePublicDashboardService) GetPublicFolderViewerPayload(ctx context.Context, accessToken string) (*models.PublicFolderViewerPayload, error) {
+	ret := _m.Called(ctx, accessToken)
+
+	var r0 *models.PublicFolderViewerPayload
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.PublicFolderViewerPayload); ok {
+		r0 = rf(ctx, accessToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicFolderViewerPayload)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, accessToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetQueryDataResponse provides a mock function with given fields: ctx, skipCache, reqDTO, panelId, accessToken
 func (_m *FakePublicDashboardService) GetQueryDataResponse(ctx context.Context, skipCache bool, reqDTO models.PublicDashboardQueryDTO, panelId int64, accessToken string) (*backend.QueryDataResponse, error) {
 	ret := _m.Called(ctx, skipCache, reqDTO, panelId, accessToken)
@@ -296,6 +545,48 @@ func (_m *FakePublicDashboardService) NewPublicDashboardUid(ctx context.Context)
 	return r0, r1
 }
 
+// Preview provides a mock function with given fields: ctx, u, dto
+func (_m *FakePublicDashboardService) Preview(ctx context.Context, u *user.SignedInUser, dto *models.SavePublicDashboardConfigDTO) (*models.PublicDashboard, error) {
+	ret := _m.Called(ctx, u, dto)
+
+	var r0 *models.PublicDashboard
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, *models.SavePublicDashboardConfigDTO) *models.PublicDashboard); ok {
+		r0 = rf(ctx, u, dto)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboard)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, *models.SavePublicDashboardConfigDTO) error); ok {
+		r1 = rf(ctx, u, dto)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordView provides a mock function with given fields: ctx, publicDashboard, anonymous
+func (_m *FakePublicDashboardService) RecordView(ctx context.Context, publicDashboard *models.PublicDashboard, anonymous bool) {
+	_m.Called(ctx, publicDashboard, anonymous)
+}
+
+// RequestMagicLink provides a mock function with given fields: ctx, accessToken, email
+func (_m *FakePublicDashboardService) RequestMagicLink(ctx context.Context, accessToken string, email string) error {
+	ret := _m.Called(ctx, accessToken, email)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, accessToken, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Save provides a mock function with given fields: ctx, u, dto
 func (_m *FakePublicDashboardService) Save(ctx context.Context, u *user.SignedInUser, dto *models.SavePublicDashboardConfigDTO) (*models.PublicDashboard, error) {
 	ret := _m.Called(ctx, u, dto)
@@ -319,6 +610,121 @@ func (_m *FakePublicDashboardService) Save(ctx context.Context, u *user.SignedIn
 	return r0, r1
 }
 
+// SavePlaylist provides a mock function with given fields: ctx, u, dto
+func (_m *FakePublicDashboardService) SavePlaylist(ctx context.Context, u *user.SignedInUser, dto *models.SavePublicPlaylistDTO) (*models.PublicPlaylist, error) {
+	ret := _m.Called(ctx, u, dto)
+
+	var r0 *models.PublicPlaylist
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, *models.SavePublicPlaylistDTO) *models.PublicPlaylist); ok {
+		r0 = rf(ctx, u, dto)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicPlaylist)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, *models.SavePublicPlaylistDTO) error); ok {
+		r1 = rf(ctx, u, dto)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SavePublicFolder provides a mock function with given fields: ctx, u, dto
+func (_m *FakePublicDashboardService) SavePublicFolder(ctx context.Context, u *user.SignedInUser, dto *models.SavePublicFolderDTO) (*models.PublicFolder, error) {
+	ret := _m.Called(ctx, u, dto)
+
+	var r0 *models.PublicFolder
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, *models.SavePublicFolderDTO) *models.PublicFolder); ok {
+		r0 = rf(ctx, u, dto)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicFolder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, *models.SavePublicFolderDTO) error); ok {
+		r1 = rf(ctx, u, dto)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveReportSubscription provides a mock function with given fields: ctx, u
+func (_m *FakePublicDashboardService) SaveReportSubscription(ctx context.Context, u *user.SignedInUser) (*models.PublicDashboardReportSubscription, error) {
+	ret := _m.Called(ctx, u)
+
+	var r0 *models.PublicDashboardReportSubscription
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser) *models.PublicDashboardReportSubscription); ok {
+		r0 = rf(ctx, u)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboardReportSubscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser) error); ok {
+		r1 = rf(ctx, u)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TransferOwnership provides a mock function with given fields: ctx, u, dto
+func (_m *FakePublicDashboardService) TransferOwnership(ctx context.Context, u *user.SignedInUser, dto *models.TransferPublicDashboardOwnershipDTO) (*models.PublicDashboard, error) {
+	ret := _m.Called(ctx, u, dto)
+
+	var r0 *models.PublicDashboard
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, *models.TransferPublicDashboardOwnershipDTO) *models.PublicDashboard); ok {
+		r0 = rf(ctx, u, dto)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboard)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, *models.TransferPublicDashboardOwnershipDTO) error); ok {
+		r1 = rf(ctx, u, dto)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VerifyMagicLink provides a mock function with given fields: ctx, accessToken, code
+func (_m *FakePublicDashboardService) VerifyMagicLink(ctx context.Context, accessToken string, code string) (*models.PublicDashboardSessionToken, error) {
+	ret := _m.Called(ctx, accessToken, code)
+
+	var r0 *models.PublicDashboardSessionToken
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.PublicDashboardSessionToken); ok {
+		r0 = rf(ctx, accessToken, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboardSessionToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, accessToken, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewFakePublicDashboardService interface {
 	mock.TestingT
 	Cleanup(func())
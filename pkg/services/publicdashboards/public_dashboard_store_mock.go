@@ -9,6 +9,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	pkgmodels "github.com/grafana/grafana/pkg/models"
+
+	time "time"
 )
 
 // FakePublicDashboardStore is an autogenerated mock type for the Store type
@@ -16,6 +18,20 @@ type FakePublicDashboardStore struct {
 	mock.Mock
 }
 
+// Delete provides a mock function with given fields: ctx, uid
+func (_m *FakePublicDashboardStore) Delete(ctx context.Context, uid string) error {
+	ret := _m.Called(ctx, uid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ExistsEnabledByAccessToken provides a mock function with given fields: ctx, accessToken
 func (_m *FakePublicDashboardStore) ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error) {
 	ret := _m.Called(ctx, accessToken)
@@ -104,6 +120,29 @@ func (_m *FakePublicDashboardStore) FindAll(ctx context.Context, orgId int64) ([
 	return r0, r1
 }
 
+// FindAllGlobal provides a mock function with given fields: ctx
+func (_m *FakePublicDashboardStore) FindAllGlobal(ctx context.Context) ([]models.PublicDashboardGlobalListResponse, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []models.PublicDashboardGlobalListResponse
+	if rf, ok := ret.Get(0).(func(context.Context) []models.PublicDashboardGlobalListResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PublicDashboardGlobalListResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindByAccessToken provides a mock function with given fields: ctx, accessToken
 func (_m *FakePublicDashboardStore) FindByAccessToken(ctx context.Context, accessToken string) (*models.PublicDashboard, error) {
 	ret := _m.Called(ctx, accessToken)
@@ -127,6 +166,52 @@ func (_m *FakePublicDashboardStore) FindByAccessToken(ctx context.Context, acces
 	return r0, r1
 }
 
+// FindByAccessTokens provides a mock function with given fields: ctx, accessTokens
+func (_m *FakePublicDashboardStore) FindByAccessTokens(ctx context.Context, accessTokens []string) (map[string]*models.PublicDashboard, error) {
+	ret := _m.Called(ctx, accessTokens)
+
+	var r0 map[string]*models.PublicDashboard
+	if rf, ok := ret.Get(0).(func(context.Context, []string) map[string]*models.PublicDashboard); ok {
+		r0 = rf(ctx, accessTokens)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]*models.PublicDashboard)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, accessTokens)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByCreatedBy provides a mock function with given fields: ctx, userId
+func (_m *FakePublicDashboardStore) FindByCreatedBy(ctx context.Context, userId int64) ([]*models.PublicDashboard, error) {
+	ret := _m.Called(ctx, userId)
+
+	var r0 []*models.PublicDashboard
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*models.PublicDashboard); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.PublicDashboard)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindByDashboardUid provides a mock function with given fields: ctx, orgId, dashboardUid
 func (_m *FakePublicDashboardStore) FindByDashboardUid(ctx context.Context, orgId int64, dashboardUid string) (*models.PublicDashboard, error) {
 	ret := _m.Called(ctx, orgId, dashboardUid)
@@ -150,6 +235,29 @@ func (_m *FakePublicDashboardStore) FindByDashboardUid(ctx context.Context, orgI
 	return r0, r1
 }
 
+// FindByDashboardUids provides a mock function with given fields: ctx, orgId, dashboardUids
+func (_m *FakePublicDashboardStore) FindByDashboardUids(ctx context.Context, orgId int64, dashboardUids []string) (map[string]*models.PublicDashboard, error) {
+	ret := _m.Called(ctx, orgId, dashboardUids)
+
+	var r0 map[string]*models.PublicDashboard
+	if rf, ok := ret.Get(0).(func(context.Context, int64, []string) map[string]*models.PublicDashboard); ok {
+		r0 = rf(ctx, orgId, dashboardUids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]*models.PublicDashboard)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, []string) error); ok {
+		r1 = rf(ctx, orgId, dashboardUids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindDashboard provides a mock function with given fields: ctx, dashboardUid, orgId
 func (_m *FakePublicDashboardStore) FindDashboard(ctx context.Context, dashboardUid string, orgId int64) (*pkgmodels.Dashboard, error) {
 	ret := _m.Called(ctx, dashboardUid, orgId)
@@ -173,6 +281,43 @@ func (_m *FakePublicDashboardStore) FindDashboard(ctx context.Context, dashboard
 	return r0, r1
 }
 
+// FindSharedByFolders provides a mock function with given fields: ctx, orgId
+func (_m *FakePublicDashboardStore) FindSharedByFolders(ctx context.Context, orgId int64) ([]*models.PublicDashboard, error) {
+	ret := _m.Called(ctx, orgId)
+
+	var r0 []*models.PublicDashboard
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*models.PublicDashboard); ok {
+		r0 = rf(ctx, orgId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.PublicDashboard)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, orgId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FlagCreatorDeleted provides a mock function with given fields: ctx, uid
+func (_m *FakePublicDashboardStore) FlagCreatorDeleted(ctx context.Context, uid string) error {
+	ret := _m.Called(ctx, uid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetOrgIdByAccessToken provides a mock function with given fields: ctx, accessToken
 func (_m *FakePublicDashboardStore) GetOrgIdByAccessToken(ctx context.Context, accessToken string) (int64, error) {
 	ret := _m.Called(ctx, accessToken)
@@ -222,6 +367,34 @@ func (_m *FakePublicDashboardStore) Update(ctx context.Context, cmd models.SaveP
 	return r0
 }
 
+// UpdateLastUsedAt provides a mock function with given fields: ctx, lastUsed
+func (_m *FakePublicDashboardStore) UpdateLastUsedAt(ctx context.Context, lastUsed map[string]time.Time) error {
+	ret := _m.Called(ctx, lastUsed)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, map[string]time.Time) error); ok {
+		r0 = rf(ctx, lastUsed)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateOwner provides a mock function with given fields: ctx, cmd
+func (_m *FakePublicDashboardStore) UpdateOwner(ctx context.Context, cmd models.TransferPublicDashboardOwnershipCommand) error {
+	ret := _m.Called(ctx, cmd)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.TransferPublicDashboardOwnershipCommand) error); ok {
+		r0 = rf(ctx, cmd)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewFakePublicDashboardStore interface {
 	mock.TestingT
 	Cleanup(func())
@@ -2,12 +2,15 @@ package publicdashboards
 
 import (
 	"context"
+	"net"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/models"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 // These are the api contracts. The API should match the underlying service and store
@@ -18,30 +21,117 @@ type Service interface {
 	FindByDashboardUid(ctx context.Context, orgId int64, dashboardUid string) (*PublicDashboard, error)
 	FindAnnotations(ctx context.Context, reqDTO AnnotationsQueryDTO, accessToken string) ([]AnnotationEvent, error)
 	FindDashboard(ctx context.Context, dashboardUid string, orgId int64) (*models.Dashboard, error)
-	FindAll(ctx context.Context, u *user.SignedInUser, orgId int64) ([]PublicDashboardListResponse, error)
+	FindAll(ctx context.Context, u *user.SignedInUser, orgId int64, page pagination.Page) (*PublicDashboardListResponseWithPagination, error)
+	FindAllGlobal(ctx context.Context) ([]PublicDashboardGlobalListResponse, error)
 	Save(ctx context.Context, u *user.SignedInUser, dto *SavePublicDashboardConfigDTO) (*PublicDashboard, error)
+	Preview(ctx context.Context, u *user.SignedInUser, dto *SavePublicDashboardConfigDTO) (*PublicDashboard, error)
+	TransferOwnership(ctx context.Context, u *user.SignedInUser, dto *TransferPublicDashboardOwnershipDTO) (*PublicDashboard, error)
 
 	GetMetricRequest(ctx context.Context, dashboard *models.Dashboard, publicDashboard *PublicDashboard, panelId int64, reqDTO PublicDashboardQueryDTO) (dtos.MetricRequest, error)
 	GetQueryDataResponse(ctx context.Context, skipCache bool, reqDTO PublicDashboardQueryDTO, panelId int64, accessToken string) (*backend.QueryDataResponse, error)
+	FindQueryExecutions(ctx context.Context, requestId string) ([]PublicDashboardQueryExecution, error)
 	GetOrgIdByAccessToken(ctx context.Context, accessToken string) (int64, error)
+	GetCacheControlByAccessToken(ctx context.Context, accessToken string) (string, error)
 	NewPublicDashboardAccessToken(ctx context.Context) (string, error)
 	NewPublicDashboardUid(ctx context.Context) (string, error)
 
 	ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error)
 	ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error)
+
+	FindAllPlaylists(ctx context.Context, orgId int64) ([]PublicPlaylist, error)
+	FindPlaylist(ctx context.Context, orgId int64, uid string) (*PublicPlaylist, error)
+	SavePlaylist(ctx context.Context, u *user.SignedInUser, dto *SavePublicPlaylistDTO) (*PublicPlaylist, error)
+	DeletePlaylist(ctx context.Context, orgId int64, uid string) error
+	GetPlaylistViewerPayload(ctx context.Context, accessToken string) (*PublicPlaylistViewerPayload, error)
+
+	FindPublicFolder(ctx context.Context, orgId int64, folderUid string) (*PublicFolder, error)
+	SavePublicFolder(ctx context.Context, u *user.SignedInUser, dto *SavePublicFolderDTO) (*PublicFolder, error)
+	GetPublicFolderViewerPayload(ctx context.Context, accessToken string) (*PublicFolderViewerPayload, error)
+
+	RequestMagicLink(ctx context.Context, accessToken string, email string) error
+	VerifyMagicLink(ctx context.Context, accessToken string, code string) (*PublicDashboardSessionToken, error)
+	CheckEmailSession(ctx context.Context, accessToken string, sessionToken string) error
+
+	FindReportSubscription(ctx context.Context, u *user.SignedInUser) (*PublicDashboardReportSubscription, error)
+	SaveReportSubscription(ctx context.Context, u *user.SignedInUser) (*PublicDashboardReportSubscription, error)
+	DeleteReportSubscription(ctx context.Context, u *user.SignedInUser) error
+	RecordView(ctx context.Context, publicDashboard *PublicDashboard, anonymous bool)
+}
+
+//go:generate mockery --name QueryDataExecutor --structname FakeQueryDataExecutor --inpackage --filename query_data_executor_mock.go
+type QueryDataExecutor interface {
+	QueryData(ctx context.Context, user *user.SignedInUser, skipCache bool, reqDTO dtos.MetricRequest) (*backend.QueryDataResponse, error)
+}
+
+//go:generate mockery --name GeoIPResolver --structname FakeGeoIPResolver --inpackage --filename geoip_resolver_mock.go
+type GeoIPResolver interface {
+	// Country returns the ISO 3166-1 alpha-2 code of the country of the IP address, or an empty string if unknown
+	Country(ctx context.Context, ip net.IP) (string, error)
 }
 
 //go:generate mockery --name Store --structname FakePublicDashboardStore --inpackage --filename public_dashboard_store_mock.go
 type Store interface {
 	Find(ctx context.Context, uid string) (*PublicDashboard, error)
 	FindByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, error)
+	FindByAccessTokens(ctx context.Context, accessTokens []string) (map[string]*PublicDashboard, error)
 	FindByDashboardUid(ctx context.Context, orgId int64, dashboardUid string) (*PublicDashboard, error)
+	FindByDashboardUids(ctx context.Context, orgId int64, dashboardUids []string) (map[string]*PublicDashboard, error)
 	FindDashboard(ctx context.Context, dashboardUid string, orgId int64) (*models.Dashboard, error)
 	FindAll(ctx context.Context, orgId int64) ([]PublicDashboardListResponse, error)
+	FindAllGlobal(ctx context.Context) ([]PublicDashboardGlobalListResponse, error)
 	Save(ctx context.Context, cmd SavePublicDashboardConfigCommand) error
 	Update(ctx context.Context, cmd SavePublicDashboardConfigCommand) error
+	UpdateLastUsedAt(ctx context.Context, lastUsed map[string]time.Time) error
+	UpdateOwner(ctx context.Context, cmd TransferPublicDashboardOwnershipCommand) error
+	FindByCreatedBy(ctx context.Context, userId int64) ([]*PublicDashboard, error)
+	FindSharedByFolders(ctx context.Context, orgId int64) ([]*PublicDashboard, error)
+	FlagCreatorDeleted(ctx context.Context, uid string) error
+	Delete(ctx context.Context, uid string) error
 
 	GetOrgIdByAccessToken(ctx context.Context, accessToken string) (int64, error)
 	ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error)
 	ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error)
 }
+
+//go:generate mockery --name PlaylistStore --structname FakePublicPlaylistStore --inpackage --filename public_playlist_store_mock.go
+type PlaylistStore interface {
+	Find(ctx context.Context, orgId int64, uid string) (*PublicPlaylist, error)
+	FindByAccessToken(ctx context.Context, accessToken string) (*PublicPlaylist, error)
+	FindAll(ctx context.Context, orgId int64) ([]PublicPlaylist, error)
+	Save(ctx context.Context, playlist *PublicPlaylist) error
+	Update(ctx context.Context, playlist *PublicPlaylist) error
+	Delete(ctx context.Context, orgId int64, uid string) error
+}
+
+//go:generate mockery --name FolderStore --structname FakePublicFolderStore --inpackage --filename public_folder_store_mock.go
+type FolderStore interface {
+	Find(ctx context.Context, orgId int64, folderUid string) (*PublicFolder, error)
+	FindByAccessToken(ctx context.Context, accessToken string) (*PublicFolder, error)
+	FindDashboards(ctx context.Context, orgId int64, folderId int64) ([]*models.Dashboard, error)
+	Save(ctx context.Context, folder *PublicFolder) error
+	Update(ctx context.Context, folder *PublicFolder) error
+}
+
+//go:generate mockery --name EmailSessionStore --structname FakePublicDashboardEmailSessionStore --inpackage --filename public_dashboard_email_session_store_mock.go
+type EmailSessionStore interface {
+	SaveMagicLink(ctx context.Context, link *PublicDashboardMagicLink) error
+	ConsumeMagicLink(ctx context.Context, tokenHash string) (*PublicDashboardMagicLink, error)
+	SaveSession(ctx context.Context, session *PublicDashboardSession) error
+	FindSession(ctx context.Context, tokenHash string) (*PublicDashboardSession, error)
+}
+
+//go:generate mockery --name ReportStore --structname FakePublicDashboardReportStore --inpackage --filename public_dashboard_report_store_mock.go
+type ReportStore interface {
+	RecordUsage(ctx context.Context, usage *PublicDashboardUsage) error
+	RecordView(ctx context.Context, orgId int64, publicDashboardUid string, day string, anonymous bool) error
+	GetUsageStats(ctx context.Context, fromDay string, toDay string) (*PublicDashboardUsageStats, error)
+	FindUsageSummaries(ctx context.Context, orgId int64, userId int64, fromDay string, toDay string) ([]PublicDashboardUsageSummary, error)
+	RecordPanelLatency(ctx context.Context, orgId int64, publicDashboardUid string, panelId int64, day string, durationMs int64) error
+	FindPanelLatencies(ctx context.Context, publicDashboardUid string, fromDay string, toDay string) (map[int64]int64, error)
+
+	FindSubscription(ctx context.Context, orgId int64, userId int64) (*PublicDashboardReportSubscription, error)
+	FindDueSubscriptions(ctx context.Context, sentBefore time.Time) ([]*PublicDashboardReportSubscription, error)
+	SaveSubscription(ctx context.Context, subscription *PublicDashboardReportSubscription) error
+	UpdateSubscription(ctx context.Context, subscription *PublicDashboardReportSubscription) error
+	DeleteSubscription(ctx context.Context, orgId int64, userId int64) error
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+// panelCostHintsPeriod is the number of days the typical latency of panels is computed over
+const panelCostHintsPeriod = 7
+
+// Thresholds of the cost classes of panels. A panel gets the highest class reached by its query count or latency
+const (
+	panelCostMediumQueryCount = 3
+	panelCostHighQueryCount   = 6
+	panelCostMediumLatency    = time.Second
+	panelCostHighLatency      = 5 * time.Second
+)
+
+// buildPanelCostHints computes the cost hints of the panels of the dashboard. The typical latency comes from the
+// usage of the public dashboard, failing to read it does not fail the hints
+func (pd *PublicDashboardServiceImpl) buildPanelCostHints(ctx context.Context, dashboard *models.Dashboard, publicDashboardUid string) PanelCostHints {
+	latencies := map[int64]int64{}
+	if publicDashboardUid != "" && pd.reportStore != nil {
+		today := time.Now().UTC()
+		var err error
+		latencies, err = pd.reportStore.FindPanelLatencies(ctx, publicDashboardUid, today.AddDate(0, 0, -panelCostHintsPeriod).Format(UsageDayFormat), today.AddDate(0, 0, 1).Format(UsageDayFormat))
+		if err != nil {
+			pd.log.FromContext(ctx).Warn("Failed to get public dashboard panel latencies", "publicDashboardUid", publicDashboardUid, "error", err)
+			latencies = map[int64]int64{}
+		}
+	}
+
+	hints := make(PanelCostHints, 0)
+	for _, panelObj := range dashboard.Data.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(panelObj)
+		if _, ok := panel.CheckGet("targets"); !ok {
+			continue
+		}
+
+		panelId := panel.Get("id").MustInt64()
+		queryCount, datasourceTypes := panelQueryCountAndDatasourceTypes(panel)
+		hints = append(hints, PanelCostHint{
+			PanelId:          panelId,
+			QueryCount:       queryCount,
+			DatasourceTypes:  datasourceTypes,
+			TypicalLatencyMs: latencies[panelId],
+			Cost:             classifyPanelCost(queryCount, time.Duration(latencies[panelId])*time.Millisecond),
+		})
+	}
+
+	return hints
+}
+
+// panelQueryCountAndDatasourceTypes returns the number of queries the panel runs and the sorted types of the data
+// sources they query. Hidden queries are not run
+func panelQueryCountAndDatasourceTypes(panel *simplejson.Json) (int, []string) {
+	queryCount := 0
+	types := make(map[string]bool)
+	for _, queryObj := range panel.Get("targets").MustArray() {
+		query := simplejson.NewFromAny(queryObj)
+		if query.Get("hide").MustBool() {
+			continue
+		}
+		queryCount++
+
+		// queries without a data source use the data source of the panel
+		datasourceType := query.Get("datasource").Get("type").MustString()
+		if datasourceType == "" {
+			datasourceType = panel.Get("datasource").Get("type").MustString()
+		}
+		if datasourceType != "" {
+			types[datasourceType] = true
+		}
+	}
+
+	datasourceTypes := make([]string, 0, len(types))
+	for datasourceType := range types {
+		datasourceTypes = append(datasourceTypes, datasourceType)
+	}
+	sort.Strings(datasourceTypes)
+
+	return queryCount, datasourceTypes
+}
+
+func classifyPanelCost(queryCount int, latency time.Duration) string {
+	switch {
+	case queryCount >= panelCostHighQueryCount || latency >= panelCostHighLatency:
+		return PanelCostHigh
+	case queryCount >= panelCostMediumQueryCount || latency >= panelCostMediumLatency:
+		return PanelCostMedium
+	default:
+		return PanelCostLow
+	}
+}
+
+// recordPanelLatency adds the duration of the queries of a panel to the usage of the public dashboard, for its cost
+// hints. Failing to do so does not fail the query
+func (pd *PublicDashboardServiceImpl) recordPanelLatency(ctx context.Context, publicDashboard *PublicDashboard, panelId int64, duration time.Duration) {
+	if pd.reportStore == nil {
+		return
+	}
+
+	day := time.Now().UTC().Format(UsageDayFormat)
+	if err := pd.reportStore.RecordPanelLatency(ctx, publicDashboard.OrgId, publicDashboard.Uid, panelId, day, duration.Milliseconds()); err != nil {
+		pd.log.FromContext(ctx).Warn("Failed to record public dashboard panel latency", "publicDashboardUid", publicDashboard.Uid, "panelId", panelId, "error", err)
+	}
+}
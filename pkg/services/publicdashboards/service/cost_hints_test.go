@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+func TestBuildPanelCostHints(t *testing.T) {
+	query := func(datasourceType string) map[string]interface{} {
+		return map[string]interface{}{"datasource": map[string]interface{}{"type": datasourceType}}
+	}
+	dashboard := &models.Dashboard{Data: simplejson.NewFromAny(map[string]interface{}{
+		"panels": []interface{}{
+			map[string]interface{}{
+				"id":         1,
+				"datasource": map[string]interface{}{"type": "prometheus"},
+				"targets":    []interface{}{map[string]interface{}{"refId": "A"}, query("prometheus"), map[string]interface{}{"hide": true}},
+			},
+			map[string]interface{}{
+				"id":      2,
+				"targets": []interface{}{query("mysql"), query("loki"), query("mysql")},
+			},
+			map[string]interface{}{
+				"id":      3,
+				"targets": []interface{}{query("mysql"), query("mysql"), query("mysql"), query("mysql"), query("mysql"), query("mysql")},
+			},
+			// text panels do not query
+			map[string]interface{}{"id": 4, "type": "text"},
+		},
+	})}
+
+	t.Run("classifies the panels from their queries and typical latency", func(t *testing.T) {
+		reportStore := NewFakePublicDashboardReportStore(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), reportStore: reportStore}
+		reportStore.On("FindPanelLatencies", mock.Anything, "pubdash1", mock.Anything, mock.Anything).Return(map[int64]int64{1: 7000, 2: 200}, nil)
+
+		hints := service.buildPanelCostHints(context.Background(), dashboard, "pubdash1")
+		assert.Equal(t, PanelCostHints{
+			{PanelId: 1, QueryCount: 2, DatasourceTypes: []string{"prometheus"}, TypicalLatencyMs: 7000, Cost: PanelCostHigh},
+			{PanelId: 2, QueryCount: 3, DatasourceTypes: []string{"loki", "mysql"}, TypicalLatencyMs: 200, Cost: PanelCostMedium},
+			{PanelId: 3, QueryCount: 6, DatasourceTypes: []string{"mysql"}, Cost: PanelCostHigh},
+		}, hints)
+	})
+
+	t.Run("builds the hints without latency for new public dashboards", func(t *testing.T) {
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), reportStore: NewFakePublicDashboardReportStore(t)}
+
+		hints := service.buildPanelCostHints(context.Background(), dashboard, "")
+		assert.Len(t, hints, 3)
+		assert.Equal(t, PanelCostLow, hints[0].Cost)
+	})
+
+	t.Run("builds the hints without latency when the usage can't be read", func(t *testing.T) {
+		reportStore := NewFakePublicDashboardReportStore(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), reportStore: reportStore}
+		reportStore.On("FindPanelLatencies", mock.Anything, "pubdash1", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+
+		hints := service.buildPanelCostHints(context.Background(), dashboard, "pubdash1")
+		assert.Len(t, hints, 3)
+		assert.Equal(t, int64(0), hints[0].TypicalLatencyMs)
+	})
+}
+
+func TestClassifyPanelCost(t *testing.T) {
+	assert.Equal(t, PanelCostLow, classifyPanelCost(1, 0))
+	assert.Equal(t, PanelCostLow, classifyPanelCost(2, 999*time.Millisecond))
+	assert.Equal(t, PanelCostMedium, classifyPanelCost(3, 0))
+	assert.Equal(t, PanelCostMedium, classifyPanelCost(1, time.Second))
+	assert.Equal(t, PanelCostHigh, classifyPanelCost(6, 0))
+	assert.Equal(t, PanelCostHigh, classifyPanelCost(1, 5*time.Second))
+}
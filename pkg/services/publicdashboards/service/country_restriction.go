@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// CIDRGeoIPResolver resolves countries from a list of networks in CIDR notation. Lookups return the country of the
+// most specific network containing the address
+type CIDRGeoIPResolver struct {
+	networks []geoIPNetwork
+}
+
+type geoIPNetwork struct {
+	network *net.IPNet
+	country string
+}
+
+// ProvideGeoIPResolver builds the GeoIP resolver from the CSV file configured in the public_dashboards section. When
+// no file is configured, no country can be resolved and public dashboards restricted by country can't be viewed
+func ProvideGeoIPResolver(cfg *setting.Cfg) (*CIDRGeoIPResolver, error) {
+	if cfg.PublicDashboards.GeoIPCIDRFile == "" {
+		return &CIDRGeoIPResolver{}, nil
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning since the path comes from the configuration file
+	file, err := os.Open(cfg.PublicDashboards.GeoIPCIDRFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP CIDR file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	return NewCIDRGeoIPResolver(file)
+}
+
+// NewCIDRGeoIPResolver reads networks from CSV records made of a network in CIDR notation and an ISO 3166-1 alpha-2
+// country code, such as "192.0.2.0/24,FR". Empty lines and lines starting with # are ignored
+func NewCIDRGeoIPResolver(r io.Reader) (*CIDRGeoIPResolver, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	resolver := &CIDRGeoIPResolver{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GeoIP CIDR file: %w", err)
+		}
+
+		_, network, err := net.ParseCIDR(strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid network in GeoIP CIDR file: %w", err)
+		}
+
+		countries, err := validation.NormalizeCountryList(CountryList{record[1]})
+		if err != nil || len(countries) != 1 {
+			return nil, fmt.Errorf("invalid country code %q in GeoIP CIDR file", record[1])
+		}
+
+		resolver.networks = append(resolver.networks, geoIPNetwork{network: network, country: countries[0]})
+	}
+
+	// most specific networks first
+	sort.SliceStable(resolver.networks, func(i, j int) bool {
+		iOnes, _ := resolver.networks[i].network.Mask.Size()
+		jOnes, _ := resolver.networks[j].network.Mask.Size()
+		return iOnes > jOnes
+	})
+
+	return resolver, nil
+}
+
+// Country returns the country of the most specific network containing the address, or an empty string if the
+// address is not part of any network
+func (r *CIDRGeoIPResolver) Country(_ context.Context, ip net.IP) (string, error) {
+	for _, n := range r.networks {
+		if n.network.Contains(ip) {
+			return n.country, nil
+		}
+	}
+	return "", nil
+}
+
+// checkCountryAccess returns ErrPublicDashboardCountryRestricted when the public dashboard is restricted by country
+// and the viewer is not allowed to view it. Viewers whose country can't be resolved are rejected
+func (pd *PublicDashboardServiceImpl) checkCountryAccess(ctx context.Context, publicDashboard *PublicDashboard) error {
+	if !publicDashboard.HasCountryRestrictions() {
+		return nil
+	}
+
+	var country string
+	if ip := ViewerIPFromContext(ctx); ip != nil && pd.geoIPResolver != nil {
+		var err error
+		country, err = pd.geoIPResolver.Country(ctx, ip)
+		if err != nil {
+			pd.log.FromContext(ctx).Warn("Failed to resolve the country of public dashboard viewer", "publicDashboardUid", publicDashboard.Uid, "error", err)
+			country = ""
+		}
+	}
+
+	if !publicDashboard.AllowsCountry(country) {
+		pd.log.FromContext(ctx).Debug("Public dashboard is not available in the country of the viewer", "publicDashboardUid", publicDashboard.Uid, "country", country)
+		return ErrPublicDashboardCountryRestricted
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+func TestCIDRGeoIPResolver(t *testing.T) {
+	resolver, err := NewCIDRGeoIPResolver(strings.NewReader(`# network,country
+192.0.2.0/24,fr
+192.0.2.128/25, DE
+
+2001:db8::/32,US
+`))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		ip      string
+		country string
+	}{
+		{ip: "192.0.2.1", country: "FR"},
+		{ip: "192.0.2.200", country: "DE"},
+		{ip: "2001:db8::1", country: "US"},
+		{ip: "198.51.100.1", country: ""},
+	}
+	for _, tc := range testCases {
+		country, err := resolver.Country(context.Background(), net.ParseIP(tc.ip))
+		require.NoError(t, err)
+		assert.Equal(t, tc.country, country, tc.ip)
+	}
+
+	t.Run("returns an error for invalid records", func(t *testing.T) {
+		for _, records := range []string{"192.0.2.0/33,FR", "192.0.2.0/24,France", "192.0.2.0/24"} {
+			_, err := NewCIDRGeoIPResolver(strings.NewReader(records))
+			require.Error(t, err, records)
+		}
+	})
+}
+
+func TestCheckCountryAccess(t *testing.T) {
+	viewerIP := net.ParseIP("192.0.2.1")
+	viewerCtx := WithViewerIP(context.Background(), viewerIP)
+
+	newService := func(t *testing.T, country string, err error) *PublicDashboardServiceImpl {
+		geoIPResolver := NewFakeGeoIPResolver(t)
+		geoIPResolver.On("Country", viewerCtx, viewerIP).Return(country, err).Maybe()
+		return &PublicDashboardServiceImpl{log: log.New("test.logger"), geoIPResolver: geoIPResolver}
+	}
+
+	testCases := []struct {
+		name    string
+		pubdash *PublicDashboard
+		country string
+		allowed bool
+	}{
+		{name: "allows every viewer without restrictions", pubdash: &PublicDashboard{}, country: "", allowed: true},
+		{name: "allows viewers from allowed countries", pubdash: &PublicDashboard{AllowedCountries: CountryList{"FR"}}, country: "FR", allowed: true},
+		{name: "rejects viewers from other countries", pubdash: &PublicDashboard{AllowedCountries: CountryList{"FR"}}, country: "DE", allowed: false},
+		{name: "rejects viewers from blocked countries", pubdash: &PublicDashboard{BlockedCountries: CountryList{"DE"}}, country: "DE", allowed: false},
+		{name: "allows viewers from countries that are not blocked", pubdash: &PublicDashboard{BlockedCountries: CountryList{"DE"}}, country: "FR", allowed: true},
+		{name: "blocked countries take precedence", pubdash: &PublicDashboard{AllowedCountries: CountryList{"FR"}, BlockedCountries: CountryList{"FR"}}, country: "FR", allowed: false},
+		{name: "rejects viewers from unknown countries", pubdash: &PublicDashboard{BlockedCountries: CountryList{"DE"}}, country: "", allowed: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := newService(t, tc.country, nil).checkCountryAccess(viewerCtx, tc.pubdash)
+			if tc.allowed {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrPublicDashboardCountryRestricted)
+			}
+		})
+	}
+
+	t.Run("rejects viewers when the country can't be resolved", func(t *testing.T) {
+		err := newService(t, "", errors.New("lookup failed")).checkCountryAccess(viewerCtx, &PublicDashboard{BlockedCountries: CountryList{"DE"}})
+		require.ErrorIs(t, err, ErrPublicDashboardCountryRestricted)
+	})
+
+	t.Run("rejects viewers without IP address", func(t *testing.T) {
+		err := newService(t, "FR", nil).checkCountryAccess(context.Background(), &PublicDashboard{AllowedCountries: CountryList{"FR"}})
+		require.ErrorIs(t, err, ErrPublicDashboardCountryRestricted)
+	})
+
+	t.Run("rejects viewers of the dashboard from other countries", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("FindByAccessToken", viewerCtx, "accessToken").Return(&PublicDashboard{IsEnabled: true, AllowedCountries: CountryList{"FR"}}, nil)
+		service := newService(t, "DE", nil)
+		service.store = store
+
+		_, _, err := service.FindPublicDashboardAndDashboardByAccessToken(viewerCtx, "accessToken")
+		require.ErrorIs(t, err, ErrPublicDashboardCountryRestricted)
+	})
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/tsdb/grafanads"
+)
+
+// frontendDatasourceUids are data sources only available in the browser
+var frontendDatasourceUids = map[string]bool{"-- Dashboard --": true}
+
+// splitUnsupportedQueries removes the queries the anonymous user cannot run from the metric request: queries of
+// deleted data sources, of data sources whose plugin is not installed and of data source types that only run in the
+// browser. It returns the remaining request and the responses explaining why the removed queries were not run, by
+// refId
+func (pd *PublicDashboardServiceImpl) splitUnsupportedQueries(ctx context.Context, anonymousUser *user.SignedInUser, skipCache bool, metricReq dtos.MetricRequest) (dtos.MetricRequest, backend.Responses) {
+	unsupported := backend.Responses{}
+	if pd.dataSourceCache == nil || pd.pluginStore == nil {
+		return metricReq, unsupported
+	}
+
+	supported := make([]*simplejson.Json, 0, len(metricReq.Queries))
+	// data sources are checked once per request
+	checked := make(map[string]*unsupportedDatasource)
+	for _, query := range metricReq.Queries {
+		uid := getDataSourceUidFromJson(query)
+		ds, ok := checked[uid]
+		if !ok {
+			ds = pd.checkDatasourceSupport(ctx, anonymousUser, skipCache, uid)
+			checked[uid] = ds
+		}
+
+		if ds == nil {
+			supported = append(supported, query)
+			continue
+		}
+
+		refId := query.Get("refId").MustString()
+		unsupported[refId] = ds.response(refId)
+	}
+
+	if len(unsupported) == 0 {
+		return metricReq, unsupported
+	}
+	return metricReq.CloneWithQueries(supported), unsupported
+}
+
+// unsupportedDatasource is a data source the anonymous user cannot query and the reason why
+type unsupportedDatasource struct {
+	err            models.PublicDashboardErr
+	uid            string
+	datasourceType string
+}
+
+// checkDatasourceSupport returns why the anonymous user cannot query the data source, or nil if it can. Lookup
+// failures other than a missing data source are left to the query path
+func (pd *PublicDashboardServiceImpl) checkDatasourceSupport(ctx context.Context, anonymousUser *user.SignedInUser, skipCache bool, uid string) *unsupportedDatasource {
+	if frontendDatasourceUids[uid] {
+		return &unsupportedDatasource{err: models.ErrPublicDashboardDatasourceNotSupported, uid: uid}
+	}
+	if uid == "" || expr.IsDataSource(uid) || uid == grafanads.DatasourceUID {
+		return nil
+	}
+
+	ds, err := pd.dataSourceCache.GetDatasourceByUID(ctx, uid, anonymousUser, skipCache)
+	if err != nil {
+		if errors.Is(err, datasources.ErrDataSourceNotFound) {
+			return &unsupportedDatasource{err: models.ErrPublicDashboardDatasourceNotFound, uid: uid}
+		}
+		return nil
+	}
+
+	plugin, ok := pd.pluginStore.Plugin(ctx, ds.Type)
+	if !ok {
+		return &unsupportedDatasource{err: models.ErrPublicDashboardDatasourcePluginNotInstalled, uid: uid, datasourceType: ds.Type}
+	}
+	if !plugin.Backend {
+		return &unsupportedDatasource{err: models.ErrPublicDashboardDatasourceNotSupported, uid: uid, datasourceType: ds.Type}
+	}
+
+	return nil
+}
+
+// response builds the response of a query that was not run because the anonymous user cannot query its data source.
+// The reason is both a notice of the frame and structured custom metadata
+func (ds *unsupportedDatasource) response(refId string) backend.DataResponse {
+	frame := data.NewFrame("").SetMeta(&data.FrameMeta{
+		Notices: []data.Notice{{
+			Severity: data.NoticeSeverityWarning,
+			Text:     ds.err.Public(),
+		}},
+		Custom: models.UnsupportedDatasourceNotice{
+			Status:         ds.err.Status,
+			Reason:         ds.err.Public(),
+			DatasourceUid:  ds.uid,
+			DatasourceType: ds.datasourceType,
+		},
+	})
+	frame.RefID = refId
+
+	return backend.DataResponse{
+		Error:  ds.err,
+		Frames: data.Frames{frame},
+	}
+}
+
+// addUnsupportedResponses adds the responses of the queries that were not run to the response
+func addUnsupportedResponses(res *backend.QueryDataResponse, unsupported backend.Responses) {
+	for refId, dataResponse := range unsupported {
+		res.Responses[refId] = dataResponse
+	}
+}
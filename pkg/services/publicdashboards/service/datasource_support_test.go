@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestSplitUnsupportedQueries(t *testing.T) {
+	service := &PublicDashboardServiceImpl{
+		log: log.New("test.logger"),
+		dataSourceCache: &fakeDatasources.FakeCacheService{DataSources: []*datasources.DataSource{
+			{Uid: "prometheus", Type: "prometheus"},
+			{Uid: "uninstalled", Type: "uninstalled-datasource"},
+			{Uid: "frontend", Type: "frontend-datasource"},
+		}},
+		pluginStore: plugins.FakePluginStore{PluginList: []plugins.PluginDTO{
+			{JSONData: plugins.JSONData{ID: "prometheus", Backend: true}},
+			{JSONData: plugins.JSONData{ID: "frontend-datasource", Backend: false}},
+		}},
+	}
+
+	query := func(refId string, uid string) *simplejson.Json {
+		return simplejson.NewFromAny(map[string]interface{}{
+			"refId":      refId,
+			"datasource": map[string]interface{}{"uid": uid},
+		})
+	}
+
+	t.Run("keeps the queries of supported data sources", func(t *testing.T) {
+		metricReq := dtos.MetricRequest{Queries: []*simplejson.Json{query("A", "prometheus"), query("B", "__expr__"), query("C", "grafana")}}
+
+		supported, unsupported := service.splitUnsupportedQueries(context.Background(), &user.SignedInUser{}, false, metricReq)
+		assert.Len(t, supported.Queries, 3)
+		assert.Empty(t, unsupported)
+	})
+
+	t.Run("returns a structured notice per refId for unsupported data sources", func(t *testing.T) {
+		metricReq := dtos.MetricRequest{From: "now-1h", To: "now", Queries: []*simplejson.Json{
+			query("A", "prometheus"),
+			query("B", "deleted"),
+			query("C", "uninstalled"),
+			query("D", "frontend"),
+			query("E", "-- Dashboard --"),
+		}}
+
+		supported, unsupported := service.splitUnsupportedQueries(context.Background(), &user.SignedInUser{}, false, metricReq)
+		require.Len(t, supported.Queries, 1)
+		assert.Equal(t, "A", supported.Queries[0].Get("refId").MustString())
+		assert.Equal(t, "now-1h", supported.From)
+
+		expected := map[string]struct {
+			err            PublicDashboardErr
+			uid            string
+			datasourceType string
+		}{
+			"B": {ErrPublicDashboardDatasourceNotFound, "deleted", ""},
+			"C": {ErrPublicDashboardDatasourcePluginNotInstalled, "uninstalled", "uninstalled-datasource"},
+			"D": {ErrPublicDashboardDatasourceNotSupported, "frontend", "frontend-datasource"},
+			"E": {ErrPublicDashboardDatasourceNotSupported, "-- Dashboard --", ""},
+		}
+		require.Len(t, unsupported, len(expected))
+		for refId, e := range expected {
+			dataResponse := unsupported[refId]
+			assert.Equal(t, e.err, dataResponse.Error, refId)
+			require.Len(t, dataResponse.Frames, 1)
+			assert.Equal(t, refId, dataResponse.Frames[0].RefID)
+			require.Len(t, dataResponse.Frames[0].Meta.Notices, 1)
+			assert.Equal(t, e.err.Public(), dataResponse.Frames[0].Meta.Notices[0].Text)
+			assert.Equal(t, UnsupportedDatasourceNotice{
+				Status:         ErrStatusUnsupportedFeature,
+				Reason:         e.err.Public(),
+				DatasourceUid:  e.uid,
+				DatasourceType: e.datasourceType,
+			}, dataResponse.Frames[0].Meta.Custom)
+		}
+	})
+
+	t.Run("does not check data sources without a cache or plugin store", func(t *testing.T) {
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger")}
+		metricReq := dtos.MetricRequest{Queries: []*simplejson.Json{query("A", "deleted")}}
+
+		supported, unsupported := service.splitUnsupportedQueries(context.Background(), &user.SignedInUser{}, false, metricReq)
+		assert.Len(t, supported.Queries, 1)
+		assert.Empty(t, unsupported)
+	})
+}
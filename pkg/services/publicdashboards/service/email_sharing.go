@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const magicLinkEmailTemplate = "public_dashboard_magic_link"
+
+// RequestMagicLink emails a sign in link to a viewer of an email-gated public dashboard. Nothing is sent to
+// addresses missing from the allowlist, but no error is returned either so the allowlist cannot be probed
+func (pd *PublicDashboardServiceImpl) RequestMagicLink(ctx context.Context, accessToken string, email string) error {
+	ctxLogger := pd.log.FromContext(ctx)
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !util.IsEmail(email) {
+		return ErrPublicDashboardInvalidEmail
+	}
+
+	pubdash, dashboard, err := pd.FindPublicDashboardAndDashboardByAccessToken(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	if !pubdash.EmailGated {
+		return ErrPublicDashboardBadRequest
+	}
+
+	if !pubdash.EmailAllowlist.Allows(email) {
+		ctxLogger.Info("Magic link requested for an email address that is not allowed", "publicDashboardUid", pubdash.Uid)
+		return nil
+	}
+
+	code, err := util.GetRandomString(32)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	link := &PublicDashboardMagicLink{
+		TokenHash:          hashEmailToken(code),
+		PublicDashboardUid: pubdash.Uid,
+		Email:              email,
+		ExpiresAt:          now.Add(pd.emailMagicLinkLifetime),
+		CreatedAt:          now,
+	}
+	if err := pd.emailSessionStore.SaveMagicLink(ctx, link); err != nil {
+		return err
+	}
+
+	return pd.emailSender.SendEmailCommandHandler(ctx, &models.SendEmailCommand{
+		To:       []string{email},
+		Template: magicLinkEmailTemplate,
+		Data: map[string]interface{}{
+			"Title":        dashboard.Title,
+			"AccessToken":  accessToken,
+			"Code":         code,
+			"ValidMinutes": int64(pd.emailMagicLinkLifetime.Minutes()),
+		},
+	})
+}
+
+// VerifyMagicLink consumes a magic link of an email-gated public dashboard and starts a session for the viewer
+// it was sent to
+func (pd *PublicDashboardServiceImpl) VerifyMagicLink(ctx context.Context, accessToken string, code string) (*PublicDashboardSessionToken, error) {
+	pubdash, err := pd.findEmailGatedByAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if code == "" {
+		return nil, ErrPublicDashboardMagicLinkInvalid
+	}
+
+	link, err := pd.emailSessionStore.ConsumeMagicLink(ctx, hashEmailToken(code))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if link == nil || link.PublicDashboardUid != pubdash.Uid || now.After(link.ExpiresAt) || !pubdash.EmailAllowlist.Allows(link.Email) {
+		return nil, ErrPublicDashboardMagicLinkInvalid
+	}
+
+	token, err := util.GetRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &PublicDashboardSession{
+		TokenHash:          hashEmailToken(token),
+		PublicDashboardUid: pubdash.Uid,
+		Email:              link.Email,
+		ExpiresAt:          now.Add(pd.emailSessionLifetime),
+		CreatedAt:          now,
+	}
+	if err := pd.emailSessionStore.SaveSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	pd.log.FromContext(ctx).Info("Started public dashboard email session", "publicDashboardUid", pubdash.Uid, "email", link.Email)
+
+	return &PublicDashboardSessionToken{Token: token, Email: session.Email, ExpiresAt: session.ExpiresAt}, nil
+}
+
+// CheckEmailSession returns ErrPublicDashboardSessionRequired when the public dashboard of the access token is
+// email-gated and the session token does not give access to it. Sessions of addresses that have since been
+// removed from the allowlist are rejected
+func (pd *PublicDashboardServiceImpl) CheckEmailSession(ctx context.Context, accessToken string, sessionToken string) error {
+	pubdash, err := pd.store.FindByAccessToken(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	// missing and disabled public dashboards are reported by the handlers
+	if pubdash == nil || !pubdash.EmailGated {
+		return nil
+	}
+
+	if sessionToken == "" {
+		return ErrPublicDashboardSessionRequired
+	}
+
+	session, err := pd.emailSessionStore.FindSession(ctx, hashEmailToken(sessionToken))
+	if err != nil {
+		return err
+	}
+
+	if session == nil || session.PublicDashboardUid != pubdash.Uid || time.Now().After(session.ExpiresAt) || !pubdash.EmailAllowlist.Allows(session.Email) {
+		return ErrPublicDashboardSessionRequired
+	}
+
+	return nil
+}
+
+func (pd *PublicDashboardServiceImpl) findEmailGatedByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, error) {
+	pubdash, err := pd.store.FindByAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if pubdash == nil {
+		return nil, ErrPublicDashboardNotFound
+	}
+
+	if !pubdash.IsEnabled {
+		return nil, ErrPublicDashboardDisabled
+	}
+
+	if !pubdash.EmailGated {
+		return nil, ErrPublicDashboardBadRequest
+	}
+
+	if err := pd.checkCountryAccess(ctx, pubdash); err != nil {
+		return nil, err
+	}
+
+	return pubdash, nil
+}
+
+// hashEmailToken hashes magic link and session tokens, only the hashes are stored
+func hashEmailToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func newEmailSharingService(t *testing.T) (*PublicDashboardServiceImpl, *FakePublicDashboardStore, *FakePublicDashboardEmailSessionStore, *notifications.NotificationServiceMock) {
+	store := NewFakePublicDashboardStore(t)
+	emailSessionStore := NewFakePublicDashboardEmailSessionStore(t)
+	emailSender := notifications.MockNotificationService()
+
+	return &PublicDashboardServiceImpl{
+		log:                    log.New("test.logger"),
+		store:                  store,
+		emailSessionStore:      emailSessionStore,
+		emailSender:            emailSender,
+		emailMagicLinkLifetime: 15 * time.Minute,
+		emailSessionLifetime:   24 * time.Hour,
+	}, store, emailSessionStore, emailSender
+}
+
+func TestRequestMagicLink(t *testing.T) {
+	pubdash := &PublicDashboard{
+		Uid:            "pubdash1",
+		DashboardUid:   "dash1",
+		OrgId:          1,
+		AccessToken:    "token",
+		IsEnabled:      true,
+		EmailGated:     true,
+		EmailAllowlist: EmailAllowlist{"example.com"},
+	}
+
+	t.Run("emails a magic link to an allowed address", func(t *testing.T) {
+		service, store, emailSessionStore, emailSender := newEmailSharingService(t)
+
+		store.On("FindByAccessToken", mock.Anything, "token").Return(pubdash, nil)
+		store.On("FindDashboard", mock.Anything, "dash1", int64(1)).Return(&models.Dashboard{Uid: "dash1", OrgId: 1, Title: "status"}, nil)
+
+		var saved *PublicDashboardMagicLink
+		emailSessionStore.On("SaveMagicLink", mock.Anything, mock.AnythingOfType("*models.PublicDashboardMagicLink")).
+			Run(func(args mock.Arguments) { saved = args.Get(1).(*PublicDashboardMagicLink) }).
+			Return(nil)
+
+		err := service.RequestMagicLink(context.Background(), "token", " Viewer@Example.com ")
+		require.NoError(t, err)
+
+		require.NotNil(t, saved)
+		assert.Equal(t, "pubdash1", saved.PublicDashboardUid)
+		assert.Equal(t, "viewer@example.com", saved.Email)
+		assert.WithinDuration(t, time.Now().Add(15*time.Minute), saved.ExpiresAt, time.Minute)
+
+		assert.Equal(t, []string{"viewer@example.com"}, emailSender.Email.To)
+		assert.Equal(t, magicLinkEmailTemplate, emailSender.Email.Template)
+		assert.Equal(t, "status", emailSender.Email.Data["Title"])
+		assert.Equal(t, int64(15), emailSender.Email.Data["ValidMinutes"])
+
+		// only the hash of the code is stored
+		code := emailSender.Email.Data["Code"].(string)
+		assert.Equal(t, hashEmailToken(code), saved.TokenHash)
+		assert.NotEqual(t, code, saved.TokenHash)
+	})
+
+	t.Run("sends nothing to an address that is not allowed", func(t *testing.T) {
+		service, store, _, emailSender := newEmailSharingService(t)
+
+		store.On("FindByAccessToken", mock.Anything, "token").Return(pubdash, nil)
+		store.On("FindDashboard", mock.Anything, "dash1", int64(1)).Return(&models.Dashboard{Uid: "dash1", OrgId: 1}, nil)
+
+		err := service.RequestMagicLink(context.Background(), "token", "viewer@other.com")
+		require.NoError(t, err)
+		assert.Empty(t, emailSender.Email.To)
+	})
+
+	t.Run("returns ErrPublicDashboardInvalidEmail for an invalid address", func(t *testing.T) {
+		service, _, _, _ := newEmailSharingService(t)
+
+		err := service.RequestMagicLink(context.Background(), "token", "not an email")
+		require.ErrorIs(t, err, ErrPublicDashboardInvalidEmail)
+	})
+
+	t.Run("returns ErrPublicDashboardBadRequest when the public dashboard is not email-gated", func(t *testing.T) {
+		service, store, _, _ := newEmailSharingService(t)
+
+		store.On("FindByAccessToken", mock.Anything, "token").Return(&PublicDashboard{DashboardUid: "dash1", OrgId: 1, IsEnabled: true}, nil)
+		store.On("FindDashboard", mock.Anything, "dash1", int64(1)).Return(&models.Dashboard{Uid: "dash1", OrgId: 1}, nil)
+
+		err := service.RequestMagicLink(context.Background(), "token", "viewer@example.com")
+		require.ErrorIs(t, err, ErrPublicDashboardBadRequest)
+	})
+}
+
+func TestVerifyMagicLink(t *testing.T) {
+	pubdash := &PublicDashboard{
+		Uid:            "pubdash1",
+		AccessToken:    "token",
+		IsEnabled:      true,
+		EmailGated:     true,
+		EmailAllowlist: EmailAllowlist{"viewer@example.com"},
+	}
+
+	t.Run("starts a session for the address the magic link was sent to", func(t *testing.T) {
+		service, store, emailSessionStore, _ := newEmailSharingService(t)
+
+		store.On("FindByAccessToken", mock.Anything, "token").Return(pubdash, nil)
+		emailSessionStore.On("ConsumeMagicLink", mock.Anything, hashEmailToken("code")).Return(&PublicDashboardMagicLink{
+			PublicDashboardUid: "pubdash1",
+			Email:              "viewer@example.com",
+			ExpiresAt:          time.Now().Add(time.Minute),
+		}, nil)
+
+		var saved *PublicDashboardSession
+		emailSessionStore.On("SaveSession", mock.Anything, mock.AnythingOfType("*models.PublicDashboardSession")).
+			Run(func(args mock.Arguments) { saved = args.Get(1).(*PublicDashboardSession) }).
+			Return(nil)
+
+		session, err := service.VerifyMagicLink(context.Background(), "token", "code")
+		require.NoError(t, err)
+
+		assert.Equal(t, "viewer@example.com", session.Email)
+		assert.WithinDuration(t, time.Now().Add(24*time.Hour), session.ExpiresAt, time.Minute)
+		require.NotNil(t, saved)
+		assert.Equal(t, hashEmailToken(session.Token), saved.TokenHash)
+		assert.Equal(t, "pubdash1", saved.PublicDashboardUid)
+	})
+
+	testCases := []struct {
+		name string
+		link *PublicDashboardMagicLink
+	}{
+		{name: "unknown magic link", link: nil},
+		{name: "expired magic link", link: &PublicDashboardMagicLink{PublicDashboardUid: "pubdash1", Email: "viewer@example.com", ExpiresAt: time.Now().Add(-time.Minute)}},
+		{name: "magic link of another public dashboard", link: &PublicDashboardMagicLink{PublicDashboardUid: "pubdash2", Email: "viewer@example.com", ExpiresAt: time.Now().Add(time.Minute)}},
+		{name: "magic link of an address removed from the allowlist", link: &PublicDashboardMagicLink{PublicDashboardUid: "pubdash1", Email: "other@example.com", ExpiresAt: time.Now().Add(time.Minute)}},
+	}
+
+	for _, test := range testCases {
+		t.Run("returns ErrPublicDashboardMagicLinkInvalid for "+test.name, func(t *testing.T) {
+			service, store, emailSessionStore, _ := newEmailSharingService(t)
+
+			store.On("FindByAccessToken", mock.Anything, "token").Return(pubdash, nil)
+			emailSessionStore.On("ConsumeMagicLink", mock.Anything, hashEmailToken("code")).Return(test.link, nil)
+
+			_, err := service.VerifyMagicLink(context.Background(), "token", "code")
+			require.ErrorIs(t, err, ErrPublicDashboardMagicLinkInvalid)
+		})
+	}
+
+	t.Run("returns ErrPublicDashboardMagicLinkInvalid for an empty code", func(t *testing.T) {
+		service, store, _, _ := newEmailSharingService(t)
+
+		store.On("FindByAccessToken", mock.Anything, "token").Return(pubdash, nil)
+
+		_, err := service.VerifyMagicLink(context.Background(), "token", "")
+		require.ErrorIs(t, err, ErrPublicDashboardMagicLinkInvalid)
+	})
+
+	t.Run("returns ErrPublicDashboardDisabled when the public dashboard is disabled", func(t *testing.T) {
+		service, store, _, _ := newEmailSharingService(t)
+
+		store.On("FindByAccessToken", mock.Anything, "token").Return(&PublicDashboard{Uid: "pubdash1", EmailGated: true}, nil)
+
+		_, err := service.VerifyMagicLink(context.Background(), "token", "code")
+		require.ErrorIs(t, err, ErrPublicDashboardDisabled)
+	})
+}
+
+func TestCheckEmailSession(t *testing.T) {
+	pubdash := &PublicDashboard{
+		Uid:            "pubdash1",
+		AccessToken:    "token",
+		IsEnabled:      true,
+		EmailGated:     true,
+		EmailAllowlist: EmailAllowlist{"viewer@example.com"},
+	}
+
+	t.Run("lets public dashboards that are not email-gated through", func(t *testing.T) {
+		service, store, _, _ := newEmailSharingService(t)
+
+		store.On("FindByAccessToken", mock.Anything, "token").Return(&PublicDashboard{Uid: "pubdash1", IsEnabled: true}, nil)
+
+		require.NoError(t, service.CheckEmailSession(context.Background(), "token", ""))
+	})
+
+	t.Run("lets a valid session through", func(t *testing.T) {
+		service, store, emailSessionStore, _ := newEmailSharingService(t)
+
+		store.On("FindByAccessToken", mock.Anything, "token").Return(pubdash, nil)
+		emailSessionStore.On("FindSession", mock.Anything, hashEmailToken("session")).Return(&PublicDashboardSession{
+			PublicDashboardUid: "pubdash1",
+			Email:              "viewer@example.com",
+			ExpiresAt:          time.Now().Add(time.Hour),
+		}, nil)
+
+		require.NoError(t, service.CheckEmailSession(context.Background(), "token", "session"))
+	})
+
+	t.Run("returns ErrPublicDashboardSessionRequired without a session", func(t *testing.T) {
+		service, store, _, _ := newEmailSharingService(t)
+
+		store.On("FindByAccessToken", mock.Anything, "token").Return(pubdash, nil)
+
+		require.ErrorIs(t, service.CheckEmailSession(context.Background(), "token", ""), ErrPublicDashboardSessionRequired)
+	})
+
+	testCases := []struct {
+		name    string
+		session *PublicDashboardSession
+	}{
+		{name: "unknown session", session: nil},
+		{name: "expired session", session: &PublicDashboardSession{PublicDashboardUid: "pubdash1", Email: "viewer@example.com", ExpiresAt: time.Now().Add(-time.Minute)}},
+		{name: "session of another public dashboard", session: &PublicDashboardSession{PublicDashboardUid: "pubdash2", Email: "viewer@example.com", ExpiresAt: time.Now().Add(time.Hour)}},
+		{name: "session of an address removed from the allowlist", session: &PublicDashboardSession{PublicDashboardUid: "pubdash1", Email: "other@example.com", ExpiresAt: time.Now().Add(time.Hour)}},
+	}
+
+	for _, test := range testCases {
+		t.Run("returns ErrPublicDashboardSessionRequired for "+test.name, func(t *testing.T) {
+			service, store, emailSessionStore, _ := newEmailSharingService(t)
+
+			store.On("FindByAccessToken", mock.Anything, "token").Return(pubdash, nil)
+			emailSessionStore.On("FindSession", mock.Anything, hashEmailToken("session")).Return(test.session, nil)
+
+			require.ErrorIs(t, service.CheckEmailSession(context.Background(), "token", "session"), ErrPublicDashboardSessionRequired)
+		})
+	}
+}
+
+func TestSaveEmailGatedPublicDashboard(t *testing.T) {
+	dashboard := &models.Dashboard{Uid: "dash1", OrgId: 1}
+
+	t.Run("returns ErrPublicDashboardEmailNotConfigured when SMTP is disabled", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store, cfg: setting.NewCfg()}
+
+		store.On("FindDashboard", mock.Anything, "dash1", SignedInUser.OrgID).Return(dashboard, nil)
+
+		_, err := service.Save(context.Background(), SignedInUser, &SavePublicDashboardConfigDTO{
+			DashboardUid:    "dash1",
+			OrgId:           1,
+			PublicDashboard: &PublicDashboard{IsEnabled: true, EmailGated: true, EmailAllowlist: EmailAllowlist{"example.com"}},
+		})
+		require.ErrorIs(t, err, ErrPublicDashboardEmailNotConfigured)
+	})
+
+	t.Run("returns ErrPublicDashboardInvalidEmailAllowlist for an invalid allowlist", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		cfg := setting.NewCfg()
+		cfg.Smtp.Enabled = true
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store, cfg: cfg}
+
+		store.On("FindDashboard", mock.Anything, "dash1", SignedInUser.OrgID).Return(dashboard, nil)
+
+		_, err := service.Save(context.Background(), SignedInUser, &SavePublicDashboardConfigDTO{
+			DashboardUid:    "dash1",
+			OrgId:           1,
+			PublicDashboard: &PublicDashboard{IsEnabled: true, EmailGated: true, EmailAllowlist: EmailAllowlist{"not an email"}},
+		})
+		require.ErrorIs(t, err, ErrPublicDashboardInvalidEmailAllowlist)
+	})
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/org"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+// handleDashboardFolderChanged re-evaluates the sharing of a public dashboard when its dashboard is moved to another
+// folder. Public dashboards reference dashboards by uid, so their access tokens keep resolving after moves. Dashboards
+// without public dashboard are ignored
+func (pd *PublicDashboardServiceImpl) handleDashboardFolderChanged(ctx context.Context, e *events.DashboardFolderChanged) error {
+	pubdash, err := pd.store.FindByDashboardUid(ctx, e.OrgID, e.DashboardUID)
+	if errors.Is(err, ErrPublicDashboardNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return pd.revalidateFolderSharing(ctx, pubdash, e.NewFolderID)
+}
+
+// handleOrgUpdated re-evaluates the sharing of the public dashboards created by public folders when their
+// organization is updated. Public dashboards reference their organization by id and read its name when they are
+// listed, so renaming it leaves their rows and access tokens untouched, but the dashboards whose moves were not
+// handled are caught up. Every public dashboard is handled even when some of them fail, the first error is returned
+func (pd *PublicDashboardServiceImpl) handleOrgUpdated(ctx context.Context, e *events.OrgUpdated) error {
+	pubdashes, err := pd.store.FindSharedByFolders(ctx, e.Id)
+	if err != nil || len(pubdashes) == 0 {
+		return err
+	}
+
+	dashboardUids := make([]string, 0, len(pubdashes))
+	for _, pubdash := range pubdashes {
+		dashboardUids = append(dashboardUids, pubdash.DashboardUid)
+	}
+	dashes, err := pd.dashboardService.GetDashboardsByUIDs(ctx, &dashboards.GetDashboardsByUIDsQuery{OrgID: e.Id, UIDs: dashboardUids})
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, pubdash := range pubdashes {
+		// public dashboards of deleted dashboards are deleted with them
+		dash, ok := dashes.Dashboards[pubdash.DashboardUid]
+		if !ok {
+			continue
+		}
+
+		if err := pd.revalidateFolderSharing(ctx, pubdash, dash.FolderId); err != nil {
+			pd.log.FromContext(ctx).Error("Failed to re-evaluate the folder sharing of public dashboard", "publicDashboardUid", pubdash.Uid, "orgId", e.Id, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// revalidateFolderSharing disables a public dashboard created by a public folder once its dashboard, now in the
+// folder folderId, left the folder, so moving a dashboard out of a public folder stops sharing it
+func (pd *PublicDashboardServiceImpl) revalidateFolderSharing(ctx context.Context, pubdash *PublicDashboard, folderId int64) error {
+	if pubdash == nil || pubdash.SharedByFolderUid == "" || !pubdash.IsEnabled {
+		return nil
+	}
+
+	folder, err := pd.findFolder(ctx, pubdash.OrgId, pubdash.SharedByFolderUid)
+	if err != nil && !errors.Is(err, ErrPublicFolderFolderNotFound) {
+		return err
+	}
+	if folder != nil && folder.ID == folderId {
+		return nil
+	}
+
+	cmd := SavePublicDashboardConfigCommand{PublicDashboard: *pubdash}
+	cmd.PublicDashboard.IsEnabled = false
+	cmd.PublicDashboard.UpdatedAt = time.Now()
+	if err := pd.store.Update(ctx, cmd); err != nil {
+		return err
+	}
+
+	pd.log.FromContext(ctx).Info("Disabled public dashboard of a dashboard moved out of its public folder", "publicDashboardUid", pubdash.Uid, "dashboardUid", pubdash.DashboardUid, "folderUid", pubdash.SharedByFolderUid)
+
+	return nil
+}
+
+// handleUserDeleted applies the deleted creator policy of their organization to the public dashboards created by a
+// deleted user, so they are not left with an owner that no longer exists. Every public dashboard is handled even when
+// some of them fail, the first error is returned
+func (pd *PublicDashboardServiceImpl) handleUserDeleted(ctx context.Context, e *events.UserDeleted) error {
+	pubdashes, err := pd.store.FindByCreatedBy(ctx, e.Id)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, pubdash := range pubdashes {
+		policy, err := pd.applyDeletedCreatorPolicy(ctx, pubdash, e.Id)
+		if err != nil {
+			pd.log.FromContext(ctx).Error("Failed to apply the deleted creator policy to public dashboard", "publicDashboardUid", pubdash.Uid, "policy", policy, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		pd.log.FromContext(ctx).Info("Applied the deleted creator policy to public dashboard", "publicDashboardUid", pubdash.Uid, "dashboardUid", pubdash.DashboardUid, "orgId", pubdash.OrgId, "deletedUserId", e.Id, "policy", policy)
+	}
+
+	return firstErr
+}
+
+// handleUsersDeleted handles the users deleted in a batch like users deleted one by one. Every user is handled even
+// when some of them fail, the first error is returned
+func (pd *PublicDashboardServiceImpl) handleUsersDeleted(ctx context.Context, e *events.UsersDeleted) error {
+	var firstErr error
+	for _, id := range e.Ids {
+		if err := pd.handleUserDeleted(ctx, &events.UserDeleted{Timestamp: e.Timestamp, Id: id}); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// applyDeletedCreatorPolicy applies the deleted creator policy of its organization to a public dashboard and returns
+// the policy applied. Public dashboards are flagged when they can't be reassigned to an admin of their organization
+func (pd *PublicDashboardServiceImpl) applyDeletedCreatorPolicy(ctx context.Context, pubdash *PublicDashboard, deletedUserId int64) (string, error) {
+	policy := pd.deletedCreatorPolicy(ctx, pubdash.OrgId)
+
+	switch policy {
+	case DeletedCreatorPolicyReassign:
+		adminId, err := pd.findOrgAdmin(ctx, pubdash.OrgId, deletedUserId)
+		if err != nil {
+			return policy, err
+		}
+		if adminId == 0 {
+			return DeletedCreatorPolicyFlag, pd.store.FlagCreatorDeleted(ctx, pubdash.Uid)
+		}
+
+		return policy, pd.store.UpdateOwner(ctx, TransferPublicDashboardOwnershipCommand{
+			Uid:       pubdash.Uid,
+			CreatedBy: adminId,
+			UpdatedBy: pubdash.UpdatedBy,
+			UpdatedAt: time.Now(),
+		})
+	case DeletedCreatorPolicyDisable:
+		cmd := SavePublicDashboardConfigCommand{PublicDashboard: *pubdash}
+		cmd.PublicDashboard.IsEnabled = false
+		cmd.PublicDashboard.UpdatedAt = time.Now()
+		if err := pd.store.Update(ctx, cmd); err != nil {
+			return policy, err
+		}
+
+		return policy, pd.store.FlagCreatorDeleted(ctx, pubdash.Uid)
+	case DeletedCreatorPolicyDelete:
+		return policy, pd.store.Delete(ctx, pubdash.Uid)
+	default:
+		return DeletedCreatorPolicyFlag, pd.store.FlagCreatorDeleted(ctx, pubdash.Uid)
+	}
+}
+
+// deletedCreatorPolicy returns the deleted creator policy of an organization. Unknown policies fall back to flagging
+// public dashboards, the safest option
+func (pd *PublicDashboardServiceImpl) deletedCreatorPolicy(ctx context.Context, orgId int64) string {
+	if pd.cfg == nil {
+		return DeletedCreatorPolicyFlag
+	}
+
+	policy, ok := pd.cfg.PublicDashboards.DeletedCreatorOrgPolicies[orgId]
+	if !ok {
+		policy = pd.cfg.PublicDashboards.DeletedCreatorPolicy
+	}
+
+	switch policy {
+	case DeletedCreatorPolicyFlag, DeletedCreatorPolicyReassign, DeletedCreatorPolicyDisable, DeletedCreatorPolicyDelete:
+		return policy
+	default:
+		pd.log.FromContext(ctx).Warn("Unknown public dashboards deleted creator policy, flagging public dashboards instead", "orgId", orgId, "policy", policy)
+		return DeletedCreatorPolicyFlag
+	}
+}
+
+// findOrgAdmin returns the id of the admin of the organization who joined Grafana first, ignoring disabled users and
+// the deleted user. 0 is returned when the organization has no other admin
+func (pd *PublicDashboardServiceImpl) findOrgAdmin(ctx context.Context, orgId int64, deletedUserId int64) (int64, error) {
+	orgUsers, err := pd.orgService.GetOrgUsers(ctx, &org.GetOrgUsersQuery{OrgID: orgId, DontEnforceAccessControl: true})
+	if err != nil {
+		return 0, err
+	}
+
+	var adminId int64
+	for _, orgUser := range orgUsers {
+		if orgUser.Role != string(org.RoleAdmin) || orgUser.IsDisabled || orgUser.UserID == deletedUserId {
+			continue
+		}
+		if adminId == 0 || orgUser.UserID < adminId {
+			adminId = orgUser.UserID
+		}
+	}
+
+	return adminId, nil
+}
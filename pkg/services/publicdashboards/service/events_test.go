@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestHandleDashboardFolderChanged(t *testing.T) {
+	folder := &dashboards.DashboardMeta{ID: 10, UID: "folder1", OrgID: 1, IsFolder: true}
+	movedOut := &events.DashboardFolderChanged{OrgID: 1, DashboardUID: "dash1", OldFolderID: 10, NewFolderID: 20}
+
+	newService := func(t *testing.T) (*PublicDashboardServiceImpl, *FakePublicDashboardStore, *dashboards.FakeDashboardService) {
+		store := NewFakePublicDashboardStore(t)
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		return &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store, dashboardService: dashboardService}, store, dashboardService
+	}
+
+	t.Run("disables the public dashboard created by a public folder when its dashboard leaves the folder", func(t *testing.T) {
+		service, store, dashboardService := newService(t)
+		pubdash := &PublicDashboard{Uid: "pubdash1", DashboardUid: "dash1", OrgId: 1, IsEnabled: true, AccessToken: "token", SharedByFolderUid: "folder1"}
+		store.On("FindByDashboardUid", mock.Anything, int64(1), "dash1").Return(pubdash, nil)
+		dashboardService.On("GetDashboardMeta", mock.Anything, int64(1), "folder1").Return(folder, nil)
+		store.On("Update", mock.Anything, mock.MatchedBy(func(cmd SavePublicDashboardConfigCommand) bool {
+			return cmd.PublicDashboard.Uid == "pubdash1" && !cmd.PublicDashboard.IsEnabled && cmd.PublicDashboard.AccessToken == "token" &&
+				cmd.PublicDashboard.SharedByFolderUid == "folder1"
+		})).Return(nil)
+
+		require.NoError(t, service.handleDashboardFolderChanged(context.Background(), movedOut))
+	})
+
+	t.Run("disables the public dashboard when the public folder's folder no longer exists", func(t *testing.T) {
+		service, store, dashboardService := newService(t)
+		pubdash := &PublicDashboard{Uid: "pubdash1", DashboardUid: "dash1", OrgId: 1, IsEnabled: true, SharedByFolderUid: "folder1"}
+		store.On("FindByDashboardUid", mock.Anything, int64(1), "dash1").Return(pubdash, nil)
+		dashboardService.On("GetDashboardMeta", mock.Anything, int64(1), "folder1").Return(nil, dashboards.ErrDashboardNotFound)
+		store.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+		require.NoError(t, service.handleDashboardFolderChanged(context.Background(), movedOut))
+	})
+
+	t.Run("keeps sharing the dashboard when it is moved back into its public folder", func(t *testing.T) {
+		service, store, dashboardService := newService(t)
+		pubdash := &PublicDashboard{Uid: "pubdash1", DashboardUid: "dash1", OrgId: 1, IsEnabled: true, SharedByFolderUid: "folder1"}
+		store.On("FindByDashboardUid", mock.Anything, int64(1), "dash1").Return(pubdash, nil)
+		dashboardService.On("GetDashboardMeta", mock.Anything, int64(1), "folder1").Return(folder, nil)
+
+		require.NoError(t, service.handleDashboardFolderChanged(context.Background(), &events.DashboardFolderChanged{OrgID: 1, DashboardUID: "dash1", OldFolderID: 0, NewFolderID: 10}))
+		store.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("leaves public dashboards shared by their owner untouched", func(t *testing.T) {
+		service, store, _ := newService(t)
+		store.On("FindByDashboardUid", mock.Anything, int64(1), "dash1").Return(&PublicDashboard{Uid: "pubdash1", IsEnabled: true}, nil)
+
+		require.NoError(t, service.handleDashboardFolderChanged(context.Background(), movedOut))
+		store.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ignores dashboards without public dashboard", func(t *testing.T) {
+		service, store, _ := newService(t)
+		store.On("FindByDashboardUid", mock.Anything, int64(1), "dash1").Return(nil, ErrPublicDashboardNotFound)
+
+		require.NoError(t, service.handleDashboardFolderChanged(context.Background(), movedOut))
+	})
+
+	t.Run("returns the errors of finding the public dashboard", func(t *testing.T) {
+		service, store, _ := newService(t)
+		store.On("FindByDashboardUid", mock.Anything, int64(1), "dash1").Return(nil, errors.New("db down"))
+
+		require.Error(t, service.handleDashboardFolderChanged(context.Background(), movedOut))
+	})
+}
+
+func TestHandleOrgUpdated(t *testing.T) {
+	folder := &dashboards.DashboardMeta{ID: 10, UID: "folder1", OrgID: 1, IsFolder: true}
+	updated := &events.OrgUpdated{Id: 1, Name: "renamed org"}
+
+	newService := func(t *testing.T) (*PublicDashboardServiceImpl, *FakePublicDashboardStore, *dashboards.FakeDashboardService) {
+		store := NewFakePublicDashboardStore(t)
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		return &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store, dashboardService: dashboardService}, store, dashboardService
+	}
+
+	t.Run("disables the public dashboards whose dashboard left their public folder", func(t *testing.T) {
+		service, store, dashboardService := newService(t)
+		store.On("FindSharedByFolders", mock.Anything, int64(1)).Return([]*PublicDashboard{
+			{Uid: "inFolder", DashboardUid: "dash1", OrgId: 1, IsEnabled: true, SharedByFolderUid: "folder1"},
+			{Uid: "movedOut", DashboardUid: "dash2", OrgId: 1, IsEnabled: true, SharedByFolderUid: "folder1"},
+			{Uid: "deleted", DashboardUid: "dash3", OrgId: 1, IsEnabled: true, SharedByFolderUid: "folder1"},
+		}, nil)
+		dashboardService.On("GetDashboardsByUIDs", mock.Anything, &dashboards.GetDashboardsByUIDsQuery{OrgID: 1, UIDs: []string{"dash1", "dash2", "dash3"}}).
+			Return(&dashboards.GetDashboardsByUIDsResult{Dashboards: map[string]*models.Dashboard{
+				"dash1": {Uid: "dash1", FolderId: 10},
+				"dash2": {Uid: "dash2", FolderId: 20},
+			}}, nil)
+		dashboardService.On("GetDashboardMeta", mock.Anything, int64(1), "folder1").Return(folder, nil)
+		store.On("Update", mock.Anything, mock.MatchedBy(func(cmd SavePublicDashboardConfigCommand) bool {
+			return cmd.PublicDashboard.Uid == "movedOut" && !cmd.PublicDashboard.IsEnabled
+		})).Return(nil).Once()
+
+		require.NoError(t, service.handleOrgUpdated(context.Background(), updated))
+	})
+
+	t.Run("leaves organizations without public dashboards shared by a folder untouched", func(t *testing.T) {
+		service, store, dashboardService := newService(t)
+		store.On("FindSharedByFolders", mock.Anything, int64(1)).Return([]*PublicDashboard{}, nil)
+
+		require.NoError(t, service.handleOrgUpdated(context.Background(), updated))
+		dashboardService.AssertNotCalled(t, "GetDashboardsByUIDs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("handles every public dashboard when some of them fail", func(t *testing.T) {
+		service, store, dashboardService := newService(t)
+		store.On("FindSharedByFolders", mock.Anything, int64(1)).Return([]*PublicDashboard{
+			{Uid: "pubdash1", DashboardUid: "dash1", OrgId: 1, IsEnabled: true, SharedByFolderUid: "folder1"},
+			{Uid: "pubdash2", DashboardUid: "dash2", OrgId: 1, IsEnabled: true, SharedByFolderUid: "folder1"},
+		}, nil)
+		dashboardService.On("GetDashboardsByUIDs", mock.Anything, mock.Anything).
+			Return(&dashboards.GetDashboardsByUIDsResult{Dashboards: map[string]*models.Dashboard{
+				"dash1": {Uid: "dash1", FolderId: 20},
+				"dash2": {Uid: "dash2", FolderId: 20},
+			}}, nil)
+		dashboardService.On("GetDashboardMeta", mock.Anything, int64(1), "folder1").Return(folder, nil)
+		store.On("Update", mock.Anything, mock.Anything).Return(errors.New("db down")).Twice()
+
+		require.Error(t, service.handleOrgUpdated(context.Background(), updated))
+	})
+}
+
+func TestHandleUserDeleted(t *testing.T) {
+	newService := func(t *testing.T, policy string, orgPolicies map[int64]string) (*PublicDashboardServiceImpl, *FakePublicDashboardStore, *orgtest.FakeOrgService) {
+		cfg := setting.NewCfg()
+		cfg.PublicDashboards.DeletedCreatorPolicy = policy
+		cfg.PublicDashboards.DeletedCreatorOrgPolicies = orgPolicies
+		store := NewFakePublicDashboardStore(t)
+		orgService := orgtest.NewOrgServiceFake()
+		return &PublicDashboardServiceImpl{log: log.New("test.logger"), cfg: cfg, store: store, orgService: orgService}, store, orgService
+	}
+	deleted := &events.UserDeleted{Id: 7}
+	pubdash := &PublicDashboard{Uid: "pubdash1", DashboardUid: "dash1", OrgId: 1, IsEnabled: true, AccessToken: "token", CreatedBy: 7}
+
+	t.Run("flags the public dashboards of the deleted user", func(t *testing.T) {
+		service, store, _ := newService(t, DeletedCreatorPolicyFlag, nil)
+		store.On("FindByCreatedBy", mock.Anything, int64(7)).Return([]*PublicDashboard{pubdash}, nil)
+		store.On("FlagCreatorDeleted", mock.Anything, "pubdash1").Return(nil)
+
+		require.NoError(t, service.handleUserDeleted(context.Background(), deleted))
+	})
+
+	t.Run("reassigns the public dashboards to the first admin of the organization", func(t *testing.T) {
+		service, store, orgService := newService(t, DeletedCreatorPolicyReassign, nil)
+		orgService.ExpectedOrgUsers = []*org.OrgUserDTO{
+			{UserID: 7, Role: string(org.RoleAdmin)},
+			{UserID: 2, Role: string(org.RoleEditor)},
+			{UserID: 3, Role: string(org.RoleAdmin), IsDisabled: true},
+			{UserID: 9, Role: string(org.RoleAdmin)},
+			{UserID: 5, Role: string(org.RoleAdmin)},
+		}
+		store.On("FindByCreatedBy", mock.Anything, int64(7)).Return([]*PublicDashboard{pubdash}, nil)
+		store.On("UpdateOwner", mock.Anything, mock.MatchedBy(func(cmd TransferPublicDashboardOwnershipCommand) bool {
+			return cmd.Uid == "pubdash1" && cmd.CreatedBy == 5
+		})).Return(nil)
+
+		require.NoError(t, service.handleUserDeleted(context.Background(), deleted))
+	})
+
+	t.Run("flags the public dashboards when the organization has no other admin", func(t *testing.T) {
+		service, store, orgService := newService(t, DeletedCreatorPolicyReassign, nil)
+		orgService.ExpectedOrgUsers = []*org.OrgUserDTO{{UserID: 7, Role: string(org.RoleAdmin)}}
+		store.On("FindByCreatedBy", mock.Anything, int64(7)).Return([]*PublicDashboard{pubdash}, nil)
+		store.On("FlagCreatorDeleted", mock.Anything, "pubdash1").Return(nil)
+
+		require.NoError(t, service.handleUserDeleted(context.Background(), deleted))
+		store.AssertNotCalled(t, "UpdateOwner", mock.Anything, mock.Anything)
+	})
+
+	t.Run("disables and flags the public dashboards", func(t *testing.T) {
+		service, store, _ := newService(t, DeletedCreatorPolicyDisable, nil)
+		store.On("FindByCreatedBy", mock.Anything, int64(7)).Return([]*PublicDashboard{pubdash}, nil)
+		store.On("Update", mock.Anything, mock.MatchedBy(func(cmd SavePublicDashboardConfigCommand) bool {
+			return cmd.PublicDashboard.Uid == "pubdash1" && !cmd.PublicDashboard.IsEnabled && cmd.PublicDashboard.AccessToken == "token"
+		})).Return(nil)
+		store.On("FlagCreatorDeleted", mock.Anything, "pubdash1").Return(nil)
+
+		require.NoError(t, service.handleUserDeleted(context.Background(), deleted))
+	})
+
+	t.Run("applies the policy of the organization of each public dashboard", func(t *testing.T) {
+		service, store, _ := newService(t, DeletedCreatorPolicyFlag, map[int64]string{2: DeletedCreatorPolicyDelete})
+		store.On("FindByCreatedBy", mock.Anything, int64(7)).Return([]*PublicDashboard{pubdash, {Uid: "pubdash2", OrgId: 2, CreatedBy: 7}}, nil)
+		store.On("FlagCreatorDeleted", mock.Anything, "pubdash1").Return(nil)
+		store.On("Delete", mock.Anything, "pubdash2").Return(nil)
+
+		require.NoError(t, service.handleUserDeleted(context.Background(), deleted))
+	})
+
+	t.Run("flags the public dashboards when the policy is unknown", func(t *testing.T) {
+		service, store, _ := newService(t, "archive", nil)
+		store.On("FindByCreatedBy", mock.Anything, int64(7)).Return([]*PublicDashboard{pubdash}, nil)
+		store.On("FlagCreatorDeleted", mock.Anything, "pubdash1").Return(nil)
+
+		require.NoError(t, service.handleUserDeleted(context.Background(), deleted))
+	})
+
+	t.Run("handles every public dashboard and returns the first error", func(t *testing.T) {
+		service, store, _ := newService(t, DeletedCreatorPolicyDelete, nil)
+		store.On("FindByCreatedBy", mock.Anything, int64(7)).Return([]*PublicDashboard{pubdash, {Uid: "pubdash2", OrgId: 1, CreatedBy: 7}}, nil)
+		store.On("Delete", mock.Anything, "pubdash1").Return(errors.New("db error"))
+		store.On("Delete", mock.Anything, "pubdash2").Return(nil)
+
+		require.EqualError(t, service.handleUserDeleted(context.Background(), deleted), "db error")
+		store.AssertCalled(t, "Delete", mock.Anything, "pubdash2")
+	})
+
+	t.Run("handles the public dashboards of every user deleted in a batch", func(t *testing.T) {
+		service, store, _ := newService(t, DeletedCreatorPolicyFlag, nil)
+		store.On("FindByCreatedBy", mock.Anything, int64(7)).Return(nil, errors.New("db error"))
+		store.On("FindByCreatedBy", mock.Anything, int64(8)).Return([]*PublicDashboard{{Uid: "pubdash2", OrgId: 1, CreatedBy: 8}}, nil)
+		store.On("FlagCreatorDeleted", mock.Anything, "pubdash2").Return(nil)
+
+		require.EqualError(t, service.handleUsersDeleted(context.Background(), &events.UsersDeleted{Ids: []int64{7, 8}}), "db error")
+		store.AssertCalled(t, "FlagCreatorDeleted", mock.Anything, "pubdash2")
+	})
+}
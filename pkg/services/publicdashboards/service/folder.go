@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/internal/tokens"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// FindPublicFolder Returns the public folder configuration of a folder
+func (pd *PublicDashboardServiceImpl) FindPublicFolder(ctx context.Context, orgId int64, folderUid string) (*PublicFolder, error) {
+	folder, err := pd.folderStore.Find(ctx, orgId, folderUid)
+	if err != nil {
+		return nil, err
+	}
+
+	if folder == nil {
+		return nil, ErrPublicFolderNotFound
+	}
+
+	return folder, nil
+}
+
+// SavePublicFolder creates the public folder configuration of a folder, or updates it when it already exists. The
+// public dashboards of the dashboards of the folder are created, disabled or re-enabled along with it
+func (pd *PublicDashboardServiceImpl) SavePublicFolder(ctx context.Context, u *user.SignedInUser, dto *SavePublicFolderDTO) (*PublicFolder, error) {
+	if _, err := pd.findFolder(ctx, dto.OrgId, dto.FolderUid); err != nil {
+		return nil, err
+	}
+
+	existing, err := pd.folderStore.Find(ctx, dto.OrgId, dto.FolderUid)
+	if err != nil {
+		return nil, err
+	}
+
+	folder := dto.PublicFolder
+	folder.FolderUid = dto.FolderUid
+	folder.OrgId = dto.OrgId
+	now := time.Now()
+
+	wasEnabled := existing != nil && existing.IsEnabled
+	if existing == nil {
+		accessToken, err := tokens.GenerateAccessToken()
+		if err != nil {
+			return nil, ErrPublicDashboardFailedGenerateAccessToken
+		}
+
+		folder.Uid = util.GenerateShortUID()
+		folder.AccessToken = accessToken
+		folder.CreatedBy = dto.UserId
+		folder.CreatedAt = now
+		if err := pd.folderStore.Save(ctx, folder); err != nil {
+			return nil, err
+		}
+	} else {
+		folder.Uid = existing.Uid
+		folder.UpdatedBy = dto.UserId
+		folder.UpdatedAt = now
+		if err := pd.folderStore.Update(ctx, folder); err != nil {
+			return nil, err
+		}
+
+		if existing.IsEnabled != folder.IsEnabled {
+			pd.log.Info("Public folder 'isEnabled' changed", "folderUid", folder.FolderUid, "isEnabled", folder.IsEnabled, "updatedBy", u.UserID)
+		}
+	}
+
+	if err := pd.syncFolderPublicDashboards(ctx, dto.UserId, folder, wasEnabled); err != nil {
+		return nil, err
+	}
+
+	return pd.FindPublicFolder(ctx, dto.OrgId, dto.FolderUid)
+}
+
+// GetPublicFolderViewerPayload Returns the dashboards of a public folder with the access tokens to view them.
+// Dashboards without a public dashboard, and dashboards whose public dashboard has been disabled or is restricted in
+// the country of the viewer are left out
+func (pd *PublicDashboardServiceImpl) GetPublicFolderViewerPayload(ctx context.Context, accessToken string) (*PublicFolderViewerPayload, error) {
+	publicFolder, err := pd.folderStore.FindByAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if publicFolder == nil {
+		return nil, ErrPublicFolderNotFound
+	}
+
+	if !publicFolder.IsEnabled {
+		return nil, ErrPublicFolderDisabled
+	}
+
+	folder, err := pd.findFolder(ctx, publicFolder.OrgId, publicFolder.FolderUid)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboards, err := pd.folderStore.FindDashboards(ctx, publicFolder.OrgId, folder.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboardUids := make([]string, 0, len(dashboards))
+	for _, dashboard := range dashboards {
+		dashboardUids = append(dashboardUids, dashboard.Uid)
+	}
+	pubdashes, err := pd.store.FindByDashboardUids(ctx, publicFolder.OrgId, dashboardUids)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &PublicFolderViewerPayload{
+		Title:      folder.Title,
+		Dashboards: make([]PublicFolderViewerPayloadDashboard, 0, len(dashboards)),
+	}
+	for _, dashboard := range dashboards {
+		pubdash, ok := pubdashes[dashboard.Uid]
+		if !ok || !pubdash.IsEnabled || pd.checkCountryAccess(ctx, pubdash) != nil {
+			continue
+		}
+
+		payload.Dashboards = append(payload.Dashboards, PublicFolderViewerPayloadDashboard{
+			AccessToken: pubdash.AccessToken,
+			Title:       dashboard.Title,
+		})
+	}
+
+	return payload, nil
+}
+
+// findFolder Returns the metadata of the folder of the given uid, or ErrPublicFolderFolderNotFound when the uid does
+// not belong to a folder of the org. The JSON data of the folder is not loaded
+func (pd *PublicDashboardServiceImpl) findFolder(ctx context.Context, orgId int64, folderUid string) (*dashboards.DashboardMeta, error) {
+	folder, err := pd.dashboardService.GetDashboardMeta(ctx, orgId, folderUid)
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) || errors.Is(err, dashboards.ErrDashboardIdentifierNotSet) {
+			return nil, ErrPublicFolderFolderNotFound
+		}
+		return nil, err
+	}
+
+	if folder == nil || !folder.IsFolder {
+		return nil, ErrPublicFolderFolderNotFound
+	}
+
+	return folder, nil
+}
+
+// syncFolderPublicDashboards keeps the public dashboards of the dashboards of a public folder in line with it after
+// it is saved. Sharing a folder creates the missing public dashboards of its dashboards, and enabling it again
+// re-enables the public dashboards it created. Disabling it disables them
+func (pd *PublicDashboardServiceImpl) syncFolderPublicDashboards(ctx context.Context, userId int64, publicFolder *PublicFolder, wasEnabled bool) error {
+	if !publicFolder.IsEnabled && !wasEnabled {
+		return nil
+	}
+
+	folder, err := pd.findFolder(ctx, publicFolder.OrgId, publicFolder.FolderUid)
+	if err != nil {
+		return err
+	}
+
+	dashboards, err := pd.folderStore.FindDashboards(ctx, publicFolder.OrgId, folder.ID)
+	if err != nil {
+		return err
+	}
+
+	dashboardUids := make([]string, 0, len(dashboards))
+	for _, dashboard := range dashboards {
+		dashboardUids = append(dashboardUids, dashboard.Uid)
+	}
+	pubdashes, err := pd.store.FindByDashboardUids(ctx, publicFolder.OrgId, dashboardUids)
+	if err != nil {
+		return err
+	}
+
+	for _, dashboard := range dashboards {
+		pubdash, ok := pubdashes[dashboard.Uid]
+		if !ok {
+			if publicFolder.IsEnabled {
+				if err := pd.createFolderPublicDashboard(ctx, userId, publicFolder, dashboard); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		// public dashboards shared on their own, or by another folder, are left untouched
+		if pubdash.SharedByFolderUid != publicFolder.FolderUid || pubdash.IsEnabled == publicFolder.IsEnabled || publicFolder.IsEnabled == wasEnabled {
+			continue
+		}
+
+		cmd := SavePublicDashboardConfigCommand{PublicDashboard: *pubdash}
+		cmd.PublicDashboard.IsEnabled = publicFolder.IsEnabled
+		cmd.PublicDashboard.UpdatedBy = userId
+		cmd.PublicDashboard.UpdatedAt = time.Now()
+		if err := pd.store.Update(ctx, cmd); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createFolderPublicDashboard creates an enabled public dashboard for a dashboard of a public folder, on behalf of
+// the user sharing the folder. Dashboards that cannot be shared publicly are skipped
+func (pd *PublicDashboardServiceImpl) createFolderPublicDashboard(ctx context.Context, userId int64, publicFolder *PublicFolder, dashboard *models.Dashboard) error {
+	dto := &SavePublicDashboardConfigDTO{
+		DashboardUid: dashboard.Uid,
+		OrgId:        publicFolder.OrgId,
+		UserId:       userId,
+		PublicDashboard: &PublicDashboard{
+			IsEnabled:    true,
+			TimeSettings: &TimeSettings{},
+		},
+	}
+
+	if validation.ValidateSavePublicDashboard(dto, dashboard) != nil {
+		return nil
+	}
+
+	cmd, err := pd.newSavePublicDashboardCommand(ctx, dto, publicFolder.FolderUid)
+	if err != nil {
+		return err
+	}
+
+	if err := pd.store.Save(ctx, cmd); err != nil {
+		return err
+	}
+
+	pd.log.FromContext(ctx).Info("Created public dashboard for public folder", "folderUid", publicFolder.FolderUid, "dashboardUid", dashboard.Uid)
+
+	return nil
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+// newFolderDashboard returns a dashboard of the folder with id 10, with the given template variables
+func newFolderDashboard(uid string, title string, templateVariables ...interface{}) *models.Dashboard {
+	data := simplejson.New()
+	data.SetPath([]string{"templating", "list"}, templateVariables)
+	return &models.Dashboard{Uid: uid, OrgId: 1, FolderId: 10, Title: title, Data: data}
+}
+
+func TestSavePublicFolder(t *testing.T) {
+	folder := &dashboards.DashboardMeta{ID: 10, UID: "folder1", OrgID: 1, Title: "status", IsFolder: true}
+
+	t.Run("creates the public folder config of a folder and the public dashboards of its dashboards", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		folderStore := NewFakePublicFolderStore(t)
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store, folderStore: folderStore, dashboardService: dashboardService}
+
+		dashboardService.On("GetDashboardMeta", mock.Anything, int64(1), "folder1").Return(folder, nil)
+
+		var saved *PublicFolder
+		folderStore.On("Find", mock.Anything, int64(1), "folder1").
+			Return(func(_ context.Context, _ int64, _ string) *PublicFolder { return saved }, nil)
+		folderStore.On("Save", mock.Anything, mock.AnythingOfType("*models.PublicFolder")).
+			Run(func(args mock.Arguments) { saved = args.Get(1).(*PublicFolder) }).
+			Return(nil)
+
+		folderStore.On("FindDashboards", mock.Anything, int64(1), int64(10)).Return([]*models.Dashboard{
+			newFolderDashboard("shared", "a"),
+			newFolderDashboard("new", "b"),
+			newFolderDashboard("variables", "c", map[string]interface{}{"name": "host"}),
+		}, nil)
+		store.On("FindByDashboardUids", mock.Anything, int64(1), []string{"shared", "new", "variables"}).Return(map[string]*PublicDashboard{
+			"shared": {Uid: "pubdash1", DashboardUid: "shared", IsEnabled: true},
+		}, nil)
+
+		// dashboards with template variables can't be shared publicly
+		store.On("Find", mock.Anything, mock.AnythingOfType("string")).Return(nil, nil)
+		store.On("FindByAccessToken", mock.Anything, mock.AnythingOfType("string")).Return(nil, nil)
+		store.On("Save", mock.Anything, mock.MatchedBy(func(cmd SavePublicDashboardConfigCommand) bool {
+			return cmd.PublicDashboard.DashboardUid == "new" && cmd.PublicDashboard.IsEnabled && cmd.PublicDashboard.CreatedBy == 7 &&
+				cmd.PublicDashboard.SharedByFolderUid == "folder1"
+		})).Return(nil).Once()
+
+		publicFolder, err := service.SavePublicFolder(context.Background(), SignedInUser, &SavePublicFolderDTO{
+			FolderUid:    "folder1",
+			OrgId:        1,
+			UserId:       7,
+			PublicFolder: &PublicFolder{IsEnabled: true},
+		})
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, publicFolder.Uid)
+		assert.Len(t, publicFolder.AccessToken, 32)
+		assert.Equal(t, "folder1", publicFolder.FolderUid)
+		assert.Equal(t, int64(7), publicFolder.CreatedBy)
+		assert.True(t, publicFolder.IsEnabled)
+	})
+
+	t.Run("disabling a public folder disables the public dashboards it created", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		folderStore := NewFakePublicFolderStore(t)
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store, folderStore: folderStore, dashboardService: dashboardService}
+
+		dashboardService.On("GetDashboardMeta", mock.Anything, int64(1), "folder1").Return(folder, nil)
+		folderStore.On("Find", mock.Anything, int64(1), "folder1").
+			Return(&PublicFolder{Uid: "pubfolder1", FolderUid: "folder1", OrgId: 1, IsEnabled: true}, nil)
+		folderStore.On("Update", mock.Anything, mock.MatchedBy(func(f *PublicFolder) bool {
+			return f.Uid == "pubfolder1" && !f.IsEnabled && f.UpdatedBy == 7
+		})).Return(nil)
+
+		folderStore.On("FindDashboards", mock.Anything, int64(1), int64(10)).Return([]*models.Dashboard{
+			newFolderDashboard("byFolder", "a"),
+			newFolderDashboard("onItsOwn", "b"),
+		}, nil)
+		store.On("FindByDashboardUids", mock.Anything, int64(1), []string{"byFolder", "onItsOwn"}).Return(map[string]*PublicDashboard{
+			"byFolder": {Uid: "pubdash1", DashboardUid: "byFolder", IsEnabled: true, SharedByFolderUid: "folder1"},
+			"onItsOwn": {Uid: "pubdash2", DashboardUid: "onItsOwn", IsEnabled: true},
+		}, nil)
+		store.On("Update", mock.Anything, mock.MatchedBy(func(cmd SavePublicDashboardConfigCommand) bool {
+			return cmd.PublicDashboard.Uid == "pubdash1" && !cmd.PublicDashboard.IsEnabled && cmd.PublicDashboard.UpdatedBy == 7
+		})).Return(nil).Once()
+
+		_, err := service.SavePublicFolder(context.Background(), SignedInUser, &SavePublicFolderDTO{
+			FolderUid:    "folder1",
+			OrgId:        1,
+			UserId:       7,
+			PublicFolder: &PublicFolder{IsEnabled: false},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("enabling a public folder again re-enables the public dashboards it created", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		folderStore := NewFakePublicFolderStore(t)
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store, folderStore: folderStore, dashboardService: dashboardService}
+
+		dashboardService.On("GetDashboardMeta", mock.Anything, int64(1), "folder1").Return(folder, nil)
+		folderStore.On("Find", mock.Anything, int64(1), "folder1").
+			Return(&PublicFolder{Uid: "pubfolder1", FolderUid: "folder1", OrgId: 1, IsEnabled: false}, nil)
+		folderStore.On("Update", mock.Anything, mock.AnythingOfType("*models.PublicFolder")).Return(nil)
+
+		folderStore.On("FindDashboards", mock.Anything, int64(1), int64(10)).Return([]*models.Dashboard{
+			newFolderDashboard("byFolder", "a"),
+			newFolderDashboard("onItsOwn", "b"),
+		}, nil)
+		store.On("FindByDashboardUids", mock.Anything, int64(1), []string{"byFolder", "onItsOwn"}).Return(map[string]*PublicDashboard{
+			"byFolder": {Uid: "pubdash1", DashboardUid: "byFolder", IsEnabled: false, SharedByFolderUid: "folder1"},
+			"onItsOwn": {Uid: "pubdash2", DashboardUid: "onItsOwn", IsEnabled: false},
+		}, nil)
+		store.On("Update", mock.Anything, mock.MatchedBy(func(cmd SavePublicDashboardConfigCommand) bool {
+			return cmd.PublicDashboard.Uid == "pubdash1" && cmd.PublicDashboard.IsEnabled
+		})).Return(nil).Once()
+
+		_, err := service.SavePublicFolder(context.Background(), SignedInUser, &SavePublicFolderDTO{
+			FolderUid:    "folder1",
+			OrgId:        1,
+			UserId:       7,
+			PublicFolder: &PublicFolder{IsEnabled: true},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("returns ErrPublicFolderFolderNotFound when the uid is not a folder", func(t *testing.T) {
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), dashboardService: dashboardService}
+
+		dashboardService.On("GetDashboardMeta", mock.Anything, int64(1), "dash1").Return(&dashboards.DashboardMeta{UID: "dash1", OrgID: 1}, nil)
+
+		_, err := service.SavePublicFolder(context.Background(), SignedInUser, &SavePublicFolderDTO{
+			FolderUid:    "dash1",
+			OrgId:        1,
+			PublicFolder: &PublicFolder{IsEnabled: true},
+		})
+		require.ErrorIs(t, err, ErrPublicFolderFolderNotFound)
+	})
+
+	t.Run("returns ErrPublicFolderFolderNotFound when the folder does not exist", func(t *testing.T) {
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), dashboardService: dashboardService}
+
+		dashboardService.On("GetDashboardMeta", mock.Anything, int64(1), "folder1").Return(nil, dashboards.ErrDashboardNotFound)
+
+		_, err := service.SavePublicFolder(context.Background(), SignedInUser, &SavePublicFolderDTO{
+			FolderUid:    "folder1",
+			OrgId:        1,
+			PublicFolder: &PublicFolder{IsEnabled: true},
+		})
+		require.ErrorIs(t, err, ErrPublicFolderFolderNotFound)
+	})
+}
+
+func TestGetPublicFolderViewerPayload(t *testing.T) {
+	folder := &dashboards.DashboardMeta{ID: 10, UID: "folder1", OrgID: 1, Title: "status", IsFolder: true}
+	publicFolder := &PublicFolder{Uid: "pubfolder1", FolderUid: "folder1", OrgId: 1, IsEnabled: true, CreatedBy: 7}
+	t.Run("lists the enabled public dashboards of the folder", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		folderStore := NewFakePublicFolderStore(t)
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store, folderStore: folderStore, dashboardService: dashboardService}
+
+		folderStore.On("FindByAccessToken", mock.Anything, "folderToken").Return(publicFolder, nil)
+		dashboardService.On("GetDashboardMeta", mock.Anything, int64(1), "folder1").Return(folder, nil)
+		folderStore.On("FindDashboards", mock.Anything, int64(1), int64(10)).Return([]*models.Dashboard{
+			newFolderDashboard("shared", "a"),
+			newFolderDashboard("new", "b"),
+			newFolderDashboard("disabled", "c"),
+			newFolderDashboard("byFolder", "d"),
+		}, nil)
+		store.On("FindByDashboardUids", mock.Anything, int64(1), []string{"shared", "new", "disabled", "byFolder"}).Return(map[string]*PublicDashboard{
+			"shared":   {AccessToken: "sharedToken", IsEnabled: true},
+			"disabled": {AccessToken: "disabledToken", IsEnabled: false},
+			"byFolder": {AccessToken: "byFolderToken", IsEnabled: true, SharedByFolderUid: "folder1"},
+		}, nil)
+
+		// viewers don't create public dashboards, dashboards added to the folder are shared when it is saved again
+		payload, err := service.GetPublicFolderViewerPayload(context.Background(), "folderToken")
+		require.NoError(t, err)
+
+		assert.Equal(t, "status", payload.Title)
+		assert.Equal(t, []PublicFolderViewerPayloadDashboard{
+			{AccessToken: "sharedToken", Title: "a"},
+			{AccessToken: "byFolderToken", Title: "d"},
+		}, payload.Dashboards)
+	})
+
+	t.Run("returns ErrPublicFolderDisabled when the public folder is disabled", func(t *testing.T) {
+		folderStore := NewFakePublicFolderStore(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), folderStore: folderStore}
+
+		folderStore.On("FindByAccessToken", mock.Anything, "folderToken").Return(&PublicFolder{FolderUid: "folder1", OrgId: 1}, nil)
+
+		_, err := service.GetPublicFolderViewerPayload(context.Background(), "folderToken")
+		require.ErrorIs(t, err, ErrPublicFolderDisabled)
+	})
+
+	t.Run("returns ErrPublicFolderNotFound for an unknown access token", func(t *testing.T) {
+		folderStore := NewFakePublicFolderStore(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), folderStore: folderStore}
+
+		folderStore.On("FindByAccessToken", mock.Anything, "folderToken").Return(nil, nil)
+
+		_, err := service.GetPublicFolderViewerPayload(context.Background(), "folderToken")
+		require.ErrorIs(t, err, ErrPublicFolderNotFound)
+	})
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+const (
+	// lastUsedGranularity is the precision of the last use of access tokens. Uses within this period of the recorded
+	// one are not written again
+	lastUsedGranularity = time.Hour
+	// lastUsedFlushInterval is how often the recorded uses are written to the database, in a single batch
+	lastUsedFlushInterval = time.Minute
+)
+
+// LastUsedTracker keeps in memory when the access tokens of public dashboards were last used and periodically writes
+// them to the database, so viewing a public dashboard does not write on every request
+type LastUsedTracker struct {
+	log      log.Logger
+	features featuremgmt.FeatureToggles
+	store    publicdashboards.Store
+
+	mu      sync.Mutex
+	pending map[string]time.Time
+}
+
+// ProvideLastUsedTracker Factory for method used by wire to inject dependencies
+func ProvideLastUsedTracker(features featuremgmt.FeatureToggles, store publicdashboards.Store) *LastUsedTracker {
+	return &LastUsedTracker{
+		log:      log.New("publicdashboards.lastused"),
+		features: features,
+		store:    store,
+		pending:  make(map[string]time.Time),
+	}
+}
+
+// IsDisabled uses are only tracked when public dashboards are enabled
+func (t *LastUsedTracker) IsDisabled() bool {
+	return !t.features.IsEnabled(featuremgmt.FlagPublicDashboards)
+}
+
+func (t *LastUsedTracker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(lastUsedFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.flushAndLog(ctx)
+		case <-ctx.Done():
+			// the context is done, the uses recorded since the last flush are written with a fresh one
+			t.flushAndLog(context.Background())
+			return ctx.Err()
+		}
+	}
+}
+
+// record keeps the use of the access token of the public dashboard until the next flush, unless the last recorded use
+// is recent enough
+func (t *LastUsedTracker) record(publicDashboard *PublicDashboard, now time.Time) {
+	if t == nil {
+		return
+	}
+
+	if publicDashboard.LastUsedAt != nil && now.Sub(*publicDashboard.LastUsedAt) < lastUsedGranularity {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pending[publicDashboard.Uid] = now
+}
+
+// Flush writes the uses recorded since the last flush. Uses failing to be written are kept for the next flush
+func (t *LastUsedTracker) Flush(ctx context.Context) error {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[string]time.Time)
+	t.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if err := t.store.UpdateLastUsedAt(ctx, pending); err != nil {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		// uses recorded during the flush are more recent than the failed ones
+		for uid, usedAt := range pending {
+			if _, ok := t.pending[uid]; !ok {
+				t.pending[uid] = usedAt
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (t *LastUsedTracker) flushAndLog(ctx context.Context) {
+	if err := t.Flush(ctx); err != nil {
+		t.log.FromContext(ctx).Error("Failed to write the last use of public dashboard access tokens", "error", err)
+	}
+}
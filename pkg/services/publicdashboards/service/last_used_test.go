@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+func TestLastUsedTracker(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("flushes the recorded uses in a single batch", func(t *testing.T) {
+		store := publicdashboards.NewFakePublicDashboardStore(t)
+		tracker := ProvideLastUsedTracker(featuremgmt.WithFeatures(), store)
+
+		tracker.record(&PublicDashboard{Uid: "never-used"}, now)
+		tracker.record(&PublicDashboard{Uid: "used"}, now.Add(-time.Minute))
+		tracker.record(&PublicDashboard{Uid: "used"}, now)
+
+		store.On("UpdateLastUsedAt", mock.Anything, map[string]time.Time{"never-used": now, "used": now}).Return(nil).Once()
+		require.NoError(t, tracker.Flush(context.Background()))
+
+		// nothing left to write
+		require.NoError(t, tracker.Flush(context.Background()))
+	})
+
+	t.Run("skips uses within the granularity of the last recorded one", func(t *testing.T) {
+		store := publicdashboards.NewFakePublicDashboardStore(t)
+		tracker := ProvideLastUsedTracker(featuremgmt.WithFeatures(), store)
+
+		recent := now.Add(-lastUsedGranularity / 2)
+		tracker.record(&PublicDashboard{Uid: "recent", LastUsedAt: &recent}, now)
+		old := now.Add(-lastUsedGranularity)
+		tracker.record(&PublicDashboard{Uid: "old", LastUsedAt: &old}, now)
+
+		store.On("UpdateLastUsedAt", mock.Anything, map[string]time.Time{"old": now}).Return(nil).Once()
+		require.NoError(t, tracker.Flush(context.Background()))
+	})
+
+	t.Run("keeps the uses failing to be written for the next flush", func(t *testing.T) {
+		store := publicdashboards.NewFakePublicDashboardStore(t)
+		tracker := ProvideLastUsedTracker(featuremgmt.WithFeatures(), store)
+
+		tracker.record(&PublicDashboard{Uid: "abc"}, now)
+
+		store.On("UpdateLastUsedAt", mock.Anything, map[string]time.Time{"abc": now}).Return(errors.New("db down")).Once()
+		require.Error(t, tracker.Flush(context.Background()))
+
+		store.On("UpdateLastUsedAt", mock.Anything, map[string]time.Time{"abc": now}).Return(nil).Once()
+		require.NoError(t, tracker.Flush(context.Background()))
+	})
+
+	t.Run("nil tracker does not record", func(t *testing.T) {
+		var tracker *LastUsedTracker
+		tracker.record(&PublicDashboard{Uid: "abc"}, now)
+	})
+
+	t.Run("is disabled without the public dashboards feature", func(t *testing.T) {
+		assert.True(t, ProvideLastUsedTracker(featuremgmt.WithFeatures(), nil).IsDisabled())
+		assert.False(t, ProvideLastUsedTracker(featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), nil).IsDisabled())
+	})
+}
+
+func TestFindPublicDashboardAndDashboardByAccessTokenRecordsLastUse(t *testing.T) {
+	store := publicdashboards.NewFakePublicDashboardStore(t)
+	tracker := ProvideLastUsedTracker(featuremgmt.WithFeatures(), store)
+	service := &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store, lastUsedTracker: tracker}
+
+	pubdash := &PublicDashboard{Uid: "abc", DashboardUid: "dash", OrgId: 1, IsEnabled: true, AccessToken: "token"}
+	store.On("FindByAccessToken", mock.Anything, "token").Return(pubdash, nil)
+	store.On("FindDashboard", mock.Anything, "dash", int64(1)).Return(&models.Dashboard{Uid: "dash", OrgId: 1}, nil)
+
+	_, _, err := service.FindPublicDashboardAndDashboardByAccessToken(context.Background(), "token")
+	require.NoError(t, err)
+
+	store.On("UpdateLastUsedAt", mock.Anything, mock.MatchedBy(func(lastUsed map[string]time.Time) bool {
+		_, ok := lastUsed["abc"]
+		return len(lastUsed) == 1 && ok
+	})).Return(nil).Once()
+	require.NoError(t, tracker.Flush(context.Background()))
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// TransferOwnership makes another user of the organization the owner of the public dashboard of a dashboard, so
+// shared links stay managed when their creator leaves. The new owner must be allowed to manage the public dashboard
+func (pd *PublicDashboardServiceImpl) TransferOwnership(ctx context.Context, u *user.SignedInUser, dto *TransferPublicDashboardOwnershipDTO) (*PublicDashboard, error) {
+	pubdash, err := pd.store.FindByDashboardUid(ctx, dto.OrgId, dto.DashboardUid)
+	if err != nil {
+		return nil, err
+	}
+	if pubdash == nil {
+		return nil, ErrPublicDashboardNotFound
+	}
+
+	newOwner, err := pd.userService.GetSignedInUserWithCacheCtx(ctx, &user.GetSignedInUserQuery{UserID: dto.NewOwnerId, OrgID: pubdash.OrgId})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil, ErrPublicDashboardOwnerNotFound
+		}
+		return nil, err
+	}
+	// users outside of the organization are returned without a role in it
+	if newOwner.OrgID != pubdash.OrgId || newOwner.OrgRole == "" {
+		return nil, ErrPublicDashboardOwnerNotFound
+	}
+
+	canManage, err := pd.ac.Evaluate(ctx, newOwner, accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(pubdash.DashboardUid)))
+	if err != nil {
+		return nil, err
+	}
+	if !canManage {
+		return nil, ErrPublicDashboardOwnerForbidden
+	}
+
+	err = pd.store.UpdateOwner(ctx, TransferPublicDashboardOwnershipCommand{
+		Uid:       pubdash.Uid,
+		CreatedBy: newOwner.UserID,
+		UpdatedBy: dto.UserId,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pd.log.FromContext(ctx).Info("Public dashboard ownership transferred", "publicDashboardUid", pubdash.Uid, "dashboardUid", pubdash.DashboardUid, "previousOwnerId", pubdash.CreatedBy, "newOwnerId", newOwner.UserID, "user", u.Login)
+
+	return pd.store.Find(ctx, pubdash.Uid)
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/org"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+)
+
+func TestTransferOwnership(t *testing.T) {
+	pubdash := &PublicDashboard{Uid: "pubdash", DashboardUid: "dash", OrgId: 1, CreatedBy: 7}
+	dto := &TransferPublicDashboardOwnershipDTO{DashboardUid: "dash", OrgId: 1, UserId: 1, NewOwnerId: 8}
+
+	setup := func(t *testing.T, newOwner *user.SignedInUser, userErr error, canManage bool) (*PublicDashboardServiceImpl, *FakePublicDashboardStore) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("FindByDashboardUid", mock.Anything, int64(1), "dash").Return(pubdash, nil)
+
+		userService := usertest.NewUserServiceFake()
+		userService.ExpectedSignedInUser = newOwner
+		userService.ExpectedError = userErr
+
+		ac := acmock.New()
+		ac.EvaluateFunc = func(_ context.Context, u *user.SignedInUser, evaluator accesscontrol.Evaluator) (bool, error) {
+			assert.Equal(t, newOwner, u)
+			assert.Equal(t, accesscontrol.EvalPermission("dashboards.public:write", "dashboards:uid:dash").String(), evaluator.String())
+			return canManage, nil
+		}
+
+		return &PublicDashboardServiceImpl{
+			log:         log.New("test.logger"),
+			store:       store,
+			userService: userService,
+			ac:          ac,
+		}, store
+	}
+
+	t.Run("makes the new owner the creator of the public dashboard", func(t *testing.T) {
+		service, store := setup(t, &user.SignedInUser{UserID: 8, OrgID: 1, OrgRole: org.RoleEditor}, nil, true)
+		store.On("UpdateOwner", mock.Anything, mock.MatchedBy(func(cmd TransferPublicDashboardOwnershipCommand) bool {
+			return cmd.Uid == "pubdash" && cmd.CreatedBy == 8 && cmd.UpdatedBy == 1 && !cmd.UpdatedAt.IsZero()
+		})).Return(nil)
+		store.On("Find", mock.Anything, "pubdash").Return(&PublicDashboard{Uid: "pubdash", CreatedBy: 8}, nil)
+
+		transferred, err := service.TransferOwnership(context.Background(), SignedInUser, dto)
+		require.NoError(t, err)
+		assert.Equal(t, int64(8), transferred.CreatedBy)
+	})
+
+	t.Run("fails when the new owner does not exist", func(t *testing.T) {
+		service, store := setup(t, nil, user.ErrUserNotFound, true)
+
+		_, err := service.TransferOwnership(context.Background(), SignedInUser, dto)
+		require.ErrorIs(t, err, ErrPublicDashboardOwnerNotFound)
+		store.AssertNotCalled(t, "UpdateOwner", mock.Anything, mock.Anything)
+	})
+
+	t.Run("fails when the new owner is not in the organization", func(t *testing.T) {
+		service, store := setup(t, &user.SignedInUser{UserID: 8, OrgID: 2, OrgRole: org.RoleAdmin}, nil, true)
+
+		_, err := service.TransferOwnership(context.Background(), SignedInUser, dto)
+		require.ErrorIs(t, err, ErrPublicDashboardOwnerNotFound)
+		store.AssertNotCalled(t, "UpdateOwner", mock.Anything, mock.Anything)
+	})
+
+	t.Run("fails when the new owner cannot manage the public dashboard", func(t *testing.T) {
+		service, store := setup(t, &user.SignedInUser{UserID: 8, OrgID: 1, OrgRole: org.RoleViewer}, nil, false)
+
+		_, err := service.TransferOwnership(context.Background(), SignedInUser, dto)
+		require.ErrorIs(t, err, ErrPublicDashboardOwnerForbidden)
+		store.AssertNotCalled(t, "UpdateOwner", mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns the errors of the user service", func(t *testing.T) {
+		service, _ := setup(t, nil, errors.New("db error"), true)
+
+		_, err := service.TransferOwnership(context.Background(), SignedInUser, dto)
+		require.EqualError(t, err, "db error")
+	})
+
+	t.Run("fails when the dashboard has no public dashboard", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("FindByDashboardUid", mock.Anything, int64(1), "dash").Return(nil, nil)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store}
+
+		_, err := service.TransferOwnership(context.Background(), SignedInUser, dto)
+		require.ErrorIs(t, err, ErrPublicDashboardNotFound)
+	})
+}
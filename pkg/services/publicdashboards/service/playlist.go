@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/internal/tokens"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// FindAllPlaylists Returns the public playlists of an org
+func (pd *PublicDashboardServiceImpl) FindAllPlaylists(ctx context.Context, orgId int64) ([]PublicPlaylist, error) {
+	return pd.playlistStore.FindAll(ctx, orgId)
+}
+
+// FindPlaylist Returns a public playlist with its items
+func (pd *PublicDashboardServiceImpl) FindPlaylist(ctx context.Context, orgId int64, uid string) (*PublicPlaylist, error) {
+	playlist, err := pd.playlistStore.Find(ctx, orgId, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if playlist == nil {
+		return nil, ErrPublicPlaylistNotFound
+	}
+
+	return playlist, nil
+}
+
+// SavePlaylist creates a public playlist, or updates it when the dto has a Uid. Every item must reference a public
+// dashboard of the org
+func (pd *PublicDashboardServiceImpl) SavePlaylist(ctx context.Context, u *user.SignedInUser, dto *SavePublicPlaylistDTO) (*PublicPlaylist, error) {
+	playlist := dto.PublicPlaylist
+	if playlist.Name == "" {
+		return nil, ErrPublicPlaylistNameNotSet
+	}
+
+	if err := pd.validatePlaylistItems(ctx, dto.OrgId, playlist.Items); err != nil {
+		return nil, err
+	}
+
+	playlist.OrgId = dto.OrgId
+	now := time.Now()
+
+	if dto.Uid == "" {
+		accessToken, err := tokens.GenerateAccessToken()
+		if err != nil {
+			return nil, ErrPublicDashboardFailedGenerateAccessToken
+		}
+
+		playlist.Uid = util.GenerateShortUID()
+		playlist.AccessToken = accessToken
+		playlist.CreatedBy = dto.UserId
+		playlist.CreatedAt = now
+		if err := pd.playlistStore.Save(ctx, playlist); err != nil {
+			return nil, err
+		}
+	} else {
+		playlist.Uid = dto.Uid
+		playlist.UpdatedBy = dto.UserId
+		playlist.UpdatedAt = now
+		if err := pd.playlistStore.Update(ctx, playlist); err != nil {
+			return nil, err
+		}
+	}
+
+	return pd.FindPlaylist(ctx, dto.OrgId, playlist.Uid)
+}
+
+// DeletePlaylist removes a public playlist. The public dashboards of its items are left untouched
+func (pd *PublicDashboardServiceImpl) DeletePlaylist(ctx context.Context, orgId int64, uid string) error {
+	return pd.playlistStore.Delete(ctx, orgId, uid)
+}
+
+// GetPlaylistViewerPayload Returns what a viewer needs to rotate through the public dashboards of a public playlist.
+// Items of public dashboards that have been disabled or deleted, or are restricted in the country of the viewer,
+// are left out
+func (pd *PublicDashboardServiceImpl) GetPlaylistViewerPayload(ctx context.Context, accessToken string) (*PublicPlaylistViewerPayload, error) {
+	playlist, err := pd.playlistStore.FindByAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if playlist == nil {
+		return nil, ErrPublicPlaylistNotFound
+	}
+
+	pubdashes, err := pd.findPlaylistItemsPublicDashboards(ctx, playlist.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboardUids := make([]string, 0, len(pubdashes))
+	seen := make(map[string]bool, len(pubdashes))
+	for _, pubdash := range pubdashes {
+		if pubdash.OrgId == playlist.OrgId && pubdash.IsEnabled && !seen[pubdash.DashboardUid] {
+			dashboardUids = append(dashboardUids, pubdash.DashboardUid)
+			seen[pubdash.DashboardUid] = true
+		}
+	}
+
+	// dashboards that have been deleted are not part of the result, their items are left out
+	dashes, err := pd.dashboardService.GetDashboardsByUIDs(ctx, &dashboards.GetDashboardsByUIDsQuery{
+		OrgID: playlist.OrgId,
+		UIDs:  dashboardUids,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &PublicPlaylistViewerPayload{
+		Name:  playlist.Name,
+		Items: make([]PublicPlaylistViewerPayloadItem, 0, len(playlist.Items)),
+	}
+	for _, item := range playlist.Items {
+		pubdash, ok := pubdashes[item.AccessToken]
+		if !ok || pubdash.OrgId != playlist.OrgId || !pubdash.IsEnabled {
+			continue
+		}
+		dash, ok := dashes.Dashboards[pubdash.DashboardUid]
+		if !ok {
+			continue
+		}
+		if pd.checkCountryAccess(ctx, pubdash) != nil {
+			continue
+		}
+
+		payload.Items = append(payload.Items, PublicPlaylistViewerPayloadItem{
+			AccessToken:  item.AccessToken,
+			Title:        dash.Title,
+			DwellSeconds: item.DwellSeconds,
+		})
+	}
+
+	return payload, nil
+}
+
+func (pd *PublicDashboardServiceImpl) validatePlaylistItems(ctx context.Context, orgId int64, items []PublicPlaylistItem) error {
+	for i := range items {
+		if items[i].DwellSeconds == 0 {
+			items[i].DwellSeconds = DefaultPublicPlaylistDwellSeconds
+		}
+		if items[i].DwellSeconds < MinPublicPlaylistDwellSeconds {
+			return ErrPublicPlaylistInvalidDwellTime
+		}
+
+		if !tokens.IsValidAccessToken(items[i].AccessToken) {
+			return ErrPublicPlaylistInvalidItem
+		}
+	}
+
+	pubdashes, err := pd.findPlaylistItemsPublicDashboards(ctx, items)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		pubdash, ok := pubdashes[item.AccessToken]
+		if !ok || pubdash.OrgId != orgId {
+			return ErrPublicPlaylistInvalidItem
+		}
+	}
+
+	return nil
+}
+
+// findPlaylistItemsPublicDashboards returns the public dashboards of the items of a playlist keyed by access token,
+// looked up in batches instead of one query per item
+func (pd *PublicDashboardServiceImpl) findPlaylistItemsPublicDashboards(ctx context.Context, items []PublicPlaylistItem) (map[string]*PublicDashboard, error) {
+	if len(items) == 0 {
+		return map[string]*PublicDashboard{}, nil
+	}
+
+	accessTokens := make([]string, 0, len(items))
+	for _, item := range items {
+		accessTokens = append(accessTokens, item.AccessToken)
+	}
+
+	return pd.store.FindByAccessTokens(ctx, accessTokens)
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+const (
+	playlistItemToken      = "e71fe2bc8c2d4d1d9fb6e4e9c36de8a1"
+	otherPlaylistItemToken = "8d8e2b5ba9b14df3ae4e15c4e4a8c0f2"
+)
+
+func TestSavePublicPlaylist(t *testing.T) {
+	t.Run("creates a playlist with defaults for items without dwell time", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		playlistStore := NewFakePublicPlaylistStore(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store, playlistStore: playlistStore}
+
+		store.On("FindByAccessTokens", mock.Anything, []string{playlistItemToken}).Return(map[string]*PublicDashboard{
+			playlistItemToken: {OrgId: 1, AccessToken: playlistItemToken},
+		}, nil)
+
+		var saved *PublicPlaylist
+		playlistStore.On("Save", mock.Anything, mock.AnythingOfType("*models.PublicPlaylist")).
+			Run(func(args mock.Arguments) { saved = args.Get(1).(*PublicPlaylist) }).
+			Return(nil)
+		playlistStore.On("Find", mock.Anything, int64(1), mock.AnythingOfType("string")).
+			Return(func(_ context.Context, _ int64, _ string) *PublicPlaylist { return saved }, nil)
+
+		playlist, err := service.SavePlaylist(context.Background(), SignedInUser, &SavePublicPlaylistDTO{
+			OrgId:  1,
+			UserId: 7,
+			PublicPlaylist: &PublicPlaylist{
+				Name:  "lobby",
+				Items: []PublicPlaylistItem{{AccessToken: playlistItemToken}},
+			},
+		})
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, playlist.Uid)
+		assert.Len(t, playlist.AccessToken, 32)
+		assert.Equal(t, int64(1), playlist.OrgId)
+		assert.Equal(t, int64(7), playlist.CreatedBy)
+		assert.Equal(t, int64(DefaultPublicPlaylistDwellSeconds), playlist.Items[0].DwellSeconds)
+	})
+
+	t.Run("updates the playlist of the given uid", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		playlistStore := NewFakePublicPlaylistStore(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store, playlistStore: playlistStore}
+
+		playlistStore.On("Update", mock.Anything, mock.MatchedBy(func(p *PublicPlaylist) bool {
+			return p.Uid == "playlist1" && p.OrgId == 1 && p.UpdatedBy == 7
+		})).Return(nil)
+		playlistStore.On("Find", mock.Anything, int64(1), "playlist1").Return(&PublicPlaylist{Uid: "playlist1", Name: "hall"}, nil)
+
+		playlist, err := service.SavePlaylist(context.Background(), SignedInUser, &SavePublicPlaylistDTO{
+			Uid:            "playlist1",
+			OrgId:          1,
+			UserId:         7,
+			PublicPlaylist: &PublicPlaylist{Name: "hall"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "hall", playlist.Name)
+	})
+
+	t.Run("returns ErrPublicPlaylistNameNotSet without name", func(t *testing.T) {
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger")}
+
+		_, err := service.SavePlaylist(context.Background(), SignedInUser, &SavePublicPlaylistDTO{OrgId: 1, PublicPlaylist: &PublicPlaylist{}})
+		require.ErrorIs(t, err, ErrPublicPlaylistNameNotSet)
+	})
+
+	t.Run("returns ErrPublicPlaylistInvalidDwellTime when dwell time is too short", func(t *testing.T) {
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger")}
+
+		_, err := service.SavePlaylist(context.Background(), SignedInUser, &SavePublicPlaylistDTO{
+			OrgId: 1,
+			PublicPlaylist: &PublicPlaylist{
+				Name:  "lobby",
+				Items: []PublicPlaylistItem{{AccessToken: playlistItemToken, DwellSeconds: 1}},
+			},
+		})
+		require.ErrorIs(t, err, ErrPublicPlaylistInvalidDwellTime)
+	})
+
+	t.Run("returns ErrPublicPlaylistInvalidItem for a public dashboard of another org", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store}
+
+		store.On("FindByAccessTokens", mock.Anything, []string{playlistItemToken}).Return(map[string]*PublicDashboard{
+			playlistItemToken: {OrgId: 2, AccessToken: playlistItemToken},
+		}, nil)
+
+		_, err := service.SavePlaylist(context.Background(), SignedInUser, &SavePublicPlaylistDTO{
+			OrgId: 1,
+			PublicPlaylist: &PublicPlaylist{
+				Name:  "lobby",
+				Items: []PublicPlaylistItem{{AccessToken: playlistItemToken}},
+			},
+		})
+		require.ErrorIs(t, err, ErrPublicPlaylistInvalidItem)
+	})
+
+	t.Run("returns ErrPublicPlaylistInvalidItem for an unknown public dashboard", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store}
+
+		store.On("FindByAccessTokens", mock.Anything, []string{playlistItemToken}).Return(map[string]*PublicDashboard{}, nil)
+
+		_, err := service.SavePlaylist(context.Background(), SignedInUser, &SavePublicPlaylistDTO{
+			OrgId: 1,
+			PublicPlaylist: &PublicPlaylist{
+				Name:  "lobby",
+				Items: []PublicPlaylistItem{{AccessToken: playlistItemToken}},
+			},
+		})
+		require.ErrorIs(t, err, ErrPublicPlaylistInvalidItem)
+	})
+}
+
+func TestGetPlaylistViewerPayload(t *testing.T) {
+	t.Run("returns the enabled public dashboards of the playlist in order", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		playlistStore := NewFakePublicPlaylistStore(t)
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store, playlistStore: playlistStore, dashboardService: dashboardService}
+
+		playlistStore.On("FindByAccessToken", mock.Anything, "playlistToken").Return(&PublicPlaylist{
+			OrgId: 1,
+			Name:  "lobby",
+			Items: []PublicPlaylistItem{
+				{AccessToken: otherPlaylistItemToken, DwellSeconds: 10},
+				{AccessToken: "disabled", DwellSeconds: 20},
+				{AccessToken: "deleted", DwellSeconds: 30},
+				{AccessToken: "otherOrg", DwellSeconds: 35},
+				{AccessToken: playlistItemToken, DwellSeconds: 40},
+			},
+		}, nil)
+		store.On("FindByAccessTokens", mock.Anything, []string{otherPlaylistItemToken, "disabled", "deleted", "otherOrg", playlistItemToken}).Return(map[string]*PublicDashboard{
+			playlistItemToken:      {OrgId: 1, AccessToken: playlistItemToken, DashboardUid: "dash1", IsEnabled: true},
+			otherPlaylistItemToken: {OrgId: 1, AccessToken: otherPlaylistItemToken, DashboardUid: "dash2", IsEnabled: true},
+			"disabled":             {OrgId: 1, AccessToken: "disabled", DashboardUid: "dash3", IsEnabled: false},
+			"deleted":              {OrgId: 1, AccessToken: "deleted", DashboardUid: "dash4", IsEnabled: true},
+			"otherOrg":             {OrgId: 2, AccessToken: "otherOrg", DashboardUid: "dash5", IsEnabled: true},
+		}, nil)
+		dashboardService.On("GetDashboardsByUIDs", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardsByUIDsQuery) bool {
+			return q.OrgID == 1 && assert.ElementsMatch(t, []string{"dash1", "dash2", "dash4"}, q.UIDs)
+		})).Return(&dashboards.GetDashboardsByUIDsResult{
+			Dashboards: map[string]*models.Dashboard{
+				"dash1": {Uid: "dash1", Title: "first"},
+				"dash2": {Uid: "dash2", Title: "second"},
+			},
+			Errors: map[string]error{"dash4": dashboards.ErrDashboardNotFound},
+		}, nil)
+
+		payload, err := service.GetPlaylistViewerPayload(context.Background(), "playlistToken")
+		require.NoError(t, err)
+
+		assert.Equal(t, "lobby", payload.Name)
+		assert.Equal(t, []PublicPlaylistViewerPayloadItem{
+			{AccessToken: otherPlaylistItemToken, Title: "second", DwellSeconds: 10},
+			{AccessToken: playlistItemToken, Title: "first", DwellSeconds: 40},
+		}, payload.Items)
+	})
+
+	t.Run("leaves out the public dashboards restricted in the country of the viewer", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		playlistStore := NewFakePublicPlaylistStore(t)
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store, playlistStore: playlistStore, dashboardService: dashboardService}
+
+		playlistStore.On("FindByAccessToken", mock.Anything, "playlistToken").Return(&PublicPlaylist{
+			OrgId: 1,
+			Name:  "lobby",
+			Items: []PublicPlaylistItem{
+				{AccessToken: otherPlaylistItemToken, DwellSeconds: 10},
+				{AccessToken: playlistItemToken, DwellSeconds: 40},
+			},
+		}, nil)
+		store.On("FindByAccessTokens", mock.Anything, mock.Anything).Return(map[string]*PublicDashboard{
+			playlistItemToken:      {OrgId: 1, AccessToken: playlistItemToken, DashboardUid: "dash1", IsEnabled: true},
+			otherPlaylistItemToken: {OrgId: 1, AccessToken: otherPlaylistItemToken, DashboardUid: "dash2", IsEnabled: true, AllowedCountries: CountryList{"FR"}},
+		}, nil)
+		dashboardService.On("GetDashboardsByUIDs", mock.Anything, mock.Anything).Return(&dashboards.GetDashboardsByUIDsResult{
+			Dashboards: map[string]*models.Dashboard{
+				"dash1": {Uid: "dash1", Title: "first"},
+				"dash2": {Uid: "dash2", Title: "second"},
+			},
+		}, nil)
+
+		// the country of viewers without a resolved IP address is unknown
+		payload, err := service.GetPlaylistViewerPayload(context.Background(), "playlistToken")
+		require.NoError(t, err)
+
+		assert.Equal(t, []PublicPlaylistViewerPayloadItem{
+			{AccessToken: playlistItemToken, Title: "first", DwellSeconds: 40},
+		}, payload.Items)
+	})
+
+	t.Run("returns ErrPublicPlaylistNotFound for an unknown access token", func(t *testing.T) {
+		playlistStore := NewFakePublicPlaylistStore(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), playlistStore: playlistStore}
+
+		playlistStore.On("FindByAccessToken", mock.Anything, "playlistToken").Return(nil, nil)
+
+		_, err := service.GetPlaylistViewerPayload(context.Background(), "playlistToken")
+		require.ErrorIs(t, err, ErrPublicPlaylistNotFound)
+	})
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// previousPeriodLabelName is the name of the label of the fields of the previous period
+const previousPeriodLabelName = "period"
+
+// addPreviousPeriod queries the panel over the period preceding the time range of the metric request and adds the
+// frames of the previous period to the responses of their query, aligned on the current period. Failing to query
+// the previous period does not fail the panel
+func (pd *PublicDashboardServiceImpl) addPreviousPeriod(ctx context.Context, res *backend.QueryDataResponse, anonymousUser *user.SignedInUser, skipCache bool, metricReq dtos.MetricRequest) {
+	ctxLogger := pd.log.FromContext(ctx)
+
+	previousReq, shift, err := previousPeriodMetricRequest(metricReq)
+	if err != nil {
+		ctxLogger.Warn("Failed to build the previous period of public dashboard queries", "error", err)
+		return
+	}
+
+	previousRes, err := pd.QueryDataService.QueryData(ctx, anonymousUser, skipCache, previousReq)
+	if err != nil {
+		ctxLogger.Warn("Failed to query the previous period of public dashboard queries", "error", err)
+		return
+	}
+	sanitizeMetadataFromQueryData(previousRes)
+
+	for refId, previous := range previousRes.Responses {
+		current, ok := res.Responses[refId]
+		if !ok || previous.Error != nil {
+			continue
+		}
+
+		for _, frame := range previous.Frames {
+			alignPreviousPeriodFrame(frame, shift)
+			current.Frames = append(current.Frames, frame)
+		}
+		res.Responses[refId] = current
+	}
+}
+
+// previousPeriodMetricRequest returns the metric request of the period of the same length ending when the time range
+// of the metric request starts, and the duration separating both periods
+func previousPeriodMetricRequest(metricReq dtos.MetricRequest) (dtos.MetricRequest, time.Duration, error) {
+	from, err := strconv.ParseInt(metricReq.From, 10, 64)
+	if err != nil {
+		return dtos.MetricRequest{}, 0, err
+	}
+	to, err := strconv.ParseInt(metricReq.To, 10, 64)
+	if err != nil {
+		return dtos.MetricRequest{}, 0, err
+	}
+	if to <= from {
+		return dtos.MetricRequest{}, 0, fmt.Errorf("invalid time range from %d to %d", from, to)
+	}
+
+	length := to - from
+	previousReq := metricReq.CloneWithQueries(metricReq.Queries)
+	previousReq.From = strconv.FormatInt(from-length, 10)
+	previousReq.To = strconv.FormatInt(from, 10)
+
+	return previousReq, time.Duration(length) * time.Millisecond, nil
+}
+
+// alignPreviousPeriodFrame shifts the time fields of a frame of the previous period onto the current period, and
+// labels its other fields as belonging to the previous period
+func alignPreviousPeriodFrame(frame *data.Frame, shift time.Duration) {
+	for _, field := range frame.Fields {
+		switch field.Type() {
+		case data.FieldTypeTime:
+			for i := 0; i < field.Len(); i++ {
+				field.Set(i, field.At(i).(time.Time).Add(shift))
+			}
+		case data.FieldTypeNullableTime:
+			for i := 0; i < field.Len(); i++ {
+				if t := field.At(i).(*time.Time); t != nil {
+					shifted := t.Add(shift)
+					field.Set(i, &shifted)
+				}
+			}
+		default:
+			if field.Labels == nil {
+				field.Labels = data.Labels{}
+			}
+			field.Labels[previousPeriodLabelName] = models.PreviousPeriodLabel
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+func TestPreviousPeriodMetricRequest(t *testing.T) {
+	t.Run("returns the period of the same length ending when the time range starts", func(t *testing.T) {
+		queries := []*simplejson.Json{simplejson.NewFromAny(map[string]interface{}{"refId": "A"})}
+
+		previousReq, shift, err := previousPeriodMetricRequest(dtos.MetricRequest{From: "10000", To: "13600", Queries: queries})
+		require.NoError(t, err)
+		assert.Equal(t, "6400", previousReq.From)
+		assert.Equal(t, "10000", previousReq.To)
+		assert.Equal(t, queries, previousReq.Queries)
+		assert.Equal(t, 3600*time.Millisecond, shift)
+	})
+
+	t.Run("returns an error for invalid time ranges", func(t *testing.T) {
+		_, _, err := previousPeriodMetricRequest(dtos.MetricRequest{From: "now-6h", To: "now"})
+		require.Error(t, err)
+
+		_, _, err = previousPeriodMetricRequest(dtos.MetricRequest{From: "10000", To: "10000"})
+		require.Error(t, err)
+	})
+}
+
+func TestAlignPreviousPeriodFrame(t *testing.T) {
+	t1 := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	frame := data.NewFrame("A",
+		data.NewField("time", nil, []time.Time{t1}),
+		data.NewField("end", nil, []*time.Time{&t1}),
+		data.NewField("value", data.Labels{"host": "a"}, []float64{1}),
+	)
+
+	alignPreviousPeriodFrame(frame, time.Hour)
+
+	assert.Equal(t, t1.Add(time.Hour), frame.Fields[0].At(0))
+	assert.Equal(t, t1.Add(time.Hour), *frame.Fields[1].At(0).(*time.Time))
+	assert.Empty(t, frame.Fields[0].Labels)
+	assert.Equal(t, data.Labels{"host": "a", "period": PreviousPeriodLabel}, frame.Fields[2].Labels)
+}
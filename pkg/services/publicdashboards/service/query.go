@@ -2,10 +2,17 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
 	dashmodels "github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/annotations"
@@ -15,6 +22,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/tsdb/grafanads"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 // GetAnnotations returns annotations for a public dashboard
@@ -81,6 +89,11 @@ func (pd *PublicDashboardServiceImpl) FindAnnotations(ctx context.Context, reqDT
 				event.PanelId = item.PanelId
 			}
 
+			// skip events of panels annotations are disabled on
+			if !pub.AnnotationsEnabledForPanel(event.PanelId) {
+				continue
+			}
+
 			// We want events from tag queries to overwrite existing events
 			_, has := uniqueEvents[event.Id]
 			if !has || (has && anno.Target.Type == "tags") {
@@ -134,21 +147,196 @@ func (pd *PublicDashboardServiceImpl) GetQueryDataResponse(ctx context.Context,
 		return nil, models.ErrNoPanelQueriesFound
 	}
 
+	if queryDto.ComparePreviousPeriod && !publicDashboard.ComparesPreviousPeriodOnPanel(panelId) {
+		return nil, models.ErrPublicDashboardPreviousPeriodNotEnabled
+	}
+
+	if !pd.queryLimiter.tryAcquire(accessToken) {
+		pd.log.FromContext(ctx).Warn("Too many concurrent queries for public dashboard", "publicDashboardUid", publicDashboard.Uid)
+		return nil, models.ErrPublicDashboardRateLimited
+	}
+	defer pd.queryLimiter.release(accessToken)
+
+	requestId := models.QueryRequestIdFromContext(ctx)
+	if requestId == "" {
+		requestId = util.GenerateShortUID()
+		ctx = models.WithQueryRequestId(ctx, requestId)
+	}
+	// data sources whose HTTP client supports contextual middlewares forward the request ID to their backend
+	ctx = httpclient.WithContextualMiddleware(ctx, queryRequestIdMiddleware(requestId))
+	ctxLogger := pd.log.FromContext(ctx)
+
 	anonymousUser := buildAnonymousUser(ctx, dashboard)
-	res, err := pd.QueryDataService.QueryData(ctx, anonymousUser, skipCache, metricReq)
 
-	reqDatasources := metricReq.GetUniqueDatasourceTypes()
+	// queries the anonymous user cannot run get a response explaining why instead of failing the panel
+	metricReq, unsupported := pd.splitUnsupportedQueries(ctx, anonymousUser, skipCache, metricReq)
+	if len(metricReq.Queries) == 0 {
+		ctxLogger.Debug("No query of the panel can be run by public dashboards", "publicDashboardUid", publicDashboard.Uid, "panelId", panelId)
+		return &backend.QueryDataResponse{Responses: unsupported}, nil
+	}
+
+	execution := newQueryExecution(requestId, publicDashboard, panelId, metricReq)
+
+	var res *backend.QueryDataResponse
+	if pd.cfg != nil && pd.cfg.PublicDashboards.ContinueOnQueryError {
+		res = queryDataContinueOnError(metricReq, requestId, ctxLogger, func(req dtos.MetricRequest) (*backend.QueryDataResponse, error) {
+			return pd.QueryDataService.QueryData(ctx, anonymousUser, skipCache, req)
+		})
+		resErr := queryDataResponseError(res)
+		pd.recordQueryExecution(execution, resErr)
+		pd.recordUsage(publicDashboard, resErr != nil)
+		pd.recordPanelLatency(ctx, publicDashboard, panelId, time.Since(execution.StartedAt))
+	} else {
+		res, err = pd.QueryDataService.QueryData(ctx, anonymousUser, skipCache, metricReq)
+		pd.recordQueryExecution(execution, err)
+		pd.recordUsage(publicDashboard, err != nil)
+		pd.recordPanelLatency(ctx, publicDashboard, panelId, time.Since(execution.StartedAt))
+
+		reqDatasources := metricReq.GetUniqueDatasourceTypes()
+		if err != nil {
+			LogQueryFailure(reqDatasources, ctxLogger, err)
+			return nil, err
+		}
+		LogQuerySuccess(reqDatasources, ctxLogger)
+	}
+
+	sanitizeMetadataFromQueryData(res)
+	addUnsupportedResponses(res, unsupported)
+
+	res, err = pd.transformQueryData(res, dashboard, panelId, metricReq, queryDto)
 	if err != nil {
-		LogQueryFailure(reqDatasources, pd.log, err)
 		return nil, err
 	}
-	LogQuerySuccess(reqDatasources, pd.log)
 
-	sanitizeMetadataFromQueryData(res)
+	if queryDto.ComparePreviousPeriod {
+		pd.addPreviousPeriod(ctx, res, anonymousUser, skipCache, metricReq)
+	}
+
+	return res, nil
+}
+
+// transformQueryData applies the transformations of the panel to the response when requested
+func (pd *PublicDashboardServiceImpl) transformQueryData(res *backend.QueryDataResponse, dashboard *dashmodels.Dashboard, panelId int64, metricReq dtos.MetricRequest, queryDto models.PublicDashboardQueryDTO) (*backend.QueryDataResponse, error) {
+	if !queryDto.ApplyTransformations {
+		return res, nil
+	}
+
+	refIds := make([]string, 0, len(metricReq.Queries))
+	for _, query := range metricReq.Queries {
+		refIds = append(refIds, query.Get("refId").MustString())
+	}
+
+	if err := applyPanelTransformations(res, refIds, getPanelTransformations(dashboard.Data, panelId)); err != nil {
+		return nil, err
+	}
 
 	return res, nil
 }
 
+// FindQueryExecutions returns the recent public query executions of this instance matching the request ID, or all of
+// them if the request ID is empty
+func (pd *PublicDashboardServiceImpl) FindQueryExecutions(ctx context.Context, requestId string) ([]models.PublicDashboardQueryExecution, error) {
+	if pd.queryHistory == nil {
+		return []models.PublicDashboardQueryExecution{}, nil
+	}
+	return pd.queryHistory.find(requestId), nil
+}
+
+func (pd *PublicDashboardServiceImpl) recordQueryExecution(execution models.PublicDashboardQueryExecution, err error) {
+	if pd.queryHistory == nil {
+		return
+	}
+
+	execution.DurationMs = time.Since(execution.StartedAt).Milliseconds()
+	execution.Status = models.QuerySuccess
+	if err != nil {
+		execution.Status = models.QueryFailure
+		execution.Error = err.Error()
+	}
+	pd.queryHistory.add(execution)
+}
+
+// queryDataResponseError returns the first error found in the responses, if any
+func queryDataResponseError(res *backend.QueryDataResponse) error {
+	for _, dataResponse := range res.Responses {
+		if dataResponse.Error != nil {
+			return dataResponse.Error
+		}
+	}
+	return nil
+}
+
+// queryDataContinueOnError queries every data source of the metric request separately and aggregates the results.
+// Queries of a data source that failed get an error response instead of failing the whole request, so the
+// healthy series of a mixed panel are still returned.
+func queryDataContinueOnError(metricReq dtos.MetricRequest, requestId string, logger log.Logger, queryData func(dtos.MetricRequest) (*backend.QueryDataResponse, error)) *backend.QueryDataResponse {
+	byDataSource := make(map[string][]*simplejson.Json)
+	for _, query := range metricReq.Queries {
+		uid := getDataSourceUidFromJson(query)
+		byDataSource[uid] = append(byDataSource[uid], query)
+	}
+
+	resp := backend.NewQueryDataResponse()
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, queries := range byDataSource {
+		subReq := metricReq.CloneWithQueries(queries)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			reqDatasources := subReq.GetUniqueDatasourceTypes()
+			subResp, err := queryData(subReq)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				LogQueryFailure(reqDatasources, logger, err)
+				for _, query := range subReq.Queries {
+					refId := query.Get("refId").MustString()
+					resp.Responses[refId] = queryErrorResponse(refId, requestId)
+				}
+				return
+			}
+			LogQuerySuccess(reqDatasources, logger)
+
+			for refId, dataResponse := range subResp.Responses {
+				resp.Responses[refId] = dataResponse
+			}
+		}()
+	}
+	wg.Wait()
+
+	return resp
+}
+
+// queryErrorResponse builds the response of a failed query. The underlying error is not exposed to public viewers,
+// only the request ID to find it in the backend logs
+func queryErrorResponse(refId string, requestId string) backend.DataResponse {
+	frame := data.NewFrame("").SetMeta(&data.FrameMeta{
+		Notices: []data.Notice{{
+			Severity: data.NoticeSeverityError,
+			Text:     fmt.Sprintf("%s (request ID: %s)", models.ErrPublicDashboardQueryFailed.Error(), requestId),
+		}},
+	})
+	frame.RefID = refId
+
+	return backend.DataResponse{
+		Error:  models.ErrPublicDashboardQueryFailed,
+		Frames: data.Frames{frame},
+	}
+}
+
+// queryRequestIdMiddleware sets the request ID of the public dashboard query on the outgoing data source requests
+func queryRequestIdMiddleware(requestId string) httpclient.Middleware {
+	return httpclient.NamedMiddlewareFunc("public-dashboard-request-id", func(opts httpclient.Options, next http.RoundTripper) http.RoundTripper {
+		return httpclient.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req.Header.Set(models.QueryRequestIdHeader, requestId)
+			return next.RoundTrip(req)
+		})
+	})
+}
+
 // buildMetricRequest merges public dashboard parameters with dashboard and returns a metrics request to be sent to query backend
 func (pd *PublicDashboardServiceImpl) buildMetricRequest(ctx context.Context, dashboard *dashmodels.Dashboard, publicDashboard *models.PublicDashboard, panelId int64, reqDTO models.PublicDashboardQueryDTO) (dtos.MetricRequest, error) {
 	// group queries by panel
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+// queryHistoryLimit is the number of public query executions kept in memory
+const queryHistoryLimit = 500
+
+// queryHistory keeps the most recent public query executions of this Grafana instance in a ring buffer
+type queryHistory struct {
+	mu         sync.RWMutex
+	executions []models.PublicDashboardQueryExecution
+	next       int
+}
+
+func newQueryHistory() *queryHistory {
+	return &queryHistory{
+		executions: make([]models.PublicDashboardQueryExecution, 0, queryHistoryLimit),
+	}
+}
+
+func (h *queryHistory) add(execution models.PublicDashboardQueryExecution) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.executions) < queryHistoryLimit {
+		h.executions = append(h.executions, execution)
+		return
+	}
+	h.executions[h.next] = execution
+	h.next = (h.next + 1) % queryHistoryLimit
+}
+
+// find returns the executions of the given request ID, or all executions if it is empty, most recent first
+func (h *queryHistory) find(requestId string) []models.PublicDashboardQueryExecution {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	result := make([]models.PublicDashboardQueryExecution, 0)
+	size := len(h.executions)
+	for i := 1; i <= size; i++ {
+		execution := h.executions[(h.next-i+size)%size]
+		if requestId == "" || execution.RequestId == requestId {
+			result = append(result, execution)
+		}
+	}
+
+	return result
+}
+
+func newQueryExecution(requestId string, publicDashboard *models.PublicDashboard, panelId int64, metricReq dtos.MetricRequest) models.PublicDashboardQueryExecution {
+	queries := make([]models.PublicDashboardQueryExecutionQuery, 0, len(metricReq.Queries))
+	for _, query := range metricReq.Queries {
+		queries = append(queries, models.PublicDashboardQueryExecutionQuery{
+			RefId:          query.Get("refId").MustString(),
+			DatasourceUid:  getDataSourceUidFromJson(query),
+			DatasourceType: query.Get("datasource").Get("type").MustString(),
+			Model:          query,
+		})
+	}
+
+	return models.PublicDashboardQueryExecution{
+		RequestId:          requestId,
+		PublicDashboardUid: publicDashboard.Uid,
+		DashboardUid:       publicDashboard.DashboardUid,
+		OrgId:              publicDashboard.OrgId,
+		PanelId:            panelId,
+		Queries:            queries,
+		StartedAt:          time.Now(),
+	}
+}
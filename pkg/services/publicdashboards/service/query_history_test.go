@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryHistory(t *testing.T) {
+	t.Run("returns executions most recent first", func(t *testing.T) {
+		history := newQueryHistory()
+		history.add(PublicDashboardQueryExecution{RequestId: "a"})
+		history.add(PublicDashboardQueryExecution{RequestId: "b"})
+		history.add(PublicDashboardQueryExecution{RequestId: "a", PanelId: 2})
+
+		executions := history.find("")
+		require.Len(t, executions, 3)
+		assert.Equal(t, int64(2), executions[0].PanelId)
+		assert.Equal(t, "b", executions[1].RequestId)
+		assert.Equal(t, "a", executions[2].RequestId)
+
+		executions = history.find("a")
+		require.Len(t, executions, 2)
+		assert.Equal(t, int64(2), executions[0].PanelId)
+
+		assert.Empty(t, history.find("unknown"))
+	})
+
+	t.Run("drops the oldest executions when the limit is reached", func(t *testing.T) {
+		history := newQueryHistory()
+		for i := 0; i < queryHistoryLimit+10; i++ {
+			history.add(PublicDashboardQueryExecution{RequestId: fmt.Sprintf("%d", i)})
+		}
+
+		executions := history.find("")
+		require.Len(t, executions, queryHistoryLimit)
+		assert.Equal(t, fmt.Sprintf("%d", queryHistoryLimit+9), executions[0].RequestId)
+		assert.Equal(t, "10", executions[queryHistoryLimit-1].RequestId)
+		assert.Empty(t, history.find("9"))
+	})
+}
+
+func TestRecordQueryExecution(t *testing.T) {
+	pubdash := &PublicDashboard{Uid: "pubdash", DashboardUid: "dash", OrgId: 3}
+	metricReq := dtos.MetricRequest{
+		Queries: []*simplejson.Json{
+			simplejson.MustJson([]byte(`{"datasource": {"type": "prometheus", "uid": "prom"}, "refId": "A", "expr": "up"}`)),
+		},
+	}
+
+	service := &PublicDashboardServiceImpl{queryHistory: newQueryHistory()}
+	service.recordQueryExecution(newQueryExecution("req1", pubdash, 2, metricReq), nil)
+	service.recordQueryExecution(newQueryExecution("req2", pubdash, 2, metricReq), errors.New("connection refused"))
+
+	executions, err := service.FindQueryExecutions(context.Background(), "req1")
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	assert.Equal(t, "pubdash", executions[0].PublicDashboardUid)
+	assert.Equal(t, "dash", executions[0].DashboardUid)
+	assert.Equal(t, int64(3), executions[0].OrgId)
+	assert.Equal(t, int64(2), executions[0].PanelId)
+	assert.Equal(t, QuerySuccess, executions[0].Status)
+	require.Len(t, executions[0].Queries, 1)
+	assert.Equal(t, "A", executions[0].Queries[0].RefId)
+	assert.Equal(t, "prom", executions[0].Queries[0].DatasourceUid)
+	assert.Equal(t, "prometheus", executions[0].Queries[0].DatasourceType)
+	assert.Equal(t, "up", executions[0].Queries[0].Model.Get("expr").MustString())
+
+	executions, err = service.FindQueryExecutions(context.Background(), "req2")
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	assert.Equal(t, QueryFailure, executions[0].Status)
+	assert.Equal(t, "connection refused", executions[0].Error)
+}
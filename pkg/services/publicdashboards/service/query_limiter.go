@@ -0,0 +1,50 @@
+package service
+
+import (
+	"sync"
+)
+
+// queryLimiter is a counting semaphore per access token capping the number of in-flight queries of a public dashboard
+type queryLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	inFlight map[string]int
+}
+
+func newQueryLimiter(limit int) *queryLimiter {
+	return &queryLimiter{
+		limit:    limit,
+		inFlight: make(map[string]int),
+	}
+}
+
+// tryAcquire reserves a query slot for the access token. It returns false without blocking if the limit is reached
+func (l *queryLimiter) tryAcquire(accessToken string) bool {
+	if l == nil || l.limit <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight[accessToken] >= l.limit {
+		return false
+	}
+	l.inFlight[accessToken]++
+	return true
+}
+
+// release frees a query slot previously reserved with tryAcquire
+func (l *queryLimiter) release(accessToken string) {
+	if l == nil || l.limit <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight[accessToken]--
+	if l.inFlight[accessToken] <= 0 {
+		delete(l.inFlight, accessToken)
+	}
+}
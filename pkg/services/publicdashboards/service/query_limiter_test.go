@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryLimiter(t *testing.T) {
+	t.Run("caps in-flight queries per access token", func(t *testing.T) {
+		limiter := newQueryLimiter(2)
+
+		assert.True(t, limiter.tryAcquire("token1"))
+		assert.True(t, limiter.tryAcquire("token1"))
+		assert.False(t, limiter.tryAcquire("token1"))
+		assert.True(t, limiter.tryAcquire("token2"))
+
+		limiter.release("token1")
+		assert.True(t, limiter.tryAcquire("token1"))
+	})
+
+	t.Run("forgets access tokens without in-flight queries", func(t *testing.T) {
+		limiter := newQueryLimiter(1)
+
+		assert.True(t, limiter.tryAcquire("token1"))
+		limiter.release("token1")
+		assert.Empty(t, limiter.inFlight)
+	})
+
+	t.Run("does not limit queries when the limit is 0", func(t *testing.T) {
+		limiter := newQueryLimiter(0)
+		for i := 0; i < 100; i++ {
+			assert.True(t, limiter.tryAcquire("token1"))
+		}
+	})
+
+	t.Run("does not limit queries when not configured", func(t *testing.T) {
+		var limiter *queryLimiter
+		assert.True(t, limiter.tryAcquire("token1"))
+		limiter.release("token1")
+	})
+}
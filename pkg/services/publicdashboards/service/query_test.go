@@ -3,10 +3,16 @@ package service
 import (
 	"context"
 	"errors"
+	"net"
+	"net/http"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	dashboard2 "github.com/grafana/grafana/pkg/coremodel/dashboard"
 	"github.com/grafana/grafana/pkg/infra/db"
@@ -22,6 +28,7 @@ import (
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 
@@ -405,6 +412,301 @@ func TestGetQueryDataResponse(t *testing.T) {
 		resp, _ := service.GetQueryDataResponse(context.Background(), true, publicDashboardQueryDTO, 1, pubdashDto.AccessToken)
 		require.Nil(t, resp)
 	})
+
+	t.Run("Returns ErrPublicDashboardRateLimited when too many queries are in flight", func(t *testing.T) {
+		query := map[string]interface{}{
+			"datasource": map[string]interface{}{
+				"type": "mysql",
+				"uid":  "ds1",
+			},
+			"refId": "A",
+		}
+		customPanels := []interface{}{
+			map[string]interface{}{
+				"id": 1,
+				"datasource": map[string]interface{}{
+					"uid": "ds1",
+				},
+				"targets": []interface{}{query},
+			}}
+
+		dashboard := insertTestDashboard(t, dashboardStore, "testDashRateLimited", 1, 0, true, []map[string]interface{}{}, customPanels)
+		dto := &SavePublicDashboardConfigDTO{
+			DashboardUid: dashboard.Uid,
+			OrgId:        dashboard.OrgId,
+			UserId:       7,
+			PublicDashboard: &PublicDashboard{
+				IsEnabled:    true,
+				TimeSettings: timeSettings,
+			},
+		}
+		pubdashDto, err := service.Save(context.Background(), SignedInUser, dto)
+		require.NoError(t, err)
+
+		service.queryLimiter = newQueryLimiter(1)
+		t.Cleanup(func() { service.queryLimiter = nil })
+		require.True(t, service.queryLimiter.tryAcquire(pubdashDto.AccessToken))
+
+		resp, err := service.GetQueryDataResponse(context.Background(), true, publicDashboardQueryDTO, 1, pubdashDto.AccessToken)
+		require.Nil(t, resp)
+		require.ErrorIs(t, err, ErrPublicDashboardRateLimited)
+	})
+
+	savePanelPublicDashboard := func(t *testing.T, title string, queries ...interface{}) *PublicDashboard {
+		customPanels := []interface{}{
+			map[string]interface{}{
+				"id":      1,
+				"targets": queries,
+			}}
+
+		dashboard := insertTestDashboard(t, dashboardStore, title, 1, 0, true, []map[string]interface{}{}, customPanels)
+		pubdash, err := service.Save(context.Background(), SignedInUser, &SavePublicDashboardConfigDTO{
+			DashboardUid: dashboard.Uid,
+			OrgId:        dashboard.OrgId,
+			UserId:       7,
+			PublicDashboard: &PublicDashboard{
+				IsEnabled:    true,
+				TimeSettings: timeSettings,
+			},
+		})
+		require.NoError(t, err)
+		return pubdash
+	}
+
+	newQuery := func(refId string, datasourceUid string) map[string]interface{} {
+		return map[string]interface{}{
+			"datasource": map[string]interface{}{
+				"type": "mysql",
+				"uid":  datasourceUid,
+			},
+			"refId": refId,
+		}
+	}
+
+	t.Run("Queries as an anonymous user of the dashboard org and forwards skipCache and the request ID", func(t *testing.T) {
+		pubdash := savePanelPublicDashboard(t, "testDashSkipCache", newQuery("A", "ds1"))
+
+		queryDataService := NewFakeQueryDataExecutor(t)
+		service.QueryDataService = queryDataService
+		t.Cleanup(func() { service.QueryDataService = nil })
+
+		queryDataService.On("QueryData", mock.MatchedBy(func(ctx context.Context) bool {
+			return QueryRequestIdFromContext(ctx) == "abc123" && len(httpclient.ContextualMiddlewareFromContext(ctx)) == 1
+		}), mock.MatchedBy(func(u *user.SignedInUser) bool {
+			return u.OrgID == 1 && u.UserID == 0 && u.Login == ""
+		}), false, mock.MatchedBy(func(req dtos.MetricRequest) bool {
+			return len(req.Queries) == 1 && req.Queries[0].Get("refId").MustString() == "A"
+		})).Return(&backend.QueryDataResponse{Responses: backend.Responses{"A": {}}}, nil)
+
+		resp, err := service.GetQueryDataResponse(WithQueryRequestId(context.Background(), "abc123"), false, publicDashboardQueryDTO, 1, pubdash.AccessToken)
+		require.NoError(t, err)
+		require.Contains(t, resp.Responses, "A")
+	})
+
+	t.Run("Redacts the query metadata of the response", func(t *testing.T) {
+		pubdash := savePanelPublicDashboard(t, "testDashRedaction", newQuery("A", "ds1"))
+
+		queryDataService := NewFakeQueryDataExecutor(t)
+		service.QueryDataService = queryDataService
+		t.Cleanup(func() { service.QueryDataService = nil })
+
+		queryDataService.On("QueryData", mock.Anything, mock.Anything, true, mock.Anything).Return(&backend.QueryDataResponse{Responses: backend.Responses{
+			"A": {Frames: data.Frames{&data.Frame{Name: "A", Meta: &data.FrameMeta{ExecutedQueryString: "SELECT secret", Custom: map[string]string{"k": "v"}}}}},
+		}}, nil)
+
+		resp, err := service.GetQueryDataResponse(context.Background(), true, publicDashboardQueryDTO, 1, pubdash.AccessToken)
+		require.NoError(t, err)
+		meta := resp.Responses["A"].Frames[0].Meta
+		assert.Empty(t, meta.ExecutedQueryString)
+		assert.Nil(t, meta.Custom)
+	})
+
+	t.Run("Returns the error of the query data service and records the failed execution", func(t *testing.T) {
+		pubdash := savePanelPublicDashboard(t, "testDashQueryError", newQuery("A", "ds1"))
+
+		queryDataService := NewFakeQueryDataExecutor(t)
+		service.QueryDataService = queryDataService
+		service.queryHistory = newQueryHistory()
+		t.Cleanup(func() {
+			service.QueryDataService = nil
+			service.queryHistory = nil
+		})
+
+		queryErr := errors.New("connection refused")
+		queryDataService.On("QueryData", mock.Anything, mock.Anything, true, mock.Anything).Return(nil, queryErr)
+
+		resp, err := service.GetQueryDataResponse(context.Background(), true, publicDashboardQueryDTO, 1, pubdash.AccessToken)
+		require.Nil(t, resp)
+		require.ErrorIs(t, err, queryErr)
+
+		executions, err := service.FindQueryExecutions(context.Background(), "")
+		require.NoError(t, err)
+		require.Len(t, executions, 1)
+		assert.Equal(t, QueryFailure, executions[0].Status)
+		assert.Equal(t, pubdash.Uid, executions[0].PublicDashboardUid)
+	})
+
+	t.Run("Rejects viewers from restricted countries before running the queries", func(t *testing.T) {
+		pubdash := savePanelPublicDashboard(t, "testDashCountryRestricted", newQuery("A", "ds1"))
+		_, err := service.Save(context.Background(), SignedInUser, &SavePublicDashboardConfigDTO{
+			DashboardUid: pubdash.DashboardUid,
+			OrgId:        1,
+			UserId:       7,
+			PublicDashboard: &PublicDashboard{
+				Uid:              pubdash.Uid,
+				IsEnabled:        true,
+				TimeSettings:     timeSettings,
+				AllowedCountries: CountryList{"fr"},
+			},
+		})
+		require.NoError(t, err)
+
+		geoIPResolver := NewFakeGeoIPResolver(t)
+		service.geoIPResolver = geoIPResolver
+		// no expectations, the queries must not run
+		service.QueryDataService = NewFakeQueryDataExecutor(t)
+		t.Cleanup(func() {
+			service.geoIPResolver = nil
+			service.QueryDataService = nil
+		})
+
+		viewerIP := net.ParseIP("192.0.2.1")
+		geoIPResolver.On("Country", mock.Anything, viewerIP).Return("DE", nil)
+
+		resp, err := service.GetQueryDataResponse(WithViewerIP(context.Background(), viewerIP), false, publicDashboardQueryDTO, 1, pubdash.AccessToken)
+		require.Nil(t, resp)
+		require.ErrorIs(t, err, ErrPublicDashboardCountryRestricted)
+	})
+
+	t.Run("Returns partial results when continuing on query errors", func(t *testing.T) {
+		pubdash := savePanelPublicDashboard(t, "testDashPartialErrors", newQuery("A", "healthy"), newQuery("B", "broken"))
+
+		queryDataService := NewFakeQueryDataExecutor(t)
+		service.QueryDataService = queryDataService
+		service.cfg = setting.NewCfg()
+		service.cfg.PublicDashboards.ContinueOnQueryError = true
+		t.Cleanup(func() {
+			service.QueryDataService = nil
+			service.cfg = nil
+		})
+
+		isDataSource := func(uid string) interface{} {
+			return mock.MatchedBy(func(req dtos.MetricRequest) bool {
+				return getDataSourceUidFromJson(req.Queries[0]) == uid
+			})
+		}
+		queryDataService.On("QueryData", mock.Anything, mock.Anything, true, isDataSource("healthy")).
+			Return(&backend.QueryDataResponse{Responses: backend.Responses{"A": {Frames: data.Frames{data.NewFrame("healthy")}}}}, nil)
+		queryDataService.On("QueryData", mock.Anything, mock.Anything, true, isDataSource("broken")).
+			Return(nil, errors.New("connection refused"))
+
+		resp, err := service.GetQueryDataResponse(context.Background(), true, publicDashboardQueryDTO, 1, pubdash.AccessToken)
+		require.NoError(t, err)
+		require.Len(t, resp.Responses, 2)
+		assert.NoError(t, resp.Responses["A"].Error)
+		assert.Equal(t, "healthy", resp.Responses["A"].Frames[0].Name)
+		assert.Equal(t, ErrPublicDashboardQueryFailed, resp.Responses["B"].Error)
+	})
+
+	t.Run("Applies the panel transformations when requested", func(t *testing.T) {
+		customPanels := []interface{}{
+			map[string]interface{}{
+				"id":              1,
+				"targets":         []interface{}{newQuery("A", "ds1")},
+				"transformations": []interface{}{map[string]interface{}{"id": "organize", "options": map[string]interface{}{"excludeByName": map[string]interface{}{"secret": true}}}},
+			}}
+		dashboard := insertTestDashboard(t, dashboardStore, "testDashTransformations", 1, 0, true, []map[string]interface{}{}, customPanels)
+		pubdash, err := service.Save(context.Background(), SignedInUser, &SavePublicDashboardConfigDTO{
+			DashboardUid:    dashboard.Uid,
+			OrgId:           dashboard.OrgId,
+			UserId:          7,
+			PublicDashboard: &PublicDashboard{IsEnabled: true, TimeSettings: timeSettings},
+		})
+		require.NoError(t, err)
+
+		queryDataService := NewFakeQueryDataExecutor(t)
+		service.QueryDataService = queryDataService
+		t.Cleanup(func() { service.QueryDataService = nil })
+
+		queryDataService.On("QueryData", mock.Anything, mock.Anything, true, mock.Anything).Return(func(context.Context, *user.SignedInUser, bool, dtos.MetricRequest) *backend.QueryDataResponse {
+			return &backend.QueryDataResponse{Responses: backend.Responses{
+				"A": {Frames: data.Frames{data.NewFrame("A", data.NewField("value", nil, []int64{1}), data.NewField("secret", nil, []int64{2}))}},
+			}}
+		}, nil)
+
+		resp, err := service.GetQueryDataResponse(context.Background(), true, publicDashboardQueryDTO, 1, pubdash.AccessToken)
+		require.NoError(t, err)
+		assert.Len(t, resp.Responses["A"].Frames[0].Fields, 2)
+
+		transformedQueryDTO := publicDashboardQueryDTO
+		transformedQueryDTO.ApplyTransformations = true
+		resp, err = service.GetQueryDataResponse(context.Background(), true, transformedQueryDTO, 1, pubdash.AccessToken)
+		require.NoError(t, err)
+		require.Len(t, resp.Responses["A"].Frames[0].Fields, 1)
+		assert.Equal(t, "value", resp.Responses["A"].Frames[0].Fields[0].Name)
+	})
+
+	t.Run("Rejects comparisons to the previous period on panels the owner did not enable it on", func(t *testing.T) {
+		pubdash := savePanelPublicDashboard(t, "testDashPreviousPeriodDisabled", newQuery("A", "ds1"))
+
+		// no expectations, the queries must not run
+		service.QueryDataService = NewFakeQueryDataExecutor(t)
+		t.Cleanup(func() { service.QueryDataService = nil })
+
+		comparedQueryDTO := publicDashboardQueryDTO
+		comparedQueryDTO.ComparePreviousPeriod = true
+		resp, err := service.GetQueryDataResponse(context.Background(), true, comparedQueryDTO, 1, pubdash.AccessToken)
+		require.Nil(t, resp)
+		require.ErrorIs(t, err, ErrPublicDashboardPreviousPeriodNotEnabled)
+	})
+
+	t.Run("Adds the aligned frames of the previous period when requested", func(t *testing.T) {
+		pubdash := savePanelPublicDashboard(t, "testDashPreviousPeriod", newQuery("A", "ds1"))
+		_, err := service.Save(context.Background(), SignedInUser, &SavePublicDashboardConfigDTO{
+			DashboardUid: pubdash.DashboardUid,
+			OrgId:        1,
+			UserId:       7,
+			PublicDashboard: &PublicDashboard{
+				Uid:                  pubdash.Uid,
+				IsEnabled:            true,
+				TimeSettings:         timeSettings,
+				PreviousPeriodPanels: PanelIdList{1},
+			},
+		})
+		require.NoError(t, err)
+
+		queryDataService := NewFakeQueryDataExecutor(t)
+		service.QueryDataService = queryDataService
+		t.Cleanup(func() { service.QueryDataService = nil })
+
+		from := time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC)
+		to := from.Add(12 * time.Hour)
+		isTimeRange := func(from, to time.Time) interface{} {
+			return mock.MatchedBy(func(req dtos.MetricRequest) bool {
+				return req.From == strconv.FormatInt(from.UnixMilli(), 10) && req.To == strconv.FormatInt(to.UnixMilli(), 10)
+			})
+		}
+		queryDataService.On("QueryData", mock.Anything, mock.Anything, true, isTimeRange(from, to)).
+			Return(&backend.QueryDataResponse{Responses: backend.Responses{
+				"A": {Frames: data.Frames{data.NewFrame("A", data.NewField("time", nil, []time.Time{from}), data.NewField("value", nil, []int64{2}))}},
+			}}, nil)
+		queryDataService.On("QueryData", mock.Anything, mock.Anything, true, isTimeRange(from.Add(-12*time.Hour), from)).
+			Return(&backend.QueryDataResponse{Responses: backend.Responses{
+				"A": {Frames: data.Frames{data.NewFrame("A", data.NewField("time", nil, []time.Time{from.Add(-12 * time.Hour)}), data.NewField("value", nil, []int64{1}))}},
+			}}, nil)
+
+		comparedQueryDTO := publicDashboardQueryDTO
+		comparedQueryDTO.ComparePreviousPeriod = true
+		resp, err := service.GetQueryDataResponse(context.Background(), true, comparedQueryDTO, 1, pubdash.AccessToken)
+		require.NoError(t, err)
+
+		frames := resp.Responses["A"].Frames
+		require.Len(t, frames, 2)
+		assert.Empty(t, frames[0].Fields[1].Labels)
+		assert.Equal(t, from, frames[1].Fields[0].At(0))
+		assert.Equal(t, int64(1), frames[1].Fields[1].At(0))
+		assert.Equal(t, PreviousPeriodLabel, frames[1].Fields[1].Labels["period"])
+	})
 }
 
 func TestGetAnnotations(t *testing.T) {
@@ -699,6 +1001,49 @@ func TestGetAnnotations(t *testing.T) {
 		assert.Empty(t, items)
 	})
 
+	t.Run("test will skip events of panels annotations are disabled on", func(t *testing.T) {
+		annotationsRepo := annotations.FakeAnnotationsRepo{}
+		fakeStore := FakePublicDashboardStore{}
+		service := &PublicDashboardServiceImpl{
+			log:             log.New("test.logger"),
+			store:           &fakeStore,
+			AnnotationsRepo: &annotationsRepo,
+		}
+		dash := grafanamodels.NewDashboard("test")
+		grafanaAnnotation := DashAnnotation{
+			Datasource: CreateDatasource("grafana", "grafana"),
+			Enable:     true,
+			Name:       &name,
+			IconColor:  &color,
+			Target: &dashboard2.AnnotationTarget{
+				Limit:    100,
+				MatchAny: false,
+				Tags:     nil,
+				Type:     "dashboard",
+			},
+			Type: "dashboard",
+		}
+		dashboard := AddAnnotationsToDashboard(t, dash, []DashAnnotation{grafanaAnnotation})
+		pubdash := &PublicDashboard{Uid: "uid1", IsEnabled: true, OrgId: 1, DashboardUid: dashboard.Uid, AnnotationsEnabled: true, AnnotationsDisabledPanels: PanelIdList{2}}
+
+		fakeStore.On("FindByAccessToken", mock.Anything, mock.AnythingOfType("string")).Return(pubdash, nil)
+		fakeStore.On("FindDashboard", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(dashboard, nil)
+		annotationsRepo.On("Find", mock.Anything, mock.Anything).Return([]*annotations.ItemDTO{
+			{Id: 1, DashboardId: 1, PanelId: 1, Text: "panel 1"},
+			{Id: 2, DashboardId: 1, PanelId: 2, Text: "panel 2"},
+			{Id: 3, DashboardId: 1, PanelId: 0, Text: "all panels"},
+		}, nil)
+
+		items, err := service.FindAnnotations(context.Background(), AnnotationsQueryDTO{}, "abc123")
+		require.NoError(t, err)
+
+		ids := make([]int64, 0, len(items))
+		for _, item := range items {
+			ids = append(ids, item.Id)
+		}
+		assert.ElementsMatch(t, []int64{1, 3}, ids)
+	})
+
 	t.Run("test will error when annotations repo returns an error", func(t *testing.T) {
 		annotationsRepo := annotations.FakeAnnotationsRepo{}
 		fakeStore := FakePublicDashboardStore{}
@@ -1229,6 +1574,54 @@ func TestGroupQueriesByDataSource(t *testing.T) {
 	})
 }
 
+func TestQueryDataContinueOnError(t *testing.T) {
+	t.Run("returns healthy series and an error response per refId of the failing data source", func(t *testing.T) {
+		metricReq := dtos.MetricRequest{
+			From: "now-1h",
+			To:   "now",
+			Queries: []*simplejson.Json{
+				simplejson.MustJson([]byte(`{"datasource": {"type": "prometheus", "uid": "healthy"}, "refId": "A"}`)),
+				simplejson.MustJson([]byte(`{"datasource": {"type": "mysql", "uid": "broken"}, "refId": "B"}`)),
+				simplejson.MustJson([]byte(`{"datasource": {"type": "mysql", "uid": "broken"}, "refId": "C"}`)),
+			},
+		}
+
+		resp := queryDataContinueOnError(metricReq, "abc123", log.New("test.logger"), func(req dtos.MetricRequest) (*backend.QueryDataResponse, error) {
+			if getDataSourceUidFromJson(req.Queries[0]) == "broken" {
+				return nil, errors.New("connection refused")
+			}
+			return &backend.QueryDataResponse{Responses: backend.Responses{
+				"A": {Frames: data.Frames{data.NewFrame("healthy")}},
+			}}, nil
+		})
+
+		require.Len(t, resp.Responses, 3)
+		assert.NoError(t, resp.Responses["A"].Error)
+		assert.Equal(t, "healthy", resp.Responses["A"].Frames[0].Name)
+
+		for _, refId := range []string{"B", "C"} {
+			assert.Equal(t, ErrPublicDashboardQueryFailed, resp.Responses[refId].Error)
+			require.Len(t, resp.Responses[refId].Frames, 1)
+			assert.Equal(t, refId, resp.Responses[refId].Frames[0].RefID)
+			assert.Contains(t, resp.Responses[refId].Frames[0].Meta.Notices[0].Text, "abc123")
+		}
+	})
+}
+
+func TestQueryRequestIdMiddleware(t *testing.T) {
+	var forwardedRequestId string
+	next := httpclient.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		forwardedRequestId = req.Header.Get(QueryRequestIdHeader)
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+
+	req, err := http.NewRequest(http.MethodGet, "http://datasource", nil)
+	require.NoError(t, err)
+	_, err = queryRequestIdMiddleware("abc123").CreateMiddleware(httpclient.Options{}, next).RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", forwardedRequestId)
+}
+
 func TestSanitizeMetadataFromQueryData(t *testing.T) {
 	t.Run("can remove metadata from query", func(t *testing.T) {
 		fakeResponse := &backend.QueryDataResponse{
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// FindReportSubscription returns the report subscription of the signed in user
+func (pd *PublicDashboardServiceImpl) FindReportSubscription(ctx context.Context, u *user.SignedInUser) (*PublicDashboardReportSubscription, error) {
+	subscription, err := pd.reportStore.FindSubscription(ctx, u.OrgID, u.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if subscription == nil {
+		return nil, ErrPublicDashboardReportSubscriptionNotFound
+	}
+
+	return subscription, nil
+}
+
+// SaveReportSubscription subscribes the signed in user to the weekly usage and error digest of the public
+// dashboards they created. Subscribing again refreshes the email address the digest is sent to
+func (pd *PublicDashboardServiceImpl) SaveReportSubscription(ctx context.Context, u *user.SignedInUser) (*PublicDashboardReportSubscription, error) {
+	if pd.cfg == nil || !pd.cfg.Smtp.Enabled {
+		return nil, ErrPublicDashboardReportEmailNotConfigured
+	}
+
+	if u.Email == "" {
+		return nil, ErrPublicDashboardReportNoEmail
+	}
+
+	subscription, err := pd.reportStore.FindSubscription(ctx, u.OrgID, u.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if subscription != nil {
+		subscription.Email = u.Email
+		if err := pd.reportStore.UpdateSubscription(ctx, subscription); err != nil {
+			return nil, err
+		}
+		return subscription, nil
+	}
+
+	// the first digest covers the week following the subscription
+	now := time.Now()
+	subscription = &PublicDashboardReportSubscription{
+		OrgId:      u.OrgID,
+		UserId:     u.UserID,
+		Email:      u.Email,
+		LastSentAt: now,
+		CreatedAt:  now,
+	}
+	if err := pd.reportStore.SaveSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// DeleteReportSubscription unsubscribes the signed in user from the report digest
+func (pd *PublicDashboardServiceImpl) DeleteReportSubscription(ctx context.Context, u *user.SignedInUser) error {
+	return pd.reportStore.DeleteSubscription(ctx, u.OrgID, u.UserID)
+}
+
+// recordUsage counts a query of a public dashboard for the report digests. The count is written by the usage tracker
+// in the background, off the path of the query
+func (pd *PublicDashboardServiceImpl) recordUsage(publicDashboard *PublicDashboard, failed bool) {
+	pd.usageTracker.record(publicDashboard, failed, time.Now())
+}
+
+// RecordView counts a view of a public dashboard for the usage stats. Failing to do so does not fail the view
+func (pd *PublicDashboardServiceImpl) RecordView(ctx context.Context, publicDashboard *PublicDashboard, anonymous bool) {
+	if pd.reportStore == nil {
+		return
+	}
+
+	day := time.Now().UTC().Format(UsageDayFormat)
+	if err := pd.reportStore.RecordView(ctx, publicDashboard.OrgId, publicDashboard.Uid, day, anonymous); err != nil {
+		pd.log.FromContext(ctx).Warn("Failed to record public dashboard view", "publicDashboardUid", publicDashboard.Uid, "error", err)
+	}
+}
+
+// getUsageMetrics reports the usage of the public dashboards of the instance over the last whole day
+func (pd *PublicDashboardServiceImpl) getUsageMetrics(ctx context.Context) (map[string]interface{}, error) {
+	today := time.Now().UTC()
+	stats, err := pd.reportStore.GetUsageStats(ctx, today.AddDate(0, 0, -1).Format(UsageDayFormat), today.Format(UsageDayFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"stats.public_dashboards.views.count":           stats.ViewCount,
+		"stats.public_dashboards.views.anonymous.count": stats.AnonymousViewCount,
+		"stats.public_dashboards.views.signed_in.count": stats.ViewCount - stats.AnonymousViewCount,
+		"stats.public_dashboards.queries.count":         stats.QueryCount,
+		"stats.public_dashboards.queries.failed.count":  stats.ErrorCount,
+		"stats.public_dashboards.viewed.count":          stats.ViewedCount,
+		"stats.public_dashboards.top_views.count":       stats.TopViewCount,
+	}, nil
+}
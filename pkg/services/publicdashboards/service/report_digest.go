@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	reportDigestPeriod        = 7 * 24 * time.Hour
+	reportDigestCheckInterval = time.Hour
+	reportDigestEmailTemplate = "public_dashboard_report"
+	reportDigestDateFormat    = "Jan 2, 2006"
+)
+
+// ReportDigestService is a background service emailing the weekly usage and error digest of their public
+// dashboards to the subscribed users
+type ReportDigestService struct {
+	log         log.Logger
+	cfg         *setting.Cfg
+	features    featuremgmt.FeatureToggles
+	reportStore publicdashboards.ReportStore
+	emailSender notifications.EmailSender
+	serverLock  *serverlock.ServerLockService
+}
+
+// ProvideReportDigestService Factory for method used by wire to inject dependencies
+func ProvideReportDigestService(
+	cfg *setting.Cfg,
+	features featuremgmt.FeatureToggles,
+	reportStore publicdashboards.ReportStore,
+	emailSender notifications.EmailSender,
+	serverLock *serverlock.ServerLockService,
+) *ReportDigestService {
+	return &ReportDigestService{
+		log:         log.New("publicdashboards.report"),
+		cfg:         cfg,
+		features:    features,
+		reportStore: reportStore,
+		emailSender: emailSender,
+		serverLock:  serverLock,
+	}
+}
+
+// IsDisabled digests are only sent when public dashboards and SMTP are enabled
+func (s *ReportDigestService) IsDisabled() bool {
+	return !s.features.IsEnabled(featuremgmt.FlagPublicDashboards) || s.cfg == nil || !s.cfg.Smtp.Enabled
+}
+
+func (s *ReportDigestService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(reportDigestCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.sendDueDigestsWithLock(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// sendDueDigestsWithLock makes sure a single instance sends the digests when running Grafana in HA
+func (s *ReportDigestService) sendDueDigestsWithLock(ctx context.Context) {
+	logger := s.log.FromContext(ctx)
+	err := s.serverLock.LockAndExecute(ctx, "send public dashboard report digests", reportDigestCheckInterval, func(ctx context.Context) {
+		if err := s.SendDueDigests(ctx, time.Now()); err != nil {
+			logger.Error("Failed to send public dashboard report digests", "error", err)
+		}
+	})
+	if err != nil {
+		logger.Error("Failed to lock and execute sending of public dashboard report digests", "error", err)
+	}
+}
+
+// SendDueDigests emails the digest of every subscription whose last digest is at least a week old
+func (s *ReportDigestService) SendDueDigests(ctx context.Context, now time.Time) error {
+	subscriptions, err := s.reportStore.FindDueSubscriptions(ctx, now.Add(-reportDigestPeriod))
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range subscriptions {
+		// a failing subscription is retried on the next run and does not block the others
+		if err := s.sendDigest(ctx, subscription, now); err != nil {
+			s.log.FromContext(ctx).Error("Failed to send public dashboard report digest", "subscriptionId", subscription.Id, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *ReportDigestService) sendDigest(ctx context.Context, subscription *PublicDashboardReportSubscription, now time.Time) error {
+	digest, err := s.BuildDigest(ctx, subscription, now)
+	if err != nil {
+		return err
+	}
+
+	// users without public dashboards are not sent empty digests
+	if len(digest.Dashboards) > 0 {
+		err = s.emailSender.SendEmailCommandHandler(ctx, &models.SendEmailCommand{
+			To:       []string{subscription.Email},
+			Template: reportDigestEmailTemplate,
+			Data: map[string]interface{}{
+				"From":       digest.From.Format(reportDigestDateFormat),
+				"To":         digest.To.Format(reportDigestDateFormat),
+				"Dashboards": digest.Dashboards,
+				"QueryCount": digest.QueryCount,
+				"ErrorCount": digest.ErrorCount,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	subscription.LastSentAt = now
+	return s.reportStore.UpdateSubscription(ctx, subscription)
+}
+
+// BuildDigest aggregates the usage of the public dashboards of the subscribed user over the whole days since
+// the last digest
+func (s *ReportDigestService) BuildDigest(ctx context.Context, subscription *PublicDashboardReportSubscription, now time.Time) (*PublicDashboardReportDigest, error) {
+	from := subscription.LastSentAt.UTC()
+	to := now.UTC()
+
+	summaries, err := s.reportStore.FindUsageSummaries(ctx, subscription.OrgId, subscription.UserId, from.Format(UsageDayFormat), to.Format(UsageDayFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	// the day of now is excluded, it is covered by the next digest
+	digest := &PublicDashboardReportDigest{From: from, To: to.AddDate(0, 0, -1), Dashboards: summaries}
+	for _, summary := range summaries {
+		digest.QueryCount += summary.QueryCount
+		digest.ErrorCount += summary.ErrorCount
+	}
+
+	return digest, nil
+}
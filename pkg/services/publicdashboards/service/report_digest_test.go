@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestSendDueDigests(t *testing.T) {
+	now := time.Date(2022, 10, 8, 9, 0, 0, 0, time.UTC)
+	lastSentAt := time.Date(2022, 10, 1, 8, 30, 0, 0, time.UTC)
+
+	newDigestService := func(t *testing.T) (*ReportDigestService, *FakePublicDashboardReportStore, *notifications.NotificationServiceMock) {
+		reportStore := NewFakePublicDashboardReportStore(t)
+		emailSender := notifications.MockNotificationService()
+		return &ReportDigestService{log: log.New("test.logger"), reportStore: reportStore, emailSender: emailSender}, reportStore, emailSender
+	}
+
+	t.Run("emails the usage of the week and moves the subscription forward", func(t *testing.T) {
+		service, reportStore, emailSender := newDigestService(t)
+
+		subscription := &PublicDashboardReportSubscription{Id: 1, OrgId: 1, UserId: 7, Email: "owner@example.com", LastSentAt: lastSentAt}
+		reportStore.On("FindDueSubscriptions", mock.Anything, now.Add(-reportDigestPeriod)).Return([]*PublicDashboardReportSubscription{subscription}, nil)
+		reportStore.On("FindUsageSummaries", mock.Anything, int64(1), int64(7), "2022-10-01", "2022-10-08").Return([]PublicDashboardUsageSummary{
+			{PublicDashboardUid: "pubdash1", DashboardUid: "dash1", Title: "a", IsEnabled: true, QueryCount: 10, ErrorCount: 2},
+			{PublicDashboardUid: "pubdash2", DashboardUid: "dash2", Title: "b", IsEnabled: true, QueryCount: 5},
+		}, nil)
+		reportStore.On("UpdateSubscription", mock.Anything, mock.MatchedBy(func(s *PublicDashboardReportSubscription) bool {
+			return s.Id == 1 && s.LastSentAt.Equal(now)
+		})).Return(nil)
+
+		err := service.SendDueDigests(context.Background(), now)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"owner@example.com"}, emailSender.Email.To)
+		assert.Equal(t, reportDigestEmailTemplate, emailSender.Email.Template)
+		assert.Equal(t, int64(15), emailSender.Email.Data["QueryCount"])
+		assert.Equal(t, int64(2), emailSender.Email.Data["ErrorCount"])
+		assert.Equal(t, "Oct 1, 2022", emailSender.Email.Data["From"])
+		assert.Equal(t, "Oct 7, 2022", emailSender.Email.Data["To"])
+	})
+
+	t.Run("does not email users without public dashboards", func(t *testing.T) {
+		service, reportStore, emailSender := newDigestService(t)
+
+		subscription := &PublicDashboardReportSubscription{Id: 1, OrgId: 1, UserId: 7, Email: "owner@example.com", LastSentAt: lastSentAt}
+		reportStore.On("FindDueSubscriptions", mock.Anything, mock.Anything).Return([]*PublicDashboardReportSubscription{subscription}, nil)
+		reportStore.On("FindUsageSummaries", mock.Anything, int64(1), int64(7), mock.Anything, mock.Anything).Return([]PublicDashboardUsageSummary{}, nil)
+		reportStore.On("UpdateSubscription", mock.Anything, subscription).Return(nil)
+
+		err := service.SendDueDigests(context.Background(), now)
+		require.NoError(t, err)
+		assert.Empty(t, emailSender.Email.To)
+	})
+
+	t.Run("keeps sending the other digests when one fails", func(t *testing.T) {
+		service, reportStore, emailSender := newDigestService(t)
+
+		failing := &PublicDashboardReportSubscription{Id: 1, OrgId: 1, UserId: 7, Email: "failing@example.com", LastSentAt: lastSentAt}
+		working := &PublicDashboardReportSubscription{Id: 2, OrgId: 1, UserId: 8, Email: "working@example.com", LastSentAt: lastSentAt}
+		reportStore.On("FindDueSubscriptions", mock.Anything, mock.Anything).Return([]*PublicDashboardReportSubscription{failing, working}, nil)
+		reportStore.On("FindUsageSummaries", mock.Anything, int64(1), int64(7), mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+		reportStore.On("FindUsageSummaries", mock.Anything, int64(1), int64(8), mock.Anything, mock.Anything).
+			Return([]PublicDashboardUsageSummary{{PublicDashboardUid: "pubdash1", QueryCount: 1}}, nil)
+		reportStore.On("UpdateSubscription", mock.Anything, working).Return(nil)
+
+		err := service.SendDueDigests(context.Background(), now)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"working@example.com"}, emailSender.Email.To)
+		assert.True(t, failing.LastSentAt.Equal(lastSentAt))
+	})
+
+	t.Run("keeps the subscription due when the email cannot be sent", func(t *testing.T) {
+		service, reportStore, emailSender := newDigestService(t)
+		emailSender.EmailHandler = func(context.Context, *models.SendEmailCommand) error { return errors.New("smtp error") }
+
+		subscription := &PublicDashboardReportSubscription{Id: 1, OrgId: 1, UserId: 7, Email: "owner@example.com", LastSentAt: lastSentAt}
+		reportStore.On("FindDueSubscriptions", mock.Anything, mock.Anything).Return([]*PublicDashboardReportSubscription{subscription}, nil)
+		reportStore.On("FindUsageSummaries", mock.Anything, int64(1), int64(7), mock.Anything, mock.Anything).
+			Return([]PublicDashboardUsageSummary{{PublicDashboardUid: "pubdash1", QueryCount: 1}}, nil)
+
+		err := service.SendDueDigests(context.Background(), now)
+		require.NoError(t, err)
+		reportStore.AssertNotCalled(t, "UpdateSubscription", mock.Anything, mock.Anything)
+	})
+}
+
+func TestReportDigestServiceIsDisabled(t *testing.T) {
+	smtpCfg := setting.NewCfg()
+	smtpCfg.Smtp.Enabled = true
+
+	assert.False(t, ProvideReportDigestService(smtpCfg, featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), nil, nil, nil).IsDisabled())
+	assert.True(t, ProvideReportDigestService(smtpCfg, featuremgmt.WithFeatures(), nil, nil, nil).IsDisabled())
+	assert.True(t, ProvideReportDigestService(setting.NewCfg(), featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), nil, nil, nil).IsDisabled())
+}
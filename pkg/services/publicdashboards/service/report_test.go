@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestSaveReportSubscription(t *testing.T) {
+	smtpCfg := setting.NewCfg()
+	smtpCfg.Smtp.Enabled = true
+	owner := &user.SignedInUser{OrgID: 1, UserID: 7, Email: "owner@example.com"}
+
+	t.Run("subscribes the user", func(t *testing.T) {
+		reportStore := NewFakePublicDashboardReportStore(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), cfg: smtpCfg, reportStore: reportStore}
+
+		reportStore.On("FindSubscription", mock.Anything, int64(1), int64(7)).Return(nil, nil)
+		reportStore.On("SaveSubscription", mock.Anything, mock.MatchedBy(func(s *PublicDashboardReportSubscription) bool {
+			return s.OrgId == 1 && s.UserId == 7 && s.Email == "owner@example.com" && !s.LastSentAt.IsZero()
+		})).Return(nil)
+
+		subscription, err := service.SaveReportSubscription(context.Background(), owner)
+		require.NoError(t, err)
+		assert.Equal(t, "owner@example.com", subscription.Email)
+	})
+
+	t.Run("refreshes the email address of an existing subscription", func(t *testing.T) {
+		reportStore := NewFakePublicDashboardReportStore(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), cfg: smtpCfg, reportStore: reportStore}
+
+		lastSentAt := time.Now().Add(-time.Hour)
+		reportStore.On("FindSubscription", mock.Anything, int64(1), int64(7)).
+			Return(&PublicDashboardReportSubscription{Id: 3, OrgId: 1, UserId: 7, Email: "old@example.com", LastSentAt: lastSentAt}, nil)
+		reportStore.On("UpdateSubscription", mock.Anything, mock.MatchedBy(func(s *PublicDashboardReportSubscription) bool {
+			return s.Id == 3 && s.Email == "owner@example.com" && s.LastSentAt.Equal(lastSentAt)
+		})).Return(nil)
+
+		_, err := service.SaveReportSubscription(context.Background(), owner)
+		require.NoError(t, err)
+	})
+
+	t.Run("returns ErrPublicDashboardReportEmailNotConfigured when SMTP is disabled", func(t *testing.T) {
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), cfg: setting.NewCfg()}
+
+		_, err := service.SaveReportSubscription(context.Background(), owner)
+		require.ErrorIs(t, err, ErrPublicDashboardReportEmailNotConfigured)
+	})
+
+	t.Run("returns ErrPublicDashboardReportNoEmail when the user has no email address", func(t *testing.T) {
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), cfg: smtpCfg}
+
+		_, err := service.SaveReportSubscription(context.Background(), &user.SignedInUser{OrgID: 1, UserID: 7})
+		require.ErrorIs(t, err, ErrPublicDashboardReportNoEmail)
+	})
+}
+
+func TestFindReportSubscription(t *testing.T) {
+	t.Run("returns ErrPublicDashboardReportSubscriptionNotFound when the user is not subscribed", func(t *testing.T) {
+		reportStore := NewFakePublicDashboardReportStore(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), reportStore: reportStore}
+
+		reportStore.On("FindSubscription", mock.Anything, int64(1), int64(7)).Return(nil, nil)
+
+		_, err := service.FindReportSubscription(context.Background(), &user.SignedInUser{OrgID: 1, UserID: 7})
+		require.ErrorIs(t, err, ErrPublicDashboardReportSubscriptionNotFound)
+	})
+}
+
+func TestRecordUsage(t *testing.T) {
+	t.Run("counts the query in the usage tracker", func(t *testing.T) {
+		reportStore := NewFakePublicDashboardReportStore(t)
+		tracker := ProvideUsageTracker(featuremgmt.WithFeatures(), reportStore)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), reportStore: reportStore, usageTracker: tracker}
+
+		service.recordUsage(&PublicDashboard{Uid: "pubdash1", OrgId: 1}, true)
+
+		// nothing is written until the tracker is flushed
+		reportStore.AssertNotCalled(t, "RecordUsage", mock.Anything, mock.Anything)
+
+		reportStore.On("RecordUsage", mock.Anything, &PublicDashboardUsage{
+			PublicDashboardUid: "pubdash1",
+			OrgId:              1,
+			Day:                time.Now().UTC().Format(UsageDayFormat),
+			QueryCount:         1,
+			ErrorCount:         1,
+		}).Return(nil).Once()
+		require.NoError(t, tracker.Flush(context.Background()))
+	})
+
+	t.Run("does not panic without usage tracker", func(t *testing.T) {
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger")}
+
+		service.recordUsage(&PublicDashboard{Uid: "pubdash1", OrgId: 1}, false)
+	})
+}
+
+func TestRecordView(t *testing.T) {
+	reportStore := NewFakePublicDashboardReportStore(t)
+	service := &PublicDashboardServiceImpl{log: log.New("test.logger"), reportStore: reportStore}
+
+	reportStore.On("RecordView", mock.Anything, int64(1), "pubdash1", time.Now().UTC().Format(UsageDayFormat), true).Return(errors.New("db error"))
+
+	service.RecordView(context.Background(), &PublicDashboard{Uid: "pubdash1", OrgId: 1}, true)
+}
+
+func TestGetUsageMetrics(t *testing.T) {
+	reportStore := NewFakePublicDashboardReportStore(t)
+	service := &PublicDashboardServiceImpl{log: log.New("test.logger"), reportStore: reportStore}
+
+	today := time.Now().UTC()
+	reportStore.On("GetUsageStats", mock.Anything, today.AddDate(0, 0, -1).Format(UsageDayFormat), today.Format(UsageDayFormat)).
+		Return(&PublicDashboardUsageStats{ViewCount: 10, AnonymousViewCount: 7, QueryCount: 40, ErrorCount: 2, ViewedCount: 3, TopViewCount: 6}, nil)
+
+	metrics, err := service.getUsageMetrics(context.Background())
+	require.NoError(t, err)
+	assert.EqualValues(t, 10, metrics["stats.public_dashboards.views.count"])
+	assert.EqualValues(t, 7, metrics["stats.public_dashboards.views.anonymous.count"])
+	assert.EqualValues(t, 3, metrics["stats.public_dashboards.views.signed_in.count"])
+	assert.EqualValues(t, 40, metrics["stats.public_dashboards.queries.count"])
+	assert.EqualValues(t, 2, metrics["stats.public_dashboards.queries.failed.count"])
+	assert.EqualValues(t, 3, metrics["stats.public_dashboards.viewed.count"])
+	assert.EqualValues(t, 6, metrics["stats.public_dashboards.top_views.count"])
+}
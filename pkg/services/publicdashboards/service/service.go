@@ -6,21 +6,27 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/internal/tokens"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
-	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 	"github.com/grafana/grafana/pkg/tsdb/legacydata"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 // PublicDashboardServiceImpl Define the Service Implementation. We're generating mock implementation
@@ -29,10 +35,28 @@ type PublicDashboardServiceImpl struct {
 	log                log.Logger
 	cfg                *setting.Cfg
 	store              publicdashboards.Store
+	playlistStore      publicdashboards.PlaylistStore
+	folderStore        publicdashboards.FolderStore
+	emailSessionStore  publicdashboards.EmailSessionStore
+	reportStore        publicdashboards.ReportStore
+	emailSender        notifications.EmailSender
 	intervalCalculator intervalv2.Calculator
-	QueryDataService   *query.Service
+	QueryDataService   publicdashboards.QueryDataExecutor
 	AnnotationsRepo    annotations.Repository
 	ac                 accesscontrol.AccessControl
+	queryHistory       *queryHistory
+	queryLimiter       *queryLimiter
+	geoIPResolver      publicdashboards.GeoIPResolver
+	dataSourceCache    datasources.CacheService
+	pluginStore        plugins.Store
+	lastUsedTracker    *LastUsedTracker
+	usageTracker       *UsageTracker
+	userService        user.Service
+	orgService         org.Service
+	dashboardService   dashboards.DashboardService
+
+	emailMagicLinkLifetime time.Duration
+	emailSessionLifetime   time.Duration
 }
 
 var LogPrefix = "publicdashboards.service"
@@ -46,19 +70,68 @@ var _ publicdashboards.Service = (*PublicDashboardServiceImpl)(nil)
 func ProvideService(
 	cfg *setting.Cfg,
 	store publicdashboards.Store,
-	qds *query.Service,
+	playlistStore publicdashboards.PlaylistStore,
+	folderStore publicdashboards.FolderStore,
+	emailSessionStore publicdashboards.EmailSessionStore,
+	reportStore publicdashboards.ReportStore,
+	emailSender notifications.EmailSender,
+	qds publicdashboards.QueryDataExecutor,
 	anno annotations.Repository,
 	ac accesscontrol.AccessControl,
+	usageStats usagestats.Service,
+	geoIPResolver publicdashboards.GeoIPResolver,
+	dataSourceCache datasources.CacheService,
+	pluginStore plugins.Store,
+	lastUsedTracker *LastUsedTracker,
+	usageTracker *UsageTracker,
+	userService user.Service,
+	orgService org.Service,
+	dashboardService dashboards.DashboardService,
+	bus bus.Bus,
 ) *PublicDashboardServiceImpl {
-	return &PublicDashboardServiceImpl{
+	maxConcurrentQueries := 0
+	var emailMagicLinkLifetime, emailSessionLifetime time.Duration
+	if cfg != nil {
+		maxConcurrentQueries = cfg.PublicDashboards.MaxConcurrentQueries
+		emailMagicLinkLifetime = cfg.PublicDashboards.EmailMagicLinkLifetime
+		emailSessionLifetime = cfg.PublicDashboards.EmailSessionLifetime
+	}
+
+	pd := &PublicDashboardServiceImpl{
 		log:                log.New(LogPrefix),
 		cfg:                cfg,
 		store:              store,
+		playlistStore:      playlistStore,
+		folderStore:        folderStore,
+		emailSessionStore:  emailSessionStore,
+		reportStore:        reportStore,
+		emailSender:        emailSender,
 		intervalCalculator: intervalv2.NewCalculator(),
 		QueryDataService:   qds,
 		AnnotationsRepo:    anno,
 		ac:                 ac,
+		queryHistory:       newQueryHistory(),
+		queryLimiter:       newQueryLimiter(maxConcurrentQueries),
+		geoIPResolver:      geoIPResolver,
+		dataSourceCache:    dataSourceCache,
+		pluginStore:        pluginStore,
+		lastUsedTracker:    lastUsedTracker,
+		usageTracker:       usageTracker,
+		userService:        userService,
+		orgService:         orgService,
+		dashboardService:   dashboardService,
+
+		emailMagicLinkLifetime: emailMagicLinkLifetime,
+		emailSessionLifetime:   emailSessionLifetime,
 	}
+
+	usageStats.RegisterMetricsFunc(pd.getUsageMetrics)
+	bus.AddEventListener(pd.handleDashboardFolderChanged)
+	bus.AddEventListener(pd.handleOrgUpdated)
+	bus.AddEventListener(pd.handleUserDeleted)
+	bus.AddEventListener(pd.handleUsersDeleted)
+
+	return pd
 }
 
 // FindDashboard Gets a dashboard by Uid
@@ -71,7 +144,8 @@ func (pd *PublicDashboardServiceImpl) FindDashboard(ctx context.Context, dashboa
 	return dashboard, nil
 }
 
-// FindPublicDashboardAndDashboardByAccessToken Gets public dashboard via access token
+// FindPublicDashboardAndDashboardByAccessToken Gets public dashboard via access token. Returns
+// ErrPublicDashboardCountryRestricted when the viewer is not allowed to view it from their country
 func (pd *PublicDashboardServiceImpl) FindPublicDashboardAndDashboardByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, *models.Dashboard, error) {
 	ctxLogger := pd.log.FromContext(ctx)
 
@@ -87,7 +161,11 @@ func (pd *PublicDashboardServiceImpl) FindPublicDashboardAndDashboardByAccessTok
 
 	if !pubdash.IsEnabled {
 		ctxLogger.Error("FindPublicDashboardAndDashboardByAccessToken: Public dashboard is disabled", "accessToken", accessToken)
-		return nil, nil, ErrPublicDashboardNotFound
+		return nil, nil, ErrPublicDashboardDisabled
+	}
+
+	if err := pd.checkCountryAccess(ctx, pubdash); err != nil {
+		return nil, nil, err
 	}
 
 	dash, err := pd.store.FindDashboard(ctx, pubdash.DashboardUid, pubdash.OrgId)
@@ -100,6 +178,8 @@ func (pd *PublicDashboardServiceImpl) FindPublicDashboardAndDashboardByAccessTok
 		return nil, nil, ErrPublicDashboardNotFound
 	}
 
+	pd.lastUsedTracker.record(pubdash, time.Now())
+
 	return pubdash, dash, nil
 }
 
@@ -116,10 +196,61 @@ func (pd *PublicDashboardServiceImpl) FindByDashboardUid(ctx context.Context, or
 // Save is a helper method to persist the sharing config
 // to the database. It handles validations for sharing config and persistence
 func (pd *PublicDashboardServiceImpl) Save(ctx context.Context, u *user.SignedInUser, dto *SavePublicDashboardConfigDTO) (*PublicDashboard, error) {
+	cmd, existingPubdash, err := pd.prepareSave(ctx, u, dto)
+	if err != nil {
+		return nil, err
+	}
+
+	// save changes
+	if existingPubdash == nil {
+		err = pd.store.Save(ctx, cmd)
+	} else {
+		err = pd.store.Update(ctx, cmd)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	//Get latest public dashboard to return
+	newPubdash, err := pd.store.Find(ctx, cmd.PublicDashboard.Uid)
+	if err != nil {
+		return nil, err
+	}
+
+	pd.logIsEnabledChanged(existingPubdash, newPubdash, u)
+
+	return newPubdash, err
+}
+
+// Preview runs the validation of Save and returns the public dashboard configuration it would save, without
+// persisting it. The uid and access token of a new public dashboard are not reserved, saving it generates new ones
+func (pd *PublicDashboardServiceImpl) Preview(ctx context.Context, u *user.SignedInUser, dto *SavePublicDashboardConfigDTO) (*PublicDashboard, error) {
+	cmd, existingPubdash, err := pd.prepareSave(ctx, u, dto)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := cmd.PublicDashboard
+	if existingPubdash != nil {
+		// updates leave the dashboard, access token and creation of the public dashboard untouched
+		preview.DashboardUid = existingPubdash.DashboardUid
+		preview.OrgId = existingPubdash.OrgId
+		preview.AccessToken = existingPubdash.AccessToken
+		preview.CreatedBy = existingPubdash.CreatedBy
+		preview.CreatedAt = existingPubdash.CreatedAt
+		preview.LastUsedAt = existingPubdash.LastUsedAt
+	}
+
+	return &preview, nil
+}
+
+// prepareSave validates the configuration to save and builds the command saving it. The existing public dashboard is
+// returned when the configuration updates it, nil when it creates a new one
+func (pd *PublicDashboardServiceImpl) prepareSave(ctx context.Context, u *user.SignedInUser, dto *SavePublicDashboardConfigDTO) (SavePublicDashboardConfigCommand, *PublicDashboard, error) {
 	// validate if the dashboard exists
 	dashboard, err := pd.FindDashboard(ctx, dto.DashboardUid, u.OrgID)
 	if err != nil {
-		return nil, err
+		return SavePublicDashboardConfigCommand{}, nil, err
 	}
 
 	// set default value for time settings
@@ -127,36 +258,69 @@ func (pd *PublicDashboardServiceImpl) Save(ctx context.Context, u *user.SignedIn
 		dto.PublicDashboard.TimeSettings = &TimeSettings{}
 	}
 
-	// get existing public dashboard if exists
-	existingPubdash, err := pd.store.Find(ctx, dto.PublicDashboard.Uid)
+	if dto.PublicDashboard.EmailGated && (pd.cfg == nil || !pd.cfg.Smtp.Enabled) {
+		return SavePublicDashboardConfigCommand{}, nil, ErrPublicDashboardEmailNotConfigured
+	}
+
+	dto.PublicDashboard.EmailAllowlist, err = validation.NormalizeEmailAllowlist(dto.PublicDashboard.EmailAllowlist)
 	if err != nil {
-		return nil, err
+		return SavePublicDashboardConfigCommand{}, nil, err
 	}
 
-	// save changes
-	var pubdashUid string
-	if existingPubdash == nil {
-		err = validation.ValidateSavePublicDashboard(dto, dashboard)
-		if err != nil {
-			return nil, err
-		}
-		pubdashUid, err = pd.savePublicDashboard(ctx, dto)
-	} else {
-		pubdashUid, err = pd.updatePublicDashboard(ctx, dto)
+	dto.PublicDashboard.AllowedCountries, err = validation.NormalizeCountryList(dto.PublicDashboard.AllowedCountries)
+	if err != nil {
+		return SavePublicDashboardConfigCommand{}, nil, err
 	}
+
+	dto.PublicDashboard.BlockedCountries, err = validation.NormalizeCountryList(dto.PublicDashboard.BlockedCountries)
 	if err != nil {
-		return nil, err
+		return SavePublicDashboardConfigCommand{}, nil, err
 	}
 
-	//Get latest public dashboard to return
-	newPubdash, err := pd.store.Find(ctx, pubdashUid)
+	dto.PublicDashboard.AnnotationsDisabledPanels, err = validation.NormalizePanelIdList(dto.PublicDashboard.AnnotationsDisabledPanels)
 	if err != nil {
-		return nil, err
+		return SavePublicDashboardConfigCommand{}, nil, err
 	}
 
-	pd.logIsEnabledChanged(existingPubdash, newPubdash, u)
+	dto.PublicDashboard.PreviousPeriodPanels, err = validation.NormalizePanelIdList(dto.PublicDashboard.PreviousPeriodPanels)
+	if err != nil {
+		return SavePublicDashboardConfigCommand{}, nil, err
+	}
 
-	return newPubdash, err
+	err = validation.ValidateCacheControl(dto.PublicDashboard.MaxAge, dto.PublicDashboard.StaleWhileRevalidate)
+	if err != nil {
+		return SavePublicDashboardConfigCommand{}, nil, err
+	}
+
+	// get existing public dashboard if exists
+	existingPubdash, err := pd.store.Find(ctx, dto.PublicDashboard.Uid)
+	if err != nil {
+		return SavePublicDashboardConfigCommand{}, nil, err
+	}
+
+	// refresh the cost hints of the panels, the latency of new public dashboards is not known yet
+	existingPubdashUid := ""
+	if existingPubdash != nil {
+		existingPubdashUid = existingPubdash.Uid
+	}
+	dto.PublicDashboard.PanelCostHints = pd.buildPanelCostHints(ctx, dashboard, existingPubdashUid)
+
+	if existingPubdash != nil {
+		// configurations not setting the footer keep the one they have
+		if dto.PublicDashboard.ShowFooter == nil {
+			dto.PublicDashboard.ShowFooter = existingPubdash.ShowFooter
+		}
+		return newUpdatePublicDashboardCommand(dto), existingPubdash, nil
+	}
+
+	err = validation.ValidateSavePublicDashboard(dto, dashboard)
+	if err != nil {
+		return SavePublicDashboardConfigCommand{}, nil, err
+	}
+
+	// public dashboards saved by users are their own, only public folders create public dashboards shared by a folder
+	cmd, err := pd.newSavePublicDashboardCommand(ctx, dto, "")
+	return cmd, nil, err
 }
 
 // NewPublicDashboardUid Generates a unique uid to create a public dashboard. Will make 3 attempts and fail if it cannot find an unused uid
@@ -192,16 +356,23 @@ func (pd *PublicDashboardServiceImpl) NewPublicDashboardAccessToken(ctx context.
 }
 
 // Called by Save this handles business logic
-// to generate token and calls create at the database layer
-func (pd *PublicDashboardServiceImpl) savePublicDashboard(ctx context.Context, dto *SavePublicDashboardConfigDTO) (string, error) {
+// to generate token and builds the command creating the public dashboard at the database layer. sharedByFolderUid is
+// the uid of the folder whose public folder creates the public dashboard, empty for public dashboards saved by users
+func (pd *PublicDashboardServiceImpl) newSavePublicDashboardCommand(ctx context.Context, dto *SavePublicDashboardConfigDTO, sharedByFolderUid string) (SavePublicDashboardConfigCommand, error) {
 	uid, err := pd.NewPublicDashboardUid(ctx)
 	if err != nil {
-		return "", err
+		return SavePublicDashboardConfigCommand{}, err
 	}
 
 	accessToken, err := pd.NewPublicDashboardAccessToken(ctx)
 	if err != nil {
-		return "", err
+		return SavePublicDashboardConfigCommand{}, err
+	}
+
+	// the footer is shown on new public dashboards unless turned off
+	showFooter := true
+	if dto.PublicDashboard.ShowFooter != nil {
+		showFooter = *dto.PublicDashboard.ShowFooter
 	}
 
 	cmd := SavePublicDashboardConfigCommand{
@@ -215,21 +386,36 @@ func (pd *PublicDashboardServiceImpl) savePublicDashboard(ctx context.Context, d
 			CreatedBy:          dto.UserId,
 			CreatedAt:          time.Now(),
 			AccessToken:        accessToken,
-		},
-	}
 
-	err = pd.store.Save(ctx, cmd)
-	if err != nil {
-		return "", err
+			ShowTimePicker:        dto.PublicDashboard.ShowTimePicker,
+			ShowAnnotationsToggle: dto.PublicDashboard.ShowAnnotationsToggle,
+			ShowFooter:            &showFooter,
+
+			EmailGated:     dto.PublicDashboard.EmailGated,
+			EmailAllowlist: dto.PublicDashboard.EmailAllowlist,
+
+			AllowedCountries: dto.PublicDashboard.AllowedCountries,
+			BlockedCountries: dto.PublicDashboard.BlockedCountries,
+
+			PanelCostHints: dto.PublicDashboard.PanelCostHints,
+
+			MaxAge:               dto.PublicDashboard.MaxAge,
+			StaleWhileRevalidate: dto.PublicDashboard.StaleWhileRevalidate,
+
+			AnnotationsDisabledPanels: dto.PublicDashboard.AnnotationsDisabledPanels,
+			PreviousPeriodPanels:      dto.PublicDashboard.PreviousPeriodPanels,
+
+			SharedByFolderUid: sharedByFolderUid,
+		},
 	}
 
-	return uid, nil
+	return cmd, nil
 }
 
 // Called by Save this handles business logic for updating a
-// dashboard and calls update at the database layer
-func (pd *PublicDashboardServiceImpl) updatePublicDashboard(ctx context.Context, dto *SavePublicDashboardConfigDTO) (string, error) {
-	cmd := SavePublicDashboardConfigCommand{
+// dashboard and builds the command updating it at the database layer
+func newUpdatePublicDashboardCommand(dto *SavePublicDashboardConfigDTO) SavePublicDashboardConfigCommand {
+	return SavePublicDashboardConfigCommand{
 		PublicDashboard: PublicDashboard{
 			Uid:                dto.PublicDashboard.Uid,
 			IsEnabled:          dto.PublicDashboard.IsEnabled,
@@ -237,20 +423,52 @@ func (pd *PublicDashboardServiceImpl) updatePublicDashboard(ctx context.Context,
 			TimeSettings:       dto.PublicDashboard.TimeSettings,
 			UpdatedBy:          dto.UserId,
 			UpdatedAt:          time.Now(),
+
+			ShowTimePicker:        dto.PublicDashboard.ShowTimePicker,
+			ShowAnnotationsToggle: dto.PublicDashboard.ShowAnnotationsToggle,
+			ShowFooter:            dto.PublicDashboard.ShowFooter,
+
+			EmailGated:     dto.PublicDashboard.EmailGated,
+			EmailAllowlist: dto.PublicDashboard.EmailAllowlist,
+
+			AllowedCountries: dto.PublicDashboard.AllowedCountries,
+			BlockedCountries: dto.PublicDashboard.BlockedCountries,
+
+			PanelCostHints: dto.PublicDashboard.PanelCostHints,
+
+			MaxAge:               dto.PublicDashboard.MaxAge,
+			StaleWhileRevalidate: dto.PublicDashboard.StaleWhileRevalidate,
+
+			AnnotationsDisabledPanels: dto.PublicDashboard.AnnotationsDisabledPanels,
+			PreviousPeriodPanels:      dto.PublicDashboard.PreviousPeriodPanels,
 		},
 	}
-
-	return dto.PublicDashboard.Uid, pd.store.Update(ctx, cmd)
 }
 
-// FindAll Returns a list of public dashboards by orgId
-func (pd *PublicDashboardServiceImpl) FindAll(ctx context.Context, u *user.SignedInUser, orgId int64) ([]PublicDashboardListResponse, error) {
+// FindAll Returns a page of the public dashboards by orgId. Public dashboards are filtered by the permissions of the
+// user before being paged through
+func (pd *PublicDashboardServiceImpl) FindAll(ctx context.Context, u *user.SignedInUser, orgId int64, page pagination.Page) (*PublicDashboardListResponseWithPagination, error) {
 	publicDashboards, err := pd.store.FindAll(ctx, orgId)
 	if err != nil {
 		return nil, err
 	}
 
-	return pd.filterDashboardsByPermissions(ctx, u, publicDashboards)
+	publicDashboards, err = pd.filterDashboardsByPermissions(ctx, u, publicDashboards)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := page.Slice(len(publicDashboards))
+	return &PublicDashboardListResponseWithPagination{
+		Result:           pagination.NewResult(page, end-start, int64(len(publicDashboards))),
+		PublicDashboards: publicDashboards[start:end],
+	}, nil
+}
+
+// FindAllGlobal Returns a list of public dashboards across all orgs. Callers are expected
+// to restrict this to Grafana server admins
+func (pd *PublicDashboardServiceImpl) FindAllGlobal(ctx context.Context) ([]PublicDashboardGlobalListResponse, error) {
+	return pd.store.FindAllGlobal(ctx)
 }
 
 func (pd *PublicDashboardServiceImpl) ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error) {
@@ -265,6 +483,16 @@ func (pd *PublicDashboardServiceImpl) GetOrgIdByAccessToken(ctx context.Context,
 	return pd.store.GetOrgIdByAccessToken(ctx, accessToken)
 }
 
+// GetCacheControlByAccessToken returns the Cache-Control header of the public responses of a public dashboard
+func (pd *PublicDashboardServiceImpl) GetCacheControlByAccessToken(ctx context.Context, accessToken string) (string, error) {
+	pubdash, err := pd.store.FindByAccessToken(ctx, accessToken)
+	if err != nil || pubdash == nil {
+		return "", err
+	}
+
+	return pubdash.CacheControl(), nil
+}
+
 // intervalMS and maxQueryData values are being calculated on the frontend for regular dashboards
 // we are doing the same for public dashboards but because this access would be public, we need a way to keep this
 // values inside reasonable bounds to avoid an attack that could hit data sources with a small interval and a big
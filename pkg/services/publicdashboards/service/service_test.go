@@ -8,7 +8,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -30,6 +29,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 var timeSettings = &TimeSettings{From: "now-12h", To: "now"}
@@ -67,14 +67,14 @@ func TestGetPublicDashboard(t *testing.T) {
 			DashResp: &models.Dashboard{Uid: "mydashboard", Data: dashboardData},
 		},
 		{
-			Name:        "returns ErrPublicDashboardNotFound when isEnabled is false",
+			Name:        "returns ErrPublicDashboardDisabled when isEnabled is false",
 			AccessToken: "abc123",
 			StoreResp: &storeResp{
 				pd:  &PublicDashboard{AccessToken: "abcdToken", IsEnabled: false},
 				d:   &models.Dashboard{Uid: "mydashboard"},
 				err: nil,
 			},
-			ErrResp:  ErrPublicDashboardNotFound,
+			ErrResp:  ErrPublicDashboardDisabled,
 			DashResp: nil,
 		},
 		{
@@ -143,6 +143,7 @@ func TestSavePublicDashboard(t *testing.T) {
 				DashboardUid:       "NOTTHESAME",
 				OrgId:              9999999,
 				TimeSettings:       timeSettings,
+				SharedByFolderUid:  "folder1",
 			},
 		}
 
@@ -156,6 +157,8 @@ func TestSavePublicDashboard(t *testing.T) {
 		assert.Equal(t, dashboard.Uid, pubdash.DashboardUid)
 		assert.Equal(t, dashboard.OrgId, pubdash.OrgId)
 		assert.Equal(t, dto.UserId, pubdash.CreatedBy)
+		// only public folders create public dashboards shared by a folder
+		assert.Empty(t, pubdash.SharedByFolderUid)
 		assert.Equal(t, dto.PublicDashboard.AnnotationsEnabled, pubdash.AnnotationsEnabled)
 		// ExistsEnabledByDashboardUid set by parameters
 		assert.Equal(t, dto.PublicDashboard.IsEnabled, pubdash.IsEnabled)
@@ -163,9 +166,8 @@ func TestSavePublicDashboard(t *testing.T) {
 		assert.NotEqual(t, &time.Time{}, pubdash.CreatedAt)
 		// Time settings set by db
 		assert.Equal(t, timeSettings, pubdash.TimeSettings)
-		// accessToken is valid uuid
-		_, err = uuid.Parse(pubdash.AccessToken)
-		require.NoError(t, err, "expected a valid UUID, got %s", pubdash.AccessToken)
+		// accessToken is a valid access token v2
+		assert.Equal(t, tokens.AccessTokenVersionV2, tokens.AccessTokenVersion(pubdash.AccessToken), "expected a valid access token, got %s", pubdash.AccessToken)
 	})
 
 	t.Run("Validate pubdash has default time setting value", func(t *testing.T) {
@@ -292,6 +294,7 @@ func TestUpdatePublicDashboard(t *testing.T) {
 		require.NoError(t, err)
 
 		// attempt to overwrite settings
+		showFooter := false
 		dto = &SavePublicDashboardConfigDTO{
 			DashboardUid: dashboard.Uid,
 			OrgId:        dashboard.OrgId,
@@ -307,11 +310,15 @@ func TestUpdatePublicDashboard(t *testing.T) {
 				AnnotationsEnabled: true,
 				TimeSettings:       timeSettings,
 				AccessToken:        "NOTAREALUUID",
+
+				ShowTimePicker:        true,
+				ShowAnnotationsToggle: true,
+				ShowFooter:            &showFooter,
 			},
 		}
 
 		// Since the dto.PublicDashboard has a uid, this will call
-		// service.updatePublicDashboard
+		// service.newUpdatePublicDashboardCommand
 		updatedPubdash, err := service.Save(context.Background(), SignedInUser, dto)
 		require.NoError(t, err)
 
@@ -325,6 +332,9 @@ func TestUpdatePublicDashboard(t *testing.T) {
 		// gets updated
 		assert.Equal(t, dto.PublicDashboard.IsEnabled, updatedPubdash.IsEnabled)
 		assert.Equal(t, dto.PublicDashboard.AnnotationsEnabled, updatedPubdash.AnnotationsEnabled)
+		assert.Equal(t, dto.PublicDashboard.ShowTimePicker, updatedPubdash.ShowTimePicker)
+		assert.Equal(t, dto.PublicDashboard.ShowAnnotationsToggle, updatedPubdash.ShowAnnotationsToggle)
+		assert.Equal(t, dto.PublicDashboard.ShowFooter, updatedPubdash.ShowFooter)
 		assert.Equal(t, dto.PublicDashboard.TimeSettings, updatedPubdash.TimeSettings)
 		assert.Equal(t, dto.UserId, updatedPubdash.UpdatedBy)
 		assert.NotEqual(t, &time.Time{}, updatedPubdash.UpdatedAt)
@@ -352,7 +362,7 @@ func TestUpdatePublicDashboard(t *testing.T) {
 		}
 
 		// Since the dto.PublicDashboard has a uid, this will call
-		// service.updatePublicDashboard
+		// service.newUpdatePublicDashboardCommand
 		savedPubdash, err := service.Save(context.Background(), SignedInUser, dto)
 		require.NoError(t, err)
 
@@ -378,6 +388,170 @@ func TestUpdatePublicDashboard(t *testing.T) {
 
 		assert.Equal(t, &TimeSettings{}, updatedPubdash.TimeSettings)
 	})
+
+	t.Run("Saving without footer setting shows the footer and updates keep it", func(t *testing.T) {
+		sqlStore := db.InitTestDB(t)
+		dashboardStore := dashboardsDB.ProvideDashboardStore(sqlStore, sqlStore.Cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore, sqlStore.Cfg))
+		publicdashboardStore := database.ProvideStore(sqlStore)
+		dashboard := insertTestDashboard(t, dashboardStore, "testDashie", 1, 0, true, []map[string]interface{}{}, nil)
+
+		service := &PublicDashboardServiceImpl{
+			log:   log.New("test.logger"),
+			store: publicdashboardStore,
+		}
+
+		savedPubdash, err := service.Save(context.Background(), SignedInUser, &SavePublicDashboardConfigDTO{
+			DashboardUid:    dashboard.Uid,
+			OrgId:           dashboard.OrgId,
+			UserId:          7,
+			PublicDashboard: &PublicDashboard{IsEnabled: true},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, savedPubdash.ShowFooter)
+		assert.True(t, *savedPubdash.ShowFooter)
+
+		// turn the footer off, then update without setting it
+		showFooter := false
+		_, err = service.Save(context.Background(), SignedInUser, &SavePublicDashboardConfigDTO{
+			DashboardUid:    dashboard.Uid,
+			OrgId:           dashboard.OrgId,
+			UserId:          8,
+			PublicDashboard: &PublicDashboard{Uid: savedPubdash.Uid, IsEnabled: true, ShowFooter: &showFooter},
+		})
+		require.NoError(t, err)
+
+		updatedPubdash, err := service.Save(context.Background(), SignedInUser, &SavePublicDashboardConfigDTO{
+			DashboardUid:    dashboard.Uid,
+			OrgId:           dashboard.OrgId,
+			UserId:          8,
+			PublicDashboard: &PublicDashboard{Uid: savedPubdash.Uid, IsEnabled: true},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, updatedPubdash.ShowFooter)
+		assert.False(t, *updatedPubdash.ShowFooter)
+	})
+}
+
+func TestPreviewPublicDashboard(t *testing.T) {
+	setup := func(t *testing.T, templateVars []map[string]interface{}) (*PublicDashboardServiceImpl, *models.Dashboard) {
+		sqlStore := db.InitTestDB(t)
+		dashboardStore := dashboardsDB.ProvideDashboardStore(sqlStore, sqlStore.Cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore, sqlStore.Cfg))
+		dashboard := insertTestDashboard(t, dashboardStore, "testDashie", 1, 0, true, templateVars, nil)
+
+		return &PublicDashboardServiceImpl{
+			log:   log.New("test.logger"),
+			store: database.ProvideStore(sqlStore),
+		}, dashboard
+	}
+
+	t.Run("returns the new public dashboard without saving it", func(t *testing.T) {
+		service, dashboard := setup(t, []map[string]interface{}{})
+
+		preview, err := service.Preview(context.Background(), SignedInUser, &SavePublicDashboardConfigDTO{
+			DashboardUid: dashboard.Uid,
+			OrgId:        dashboard.OrgId,
+			UserId:       7,
+			PublicDashboard: &PublicDashboard{
+				IsEnabled:        true,
+				AllowedCountries: CountryList{"fr"},
+			},
+		})
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, preview.Uid)
+		assert.Equal(t, dashboard.Uid, preview.DashboardUid)
+		assert.Equal(t, int64(7), preview.CreatedBy)
+		assert.Equal(t, tokens.AccessTokenVersionV2, tokens.AccessTokenVersion(preview.AccessToken))
+		assert.Equal(t, CountryList{"FR"}, preview.AllowedCountries)
+		assert.Equal(t, &TimeSettings{}, preview.TimeSettings)
+		assert.NotEmpty(t, preview.PanelCostHints)
+
+		_, err = service.FindByDashboardUid(context.Background(), dashboard.OrgId, dashboard.Uid)
+		require.ErrorIs(t, err, ErrPublicDashboardNotFound)
+	})
+
+	t.Run("returns the updated public dashboard without saving it", func(t *testing.T) {
+		service, dashboard := setup(t, []map[string]interface{}{})
+
+		savedPubdash, err := service.Save(context.Background(), SignedInUser, &SavePublicDashboardConfigDTO{
+			DashboardUid:    dashboard.Uid,
+			OrgId:           dashboard.OrgId,
+			UserId:          7,
+			PublicDashboard: &PublicDashboard{IsEnabled: true},
+		})
+		require.NoError(t, err)
+
+		showFooter := false
+		preview, err := service.Preview(context.Background(), SignedInUser, &SavePublicDashboardConfigDTO{
+			DashboardUid: dashboard.Uid,
+			OrgId:        dashboard.OrgId,
+			UserId:       8,
+			PublicDashboard: &PublicDashboard{
+				Uid:         savedPubdash.Uid,
+				AccessToken: "NOTAREALUUID",
+				IsEnabled:   false,
+				ShowFooter:  &showFooter,
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, savedPubdash.Uid, preview.Uid)
+		assert.Equal(t, savedPubdash.AccessToken, preview.AccessToken)
+		assert.Equal(t, savedPubdash.DashboardUid, preview.DashboardUid)
+		assert.Equal(t, savedPubdash.CreatedBy, preview.CreatedBy)
+		assert.Equal(t, int64(8), preview.UpdatedBy)
+		assert.False(t, preview.IsEnabled)
+		assert.False(t, *preview.ShowFooter)
+
+		pubdash, err := service.FindByDashboardUid(context.Background(), dashboard.OrgId, dashboard.Uid)
+		require.NoError(t, err)
+		assert.True(t, pubdash.IsEnabled)
+		assert.True(t, *pubdash.ShowFooter)
+	})
+
+	t.Run("returns the validation errors of Save", func(t *testing.T) {
+		service, dashboard := setup(t, make([]map[string]interface{}, 1))
+
+		_, err := service.Preview(context.Background(), SignedInUser, &SavePublicDashboardConfigDTO{
+			DashboardUid:    dashboard.Uid,
+			OrgId:           dashboard.OrgId,
+			UserId:          7,
+			PublicDashboard: &PublicDashboard{IsEnabled: true},
+		})
+		require.ErrorIs(t, err, ErrPublicDashboardHasTemplateVariables)
+	})
+}
+
+func TestSavePublicDashboardCacheControl(t *testing.T) {
+	sqlStore := db.InitTestDB(t)
+	dashboardStore := dashboardsDB.ProvideDashboardStore(sqlStore, sqlStore.Cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore, sqlStore.Cfg))
+	dashboard := insertTestDashboard(t, dashboardStore, "testDashie", 1, 0, true, []map[string]interface{}{}, nil)
+	service := &PublicDashboardServiceImpl{
+		log:   log.New("test.logger"),
+		store: database.ProvideStore(sqlStore),
+	}
+
+	pubdash, err := service.Save(context.Background(), SignedInUser, &SavePublicDashboardConfigDTO{
+		DashboardUid:    dashboard.Uid,
+		OrgId:           dashboard.OrgId,
+		UserId:          7,
+		PublicDashboard: &PublicDashboard{IsEnabled: true, MaxAge: 60, StaleWhileRevalidate: 30},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(60), pubdash.MaxAge)
+	assert.Equal(t, int64(30), pubdash.StaleWhileRevalidate)
+
+	cacheControl, err := service.GetCacheControlByAccessToken(context.Background(), pubdash.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "public, max-age=60, stale-while-revalidate=30", cacheControl)
+
+	_, err = service.Save(context.Background(), SignedInUser, &SavePublicDashboardConfigDTO{
+		DashboardUid:    dashboard.Uid,
+		OrgId:           dashboard.OrgId,
+		UserId:          7,
+		PublicDashboard: &PublicDashboard{Uid: pubdash.Uid, IsEnabled: true, MaxAge: -1},
+	})
+	require.ErrorIs(t, err, ErrPublicDashboardInvalidCacheControl)
 }
 
 func insertTestDashboard(t *testing.T, dashboardStore *dashboardsDB.DashboardStore, title string, orgId int64,
@@ -589,6 +763,13 @@ func TestPublicDashboardServiceImpl_ListPublicDashboards(t *testing.T) {
 		ctx   context.Context
 		u     *user.SignedInUser
 		orgId int64
+		page  pagination.Page
+	}
+
+	allDashboardsReader := &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{
+		1: {"dashboards:read": {
+			"dashboards:uid:0S6TmO67z", "dashboards:uid:1S6TmO67z", "dashboards:uid:2S6TmO67z", "dashboards:uid:9S6TmO67z",
+		}}},
 	}
 
 	testCases := []struct {
@@ -715,6 +896,32 @@ func TestPublicDashboardServiceImpl_ListPublicDashboards(t *testing.T) {
 			},
 			wantErr: assert.NoError,
 		},
+		{
+			name: "should return a page of the dashboards the user has permissions on",
+			args: args{
+				ctx:   context.Background(),
+				u:     allDashboardsReader,
+				orgId: 1,
+				page:  pagination.Page{Page: 2, PerPage: 2},
+			},
+			want: []PublicDashboardListResponse{
+				{
+					Uid:          "2GwW7mgVk",
+					AccessToken:  "2b458cb7fe7f42c68712078bcacee6e3",
+					DashboardUid: "2S6TmO67z",
+					Title:        "my second dashboard",
+					IsEnabled:    false,
+				},
+				{
+					Uid:          "9GwW7mgVk",
+					AccessToken:  "deletedashboardaccesstoken",
+					DashboardUid: "9S6TmO67z",
+					Title:        "",
+					IsEnabled:    true,
+				},
+			},
+			wantErr: assert.NoError,
+		},
 		{
 			name: "errors different than not data found should be returned",
 			args: args{
@@ -784,13 +991,22 @@ func TestPublicDashboardServiceImpl_ListPublicDashboards(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ac.EvaluateFunc = tt.evaluateFunc
 
-			got, err := pd.FindAll(tt.args.ctx, tt.args.u, tt.args.orgId)
-			if !tt.wantErr(t, err, fmt.Sprintf("FindAll(%v, %v, %v)", tt.args.ctx, tt.args.u, tt.args.orgId)) {
+			got, err := pd.FindAll(tt.args.ctx, tt.args.u, tt.args.orgId, tt.args.page)
+			if !tt.wantErr(t, err, fmt.Sprintf("FindAll(%v, %v, %v)", tt.args.ctx, tt.args.u, tt.args.orgId)) || err != nil {
 				return
 			}
-			assert.Equalf(t, tt.want, got, "FindAll(%v, %v, %v)", tt.args.ctx, tt.args.u, tt.args.orgId)
+			assert.Equalf(t, tt.want, got.PublicDashboards, "FindAll(%v, %v, %v)", tt.args.ctx, tt.args.u, tt.args.orgId)
 		})
 	}
+
+	t.Run("should describe the page", func(t *testing.T) {
+		ac.EvaluateFunc = nil
+
+		got, err := pd.FindAll(context.Background(), allDashboardsReader, 1, pagination.Page{Page: 1, PerPage: 3})
+		require.NoError(t, err)
+		assert.Len(t, got.PublicDashboards, 3)
+		assert.Equal(t, pagination.Result{TotalCount: 4, Page: 1, PerPage: 3, NextCursor: pagination.NewCursor(3)}, got.Result)
+	})
 }
 
 func TestPublicDashboardServiceImpl_NewPublicDashboardUid(t *testing.T) {
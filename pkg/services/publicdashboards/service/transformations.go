@@ -0,0 +1,426 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+// Panel transformations that can be applied server-side
+const (
+	transformationMerge          = "merge"
+	transformationOrganize       = "organize"
+	transformationCalculateField = "calculateField"
+)
+
+// Default aliases of the fields added by the calculateField transformation, by reducer
+var calculateFieldReducerNames = map[string]string{
+	"sum":   "Total",
+	"mean":  "Mean",
+	"min":   "Min",
+	"max":   "Max",
+	"count": "Count",
+	"first": "First",
+	"last":  "Last",
+}
+
+// Operators of the binary mode of the calculateField transformation
+var calculateFieldOperators = map[string]bool{"+": true, "-": true, "*": true, "/": true}
+
+// getPanelTransformations returns the enabled transformations stored on the panel
+func getPanelTransformations(dashboard *simplejson.Json, panelId int64) []*simplejson.Json {
+	var transformations []*simplejson.Json
+
+	for _, panelObj := range dashboard.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(panelObj)
+		if panel.Get("id").MustInt64() != panelId {
+			continue
+		}
+
+		for _, transformationObj := range panel.Get("transformations").MustArray() {
+			transformation := simplejson.NewFromAny(transformationObj)
+			if !transformation.Get("disabled").MustBool() {
+				transformations = append(transformations, transformation)
+			}
+		}
+	}
+
+	return transformations
+}
+
+// applyPanelTransformations applies the transformations of the panel to the frames of the response, the same way the
+// frontend does before rendering the panel. The frames of all successful queries are transformed together, in the
+// order of the panel queries, and are returned on the first of them. Failed queries are left untouched.
+func applyPanelTransformations(res *backend.QueryDataResponse, refIds []string, transformations []*simplejson.Json) error {
+	if len(transformations) == 0 {
+		return nil
+	}
+
+	orderedRefIds := make([]string, 0, len(res.Responses))
+	seen := make(map[string]bool, len(res.Responses))
+	for _, refId := range refIds {
+		if dataResponse, ok := res.Responses[refId]; ok && dataResponse.Error == nil && !seen[refId] {
+			orderedRefIds = append(orderedRefIds, refId)
+			seen[refId] = true
+		}
+	}
+	var remainingRefIds []string
+	for refId, dataResponse := range res.Responses {
+		if dataResponse.Error == nil && !seen[refId] {
+			remainingRefIds = append(remainingRefIds, refId)
+		}
+	}
+	sort.Strings(remainingRefIds)
+	orderedRefIds = append(orderedRefIds, remainingRefIds...)
+
+	var frames data.Frames
+	for _, refId := range orderedRefIds {
+		frames = append(frames, res.Responses[refId].Frames...)
+	}
+
+	for _, transformation := range transformations {
+		var err error
+		frames, err = transformFrames(frames, transformation)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, refId := range orderedRefIds {
+		dataResponse := res.Responses[refId]
+		dataResponse.Frames = nil
+		if i == 0 {
+			dataResponse.Frames = frames
+		}
+		res.Responses[refId] = dataResponse
+	}
+
+	return nil
+}
+
+func transformFrames(frames data.Frames, transformation *simplejson.Json) (data.Frames, error) {
+	options := transformation.Get("options")
+
+	switch transformation.Get("id").MustString() {
+	case transformationMerge:
+		return mergeFrames(frames)
+	case transformationOrganize:
+		return organizeFields(frames, options), nil
+	case transformationCalculateField:
+		return calculateField(frames, options)
+	default:
+		return nil, models.ErrPublicDashboardTransformationNotSupported
+	}
+}
+
+// mergeFrames merges the frames into a single one holding the fields of all frames. Rows with the same values in the
+// fields shared by all frames are merged into a single row, the other rows are appended.
+func mergeFrames(frames data.Frames) (data.Frames, error) {
+	var sources data.Frames
+	for _, frame := range frames {
+		if len(frame.Fields) > 0 {
+			sources = append(sources, frame)
+		}
+	}
+	if len(sources) < 2 {
+		return frames, nil
+	}
+
+	merged := data.NewFrame(sources[0].Name)
+	merged.RefID = sources[0].RefID
+	mergedFields := make(map[string]*data.Field)
+	occurrences := make(map[string]int)
+	for _, frame := range sources {
+		for _, field := range frame.Fields {
+			key := mergeFieldKey(field)
+			if _, ok := mergedFields[key]; !ok {
+				mergedField := data.NewFieldFromFieldType(field.Type().NullableType(), 0)
+				mergedField.Name = field.Name
+				mergedField.Labels = field.Labels
+				mergedField.Config = field.Config
+				mergedFields[key] = mergedField
+				merged.Fields = append(merged.Fields, mergedField)
+			}
+			occurrences[key]++
+		}
+	}
+
+	var commonKeys []string
+	for _, field := range merged.Fields {
+		key := mergeFieldKey(field)
+		if occurrences[key] == len(sources) {
+			commonKeys = append(commonKeys, key)
+		}
+	}
+
+	rowsByKey := make(map[string]int)
+	rowCount := 0
+	for _, frame := range sources {
+		rowLen, err := frame.RowLen()
+		if err != nil {
+			return nil, err
+		}
+
+		fieldsByKey := make(map[string]*data.Field, len(frame.Fields))
+		for _, field := range frame.Fields {
+			fieldsByKey[mergeFieldKey(field)] = field
+		}
+
+		for i := 0; i < rowLen; i++ {
+			rowKey := mergeRowKey(fieldsByKey, commonKeys, i)
+			row, ok := rowsByKey[rowKey]
+			if !ok || len(commonKeys) == 0 {
+				row = rowCount
+				rowsByKey[rowKey] = row
+				rowCount++
+				for _, field := range merged.Fields {
+					field.Extend(1)
+				}
+			}
+
+			for key, field := range fieldsByKey {
+				if value, ok := field.ConcreteAt(i); ok {
+					mergedFields[key].SetConcrete(row, value)
+				}
+			}
+		}
+	}
+
+	return data.Frames{merged}, nil
+}
+
+func mergeFieldKey(field *data.Field) string {
+	return field.Name + field.Labels.String() + "/" + field.Type().NullableType().ItemTypeString()
+}
+
+func mergeRowKey(fieldsByKey map[string]*data.Field, commonKeys []string, row int) string {
+	values := make([]string, 0, len(commonKeys))
+	for _, key := range commonKeys {
+		value, ok := fieldsByKey[key].ConcreteAt(row)
+		if !ok {
+			values = append(values, "null")
+			continue
+		}
+		values = append(values, fmt.Sprint(value))
+	}
+	return strings.Join(values, "\x00")
+}
+
+// organizeFields excludes, reorders and renames the fields of every frame
+func organizeFields(frames data.Frames, options *simplejson.Json) data.Frames {
+	excludeByName := options.Get("excludeByName")
+	indexByName := options.Get("indexByName")
+	renameByName := options.Get("renameByName")
+
+	for _, frame := range frames {
+		fields := make([]*data.Field, 0, len(frame.Fields))
+		for _, field := range frame.Fields {
+			if !excludeByName.Get(fieldDisplayName(field)).MustBool() {
+				fields = append(fields, field)
+			}
+		}
+
+		// fields without an index keep their order after the indexed ones
+		sort.SliceStable(fields, func(i, j int) bool {
+			return indexByName.Get(fieldDisplayName(fields[i])).MustInt(math.MaxInt) <
+				indexByName.Get(fieldDisplayName(fields[j])).MustInt(math.MaxInt)
+		})
+
+		for _, field := range fields {
+			if name := renameByName.Get(fieldDisplayName(field)).MustString(); name != "" {
+				if field.Config == nil {
+					field.Config = &data.FieldConfig{}
+				}
+				field.Config.DisplayName = name
+			}
+		}
+
+		frame.Fields = fields
+	}
+
+	return frames
+}
+
+// calculateField adds a field computed from the other fields of the row to every frame
+func calculateField(frames data.Frames, options *simplejson.Json) (data.Frames, error) {
+	mode := options.Get("mode").MustString("reduceRow")
+	reducer := options.GetPath("reduce", "reducer").MustString("sum")
+	include := options.GetPath("reduce", "include").MustStringArray()
+	left := options.GetPath("binary", "left").MustString()
+	operator := options.GetPath("binary", "operator").MustString("+")
+	right := options.GetPath("binary", "right").MustString()
+
+	alias := options.Get("alias").MustString()
+	switch mode {
+	case "reduceRow":
+		name, ok := calculateFieldReducerNames[reducer]
+		if !ok {
+			return nil, models.ErrPublicDashboardTransformationNotSupported
+		}
+		if alias == "" {
+			alias = name
+		}
+	case "binary":
+		if !calculateFieldOperators[operator] {
+			return nil, models.ErrPublicDashboardTransformationNotSupported
+		}
+		if alias == "" {
+			alias = fmt.Sprintf("%s %s %s", left, operator, right)
+		}
+	default:
+		return nil, models.ErrPublicDashboardTransformationNotSupported
+	}
+
+	for _, frame := range frames {
+		if len(frame.Fields) == 0 {
+			continue
+		}
+
+		rowLen, err := frame.RowLen()
+		if err != nil {
+			return nil, err
+		}
+
+		values := make([]*float64, rowLen)
+		for i := 0; i < rowLen; i++ {
+			if mode == "binary" {
+				values[i] = binaryOperation(operator, operandAt(frame, left, i), operandAt(frame, right, i))
+			} else {
+				values[i] = reduceRow(reducer, rowValues(frame, include, i))
+			}
+		}
+
+		calculated := data.NewField(alias, nil, values)
+		if options.Get("replaceFields").MustBool() {
+			var timeFields []*data.Field
+			for _, field := range frame.Fields {
+				if field.Type().Time() {
+					timeFields = append(timeFields, field)
+				}
+			}
+			frame.Fields = timeFields
+		}
+		frame.Fields = append(frame.Fields, calculated)
+	}
+
+	return frames, nil
+}
+
+// rowValues returns the non null values of the numeric fields of the row, limited to the included fields if any
+func rowValues(frame *data.Frame, include []string, row int) []float64 {
+	var values []float64
+	for _, field := range frame.Fields {
+		if !field.Type().Numeric() {
+			continue
+		}
+		if len(include) > 0 && !containsString(include, fieldDisplayName(field)) {
+			continue
+		}
+
+		value, err := field.NullableFloatAt(row)
+		if err != nil || value == nil || math.IsNaN(*value) {
+			continue
+		}
+		values = append(values, *value)
+	}
+	return values
+}
+
+func reduceRow(reducer string, values []float64) *float64 {
+	if reducer == "count" {
+		count := float64(len(values))
+		return &count
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	result := values[0]
+	switch reducer {
+	case "sum", "mean":
+		for _, value := range values[1:] {
+			result += value
+		}
+		if reducer == "mean" {
+			result /= float64(len(values))
+		}
+	case "min":
+		for _, value := range values[1:] {
+			result = math.Min(result, value)
+		}
+	case "max":
+		for _, value := range values[1:] {
+			result = math.Max(result, value)
+		}
+	case "last":
+		result = values[len(values)-1]
+	}
+	return &result
+}
+
+// operandAt returns the value of the field with the given name in the row, or the operand itself if it is a number
+func operandAt(frame *data.Frame, operand string, row int) *float64 {
+	for _, field := range frame.Fields {
+		if fieldDisplayName(field) == operand {
+			value, err := field.NullableFloatAt(row)
+			if err != nil {
+				return nil
+			}
+			return value
+		}
+	}
+
+	value, err := strconv.ParseFloat(operand, 64)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+func binaryOperation(operator string, left *float64, right *float64) *float64 {
+	if left == nil || right == nil {
+		return nil
+	}
+
+	var result float64
+	switch operator {
+	case "+":
+		result = *left + *right
+	case "-":
+		result = *left - *right
+	case "*":
+		result = *left * *right
+	case "/":
+		result = *left / *right
+	}
+	return &result
+}
+
+// fieldDisplayName returns the name transformations refer to a field by
+func fieldDisplayName(field *data.Field) string {
+	if field.Config != nil {
+		if field.Config.DisplayName != "" {
+			return field.Config.DisplayName
+		}
+		if field.Config.DisplayNameFromDS != "" {
+			return field.Config.DisplayNameFromDS
+		}
+	}
+	return field.Name
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
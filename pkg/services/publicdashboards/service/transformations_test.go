@@ -0,0 +1,194 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+func TestGetPanelTransformations(t *testing.T) {
+	dashboard := simplejson.NewFromAny(map[string]interface{}{
+		"panels": []interface{}{
+			map[string]interface{}{
+				"id": 1,
+				"transformations": []interface{}{
+					map[string]interface{}{"id": "merge"},
+					map[string]interface{}{"id": "organize", "disabled": true},
+					map[string]interface{}{"id": "calculateField"},
+				},
+			},
+			map[string]interface{}{"id": 2},
+		},
+	})
+
+	transformations := getPanelTransformations(dashboard, 1)
+	require.Len(t, transformations, 2)
+	assert.Equal(t, "merge", transformations[0].Get("id").MustString())
+	assert.Equal(t, "calculateField", transformations[1].Get("id").MustString())
+
+	assert.Empty(t, getPanelTransformations(dashboard, 2))
+	assert.Empty(t, getPanelTransformations(dashboard, 3))
+}
+
+func TestApplyPanelTransformations(t *testing.T) {
+	t1 := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+
+	newResponse := func() *backend.QueryDataResponse {
+		return &backend.QueryDataResponse{Responses: backend.Responses{
+			"A": {Frames: data.Frames{data.NewFrame("A",
+				data.NewField("time", nil, []time.Time{t1, t2}),
+				data.NewField("cpu", nil, []float64{1, 2}),
+			)}},
+			"B": {Frames: data.Frames{data.NewFrame("B",
+				data.NewField("time", nil, []time.Time{t2}),
+				data.NewField("memory", nil, []float64{20}),
+			)}},
+		}}
+	}
+	transformations := func(transformations ...interface{}) []*simplejson.Json {
+		var result []*simplejson.Json
+		for _, transformation := range transformations {
+			result = append(result, simplejson.NewFromAny(transformation))
+		}
+		return result
+	}
+
+	t.Run("merges the frames of all queries on the first query", func(t *testing.T) {
+		res := newResponse()
+
+		err := applyPanelTransformations(res, []string{"A", "B"}, transformations(map[string]interface{}{"id": "merge"}))
+		require.NoError(t, err)
+
+		require.Len(t, res.Responses["A"].Frames, 1)
+		assert.Empty(t, res.Responses["B"].Frames)
+
+		frame := res.Responses["A"].Frames[0]
+		require.Len(t, frame.Fields, 3)
+		assert.Equal(t, []string{"time", "cpu", "memory"}, []string{frame.Fields[0].Name, frame.Fields[1].Name, frame.Fields[2].Name})
+		require.Equal(t, 2, frame.Fields[0].Len())
+		assert.Equal(t, 1.0, *frame.Fields[1].At(0).(*float64))
+		assert.Nil(t, frame.Fields[2].At(0))
+		assert.Equal(t, 2.0, *frame.Fields[1].At(1).(*float64))
+		assert.Equal(t, 20.0, *frame.Fields[2].At(1).(*float64))
+	})
+
+	t.Run("appends the rows of frames without common fields", func(t *testing.T) {
+		res := &backend.QueryDataResponse{Responses: backend.Responses{
+			"A": {Frames: data.Frames{data.NewFrame("A", data.NewField("host", nil, []string{"a"}))}},
+			"B": {Frames: data.Frames{data.NewFrame("B", data.NewField("region", nil, []string{"eu"}))}},
+		}}
+
+		err := applyPanelTransformations(res, []string{"A", "B"}, transformations(map[string]interface{}{"id": "merge"}))
+		require.NoError(t, err)
+
+		frame := res.Responses["A"].Frames[0]
+		rowLen, err := frame.RowLen()
+		require.NoError(t, err)
+		assert.Equal(t, 2, rowLen)
+	})
+
+	t.Run("keeps the errors of the queries", func(t *testing.T) {
+		res := newResponse()
+		res.Responses["B"] = queryErrorResponse("B", "abc123")
+
+		err := applyPanelTransformations(res, []string{"A", "B"}, transformations(map[string]interface{}{"id": "merge"}))
+		require.NoError(t, err)
+
+		assert.Equal(t, ErrPublicDashboardQueryFailed, res.Responses["B"].Error)
+		require.Len(t, res.Responses["A"].Frames, 1)
+		assert.Len(t, res.Responses["A"].Frames[0].Fields, 2)
+		assert.Len(t, res.Responses["B"].Frames, 1)
+	})
+
+	t.Run("organizes the fields", func(t *testing.T) {
+		res := newResponse()
+
+		err := applyPanelTransformations(res, []string{"A", "B"}, transformations(map[string]interface{}{
+			"id": "organize",
+			"options": map[string]interface{}{
+				"excludeByName": map[string]interface{}{"memory": true},
+				"indexByName":   map[string]interface{}{"cpu": 0, "time": 1},
+				"renameByName":  map[string]interface{}{"cpu": "CPU usage"},
+			},
+		}))
+		require.NoError(t, err)
+
+		frames := res.Responses["A"].Frames
+		require.Len(t, frames, 2)
+		require.Len(t, frames[0].Fields, 2)
+		assert.Equal(t, "cpu", frames[0].Fields[0].Name)
+		assert.Equal(t, "CPU usage", frames[0].Fields[0].Config.DisplayName)
+		assert.Equal(t, "time", frames[0].Fields[1].Name)
+		require.Len(t, frames[1].Fields, 1)
+		assert.Equal(t, "time", frames[1].Fields[0].Name)
+	})
+
+	t.Run("calculates a field reducing the rows", func(t *testing.T) {
+		res := newResponse()
+
+		err := applyPanelTransformations(res, []string{"A", "B"}, transformations(
+			map[string]interface{}{"id": "merge"},
+			map[string]interface{}{"id": "calculateField", "options": map[string]interface{}{
+				"mode":          "reduceRow",
+				"reduce":        map[string]interface{}{"reducer": "sum"},
+				"replaceFields": true,
+			}},
+		))
+		require.NoError(t, err)
+
+		frame := res.Responses["A"].Frames[0]
+		require.Len(t, frame.Fields, 2)
+		assert.Equal(t, "time", frame.Fields[0].Name)
+		assert.Equal(t, "Total", frame.Fields[1].Name)
+		assert.Equal(t, 1.0, *frame.Fields[1].At(0).(*float64))
+		assert.Equal(t, 22.0, *frame.Fields[1].At(1).(*float64))
+	})
+
+	t.Run("calculates a field with a binary operation", func(t *testing.T) {
+		res := newResponse()
+
+		err := applyPanelTransformations(res, []string{"A"}, transformations(map[string]interface{}{
+			"id": "calculateField",
+			"options": map[string]interface{}{
+				"mode":   "binary",
+				"binary": map[string]interface{}{"left": "cpu", "operator": "*", "right": "100"},
+				"alias":  "cpu percent",
+			},
+		}))
+		require.NoError(t, err)
+
+		frame := res.Responses["A"].Frames[0]
+		require.Len(t, frame.Fields, 3)
+		assert.Equal(t, "cpu percent", frame.Fields[2].Name)
+		assert.Equal(t, 100.0, *frame.Fields[2].At(0).(*float64))
+		assert.Equal(t, 200.0, *frame.Fields[2].At(1).(*float64))
+	})
+
+	t.Run("returns ErrPublicDashboardTransformationNotSupported for transformations only available in the browser", func(t *testing.T) {
+		err := applyPanelTransformations(newResponse(), []string{"A", "B"}, transformations(map[string]interface{}{"id": "seriesToRows"}))
+		require.ErrorIs(t, err, ErrPublicDashboardTransformationNotSupported)
+
+		err = applyPanelTransformations(newResponse(), []string{"A", "B"}, transformations(map[string]interface{}{
+			"id":      "calculateField",
+			"options": map[string]interface{}{"mode": "reduceRow", "reduce": map[string]interface{}{"reducer": "p95"}},
+		}))
+		require.ErrorIs(t, err, ErrPublicDashboardTransformationNotSupported)
+	})
+
+	t.Run("leaves the response untouched without transformations", func(t *testing.T) {
+		res := newResponse()
+
+		err := applyPanelTransformations(res, []string{"A", "B"}, nil)
+		require.NoError(t, err)
+		assert.Len(t, res.Responses["A"].Frames, 1)
+		assert.Len(t, res.Responses["B"].Frames, 1)
+	})
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+// usageFlushInterval is how often the counted queries are added to the usage of public dashboards in the database
+const usageFlushInterval = time.Minute
+
+// usageKey identifies the usage of a public dashboard for a day
+type usageKey struct {
+	publicDashboardUid string
+	day                string
+}
+
+// UsageTracker counts in memory the queries of public dashboards for the report digests and periodically adds them
+// to the database, so running the queries of a public dashboard does not write on every request
+type UsageTracker struct {
+	log      log.Logger
+	features featuremgmt.FeatureToggles
+	store    publicdashboards.ReportStore
+
+	mu      sync.Mutex
+	pending map[usageKey]*PublicDashboardUsage
+}
+
+// ProvideUsageTracker Factory for method used by wire to inject dependencies
+func ProvideUsageTracker(features featuremgmt.FeatureToggles, store publicdashboards.ReportStore) *UsageTracker {
+	return &UsageTracker{
+		log:      log.New("publicdashboards.usage"),
+		features: features,
+		store:    store,
+		pending:  make(map[usageKey]*PublicDashboardUsage),
+	}
+}
+
+// IsDisabled queries are only counted when public dashboards are enabled
+func (t *UsageTracker) IsDisabled() bool {
+	return !t.features.IsEnabled(featuremgmt.FlagPublicDashboards)
+}
+
+func (t *UsageTracker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(usageFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.flushAndLog(ctx)
+		case <-ctx.Done():
+			// the context is done, the queries counted since the last flush are written with a fresh one
+			t.flushAndLog(context.Background())
+			return ctx.Err()
+		}
+	}
+}
+
+// record counts a query of the public dashboard, and whether it failed, for the day of now until the next flush
+func (t *UsageTracker) record(publicDashboard *PublicDashboard, failed bool, now time.Time) {
+	if t == nil {
+		return
+	}
+
+	var errorCount int64
+	if failed {
+		errorCount = 1
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.add(&PublicDashboardUsage{
+		PublicDashboardUid: publicDashboard.Uid,
+		OrgId:              publicDashboard.OrgId,
+		Day:                now.UTC().Format(UsageDayFormat),
+		QueryCount:         1,
+		ErrorCount:         errorCount,
+	})
+}
+
+// add adds the counters of usage to the pending usage of its public dashboard for its day. t.mu must be held
+func (t *UsageTracker) add(usage *PublicDashboardUsage) {
+	key := usageKey{publicDashboardUid: usage.PublicDashboardUid, day: usage.Day}
+	pending, ok := t.pending[key]
+	if !ok {
+		t.pending[key] = usage
+		return
+	}
+
+	pending.QueryCount += usage.QueryCount
+	pending.ErrorCount += usage.ErrorCount
+}
+
+// Flush adds the queries counted since the last flush to the database, one public dashboard and day at a time. Counts
+// failing to be written are kept for the next flush
+func (t *UsageTracker) Flush(ctx context.Context) error {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[usageKey]*PublicDashboardUsage)
+	t.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	usages := make([]*PublicDashboardUsage, 0, len(pending))
+	for _, usage := range pending {
+		usages = append(usages, usage)
+	}
+	// usages are written in a stable order, which also tells which ones are left when writing one fails
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].PublicDashboardUid != usages[j].PublicDashboardUid {
+			return usages[i].PublicDashboardUid < usages[j].PublicDashboardUid
+		}
+		return usages[i].Day < usages[j].Day
+	})
+
+	for i, usage := range usages {
+		if err := t.store.RecordUsage(ctx, usage); err != nil {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+
+			// the usages not written yet are added to the queries counted during the flush
+			for _, usage := range usages[i:] {
+				t.add(usage)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *UsageTracker) flushAndLog(ctx context.Context) {
+	if err := t.Flush(ctx); err != nil {
+		t.log.FromContext(ctx).Error("Failed to write the usage of public dashboards", "error", err)
+	}
+}
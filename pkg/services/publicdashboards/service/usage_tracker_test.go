@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+func TestUsageTracker(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	busy := &PublicDashboard{Uid: "busy", OrgId: 1}
+	idle := &PublicDashboard{Uid: "idle", OrgId: 2}
+
+	t.Run("adds up the queries of each public dashboard and day", func(t *testing.T) {
+		store := publicdashboards.NewFakePublicDashboardReportStore(t)
+		tracker := ProvideUsageTracker(featuremgmt.WithFeatures(), store)
+
+		tracker.record(busy, false, now)
+		tracker.record(busy, true, now)
+		tracker.record(busy, false, now.Add(24*time.Hour))
+		tracker.record(idle, false, now)
+
+		store.On("RecordUsage", mock.Anything, &PublicDashboardUsage{PublicDashboardUid: "busy", OrgId: 1, Day: "2022-10-01", QueryCount: 2, ErrorCount: 1}).Return(nil).Once()
+		store.On("RecordUsage", mock.Anything, &PublicDashboardUsage{PublicDashboardUid: "busy", OrgId: 1, Day: "2022-10-02", QueryCount: 1}).Return(nil).Once()
+		store.On("RecordUsage", mock.Anything, &PublicDashboardUsage{PublicDashboardUid: "idle", OrgId: 2, Day: "2022-10-01", QueryCount: 1}).Return(nil).Once()
+		require.NoError(t, tracker.Flush(context.Background()))
+
+		// nothing left to write
+		require.NoError(t, tracker.Flush(context.Background()))
+	})
+
+	t.Run("keeps the counts failing to be written for the next flush", func(t *testing.T) {
+		store := publicdashboards.NewFakePublicDashboardReportStore(t)
+		tracker := ProvideUsageTracker(featuremgmt.WithFeatures(), store)
+
+		tracker.record(busy, false, now)
+		tracker.record(idle, false, now)
+
+		store.On("RecordUsage", mock.Anything, &PublicDashboardUsage{PublicDashboardUid: "busy", OrgId: 1, Day: "2022-10-01", QueryCount: 1}).Return(nil).Once()
+		store.On("RecordUsage", mock.Anything, &PublicDashboardUsage{PublicDashboardUid: "idle", OrgId: 2, Day: "2022-10-01", QueryCount: 1}).Return(errors.New("db down")).Once()
+		require.Error(t, tracker.Flush(context.Background()))
+
+		// the written count is not written again, queries counted since are added to the failed one
+		tracker.record(idle, true, now)
+		store.On("RecordUsage", mock.Anything, &PublicDashboardUsage{PublicDashboardUid: "idle", OrgId: 2, Day: "2022-10-01", QueryCount: 2, ErrorCount: 1}).Return(nil).Once()
+		require.NoError(t, tracker.Flush(context.Background()))
+	})
+
+	t.Run("nil tracker does not record", func(t *testing.T) {
+		var tracker *UsageTracker
+		tracker.record(busy, false, now)
+	})
+
+	t.Run("is disabled without the public dashboards feature", func(t *testing.T) {
+		assert.True(t, ProvideUsageTracker(featuremgmt.WithFeatures(), nil).IsDisabled())
+		assert.False(t, ProvideUsageTracker(featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), nil).IsDisabled())
+	})
+}
@@ -2,11 +2,18 @@ package validation
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/models"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/util"
 )
 
+var domainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,}$`)
+
+var countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
 func ValidateSavePublicDashboard(dto *SavePublicDashboardConfigDTO, dashboard *models.Dashboard) error {
 	if hasTemplateVariables(dashboard) {
 		return ErrPublicDashboardHasTemplateVariables
@@ -21,6 +28,78 @@ func hasTemplateVariables(dashboard *models.Dashboard) bool {
 	return len(templateVariables) > 0
 }
 
+// NormalizeEmailAllowlist trims and lower cases the entries of an email allowlist, dropping empty ones. Entries
+// must be email addresses or domains
+func NormalizeEmailAllowlist(allowlist EmailAllowlist) (EmailAllowlist, error) {
+	normalized := make(EmailAllowlist, 0, len(allowlist))
+	for _, entry := range allowlist {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+
+		if !util.IsEmail(entry) && !isDomain(strings.TrimPrefix(entry, "@")) {
+			return nil, ErrPublicDashboardInvalidEmailAllowlist
+		}
+
+		normalized = append(normalized, entry)
+	}
+
+	return normalized, nil
+}
+
+// NormalizeCountryList trims and upper cases the entries of a country list, dropping empty and duplicate ones.
+// Entries must be ISO 3166-1 alpha-2 country codes
+func NormalizeCountryList(countries CountryList) (CountryList, error) {
+	normalized := make(CountryList, 0, len(countries))
+	for _, entry := range countries {
+		entry = strings.ToUpper(strings.TrimSpace(entry))
+		if entry == "" || normalized.Contains(entry) {
+			continue
+		}
+
+		if !countryCodePattern.MatchString(entry) {
+			return nil, ErrPublicDashboardInvalidCountryList
+		}
+
+		normalized = append(normalized, entry)
+	}
+
+	return normalized, nil
+}
+
+// NormalizePanelIdList drops duplicate entries of a panel id list. Entries must be positive panel ids
+func NormalizePanelIdList(panelIds PanelIdList) (PanelIdList, error) {
+	normalized := make(PanelIdList, 0, len(panelIds))
+	for _, panelId := range panelIds {
+		if panelId <= 0 {
+			return nil, ErrPublicDashboardInvalidPanelIdList
+		}
+		if normalized.Contains(panelId) {
+			continue
+		}
+
+		normalized = append(normalized, panelId)
+	}
+
+	return normalized, nil
+}
+
+// ValidateCacheControl checks the cache control durations of a public dashboard, in seconds
+func ValidateCacheControl(maxAge int64, staleWhileRevalidate int64) error {
+	for _, seconds := range []int64{maxAge, staleWhileRevalidate} {
+		if seconds < 0 || seconds > MaxCacheControlSeconds {
+			return ErrPublicDashboardInvalidCacheControl
+		}
+	}
+
+	return nil
+}
+
+func isDomain(domain string) bool {
+	return domainPattern.MatchString(domain)
+}
+
 func ValidateQueryPublicDashboardRequest(req PublicDashboardQueryDTO) error {
 	if req.IntervalMs < 0 {
 		return fmt.Errorf("intervalMS should be greater than 0")
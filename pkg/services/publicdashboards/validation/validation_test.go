@@ -42,3 +42,55 @@ func TestValidateSavePublicDashboard(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestNormalizeEmailAllowlist(t *testing.T) {
+	t.Run("Trims and lower cases entries and drops empty ones", func(t *testing.T) {
+		allowlist, err := NormalizeEmailAllowlist(EmailAllowlist{" Viewer@Example.com ", "", "Grafana.com", "@example.org"})
+		require.NoError(t, err)
+		require.Equal(t, EmailAllowlist{"viewer@example.com", "grafana.com", "@example.org"}, allowlist)
+	})
+
+	t.Run("Returns validation error when an entry is neither an email address nor a domain", func(t *testing.T) {
+		for _, entry := range []string{"not an email", "localhost", "@", "viewer@"} {
+			_, err := NormalizeEmailAllowlist(EmailAllowlist{entry})
+			require.ErrorIs(t, err, ErrPublicDashboardInvalidEmailAllowlist, entry)
+		}
+	})
+}
+
+func TestNormalizeCountryList(t *testing.T) {
+	t.Run("Trims and upper cases entries and drops empty and duplicate ones", func(t *testing.T) {
+		countries, err := NormalizeCountryList(CountryList{" fr ", "", "DE", "Fr"})
+		require.NoError(t, err)
+		require.Equal(t, CountryList{"FR", "DE"}, countries)
+	})
+
+	t.Run("Returns validation error when an entry is not a country code", func(t *testing.T) {
+		for _, entry := range []string{"FRA", "F", "France", "1A"} {
+			_, err := NormalizeCountryList(CountryList{entry})
+			require.ErrorIs(t, err, ErrPublicDashboardInvalidCountryList, entry)
+		}
+	})
+}
+
+func TestNormalizePanelIdList(t *testing.T) {
+	t.Run("Drops duplicate entries", func(t *testing.T) {
+		panelIds, err := NormalizePanelIdList(PanelIdList{2, 1, 2})
+		require.NoError(t, err)
+		require.Equal(t, PanelIdList{2, 1}, panelIds)
+	})
+
+	t.Run("Returns validation error when an entry is not a positive panel id", func(t *testing.T) {
+		for _, entry := range []int64{0, -1} {
+			_, err := NormalizePanelIdList(PanelIdList{entry})
+			require.ErrorIs(t, err, ErrPublicDashboardInvalidPanelIdList, entry)
+		}
+	})
+}
+
+func TestValidateCacheControl(t *testing.T) {
+	require.NoError(t, ValidateCacheControl(0, 0))
+	require.NoError(t, ValidateCacheControl(60, MaxCacheControlSeconds))
+	require.ErrorIs(t, ValidateCacheControl(-1, 0), ErrPublicDashboardInvalidCacheControl)
+	require.ErrorIs(t, ValidateCacheControl(0, MaxCacheControlSeconds+1), ErrPublicDashboardInvalidCacheControl)
+}
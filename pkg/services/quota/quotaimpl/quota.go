@@ -8,22 +8,25 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 type Service struct {
 	store            store
 	authTokenService models.ActiveTokenService
+	userService      user.Service
 	Cfg              *setting.Cfg
 	SQLStore         sqlstore.Store
 	Logger           log.Logger
 }
 
-func ProvideService(db db.DB, cfg *setting.Cfg, tokenService models.ActiveTokenService, ss *sqlstore.SQLStore) quota.Service {
+func ProvideService(db db.DB, cfg *setting.Cfg, tokenService models.ActiveTokenService, ss *sqlstore.SQLStore, userService user.Service) quota.Service {
 	return &Service{
 		store:            &sqlStore{db: db},
 		Cfg:              cfg,
 		authTokenService: tokenService,
+		userService:      userService,
 		SQLStore:         ss,
 		Logger:           log.New("quota_service"),
 	}
@@ -118,19 +121,26 @@ func (s *Service) CheckQuotaReached(ctx context.Context, target string, scopePar
 			if scopeParams == nil || scopeParams.UserID == 0 {
 				continue
 			}
-			query := models.GetUserQuotaByTargetQuery{UserId: scopeParams.UserID, Target: scope.Target, Default: scope.DefaultLimit, UnifiedAlertingEnabled: s.Cfg.UnifiedAlerting.IsEnabled()}
-			// TODO: move GetUserQuotaByTarget from sqlstore to quota store
-			if err := s.SQLStore.GetUserQuotaByTarget(ctx, &query); err != nil {
+			quotas, err := s.userService.GetQuotas(ctx, &user.GetQuotasQuery{
+				UserID:                 scopeParams.UserID,
+				Targets:                []string{scope.Target},
+				UnifiedAlertingEnabled: s.Cfg.UnifiedAlerting.IsEnabled(),
+			})
+			if err != nil {
 				return true, err
 			}
-			if query.Result.Limit < 0 {
+			if len(quotas) == 0 {
 				continue
 			}
-			if query.Result.Limit == 0 {
+			userQuota := quotas[0]
+			if userQuota.Limit < 0 {
+				continue
+			}
+			if userQuota.Limit == 0 {
 				return true, nil
 			}
 
-			if query.Result.Used >= query.Result.Limit {
+			if userQuota.Used >= userQuota.Limit {
 				return true, nil
 			}
 		}
@@ -140,6 +150,11 @@ func (s *Service) CheckQuotaReached(ctx context.Context, target string, scopePar
 
 func (s *Service) getQuotaScopes(target string) ([]models.QuotaScope, error) {
 	scopes := make([]models.QuotaScope, 0)
+	// users are not limited without per user limits, but limits set for a user still apply
+	userQuota := s.Cfg.Quota.User
+	if userQuota == nil {
+		userQuota = setting.UnlimitedUserQuota()
+	}
 	switch target {
 	case "user":
 		scopes = append(scopes,
@@ -150,7 +165,7 @@ func (s *Service) getQuotaScopes(target string) ([]models.QuotaScope, error) {
 	case "org":
 		scopes = append(scopes,
 			models.QuotaScope{Name: "global", Target: target, DefaultLimit: s.Cfg.Quota.Global.Org},
-			models.QuotaScope{Name: "user", Target: "org_user", DefaultLimit: s.Cfg.Quota.User.Org},
+			models.QuotaScope{Name: "user", Target: "org_user", DefaultLimit: userQuota.Org},
 		)
 		return scopes, nil
 	case "dashboard":
@@ -165,18 +180,25 @@ func (s *Service) getQuotaScopes(target string) ([]models.QuotaScope, error) {
 				Target:       target,
 				DefaultLimit: s.Cfg.Quota.Org.Dashboard,
 			},
+			models.QuotaScope{
+				Name:         "user",
+				Target:       target,
+				DefaultLimit: userQuota.Dashboard,
+			},
 		)
 		return scopes, nil
 	case "data_source":
 		scopes = append(scopes,
 			models.QuotaScope{Name: "global", Target: target, DefaultLimit: s.Cfg.Quota.Global.DataSource},
 			models.QuotaScope{Name: "org", Target: target, DefaultLimit: s.Cfg.Quota.Org.DataSource},
+			models.QuotaScope{Name: "user", Target: target, DefaultLimit: userQuota.DataSource},
 		)
 		return scopes, nil
 	case "api_key":
 		scopes = append(scopes,
 			models.QuotaScope{Name: "global", Target: target, DefaultLimit: s.Cfg.Quota.Global.ApiKey},
 			models.QuotaScope{Name: "org", Target: target, DefaultLimit: s.Cfg.Quota.Org.ApiKey},
+			models.QuotaScope{Name: "user", Target: target, DefaultLimit: userQuota.ApiKey},
 		)
 		return scopes, nil
 	case "session":
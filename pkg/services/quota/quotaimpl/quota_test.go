@@ -5,6 +5,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestQuotaService(t *testing.T) {
@@ -17,6 +23,55 @@ func TestQuotaService(t *testing.T) {
 		err := quotaService.DeleteByUser(context.Background(), 1)
 		require.NoError(t, err)
 	})
+
+	t.Run("check the quotas of users", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.Quota = setting.QuotaSettings{
+			Enabled: true,
+			Global:  &setting.GlobalQuota{Org: -1},
+			User:    &setting.UserQuota{Org: 10},
+		}
+		userService := usertest.NewUserServiceFake()
+		quotaService := Service{Cfg: cfg, userService: userService, Logger: log.New("test.logger")}
+		params := &quota.ScopeParameters{OrgID: 1, UserID: 1}
+
+		userService.ExpectedQuotas = []*user.Quota{{UserID: 1, Target: "org_user", Limit: 2, Used: 1}}
+		reached, err := quotaService.CheckQuotaReached(context.Background(), "org", params)
+		require.NoError(t, err)
+		require.False(t, reached)
+
+		userService.ExpectedQuotas = []*user.Quota{{UserID: 1, Target: "org_user", Limit: 2, Used: 2}}
+		reached, err = quotaService.CheckQuotaReached(context.Background(), "org", params)
+		require.NoError(t, err)
+		require.True(t, reached)
+
+		userService.ExpectedQuotas = []*user.Quota{{UserID: 1, Target: "org_user", Limit: -1, Used: 2}}
+		reached, err = quotaService.CheckQuotaReached(context.Background(), "org", params)
+		require.NoError(t, err)
+		require.False(t, reached)
+	})
+
+	t.Run("check the quotas of users without per user quota settings", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.Quota = setting.QuotaSettings{
+			Enabled: true,
+			Global:  &setting.GlobalQuota{Org: -1},
+		}
+		userService := usertest.NewUserServiceFake()
+		quotaService := Service{Cfg: cfg, userService: userService, Logger: log.New("test.logger")}
+		params := &quota.ScopeParameters{OrgID: 1, UserID: 1}
+
+		// limits set for a user still apply
+		userService.ExpectedQuotas = []*user.Quota{{UserID: 1, Target: "org_user", Limit: 1, Used: 1}}
+		reached, err := quotaService.CheckQuotaReached(context.Background(), "org", params)
+		require.NoError(t, err)
+		require.True(t, reached)
+
+		userService.ExpectedQuotas = []*user.Quota{}
+		reached, err = quotaService.CheckQuotaReached(context.Background(), "org", params)
+		require.NoError(t, err)
+		require.False(t, reached)
+	})
 }
 
 type FakeQuotaStore struct {
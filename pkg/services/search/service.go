@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/pagination"
 
 	"github.com/grafana/grafana/pkg/models"
 )
@@ -32,8 +33,7 @@ type Query struct {
 	Tags          []string
 	OrgId         int64
 	SignedInUser  *user.SignedInUser
-	Limit         int64
-	Page          int64
+	Pagination    pagination.Page
 	IsStarred     bool
 	Type          string
 	DashboardUIDs []string
@@ -68,8 +68,7 @@ func (s *SearchService) SearchHandler(ctx context.Context, query *Query) error {
 		Type:          query.Type,
 		FolderIds:     query.FolderIds,
 		Tags:          query.Tags,
-		Limit:         query.Limit,
-		Page:          query.Page,
+		Pagination:    query.Pagination,
 		Permission:    query.Permission,
 	}
 
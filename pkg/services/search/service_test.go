@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/star/startest"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 func TestSearch_SortedResults(t *testing.T) {
@@ -41,7 +42,7 @@ func TestSearch_SortedResults(t *testing.T) {
 	}
 
 	query := &Query{
-		Limit: 2000,
+		Pagination: pagination.Page{PerPage: 2000},
 		SignedInUser: &user.SignedInUser{
 			IsGrafanaAdmin: true,
 		},
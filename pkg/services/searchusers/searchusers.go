@@ -1,18 +1,24 @@
 package searchusers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 type Service interface {
 	SearchUsers(c *models.ReqContext) response.Response
 	SearchUsersWithPaging(c *models.ReqContext) response.Response
+	ExportUsers(c *models.ReqContext) response.Response
 }
 
 type OSSService struct {
@@ -42,6 +48,9 @@ func ProvideUsersService(searchUserFilter user.SearchUserFilter, userService use
 func (s *OSSService) SearchUsers(c *models.ReqContext) response.Response {
 	result, err := s.SearchUser(c)
 	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) || errors.Is(err, pagination.ErrInvalidSortOrder) || errors.Is(err, user.ErrInvalidAttribute) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
 		return response.Error(500, "Failed to fetch users", err)
 	}
 
@@ -61,21 +70,70 @@ func (s *OSSService) SearchUsers(c *models.ReqContext) response.Response {
 func (s *OSSService) SearchUsersWithPaging(c *models.ReqContext) response.Response {
 	result, err := s.SearchUser(c)
 	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) || errors.Is(err, pagination.ErrInvalidSortOrder) || errors.Is(err, user.ErrInvalidAttribute) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
 		return response.Error(500, "Failed to fetch users", err)
 	}
 
 	return response.JSON(http.StatusOK, result)
 }
 
-func (s *OSSService) SearchUser(c *models.ReqContext) (*user.SearchUserQueryResult, error) {
-	perPage := c.QueryInt("perpage")
-	if perPage <= 0 {
-		perPage = 1000
+// swagger:route GET /users/export users exportUsers
+//
+// Export users.
+//
+// Streams all the users matching the filters of the user search that the authenticated user has permission to view, as CSV or JSON.
+//
+// Produces:
+// - text/csv
+// - application/json
+//
+// Responses:
+// 200: exportUsersResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *OSSService) ExportUsers(c *models.ReqContext) response.Response {
+	format := user.ExportFormat(c.Query("format"))
+	contentType := "text/csv; charset=utf-8"
+	switch format {
+	case "", user.ExportFormatCSV:
+		format = user.ExportFormatCSV
+	case user.ExportFormatJSON:
+		contentType = "application/json"
+	default:
+		return response.Error(http.StatusBadRequest, user.ErrInvalidExport.Error(), nil)
+	}
+
+	query, err := s.searchQuery(c)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) || errors.Is(err, pagination.ErrInvalidSortOrder) || errors.Is(err, user.ErrInvalidAttribute) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(500, "Failed to export users", err)
+	}
+
+	c.Resp.Header().Set("Content-Type", contentType)
+	c.Resp.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="users.%s"`, format))
+	if err := s.userService.Export(c.Req.Context(), query, format, c.Resp); err != nil {
+		// the export can only fail with an error response until its first page is written
+		if !c.Resp.Written() {
+			c.Resp.Header().Del("Content-Disposition")
+			return response.Error(500, "Failed to export users", err)
+		}
+		c.Logger.Error("Failed to export users", "error", err)
 	}
-	page := c.QueryInt("page")
 
-	if page < 1 {
-		page = 1
+	return nil
+}
+
+// searchQuery builds the user search query from the query parameters of the request
+func (s *OSSService) searchQuery(c *models.ReqContext) (*user.SearchUsersQuery, error) {
+	page, err := pagination.NewPage(c.QueryInt64("page"), c.QueryInt64("perpage"), c.Query("cursor"), c.Query("order"))
+	if err != nil {
+		return nil, err
 	}
 
 	searchQuery := c.Query("query")
@@ -92,9 +150,37 @@ func (s *OSSService) SearchUser(c *models.ReqContext) (*user.SearchUserQueryResu
 		SignedInUser: c.SignedInUser,
 		Query:        searchQuery,
 		Filters:      filters,
-		Page:         page,
-		Limit:        perPage,
+		Pagination:   page.WithDefaults(1000, 0),
+		AuthModule:   c.Query("authModule"),
+		WithOrgs:     c.QueryBool("orgs"),
+	}
+	for _, attribute := range c.QueryStrings("attribute") {
+		key, value, ok := strings.Cut(attribute, ":")
+		if !ok || key == "" {
+			return nil, user.ErrInvalidAttribute
+		}
+		if query.Attributes == nil {
+			query.Attributes = make(map[string]string)
+		}
+		query.Attributes[key] = value
+	}
+	if lastSeenBefore := c.QueryInt64("lastSeenBefore"); lastSeenBefore > 0 {
+		t := time.UnixMilli(lastSeenBefore)
+		query.LastSeenBefore = &t
 	}
+	if lastSeenAfter := c.QueryInt64("lastSeenAfter"); lastSeenAfter > 0 {
+		t := time.UnixMilli(lastSeenAfter)
+		query.LastSeenAfter = &t
+	}
+	return query, nil
+}
+
+func (s *OSSService) SearchUser(c *models.ReqContext) (*user.SearchUserQueryResult, error) {
+	query, err := s.searchQuery(c)
+	if err != nil {
+		return nil, err
+	}
+
 	res, err := s.userService.Search(c.Req.Context(), query)
 	if err != nil {
 		return nil, err
@@ -110,8 +196,10 @@ func (s *OSSService) SearchUser(c *models.ReqContext) (*user.SearchUserQueryResu
 		}
 	}
 
-	res.Page = page
-	res.PerPage = perPage
+	// the store pages by login, its next cursor seeks to the following page
+	nextCursor := res.NextCursor
+	res.Result = pagination.NewResult(query.Pagination, len(res.Users), res.TotalCount)
+	res.NextCursor = nextCursor
 
 	return res, nil
 }
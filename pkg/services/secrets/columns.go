@@ -0,0 +1,153 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	// ErrColumnNotEncrypted is returned when an EncryptedString with a value is written before EncryptColumns is called,
+	// so the value isn't stored in plain text
+	ErrColumnNotEncrypted = errors.New("encrypted column written without being encrypted")
+	// ErrColumnNotDecrypted is returned when the value of an EncryptedString read from the database is used before
+	// DecryptColumns is called
+	ErrColumnNotDecrypted = errors.New("encrypted column used without being decrypted")
+)
+
+var encryptedStringType = reflect.TypeOf(EncryptedString{})
+
+// EncryptedString is a string field of a bean whose column is encrypted by the secrets service. Stores declare their
+// sensitive columns with it, call EncryptColumns before writing the beans and DecryptColumns after reading them. The
+// column holds the base64 encoded payload of the secrets service, so it can be of any text type.
+//
+// Encryption MUST NOT happen within database transactions, see Service, so the beans are encrypted before the
+// transaction starts.
+type EncryptedString struct {
+	value      string
+	ciphertext []byte
+	decrypted  bool
+}
+
+// NewEncryptedString returns an EncryptedString with a value to encrypt
+func NewEncryptedString(value string) EncryptedString {
+	return EncryptedString{value: value, decrypted: true}
+}
+
+// Set changes the value, which has to be encrypted again before being written
+func (s *EncryptedString) Set(value string) {
+	*s = NewEncryptedString(value)
+}
+
+// Value returns the value, or ErrColumnNotDecrypted when it was read from the database but not decrypted
+func (s EncryptedString) Value() (string, error) {
+	if len(s.ciphertext) > 0 && !s.decrypted {
+		return "", ErrColumnNotDecrypted
+	}
+	return s.value, nil
+}
+
+// FromDB implements core.Conversion, keeping the ciphertext until DecryptColumns is called
+func (s *EncryptedString) FromDB(data []byte) error {
+	*s = EncryptedString{}
+	if len(data) == 0 {
+		return nil
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return fmt.Errorf("failed to decode encrypted column: %w", err)
+	}
+	s.ciphertext = ciphertext
+	return nil
+}
+
+// ToDB implements core.Conversion, refusing to write a value which wasn't encrypted
+func (s *EncryptedString) ToDB() ([]byte, error) {
+	if len(s.ciphertext) == 0 {
+		if s.value != "" {
+			return nil, ErrColumnNotEncrypted
+		}
+		return []byte{}, nil
+	}
+	return []byte(base64.StdEncoding.EncodeToString(s.ciphertext)), nil
+}
+
+// EncryptColumns encrypts the EncryptedString fields of beans, which are pointers to structs or to slices of structs.
+// The fields which are already encrypted are left as they are.
+func EncryptColumns(ctx context.Context, svc Service, opt EncryptionOptions, beans ...interface{}) error {
+	return forEachEncryptedString(beans, func(s *EncryptedString) error {
+		if len(s.ciphertext) > 0 || s.value == "" {
+			return nil
+		}
+		ciphertext, err := svc.Encrypt(ctx, []byte(s.value), opt)
+		if err != nil {
+			return err
+		}
+		s.ciphertext = ciphertext
+		return nil
+	})
+}
+
+// DecryptColumns decrypts the EncryptedString fields of beans, which are pointers to structs or to slices of structs
+func DecryptColumns(ctx context.Context, svc Service, beans ...interface{}) error {
+	return forEachEncryptedString(beans, func(s *EncryptedString) error {
+		if s.decrypted || len(s.ciphertext) == 0 {
+			return nil
+		}
+		value, err := svc.Decrypt(ctx, s.ciphertext)
+		if err != nil {
+			return err
+		}
+		s.value = string(value)
+		s.decrypted = true
+		return nil
+	})
+}
+
+// forEachEncryptedString calls fn with the EncryptedString fields of beans, including the ones of embedded structs
+func forEachEncryptedString(beans []interface{}, fn func(s *EncryptedString) error) error {
+	for _, bean := range beans {
+		v := reflect.ValueOf(bean)
+		if v.Kind() != reflect.Ptr || v.IsNil() {
+			return fmt.Errorf("encrypted columns need a pointer to a bean, got %T", bean)
+		}
+		if err := walkEncryptedStrings(v.Elem(), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkEncryptedStrings(v reflect.Value, fn func(s *EncryptedString) error) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return walkEncryptedStrings(v.Elem(), fn)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := walkEncryptedStrings(v.Index(i), fn); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		if v.Type() == encryptedStringType {
+			return fn(v.Addr().Interface().(*EncryptedString))
+		}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if field.Type == encryptedStringType || field.Anonymous {
+				if err := walkEncryptedStrings(v.Field(i), fn); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package secrets_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/database"
+	"github.com/grafana/grafana/pkg/services/secrets/manager"
+)
+
+type accessLog struct {
+	Id int64
+	Ip secrets.EncryptedString
+}
+
+func TestEncryptedString(t *testing.T) {
+	t.Run("a value read from the database needs to be decrypted", func(t *testing.T) {
+		var s secrets.EncryptedString
+		require.NoError(t, s.FromDB([]byte("Y2lwaGVydGV4dA==")))
+		_, err := s.Value()
+		require.ErrorIs(t, err, secrets.ErrColumnNotDecrypted)
+	})
+
+	t.Run("a value needs to be encrypted before being written", func(t *testing.T) {
+		s := secrets.NewEncryptedString("10.0.0.1")
+		_, err := s.ToDB()
+		require.ErrorIs(t, err, secrets.ErrColumnNotEncrypted)
+	})
+
+	t.Run("an empty value is written as is", func(t *testing.T) {
+		var s secrets.EncryptedString
+		data, err := s.ToDB()
+		require.NoError(t, err)
+		require.Empty(t, data)
+	})
+}
+
+func TestIntegrationEncryptedColumns(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	svc := manager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
+	ctx := context.Background()
+
+	err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("access_log").Sync2(new(accessLog))
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.DropTable("access_log")
+		})
+	})
+
+	logs := []*accessLog{
+		{Ip: secrets.NewEncryptedString("10.0.0.1")},
+		{Ip: secrets.NewEncryptedString("10.0.0.2")},
+		{},
+	}
+	require.NoError(t, secrets.EncryptColumns(ctx, svc, secrets.WithoutScope(), &logs))
+	err = sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		for _, l := range logs {
+			if _, err := sess.Insert(l); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	t.Run("the values are stored encrypted", func(t *testing.T) {
+		var stored []string
+		err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.SQL("SELECT ip FROM access_log WHERE ip != ''").Find(&stored)
+		})
+		require.NoError(t, err)
+		require.Len(t, stored, 2)
+		require.NotContains(t, stored, "10.0.0.1")
+		require.NotContains(t, stored, "10.0.0.2")
+	})
+
+	t.Run("the values are decrypted after being read", func(t *testing.T) {
+		var read []accessLog
+		err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table("access_log").OrderBy("id").Find(&read)
+		})
+		require.NoError(t, err)
+		require.NoError(t, secrets.DecryptColumns(ctx, svc, &read))
+
+		values := make([]string, 0, len(read))
+		for _, l := range read {
+			value, err := l.Ip.Value()
+			require.NoError(t, err)
+			values = append(values, value)
+		}
+		require.Equal(t, []string{"10.0.0.1", "10.0.0.2", ""}, values)
+	})
+
+	t.Run("beans which aren't pointers are rejected", func(t *testing.T) {
+		require.Error(t, secrets.DecryptColumns(ctx, svc, accessLog{}))
+	})
+}
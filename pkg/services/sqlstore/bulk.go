@@ -0,0 +1,403 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// defaultBulkRetryBackoff is the delay before the first retry of a batch when the settings don't set one
+const defaultBulkRetryBackoff = 50 * time.Millisecond
+
+// BulkOpSettings configures the bulk operations of a session
+type BulkOpSettings struct {
+	// BatchSize is the maximum number of rows handled by a statement. It is capped by the parameter limit of the
+	// database, which is also the default
+	BatchSize int
+	// OnBatch is called after each batch with the number of rows done and to do in total, and the number of rows
+	// affected by the batch, so long operations can report their progress. The total is -1 for streamed records
+	OnBatch func(done, total int, affected int64)
+	// Retries is the number of times a batch failing because of lock contention, such as a deadlock, is retried.
+	// Batches of sessions in a transaction are never retried, as the failure aborts the transaction
+	Retries int
+	// RetryBackoff is the delay before the first retry of a batch, doubled on each retry, with jitter
+	RetryBackoff time.Duration
+	// Concurrency is the number of batches run in parallel, on their own sessions. Batches of sessions in a
+	// transaction, and batches of SQLite, run one at a time. Parallel batches are not run in a transaction, so callers
+	// must only use it when the batches are independent
+	Concurrency int
+	// Copy inserts the records with COPY FROM STDIN on Postgres, which is much faster than multi-row INSERTs for large
+	// inserts. Copied batches run in transactions of their own, so sessions in a transaction still use INSERTs
+	Copy bool
+	// ContinueOnError runs the rows of a failing batch one at a time, and reports the rows which still fail with a
+	// BulkErrors once all the batches ran, instead of stopping at the first failing batch. Sessions in a transaction on
+	// Postgres stop at the first error, as it aborts the transaction
+	ContinueOnError bool
+	// ReturnIDs sets the auto incremented keys of the inserted records to the IDs generated by the database, so the rows
+	// referencing them can be inserted without querying them. The records must be pointers or elements of the inserted
+	// slice, and streamed records must be pointers. Databases other than Postgres and MySQL insert the records one at
+	// a time
+	ReturnIDs bool
+}
+
+// BulkRowError is the error of a row of a bulk operation, identified by its index in the records or keys
+type BulkRowError struct {
+	Index int
+	Err   error
+}
+
+// BulkErrors is returned by the bulk operations run with ContinueOnError when rows failed
+type BulkErrors struct {
+	// Rows are the rows which failed, ordered by index
+	Rows []BulkRowError
+}
+
+func (e *BulkErrors) Error() string {
+	return fmt.Sprintf("%d rows failed, the first one, row %d: %v", len(e.Rows), e.Rows[0].Index, e.Rows[0].Err)
+}
+
+// batchSize returns the number of rows of a batch using paramsPerRow parameters each
+func (s BulkOpSettings) batchSize(paramsPerRow int) int {
+	if paramsPerRow < 1 {
+		paramsPerRow = 1
+	}
+	limit := dialect.MaxParameters() / paramsPerRow
+	if s.BatchSize <= 0 || s.BatchSize > limit {
+		return limit
+	}
+	return s.BatchSize
+}
+
+// batchFunc runs the batch of rows [start, end) of a bulk operation on sess, and returns the number of rows it affected
+type batchFunc func(sess *DBSession, start, end int) (int64, error)
+
+// bulkConcurrency returns the number of batches of a bulk operation which can run in parallel. Batches of a session in a
+// transaction run on the session, one at a time, and so do the batches of SQLite which only has one writer at a time
+func (sess *DBSession) bulkConcurrency(opts BulkOpSettings) int {
+	if opts.Concurrency <= 1 || sess.transactionOpen || sess.engine == nil || dialect.DriverName() == migrator.SQLite {
+		return 1
+	}
+	return opts.Concurrency
+}
+
+// batchRunner runs the batches of a bulk operation, on its session or on new sessions in parallel, and reports their
+// progress. The number of rows affected includes the ones of the batches done before an error
+type batchRunner struct {
+	sess  *DBSession
+	opts  BulkOpSettings
+	total int
+
+	group *errgroup.Group
+	ctx   context.Context
+
+	mu        sync.Mutex
+	done      int
+	affected  int64
+	err       error
+	rowErrors []BulkRowError
+}
+
+// callerContext returns the context of the caller of sess, the background context for sessions started without one
+func (sess *DBSession) callerContext() context.Context {
+	if sess.ctx == nil {
+		return context.Background()
+	}
+	return sess.ctx
+}
+
+// newBatchRunner returns the runner of the batches of a bulk operation on sess. Batches running in parallel run with
+// the context of the caller of the session, so they stop being scheduled once it is cancelled
+func (sess *DBSession) newBatchRunner(opts BulkOpSettings, total, concurrency int) *batchRunner {
+	r := &batchRunner{sess: sess, opts: opts, total: total}
+	if concurrency > 1 {
+		r.group, r.ctx = errgroup.WithContext(sess.callerContext())
+		r.group.SetLimit(concurrency)
+	}
+	return r
+}
+
+// run runs a batch, or schedules it when batches run in parallel. It returns an error once a batch failed, so no more
+// batches are run
+func (r *batchRunner) run(start, end int, fn batchFunc) error {
+	fn = r.splitOnError(withBatchRetries(r.opts, fn))
+	if r.group == nil {
+		affected, err := fn(r.sess, start, end)
+		r.report(end-start, affected, err)
+		return err
+	}
+
+	if err := r.ctx.Err(); err != nil {
+		return err
+	}
+	r.group.Go(func() error {
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+		sess := &DBSession{Session: r.sess.engine.NewSession().Context(r.ctx), engine: r.sess.engine, ctx: r.ctx}
+		defer sess.Close()
+		affected, err := fn(sess, start, end)
+		r.report(end-start, affected, err)
+		return err
+	})
+	return nil
+}
+
+// splitOnError runs the rows of the batches of fn which fail one at a time when the operation continues on errors, and
+// records the errors of the rows
+func (r *batchRunner) splitOnError(fn batchFunc) batchFunc {
+	if !r.opts.ContinueOnError {
+		return fn
+	}
+	return func(sess *DBSession, start, end int) (int64, error) {
+		affected, err := fn(sess, start, end)
+		if err == nil || (sess.transactionOpen && dialect.DriverName() == migrator.Postgres) {
+			return affected, err
+		}
+		if end-start == 1 {
+			r.recordRowError(start, err)
+			return affected, nil
+		}
+
+		// the statement of the failed batch was rolled back, its rows are run again
+		affected = 0
+		for i := start; i < end; i++ {
+			rowAffected, err := fn(sess, i, i+1)
+			affected += rowAffected
+			if err != nil {
+				r.recordRowError(i, err)
+			}
+		}
+		return affected, nil
+	}
+}
+
+func (r *batchRunner) recordRowError(index int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rowErrors = append(r.rowErrors, BulkRowError{Index: index, Err: err})
+}
+
+func (r *batchRunner) report(rows int, affected int64, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.affected += affected
+	if err != nil {
+		if r.err == nil {
+			r.err = err
+		}
+		return
+	}
+	r.done += rows
+	if r.opts.OnBatch != nil {
+		r.opts.OnBatch(r.done, r.total, affected)
+	}
+}
+
+// wait waits for the batches running in parallel, and returns the number of rows affected by all the batches, and
+// the error of the first batch which failed
+func (r *batchRunner) wait() (int64, error) {
+	if r.group != nil {
+		_ = r.group.Wait()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil && len(r.rowErrors) > 0 {
+		sort.Slice(r.rowErrors, func(i, j int) bool { return r.rowErrors[i].Index < r.rowErrors[j].Index })
+		return r.affected, &BulkErrors{Rows: r.rowErrors}
+	}
+	return r.affected, r.err
+}
+
+// inBatches runs fn on the successive batches of total rows, and returns the number of rows they affected
+func (sess *DBSession) inBatches(total, batchSize int, opts BulkOpSettings, fn batchFunc) (int64, error) {
+	r := sess.newBatchRunner(opts, total, sess.bulkConcurrency(opts))
+	for start := 0; start < total; start += batchSize {
+		end := start + batchSize
+		if end > total {
+			end = total
+		}
+		if err := r.run(start, end, fn); err != nil {
+			break
+		}
+	}
+	return r.wait()
+}
+
+// withBatchRetries retries the batches of fn failing because of lock contention as configured by opts. It stops
+// waiting for the next retry once the context of the caller is cancelled
+func withBatchRetries(opts BulkOpSettings, fn batchFunc) batchFunc {
+	if opts.Retries <= 0 {
+		return fn
+	}
+
+	backoff := opts.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultBulkRetryBackoff
+	}
+	return func(sess *DBSession, start, end int) (int64, error) {
+		ctx := sess.callerContext()
+		delay := backoff
+		for retry := 0; ; retry++ {
+			affected, err := fn(sess, start, end)
+			if err == nil || sess.transactionOpen || retry >= opts.Retries || !dialect.IsLockContention(err) {
+				return affected, err
+			}
+
+			jittered := delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+			sessionLogger.Warn("Batch failed because of lock contention, retrying", "retry", retry+1, "delay", jittered, "error", err)
+			timer := time.NewTimer(jittered)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return affected, ctx.Err()
+			case <-timer.C:
+			}
+			delay *= 2
+		}
+	}
+}
+
+// BulkInsert inserts records, a slice of beans of table, in batches which fit in the parameter limit of the database.
+// It returns the number of inserted rows, including the ones of the batches inserted before an error
+func (sess *DBSession) BulkInsert(table interface{}, records interface{}, opts BulkOpSettings) (int64, error) {
+	slice := reflect.ValueOf(records)
+	if slice.Kind() != reflect.Slice {
+		return 0, errors.New("bulk insert needs a slice of records")
+	}
+	if slice.Len() == 0 {
+		return 0, nil
+	}
+
+	batchSize := sess.insertBatchSize(slice.Index(0).Interface(), opts)
+	return sess.inBatches(slice.Len(), batchSize, opts, func(sess *DBSession, start, end int) (int64, error) {
+		return sess.insertBatch(table, slice.Slice(start, end), opts)
+	})
+}
+
+// RecordIterator returns the next record of a stream, and false once the stream has no more records
+type RecordIterator func() (record interface{}, ok bool, err error)
+
+// RecordsFromChannel returns an iterator over the records sent to ch, until it is closed
+func RecordsFromChannel(ch <-chan interface{}) RecordIterator {
+	return func() (interface{}, bool, error) {
+		record, ok := <-ch
+		return record, ok, nil
+	}
+}
+
+// drain reads the records left in a stream until its end or an error, so the producers of the stream, such as the
+// goroutines sending to the channel of RecordsFromChannel, are not blocked forever when it is not read to the end
+func (next RecordIterator) drain() {
+	for {
+		if _, ok, err := next(); !ok || err != nil {
+			return
+		}
+	}
+}
+
+// BulkInsertStream inserts the records of a stream, which must all be beans of table of the same type, like BulkInsert.
+// Only a batch of records is held in memory at a time, so streams can be larger than the memory. When the insert stops
+// early because of an error, the records left in the stream are read and dropped
+func (sess *DBSession) BulkInsertStream(table interface{}, next RecordIterator, opts BulkOpSettings) (int64, error) {
+	r := sess.newBatchRunner(opts, -1, sess.bulkConcurrency(opts))
+	var batch reflect.Value
+	var batchSize, scheduled int
+	flush := func() error {
+		records, first := batch, scheduled
+		batch = reflect.MakeSlice(batch.Type(), 0, batchSize)
+		scheduled += records.Len()
+		return r.run(first, scheduled, func(sess *DBSession, start, end int) (int64, error) {
+			return sess.insertBatch(table, records.Slice(start-first, end-first), opts)
+		})
+	}
+
+	for {
+		record, ok, err := next()
+		if err != nil {
+			inserted, _ := r.wait()
+			return inserted, err
+		}
+		if !ok {
+			break
+		}
+
+		if !batch.IsValid() {
+			batchSize = sess.insertBatchSize(record, opts)
+			batch = reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(record)), 0, batchSize)
+		}
+		if reflect.TypeOf(record) != batch.Type().Elem() {
+			next.drain()
+			inserted, _ := r.wait()
+			return inserted, fmt.Errorf("streamed records must all be of type %s, got %T", batch.Type().Elem(), record)
+		}
+		if opts.ReturnIDs && reflect.TypeOf(record).Kind() != reflect.Ptr {
+			next.drain()
+			inserted, _ := r.wait()
+			return inserted, errIDsNotSettable
+		}
+		batch = reflect.Append(batch, reflect.ValueOf(record))
+		if batch.Len() == batchSize {
+			if err := flush(); err != nil {
+				next.drain()
+				return r.wait()
+			}
+		}
+	}
+
+	if batch.IsValid() && batch.Len() > 0 {
+		_ = flush()
+	}
+	return r.wait()
+}
+
+// BulkInsertT inserts records in the table of their type, like BulkInsert
+func BulkInsertT[T any](sess *DBSession, records []T, opts BulkOpSettings) (int64, error) {
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	return sess.inBatches(len(records), sess.insertBatchSize(records[0], opts), opts, func(sess *DBSession, start, end int) (int64, error) {
+		return sess.insertBatch(nil, reflect.ValueOf(records[start:end]), opts)
+	})
+}
+
+// BulkDelete deletes the rows of a table whose key column is one of keys, in batches whose IN clauses fit in the
+// parameter limit of the database. It returns the number of deleted rows, including the ones of the batches deleted
+// before an error
+func (sess *DBSession) BulkDelete(table, keyColumn string, keys []interface{}, opts BulkOpSettings) (int64, error) {
+	return sess.bulkDelete(table, keyColumn, len(keys), func(start, end int) []interface{} {
+		return keys[start:end]
+	}, opts)
+}
+
+// BulkDeleteT deletes the rows of a table whose key column is one of keys, like BulkDelete
+func BulkDeleteT[K any](sess *DBSession, table, keyColumn string, keys []K, opts BulkOpSettings) (int64, error) {
+	return sess.bulkDelete(table, keyColumn, len(keys), func(start, end int) []interface{} {
+		batch := make([]interface{}, 0, end-start)
+		for _, key := range keys[start:end] {
+			batch = append(batch, key)
+		}
+		return batch
+	}, opts)
+}
+
+func (sess *DBSession) bulkDelete(table, keyColumn string, total int, keys func(start, end int) []interface{}, opts BulkOpSettings) (int64, error) {
+	return sess.inBatches(total, opts.batchSize(1), opts, func(sess *DBSession, start, end int) (int64, error) {
+		rawSQL := fmt.Sprintf("DELETE FROM %s WHERE %s IN (?%s)", dialect.Quote(table), dialect.Quote(keyColumn), strings.Repeat(",?", end-start-1))
+		res, err := sess.Exec(append([]interface{}{rawSQL}, keys(start, end)...)...)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	})
+}
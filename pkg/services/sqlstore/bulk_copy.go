@@ -0,0 +1,161 @@
+package sqlstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/lib/pq"
+	"xorm.io/core"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// defaultCopyBatchSize is the number of rows of a COPY batch when the settings don't set a batch size. COPY has no
+// parameter, so its batches are only limited to bound the work lost to a failure
+const defaultCopyBatchSize = 10000
+
+// useCopy returns true when the batches of a bulk insert can be copied with COPY FROM STDIN. COPY runs in a transaction
+// of its own, so it is not used by sessions in a transaction. COPY doesn't return the generated IDs either
+func (sess *DBSession) useCopy(opts BulkOpSettings) bool {
+	return opts.Copy && !opts.ReturnIDs && !sess.transactionOpen && sess.engine != nil && dialect.DriverName() == migrator.Postgres
+}
+
+// insertBatch inserts records, a slice of beans of table, with a multi-row INSERT, or with COPY FROM STDIN when it is
+// enabled and supported
+func (sess *DBSession) insertBatch(table interface{}, records reflect.Value, opts BulkOpSettings) (int64, error) {
+	if opts.ReturnIDs {
+		return sess.insertBatchReturningIDs(table, records)
+	}
+	if !sess.useCopy(opts) {
+		if table == nil {
+			return sess.InsertMulti(records.Interface())
+		}
+		return sess.Table(table).InsertMulti(records.Interface())
+	}
+	return sess.copyBatch(table, records)
+}
+
+// insertBatchSize returns the number of records of record's type inserted by a batch
+func (sess *DBSession) insertBatchSize(record interface{}, opts BulkOpSettings) int {
+	if sess.useCopy(opts) {
+		if opts.BatchSize > 0 {
+			return opts.BatchSize
+		}
+		return defaultCopyBatchSize
+	}
+	// auto incremented keys are counted, as they are inserted when records set them
+	return opts.batchSize(len(insertedColumns(sess.engine.TableInfo(record).Table, true)))
+}
+
+// insertedColumns returns the columns of a table which records are inserted with, each taking a parameter of multi-row
+// INSERTs. Auto incremented keys are only returned with autoIncrement, they are otherwise left to the database
+func insertedColumns(table *core.Table, autoIncrement bool) []*core.Column {
+	var columns []*core.Column
+	for _, col := range table.Columns() {
+		if (col.IsAutoIncrement && !autoIncrement) || col.MapType == core.ONLYFROMDB || col.IsDeleted {
+			continue
+		}
+		columns = append(columns, col)
+	}
+	return columns
+}
+
+func (sess *DBSession) copyBatch(table interface{}, records reflect.Value) (int64, error) {
+	if records.Len() == 0 {
+		return 0, nil
+	}
+	info := sess.engine.TableInfo(records.Index(0).Interface())
+	tableName := bulkTableName(table, info)
+
+	columns := insertedColumns(info.Table, false)
+	names := make([]string, 0, len(columns))
+	for _, col := range columns {
+		names = append(names, col.Name)
+	}
+
+	// the copy is rolled back once the caller of the session is cancelled
+	ctx := sess.callerContext()
+	tx, err := sess.DB().DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		// the transaction is already committed when the copy succeeded
+		_ = tx.Rollback()
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(tableName, names...))
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	now := time.Now()
+	values := make([]interface{}, len(columns))
+	for i := 0; i < records.Len(); i++ {
+		record := reflect.Indirect(records.Index(i))
+		for j, col := range columns {
+			if values[j], err = copyValue(col, &record, now); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := stmt.ExecContext(ctx, values...); err != nil {
+			return 0, err
+		}
+	}
+
+	res, err := stmt.ExecContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// copyValue returns the value of a column of record, converted like xorm does for the types it converts itself
+func copyValue(col *core.Column, record *reflect.Value, now time.Time) (interface{}, error) {
+	if col.IsCreated || col.IsUpdated {
+		return now, nil
+	}
+
+	field, err := col.ValueOfV(record)
+	if err != nil {
+		return nil, err
+	}
+	if field.Kind() == reflect.Ptr && field.IsNil() {
+		return nil, nil
+	}
+
+	value := field.Interface()
+	conversion, ok := value.(core.Conversion)
+	if !ok && field.CanAddr() {
+		conversion, ok = field.Addr().Interface().(core.Conversion)
+	}
+	if ok {
+		data, err := conversion.ToDB()
+		if err != nil {
+			return nil, err
+		}
+		if col.SQLType.IsBlob() {
+			return data, nil
+		}
+		return string(data), nil
+	}
+
+	switch field.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		if _, ok := value.(time.Time); ok || field.Type() == reflect.TypeOf([]byte(nil)) {
+			return value, nil
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert column %s: %w", col.Name, err)
+		}
+		return string(data), nil
+	}
+	return value, nil
+}
@@ -0,0 +1,176 @@
+package sqlstore
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"xorm.io/core"
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// errIDsNotSettable is returned when the generated keys of bulk inserted records can't be set on them
+var errIDsNotSettable = errors.New("returning generated IDs needs records which are pointers or elements of a slice")
+
+// insertBatchReturningIDs inserts records, a slice of beans of table, and sets their auto incremented keys to the
+// generated IDs. Postgres returns the IDs of a multi-row INSERT, MySQL returns the first one and the next ones follow
+// it, other databases insert the records one at a time
+func (sess *DBSession) insertBatchReturningIDs(table interface{}, records reflect.Value) (int64, error) {
+	if records.Len() == 0 {
+		return 0, nil
+	}
+	info := sess.engine.TableInfo(records.Index(0).Interface())
+	pk := info.Table.AutoIncrColumn()
+	if pk == nil {
+		return sess.insertBatch(table, records, BulkOpSettings{})
+	}
+
+	switch dialect.DriverName() {
+	case migrator.Postgres:
+		return sess.insertReturning(bulkTableName(table, info), info.Table, pk, records)
+	case migrator.MySQL:
+		return sess.insertLastInsertID(bulkTableName(table, info), info.Table, pk, records)
+	}
+
+	var inserted int64
+	for i := 0; i < records.Len(); i++ {
+		record := records.Index(i)
+		if record.Kind() != reflect.Ptr {
+			if !record.CanAddr() {
+				return inserted, errIDsNotSettable
+			}
+			record = record.Addr()
+		}
+		// xorm sets the key of the records it inserts one at a time
+		insert := sess.Session
+		if table != nil {
+			insert = sess.Table(table)
+		}
+		affected, err := insert.InsertOne(record.Interface())
+		inserted += affected
+		if err != nil {
+			return inserted, err
+		}
+	}
+	return inserted, nil
+}
+
+func (sess *DBSession) insertReturning(tableName string, table *core.Table, pk *core.Column, records reflect.Value) (int64, error) {
+	rawSQL, args, err := multiRowInsert(tableName, table, records)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := sess.Query(append([]interface{}{rawSQL + " RETURNING " + dialect.Quote(pk.Name)}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) != records.Len() {
+		return int64(len(rows)), fmt.Errorf("inserted %d records, got %d generated IDs", records.Len(), len(rows))
+	}
+
+	// the rows are returned in the order of the VALUES
+	for i, row := range rows {
+		id, err := strconv.ParseInt(string(row[pk.Name]), 10, 64)
+		if err != nil {
+			return int64(len(rows)), err
+		}
+		if err := setGeneratedID(pk, records.Index(i), id); err != nil {
+			return int64(len(rows)), err
+		}
+	}
+	return int64(len(rows)), nil
+}
+
+func (sess *DBSession) insertLastInsertID(tableName string, table *core.Table, pk *core.Column, records reflect.Value) (int64, error) {
+	rawSQL, args, err := multiRowInsert(tableName, table, records)
+	if err != nil {
+		return 0, err
+	}
+	res, err := sess.Exec(append([]interface{}{rawSQL}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	// the keys of the rows of a multi-row INSERT are consecutive, starting at the key of the first row
+	first, err := res.LastInsertId()
+	if err != nil {
+		return inserted, err
+	}
+	increment := int64(1)
+	if _, err := sess.SQL("SELECT @@auto_increment_increment").Get(&increment); err != nil {
+		return inserted, err
+	}
+
+	for i := 0; i < records.Len(); i++ {
+		if err := setGeneratedID(pk, records.Index(i), first+int64(i)*increment); err != nil {
+			return inserted, err
+		}
+	}
+	return inserted, nil
+}
+
+// multiRowInsert returns the INSERT of records in a table, leaving its auto incremented key to the database
+func multiRowInsert(tableName string, table *core.Table, records reflect.Value) (string, []interface{}, error) {
+	columns := insertedColumns(table, false)
+	names := make([]string, 0, len(columns))
+	for _, col := range columns {
+		names = append(names, dialect.Quote(col.Name))
+	}
+	placeholders := "(?" + strings.Repeat(",?", len(columns)-1) + ")"
+
+	now := time.Now()
+	args := make([]interface{}, 0, len(columns)*records.Len())
+	values := make([]string, 0, records.Len())
+	for i := 0; i < records.Len(); i++ {
+		record := reflect.Indirect(records.Index(i))
+		for _, col := range columns {
+			value, err := copyValue(col, &record, now)
+			if err != nil {
+				return "", nil, err
+			}
+			args = append(args, value)
+		}
+		values = append(values, placeholders)
+	}
+
+	rawSQL := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", dialect.Quote(tableName), strings.Join(names, ","), strings.Join(values, ","))
+	return rawSQL, args, nil
+}
+
+// setGeneratedID sets the auto incremented key of a record to id
+func setGeneratedID(pk *core.Column, record reflect.Value, id int64) error {
+	record = reflect.Indirect(record)
+	if !record.CanAddr() {
+		return errIDsNotSettable
+	}
+	field, err := pk.ValueOfV(&record)
+	if err != nil {
+		return err
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(uint64(id))
+	default:
+		return fmt.Errorf("can't set generated ID of column %s of type %s", pk.Name, field.Type())
+	}
+	return nil
+}
+
+// bulkTableName returns the name of the table records are inserted in
+func bulkTableName(table interface{}, info *xorm.Table) string {
+	if name, ok := table.(string); ok {
+		return name
+	}
+	return info.Name
+}
@@ -0,0 +1,691 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"xorm.io/core"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+type bulkTestStar struct {
+	ID          int64 `xorm:"pk autoincr 'id'"`
+	UserID      int64 `xorm:"user_id"`
+	DashboardID int64 `xorm:"dashboard_id"`
+}
+
+func (bulkTestStar) TableName() string {
+	return "star"
+}
+
+func TestIntegrationBulkInsert(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := InitTestDB(t)
+
+	// more parameters than the limit of SQLite, so they are split in several batches whatever the batch size
+	const rows = 1500
+	stars := make([]bulkTestStar, 0, rows)
+	for i := int64(1); i <= rows; i++ {
+		stars = append(stars, bulkTestStar{UserID: 1, DashboardID: i})
+	}
+
+	t.Run("typed records are inserted in batches", func(t *testing.T) {
+		var batches int
+		var inserted int64
+		err := ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+			var err error
+			inserted, err = BulkInsertT(sess, stars, BulkOpSettings{OnBatch: func(done, total int, affected int64) {
+				batches++
+			}})
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(rows), inserted)
+		// the 3 columns of a star make batches of 333 rows at most with SQLite
+		if ss.GetDialect().DriverName() == migrator.SQLite {
+			require.Equal(t, 5, batches)
+		}
+	})
+
+	t.Run("records of any slice are inserted in batches", func(t *testing.T) {
+		for i := range stars {
+			stars[i].UserID = 2
+		}
+
+		var inserted int64
+		err := ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+			var err error
+			inserted, err = sess.BulkInsert("star", stars, BulkOpSettings{BatchSize: 100})
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(rows), inserted)
+
+		var count int64
+		err = ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+			var err error
+			count, err = sess.Table("star").Where("user_id = ?", 2).Count()
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(rows), count)
+	})
+
+	t.Run("typed keys are deleted in batches", func(t *testing.T) {
+		keys := make([]int64, 0, rows)
+		for i := int64(1); i <= rows; i++ {
+			keys = append(keys, i)
+		}
+
+		var deleted int64
+		err := ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+			var err error
+			deleted, err = BulkDeleteT(sess, "star", "dashboard_id", keys, BulkOpSettings{})
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(2*rows), deleted)
+	})
+
+	t.Run("bulk inserts need a slice", func(t *testing.T) {
+		err := ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+			_, err := sess.BulkInsert("star", stars[0], BulkOpSettings{})
+			return err
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestIntegrationBulkInsertStream(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := InitTestDB(t)
+
+	const rows = 1000
+	stream := func() RecordIterator {
+		i := int64(0)
+		return func() (interface{}, bool, error) {
+			if i == rows {
+				return nil, false, nil
+			}
+			i++
+			return &bulkTestStar{UserID: 1, DashboardID: i}, true, nil
+		}
+	}
+
+	t.Run("streamed records are inserted in batches", func(t *testing.T) {
+		var done []int
+		var inserted int64
+		err := ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+			var err error
+			inserted, err = sess.BulkInsertStream("star", stream(), BulkOpSettings{BatchSize: 300, OnBatch: func(d, total int, affected int64) {
+				require.Equal(t, -1, total)
+				done = append(done, d)
+			}})
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(rows), inserted)
+		require.Equal(t, []int{300, 600, 900, 1000}, done)
+	})
+
+	t.Run("records sent to a channel are inserted", func(t *testing.T) {
+		ch := make(chan interface{})
+		go func() {
+			defer close(ch)
+			for i := int64(1); i <= 10; i++ {
+				ch <- &bulkTestStar{UserID: 2, DashboardID: i}
+			}
+		}()
+
+		var inserted int64
+		err := ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+			var err error
+			inserted, err = sess.BulkInsertStream("star", RecordsFromChannel(ch), BulkOpSettings{})
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(10), inserted)
+	})
+
+	t.Run("errors of the stream stop the insert", func(t *testing.T) {
+		next := stream()
+		calls := 0
+		failing := func() (interface{}, bool, error) {
+			calls++
+			if calls > 5 {
+				return nil, false, errors.New("read error")
+			}
+			record, ok, err := next()
+			record.(*bulkTestStar).UserID = 3
+			return record, ok, err
+		}
+
+		err := ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+			_, err := sess.BulkInsertStream("star", failing, BulkOpSettings{})
+			return err
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("streamed records must have the same type", func(t *testing.T) {
+		records := []interface{}{&bulkTestStar{UserID: 4, DashboardID: 1}, bulkTestStar{UserID: 4, DashboardID: 2}}
+		next := func() (interface{}, bool, error) {
+			if len(records) == 0 {
+				return nil, false, nil
+			}
+			record := records[0]
+			records = records[1:]
+			return record, true, nil
+		}
+
+		err := ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+			_, err := sess.BulkInsertStream("star", next, BulkOpSettings{})
+			return err
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("records left in a channel are drained when the insert stops early", func(t *testing.T) {
+		ch := make(chan interface{})
+		sent := make(chan struct{})
+		go func() {
+			defer close(sent)
+			defer close(ch)
+			ch <- &bulkTestStar{UserID: 5, DashboardID: 1}
+			ch <- bulkTestStar{UserID: 5, DashboardID: 2}
+			for i := int64(3); i <= 100; i++ {
+				ch <- &bulkTestStar{UserID: 5, DashboardID: i}
+			}
+		}()
+
+		err := ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+			_, err := sess.BulkInsertStream("star", RecordsFromChannel(ch), BulkOpSettings{})
+			return err
+		})
+		require.Error(t, err)
+
+		select {
+		case <-sent:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the producer of the channel is still blocked")
+		}
+	})
+}
+
+func TestIntegrationBulkDelete(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := InitTestDB(t)
+
+	// more keys than the parameter limit of SQLite, so they are split in several batches whatever the batch size
+	const rows = 1500
+	keys := make([]interface{}, 0, rows)
+	err := ss.WithTransactionalDbSession(context.Background(), func(sess *DBSession) error {
+		for i := int64(1); i <= rows; i++ {
+			if _, err := sess.Exec("INSERT INTO star (user_id, dashboard_id) VALUES (?, ?)", 1, i); err != nil {
+				return err
+			}
+			keys = append(keys, i)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	t.Run("deletes the rows in batches", func(t *testing.T) {
+		var progress [][3]int64
+		opts := BulkOpSettings{BatchSize: 300, OnBatch: func(done, total int, affected int64) {
+			progress = append(progress, [3]int64{int64(done), int64(total), affected})
+		}}
+
+		var deleted int64
+		err := ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+			var err error
+			deleted, err = sess.BulkDelete("star", "dashboard_id", keys[:1000], opts)
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(1000), deleted)
+		require.Equal(t, [][3]int64{{300, 1000, 300}, {600, 1000, 300}, {900, 1000, 300}, {1000, 1000, 100}}, progress)
+	})
+
+	t.Run("caps the batches to the parameter limit of the database", func(t *testing.T) {
+		var deleted int64
+		err := ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+			var err error
+			// the keys deleted before are reported as not affected
+			deleted, err = sess.BulkDelete("star", "dashboard_id", keys, BulkOpSettings{})
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(rows-1000), deleted)
+
+		var remaining int64
+		err = ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+			var err error
+			remaining, err = sess.Table("star").Count()
+			return err
+		})
+		require.NoError(t, err)
+		require.Zero(t, remaining)
+	})
+
+	t.Run("does nothing without keys", func(t *testing.T) {
+		err := ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+			deleted, err := sess.BulkDelete("star", "dashboard_id", nil, BulkOpSettings{})
+			require.Zero(t, deleted)
+			return err
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestIntegrationBulkRetries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := InitTestDB(t)
+	opts := BulkOpSettings{Retries: 3, RetryBackoff: time.Millisecond}
+
+	failing := func(failures int, err error) (batchFunc, *int) {
+		calls := 0
+		return func(sess *DBSession, start, end int) (int64, error) {
+			calls++
+			if calls <= failures {
+				return 0, err
+			}
+			return int64(end - start), nil
+		}, &calls
+	}
+
+	t.Run("batches failing because of lock contention are retried", func(t *testing.T) {
+		sess := &DBSession{Session: ss.engine.NewSession()}
+		defer sess.Close()
+		fn, calls := failing(2, sqlite3.Error{Code: sqlite3.ErrBusy})
+
+		affected, err := withBatchRetries(opts, fn)(sess, 0, 10)
+		require.NoError(t, err)
+		require.Equal(t, int64(10), affected)
+		require.Equal(t, 3, *calls)
+	})
+
+	t.Run("batches are retried at most the configured number of times", func(t *testing.T) {
+		sess := &DBSession{Session: ss.engine.NewSession()}
+		defer sess.Close()
+		fn, calls := failing(10, sqlite3.Error{Code: sqlite3.ErrLocked})
+
+		_, err := withBatchRetries(opts, fn)(sess, 0, 10)
+		require.Error(t, err)
+		require.Equal(t, opts.Retries+1, *calls)
+	})
+
+	t.Run("other errors are not retried", func(t *testing.T) {
+		sess := &DBSession{Session: ss.engine.NewSession()}
+		defer sess.Close()
+		fn, calls := failing(1, errors.New("constraint violation"))
+
+		_, err := withBatchRetries(opts, fn)(sess, 0, 10)
+		require.Error(t, err)
+		require.Equal(t, 1, *calls)
+	})
+
+	t.Run("batches of sessions in a transaction are not retried", func(t *testing.T) {
+		sess := &DBSession{Session: ss.engine.NewSession(), transactionOpen: true}
+		defer sess.Close()
+		fn, calls := failing(1, sqlite3.Error{Code: sqlite3.ErrBusy})
+
+		_, err := withBatchRetries(opts, fn)(sess, 0, 10)
+		require.Error(t, err)
+		require.Equal(t, 1, *calls)
+	})
+
+	t.Run("retries stop once the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		sess := &DBSession{Session: ss.engine.NewSession(), ctx: ctx}
+		defer sess.Close()
+		fn, calls := failing(10, sqlite3.Error{Code: sqlite3.ErrBusy})
+		// cancelled while waiting for the first retry
+		fn = func(fn batchFunc) batchFunc {
+			return func(sess *DBSession, start, end int) (int64, error) {
+				defer cancel()
+				return fn(sess, start, end)
+			}
+		}(fn)
+
+		_, err := withBatchRetries(BulkOpSettings{Retries: 3, RetryBackoff: time.Hour}, fn)(sess, 0, 10)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 1, *calls)
+	})
+}
+
+func TestIntegrationBulkConcurrency(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := InitTestDB(t)
+
+	t.Run("batches of sessions in a transaction or of SQLite run one at a time", func(t *testing.T) {
+		sess := &DBSession{Session: ss.engine.NewSession(), engine: ss.engine, transactionOpen: true}
+		defer sess.Close()
+		require.Equal(t, 1, sess.bulkConcurrency(BulkOpSettings{Concurrency: 4}))
+
+		sess.transactionOpen = false
+		if ss.GetDialect().DriverName() == migrator.SQLite {
+			require.Equal(t, 1, sess.bulkConcurrency(BulkOpSettings{Concurrency: 4}))
+		} else {
+			require.Equal(t, 4, sess.bulkConcurrency(BulkOpSettings{Concurrency: 4}))
+		}
+	})
+
+	t.Run("batches run in parallel on their own sessions", func(t *testing.T) {
+		sess := &DBSession{Session: ss.engine.NewSession(), engine: ss.engine}
+		defer sess.Close()
+
+		var mu sync.Mutex
+		running, maxRunning := 0, 0
+		var done []int
+		r := sess.newBatchRunner(BulkOpSettings{OnBatch: func(d, total int, affected int64) {
+			done = append(done, d)
+		}}, 100, 4)
+		for start := 0; start < 100; start += 10 {
+			err := r.run(start, start+10, func(batchSess *DBSession, start, end int) (int64, error) {
+				assert.NotSame(t, sess, batchSess)
+				mu.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mu.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return int64(end - start), nil
+			})
+			require.NoError(t, err)
+		}
+
+		affected, err := r.wait()
+		require.NoError(t, err)
+		require.Equal(t, int64(100), affected)
+		require.Greater(t, maxRunning, 1)
+		require.LessOrEqual(t, maxRunning, 4)
+		require.Len(t, done, 10)
+		require.Equal(t, 100, done[9])
+	})
+
+	t.Run("no more batches run after a batch failed", func(t *testing.T) {
+		sess := &DBSession{Session: ss.engine.NewSession(), engine: ss.engine}
+		defer sess.Close()
+
+		r := sess.newBatchRunner(BulkOpSettings{}, 100, 2)
+		scheduled := 0
+		for start := 0; start < 100; start += 10 {
+			err := r.run(start, start+10, func(batchSess *DBSession, start, end int) (int64, error) {
+				if start == 0 {
+					return 0, errors.New("batch error")
+				}
+				time.Sleep(10 * time.Millisecond)
+				return int64(end - start), nil
+			})
+			if err != nil {
+				break
+			}
+			scheduled++
+		}
+
+		_, err := r.wait()
+		require.EqualError(t, err, "batch error")
+		require.Less(t, scheduled, 10)
+	})
+
+	t.Run("batches run with the context of the caller", func(t *testing.T) {
+		type ctxKey struct{}
+		ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "caller"))
+		sess := &DBSession{Session: ss.engine.NewSession(), engine: ss.engine, ctx: ctx}
+		defer sess.Close()
+
+		r := sess.newBatchRunner(BulkOpSettings{}, 20, 2)
+		err := r.run(0, 10, func(batchSess *DBSession, start, end int) (int64, error) {
+			assert.Equal(t, "caller", batchSess.ctx.Value(ctxKey{}))
+			return int64(end - start), nil
+		})
+		require.NoError(t, err)
+		affected, err := r.wait()
+		require.NoError(t, err)
+		require.Equal(t, int64(10), affected)
+
+		// no batch is scheduled once the caller is gone
+		cancel()
+		r = sess.newBatchRunner(BulkOpSettings{}, 20, 2)
+		err = r.run(0, 10, func(batchSess *DBSession, start, end int) (int64, error) {
+			t.Error("batch ran after the context of the caller was cancelled")
+			return 0, nil
+		})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+type bulkTestLabels []string
+
+func (l *bulkTestLabels) FromDB(data []byte) error {
+	*l = strings.Split(string(data), ",")
+	return nil
+}
+
+func (l *bulkTestLabels) ToDB() ([]byte, error) {
+	return []byte(strings.Join(*l, ",")), nil
+}
+
+func TestCopyValue(t *testing.T) {
+	type record struct {
+		ID       int64 `xorm:"pk autoincr 'id'"`
+		Name     string
+		Labels   bulkTestLabels
+		Settings map[string]string
+		Parent   *int64
+		Data     []byte
+		Created  time.Time `xorm:"created"`
+	}
+
+	now := time.Now()
+	value := reflect.ValueOf([]record{{Name: "name", Labels: bulkTestLabels{"a", "b"}, Settings: map[string]string{"k": "v"}, Data: []byte("data")}}).Index(0)
+	expected := map[string]interface{}{
+		"Name":     "name",
+		"Labels":   "a,b",
+		"Settings": `{"k":"v"}`,
+		"Parent":   nil,
+		"Data":     []byte("data"),
+		"Created":  now,
+	}
+	for field, want := range expected {
+		col := &core.Column{Name: field, FieldName: field, SQLType: core.SQLType{Name: core.Text}, IsCreated: field == "Created"}
+		got, err := copyValue(col, &value, now)
+		require.NoError(t, err)
+		require.Equal(t, want, got, field)
+	}
+}
+
+func TestIntegrationBulkInsertCopy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := InitTestDB(t)
+
+	sess := &DBSession{Session: ss.engine.NewSession(), engine: ss.engine}
+	defer sess.Close()
+	if !IsTestDbPostgres() {
+		require.False(t, sess.useCopy(BulkOpSettings{Copy: true}))
+		t.Skip("COPY is only supported by Postgres")
+	}
+	require.True(t, sess.useCopy(BulkOpSettings{Copy: true}))
+
+	stars := make([]bulkTestStar, 0, 2500)
+	for i := int64(1); i <= 2500; i++ {
+		stars = append(stars, bulkTestStar{UserID: 1, DashboardID: i})
+	}
+	inserted, err := BulkInsertT(sess, stars, BulkOpSettings{Copy: true, BatchSize: 1000})
+	require.NoError(t, err)
+	require.Equal(t, int64(2500), inserted)
+
+	count, err := sess.Table("star").Where("user_id = ?", 1).Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(2500), count)
+
+	// copies run with the context of the caller
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cancelledSess := &DBSession{Session: ss.engine.NewSession(), engine: ss.engine, ctx: ctx}
+	defer cancelledSess.Close()
+	_, err = BulkInsertT(cancelledSess, []bulkTestStar{{UserID: 2, DashboardID: 1}}, BulkOpSettings{Copy: true})
+	require.ErrorIs(t, err, context.Canceled)
+
+	count, err = sess.Table("star").Where("user_id = ?", 2).Count()
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func TestIntegrationBulkInsertBatchSize(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := InitTestDB(t)
+	sess := &DBSession{Session: ss.engine.NewSession(), engine: ss.engine}
+	defer sess.Close()
+
+	type wideRecord struct {
+		ID       int64 `xorm:"pk autoincr 'id'"`
+		A, B, C  string
+		D, E, F  int64
+		G, H     bool
+		Computed string    `xorm:"<-"`
+		Deleted  time.Time `xorm:"deleted"`
+	}
+
+	// the read-only and deleted columns are not inserted
+	limit := ss.GetDialect().MaxParameters() / 9
+	require.Equal(t, limit, sess.insertBatchSize(wideRecord{}, BulkOpSettings{}))
+	require.Equal(t, 10, sess.insertBatchSize(wideRecord{}, BulkOpSettings{BatchSize: 10}))
+	require.Equal(t, limit, sess.insertBatchSize(wideRecord{}, BulkOpSettings{BatchSize: limit + 1}))
+}
+
+func TestIntegrationBulkContinueOnError(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := InitTestDB(t)
+	sess := &DBSession{Session: ss.engine.NewSession(), engine: ss.engine}
+	defer sess.Close()
+
+	_, err := sess.Insert(&bulkTestStar{UserID: 1, DashboardID: 5}, &bulkTestStar{UserID: 1, DashboardID: 12})
+	require.NoError(t, err)
+
+	stars := make([]bulkTestStar, 0, 20)
+	for i := int64(1); i <= 20; i++ {
+		stars = append(stars, bulkTestStar{UserID: 1, DashboardID: i})
+	}
+
+	t.Run("failing batches stop the operation", func(t *testing.T) {
+		inserted, err := BulkInsertT(sess, stars, BulkOpSettings{BatchSize: 10})
+		require.Error(t, err)
+		require.Zero(t, inserted)
+	})
+
+	t.Run("the rows of failing batches are run one at a time", func(t *testing.T) {
+		inserted, err := BulkInsertT(sess, stars, BulkOpSettings{BatchSize: 10, ContinueOnError: true})
+		require.Equal(t, int64(18), inserted)
+
+		var bulkErrs *BulkErrors
+		require.ErrorAs(t, err, &bulkErrs)
+		require.Len(t, bulkErrs.Rows, 2)
+		require.Equal(t, 4, bulkErrs.Rows[0].Index)
+		require.Equal(t, 11, bulkErrs.Rows[1].Index)
+		require.True(t, ss.GetDialect().IsUniqueConstraintViolation(bulkErrs.Rows[0].Err))
+	})
+}
+
+func TestIntegrationBulkInsertReturnIDs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := InitTestDB(t)
+	sess := &DBSession{Session: ss.engine.NewSession(), engine: ss.engine}
+	defer sess.Close()
+
+	requireIDs := func(t *testing.T, stars []*bulkTestStar) {
+		t.Helper()
+		for _, star := range stars {
+			require.NotZero(t, star.ID)
+			stored := bulkTestStar{ID: star.ID}
+			has, err := sess.Get(&stored)
+			require.NoError(t, err)
+			require.True(t, has)
+			require.Equal(t, *star, stored)
+		}
+	}
+
+	t.Run("the keys of slice elements are set", func(t *testing.T) {
+		stars := make([]bulkTestStar, 0, 25)
+		for i := int64(1); i <= 25; i++ {
+			stars = append(stars, bulkTestStar{UserID: 1, DashboardID: i})
+		}
+		inserted, err := BulkInsertT(sess, stars, BulkOpSettings{BatchSize: 10, ReturnIDs: true})
+		require.NoError(t, err)
+		require.Equal(t, int64(25), inserted)
+
+		pointers := make([]*bulkTestStar, 0, len(stars))
+		for i := range stars {
+			pointers = append(pointers, &stars[i])
+		}
+		requireIDs(t, pointers)
+	})
+
+	t.Run("the keys of streamed pointers are set", func(t *testing.T) {
+		stars := make([]*bulkTestStar, 0, 25)
+		ch := make(chan interface{}, 25)
+		for i := int64(1); i <= 25; i++ {
+			star := &bulkTestStar{UserID: 2, DashboardID: i}
+			stars = append(stars, star)
+			ch <- star
+		}
+		close(ch)
+		inserted, err := sess.BulkInsertStream("star", RecordsFromChannel(ch), BulkOpSettings{BatchSize: 10, ReturnIDs: true})
+		require.NoError(t, err)
+		require.Equal(t, int64(25), inserted)
+		requireIDs(t, stars)
+	})
+
+	t.Run("multi-row INSERTs leave the keys to the database", func(t *testing.T) {
+		stars := []bulkTestStar{{ID: 99999, UserID: 4, DashboardID: 1}, {ID: 99999, UserID: 4, DashboardID: 2}}
+		rawSQL, args, err := multiRowInsert("star", ss.engine.TableInfo(stars[0]).Table, reflect.ValueOf(stars))
+		require.NoError(t, err)
+		require.Len(t, args, 4)
+		_, err = sess.Exec(append([]interface{}{rawSQL}, args...)...)
+		require.NoError(t, err)
+
+		count, err := sess.Table("star").Where("user_id = ?", 4).And("id <> ?", 99999).Count()
+		require.NoError(t, err)
+		require.Equal(t, int64(2), count)
+	})
+
+	t.Run("streamed values are refused", func(t *testing.T) {
+		ch := make(chan interface{}, 1)
+		ch <- bulkTestStar{UserID: 3, DashboardID: 1}
+		close(ch)
+		_, err := sess.BulkInsertStream("star", RecordsFromChannel(ch), BulkOpSettings{ReturnIDs: true})
+		require.ErrorIs(t, err, errIDsNotSettable)
+	})
+}
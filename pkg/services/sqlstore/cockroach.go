@@ -0,0 +1,17 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+	"xorm.io/core"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// CockroachDB is reached through the driver of Postgres, registered under its own name so the migrator picks its
+// dialect, while xorm parses its connection strings as the ones of Postgres
+func init() {
+	sql.Register(migrator.CockroachDB, &pq.Driver{})
+	core.RegisterDriver(migrator.CockroachDB, &databaseQueryWrapperDriver{dbType: migrator.Postgres})
+}
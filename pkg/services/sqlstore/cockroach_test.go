@@ -0,0 +1,23 @@
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"xorm.io/core"
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func TestCockroachDBDriver(t *testing.T) {
+	engine, err := xorm.NewEngine(migrator.CockroachDB, "user=root host=localhost port=26257 dbname=grafana sslmode=disable")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = engine.Close() })
+
+	// xorm builds the queries of Postgres, and the migrator the ones of CockroachDB
+	require.Equal(t, core.DbType(core.POSTGRES), engine.Dialect().DBType())
+	d := migrator.NewDialect(engine)
+	require.IsType(t, &migrator.CockroachDialect{}, d)
+	require.Equal(t, migrator.Postgres, d.DriverName())
+}
@@ -6,6 +6,7 @@ import (
 	"database/sql/driver"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gchaincl/sqlhooks"
@@ -20,7 +21,9 @@ import (
 )
 
 var (
-	databaseQueryHistogram *prometheus.HistogramVec
+	databaseQueryHistogram      *prometheus.HistogramVec
+	databaseTableQueryHistogram *prometheus.HistogramVec
+	databaseTableQueryCounter   *prometheus.CounterVec
 )
 
 func init() {
@@ -31,17 +34,37 @@ func init() {
 		Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 10),
 	}, []string{"status"})
 
-	prometheus.MustRegister(databaseQueryHistogram)
+	databaseTableQueryHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grafana",
+		Name:      "database_table_queries_duration_seconds",
+		Help:      "Database query histogram by table and operation",
+		Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 10),
+	}, []string{"table", "operation"})
+
+	databaseTableQueryCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "database_table_queries_total",
+		Help:      "Database query counter by table, operation and status",
+	}, []string{"table", "operation", "status"})
+
+	prometheus.MustRegister(databaseQueryHistogram, databaseTableQueryHistogram, databaseTableQueryCounter)
 }
 
 // WrapDatabaseDriverWithHooks creates a fake database driver that
 // executes pre and post functions which we use to gather metrics about
 // database queries. It also registers the metrics.
 func WrapDatabaseDriverWithHooks(dbType string, tracer tracing.Tracer) string {
+	return wrapDatabaseDriver(dbType, &databaseQueryWrapper{log: log.New("sqlstore.metrics"), tracer: tracer})
+}
+
+// wrapDatabaseDriver creates a fake database driver that executes the
+// hooks before and after the queries of the driver of dbType.
+func wrapDatabaseDriver(dbType string, hooks ...sqlhooks.Hooks) string {
 	drivers := map[string]driver.Driver{
-		migrator.SQLite:   &sqlite3.SQLiteDriver{},
-		migrator.MySQL:    &mysql.MySQLDriver{},
-		migrator.Postgres: &pq.Driver{},
+		migrator.SQLite:      &sqlite3.SQLiteDriver{},
+		migrator.MySQL:       &mysql.MySQLDriver{},
+		migrator.Postgres:    &pq.Driver{},
+		migrator.CockroachDB: &pq.Driver{},
 	}
 
 	d, exist := drivers[dbType]
@@ -50,7 +73,7 @@ func WrapDatabaseDriverWithHooks(dbType string, tracer tracing.Tracer) string {
 	}
 
 	driverWithHooks := dbType + "WithHooks"
-	sql.Register(driverWithHooks, sqlhooks.Wrap(d, &databaseQueryWrapper{log: log.New("sqlstore.metrics"), tracer: tracer}))
+	sql.Register(driverWithHooks, sqlhooks.Wrap(d, sqlhooks.Compose(hooks...)))
 	core.RegisterDriver(driverWithHooks, &databaseQueryWrapperDriver{dbType: dbType})
 	return driverWithHooks
 }
@@ -93,6 +116,10 @@ func (h *databaseQueryWrapper) instrument(ctx context.Context, status string, qu
 		histogram.Observe(elapsed.Seconds())
 	}
 
+	table, operation := queryTableAndOperation(query)
+	databaseTableQueryHistogram.WithLabelValues(table, operation).Observe(elapsed.Seconds())
+	databaseTableQueryCounter.WithLabelValues(table, operation, status).Inc()
+
 	ctx = log.IncDBCallCounter(ctx)
 
 	_, span := h.tracer.Start(ctx, "database query")
@@ -141,3 +168,54 @@ func (hp *databaseQueryWrapperDriver) Parse(driverName, dataSourceName string) (
 	}
 	return driver.Parse(driverName, dataSourceName)
 }
+
+// queryTableAndOperation returns the table a query runs on and its operation, such as "select" or "insert". Queries
+// joining tables return the first one. The table is "unknown" when it can't be told, and the operation is "other" for
+// the queries which don't read or write rows, such as transaction statements
+func queryTableAndOperation(query string) (string, string) {
+	tokens := strings.Fields(query)
+	if len(tokens) == 0 {
+		return "unknown", "other"
+	}
+
+	operation := strings.ToLower(tokens[0])
+	var tableKeyword string
+	switch operation {
+	case "select", "delete":
+		tableKeyword = "from"
+	case "insert", "replace":
+		tableKeyword = "into"
+	case "update":
+		tableKeyword = "update"
+	default:
+		return "unknown", "other"
+	}
+
+	for i, token := range tokens[:len(tokens)-1] {
+		if strings.EqualFold(token, tableKeyword) {
+			return queryTableName(tokens[i+1]), operation
+		}
+	}
+	return "unknown", operation
+}
+
+// queryTableName returns the name of a table from its quoted or schema qualified name in a query, or "unknown" when
+// the token isn't a table name, such as a subquery
+func queryTableName(token string) string {
+	if i := strings.IndexAny(token, "(,;"); i >= 0 {
+		token = token[:i]
+	}
+	if i := strings.LastIndex(token, "."); i >= 0 {
+		token = token[i+1:]
+	}
+	token = strings.ToLower(strings.Trim(token, "\"`[]"))
+	if token == "" {
+		return "unknown"
+	}
+	for _, r := range token {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' {
+			return "unknown"
+		}
+	}
+	return token
+}
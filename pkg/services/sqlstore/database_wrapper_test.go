@@ -0,0 +1,31 @@
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryTableAndOperation(t *testing.T) {
+	tests := []struct {
+		query     string
+		table     string
+		operation string
+	}{
+		{query: "SELECT * FROM `dashboard_public` WHERE org_id=?", table: "dashboard_public", operation: "select"},
+		{query: `select count(*) from "user" as u inner join org_user on org_user.user_id = u.id`, table: "user", operation: "select"},
+		{query: "INSERT INTO star(user_id,dashboard_id) VALUES (?,?)", table: "star", operation: "insert"},
+		{query: "UPDATE \"annotation\" SET text=$1 WHERE id=$2", table: "annotation", operation: "update"},
+		{query: "DELETE FROM public.annotation_tag WHERE annotation_id IN (?)", table: "annotation_tag", operation: "delete"},
+		{query: "REPLACE INTO [kv_store] (k) VALUES (?)", table: "kv_store", operation: "replace"},
+		{query: "SELECT * FROM (SELECT id FROM dashboard) AS d", table: "unknown", operation: "select"},
+		{query: "SELECT 1", table: "unknown", operation: "select"},
+		{query: "BEGIN", table: "unknown", operation: "other"},
+		{query: "  ", table: "unknown", operation: "other"},
+	}
+	for _, tt := range tests {
+		table, operation := queryTableAndOperation(tt.query)
+		require.Equal(t, tt.table, table, tt.query)
+		require.Equal(t, tt.operation, operation, tt.query)
+	}
+}
@@ -6,6 +6,23 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 )
 
+// PoolStats are the statistics of the connection pool of the database
+type PoolStats struct {
+	// MaxOpenConnections is the maximum number of open connections, 0 when unlimited
+	MaxOpenConnections int `json:"maxOpenConnections"`
+	OpenConnections    int `json:"openConnections"`
+	InUse              int `json:"inUse"`
+	Idle               int `json:"idle"`
+	// WaitCount is the number of times a connection was waited for, WaitDurationMs the total time waited
+	WaitCount         int64 `json:"waitCount"`
+	WaitDurationMs    int64 `json:"waitDurationMs"`
+	MaxIdleClosed     int64 `json:"maxIdleClosed"`
+	MaxIdleTimeClosed int64 `json:"maxIdleTimeClosed"`
+	MaxLifetimeClosed int64 `json:"maxLifetimeClosed"`
+	// Saturated is true when all the connections the pool can open are in use, so queries wait for a connection
+	Saturated bool `json:"saturated"`
+}
+
 // GetDBHealthQuery executes a query to check
 // the availability of the database.
 func (ss *SQLStore) GetDBHealthQuery(ctx context.Context, query *models.GetDBHealthQuery) error {
@@ -14,3 +31,20 @@ func (ss *SQLStore) GetDBHealthQuery(ctx context.Context, query *models.GetDBHea
 		return err
 	})
 }
+
+// GetPoolStats returns the statistics of the connection pool of the primary database.
+func (ss *SQLStore) GetPoolStats() PoolStats {
+	stats := ss.engine.DB().DB.Stats()
+	return PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+		Saturated:          stats.MaxOpenConnections > 0 && stats.InUse >= stats.MaxOpenConnections,
+	}
+}
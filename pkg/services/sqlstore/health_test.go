@@ -18,3 +18,18 @@ func TestIntegrationGetDBHealthQuery(t *testing.T) {
 	err := store.GetDBHealthQuery(context.Background(), &query)
 	require.NoError(t, err)
 }
+
+func TestIntegrationGetPoolStats(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	store := InitTestDB(t)
+
+	err := store.WithDbSession(context.Background(), func(sess *DBSession) error {
+		stats := store.GetPoolStats()
+		require.GreaterOrEqual(t, stats.OpenConnections, stats.InUse)
+		require.Equal(t, stats.OpenConnections, stats.InUse+stats.Idle)
+		return nil
+	})
+	require.NoError(t, err)
+}
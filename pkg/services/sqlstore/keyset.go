@@ -0,0 +1,194 @@
+package sqlstore
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor can't be decoded, or doesn't match the columns of the keyset
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// KeysetColumn is a column a keyset paginated query is ordered by
+type KeysetColumn struct {
+	// Name of the column, which can be prefixed by the table. It isn't quoted, so it must not come from user input
+	Name       string
+	Descending bool
+}
+
+// Asc returns a column ordered in ascending order
+func Asc(name string) KeysetColumn {
+	return KeysetColumn{Name: name}
+}
+
+// Desc returns a column ordered in descending order
+func Desc(name string) KeysetColumn {
+	return KeysetColumn{Name: name, Descending: true}
+}
+
+// Keyset paginates a query by the values of the columns it's ordered by, instead of an OFFSET. The next page starts
+// after the last row of the previous one, so it's read through the index of the columns whatever the page is, and
+// rows added or deleted meanwhile don't shift the pages. The last column must be unique, such as the ID, so the rows
+// with the same values in the other columns have a stable order.
+//
+// The position in the results is a cursor, an opaque string encoding the values of the last row of a page, which is
+// returned to the clients so they can ask for the next page.
+type Keyset struct {
+	Columns []KeysetColumn
+}
+
+// NewKeyset returns the keyset of a query ordered by columns
+func NewKeyset(columns ...KeysetColumn) Keyset {
+	return Keyset{Columns: columns}
+}
+
+// OrderBy returns the ORDER BY clause of the query, without the keywords
+func (k Keyset) OrderBy() string {
+	order := make([]string, 0, len(k.Columns))
+	for _, col := range k.Columns {
+		if col.Descending {
+			order = append(order, col.Name+" DESC")
+		} else {
+			order = append(order, col.Name+" ASC")
+		}
+	}
+	return strings.Join(order, ", ")
+}
+
+// Where returns the condition selecting the rows after the cursor, and its arguments. The condition is empty for the
+// first page, whose cursor is empty.
+//
+// The rows after (a, b) in ascending order are (a > ?) OR (a = ? AND b > ?), which databases can read through an
+// index on (a, b), unlike the row value comparisons some of them don't support.
+func (k Keyset) Where(cursor string) (string, []interface{}, error) {
+	if cursor == "" {
+		return "", nil, nil
+	}
+	values, err := k.DecodeCursor(cursor)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var args []interface{}
+	alternatives := make([]string, 0, len(k.Columns))
+	for i, col := range k.Columns {
+		conditions := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, k.Columns[j].Name+" = ?")
+			args = append(args, values[j])
+		}
+		op := " > ?"
+		if col.Descending {
+			op = " < ?"
+		}
+		conditions = append(conditions, col.Name+op)
+		args = append(args, values[i])
+		alternatives = append(alternatives, "("+strings.Join(conditions, " AND ")+")")
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", args, nil
+}
+
+// Apply orders the query of a session by the columns of the keyset, and selects the limit rows after the cursor
+func (k Keyset) Apply(sess *DBSession, cursor string, limit int) error {
+	where, args, err := k.Where(cursor)
+	if err != nil {
+		return err
+	}
+	if where != "" {
+		sess.Where(where, args...)
+	}
+	sess.OrderBy(k.OrderBy())
+	if limit > 0 {
+		sess.Limit(limit)
+	}
+	return nil
+}
+
+// cursorValue is a typed value of a cursor, so it's decoded to the type it was encoded from
+type cursorValue struct {
+	Type  string `json:"t"`
+	Value string `json:"v"`
+}
+
+// EncodeCursor returns the cursor of the row with values, the values of the columns of the keyset in their order.
+// The values can be integers, strings, booleans and times.
+func (k Keyset) EncodeCursor(values ...interface{}) (string, error) {
+	if len(values) != len(k.Columns) {
+		return "", fmt.Errorf("cursor of %d columns got %d values", len(k.Columns), len(values))
+	}
+	encoded := make([]cursorValue, 0, len(values))
+	for i, value := range values {
+		v, err := encodeCursorValue(value)
+		if err != nil {
+			return "", fmt.Errorf("cursor value of column %s: %w", k.Columns[i].Name, err)
+		}
+		encoded = append(encoded, v)
+	}
+	data, err := json.Marshal(encoded)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor returns the values of the columns of the keyset encoded in a cursor
+func (k Keyset) DecodeCursor(cursor string) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var encoded []cursorValue
+	if err := json.Unmarshal(data, &encoded); err != nil || len(encoded) != len(k.Columns) {
+		return nil, ErrInvalidCursor
+	}
+	values := make([]interface{}, 0, len(encoded))
+	for _, v := range encoded {
+		value, err := decodeCursorValue(v)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func encodeCursorValue(value interface{}) (cursorValue, error) {
+	switch v := value.(type) {
+	case string:
+		return cursorValue{Type: "s", Value: v}, nil
+	case bool:
+		return cursorValue{Type: "b", Value: strconv.FormatBool(v)}, nil
+	case time.Time:
+		return cursorValue{Type: "t", Value: v.Format(time.RFC3339Nano)}, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cursorValue{Type: "i", Value: strconv.FormatInt(rv.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cursorValue{Type: "u", Value: strconv.FormatUint(rv.Uint(), 10)}, nil
+	}
+	return cursorValue{}, fmt.Errorf("unsupported type %T", value)
+}
+
+func decodeCursorValue(v cursorValue) (interface{}, error) {
+	switch v.Type {
+	case "s":
+		return v.Value, nil
+	case "b":
+		return strconv.ParseBool(v.Value)
+	case "t":
+		return time.Parse(time.RFC3339Nano, v.Value)
+	case "i":
+		return strconv.ParseInt(v.Value, 10, 64)
+	case "u":
+		return strconv.ParseUint(v.Value, 10, 64)
+	}
+	return nil, fmt.Errorf("unsupported type %q", v.Type)
+}
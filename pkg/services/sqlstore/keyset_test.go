@@ -0,0 +1,104 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyset(t *testing.T) {
+	keyset := NewKeyset(Desc("created"), Asc("name"), Asc("id"))
+
+	t.Run("the query is ordered by the columns", func(t *testing.T) {
+		require.Equal(t, "created DESC, name ASC, id ASC", keyset.OrderBy())
+	})
+
+	t.Run("the first page has no condition", func(t *testing.T) {
+		where, args, err := keyset.Where("")
+		require.NoError(t, err)
+		require.Empty(t, where)
+		require.Empty(t, args)
+	})
+
+	t.Run("the next pages select the rows after the cursor", func(t *testing.T) {
+		created := time.Date(2022, 10, 17, 12, 30, 0, 0, time.UTC)
+		cursor, err := keyset.EncodeCursor(created, "b", int64(3))
+		require.NoError(t, err)
+
+		where, args, err := keyset.Where(cursor)
+		require.NoError(t, err)
+		require.Equal(t, "((created < ?) OR (created = ? AND name > ?) OR (created = ? AND name = ? AND id > ?))", where)
+		require.Equal(t, []interface{}{created, created, "b", created, "b", int64(3)}, args)
+	})
+
+	t.Run("the values keep their types", func(t *testing.T) {
+		cursor, err := NewKeyset(Asc("a"), Asc("b"), Asc("c")).EncodeCursor(7, uint8(8), true)
+		require.NoError(t, err)
+		values, err := NewKeyset(Asc("a"), Asc("b"), Asc("c")).DecodeCursor(cursor)
+		require.NoError(t, err)
+		require.Equal(t, []interface{}{int64(7), uint64(8), true}, values)
+	})
+
+	t.Run("invalid cursors are rejected", func(t *testing.T) {
+		for _, cursor := range []string{"not a cursor", "W10", "W3sidCI6IngiLCJ2IjoiMSJ9XQ"} {
+			_, _, err := NewKeyset(Asc("id")).Where(cursor)
+			require.ErrorIs(t, err, ErrInvalidCursor, cursor)
+		}
+	})
+
+	t.Run("unsupported values are rejected", func(t *testing.T) {
+		_, err := NewKeyset(Asc("id")).EncodeCursor(1.5)
+		require.Error(t, err)
+		_, err = NewKeyset(Asc("id")).EncodeCursor(1, 2)
+		require.Error(t, err)
+	})
+}
+
+type keysetTestRow struct {
+	Id   int64
+	Name string
+}
+
+func TestIntegrationKeysetPagination(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := InitTestDB(t)
+	require.NoError(t, ss.engine.Sync2(new(keysetTestRow)))
+	t.Cleanup(func() { _ = ss.engine.DropTables(new(keysetTestRow)) })
+
+	// the names repeat, so the rows with the same name are ordered by ID
+	for i := 0; i < 7; i++ {
+		_, err := ss.engine.Insert(&keysetTestRow{Name: fmt.Sprintf("name-%d", i%3)})
+		require.NoError(t, err)
+	}
+
+	keyset := NewKeyset(Desc("name"), Asc("id"))
+	var paged []keysetTestRow
+	cursor := ""
+	for {
+		var page []keysetTestRow
+		err := ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+			if err := keyset.Apply(sess, cursor, 3); err != nil {
+				return err
+			}
+			return sess.Find(&page)
+		})
+		require.NoError(t, err)
+		paged = append(paged, page...)
+		if len(page) < 3 {
+			break
+		}
+		last := page[len(page)-1]
+		cursor, err = keyset.EncodeCursor(last.Name, last.Id)
+		require.NoError(t, err)
+	}
+
+	var all []keysetTestRow
+	require.NoError(t, ss.engine.OrderBy("name DESC, id ASC").Find(&all))
+	require.Len(t, all, 7)
+	require.Equal(t, all, paged)
+}
@@ -100,4 +100,9 @@ func addApiKeyMigrations(mg *Migrator) {
 	mg.AddMigration("Add is_revoked column to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
 		Name: "is_revoked", Type: DB_Bool, Nullable: true, Default: "0",
 	}))
+
+	// created_by counts the api keys of a user against its quota
+	mg.AddMigration("Add created_by column to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "created_by", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
 }
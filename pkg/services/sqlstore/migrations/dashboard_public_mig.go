@@ -74,4 +74,292 @@ func addPublicDashboardMigration(mg *Migrator) {
 		Nullable: false,
 		Default:  "0",
 	}))
+
+	mg.AddMigration("add show_time_picker column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "show_time_picker",
+		Type:     DB_Bool,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add show_annotations_toggle column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "show_annotations_toggle",
+		Type:     DB_Bool,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add show_footer column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "show_footer",
+		Type:     DB_Bool,
+		Nullable: false,
+		Default:  "1",
+	}))
+
+	mg.AddMigration("add email_gated column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "email_gated",
+		Type:     DB_Bool,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add email_allowlist column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "email_allowlist",
+		Type:     DB_Text,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add allowed_countries column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "allowed_countries",
+		Type:     DB_Text,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add blocked_countries column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "blocked_countries",
+		Type:     DB_Text,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add panel_cost_hints column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "panel_cost_hints",
+		Type:     DB_Text,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add shared_by_folder_uid column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "shared_by_folder_uid",
+		Type:     DB_NVarchar,
+		Length:   40,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add annotations_disabled_panels column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "annotations_disabled_panels",
+		Type:     DB_Text,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add last_used_at column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "last_used_at",
+		Type:     DB_DateTime,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add previous_period_panels column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "previous_period_panels",
+		Type:     DB_Text,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add max_age column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "max_age",
+		Type:     DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add stale_while_revalidate column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "stale_while_revalidate",
+		Type:     DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add creator_deleted column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "creator_deleted",
+		Type:     DB_Bool,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	// a dashboard has a single public dashboard, duplicates created by concurrent requests are removed before the
+	// index is made unique
+	mg.AddMigration("delete duplicate public dashboards of a dashboard", NewRawSQLMigration(
+		"DELETE FROM dashboard_public WHERE uid NOT IN (SELECT uid FROM (SELECT MIN(uid) AS uid FROM dashboard_public GROUP BY org_id, dashboard_uid) AS kept)"))
+	// the index was created before the table was renamed
+	mg.AddMigration("drop index org_id_dashboard_uid - v2", NewDropIndexMigration(dashboardPublicCfgV2, &Index{
+		Name: "IDX_dashboard_public_config_org_id_dashboard_uid",
+		Cols: []string{"org_id", "dashboard_uid"},
+	}))
+	mg.AddMigration("add unique index dashboard_public.org_id_dashboard_uid", NewAddIndexMigration(dashboardPublicCfgV2, &Index{
+		Cols: []string{"org_id", "dashboard_uid"},
+		Type: UniqueIndex,
+	}))
+}
+
+func addPublicPlaylistMigration(mg *Migrator) {
+	var publicPlaylistV1 = Table{
+		Name: "dashboard_public_playlist",
+		Columns: []*Column{
+			{Name: "uid", Type: DB_NVarchar, Length: 40, IsPrimaryKey: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "access_token", Type: DB_NVarchar, Length: 32, Nullable: false},
+
+			{Name: "created_by", Type: DB_Int, Nullable: false},
+			{Name: "updated_by", Type: DB_Int, Nullable: true},
+
+			{Name: "created_at", Type: DB_DateTime, Nullable: false},
+			{Name: "updated_at", Type: DB_DateTime, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}},
+			{Cols: []string{"access_token"}, Type: UniqueIndex},
+		},
+	}
+
+	var publicPlaylistItemV1 = Table{
+		Name: "dashboard_public_playlist_item",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "playlist_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "access_token", Type: DB_NVarchar, Length: 32, Nullable: false},
+			{Name: "dwell_seconds", Type: DB_BigInt, Nullable: false},
+			{Name: "position", Type: DB_Int, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"playlist_uid"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard public playlist table v1", NewAddTableMigration(publicPlaylistV1))
+	addTableIndicesMigrations(mg, "v1", publicPlaylistV1)
+
+	mg.AddMigration("create dashboard public playlist item table v1", NewAddTableMigration(publicPlaylistItemV1))
+	addTableIndicesMigrations(mg, "v1", publicPlaylistItemV1)
+}
+
+func addPublicFolderMigration(mg *Migrator) {
+	var publicFolderV1 = Table{
+		Name: "dashboard_public_folder",
+		Columns: []*Column{
+			{Name: "uid", Type: DB_NVarchar, Length: 40, IsPrimaryKey: true},
+			{Name: "folder_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "is_enabled", Type: DB_Bool, Nullable: false, Default: "0"},
+			{Name: "access_token", Type: DB_NVarchar, Length: 32, Nullable: false},
+
+			{Name: "created_by", Type: DB_Int, Nullable: false},
+			{Name: "updated_by", Type: DB_Int, Nullable: true},
+
+			{Name: "created_at", Type: DB_DateTime, Nullable: false},
+			{Name: "updated_at", Type: DB_DateTime, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "folder_uid"}, Type: UniqueIndex},
+			{Cols: []string{"access_token"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create dashboard public folder table v1", NewAddTableMigration(publicFolderV1))
+	addTableIndicesMigrations(mg, "v1", publicFolderV1)
+}
+
+func addPublicDashboardEmailSessionMigration(mg *Migrator) {
+	var magicLinkV1 = Table{
+		Name: "dashboard_public_magic_link",
+		Columns: []*Column{
+			{Name: "token_hash", Type: DB_NVarchar, Length: 64, IsPrimaryKey: true},
+			{Name: "public_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "email", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "expires_at", Type: DB_DateTime, Nullable: false},
+			{Name: "created_at", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"expires_at"}},
+		},
+	}
+
+	var sessionV1 = Table{
+		Name: "dashboard_public_session",
+		Columns: []*Column{
+			{Name: "token_hash", Type: DB_NVarchar, Length: 64, IsPrimaryKey: true},
+			{Name: "public_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "email", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "expires_at", Type: DB_DateTime, Nullable: false},
+			{Name: "created_at", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"expires_at"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard public magic link table v1", NewAddTableMigration(magicLinkV1))
+	addTableIndicesMigrations(mg, "v1", magicLinkV1)
+
+	mg.AddMigration("create dashboard public session table v1", NewAddTableMigration(sessionV1))
+	addTableIndicesMigrations(mg, "v1", sessionV1)
+}
+
+func addPublicDashboardReportMigration(mg *Migrator) {
+	var usageV1 = Table{
+		Name: "dashboard_public_usage",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "public_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "day", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "query_count", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "error_count", Type: DB_BigInt, Nullable: false, Default: "0"},
+		},
+		Indices: []*Index{
+			{Cols: []string{"public_dashboard_uid", "day"}, Type: UniqueIndex},
+		},
+	}
+
+	var reportSubscriptionV1 = Table{
+		Name: "dashboard_public_report_subscription",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "email", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "last_sent_at", Type: DB_DateTime, Nullable: false},
+			{Name: "created_at", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "user_id"}, Type: UniqueIndex},
+			{Cols: []string{"last_sent_at"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard public usage table v1", NewAddTableMigration(usageV1))
+	addTableIndicesMigrations(mg, "v1", usageV1)
+
+	mg.AddMigration("create dashboard public report subscription table v1", NewAddTableMigration(reportSubscriptionV1))
+	addTableIndicesMigrations(mg, "v1", reportSubscriptionV1)
+
+	mg.AddMigration("add view_count column to dashboard public usage table", NewAddColumnMigration(usageV1, &Column{
+		Name:     "view_count",
+		Type:     DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add anonymous_view_count column to dashboard public usage table", NewAddColumnMigration(usageV1, &Column{
+		Name:     "anonymous_view_count",
+		Type:     DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	var panelUsageV1 = Table{
+		Name: "dashboard_public_panel_usage",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "public_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "panel_id", Type: DB_BigInt, Nullable: false},
+			{Name: "day", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "query_count", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "duration_ms", Type: DB_BigInt, Nullable: false, Default: "0"},
+		},
+		Indices: []*Index{
+			{Cols: []string{"public_dashboard_uid", "panel_id", "day"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create dashboard public panel usage table v1", NewAddTableMigration(panelUsageV1))
+	addTableIndicesMigrations(mg, "v1", panelUsageV1)
 }
@@ -134,4 +134,9 @@ func addDataSourceMigration(mg *Migrator) {
 
 	mg.AddMigration("add unique index datasource_org_id_is_default", NewAddIndexMigration(tableV2, &Index{
 		Cols: []string{"org_id", "is_default"}}))
+
+	// created_by counts the data sources of a user against its quota
+	mg.AddMigration("Add created_by column to data_source table", NewAddColumnMigration(tableV2, &Column{
+		Name: "created_by", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
 }
@@ -100,6 +100,17 @@ func (*OSSMigrations) AddMigration(mg *Migrator) {
 	accesscontrol.AddAdminOnlyMigration(mg)
 	accesscontrol.AddSeedAssignmentMigrations(mg)
 
+	addPublicPlaylistMigration(mg)
+	addPublicFolderMigration(mg)
+	addPublicDashboardEmailSessionMigration(mg)
+	addPublicDashboardReportMigration(mg)
+	addUserAttributeMigrations(mg)
+	addUserPasswordHistoryMigrations(mg)
+	addUserAuditMigrations(mg)
+	addUserEmailVerificationMigrations(mg)
+	addUserLabelMigrations(mg)
+	addUserLoginHistoryMigrations(mg)
+
 	// TODO: This migration will be enabled later in the nested folder feature
 	// implementation process. It is on hold so we can continue working on the
 	// store implementation without impacting any grafana instances built off
@@ -8,6 +8,7 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/assert"
@@ -237,6 +238,49 @@ func TestDatabaseLocking(t *testing.T) {
 	assert.Equal(t, int64(1), errorNum)
 }
 
+func TestAddIndexOnlineMigration(t *testing.T) {
+	testDB := getTestDB(t, getDBType())
+	x, err := xorm.NewEngine(testDB.DriverName, testDB.ConnStr)
+	require.NoError(t, err)
+
+	table := Table{
+		Name: "online_index_test",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+		},
+	}
+	index := &Index{Cols: []string{"org_id"}}
+	t.Cleanup(func() {
+		_, err := x.Exec(NewDialect(x).DropTable(table.Name))
+		require.NoError(t, err)
+	})
+
+	addMigrations := func(mg *Migrator) {
+		addMigrationLogMigrations(mg)
+		mg.AddMigration("create online_index_test table", NewAddTableMigration(table))
+		mg.AddMigration("add online_index_test org_id index", NewAddIndexOnlineMigration(table, index).LockTimeout(time.Second))
+	}
+
+	mg := NewMigrator(x, &setting.Cfg{})
+	addMigrations(mg)
+	require.NoError(t, mg.Start(false, 0))
+
+	indexSQL, args := mg.Dialect.IndexCheckSQL(table.Name, index.XName(table.Name))
+	results, err := x.SQL(indexSQL, args...).Query()
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	log, err := mg.GetMigrationLog()
+	require.NoError(t, err)
+	require.True(t, log["add online_index_test org_id index"].Success)
+
+	// the migration isn't run again
+	mg = NewMigrator(x, &setting.Cfg{})
+	addMigrations(mg)
+	require.NoError(t, mg.Start(false, 0))
+}
+
 func checkStepsAndDatabaseMatch(t *testing.T, mg *Migrator, expected []string) {
 	t.Helper()
 	log, err := mg.GetMigrationLog()
@@ -139,6 +139,66 @@ func addUserMigrations(mg *Migrator) {
 			SQLite(migSQLITEisServiceAccountNullable).
 			Postgres("ALTER TABLE `user` ALTER COLUMN is_service_account DROP NOT NULL;").
 			Mysql("ALTER TABLE user MODIFY is_service_account BOOLEAN DEFAULT 0;"))
+
+	// deleted_at marks soft deleted users, they can be restored until they are purged
+	mg.AddMigration("Add deleted_at column to user", NewAddColumnMigration(userV2, &Column{
+		Name: "deleted_at", Type: DB_DateTime, Nullable: true,
+	}))
+
+	mg.AddMigration("Add index user.deleted_at", NewAddIndexMigration(userV2, &Index{
+		Cols: []string{"deleted_at"},
+	}))
+
+	// soft deleted users free their login and email, which are kept in deleted_login and deleted_email until they
+	// are restored
+	mg.AddMigration("Add deleted_login column to user", NewAddColumnMigration(userV2, &Column{
+		Name: "deleted_login", Type: DB_NVarchar, Length: 190, Nullable: true,
+	}))
+
+	mg.AddMigration("Add deleted_email column to user", NewAddColumnMigration(userV2, &Column{
+		Name: "deleted_email", Type: DB_NVarchar, Length: 190, Nullable: true,
+	}))
+
+	// failed_login_attempts counts the consecutive failed logins of users, locking them until locked_until
+	mg.AddMigration("Add failed_login_attempts column to user", NewAddColumnMigration(userV2, &Column{
+		Name: "failed_login_attempts", Type: DB_Int, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("Add locked_until column to user", NewAddColumnMigration(userV2, &Column{
+		Name: "locked_until", Type: DB_DateTime, Nullable: true,
+	}))
+
+	// the client of the last login of users, recorded when last_login_client_tracking is enabled
+	mg.AddMigration("Add last_login_at column to user", NewAddColumnMigration(userV2, &Column{
+		Name: "last_login_at", Type: DB_DateTime, Nullable: true,
+	}))
+
+	mg.AddMigration("Add last_login_ip column to user", NewAddColumnMigration(userV2, &Column{
+		Name: "last_login_ip", Type: DB_NVarchar, Length: 64, Nullable: true,
+	}))
+
+	mg.AddMigration("Add last_login_user_agent column to user", NewAddColumnMigration(userV2, &Column{
+		Name: "last_login_user_agent", Type: DB_NVarchar, Length: 255, Nullable: true,
+	}))
+
+	// users are created with version 1 and their updates increment it, version 0 is never the version of a user
+	mg.AddMigration("Set version of unversioned users", NewRawSQLMigration("").
+		SQLite("UPDATE user SET version = 1 WHERE version = 0").
+		Postgres(`UPDATE "user" SET version = 1 WHERE version = 0`).
+		Mysql("UPDATE `user` SET version = 1 WHERE version = 0"))
+
+	// profile fields managed by administrators or identity providers are locked, users can't change them
+	mg.AddMigration("Add name_locked column to user", NewAddColumnMigration(userV2, &Column{
+		Name: "name_locked", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("Add email_locked column to user", NewAddColumnMigration(userV2, &Column{
+		Name: "email_locked", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("Add login_locked column to user", NewAddColumnMigration(userV2, &Column{
+		Name: "login_locked", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
 }
 
 const migSQLITEisServiceAccountNullable = `ALTER TABLE user ADD COLUMN tmp_service_account BOOLEAN DEFAULT 0;
@@ -146,6 +206,135 @@ UPDATE user SET tmp_service_account = is_service_account;
 ALTER TABLE user DROP COLUMN is_service_account;
 ALTER TABLE user RENAME COLUMN tmp_service_account TO is_service_account;`
 
+func addUserAttributeMigrations(mg *Migrator) {
+	userAttributeV1 := Table{
+		Name: "user_attribute",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "key", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "value", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id", "key"}, Type: UniqueIndex},
+			{Cols: []string{"key", "value"}},
+		},
+	}
+
+	mg.AddMigration("create user_attribute table v1", NewAddTableMigration(userAttributeV1))
+	addTableIndicesMigrations(mg, "v1", userAttributeV1)
+}
+
+func addUserPasswordHistoryMigrations(mg *Migrator) {
+	userPasswordHistoryV1 := Table{
+		Name: "user_password_history",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "password", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id"}},
+		},
+	}
+
+	mg.AddMigration("create user_password_history table v1", NewAddTableMigration(userPasswordHistoryV1))
+	addTableIndicesMigrations(mg, "v1", userPasswordHistoryV1)
+}
+
+func addUserAuditMigrations(mg *Migrator) {
+	userAuditV1 := Table{
+		Name: "user_audit",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "actor_id", Type: DB_BigInt, Nullable: false},
+			{Name: "actor_login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "action", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "changes", Type: DB_Text, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id", "created"}},
+		},
+	}
+
+	mg.AddMigration("create user_audit table v1", NewAddTableMigration(userAuditV1))
+	addTableIndicesMigrations(mg, "v1", userAuditV1)
+
+	// changes made while impersonating a user are recorded with the Grafana server admin impersonating the actor
+	mg.AddMigration("Add impersonator_id column to user_audit", NewAddColumnMigration(userAuditV1, &Column{
+		Name: "impersonator_id", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("Add impersonator_login column to user_audit", NewAddColumnMigration(userAuditV1, &Column{
+		Name: "impersonator_login", Type: DB_NVarchar, Length: 190, Nullable: true,
+	}))
+}
+
+func addUserEmailVerificationMigrations(mg *Migrator) {
+	userEmailVerificationV1 := Table{
+		Name: "user_email_verification",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "email", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "token_hash", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "expires", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"token_hash"}, Type: UniqueIndex},
+			{Cols: []string{"user_id"}},
+		},
+	}
+
+	mg.AddMigration("create user_email_verification table v1", NewAddTableMigration(userEmailVerificationV1))
+	addTableIndicesMigrations(mg, "v1", userEmailVerificationV1)
+}
+
+func addUserLabelMigrations(mg *Migrator) {
+	userLabelV1 := Table{
+		Name: "user_label",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "label", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id", "label"}, Type: UniqueIndex},
+			{Cols: []string{"label"}},
+		},
+	}
+
+	mg.AddMigration("create user_label table v1", NewAddTableMigration(userLabelV1))
+	addTableIndicesMigrations(mg, "v1", userLabelV1)
+}
+
+func addUserLoginHistoryMigrations(mg *Migrator) {
+	userLoginHistoryV1 := Table{
+		Name: "user_login_history",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "auth_module", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "result", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id", "created"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create user_login_history table v1", NewAddTableMigration(userLoginHistoryV1))
+	addTableIndicesMigrations(mg, "v1", userLoginHistoryV1)
+}
+
 type AddMissingUserSaltAndRandsMigration struct {
 	MigrationBase
 }
@@ -0,0 +1,109 @@
+package migrator
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+// CockroachDialect is the dialect of CockroachDB. CockroachDB speaks the protocol and the SQL of Postgres, so its
+// DriverName is Postgres and the code written for Postgres runs on it. It differs in the following ways:
+//   - the keys of SERIAL columns are generated by unique_rowid() rather than by sequences, so there are no sequences to
+//     sync or reset
+//   - transactions are serializable, and fail with a serialization failure when they conflict, to be run again
+//   - there are no advisory locks
+//   - tables aren't partitioned by ranges of time, their old rows are deleted instead
+type CockroachDialect struct {
+	PostgresDialect
+}
+
+func NewCockroachDialect(engine *xorm.Engine) Dialect {
+	d := CockroachDialect{}
+	d.BaseDialect.dialect = &d
+	d.BaseDialect.engine = engine
+	d.BaseDialect.driverName = Postgres
+	return &d
+}
+
+// ColString returns the definition of a column, generating the values of the UUID columns which can't be null
+func (db *CockroachDialect) ColString(col *Column) string {
+	return db.PostgresDialect.ColString(withUUIDDefault(col))
+}
+
+// ColStringNoPk returns the definition of a column, generating the values of the UUID columns which can't be null
+func (db *CockroachDialect) ColStringNoPk(col *Column) string {
+	return db.PostgresDialect.ColStringNoPk(withUUIDDefault(col))
+}
+
+// withUUIDDefault returns the column with a default generating random UUIDs, when it's a UUID column which can't be
+// null and doesn't have a default
+func withUUIDDefault(col *Column) *Column {
+	if col.Type != DB_Uuid || col.Nullable || col.Default != "" {
+		return col
+	}
+	withDefault := *col
+	withDefault.Default = "gen_random_uuid()"
+	return &withDefault
+}
+
+func (db *CockroachDialect) CreatePartitionedTableSQL(table *Table, column string) []string {
+	return []string{db.CreateTableSQL(table)}
+}
+
+func (db *CockroachDialect) AddPartitionSQL(tableName string, partition Partition) string {
+	return ""
+}
+
+func (db *CockroachDialect) DropPartitionSQL(tableName, partitionName string) string {
+	return ""
+}
+
+func (db *CockroachDialect) PartitionsSQL(tableName string) (string, []interface{}) {
+	return "", nil
+}
+
+// PostInsertId doesn't sync the keys of the org table, as they aren't generated by a sequence
+func (db *CockroachDialect) PostInsertId(table string, sess *xorm.Session) error {
+	return nil
+}
+
+// TruncateDBTables truncates all the tables, keeping the default dashboard permissions
+func (db *CockroachDialect) TruncateDBTables() error {
+	tables, err := db.engine.DBMetas()
+	if err != nil {
+		return err
+	}
+	sess := db.engine.NewSession()
+	defer sess.Close()
+
+	for _, table := range tables {
+		switch table.Name {
+		case "", "migration_log":
+			continue
+		case "dashboard_acl":
+			if _, err := sess.Exec(fmt.Sprintf("DELETE FROM %v WHERE dashboard_id != -1 AND org_id != -1;", db.Quote(table.Name))); err != nil {
+				return fmt.Errorf("failed to truncate table %q: %w", table.Name, err)
+			}
+		default:
+			if _, err := sess.Exec(fmt.Sprintf("TRUNCATE TABLE %v CASCADE;", db.Quote(table.Name))); err != nil {
+				if db.isUndefinedTable(err) {
+					continue
+				}
+				return fmt.Errorf("failed to truncate table %q: %w", table.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Lock doesn't lock the database, CockroachDB has no advisory locks. The migrations should run from a single instance,
+// with skip_migrations set on the others
+func (db *CockroachDialect) Lock(cfg LockCfg) error {
+	return nil
+}
+
+// Unlock doesn't unlock the database, see Lock
+func (db *CockroachDialect) Unlock(cfg LockCfg) error {
+	return nil
+}
@@ -37,6 +37,22 @@ func (c *IfIndexNotExistsCondition) SQL(dialect Dialect) (string, []interface{})
 	return dialect.IndexCheckSQL(c.TableName, c.IndexName)
 }
 
+// IfValidIndexNotExistsCondition is fulfilled when a table has no valid index of a name. On Postgres, the invalid
+// indexes left by concurrent creations which failed don't count, so they can be created again
+type IfValidIndexNotExistsCondition struct {
+	NotExistsMigrationCondition
+	TableName string
+	IndexName string
+}
+
+func (c *IfValidIndexNotExistsCondition) SQL(dialect Dialect) (string, []interface{}) {
+	if dialect.DriverName() == Postgres {
+		return "SELECT 1 FROM pg_index i JOIN pg_class c ON c.oid = i.indexrelid JOIN pg_class t ON t.oid = i.indrelid " +
+			"WHERE t.relname = ? AND c.relname = ? AND i.indisvalid", []interface{}{c.TableName, c.IndexName}
+	}
+	return dialect.IndexCheckSQL(c.TableName, c.IndexName)
+}
+
 type IfColumnNotExistsCondition struct {
 	NotExistsMigrationCondition
 	TableName  string
@@ -3,6 +3,7 @@ package migrator
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"xorm.io/xorm"
 )
@@ -30,6 +31,9 @@ type Dialect interface {
 	OrderBy(order string) string
 
 	CreateIndexSQL(tableName string, index *Index) string
+	// CreateIndexOnlineSQL returns the statement creating an index without blocking the writes to the table, or the
+	// statement of CreateIndexSQL when the database can't
+	CreateIndexOnlineSQL(tableName string, index *Index) string
 	CreateTableSQL(table *Table) string
 	AddColumnSQL(tableName string, col *Column) string
 	CopyTableData(sourceTable string, targetTable string, sourceCols []string, targetCols []string) string
@@ -56,6 +60,28 @@ type Dialect interface {
 
 	Limit(limit int64) string
 	LimitOffset(limit int64, offset int64) string
+	// MaxParameters returns the maximum number of parameters of a statement
+	MaxParameters() int
+	// SavepointSQL returns the statements creating a savepoint, rolling back to it and releasing it, or empty
+	// statements when the database doesn't support savepoints
+	SavepointSQL(name string) (create, rollbackTo, release string)
+	// LockTimeoutSQL returns the statements setting how long the statements of a session wait for a lock before
+	// failing, and resetting it, or empty statements when the database doesn't support it
+	LockTimeoutSQL(timeout time.Duration) (set, reset string)
+	// CreatePartitionedTableSQL returns the statements creating a table partitioned by ranges of column, with a
+	// partition catching the rows out of the ranges of the others. The databases which don't partition tables create
+	// it as is
+	CreatePartitionedTableSQL(table *Table, column string) []string
+	// AddPartitionSQL returns the statement adding a range partition to a partitioned table, or an empty statement
+	// when the database doesn't partition tables
+	AddPartitionSQL(tableName string, partition Partition) string
+	// DropPartitionSQL returns the statement dropping a partition and its rows
+	DropPartitionSQL(tableName, partitionName string) string
+	// PartitionsSQL returns the query listing the names of the partitions of a table
+	PartitionsSQL(tableName string) (string, []interface{})
+	// ForUpdateSQL returns a SELECT locking the rows it reads until the end of the transaction, or the SELECT as it is
+	// when the database doesn't lock rows
+	ForUpdateSQL(query string) string
 
 	PreInsertId(table string, sess *xorm.Session) error
 	PostInsertId(table string, sess *xorm.Session) error
@@ -67,6 +93,9 @@ type Dialect interface {
 	IsUniqueConstraintViolation(err error) bool
 	ErrorMessage(err error) string
 	IsDeadlock(err error) bool
+	// IsLockContention returns true when a statement failed because of concurrent transactions, such as a deadlock or
+	// a lock timeout, and may succeed if it is retried
+	IsLockContention(err error) bool
 	Lock(LockCfg) error
 	Unlock(LockCfg) error
 }
@@ -79,12 +108,14 @@ type LockCfg struct {
 type dialectFunc func(*xorm.Engine) Dialect
 
 var supportedDialects = map[string]dialectFunc{
-	MySQL:                  NewMysqlDialect,
-	SQLite:                 NewSQLite3Dialect,
-	Postgres:               NewPostgresDialect,
-	MySQL + "WithHooks":    NewMysqlDialect,
-	SQLite + "WithHooks":   NewSQLite3Dialect,
-	Postgres + "WithHooks": NewPostgresDialect,
+	MySQL:                     NewMysqlDialect,
+	SQLite:                    NewSQLite3Dialect,
+	Postgres:                  NewPostgresDialect,
+	CockroachDB:               NewCockroachDialect,
+	MySQL + "WithHooks":       NewMysqlDialect,
+	SQLite + "WithHooks":      NewSQLite3Dialect,
+	Postgres + "WithHooks":    NewPostgresDialect,
+	CockroachDB + "WithHooks": NewCockroachDialect,
 }
 
 func NewDialect(engine *xorm.Engine) Dialect {
@@ -189,6 +220,14 @@ func (b *BaseDialect) CreateIndexSQL(tableName string, index *Index) string {
 	return fmt.Sprintf("CREATE%s INDEX %v ON %v (%v);", unique, quote(idxName), quote(tableName), strings.Join(quotedCols, ","))
 }
 
+func (b *BaseDialect) CreateIndexOnlineSQL(tableName string, index *Index) string {
+	return b.dialect.CreateIndexSQL(tableName, index)
+}
+
+func (b *BaseDialect) LockTimeoutSQL(timeout time.Duration) (string, string) {
+	return "", ""
+}
+
 func (b *BaseDialect) QuoteColList(cols []string) string {
 	var sourceColsSQL = ""
 	for _, col := range cols {
@@ -305,6 +344,31 @@ func (b *BaseDialect) CleanDB() error {
 	return nil
 }
 
+func (b *BaseDialect) SavepointSQL(name string) (string, string, string) {
+	name = b.dialect.Quote(name)
+	return "SAVEPOINT " + name, "ROLLBACK TO SAVEPOINT " + name, "RELEASE SAVEPOINT " + name
+}
+
+func (b *BaseDialect) CreatePartitionedTableSQL(table *Table, column string) []string {
+	return []string{b.dialect.CreateTableSQL(table)}
+}
+
+func (b *BaseDialect) AddPartitionSQL(tableName string, partition Partition) string {
+	return ""
+}
+
+func (b *BaseDialect) DropPartitionSQL(tableName, partitionName string) string {
+	return ""
+}
+
+func (b *BaseDialect) PartitionsSQL(tableName string) (string, []interface{}) {
+	return "", nil
+}
+
+func (b *BaseDialect) ForUpdateSQL(query string) string {
+	return query + " FOR UPDATE"
+}
+
 func (b *BaseDialect) NoOpSQL() string {
 	return "SELECT 0;"
 }
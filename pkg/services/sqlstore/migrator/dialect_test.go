@@ -0,0 +1,114 @@
+package migrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateIndexOnlineSQL(t *testing.T) {
+	index := &Index{Cols: []string{"org_id", "epoch"}}
+
+	require.Equal(t,
+		`CREATE INDEX CONCURRENTLY "IDX_annotation_org_id_epoch" ON "annotation" ("org_id","epoch");`,
+		NewPostgresDialect(nil).CreateIndexOnlineSQL("annotation", index))
+	require.Equal(t,
+		"CREATE INDEX `IDX_annotation_org_id_epoch` ON `annotation` (`org_id`,`epoch`) ALGORITHM=INPLACE LOCK=NONE;",
+		NewMysqlDialect(nil).CreateIndexOnlineSQL("annotation", index))
+	require.Equal(t,
+		NewSQLite3Dialect(nil).CreateIndexSQL("annotation", index),
+		NewSQLite3Dialect(nil).CreateIndexOnlineSQL("annotation", index))
+
+	unique := &Index{Cols: []string{"uid"}, Type: UniqueIndex}
+	require.Equal(t,
+		`CREATE UNIQUE INDEX CONCURRENTLY "UQE_dashboard_uid" ON "dashboard" ("uid");`,
+		NewPostgresDialect(nil).CreateIndexOnlineSQL("dashboard", unique))
+}
+
+func TestLockTimeoutSQL(t *testing.T) {
+	set, reset := NewPostgresDialect(nil).LockTimeoutSQL(1500 * time.Millisecond)
+	require.Equal(t, "SET lock_timeout = 1500", set)
+	require.Equal(t, "RESET lock_timeout", reset)
+
+	set, reset = NewMysqlDialect(nil).LockTimeoutSQL(1500 * time.Millisecond)
+	require.Equal(t, "SET SESSION lock_wait_timeout = 2", set)
+	require.Equal(t, "SET SESSION lock_wait_timeout = DEFAULT", reset)
+
+	set, reset = NewSQLite3Dialect(nil).LockTimeoutSQL(time.Second)
+	require.Empty(t, set)
+	require.Empty(t, reset)
+}
+
+func TestForUpdateSQL(t *testing.T) {
+	query := "SELECT id FROM quota WHERE org_id = ?"
+	require.Equal(t, query+" FOR UPDATE", NewPostgresDialect(nil).ForUpdateSQL(query))
+	require.Equal(t, query+" FOR UPDATE", NewMysqlDialect(nil).ForUpdateSQL(query))
+	require.Equal(t, query, NewSQLite3Dialect(nil).ForUpdateSQL(query))
+}
+
+func TestCockroachColString(t *testing.T) {
+	d := NewCockroachDialect(nil)
+
+	require.Equal(t, `"uid" UUID NOT NULL DEFAULT gen_random_uuid() `, d.ColString(&Column{Name: "uid", Type: DB_Uuid}))
+	require.Equal(t, `"uid" UUID NULL `, d.ColString(&Column{Name: "uid", Type: DB_Uuid, Nullable: true}))
+	require.Equal(t, `"name" VARCHAR(40) NOT NULL `, d.ColString(&Column{Name: "name", Type: DB_NVarchar, Length: 40}))
+	require.Equal(t, NewPostgresDialect(nil).ColString(&Column{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true}),
+		d.ColString(&Column{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true}))
+}
+
+func TestTimePartitioning(t *testing.T) {
+	partitioning := TimePartitioning{Column: "epoch", Unit: time.Millisecond, Interval: 24 * time.Hour}
+	at := time.Date(2022, 10, 17, 15, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	partition := partitioning.PartitionAt("annotation", at)
+	require.Equal(t, Partition{
+		Name: "annotation_p202210170000",
+		From: time.Date(2022, 10, 17, 0, 0, 0, 0, time.UTC).UnixMilli(),
+		To:   time.Date(2022, 10, 18, 0, 0, 0, 0, time.UTC).UnixMilli(),
+	}, partition)
+
+	start, ok := partitioning.PartitionStart("annotation", partition.Name)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2022, 10, 17, 0, 0, 0, 0, time.UTC), start)
+	_, ok = partitioning.PartitionStart("annotation", "annotation_default")
+	require.False(t, ok)
+	_, ok = partitioning.PartitionStart("annotation", "p_max")
+	require.False(t, ok)
+}
+
+func TestPartitionSQL(t *testing.T) {
+	table := &Table{
+		Name: "login_history",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+		},
+		PrimaryKeys: []string{"id"},
+	}
+	partition := Partition{Name: "login_history_p202210170000", From: 100, To: 200}
+
+	require.Equal(t, []string{
+		`CREATE TABLE IF NOT EXISTS "login_history" (
+"id" SERIAL NOT NULL
+, "created" BIGINT NOT NULL
+, PRIMARY KEY ( "id","created" )) PARTITION BY RANGE ("created");`,
+		`CREATE TABLE IF NOT EXISTS "login_history_default" PARTITION OF "login_history" DEFAULT;`,
+	}, NewPostgresDialect(nil).CreatePartitionedTableSQL(table, "created"))
+	require.Equal(t,
+		`CREATE TABLE IF NOT EXISTS "login_history_p202210170000" PARTITION OF "login_history" FOR VALUES FROM (100) TO (200);`,
+		NewPostgresDialect(nil).AddPartitionSQL("login_history", partition))
+
+	require.Equal(t, []string{"CREATE TABLE IF NOT EXISTS `login_history` (\n`id` BIGINT(20) NOT NULL AUTO_INCREMENT\n, `created` BIGINT(20) NOT NULL\n, PRIMARY KEY (`id`,`created`)\n) ENGINE=InnoDB DEFAULT CHARSET utf8mb4 COLLATE utf8mb4_unicode_ci PARTITION BY RANGE (`created`) (PARTITION `p_max` VALUES LESS THAN MAXVALUE);"},
+		NewMysqlDialect(nil).CreatePartitionedTableSQL(table, "created"))
+	require.Equal(t,
+		"ALTER TABLE `login_history` REORGANIZE PARTITION `p_max` INTO (PARTITION `login_history_p202210170000` VALUES LESS THAN (200), PARTITION `p_max` VALUES LESS THAN MAXVALUE);",
+		NewMysqlDialect(nil).AddPartitionSQL("login_history", partition))
+
+	// SQLite doesn't partition tables
+	require.Equal(t, []string{NewSQLite3Dialect(nil).CreateTableSQL(table)}, NewSQLite3Dialect(nil).CreatePartitionedTableSQL(table, "created"))
+	require.Empty(t, NewSQLite3Dialect(nil).AddPartitionSQL("login_history", partition))
+	query, _ := NewSQLite3Dialect(nil).PartitionsSQL("login_history")
+	require.Empty(t, query)
+	require.Equal(t, []string{"id"}, table.PrimaryKeys)
+}
@@ -1,7 +1,11 @@
 package migrator
 
 import (
+	"context"
 	"strings"
+	"time"
+
+	"xorm.io/xorm"
 )
 
 type MigrationBase struct {
@@ -164,6 +168,87 @@ func (m *AddIndexMigration) SQL(dialect Dialect) string {
 	return dialect.CreateIndexSQL(m.tableName, m.index)
 }
 
+// defaultOnlineIndexLockTimeout is how long the creation of an index waits for the locks it needs when the migration
+// doesn't set it
+const defaultOnlineIndexLockTimeout = 10 * time.Second
+
+// AddIndexOnlineMigration creates an index without blocking the writes to its table, so large tables, such as the
+// dashboard and annotation ones, stay writable during upgrades. The index is created concurrently on Postgres and in
+// place on MySQL. The migration runs outside of a transaction, waits for the locks it needs up to its lock timeout,
+// and is attempted again when it times out.
+type AddIndexOnlineMigration struct {
+	MigrationBase
+	tableName   string
+	index       *Index
+	lockTimeout time.Duration
+	attempts    int
+}
+
+func NewAddIndexOnlineMigration(table Table, index *Index) *AddIndexOnlineMigration {
+	m := &AddIndexOnlineMigration{tableName: table.Name, index: index, lockTimeout: defaultOnlineIndexLockTimeout, attempts: 3}
+	m.Condition = &IfValidIndexNotExistsCondition{TableName: table.Name, IndexName: index.XName(table.Name)}
+	return m
+}
+
+// LockTimeout sets how long each attempt waits for the locks it needs
+func (m *AddIndexOnlineMigration) LockTimeout(timeout time.Duration) *AddIndexOnlineMigration {
+	m.lockTimeout = timeout
+	return m
+}
+
+// Attempts sets how many times the creation is attempted when it times out waiting for a lock
+func (m *AddIndexOnlineMigration) Attempts(attempts int) *AddIndexOnlineMigration {
+	m.attempts = attempts
+	return m
+}
+
+func (m *AddIndexOnlineMigration) SQL(dialect Dialect) string {
+	return dialect.CreateIndexOnlineSQL(m.tableName, m.index)
+}
+
+func (m *AddIndexOnlineMigration) NonTransactional() bool {
+	return true
+}
+
+func (m *AddIndexOnlineMigration) Exec(sess *xorm.Session, mg *Migrator) error {
+	ctx := context.Background()
+	// the lock timeout is a setting of the connection, so all the statements run on the same one
+	conn, err := sess.DB().DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if set, reset := mg.Dialect.LockTimeoutSQL(m.lockTimeout); set != "" {
+		if _, err := conn.ExecContext(ctx, set); err != nil {
+			return err
+		}
+		defer func() {
+			if _, err := conn.ExecContext(ctx, reset); err != nil {
+				mg.Logger.Warn("Failed to reset the lock timeout", "id", m.Id(), "error", err)
+			}
+		}()
+	}
+
+	for attempt := 1; ; attempt++ {
+		if mg.Dialect.DriverName() == Postgres {
+			// a concurrent creation which failed leaves an invalid index, which must be dropped to create it again. The
+			// condition of the migration made sure there is no valid index of the name
+			dropSQL := "DROP INDEX CONCURRENTLY IF EXISTS " + mg.Dialect.Quote(m.index.XName(m.tableName))
+			if _, err := conn.ExecContext(ctx, dropSQL); err != nil {
+				return err
+			}
+		}
+
+		_, err := conn.ExecContext(ctx, m.SQL(mg.Dialect))
+		if err == nil || attempt >= m.attempts || !mg.Dialect.IsLockContention(err) {
+			return err
+		}
+		mg.Logger.Warn("Creating index timed out waiting for a lock, retrying", "id", m.Id(), "attempt", attempt, "error", err)
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
 type DropIndexMigration struct {
 	MigrationBase
 	tableName string
@@ -153,7 +153,12 @@ func (mg *Migrator) run() (err error) {
 			Timestamp:   time.Now(),
 		}
 
-		err := mg.InTransaction(func(sess *xorm.Session) error {
+		runMigration := mg.InTransaction
+		if nonTransactional, ok := m.(NonTransactionalMigration); ok && nonTransactional.NonTransactional() {
+			runMigration = mg.withoutTransaction
+		}
+
+		err := runMigration(func(sess *xorm.Session) error {
 			err := mg.exec(m, sess)
 			if err != nil {
 				mg.Logger.Error("Exec failed", "error", err, "sql", sql)
@@ -250,6 +255,13 @@ func (mg *Migrator) InTransaction(callback dbTransactionFunc) error {
 	return nil
 }
 
+// withoutTransaction calls the callback with a session without a transaction, each statement being committed on its own
+func (mg *Migrator) withoutTransaction(callback dbTransactionFunc) error {
+	sess := mg.DBEngine.NewSession()
+	defer sess.Close()
+	return callback(sess)
+}
+
 func casRestoreOnErr(lock *atomic.Bool, o, n bool, casErr error, f func(LockCfg) error, lockCfg LockCfg) error {
 	if !lock.CAS(o, n) {
 		return casErr
@@ -4,8 +4,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/VividCortex/mysqlerr"
 	"github.com/go-sql-driver/mysql"
@@ -105,6 +107,62 @@ func (db *MySQLDialect) UpdateTableSQL(tableName string, columns []*Column) stri
 	return "ALTER TABLE " + db.Quote(tableName) + " " + strings.Join(statements, ", ") + ";"
 }
 
+// CreateIndexOnlineSQL returns the statement creating an index in place, which fails rather than locking the writes
+func (db *MySQLDialect) CreateIndexOnlineSQL(tableName string, index *Index) string {
+	return strings.TrimSuffix(db.CreateIndexSQL(tableName, index), ";") + " ALGORITHM=INPLACE LOCK=NONE;"
+}
+
+// mysqlCatchAllPartition is the partition of the rows after the ranges of the other partitions, which the new
+// partitions are split from
+const mysqlCatchAllPartition = "p_max"
+
+// CreatePartitionedTableSQL returns the statement creating a table partitioned by range, with a single partition until
+// partitions are added. The auto incremented key isn't the whole primary key anymore, so its definition is written here
+func (db *MySQLDialect) CreatePartitionedTableSQL(table *Table, column string) []string {
+	t := withPartitionKey(table, column)
+	definitions := make([]string, 0, len(t.Columns)+1)
+	for _, col := range t.Columns {
+		def := strings.TrimSpace(col.StringNoPk(db))
+		if col.IsAutoIncrement {
+			def += " " + db.AutoIncrStr()
+		}
+		definitions = append(definitions, def)
+	}
+	pks := make([]string, 0, len(t.PrimaryKeys))
+	for _, pk := range t.PrimaryKeys {
+		pks = append(pks, db.Quote(pk))
+	}
+	definitions = append(definitions, "PRIMARY KEY ("+strings.Join(pks, ",")+")")
+
+	return []string{fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n%s\n) ENGINE=InnoDB DEFAULT CHARSET utf8mb4 COLLATE utf8mb4_unicode_ci PARTITION BY RANGE (%s) (PARTITION %s VALUES LESS THAN MAXVALUE);",
+		db.Quote(t.Name), strings.Join(definitions, "\n, "), db.Quote(column), db.Quote(mysqlCatchAllPartition))}
+}
+
+// AddPartitionSQL returns the statement splitting a partition from the catch-all one. The partitions must be added in
+// increasing order
+func (db *MySQLDialect) AddPartitionSQL(tableName string, partition Partition) string {
+	return fmt.Sprintf("ALTER TABLE %s REORGANIZE PARTITION %s INTO (PARTITION %s VALUES LESS THAN (%d), PARTITION %s VALUES LESS THAN MAXVALUE);",
+		db.Quote(tableName), db.Quote(mysqlCatchAllPartition), db.Quote(partition.Name), partition.To, db.Quote(mysqlCatchAllPartition))
+}
+
+func (db *MySQLDialect) DropPartitionSQL(tableName, partitionName string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP PARTITION %s;", db.Quote(tableName), db.Quote(partitionName))
+}
+
+func (db *MySQLDialect) PartitionsSQL(tableName string) (string, []interface{}) {
+	return `SELECT PARTITION_NAME FROM information_schema.PARTITIONS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND PARTITION_NAME IS NOT NULL`, []interface{}{tableName}
+}
+
+// LockTimeoutSQL returns the statements setting the timeout of the metadata locks, in seconds
+func (db *MySQLDialect) LockTimeoutSQL(timeout time.Duration) (string, string) {
+	seconds := int64(math.Ceil(timeout.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return fmt.Sprintf("SET SESSION lock_wait_timeout = %d", seconds), "SET SESSION lock_wait_timeout = DEFAULT"
+}
+
 func (db *MySQLDialect) IndexCheckSQL(tableName, indexName string) (string, []interface{}) {
 	args := []interface{}{tableName, indexName}
 	sql := "SELECT 1 FROM " + db.Quote("INFORMATION_SCHEMA") + "." + db.Quote("STATISTICS") + " WHERE " + db.Quote("TABLE_SCHEMA") + " = DATABASE() AND " + db.Quote("TABLE_NAME") + "=? AND " + db.Quote("INDEX_NAME") + "=?"
@@ -206,10 +264,18 @@ func (db *MySQLDialect) ErrorMessage(err error) string {
 	return ""
 }
 
+func (db *MySQLDialect) MaxParameters() int {
+	return 65535
+}
+
 func (db *MySQLDialect) IsDeadlock(err error) bool {
 	return db.isThisError(err, mysqlerr.ER_LOCK_DEADLOCK)
 }
 
+func (db *MySQLDialect) IsLockContention(err error) bool {
+	return db.IsDeadlock(err) || db.isThisError(err, mysqlerr.ER_LOCK_WAIT_TIMEOUT)
+}
+
 // UpsertSQL returns the upsert sql statement for MySQL dialect
 func (db *MySQLDialect) UpsertSQL(tableName string, keyCols, updateCols []string) string {
 	q, _ := db.UpsertMultipleSQL(tableName, keyCols, updateCols, 1)
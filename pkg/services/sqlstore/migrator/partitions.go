@@ -0,0 +1,101 @@
+package migrator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"xorm.io/xorm"
+)
+
+// partitionNameLayout is the layout of the start of a partition in its name
+const partitionNameLayout = "200601021504"
+
+// TimePartitioning partitions a table by ranges of time, so the old rows of large append-only tables, such as the
+// annotation or the login history ones, are deleted by dropping their partitions, and the queries on recent rows only
+// read the recent partitions.
+type TimePartitioning struct {
+	// Column the table is partitioned by, holding unix timestamps
+	Column string
+	// Unit of the timestamps of the column, such as time.Second or time.Millisecond
+	Unit time.Duration
+	// Interval of time of a partition, such as 24 hours
+	Interval time.Duration
+}
+
+// Partition is a partition of a table holding the rows whose column is in [From, To)
+type Partition struct {
+	Name string
+	From int64
+	To   int64
+}
+
+// PartitionAt returns the partition of a table holding the rows of time t. The partitions are aligned on the
+// interval, in UTC
+func (p TimePartitioning) PartitionAt(tableName string, t time.Time) Partition {
+	start := t.UTC().Truncate(p.Interval)
+	end := start.Add(p.Interval)
+	return Partition{
+		Name: tableName + "_p" + start.Format(partitionNameLayout),
+		From: start.UnixNano() / int64(p.Unit),
+		To:   end.UnixNano() / int64(p.Unit),
+	}
+}
+
+// PartitionStart returns the start of the partition of a table with a name, or false when it's not a partition
+// returned by PartitionAt, such as the partition catching the rows out of the ranges of the others
+func (p TimePartitioning) PartitionStart(tableName, name string) (time.Time, bool) {
+	prefix := tableName + "_p"
+	if !strings.HasPrefix(name, prefix) {
+		return time.Time{}, false
+	}
+	start, err := time.Parse(partitionNameLayout, strings.TrimPrefix(name, prefix))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return start, true
+}
+
+// withPartitionKey returns the table with the partition column in its primary key, as the databases partitioning
+// tables require their unique keys to include it
+func withPartitionKey(table *Table, column string) *Table {
+	t := *table
+	for _, pk := range t.PrimaryKeys {
+		if pk == column {
+			return &t
+		}
+	}
+	t.PrimaryKeys = append(append([]string{}, t.PrimaryKeys...), column)
+	return &t
+}
+
+// AddPartitionedTableMigration creates a table partitioned by ranges of time. The databases which don't partition
+// tables create it as is. The unique indexes of the table must include the partition column, which is added to its
+// primary key.
+type AddPartitionedTableMigration struct {
+	MigrationBase
+	table        Table
+	partitioning TimePartitioning
+}
+
+func NewAddPartitionedTableMigration(table Table, partitioning TimePartitioning) *AddPartitionedTableMigration {
+	for _, col := range table.Columns {
+		if col.IsPrimaryKey {
+			table.PrimaryKeys = append(table.PrimaryKeys, col.Name)
+		}
+	}
+	return &AddPartitionedTableMigration{table: table, partitioning: partitioning}
+}
+
+func (m *AddPartitionedTableMigration) SQL(dialect Dialect) string {
+	return strings.Join(dialect.CreatePartitionedTableSQL(&m.table, m.partitioning.Column), "\n")
+}
+
+func (m *AddPartitionedTableMigration) Exec(sess *xorm.Session, mg *Migrator) error {
+	for _, statement := range mg.Dialect.CreatePartitionedTableSQL(&m.table, m.partitioning.Column) {
+		if _, err := sess.Exec(statement); err != nil {
+			return fmt.Errorf("failed to create partitioned table %s: %w", m.table.Name, err)
+		}
+	}
+	return nil
+}
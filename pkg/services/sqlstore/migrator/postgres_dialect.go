@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4/database"
 	"github.com/lib/pq"
@@ -111,6 +112,40 @@ func (db *PostgresDialect) IndexCheckSQL(tableName, indexName string) (string, [
 	return sql, args
 }
 
+// CreateIndexOnlineSQL returns the statement creating an index concurrently, which can't run in a transaction
+func (db *PostgresDialect) CreateIndexOnlineSQL(tableName string, index *Index) string {
+	return strings.Replace(db.CreateIndexSQL(tableName, index), " INDEX ", " INDEX CONCURRENTLY ", 1)
+}
+
+// CreatePartitionedTableSQL returns the statements creating a table partitioned by range, and its default partition
+func (db *PostgresDialect) CreatePartitionedTableSQL(table *Table, column string) []string {
+	create := strings.TrimSuffix(db.CreateTableSQL(withPartitionKey(table, column)), ";")
+	return []string{
+		fmt.Sprintf("%s PARTITION BY RANGE (%s);", create, db.Quote(column)),
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s DEFAULT;", db.Quote(table.Name+"_default"), db.Quote(table.Name)),
+	}
+}
+
+func (db *PostgresDialect) AddPartitionSQL(tableName string, partition Partition) string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM (%d) TO (%d);",
+		db.Quote(partition.Name), db.Quote(tableName), partition.From, partition.To)
+}
+
+func (db *PostgresDialect) DropPartitionSQL(tableName, partitionName string) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s;", db.Quote(partitionName))
+}
+
+func (db *PostgresDialect) PartitionsSQL(tableName string) (string, []interface{}) {
+	return `SELECT c.relname FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		WHERE p.relname = ?`, []interface{}{tableName}
+}
+
+func (db *PostgresDialect) LockTimeoutSQL(timeout time.Duration) (string, string) {
+	return fmt.Sprintf("SET lock_timeout = %d", timeout.Milliseconds()), "RESET lock_timeout"
+}
+
 func (db *PostgresDialect) DropIndexSQL(tableName string, index *Index) string {
 	quote := db.Quote
 	idxName := index.XName(tableName)
@@ -206,10 +241,19 @@ func (db *PostgresDialect) IsUniqueConstraintViolation(err error) bool {
 	return db.isThisError(err, "23505")
 }
 
+func (db *PostgresDialect) MaxParameters() int {
+	return 65535
+}
+
 func (db *PostgresDialect) IsDeadlock(err error) bool {
 	return db.isThisError(err, "40P01")
 }
 
+// IsLockContention returns true for deadlocks, serialization failures and lock timeouts
+func (db *PostgresDialect) IsLockContention(err error) bool {
+	return db.IsDeadlock(err) || db.isThisError(err, "40001") || db.isThisError(err, "55P03")
+}
+
 func (db *PostgresDialect) PostInsertId(table string, sess *xorm.Session) error {
 	if table != "org" {
 		return nil
@@ -145,10 +145,30 @@ func (db *SQLite3) IsUniqueConstraintViolation(err error) bool {
 	return db.isThisError(err, int(sqlite3.ErrConstraintUnique))
 }
 
+// MaxParameters returns the default limit of SQLite before 3.32, which builds may still be configured with
+// ForUpdateSQL returns the query as it is, SQLite locks the whole database rather than rows. A transaction writing
+// after another one did fails with SQLITE_BUSY instead of overwriting its changes, and is retried
+func (db *SQLite3) ForUpdateSQL(query string) string {
+	return query
+}
+
+func (db *SQLite3) MaxParameters() int {
+	return 999
+}
+
 func (db *SQLite3) IsDeadlock(err error) bool {
 	return false // No deadlock
 }
 
+// IsLockContention returns true when the database, or a table, is locked by another connection
+func (db *SQLite3) IsLockContention(err error) bool {
+	var driverErr sqlite3.Error
+	if errors.As(err, &driverErr) {
+		return driverErr.Code == sqlite3.ErrBusy || driverErr.Code == sqlite3.ErrLocked
+	}
+	return false
+}
+
 // UpsertSQL returns the upsert sql statement for SQLite dialect
 func (db *SQLite3) UpsertSQL(tableName string, keyCols, updateCols []string) string {
 	str, _ := db.UpsertMultipleSQL(tableName, keyCols, updateCols, 1)
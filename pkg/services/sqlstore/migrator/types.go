@@ -8,10 +8,11 @@ import (
 )
 
 const (
-	Postgres = "postgres"
-	SQLite   = "sqlite3"
-	MySQL    = "mysql"
-	MSSQL    = "mssql"
+	Postgres    = "postgres"
+	SQLite      = "sqlite3"
+	MySQL       = "mysql"
+	MSSQL       = "mssql"
+	CockroachDB = "cockroachdb"
 )
 
 type Migration interface {
@@ -25,6 +26,13 @@ type Migration interface {
 	SkipMigrationLog() bool
 }
 
+// NonTransactionalMigration is implemented by the migrations which can't run in a transaction, such as the ones creating
+// indexes concurrently on Postgres. A failure doesn't roll back their changes, so they must be able to run again
+type NonTransactionalMigration interface {
+	Migration
+	NonTransactional() bool
+}
+
 type CodeMigration interface {
 	Migration
 	Exec(sess *xorm.Session, migrator *Migrator) error
@@ -29,6 +29,7 @@ type SQLStoreMock struct {
 	ExpectedNotifierUsageStats     []*models.NotifierUsageStats
 	ExpectedSignedInUser           *user.SignedInUser
 	ExpectedLoginAttempts          int64
+	ExpectedPoolStats              sqlstore.PoolStats
 
 	ExpectedError error
 }
@@ -98,27 +99,19 @@ func (m *SQLStoreMock) WithNewDbSession(ctx context.Context, callback sqlstore.D
 	return m.ExpectedError
 }
 
-func (m *SQLStoreMock) GetOrgQuotaByTarget(ctx context.Context, query *models.GetOrgQuotaByTargetQuery) error {
-	return m.ExpectedError
-}
-
-func (m *SQLStoreMock) GetOrgQuotas(ctx context.Context, query *models.GetOrgQuotasQuery) error {
-	return m.ExpectedError
-}
-
-func (m *SQLStoreMock) UpdateOrgQuota(ctx context.Context, cmd *models.UpdateOrgQuotaCmd) error {
+func (m *SQLStoreMock) WithReplicaSession(ctx context.Context, callback sqlstore.DBTransactionFunc) error {
 	return m.ExpectedError
 }
 
-func (m *SQLStoreMock) GetUserQuotaByTarget(ctx context.Context, query *models.GetUserQuotaByTargetQuery) error {
+func (m *SQLStoreMock) GetOrgQuotaByTarget(ctx context.Context, query *models.GetOrgQuotaByTargetQuery) error {
 	return m.ExpectedError
 }
 
-func (m *SQLStoreMock) GetUserQuotas(ctx context.Context, query *models.GetUserQuotasQuery) error {
+func (m *SQLStoreMock) GetOrgQuotas(ctx context.Context, query *models.GetOrgQuotasQuery) error {
 	return m.ExpectedError
 }
 
-func (m *SQLStoreMock) UpdateUserQuota(ctx context.Context, cmd *models.UpdateUserQuotaCmd) error {
+func (m *SQLStoreMock) UpdateOrgQuota(ctx context.Context, cmd *models.UpdateOrgQuotaCmd) error {
 	return m.ExpectedError
 }
 
@@ -158,6 +151,16 @@ func (m *SQLStoreMock) GetSqlxSession() *session.SessionDB {
 	return nil
 }
 
+func (m *SQLStoreMock) GetPoolStats() sqlstore.PoolStats {
+	return m.ExpectedPoolStats
+}
+
+func (m *SQLStoreMock) RegisterPartitionedTable(table sqlstore.PartitionedTable) {}
+
+func (m *SQLStoreMock) RotatePartitions(ctx context.Context) error {
+	return m.ExpectedError
+}
+
 func (m *SQLStoreMock) CreateLoginAttempt(ctx context.Context, cmd *models.CreateLoginAttemptCommand) error {
 	m.LastLoginAttemptCommand = cmd
 	return m.ExpectedError
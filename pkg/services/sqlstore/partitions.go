@@ -0,0 +1,108 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// PartitionedTable is a table created by a migrator.AddPartitionedTableMigration, whose partitions are created ahead
+// of time and dropped once expired by RotatePartitions
+type PartitionedTable struct {
+	Name         string
+	Partitioning migrator.TimePartitioning
+	// Premake is how many partitions are created after the current one, so the rows never land in the catch-all one
+	Premake int
+	// Retention is how long the rows are kept, their partition is dropped after it. 0 keeps them
+	Retention time.Duration
+}
+
+// RegisterPartitionedTable registers a table whose partitions are rotated by RotatePartitions
+func (ss *SQLStore) RegisterPartitionedTable(table PartitionedTable) {
+	ss.partitionsMu.Lock()
+	defer ss.partitionsMu.Unlock()
+	ss.partitionedTables = append(ss.partitionedTables, table)
+}
+
+// RotatePartitions creates the partitions of the registered tables for the current and the next intervals, and drops
+// the expired ones. The databases which don't partition tables delete the expired rows instead.
+func (ss *SQLStore) RotatePartitions(ctx context.Context) error {
+	ss.partitionsMu.Lock()
+	tables := append([]PartitionedTable{}, ss.partitionedTables...)
+	ss.partitionsMu.Unlock()
+
+	var lastErr error
+	for _, table := range tables {
+		if err := ss.rotatePartitions(ctx, table, time.Now()); err != nil {
+			ss.log.Error("Failed to rotate the partitions of a table", "table", table.Name, "error", err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+func (ss *SQLStore) rotatePartitions(ctx context.Context, table PartitionedTable, now time.Time) error {
+	partitioning := table.Partitioning
+	query, args := ss.Dialect.PartitionsSQL(table.Name)
+	if query == "" {
+		return ss.deleteExpiredRows(ctx, table, now)
+	}
+
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		var names []string
+		if err := sess.SQL(query, args...).Find(&names); err != nil {
+			return fmt.Errorf("failed to list partitions: %w", err)
+		}
+		existing := make(map[string]bool, len(names))
+		for _, name := range names {
+			existing[name] = true
+		}
+
+		// the partitions are created in increasing order, as MySQL only splits new ones from the last one
+		for i := 0; i <= table.Premake; i++ {
+			partition := partitioning.PartitionAt(table.Name, now.Add(time.Duration(i)*partitioning.Interval))
+			if existing[partition.Name] {
+				continue
+			}
+			if _, err := sess.Exec(ss.Dialect.AddPartitionSQL(table.Name, partition)); err != nil {
+				return fmt.Errorf("failed to create partition %s: %w", partition.Name, err)
+			}
+			ss.log.Info("Created table partition", "table", table.Name, "partition", partition.Name)
+		}
+
+		if table.Retention <= 0 {
+			return nil
+		}
+		for _, name := range names {
+			start, ok := partitioning.PartitionStart(table.Name, name)
+			if !ok || start.Add(partitioning.Interval).After(now.Add(-table.Retention)) {
+				continue
+			}
+			if _, err := sess.Exec(ss.Dialect.DropPartitionSQL(table.Name, name)); err != nil {
+				return fmt.Errorf("failed to drop partition %s: %w", name, err)
+			}
+			ss.log.Info("Dropped expired table partition", "table", table.Name, "partition", name)
+		}
+		return nil
+	})
+}
+
+// deleteExpiredRows deletes the rows of a table which isn't partitioned once they expire
+func (ss *SQLStore) deleteExpiredRows(ctx context.Context, table PartitionedTable, now time.Time) error {
+	if table.Retention <= 0 {
+		return nil
+	}
+	cutoff := now.Add(-table.Retention).UnixNano() / int64(table.Partitioning.Unit)
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		res, err := sess.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s < ?", ss.Dialect.Quote(table.Name), ss.Dialect.Quote(table.Partitioning.Column)), cutoff)
+		if err != nil {
+			return fmt.Errorf("failed to delete expired rows: %w", err)
+		}
+		if deleted, err := res.RowsAffected(); err == nil && deleted > 0 {
+			ss.log.Info("Deleted expired rows", "table", table.Name, "rows", deleted)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,69 @@
+package sqlstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+type partitionTestRow struct {
+	Id      int64
+	Created int64
+}
+
+func TestIntegrationRotatePartitions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := InitTestDB(t)
+	ctx := context.Background()
+
+	table := PartitionedTable{
+		Name:         "partition_test_row",
+		Partitioning: migrator.TimePartitioning{Column: "created", Unit: time.Second, Interval: 24 * time.Hour},
+		Premake:      2,
+		Retention:    30 * 24 * time.Hour,
+	}
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		for _, statement := range ss.Dialect.CreatePartitionedTableSQL(&migrator.Table{
+			Name: table.Name,
+			Columns: []*migrator.Column{
+				{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+				{Name: "created", Type: migrator.DB_BigInt, Nullable: false},
+			},
+			PrimaryKeys: []string{"id"},
+		}, "created") {
+			if _, err := sess.Exec(statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ss.engine.DropTables(table.Name) })
+
+	now := time.Now()
+	expired := now.Add(-40 * 24 * time.Hour)
+	// the partition of the expired rows is created when they were recent
+	require.NoError(t, ss.rotatePartitions(ctx, PartitionedTable{Name: table.Name, Partitioning: table.Partitioning}, expired))
+	ss.RegisterPartitionedTable(table)
+	require.NoError(t, ss.RotatePartitions(ctx))
+
+	_, err = ss.engine.Insert(
+		&partitionTestRow{Created: expired.Unix()},
+		&partitionTestRow{Created: now.Unix()},
+		&partitionTestRow{Created: now.Add(24 * time.Hour).Unix()},
+	)
+	require.NoError(t, err)
+
+	// the expired rows are dropped with their partition, or deleted when the database doesn't partition tables
+	require.NoError(t, ss.RotatePartitions(ctx))
+	var rows []partitionTestRow
+	require.NoError(t, ss.engine.OrderBy("created").Find(&rows))
+	require.Len(t, rows, 2)
+	require.Equal(t, now.Unix(), rows[0].Created)
+}
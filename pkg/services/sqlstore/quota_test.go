@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/require"
@@ -16,7 +17,6 @@ func TestIntegrationQuotaCommandsAndQueries(t *testing.T) {
 		t.Skip("skipping integration test")
 	}
 	sqlStore := InitTestDB(t)
-	userId := int64(1)
 	orgId := int64(0)
 
 	setting.Quota = setting.QuotaSettings{
@@ -93,6 +93,26 @@ func TestIntegrationQuotaCommandsAndQueries(t *testing.T) {
 			require.Equal(t, int64(1), query.Result.Used)
 		})
 
+		t.Run("Should not count soft deleted users in the used org quota", func(t *testing.T) {
+			deletedCmd := createUserCmd
+			deletedCmd.Login = "deleted_quota_user"
+			deleted, err := sqlStore.CreateUser(context.Background(), deletedCmd)
+			require.NoError(t, err)
+			err = sqlStore.AddOrgUser(context.Background(), &models.AddOrgUserCommand{OrgId: orgId, UserId: deleted.ID, Role: org.RoleViewer})
+			require.NoError(t, err)
+			err = sqlStore.WithDbSession(context.Background(), func(sess *DBSession) error {
+				_, err := sess.Exec("UPDATE "+sqlStore.Dialect.Quote("user")+" SET deleted_at = ? WHERE id = ?", time.Now(), deleted.ID)
+				return err
+			})
+			require.NoError(t, err)
+
+			query := models.GetOrgQuotaByTargetQuery{OrgId: orgId, Target: "org_user", Default: 11}
+			err = sqlStore.GetOrgQuotaByTarget(context.Background(), &query)
+
+			require.NoError(t, err)
+			require.Equal(t, int64(1), query.Result.Used)
+		})
+
 		t.Run("Should be able to get used org quota when no rows exist", func(t *testing.T) {
 			query := models.GetOrgQuotaByTargetQuery{OrgId: 2, Target: "org_user", Default: 11}
 			err = sqlStore.GetOrgQuotaByTarget(context.Background(), &query)
@@ -147,58 +167,6 @@ func TestIntegrationQuotaCommandsAndQueries(t *testing.T) {
 		})
 	})
 
-	t.Run("Given saved user quota for org", func(t *testing.T) {
-		userQuotaCmd := models.UpdateUserQuotaCmd{
-			UserId: userId,
-			Target: "org_user",
-			Limit:  10,
-		}
-		err := sqlStore.UpdateUserQuota(context.Background(), &userQuotaCmd)
-		require.NoError(t, err)
-
-		t.Run("Should be able to get saved quota by user id and target", func(t *testing.T) {
-			query := models.GetUserQuotaByTargetQuery{UserId: userId, Target: "org_user", Default: 1}
-			err = sqlStore.GetUserQuotaByTarget(context.Background(), &query)
-
-			require.NoError(t, err)
-			require.Equal(t, int64(10), query.Result.Limit)
-		})
-
-		t.Run("Should be able to get default quota by user id and target", func(t *testing.T) {
-			query := models.GetUserQuotaByTargetQuery{UserId: 9, Target: "org_user", Default: 11}
-			err = sqlStore.GetUserQuotaByTarget(context.Background(), &query)
-
-			require.NoError(t, err)
-			require.Equal(t, int64(11), query.Result.Limit)
-		})
-
-		t.Run("Should be able to get used user quota when rows exist", func(t *testing.T) {
-			query := models.GetUserQuotaByTargetQuery{UserId: userId, Target: "org_user", Default: 11}
-			err = sqlStore.GetUserQuotaByTarget(context.Background(), &query)
-
-			require.NoError(t, err)
-			require.Equal(t, int64(1), query.Result.Used)
-		})
-
-		t.Run("Should be able to get used user quota when no rows exist", func(t *testing.T) {
-			query := models.GetUserQuotaByTargetQuery{UserId: 2, Target: "org_user", Default: 11}
-			err = sqlStore.GetUserQuotaByTarget(context.Background(), &query)
-
-			require.NoError(t, err)
-			require.Equal(t, int64(0), query.Result.Used)
-		})
-
-		t.Run("Should be able to quota list for user", func(t *testing.T) {
-			query := models.GetUserQuotasQuery{UserId: userId}
-			err = sqlStore.GetUserQuotas(context.Background(), &query)
-
-			require.NoError(t, err)
-			require.Len(t, query.Result, 1)
-			require.Equal(t, int64(10), query.Result[0].Limit)
-			require.Equal(t, int64(1), query.Result[0].Used)
-		})
-	})
-
 	t.Run("Should be able to global user quota", func(t *testing.T) {
 		query := models.GetGlobalQuotaByTargetQuery{Target: "user", Default: 5}
 		err = sqlStore.GetGlobalQuotaByTarget(context.Background(), &query)
@@ -266,36 +234,4 @@ func TestIntegrationQuotaCommandsAndQueries(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, int64(10), query.Result.Limit)
 	})
-
-	// related: https://github.com/grafana/grafana/issues/14342
-	t.Run("Should user quota updating is successful even if it called multiple time", func(t *testing.T) {
-		userQuotaCmd := models.UpdateUserQuotaCmd{
-			UserId: userId,
-			Target: "org_user",
-			Limit:  5,
-		}
-		err := sqlStore.UpdateUserQuota(context.Background(), &userQuotaCmd)
-		require.NoError(t, err)
-
-		query := models.GetUserQuotaByTargetQuery{UserId: userId, Target: "org_user", Default: 1}
-		err = sqlStore.GetUserQuotaByTarget(context.Background(), &query)
-		require.NoError(t, err)
-		require.Equal(t, int64(5), query.Result.Limit)
-
-		// XXX: resolution of `Updated` column is 1sec, so this makes delay
-		time.Sleep(1 * time.Second)
-
-		userQuotaCmd = models.UpdateUserQuotaCmd{
-			UserId: userId,
-			Target: "org_user",
-			Limit:  10,
-		}
-		err = sqlStore.UpdateUserQuota(context.Background(), &userQuotaCmd)
-		require.NoError(t, err)
-
-		query = models.GetUserQuotaByTargetQuery{UserId: userId, Target: "org_user", Default: 1}
-		err = sqlStore.GetUserQuotaByTarget(context.Background(), &query)
-		require.NoError(t, err)
-		require.Equal(t, int64(10), query.Result.Limit)
-	})
 }
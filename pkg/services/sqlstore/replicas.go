@@ -0,0 +1,115 @@
+package sqlstore
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"xorm.io/xorm"
+)
+
+// replicaPingTimeout bounds the health checks of the replicas, so an unreachable replica doesn't hold up the queries
+// falling back to the primary
+const replicaPingTimeout = 2 * time.Second
+
+// replica is a read-only replica of the database. Its health is checked at most once per interval
+type replica struct {
+	engine *xorm.Engine
+
+	mu        sync.Mutex
+	healthy   bool
+	checkedAt time.Time
+}
+
+// isHealthy returns true when the replica answered its last health check, checking it again when the check is older
+// than interval
+func (r *replica) isHealthy(ctx context.Context, interval time.Duration) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.checkedAt.IsZero() && time.Since(r.checkedAt) < interval {
+		return r.healthy
+	}
+	return r.check(ctx)
+}
+
+// check pings the replica and records whether it answered. r.mu must be held
+func (r *replica) check(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(ctx, replicaPingTimeout)
+	defer cancel()
+	r.healthy = r.engine.PingContext(ctx) == nil
+	r.checkedAt = time.Now()
+	return r.healthy
+}
+
+// recheck checks the replica again after a query failed on it, so the next queries fall back to the primary at once
+// if it doesn't answer anymore
+func (r *replica) recheck(ctx context.Context) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.check(ctx)
+}
+
+// WithReplicaSession calls the callback with a session of a read-only replica of the database, so read-heavy queries
+// don't load the primary. The replicas are used in turn, skipping the unhealthy ones, and the callback runs on the
+// primary when no replica is configured or healthy. It also runs on the primary when the context has a session, as the
+// rows written by its transaction are not on the replicas. Replicas can lag behind the primary, so callbacks must only
+// read rows which don't need to be up to date.
+func (ss *SQLStore) WithReplicaSession(ctx context.Context, callback DBTransactionFunc) error {
+	if _, ok := ctx.Value(ContextSessionKey{}).(*DBSession); ok {
+		return ss.WithDbSession(ctx, callback)
+	}
+	r := ss.healthyReplica(ctx)
+	if r == nil {
+		return ss.WithDbSession(ctx, callback)
+	}
+
+	sess := &DBSession{Session: r.engine.NewSession().Context(ctx), engine: r.engine, ctx: ctx}
+	defer sess.Close()
+	err := ss.withRetry(ctx, callback, 0)(sess)
+	if err != nil && ctx.Err() == nil && !r.recheck(ctx) {
+		ss.log.Warn("Database replica is unhealthy, the next queries run on the primary", "error", err)
+	}
+	return err
+}
+
+// healthyReplica returns the next healthy replica, or nil when none is
+func (ss *SQLStore) healthyReplica(ctx context.Context) *replica {
+	interval := time.Duration(ss.dbCfg.ReplicaHealthCheckInterval) * time.Second
+	for range ss.replicas {
+		next := atomic.AddUint64(&ss.nextReplica, 1)
+		r := ss.replicas[next%uint64(len(ss.replicas))]
+		if r.isHealthy(ctx, interval) {
+			return r
+		}
+	}
+	return nil
+}
+
+// initReplicas connects to the replicas of the database, which are configured like the primary
+func (ss *SQLStore) initReplicas() error {
+	for _, connectionString := range ss.dbCfg.ReplicaConnectionStrings {
+		engine, err := xorm.NewEngine(ss.dbCfg.Type, connectionString)
+		if err != nil {
+			return err
+		}
+		ss.configureEngine(engine)
+		ss.replicas = append(ss.replicas, &replica{engine: engine})
+	}
+	if len(ss.replicas) > 0 {
+		sqlog.Info("Connecting to DB replicas", "count", len(ss.replicas))
+	}
+	return nil
+}
+
+// splitConnectionStrings splits a comma separated list of connection strings
+func splitConnectionStrings(value string) []string {
+	var connectionStrings []string
+	for _, connectionString := range strings.Split(value, ",") {
+		if connectionString = strings.TrimSpace(connectionString); connectionString != "" {
+			connectionStrings = append(connectionStrings, connectionString)
+		}
+	}
+	return connectionStrings
+}
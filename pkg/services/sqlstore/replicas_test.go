@@ -0,0 +1,86 @@
+package sqlstore
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+)
+
+func TestSplitConnectionStrings(t *testing.T) {
+	require.Nil(t, splitConnectionStrings(""))
+	require.Equal(t, []string{
+		"user=grafana host=replica1 dbname=grafana",
+		"user=grafana host=replica2 dbname=grafana",
+	}, splitConnectionStrings(" user=grafana host=replica1 dbname=grafana ,user=grafana host=replica2 dbname=grafana,"))
+}
+
+func TestIntegrationWithReplicaSession(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := InitTestDB(t)
+
+	// the replica has a table the primary doesn't have, so the queries tell which database they ran on
+	newReplica := func(t *testing.T) *replica {
+		engine, err := xorm.NewEngine("sqlite3", "file:"+filepath.Join(t.TempDir(), "replica.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = engine.Close() })
+		_, err = engine.Exec("CREATE TABLE replica_marker (id INTEGER)")
+		require.NoError(t, err)
+		return &replica{engine: engine}
+	}
+	ranOnReplica := func(t *testing.T, ctx context.Context) bool {
+		var onReplica bool
+		err := ss.WithReplicaSession(ctx, func(sess *DBSession) error {
+			_, err := sess.Exec("SELECT COUNT(*) FROM replica_marker")
+			onReplica = err == nil
+			return nil
+		})
+		require.NoError(t, err)
+		return onReplica
+	}
+	setReplicas := func(t *testing.T, replicas ...*replica) {
+		ss.replicas = replicas
+		t.Cleanup(func() { ss.replicas = nil })
+	}
+
+	t.Run("the primary is used without replicas", func(t *testing.T) {
+		require.False(t, ranOnReplica(t, context.Background()))
+	})
+
+	t.Run("healthy replicas are used", func(t *testing.T) {
+		setReplicas(t, newReplica(t))
+		require.True(t, ranOnReplica(t, context.Background()))
+	})
+
+	t.Run("unhealthy replicas are skipped", func(t *testing.T) {
+		down := newReplica(t)
+		require.NoError(t, down.engine.Close())
+		setReplicas(t, down, newReplica(t))
+
+		for i := 0; i < 4; i++ {
+			require.True(t, ranOnReplica(t, context.Background()))
+		}
+	})
+
+	t.Run("the primary is used when no replica is healthy", func(t *testing.T) {
+		down := newReplica(t)
+		require.NoError(t, down.engine.Close())
+		setReplicas(t, down)
+
+		require.False(t, ranOnReplica(t, context.Background()))
+	})
+
+	t.Run("the session of the context is used", func(t *testing.T) {
+		setReplicas(t, newReplica(t))
+
+		err := ss.InTransaction(context.Background(), func(ctx context.Context) error {
+			require.False(t, ranOnReplica(t, ctx))
+			return nil
+		})
+		require.NoError(t, err)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 // Builder defaults to returning a SQL query to get a list of all dashboards
@@ -20,8 +21,8 @@ type Builder struct {
 	sql    bytes.Buffer
 }
 
-// ToSQL builds the SQL query and returns it as a string, together with the SQL parameters.
-func (b *Builder) ToSQL(limit, page int64) (string, []interface{}) {
+// ToSQL builds the SQL query of the page and returns it as a string, together with the SQL parameters.
+func (b *Builder) ToSQL(page pagination.Page) (string, []interface{}) {
 	b.params = make([]interface{}, 0)
 	b.sql = bytes.Buffer{}
 
@@ -30,7 +31,7 @@ func (b *Builder) ToSQL(limit, page int64) (string, []interface{}) {
 	b.sql.WriteString("( ")
 	orderQuery := b.applyFilters()
 
-	b.sql.WriteString(b.Dialect.LimitOffset(limit, (page-1)*limit) + `) AS ids
+	b.sql.WriteString(b.Dialect.LimitOffset(page.PerPage, page.Offset()) + `) AS ids
 		INNER JOIN dashboard ON ids.id = dashboard.id`)
 	b.sql.WriteString("\n")
 
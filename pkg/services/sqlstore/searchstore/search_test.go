@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 const (
@@ -49,7 +50,7 @@ func TestBuilder_EqualResults_Basic(t *testing.T) {
 
 	res := []dashboards.DashboardSearchProjection{}
 	err := store.WithDbSession(context.Background(), func(sess *db.Session) error {
-		sql, params := builder.ToSQL(limit, page)
+		sql, params := builder.ToSQL(pagination.Page{Page: page, PerPage: limit})
 		return sess.SQL(sql, params...).Find(&res)
 	})
 	require.NoError(t, err)
@@ -88,18 +89,18 @@ func TestBuilder_Pagination(t *testing.T) {
 	resPg2 := []dashboards.DashboardSearchProjection{}
 	resPg3 := []dashboards.DashboardSearchProjection{}
 	err := store.WithDbSession(context.Background(), func(sess *db.Session) error {
-		sql, params := builder.ToSQL(15, 1)
+		sql, params := builder.ToSQL(pagination.Page{Page: 1, PerPage: 15})
 		err := sess.SQL(sql, params...).Find(&resPg1)
 		if err != nil {
 			return err
 		}
-		sql, params = builder.ToSQL(15, 2)
+		sql, params = builder.ToSQL(pagination.Page{Page: 2, PerPage: 15})
 		err = sess.SQL(sql, params...).Find(&resPg2)
 		if err != nil {
 			return err
 		}
 
-		sql, params = builder.ToSQL(15, 3)
+		sql, params = builder.ToSQL(pagination.Page{Page: 3, PerPage: 15})
 		return sess.SQL(sql, params...).Find(&resPg3)
 	})
 	require.NoError(t, err)
@@ -141,7 +142,7 @@ func TestBuilder_Permissions(t *testing.T) {
 
 	res := []dashboards.DashboardSearchProjection{}
 	err := store.WithDbSession(context.Background(), func(sess *db.Session) error {
-		sql, params := builder.ToSQL(limit, page)
+		sql, params := builder.ToSQL(pagination.Page{Page: page, PerPage: limit})
 		return sess.SQL(sql, params...).Find(&res)
 	})
 	require.NoError(t, err)
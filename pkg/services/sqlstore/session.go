@@ -3,6 +3,7 @@ package sqlstore
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
 	"time"
 
@@ -14,10 +15,21 @@ import (
 
 var sessionLogger = log.New("sqlstore.session")
 
+// the waits before retrying after the database was locked, see retryBackoff
+const (
+	minRetryBackoff = 10 * time.Millisecond
+	maxRetryBackoff = time.Second
+)
+
 type DBSession struct {
 	*xorm.Session
+	engine          *xorm.Engine
 	transactionOpen bool
 	events          []interface{}
+	// savepoints is the number of savepoints of the transaction the session is in
+	savepoints int
+	// ctx is the context of the caller the session runs for, the sessions it starts run with it
+	ctx context.Context
 }
 
 type DBTransactionFunc func(sess *DBSession) error
@@ -30,6 +42,49 @@ func (sess *DBSession) PublishAfterCommit(msg interface{}) {
 	sess.events = append(sess.events, msg)
 }
 
+// ForUpdate locks the rows read by the next query of the session until the end of its transaction, so concurrent
+// transactions reading them to update them wait for it rather than racing. It applies to the queries built by the
+// session, raw queries use dialect.ForUpdateSQL. SQLite locks the whole database instead of rows: the first transaction
+// to write wins, and the others fail with SQLITE_BUSY and are retried, so ForUpdate must be used within
+// InTransaction or WithTransactionalDbSession.
+func (sess *DBSession) ForUpdate() *xorm.Session {
+	if !sess.transactionOpen {
+		sessionLogger.Warn("Locking rows outside of a transaction has no effect")
+	}
+	return sess.Session.ForUpdate()
+}
+
+// WithSavepoint calls the callback within a savepoint of the transaction of the session, so a failure of the callback
+// only rolls back its own changes, and the events it published, and the transaction can go on. Without a transaction,
+// or with a database which doesn't support savepoints, the callback just runs on the session, and its failure may leave
+// the transaction unusable, such as on Postgres where a failed statement aborts the transaction.
+func (sess *DBSession) WithSavepoint(callback DBTransactionFunc) error {
+	if !sess.transactionOpen {
+		return callback(sess)
+	}
+	create, rollbackTo, release := dialect.SavepointSQL(fmt.Sprintf("grafana_savepoint_%d", sess.savepoints+1))
+	if create == "" {
+		return callback(sess)
+	}
+
+	if _, err := sess.Exec(create); err != nil {
+		return err
+	}
+	sess.savepoints++
+	defer func() { sess.savepoints-- }()
+
+	events := len(sess.events)
+	if err := callback(sess); err != nil {
+		if _, rollErr := sess.Exec(rollbackTo); rollErr != nil {
+			return fmt.Errorf("rolling back to savepoint due to error failed: %s: %w", rollErr, err)
+		}
+		sess.events = sess.events[:events]
+		return err
+	}
+	_, err := sess.Exec(release)
+	return err
+}
+
 func startSessionOrUseExisting(ctx context.Context, engine *xorm.Engine, beginTran bool) (*DBSession, bool, error) {
 	value := ctx.Value(ContextSessionKey{})
 	var sess *DBSession
@@ -39,10 +94,11 @@ func startSessionOrUseExisting(ctx context.Context, engine *xorm.Engine, beginTr
 		ctxLogger := sessionLogger.FromContext(ctx)
 		ctxLogger.Debug("reusing existing session", "transaction", sess.transactionOpen)
 		sess.Session = sess.Session.Context(ctx)
+		sess.ctx = ctx
 		return sess, false, nil
 	}
 
-	newSess := &DBSession{Session: engine.NewSession(), transactionOpen: beginTran}
+	newSess := &DBSession{Session: engine.NewSession(), engine: engine, transactionOpen: beginTran}
 	if beginTran {
 		err := newSess.Begin()
 		if err != nil {
@@ -51,6 +107,7 @@ func startSessionOrUseExisting(ctx context.Context, engine *xorm.Engine, beginTr
 	}
 
 	newSess.Session = newSess.Session.Context(ctx)
+	newSess.ctx = ctx
 
 	return newSess, true, nil
 }
@@ -58,15 +115,15 @@ func startSessionOrUseExisting(ctx context.Context, engine *xorm.Engine, beginTr
 // WithDbSession calls the callback with the session in the context (if exists).
 // Otherwise it creates a new one that is closed upon completion.
 // A session is stored in the context if sqlstore.InTransaction() has been been previously called with the same context (and it's not committed/rolledback yet).
-// In case of sqlite3.ErrLocked or sqlite3.ErrBusy failure it will be retried at most five times before giving up.
+// In case of sqlite3.ErrLocked or sqlite3.ErrBusy failure it will be retried at most query_retries times before giving up.
 func (ss *SQLStore) WithDbSession(ctx context.Context, callback DBTransactionFunc) error {
 	return ss.withDbSession(ctx, ss.engine, callback)
 }
 
 // WithNewDbSession calls the callback with a new session that is closed upon completion.
-// In case of sqlite3.ErrLocked or sqlite3.ErrBusy failure it will be retried at most five times before giving up.
+// In case of sqlite3.ErrLocked or sqlite3.ErrBusy failure it will be retried at most query_retries times before giving up.
 func (ss *SQLStore) WithNewDbSession(ctx context.Context, callback DBTransactionFunc) error {
-	sess := &DBSession{Session: ss.engine.NewSession(), transactionOpen: false}
+	sess := &DBSession{Session: ss.engine.NewSession(), engine: ss.engine, transactionOpen: false, ctx: ctx}
 	defer sess.Close()
 	return ss.withRetry(ctx, callback, 0)(sess)
 }
@@ -79,7 +136,7 @@ func (ss *SQLStore) withRetry(ctx context.Context, callback DBTransactionFunc, r
 
 		var sqlError sqlite3.Error
 		if errors.As(err, &sqlError) && retry < ss.dbCfg.QueryRetries && (sqlError.Code == sqlite3.ErrLocked || sqlError.Code == sqlite3.ErrBusy) {
-			time.Sleep(time.Millisecond * time.Duration(10))
+			time.Sleep(retryBackoff(retry))
 			ctxLogger.Info("Database locked, sleeping then retrying", "error", err, "retry", retry, "code", sqlError.Code)
 			return ss.withRetry(ctx, callback, retry+1)(sess)
 		}
@@ -88,6 +145,19 @@ func (ss *SQLStore) withRetry(ctx context.Context, callback DBTransactionFunc, r
 	}
 }
 
+// retryBackoff returns how long to wait before a retry after the database was locked. It doubles at each retry so
+// concurrent writers don't keep colliding
+func retryBackoff(retry int) time.Duration {
+	backoff := maxRetryBackoff
+	if retry < 7 {
+		backoff = minRetryBackoff << retry
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	return backoff
+}
+
 func (ss *SQLStore) withDbSession(ctx context.Context, engine *xorm.Engine, callback DBTransactionFunc) error {
 	sess, isNew, err := startSessionOrUseExisting(ctx, engine, false)
 	if err != nil {
@@ -0,0 +1,101 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// slowQueryLogger satisfies the sqlhooks.Hooks interface and logs the queries which take longer than a threshold. The
+// values of their arguments are redacted, as they can be secrets or personal data, only their types are logged
+type slowQueryLogger struct {
+	log        log.Logger
+	threshold  time.Duration
+	sampleRate float64
+	// sample returns a random number in [0, 1), compared to the sample rate
+	sample func() float64
+}
+
+// slowQueryStartKey is used as key to save the start of a query in `context.Context`
+type slowQueryStartKey struct{}
+
+func newSlowQueryLogger(threshold time.Duration, sampleRate float64) *slowQueryLogger {
+	return &slowQueryLogger{
+		log:        log.New("sqlstore.slowquery"),
+		threshold:  threshold,
+		sampleRate: sampleRate,
+		sample:     rand.Float64,
+	}
+}
+
+// Before returns the context with the start of the query
+func (l *slowQueryLogger) Before(ctx context.Context, query string, args ...interface{}) (context.Context, error) {
+	return context.WithValue(ctx, slowQueryStartKey{}, time.Now()), nil
+}
+
+// After logs the query if it was slow
+func (l *slowQueryLogger) After(ctx context.Context, query string, args ...interface{}) (context.Context, error) {
+	l.logIfSlow(ctx, query, args, nil)
+	return ctx, nil
+}
+
+// OnError logs the query if it was slow before failing
+func (l *slowQueryLogger) OnError(ctx context.Context, err error, query string, args ...interface{}) error {
+	// the driver doesn't implement an optional interface, the query is run again another way
+	if errors.Is(err, driver.ErrSkip) {
+		return nil
+	}
+	l.logIfSlow(ctx, query, args, err)
+	return err
+}
+
+func (l *slowQueryLogger) logIfSlow(ctx context.Context, query string, args []interface{}, err error) {
+	start, ok := ctx.Value(slowQueryStartKey{}).(time.Time)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(start)
+	if !l.shouldLog(elapsed) {
+		return
+	}
+
+	ctxLogger := l.log.FromContext(ctx)
+	if err != nil {
+		ctxLogger.Warn("Slow query", "elapsed", elapsed, "threshold", l.threshold, "sql", query, "args", redactQueryArgs(args), "error", err)
+		return
+	}
+	ctxLogger.Warn("Slow query", "elapsed", elapsed, "threshold", l.threshold, "sql", query, "args", redactQueryArgs(args))
+}
+
+// shouldLog returns true when a query which took elapsed is slow, and sampled
+func (l *slowQueryLogger) shouldLog(elapsed time.Duration) bool {
+	if elapsed < l.threshold {
+		return false
+	}
+	return l.sampleRate >= 1 || l.sample() < l.sampleRate
+}
+
+// redactQueryArgs returns the types of the arguments of a query, and the length of the strings and byte slices, so the
+// logs tell how a query was called without exposing the values
+func redactQueryArgs(args []interface{}) string {
+	redacted := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch value := arg.(type) {
+		case nil:
+			redacted = append(redacted, "nil")
+		case string:
+			redacted = append(redacted, fmt.Sprintf("string(%d)", len(value)))
+		case []byte:
+			redacted = append(redacted, fmt.Sprintf("[]byte(%d)", len(value)))
+		default:
+			redacted = append(redacted, fmt.Sprintf("%T", value))
+		}
+	}
+	return "[" + strings.Join(redacted, " ") + "]"
+}
@@ -0,0 +1,29 @@
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlowQueryLogger(t *testing.T) {
+	t.Run("queries faster than the threshold are not logged", func(t *testing.T) {
+		l := newSlowQueryLogger(time.Second, 1)
+		require.False(t, l.shouldLog(999*time.Millisecond))
+		require.True(t, l.shouldLog(time.Second))
+	})
+
+	t.Run("slow queries are sampled", func(t *testing.T) {
+		l := newSlowQueryLogger(time.Second, 0.25)
+		l.sample = func() float64 { return 0.2 }
+		require.True(t, l.shouldLog(2*time.Second))
+		l.sample = func() float64 { return 0.3 }
+		require.False(t, l.shouldLog(2*time.Second))
+	})
+
+	t.Run("the values of the arguments are redacted", func(t *testing.T) {
+		args := []interface{}{"secret", []byte("token"), int64(42), true, nil, time.Now()}
+		require.Equal(t, "[string(6) []byte(5) int64 bool nil time.Time]", redactQueryArgs(args))
+	})
+}
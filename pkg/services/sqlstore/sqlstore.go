@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/dlmiddlecote/sqlstats"
+	"github.com/gchaincl/sqlhooks"
 	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
@@ -58,6 +59,10 @@ type SQLStore struct {
 	skipEnsureDefaultOrgAndUser bool
 	migrations                  registry.DatabaseMigrator
 	tracer                      tracing.Tracer
+	replicas                    []*replica
+	nextReplica                 uint64
+	partitionsMu                sync.Mutex
+	partitionedTables           []PartitionedTable
 }
 
 func ProvideService(cfg *setting.Cfg, cacheService *localcache.CacheService, migrations registry.DatabaseMigrator, bus bus.Bus, tracer tracing.Tracer) (*SQLStore, error) {
@@ -298,8 +303,12 @@ func (ss *SQLStore) buildConnectionString() (string, error) {
 		}
 
 		cnnstr += ss.buildExtraConnectionString('&')
-	case migrator.Postgres:
-		addr, err := util.SplitHostPortDefault(ss.dbCfg.Host, "127.0.0.1", "5432")
+	case migrator.Postgres, migrator.CockroachDB:
+		defaultPort := "5432"
+		if ss.dbCfg.Type == migrator.CockroachDB {
+			defaultPort = "26257"
+		}
+		addr, err := util.SplitHostPortDefault(ss.dbCfg.Host, "127.0.0.1", defaultPort)
 		if err != nil {
 			return "", fmt.Errorf("invalid host specifier '%s': %w", ss.dbCfg.Host, err)
 		}
@@ -325,6 +334,7 @@ func (ss *SQLStore) buildConnectionString() (string, error) {
 		}
 
 		cnnstr = fmt.Sprintf("file:%s?cache=%s&mode=rwc", ss.dbCfg.Path, ss.dbCfg.CacheMode)
+		cnnstr += ss.buildSQLitePragmas()
 		cnnstr += ss.buildExtraConnectionString('&')
 	default:
 		return "", fmt.Errorf("unknown database type: %s", ss.dbCfg.Type)
@@ -333,6 +343,28 @@ func (ss *SQLStore) buildConnectionString() (string, error) {
 	return cnnstr, nil
 }
 
+// buildSQLitePragmas returns the parameters of the SQLite connection string setting the pragmas of the configuration,
+// which the driver runs on every new connection
+func (ss *SQLStore) buildSQLitePragmas() string {
+	pragmas := fmt.Sprintf("&_busy_timeout=%d", ss.dbCfg.BusyTimeout)
+	if ss.dbCfg.JournalMode != "" {
+		pragmas += "&_journal_mode=" + ss.dbCfg.JournalMode
+	}
+	if ss.dbCfg.Synchronous != "" {
+		pragmas += "&_synchronous=" + ss.dbCfg.Synchronous
+	}
+	return pragmas
+}
+
+func isOneOf(value string, values ...string) bool {
+	for _, v := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
 // initEngine initializes ss.engine.
 func (ss *SQLStore) initEngine(engine *xorm.Engine) error {
 	if ss.engine != nil {
@@ -345,8 +377,15 @@ func (ss *SQLStore) initEngine(engine *xorm.Engine) error {
 		return err
 	}
 
+	var hooks []sqlhooks.Hooks
 	if ss.Cfg.IsFeatureToggleEnabled(featuremgmt.FlagDatabaseMetrics) {
-		ss.dbCfg.Type = WrapDatabaseDriverWithHooks(ss.dbCfg.Type, ss.tracer)
+		hooks = append(hooks, &databaseQueryWrapper{log: log.New("sqlstore.metrics"), tracer: ss.tracer})
+	}
+	if ss.dbCfg.SlowQueryThreshold > 0 {
+		hooks = append(hooks, newSlowQueryLogger(ss.dbCfg.SlowQueryThreshold, ss.dbCfg.SlowQuerySampleRate))
+	}
+	if len(hooks) > 0 {
+		ss.dbCfg.Type = wrapDatabaseDriver(ss.dbCfg.Type, hooks...)
 	}
 
 	sqlog.Info("Connecting to DB", "dbtype", ss.dbCfg.Type)
@@ -387,6 +426,13 @@ func (ss *SQLStore) initEngine(engine *xorm.Engine) error {
 		}
 	}
 
+	ss.configureEngine(engine)
+	ss.engine = engine
+	return ss.initReplicas()
+}
+
+// configureEngine configures the connections and the logging of an engine.
+func (ss *SQLStore) configureEngine(engine *xorm.Engine) {
 	engine.SetMaxOpenConns(ss.dbCfg.MaxOpenConn)
 	engine.SetMaxIdleConns(ss.dbCfg.MaxIdleConn)
 	engine.SetConnMaxLifetime(time.Second * time.Duration(ss.dbCfg.ConnMaxLifetime))
@@ -401,9 +447,6 @@ func (ss *SQLStore) initEngine(engine *xorm.Engine) error {
 		engine.ShowSQL(true)
 		engine.ShowExecTime(true)
 	}
-
-	ss.engine = engine
-	return nil
 }
 
 // readConfig initializes the SQLStore from its configuration.
@@ -458,6 +501,22 @@ func (ss *SQLStore) readConfig() error {
 
 	ss.dbCfg.QueryRetries = sec.Key("query_retries").MustInt()
 	ss.dbCfg.TransactionRetries = sec.Key("transaction_retries").MustInt(5)
+
+	ss.dbCfg.JournalMode = strings.ToUpper(sec.Key("journal_mode").String())
+	if ss.dbCfg.JournalMode != "" && !isOneOf(ss.dbCfg.JournalMode, "DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF") {
+		return fmt.Errorf("invalid database journal_mode %q", ss.dbCfg.JournalMode)
+	}
+	ss.dbCfg.Synchronous = strings.ToUpper(sec.Key("synchronous").String())
+	if ss.dbCfg.Synchronous != "" && !isOneOf(ss.dbCfg.Synchronous, "OFF", "NORMAL", "FULL", "EXTRA") {
+		return fmt.Errorf("invalid database synchronous %q", ss.dbCfg.Synchronous)
+	}
+	ss.dbCfg.BusyTimeout = sec.Key("busy_timeout").MustInt(5000)
+
+	ss.dbCfg.ReplicaConnectionStrings = splitConnectionStrings(sec.Key("replica_connection_strings").String())
+	ss.dbCfg.ReplicaHealthCheckInterval = sec.Key("replica_health_check_interval_sec").MustInt(10)
+
+	ss.dbCfg.SlowQueryThreshold = sec.Key("slow_query_threshold").MustDuration(0)
+	ss.dbCfg.SlowQuerySampleRate = sec.Key("slow_query_sample_rate").MustFloat64(1)
 	return nil
 }
 
@@ -560,6 +619,10 @@ func initTestDB(migration registry.DatabaseMigrator, opts ...InitTestDBOpt) (*SQ
 			if _, err := sec.NewKey("connection_string", sqlutil.PostgresTestDB().ConnStr); err != nil {
 				return nil, err
 			}
+		case "cockroachdb":
+			if _, err := sec.NewKey("connection_string", sqlutil.CockroachTestDB().ConnStr); err != nil {
+				return nil, err
+			}
 		default:
 			if _, err := sec.NewKey("connection_string", sqlutil.SQLite3TestDB().ConnStr); err != nil {
 				return nil, err
@@ -683,4 +746,18 @@ type DatabaseConfig struct {
 	QueryRetries int
 	// SQLite only
 	TransactionRetries int
+	// SQLite only, journal mode of the database, the default of SQLite when empty
+	JournalMode string
+	// SQLite only, milliseconds a query waits for a lock of the database before failing
+	BusyTimeout int
+	// SQLite only, synchronous mode of the connections, the default of SQLite when empty
+	Synchronous string
+	// Connection strings of the read-only replicas
+	ReplicaConnectionStrings []string
+	// Seconds between the health checks of a replica
+	ReplicaHealthCheckInterval int
+	// Queries taking longer are logged, 0 disables the logging
+	SlowQueryThreshold time.Duration
+	// Share of the slow queries which are logged, between 0 and 1
+	SlowQuerySampleRate float64
 }
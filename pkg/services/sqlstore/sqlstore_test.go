@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/require"
@@ -67,6 +68,12 @@ var sqlStoreTestCases = []sqlStoreTest{
 		dbHost:        "[::1]",
 		connStrValues: []string{"host=::1", "port=5432"},
 	},
+	{
+		name:          "CockroachDB (Default Port)",
+		dbType:        "cockroachdb",
+		dbHost:        "1.2.3.4",
+		connStrValues: []string{"host=1.2.3.4", "port=26257"},
+	},
 	{
 		name:  "Invalid database URL",
 		dbURL: "://invalid.com/",
@@ -122,3 +129,49 @@ func makeSQLStoreTestConfig(t *testing.T, dbType, host, dbURL string) *setting.C
 
 	return cfg
 }
+
+func TestSQLiteConnectionStringPragmas(t *testing.T) {
+	newStore := func(t *testing.T, keys map[string]string) *SQLStore {
+		cfg := makeSQLStoreTestConfig(t, "sqlite3", "", "")
+		cfg.DataPath = t.TempDir()
+		for key, value := range keys {
+			_, err := cfg.Raw.Section("database").NewKey(key, value)
+			require.NoError(t, err)
+		}
+		return &SQLStore{Cfg: cfg}
+	}
+
+	t.Run("the busy timeout is set by default", func(t *testing.T) {
+		connStr, err := newStore(t, nil).buildConnectionString()
+		require.NoError(t, err)
+		require.Contains(t, connStr, "&_busy_timeout=5000")
+		require.NotContains(t, connStr, "_journal_mode")
+		require.NotContains(t, connStr, "_synchronous")
+	})
+
+	t.Run("the pragmas of the configuration are set", func(t *testing.T) {
+		connStr, err := newStore(t, map[string]string{
+			"journal_mode": "wal",
+			"busy_timeout": "10000",
+			"synchronous":  "normal",
+		}).buildConnectionString()
+		require.NoError(t, err)
+		require.Contains(t, connStr, "&_busy_timeout=10000")
+		require.Contains(t, connStr, "&_journal_mode=WAL")
+		require.Contains(t, connStr, "&_synchronous=NORMAL")
+	})
+
+	t.Run("invalid pragmas are rejected", func(t *testing.T) {
+		_, err := newStore(t, map[string]string{"journal_mode": "fast"}).buildConnectionString()
+		require.Error(t, err)
+		_, err = newStore(t, map[string]string{"synchronous": "sometimes"}).buildConnectionString()
+		require.Error(t, err)
+	})
+}
+
+func TestRetryBackoff(t *testing.T) {
+	require.Equal(t, 10*time.Millisecond, retryBackoff(0))
+	require.Equal(t, 40*time.Millisecond, retryBackoff(2))
+	require.Equal(t, time.Second, retryBackoff(7))
+	require.Equal(t, time.Second, retryBackoff(100))
+}
@@ -52,6 +52,22 @@ func PostgresTestDB() TestDB {
 	}
 }
 
+func CockroachTestDB() TestDB {
+	host := os.Getenv("COCKROACH_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+	port := os.Getenv("COCKROACH_PORT")
+	if port == "" {
+		port = "26257"
+	}
+	connStr := fmt.Sprintf("user=root host=%s port=%s dbname=grafanatest sslmode=disable", host, port)
+	return TestDB{
+		DriverName: "cockroachdb",
+		ConnStr:    connStr,
+	}
+}
+
 func MSSQLTestDB() TestDB {
 	host := os.Getenv("MSSQL_HOST")
 	if host == "" {
@@ -14,7 +14,7 @@ const activeUserTimeLimit = time.Hour * 24 * 30
 const dailyActiveUserTimeLimit = time.Hour * 24
 
 func (ss *SQLStore) GetAlertNotifiersUsageStats(ctx context.Context, query *models.GetAlertNotifierUsageStatsQuery) error {
-	return ss.WithDbSession(ctx, func(dbSession *DBSession) error {
+	return ss.WithReplicaSession(ctx, func(dbSession *DBSession) error {
 		var rawSQL = `SELECT COUNT(*) AS count, type FROM ` + dialect.Quote("alert_notification") + ` GROUP BY type`
 		query.Result = make([]*models.NotifierUsageStats, 0)
 		err := dbSession.SQL(rawSQL).Find(&query.Result)
@@ -23,7 +23,7 @@ func (ss *SQLStore) GetAlertNotifiersUsageStats(ctx context.Context, query *mode
 }
 
 func (ss *SQLStore) GetDataSourceStats(ctx context.Context, query *models.GetDataSourceStatsQuery) error {
-	return ss.WithDbSession(ctx, func(dbSession *DBSession) error {
+	return ss.WithReplicaSession(ctx, func(dbSession *DBSession) error {
 		var rawSQL = `SELECT COUNT(*) AS count, type FROM ` + dialect.Quote("data_source") + ` GROUP BY type`
 		query.Result = make([]*models.DataSourceStats, 0)
 		err := dbSession.SQL(rawSQL).Find(&query.Result)
@@ -32,7 +32,7 @@ func (ss *SQLStore) GetDataSourceStats(ctx context.Context, query *models.GetDat
 }
 
 func (ss *SQLStore) GetDataSourceAccessStats(ctx context.Context, query *models.GetDataSourceAccessStatsQuery) error {
-	return ss.WithDbSession(ctx, func(dbSession *DBSession) error {
+	return ss.WithReplicaSession(ctx, func(dbSession *DBSession) error {
 		var rawSQL = `SELECT COUNT(*) AS count, type, access FROM ` + dialect.Quote("data_source") + ` GROUP BY type, access`
 		query.Result = make([]*models.DataSourceAccessStats, 0)
 		err := dbSession.SQL(rawSQL).Find(&query.Result)
@@ -45,11 +45,16 @@ func notServiceAccount(dialect migrator.Dialect) string {
 		dialect.BooleanStr(false)
 }
 
+// countedUser filters the users counted in the stats, which are neither service accounts nor soft deleted
+func countedUser(dialect migrator.Dialect) string {
+	return notServiceAccount(dialect) + ` AND deleted_at IS NULL`
+}
+
 func (ss *SQLStore) GetSystemStats(ctx context.Context, query *models.GetSystemStatsQuery) error {
-	return ss.WithDbSession(ctx, func(dbSession *DBSession) error {
+	return ss.WithReplicaSession(ctx, func(dbSession *DBSession) error {
 		sb := &SQLBuilder{}
 		sb.Write("SELECT ")
-		sb.Write(`(SELECT COUNT(*) FROM ` + dialect.Quote("user") + ` WHERE ` + notServiceAccount(dialect) + `) AS users,`)
+		sb.Write(`(SELECT COUNT(*) FROM ` + dialect.Quote("user") + ` WHERE ` + countedUser(dialect) + `) AS users,`)
 		sb.Write(`(SELECT COUNT(*) FROM ` + dialect.Quote("org") + `) AS orgs,`)
 		sb.Write(`(SELECT COUNT(*) FROM ` + dialect.Quote("data_source") + `) AS datasources,`)
 		sb.Write(`(SELECT COUNT(*) FROM ` + dialect.Quote("star") + `) AS stars,`)
@@ -59,15 +64,15 @@ func (ss *SQLStore) GetSystemStats(ctx context.Context, query *models.GetSystemS
 		now := time.Now()
 		activeUserDeadlineDate := now.Add(-activeUserTimeLimit)
 		sb.Write(`(SELECT COUNT(*) FROM `+dialect.Quote("user")+` WHERE `+
-			notServiceAccount(dialect)+` AND last_seen_at > ?) AS active_users,`, activeUserDeadlineDate)
+			countedUser(dialect)+` AND last_seen_at > ?) AS active_users,`, activeUserDeadlineDate)
 
 		dailyActiveUserDeadlineDate := now.Add(-dailyActiveUserTimeLimit)
 		sb.Write(`(SELECT COUNT(*) FROM `+dialect.Quote("user")+` WHERE `+
-			notServiceAccount(dialect)+` AND last_seen_at > ?) AS daily_active_users,`, dailyActiveUserDeadlineDate)
+			countedUser(dialect)+` AND last_seen_at > ?) AS daily_active_users,`, dailyActiveUserDeadlineDate)
 
 		monthlyActiveUserDeadlineDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 		sb.Write(`(SELECT COUNT(*) FROM `+dialect.Quote("user")+` WHERE `+
-			notServiceAccount(dialect)+` AND last_seen_at > ?) AS monthly_active_users,`, monthlyActiveUserDeadlineDate)
+			countedUser(dialect)+` AND last_seen_at > ?) AS monthly_active_users,`, monthlyActiveUserDeadlineDate)
 
 		sb.Write(`(SELECT COUNT(id) FROM `+dialect.Quote("dashboard")+` WHERE is_folder = ?) AS dashboards,`, dialect.BooleanStr(false))
 		sb.Write(`(SELECT COUNT(id) FROM `+dialect.Quote("dashboard")+` WHERE is_folder = ?) AS folders,`, dialect.BooleanStr(true))
@@ -155,7 +160,7 @@ func viewersPermissionsCounterSQL(statName string, isFolder bool, permission mod
 }
 
 func (ss *SQLStore) GetAdminStats(ctx context.Context, query *models.GetAdminStatsQuery) error {
-	return ss.WithDbSession(ctx, func(dbSession *DBSession) error {
+	return ss.WithReplicaSession(ctx, func(dbSession *DBSession) error {
 		now := time.Now()
 		activeEndDate := now.Add(-activeUserTimeLimit)
 		dailyActiveEndDate := now.Add(-dailyActiveUserTimeLimit)
@@ -196,19 +201,19 @@ func (ss *SQLStore) GetAdminStats(ctx context.Context, query *models.GetAdminSta
 		) AS alerts,
 		(
 			SELECT COUNT(*)
-			FROM ` + dialect.Quote("user") + ` WHERE ` + notServiceAccount(dialect) + `
+			FROM ` + dialect.Quote("user") + ` WHERE ` + countedUser(dialect) + `
 		) AS users,
 		(
 			SELECT COUNT(*)
-			FROM ` + dialect.Quote("user") + ` WHERE ` + notServiceAccount(dialect) + ` AND last_seen_at > ?
+			FROM ` + dialect.Quote("user") + ` WHERE ` + countedUser(dialect) + ` AND last_seen_at > ?
 		) AS active_users,
 		(
 			SELECT COUNT(*)
-			FROM ` + dialect.Quote("user") + ` WHERE ` + notServiceAccount(dialect) + ` AND last_seen_at > ?
+			FROM ` + dialect.Quote("user") + ` WHERE ` + countedUser(dialect) + ` AND last_seen_at > ?
 		) AS daily_active_users,
 		(
 			SELECT COUNT(*)
-			FROM ` + dialect.Quote("user") + ` WHERE ` + notServiceAccount(dialect) + ` AND last_seen_at > ?
+			FROM ` + dialect.Quote("user") + ` WHERE ` + countedUser(dialect) + ` AND last_seen_at > ?
 		) AS monthly_active_users,
 		` + ss.roleCounterSQL(ctx) + `,
 		(
@@ -232,8 +237,8 @@ func (ss *SQLStore) GetAdminStats(ctx context.Context, query *models.GetAdminSta
 }
 
 func (ss *SQLStore) GetSystemUserCountStats(ctx context.Context, query *models.GetSystemUserCountStatsQuery) error {
-	return ss.WithDbSession(ctx, func(sess *DBSession) error {
-		var rawSQL = `SELECT COUNT(id) AS Count FROM ` + dialect.Quote("user")
+	return ss.WithReplicaSession(ctx, func(sess *DBSession) error {
+		var rawSQL = `SELECT COUNT(id) AS Count FROM ` + dialect.Quote("user") + ` WHERE deleted_at IS NULL`
 		var stats models.SystemUserCountStats
 		_, err := sess.SQL(rawSQL).Get(&stats)
 		if err != nil {
@@ -271,7 +276,7 @@ var (
 )
 
 func (ss *SQLStore) updateUserRoleCounts(ctx context.Context) error {
-	return ss.WithDbSession(ctx, func(dbSession *DBSession) error {
+	return ss.WithReplicaSession(ctx, func(dbSession *DBSession) error {
 		query := `
 SELECT role AS bitrole, active, COUNT(role) AS count FROM
   (SELECT last_seen_at>? AS active, last_seen_at>? AS daily_active, SUM(role) AS role
@@ -284,6 +289,7 @@ SELECT role AS bitrole, active, COUNT(role) AS count FROM
       END AS role,
       u.last_seen_at
     FROM ` + dialect.Quote("user") + ` AS u INNER JOIN org_user ON org_user.user_id = u.id
+    WHERE u.deleted_at IS NULL
     GROUP BY u.id, u.last_seen_at, org_user.role) AS t2
   GROUP BY id, last_seen_at) AS t1
 GROUP BY active, daily_active, role;`
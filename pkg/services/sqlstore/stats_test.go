@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -62,6 +63,34 @@ func TestIntegrationStatsDataAccess(t *testing.T) {
 		err := sqlStore.GetAdminStats(context.Background(), &query)
 		assert.NoError(t, err)
 	})
+
+	t.Run("Soft deleted users are not counted", func(t *testing.T) {
+		deleted, err := sqlStore.CreateUser(context.Background(), user.CreateUserCommand{Login: "deleted_user_login", OrgName: "Org deleted"})
+		require.NoError(t, err)
+		err = sqlStore.WithDbSession(context.Background(), func(sess *DBSession) error {
+			_, err := sess.Exec("UPDATE "+sqlStore.Dialect.Quote("user")+" SET deleted_at = ? WHERE id = ?", time.Now(), deleted.ID)
+			return err
+		})
+		require.NoError(t, err)
+		err = sqlStore.updateUserRoleCountsIfNecessary(context.Background(), true)
+		require.NoError(t, err)
+
+		systemStats := models.GetSystemStatsQuery{}
+		err = sqlStore.GetSystemStats(context.Background(), &systemStats)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), systemStats.Result.Users)
+		assert.Equal(t, int64(3), systemStats.Result.Admins)
+
+		adminStats := models.GetAdminStatsQuery{}
+		err = sqlStore.GetAdminStats(context.Background(), &adminStats)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), adminStats.Result.Users)
+
+		userCount := models.GetSystemUserCountStatsQuery{}
+		err = sqlStore.GetSystemUserCountStats(context.Background(), &userCount)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), userCount.Result.Count)
+	})
 }
 
 func populateDB(t *testing.T, sqlStore *SQLStore) {
@@ -24,12 +24,10 @@ type Store interface {
 	GetSignedInUser(ctx context.Context, query *models.GetSignedInUserQuery) error
 	WithDbSession(ctx context.Context, callback DBTransactionFunc) error
 	WithNewDbSession(ctx context.Context, callback DBTransactionFunc) error
+	WithReplicaSession(ctx context.Context, callback DBTransactionFunc) error
 	GetOrgQuotaByTarget(ctx context.Context, query *models.GetOrgQuotaByTargetQuery) error
 	GetOrgQuotas(ctx context.Context, query *models.GetOrgQuotasQuery) error
 	UpdateOrgQuota(ctx context.Context, cmd *models.UpdateOrgQuotaCmd) error
-	GetUserQuotaByTarget(ctx context.Context, query *models.GetUserQuotaByTargetQuery) error
-	GetUserQuotas(ctx context.Context, query *models.GetUserQuotasQuery) error
-	UpdateUserQuota(ctx context.Context, cmd *models.UpdateUserQuotaCmd) error
 	GetGlobalQuotaByTarget(ctx context.Context, query *models.GetGlobalQuotaByTargetQuery) error
 	WithTransactionalDbSession(ctx context.Context, callback DBTransactionFunc) error
 	InTransaction(ctx context.Context, fn func(ctx context.Context) error) error
@@ -38,5 +36,8 @@ type Store interface {
 	Reset() error
 	Quote(value string) string
 	GetDBHealthQuery(ctx context.Context, query *models.GetDBHealthQuery) error
+	GetPoolStats() PoolStats
+	RegisterPartitionedTable(table PartitionedTable)
+	RotatePartitions(ctx context.Context) error
 	GetSqlxSession() *session.SessionDB
 }
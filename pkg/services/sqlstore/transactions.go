@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/mattn/go-sqlite3"
 	"xorm.io/xorm"
 
@@ -48,25 +49,25 @@ func (ss *SQLStore) inTransactionWithRetryCtx(ctx context.Context, engine *xorm.
 		defer sess.Close()
 	}
 
-	err = callback(sess)
-
 	ctxLogger := tsclogger.FromContext(ctx)
 
 	if !isNew {
 		ctxLogger.Debug("skip committing the transaction because it belongs to a session created in the outer scope")
-		// Do not commit the transaction if the session was reused.
-		return err
+		// Do not commit the transaction if the session was reused. The nested transaction runs in a savepoint, so its
+		// failure only rolls back its own changes.
+		return sess.WithSavepoint(callback)
 	}
 
-	// special handling of database locked errors for sqlite, then we can retry 5 times
-	var sqlError sqlite3.Error
-	if errors.As(err, &sqlError) && retry < ss.dbCfg.TransactionRetries && (sqlError.Code == sqlite3.ErrLocked || sqlError.Code == sqlite3.ErrBusy) {
+	err = callback(sess)
+
+	// special handling of database locked errors for sqlite and of serialization failures, then we can retry 5 times
+	if retry < ss.dbCfg.TransactionRetries && isRetryableTransactionError(err) {
 		if rollErr := sess.Rollback(); rollErr != nil {
 			return fmt.Errorf("rolling back transaction due to error failed: %s: %w", rollErr, err)
 		}
 
-		time.Sleep(time.Millisecond * time.Duration(10))
-		ctxLogger.Info("Database locked, sleeping then retrying", "error", err, "retry", retry, "code", sqlError.Code)
+		time.Sleep(retryBackoff(retry))
+		ctxLogger.Info("Database locked, sleeping then retrying", "error", err, "retry", retry)
 		return ss.inTransactionWithRetryCtx(ctx, engine, bus, callback, retry+1)
 	}
 
@@ -77,6 +78,12 @@ func (ss *SQLStore) inTransactionWithRetryCtx(ctx context.Context, engine *xorm.
 		return err
 	}
 	if err := sess.Commit(); err != nil {
+		// CockroachDB can report the conflicts of a transaction when committing it
+		if retry < ss.dbCfg.TransactionRetries && isRetryableTransactionError(err) {
+			time.Sleep(retryBackoff(retry))
+			ctxLogger.Info("Transaction conflicted when committing, sleeping then retrying", "error", err, "retry", retry)
+			return ss.inTransactionWithRetryCtx(ctx, engine, bus, callback, retry+1)
+		}
 		return err
 	}
 
@@ -90,3 +97,18 @@ func (ss *SQLStore) inTransactionWithRetryCtx(ctx context.Context, engine *xorm.
 
 	return nil
 }
+
+// isRetryableTransactionError returns true when a transaction failed because of concurrent transactions, and was
+// rolled back so it can run again: SQLite was locked, or a serializable transaction of Postgres or CockroachDB
+// conflicted with another one
+func isRetryableTransactionError(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrLocked || sqliteErr.Code == sqlite3.ErrBusy
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "40001"
+	}
+	return false
+}
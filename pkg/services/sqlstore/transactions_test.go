@@ -2,8 +2,13 @@ package sqlstore
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
 
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/require"
 )
 
@@ -65,3 +70,151 @@ func TestIntegrationReuseSessionWithTransaction(t *testing.T) {
 		}))
 	})
 }
+
+func TestIntegrationNestedTransactionSavepoints(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := InitTestDB(t)
+
+	starCount := func(t *testing.T, userID int64) int64 {
+		var count int64
+		err := ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+			var err error
+			count, err = sess.Table("star").Where("user_id = ?", userID).Count()
+			return err
+		})
+		require.NoError(t, err)
+		return count
+	}
+
+	t.Run("a failing nested transaction only rolls back its own changes", func(t *testing.T) {
+		err := ss.InTransaction(context.Background(), func(ctx context.Context) error {
+			outer := ctx.Value(ContextSessionKey{}).(*DBSession)
+			if _, err := outer.Insert(&bulkTestStar{UserID: 1, DashboardID: 1}); err != nil {
+				return err
+			}
+
+			err := ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+				if _, err := sess.Insert(&bulkTestStar{UserID: 1, DashboardID: 2}); err != nil {
+					return err
+				}
+				sess.PublishAfterCommit("event")
+				// fails the statement, which aborts the whole transaction on Postgres without the savepoint
+				_, err := sess.Insert(&bulkTestStar{UserID: 1, DashboardID: 2})
+				return err
+			})
+			require.Error(t, err)
+			require.Empty(t, outer.events)
+			require.Zero(t, outer.savepoints)
+
+			_, err = outer.Insert(&bulkTestStar{UserID: 1, DashboardID: 3})
+			return err
+		})
+		require.NoError(t, err)
+
+		var stars []bulkTestStar
+		require.NoError(t, ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+			return sess.Where("user_id = ?", 1).Asc("dashboard_id").Find(&stars)
+		}))
+		require.Len(t, stars, 2)
+		require.Equal(t, int64(1), stars[0].DashboardID)
+		require.Equal(t, int64(3), stars[1].DashboardID)
+	})
+
+	t.Run("nested transactions are committed with the outer transaction", func(t *testing.T) {
+		err := ss.InTransaction(context.Background(), func(ctx context.Context) error {
+			return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+				return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+					require.Equal(t, 2, sess.savepoints)
+					_, err := sess.Insert(&bulkTestStar{UserID: 2, DashboardID: 1})
+					return err
+				})
+			})
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), starCount(t, 2))
+	})
+
+	t.Run("the outer transaction is rolled back when it fails", func(t *testing.T) {
+		err := ss.InTransaction(context.Background(), func(ctx context.Context) error {
+			err := ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+				_, err := sess.Insert(&bulkTestStar{UserID: 3, DashboardID: 1})
+				return err
+			})
+			require.NoError(t, err)
+			return errors.New("outer failure")
+		})
+		require.Error(t, err)
+		require.Zero(t, starCount(t, 3))
+	})
+
+	t.Run("sessions without a transaction run the callback without a savepoint", func(t *testing.T) {
+		err := ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+			return sess.WithSavepoint(func(sess *DBSession) error {
+				require.Zero(t, sess.savepoints)
+				return nil
+			})
+		})
+		require.NoError(t, err)
+	})
+}
+
+type forUpdateCounter struct {
+	Id    int64
+	Value int64
+}
+
+func TestIntegrationForUpdate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss := InitTestDB(t)
+	require.NoError(t, ss.engine.Sync2(new(forUpdateCounter)))
+	t.Cleanup(func() { _ = ss.engine.DropTables(new(forUpdateCounter)) })
+	_, err := ss.engine.Insert(&forUpdateCounter{Id: 1})
+	require.NoError(t, err)
+	// the transactions losing the race on SQLite are retried
+	ss.dbCfg.TransactionRetries = 100
+
+	// concurrent transactions reading the counter before incrementing it don't lose increments
+	const workers, increments = 4, 5
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*increments)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < increments; i++ {
+				errs <- ss.WithTransactionalDbSession(context.Background(), func(sess *DBSession) error {
+					var counter forUpdateCounter
+					if _, err := sess.ForUpdate().ID(1).Get(&counter); err != nil {
+						return err
+					}
+					_, err := sess.ID(1).Cols("value").Update(&forUpdateCounter{Value: counter.Value + 1})
+					return err
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	var counter forUpdateCounter
+	_, err = ss.engine.ID(1).Get(&counter)
+	require.NoError(t, err)
+	require.Equal(t, int64(workers*increments), counter.Value)
+}
+
+func TestIsRetryableTransactionError(t *testing.T) {
+	require.True(t, isRetryableTransactionError(sqlite3.Error{Code: sqlite3.ErrBusy}))
+	require.True(t, isRetryableTransactionError(sqlite3.Error{Code: sqlite3.ErrLocked}))
+	require.False(t, isRetryableTransactionError(sqlite3.Error{Code: sqlite3.ErrConstraint}))
+	require.True(t, isRetryableTransactionError(fmt.Errorf("commit: %w", &pq.Error{Code: "40001"})))
+	require.False(t, isRetryableTransactionError(&pq.Error{Code: "23505"}))
+	require.False(t, isRetryableTransactionError(errors.New("some error")))
+	require.False(t, isRetryableTransactionError(nil))
+}
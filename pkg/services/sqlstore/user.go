@@ -98,6 +98,7 @@ func (ss *SQLStore) createUser(ctx context.Context, sess *DBSession, args user.C
 		Updated:          TimeNow(),
 		LastSeenAt:       TimeNow().AddDate(-10, 0, 0),
 		IsServiceAccount: args.IsServiceAccount,
+		Version:          1,
 	}
 
 	salt, err := util.GetRandomString(10)
@@ -161,6 +162,12 @@ func (ss *SQLStore) createUser(ctx context.Context, sess *DBSession, args user.C
 
 // deprecated method, use only for tests
 func (ss *SQLStore) CreateUser(ctx context.Context, cmd user.CreateUserCommand) (*user.User, error) {
+	if len(cmd.Password) > 0 {
+		if err := user.ValidatePassword(cmd.Password, ss.Cfg); err != nil {
+			return nil, err
+		}
+	}
+
 	var user user.User
 	createErr := ss.WithTransactionalDbSession(ctx, func(sess *DBSession) (err error) {
 		user, err = ss.createUser(ctx, sess, cmd)
@@ -490,7 +497,15 @@ func deleteUserInTransaction(ss *SQLStore, sess *DBSession, cmd *models.DeleteUs
 		}
 	}
 
-	return deleteUserAccessControl(sess, cmd.UserId)
+	if err := deleteUserAccessControl(sess, cmd.UserId); err != nil {
+		return err
+	}
+
+	sess.PublishAfterCommit(&events.UserDeleted{
+		Timestamp: time.Now(),
+		Id:        usr.ID,
+	})
+	return nil
 }
 
 func deleteUserAccessControl(sess *DBSession, userID int64) error {
@@ -544,6 +559,10 @@ func UserDeletions() []string {
 		"DELETE FROM user_auth WHERE user_id = ?",
 		"DELETE FROM user_auth_token WHERE user_id = ?",
 		"DELETE FROM quota WHERE user_id = ?",
+		"DELETE FROM user_attribute WHERE user_id = ?",
+		"DELETE FROM user_password_history WHERE user_id = ?",
+		"DELETE FROM user_email_verification WHERE user_id = ?",
+		"DELETE FROM user_label WHERE user_id = ?",
 	}
 	return deletes
 }
@@ -51,16 +51,21 @@ func getFilteredUsers(signedInUser *user.SignedInUser, hiddenUsers map[string]st
 	return filteredUsers
 }
 
+// getTeamMemberCount returns the subquery counting the members of a team. Soft deleted users keep their memberships
+// until they are purged, they are not counted
 func getTeamMemberCount(db db.DB, filteredUsers []string) string {
+	userTable := db.GetDialect().Quote("user")
 	if len(filteredUsers) > 0 {
 		return `(SELECT COUNT(*) FROM team_member
-			INNER JOIN ` + db.GetDialect().Quote("user") + ` ON team_member.user_id = ` + db.GetDialect().Quote("user") + `.id
-			WHERE team_member.team_id = team.id AND ` + db.GetDialect().Quote("user") + `.login NOT IN (?` +
+			INNER JOIN ` + userTable + ` ON team_member.user_id = ` + userTable + `.id
+			WHERE team_member.team_id = team.id AND ` + userTable + `.deleted_at IS NULL AND ` + userTable + `.login NOT IN (?` +
 			strings.Repeat(",?", len(filteredUsers)-1) + ")" +
 			`) AS member_count `
 	}
 
-	return "(SELECT COUNT(*) FROM team_member WHERE team_member.team_id = team.id) AS member_count "
+	return `(SELECT COUNT(*) FROM team_member
+			INNER JOIN ` + userTable + ` ON team_member.user_id = ` + userTable + `.id
+			WHERE team_member.team_id = team.id AND ` + userTable + `.deleted_at IS NULL) AS member_count `
 }
 
 func getTeamSelectSQLBase(db db.DB, filteredUsers []string) string {
@@ -527,6 +532,8 @@ func (ss *xormStore) getTeamMembers(ctx context.Context, query *models.GetTeamMe
 
 		// explicitly check for serviceaccounts
 		sess.Where(fmt.Sprintf("%s.is_service_account=?", ss.db.GetDialect().Quote("user")), ss.db.GetDialect().BooleanStr(false))
+		// soft deleted users keep their memberships until they are purged
+		sess.Where(fmt.Sprintf("%s.deleted_at IS NULL", ss.db.GetDialect().Quote("user")))
 
 		if acUserFilter != nil {
 			sess.Where(acUserFilter.Where, acUserFilter.Args...)
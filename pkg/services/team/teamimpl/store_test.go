@@ -140,6 +140,42 @@ func TestIntegrationTeamCommandsAndQueries(t *testing.T) {
 				require.Equal(t, memberQuery.Result[0].External, true)
 			})
 
+			t.Run("Should not count soft deleted users as team members", func(t *testing.T) {
+				team3, err := teamSvc.CreateTeam("group3 name", "test3@test.com", testOrgID)
+				require.NoError(t, err)
+				err = teamSvc.AddTeamMember(userIds[2], testOrgID, team3.Id, false, 0)
+				require.NoError(t, err)
+				err = teamSvc.AddTeamMember(userIds[3], testOrgID, team3.Id, false, 0)
+				require.NoError(t, err)
+
+				// soft deleted users keep their memberships until they are purged
+				err = sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+					_, err := sess.Exec("UPDATE "+sqlStore.GetDialect().Quote("user")+" SET deleted_at = ? WHERE id = ?", time.Now(), userIds[3])
+					return err
+				})
+				require.NoError(t, err)
+				t.Cleanup(func() {
+					err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+						_, err := sess.Exec("UPDATE "+sqlStore.GetDialect().Quote("user")+" SET deleted_at = NULL WHERE id = ?", userIds[3])
+						return err
+					})
+					require.NoError(t, err)
+				})
+
+				query := &models.SearchTeamsQuery{OrgId: testOrgID, Name: "group3 name", Page: 1, Limit: 10, SignedInUser: testUser}
+				err = teamSvc.SearchTeams(context.Background(), query)
+				require.NoError(t, err)
+				require.EqualValues(t, 1, query.Result.Teams[0].MemberCount)
+
+				getTeamQuery := &models.GetTeamByIdQuery{OrgId: testOrgID, Id: team3.Id, SignedInUser: testUser}
+				err = teamSvc.GetTeamById(context.Background(), getTeamQuery)
+				require.NoError(t, err)
+				require.EqualValues(t, 1, getTeamQuery.Result.MemberCount)
+
+				err = teamSvc.DeleteTeam(context.Background(), &models.DeleteTeamCommand{OrgId: testOrgID, Id: team3.Id})
+				require.NoError(t, err)
+			})
+
 			t.Run("Should be able to update users in a team", func(t *testing.T) {
 				userId := userIds[0]
 				team := team1
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/models/roletype"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 type HelpFlags1 uint64
@@ -21,14 +22,69 @@ const (
 
 // Typed errors
 var (
-	ErrCaseInsensitive   = errors.New("case insensitive conflict")
-	ErrUserNotFound      = errors.New("user not found")
-	ErrUserAlreadyExists = errors.New("user already exists")
-	ErrLastGrafanaAdmin  = errors.New("cannot remove last grafana admin")
-	ErrProtectedUser     = errors.New("cannot adopt protected user")
-	ErrNoUniqueID        = errors.New("identifying id not found")
+	ErrCaseInsensitive               = errors.New("case insensitive conflict")
+	ErrUserNotFound                  = errors.New("user not found")
+	ErrUserAlreadyExists             = errors.New("user already exists")
+	ErrLastGrafanaAdmin              = errors.New("cannot remove last grafana admin")
+	ErrInvalidGrafanaAdminTransfer   = errors.New("grafana admin can only be transferred from a grafana admin to another enabled user")
+	ErrUserVersionConflict           = errors.New("user was changed since it was read")
+	ErrProtectedUser                 = errors.New("cannot adopt protected user")
+	ErrNoUniqueID                    = errors.New("identifying id not found")
+	ErrInvalidAttribute              = errors.New("user attribute keys must be 1 to 190 characters long and values at most 255 characters long")
+	ErrInvalidLabel                  = errors.New("user labels must be 1 to 190 characters long")
+	ErrPasswordPolicy                = errors.New("password does not satisfy the password policy")
+	ErrInvalidOldPassword            = errors.New("invalid old password")
+	ErrInvalidSuccessor              = errors.New("the successor of a deleted user must be another existing user")
+	ErrFieldLocked                   = errors.New("user profile field is locked")
+	ErrInvalidExport                 = errors.New("user exports must be in csv or json format")
+	ErrMergeSameUser                 = errors.New("cannot merge a user into itself")
+	ErrNoUserConflict                = errors.New("users do not have conflicting logins or emails")
+	ErrInvalidResolution             = errors.New("user conflicts are resolved by merging or disabling the conflicting user")
+	ErrInvalidQuotaTarget            = errors.New("invalid user quota target")
+	ErrImpersonationDisabled         = errors.New("user impersonation is disabled")
+	ErrImpersonationForbidden        = errors.New("only Grafana server admins can impersonate users, who must be enabled and not Grafana server admins")
+	ErrImpersonationExpired          = errors.New("user impersonation expired")
+	ErrEmailNotVerified              = errors.New("email address of the user is not verified")
+	ErrEmailVerificationTokenInvalid = errors.New("invalid or expired email verification token")
 )
 
+// ExportFormat is the format users are exported in
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatJSON ExportFormat = "json"
+)
+
+// PasswordRule is a rule of the password policy configured in the [auth.basic] section
+type PasswordRule string
+
+const (
+	PasswordRuleMinLength PasswordRule = "minLength"
+	PasswordRuleUppercase PasswordRule = "uppercase"
+	PasswordRuleLowercase PasswordRule = "lowercase"
+	PasswordRuleDigit     PasswordRule = "digit"
+	PasswordRuleSymbol    PasswordRule = "symbol"
+	PasswordRuleHistory   PasswordRule = "history"
+)
+
+// PasswordPolicyError lists the rules of the password policy a password failed, it wraps ErrPasswordPolicy
+type PasswordPolicyError struct {
+	FailedRules []PasswordRule
+}
+
+func (e *PasswordPolicyError) Error() string {
+	rules := make([]string, 0, len(e.FailedRules))
+	for _, rule := range e.FailedRules {
+		rules = append(rules, string(rule))
+	}
+	return fmt.Sprintf("%s: %s", ErrPasswordPolicy, strings.Join(rules, ", "))
+}
+
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrPasswordPolicy
+}
+
 type User struct {
 	ID            int64 `xorm:"pk autoincr 'id'"`
 	Version       int
@@ -51,6 +107,35 @@ type User struct {
 	Created    time.Time
 	Updated    time.Time
 	LastSeenAt time.Time
+	// DeletedAt is set on soft deleted users until they are restored or purged
+	DeletedAt *time.Time
+	// FailedLoginAttempts counts the consecutive failed logins, the user is locked until LockedUntil once they reach
+	// the configured maximum
+	FailedLoginAttempts int
+	LockedUntil         *time.Time
+	// LastLoginIP is hashed or empty depending on the last_login_client_tracking configuration
+	LastLoginAt        *time.Time
+	LastLoginIP        string `xorm:"last_login_ip"`
+	LastLoginUserAgent string
+	// NameLocked, EmailLocked and LoginLocked lock the fields managed by administrators or identity providers, users
+	// can't change them
+	NameLocked  bool
+	EmailLocked bool
+	LoginLocked bool
+}
+
+// LockedFields returns the profile fields of the user which are locked
+func (u *User) LockedFields() []string {
+	fields := make([]string, 0, 3)
+	for _, field := range []struct {
+		name   string
+		locked bool
+	}{{"name", u.NameLocked}, {"email", u.EmailLocked}, {"login", u.LoginLocked}} {
+		if field.locked {
+			fields = append(fields, field.name)
+		}
+	}
+	return fields
 }
 
 type CreateUserCommand struct {
@@ -82,19 +167,46 @@ type UpdateUserCommand struct {
 	Email string `json:"email"`
 	Login string `json:"login"`
 	Theme string `json:"theme"`
+	// Version is the version of the user the update is based on, the update fails with ErrUserVersionConflict when
+	// the user was changed since. 0 skips the check
+	Version int `json:"version"`
+	// UserInitiated marks the updates users make to their own profile, which fail with ErrFieldLocked when they
+	// change locked fields
+	UserInitiated bool `json:"-"`
 
 	UserID int64 `json:"-"`
 }
 
+// SetUserFieldLocksCommand locks or unlocks the name, email and login of a user
+type SetUserFieldLocksCommand struct {
+	Name  bool `json:"name"`
+	Email bool `json:"email"`
+	Login bool `json:"login"`
+
+	UserID int64 `json:"-"`
+}
+
+// ChangeUserPasswordCommand changes the password of a user. NewPassword is sent in plain text to the user service,
+// which validates it against the password policy and encodes it before storing it. The old password is verified when
+// the user has one, IsReset skips the verification for the flows in which users proved their identity otherwise, such
+// as password reset emails, or in which the password is set for them, by Grafana server admins
 type ChangeUserPasswordCommand struct {
 	OldPassword string `json:"oldPassword"`
 	NewPassword string `json:"newPassword"`
 
-	UserID int64 `json:"-"`
+	UserID  int64 `json:"-"`
+	IsReset bool  `json:"-"`
 }
 
 type UpdateUserLastSeenAtCommand struct {
 	UserID int64
+	// Login is the client of the user when it was just logged in, recorded when last_login_client_tracking is enabled
+	Login *LoginClient
+}
+
+type LoginClient struct {
+	IP        string
+	UserAgent string
 }
 
 type SetUsingOrgCommand struct {
@@ -106,33 +218,63 @@ type SearchUsersQuery struct {
 	SignedInUser *SignedInUser
 	OrgID        int64 `xorm:"org_id"`
 	Query        string
-	Page         int
-	Limit        int
+	Pagination   pagination.Page
 	AuthModule   string
 	Filters      []Filter
 
 	IsDisabled *bool
+	// LastSeenBefore and LastSeenAfter only return users last seen in the range, to find stale accounts
+	LastSeenBefore *time.Time
+	LastSeenAfter  *time.Time
+	// Attributes only returns users with all the attributes set to the values
+	Attributes map[string]string
+	// Label only returns users with the label
+	Label string
+	// WithOrgs returns the organizations of the users and their roles in them
+	WithOrgs bool
+	// IncludeServiceAccounts returns the service accounts along with the users, OnlyServiceAccounts only returns them
+	IncludeServiceAccounts bool
+	OnlyServiceAccounts    bool
+}
+
+// CountUsersResult is the number of users matching a search, in total and by status
+type CountUsersResult struct {
+	Total int64 `json:"total"`
+	// Active is the number of users seen in the last 30 days
+	Active   int64 `json:"active"`
+	Disabled int64 `json:"disabled"`
+	Admins   int64 `json:"admins"`
+	// ByAuthModule is the number of users by their most recent auth module, users without one are not included
+	ByAuthModule map[string]int64 `json:"byAuthModule"`
 }
 
 type SearchUserQueryResult struct {
-	TotalCount int64               `json:"totalCount"`
-	Users      []*UserSearchHitDTO `json:"users"`
-	Page       int                 `json:"page"`
-	PerPage    int                 `json:"perPage"`
+	pagination.Result
+	Users []*UserSearchHitDTO `json:"users"`
 }
 
 type UserSearchHitDTO struct {
-	ID            int64                `json:"id" xorm:"id"`
-	Name          string               `json:"name"`
-	Login         string               `json:"login"`
-	Email         string               `json:"email"`
-	AvatarURL     string               `json:"avatarUrl" xorm:"avatar_url"`
-	IsAdmin       bool                 `json:"isAdmin"`
-	IsDisabled    bool                 `json:"isDisabled"`
-	LastSeenAt    time.Time            `json:"lastSeenAt"`
-	LastSeenAtAge string               `json:"lastSeenAtAge"`
-	AuthLabels    []string             `json:"authLabels"`
-	AuthModule    AuthModuleConversion `json:"-"`
+	ID               int64                  `json:"id" xorm:"id"`
+	Name             string                 `json:"name"`
+	Login            string                 `json:"login"`
+	Email            string                 `json:"email"`
+	AvatarURL        string                 `json:"avatarUrl" xorm:"avatar_url"`
+	IsAdmin          bool                   `json:"isAdmin"`
+	IsDisabled       bool                   `json:"isDisabled"`
+	IsServiceAccount bool                   `json:"isServiceAccount"`
+	LastSeenAt       time.Time              `json:"lastSeenAt"`
+	LastSeenAtAge    string                 `json:"lastSeenAtAge"`
+	AuthLabels       []string               `json:"authLabels"`
+	AuthModule       AuthModuleConversion   `json:"-"`
+	Orgs             []*UserSearchHitOrgDTO `json:"orgs,omitempty" xorm:"-"`
+}
+
+// UserSearchHitOrgDTO is an organization of a user returned by a search, and the role of the user in it
+type UserSearchHitOrgDTO struct {
+	UserID int64             `json:"-" xorm:"user_id"`
+	OrgID  int64             `json:"orgId" xorm:"org_id"`
+	Name   string            `json:"name"`
+	Role   roletype.RoleType `json:"role"`
 }
 
 type GetUserProfileQuery struct {
@@ -141,6 +283,7 @@ type GetUserProfileQuery struct {
 
 type UserProfileDTO struct {
 	ID             int64           `json:"id"`
+	Version        int             `json:"version"`
 	Email          string          `json:"email"`
 	Name           string          `json:"name"`
 	Login          string          `json:"login"`
@@ -154,6 +297,13 @@ type UserProfileDTO struct {
 	CreatedAt      time.Time       `json:"createdAt"`
 	AvatarUrl      string          `json:"avatarUrl"`
 	AccessControl  map[string]bool `json:"accessControl,omitempty"`
+	Quotas         []*Quota        `json:"quotas,omitempty"`
+	// LastLoginAt, LastLoginIP and LastLoginUserAgent are only set when last_login_client_tracking is enabled
+	LastLoginAt        *time.Time `json:"lastLoginAt,omitempty"`
+	LastLoginIP        string     `json:"lastLoginIp,omitempty"`
+	LastLoginUserAgent string     `json:"lastLoginUserAgent,omitempty"`
+	// LockedFields are the profile fields the user can't change
+	LockedFields []string `json:"lockedFields"`
 }
 
 // implement Conversion interface to define custom field mapping (xorm feature)
@@ -170,14 +320,233 @@ func (auth *AuthModuleConversion) ToDB() ([]byte, error) {
 	return []byte{}, nil
 }
 
+// DisableUserCommand disables or enables a user. Without UserID, the user is found by its login or email, compared
+// case-insensitively when logins are case insensitive
 type DisableUserCommand struct {
-	UserID     int64 `xorm:"user_id"`
-	IsDisabled bool
+	UserID       int64 `xorm:"user_id"`
+	LoginOrEmail string
+	IsDisabled   bool
 }
 
+// BatchDisableUsersCommand disables or enables users. The users whose state changed are returned in UpdatedUserIDs,
+// the users which don't exist, are deleted or are service accounts in NotFoundUserIDs
 type BatchDisableUsersCommand struct {
 	UserIDs    []int64 `xorm:"user_ids"`
 	IsDisabled bool
+
+	UpdatedUserIDs  []int64
+	NotFoundUserIDs []int64
+}
+
+// SetUserAttributeCommand sets an attribute of a user, an empty value removes the attribute
+type SetUserAttributeCommand struct {
+	UserID int64 `xorm:"user_id"`
+	Key    string
+	Value  string
+}
+
+type GetUserAttributesQuery struct {
+	UserID int64 `xorm:"user_id"`
+}
+
+// SetUserLabelsCommand replaces the labels of a user, no labels removes them all
+type SetUserLabelsCommand struct {
+	UserID int64 `xorm:"user_id"`
+	Labels []string
+}
+
+type GetUserLabelsQuery struct {
+	UserID int64 `xorm:"user_id"`
+}
+
+type GetEffectiveAccessQuery struct {
+	UserID int64 `xorm:"user_id"`
+}
+
+// EffectiveAccessReport is the access of a user to the organizations, teams, dashboards and folders of the instance,
+// for access reviews
+type EffectiveAccessReport struct {
+	UserID         int64                 `json:"userId"`
+	Login          string                `json:"login"`
+	IsGrafanaAdmin bool                  `json:"isGrafanaAdmin"`
+	Orgs           []*EffectiveOrgAccess `json:"orgs"`
+}
+
+// EffectiveOrgAccess is the access of a user in an organization. Dashboards hold the dashboards and folders whose
+// access control lists grant the user a permission, the dashboards of folders inherit the permissions of their folder
+type EffectiveOrgAccess struct {
+	OrgID      int64                       `json:"orgId"`
+	Name       string                      `json:"name"`
+	Role       roletype.RoleType           `json:"role"`
+	Teams      []*EffectiveTeamAccess      `json:"teams"`
+	Dashboards []*EffectiveDashboardAccess `json:"dashboards"`
+}
+
+type EffectiveTeamAccess struct {
+	TeamID int64  `json:"teamId"`
+	Name   string `json:"name"`
+	// Permission is the permission of the user in the team, 4 for the admins of the team
+	Permission int `json:"permission"`
+}
+
+// EffectiveDashboardAccess is the highest permission granted to a user on a dashboard or folder, and the grants giving
+// it to the user: "user" for the user itself, "team:<name>" for its teams and "role:<role>" for its role
+type EffectiveDashboardAccess struct {
+	DashboardID    int64    `json:"dashboardId"`
+	UID            string   `json:"uid"`
+	Title          string   `json:"title"`
+	IsFolder       bool     `json:"isFolder"`
+	Permission     int      `json:"permission"`
+	PermissionName string   `json:"permissionName"`
+	Sources        []string `json:"sources"`
+}
+
+type RestoreUserCommand struct {
+	UserID int64 `xorm:"user_id"`
+}
+
+// AuditAction is a change to the identity data of a user recorded in the audit log
+type AuditAction string
+
+const (
+	AuditActionUpdate            AuditAction = "update"
+	AuditActionUpdatePermissions AuditAction = "update-permissions"
+	AuditActionDisable           AuditAction = "disable"
+	AuditActionEnable            AuditAction = "enable"
+	AuditActionChangePassword    AuditAction = "change-password"
+	AuditActionImpersonate       AuditAction = "impersonate"
+	AuditActionVerifyEmail       AuditAction = "verify-email"
+	AuditActionLockFields        AuditAction = "lock-fields"
+)
+
+// AuditChange is a field changed by an audited change. The values of secret fields, such as passwords, are not recorded
+type AuditChange struct {
+	Field    string `json:"field"`
+	OldValue string `json:"oldValue,omitempty"`
+	NewValue string `json:"newValue,omitempty"`
+}
+
+// AuditEntry records a change to the identity data of a user along with the user who made it. The actor is empty for
+// changes made by Grafana itself or from the CLI. Changes made while impersonating the actor also record the
+// Grafana server admin impersonating it
+type AuditEntry struct {
+	ID                int64         `json:"id"`
+	UserID            int64         `json:"userId"`
+	ActorID           int64         `json:"actorId"`
+	ActorLogin        string        `json:"actorLogin"`
+	ImpersonatorID    int64         `json:"impersonatorId,omitempty"`
+	ImpersonatorLogin string        `json:"impersonatorLogin,omitempty"`
+	Action            AuditAction   `json:"action"`
+	Changes           []AuditChange `json:"changes"`
+	Created           time.Time     `json:"created"`
+}
+
+// GetAuditEntriesQuery pages through the audit log of a user, most recent entries first, optionally of a single action
+type GetAuditEntriesQuery struct {
+	UserID int64
+	Action AuditAction
+	Page   int
+	Limit  int
+}
+
+type AuditEntriesResult struct {
+	TotalCount int64         `json:"totalCount"`
+	Entries    []*AuditEntry `json:"entries"`
+	Page       int           `json:"page"`
+	PerPage    int           `json:"perPage"`
+}
+
+// LoginResult is the outcome of a login recorded in the login history
+type LoginResult string
+
+const (
+	LoginResultSuccess LoginResult = "success"
+	LoginResultFailure LoginResult = "failure"
+)
+
+// LoginHistoryEntry is a successful or failed login. Failed logins of unknown users have no user ID, only the login
+// they were attempted with
+type LoginHistoryEntry struct {
+	ID         int64       `json:"id"`
+	UserID     int64       `json:"userId"`
+	Login      string      `json:"login"`
+	AuthModule string      `json:"authModule"`
+	Result     LoginResult `json:"result"`
+	Created    time.Time   `json:"created"`
+}
+
+// RecordLoginCommand records a login in the login history. The user is looked up by login when UserID is not set
+type RecordLoginCommand struct {
+	UserID     int64
+	Login      string
+	AuthModule string
+	Result     LoginResult
+}
+
+// GetLoginHistoryQuery pages through the login history, most recent logins first. Logins can be filtered by user,
+// authentication module, result and time range, zero values match all logins
+type GetLoginHistoryQuery struct {
+	UserID     int64
+	AuthModule string
+	Result     LoginResult
+	From       time.Time
+	To         time.Time
+	Page       int
+	Limit      int
+}
+
+type LoginHistoryResult struct {
+	TotalCount int64                `json:"totalCount"`
+	Entries    []*LoginHistoryEntry `json:"entries"`
+	Page       int                  `json:"page"`
+	PerPage    int                  `json:"perPage"`
+}
+
+// PurgeLoginHistoryCommand deletes the logins recorded before OlderThan
+type PurgeLoginHistoryCommand struct {
+	OlderThan time.Time
+
+	PurgedLogins int64
+}
+
+// Quota is the limit of a user for a quota target, the organizations, dashboards, data sources or api keys of the
+// user, and how much of it the user uses
+type Quota struct {
+	UserID int64  `json:"user_id"`
+	Target string `json:"target"`
+	Limit  int64  `json:"limit"`
+	Used   int64  `json:"used"`
+}
+
+// GetQuotasQuery gets the quotas of a user, of all the targets unless Targets is set. The usage of the alert rule
+// target is only counted when UnifiedAlertingEnabled is set
+type GetQuotasQuery struct {
+	UserID                 int64
+	Targets                []string
+	UnifiedAlertingEnabled bool
+}
+
+// SetQuotaCommand overrides the default limit of a user for a quota target
+type SetQuotaCommand struct {
+	UserID int64
+	Target string
+	Limit  int64
+}
+
+// DeactivateInactiveUsersCommand disables the users not seen since a time, except Grafana server admins and the users
+// whose login or email is excluded. In dry run mode, the users are only returned
+type DeactivateInactiveUsersCommand struct {
+	InactiveSince time.Time
+	Exclusions    []string
+	DryRun        bool
+
+	DeactivatedUsers []int64
+}
+
+type PurgeDeletedUsersCommand struct {
+	OlderThan time.Time
+
+	PurgedUsers int64
 }
 
 type SetUserHelpFlagCommand struct {
@@ -212,6 +581,35 @@ type SignedInUser struct {
 	Teams              []int64
 	// Permissions grouped by orgID and actions
 	Permissions map[int64]map[string][]string `json:"-"`
+	// Impersonator is set when a Grafana server admin acts as the user
+	Impersonator *Impersonator `json:"-" xorm:"-"`
+}
+
+// Impersonator is a Grafana server admin acting as another user until ExpiresAt
+type Impersonator struct {
+	UserID    int64
+	Login     string
+	ExpiresAt time.Time
+}
+
+// ImpersonateUserCommand gets a signed in user of a user, in an organization of the user or its current organization,
+// for a Grafana server admin to act as the user for a duration, at most the configured maximum
+type ImpersonateUserCommand struct {
+	ImpersonatorID int64
+	UserID         int64
+	OrgID          int64
+	Duration       time.Duration
+}
+
+// CreateEmailVerificationTokenCommand issues a token verifying the current email address of a user. Issuing a token
+// revokes the previous tokens of the user
+type CreateEmailVerificationTokenCommand struct {
+	UserID int64
+}
+
+// VerifyEmailCommand marks the email address a token was issued for as verified, if it is still the address of the user
+type VerifyEmailCommand struct {
+	Token string
 }
 
 func (u *User) NameOrFallback() string {
@@ -226,6 +624,8 @@ func (u *User) NameOrFallback() string {
 
 type DeleteUserCommand struct {
 	UserID int64
+	// SuccessorID is the user the resources created by the deleted user are reassigned to, if any
+	SuccessorID int64
 }
 
 type GetUserByIDQuery struct {
@@ -236,6 +636,38 @@ type ErrCaseInsensitiveLoginConflict struct {
 	Users []User
 }
 
+// UserConflict is a pair of users whose logins or emails only differ by their case, which prevents enabling case
+// insensitive logins
+type UserConflict struct {
+	Users         [2]ConflictingUserDTO `json:"users"`
+	LoginConflict bool                  `json:"loginConflict"`
+	EmailConflict bool                  `json:"emailConflict"`
+}
+
+type ConflictingUserDTO struct {
+	ID         int64     `json:"id" xorm:"id"`
+	Login      string    `json:"login"`
+	Email      string    `json:"email"`
+	IsDisabled bool      `json:"isDisabled"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+}
+
+// ConflictResolution is what is done with the conflicting user of a user conflict
+type ConflictResolution string
+
+const (
+	ConflictResolutionMerge   ConflictResolution = "merge"
+	ConflictResolutionDisable ConflictResolution = "disable"
+)
+
+// ResolveUserConflictCommand resolves the conflict between two users by keeping the canonical user, and either
+// merging the conflicting user into it or disabling the conflicting user
+type ResolveUserConflictCommand struct {
+	CanonicalUserID   int64
+	ConflictingUserID int64
+	Resolution        ConflictResolution
+}
+
 type UserDisplayDTO struct {
 	ID        int64  `json:"id,omitempty"`
 	Name      string `json:"name,omitempty"`
@@ -246,8 +678,14 @@ type UserDisplayDTO struct {
 // ------------------------
 // DTO & Projections
 
-func (u *SignedInUser) ShouldUpdateLastSeenAt() bool {
-	return u.UserID > 0 && time.Since(u.LastSeenAt) > time.Minute*5
+// IsImpersonationExpired returns whether the user is impersonated by a Grafana server admin whose impersonation expired
+func (u *SignedInUser) IsImpersonationExpired(now time.Time) bool {
+	return u.Impersonator != nil && !now.Before(u.Impersonator.ExpiresAt)
+}
+
+// ShouldUpdateLastSeenAt returns whether the last seen time of the user is older than the minimum update interval
+func (u *SignedInUser) ShouldUpdateLastSeenAt(interval time.Duration) bool {
+	return u.UserID > 0 && time.Since(u.LastSeenAt) > interval
 }
 
 func (u *SignedInUser) NameOrFallback() string {
@@ -0,0 +1,48 @@
+package user
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ValidatePassword checks a password in plain text against the password policy of the [auth.basic] section and
+// returns a PasswordPolicyError listing every rule it failed
+func ValidatePassword(password string, cfg *setting.Cfg) error {
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	var failed []PasswordRule
+	if utf8.RuneCountInString(password) < cfg.PasswordMinLength {
+		failed = append(failed, PasswordRuleMinLength)
+	}
+	if cfg.PasswordRequireUppercase && !hasUpper {
+		failed = append(failed, PasswordRuleUppercase)
+	}
+	if cfg.PasswordRequireLowercase && !hasLower {
+		failed = append(failed, PasswordRuleLowercase)
+	}
+	if cfg.PasswordRequireDigit && !hasDigit {
+		failed = append(failed, PasswordRuleDigit)
+	}
+	if cfg.PasswordRequireSymbol && !hasSymbol {
+		failed = append(failed, PasswordRuleSymbol)
+	}
+
+	if len(failed) > 0 {
+		return &PasswordPolicyError{FailedRules: failed}
+	}
+	return nil
+}
@@ -2,12 +2,14 @@ package user
 
 import (
 	"context"
+	"io"
 )
 
 type Service interface {
 	Create(context.Context, *CreateUserCommand) (*User, error)
 	Delete(context.Context, *DeleteUserCommand) error
 	GetByID(context.Context, *GetUserByIDQuery) (*User, error)
+	GetByIDs(context.Context, []int64) (map[int64]*User, error)
 	GetByLogin(context.Context, *GetUserByLoginQuery) (*User, error)
 	GetByEmail(context.Context, *GetUserByEmailQuery) (*User, error)
 	Update(context.Context, *UpdateUserCommand) error
@@ -17,9 +19,39 @@ type Service interface {
 	GetSignedInUserWithCacheCtx(context.Context, *GetSignedInUserQuery) (*SignedInUser, error)
 	GetSignedInUser(context.Context, *GetSignedInUserQuery) (*SignedInUser, error)
 	Search(context.Context, *SearchUsersQuery) (*SearchUserQueryResult, error)
+	Count(context.Context, *SearchUsersQuery) (*CountUsersResult, error)
+	Export(context.Context, *SearchUsersQuery, ExportFormat, io.Writer) error
 	Disable(context.Context, *DisableUserCommand) error
 	BatchDisableUsers(context.Context, *BatchDisableUsersCommand) error
+	BatchDeleteUsers(context.Context, []int64) error
+	Restore(context.Context, *RestoreUserCommand) error
+	AnonymizeUser(context.Context, int64) error
+	MergeUsers(ctx context.Context, targetID, sourceID int64) error
+	GetConflicts(context.Context) ([]*UserConflict, error)
+	ResolveConflict(context.Context, *ResolveUserConflictCommand) error
+	SetAttribute(context.Context, *SetUserAttributeCommand) error
+	GetAttributes(context.Context, *GetUserAttributesQuery) (map[string]string, error)
+	SetLabels(context.Context, *SetUserLabelsCommand) error
+	GetLabels(context.Context, *GetUserLabelsQuery) ([]string, error)
+	GetEffectiveAccess(context.Context, *GetEffectiveAccessQuery) (*EffectiveAccessReport, error)
+	PurgeDeletedUsers(context.Context, *PurgeDeletedUsersCommand) error
+	DeactivateInactiveUsers(context.Context, *DeactivateInactiveUsersCommand) error
+	RecordFailedLogin(context.Context, int64) error
+	IsLocked(context.Context, int64) (bool, error)
+	ResetLockout(context.Context, int64) error
 	UpdatePermissions(context.Context, int64, bool) error
+	SetFieldLocks(context.Context, *SetUserFieldLocksCommand) error
+	TransferGrafanaAdmin(ctx context.Context, fromUserID, toUserID int64) error
+	GetAuditEntries(context.Context, *GetAuditEntriesQuery) (*AuditEntriesResult, error)
+	RecordLogin(context.Context, *RecordLoginCommand) error
+	GetLoginHistory(context.Context, *GetLoginHistoryQuery) (*LoginHistoryResult, error)
+	PurgeLoginHistory(context.Context, *PurgeLoginHistoryCommand) error
+	Impersonate(context.Context, *ImpersonateUserCommand) (*SignedInUser, error)
+	CreateEmailVerificationToken(context.Context, *CreateEmailVerificationTokenCommand) (string, error)
+	VerifyEmail(context.Context, *VerifyEmailCommand) error
+	CheckEmailVerified(context.Context, *User) error
 	SetUserHelpFlag(context.Context, *SetUserHelpFlagCommand) error
 	GetProfile(context.Context, *GetUserProfileQuery) (*UserProfileDTO, error)
+	GetQuotas(context.Context, *GetQuotasQuery) ([]*Quota, error)
+	SetQuota(context.Context, *SetQuotaCommand) error
 }
@@ -0,0 +1,120 @@
+package userimpl
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/appcontext"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// audited runs a change to a user and records the fields it changed in the audit log, in the same transaction so
+// changes are never left unrecorded. Changes which leave all the fields as they were are not recorded. Changes made
+// while impersonating a user are refused once the impersonation expired
+func (s *Service) audited(ctx context.Context, userID int64, action user.AuditAction, change func(context.Context) error, changes func() []user.AuditChange) error {
+	entry := &user.AuditEntry{UserID: userID, Action: action}
+	if actor, err := appcontext.User(ctx); err == nil {
+		if actor.IsImpersonationExpired(time.Now()) {
+			return user.ErrImpersonationExpired
+		}
+		entry.ActorID = actor.UserID
+		entry.ActorLogin = actor.Login
+		if actor.Impersonator != nil {
+			entry.ImpersonatorID = actor.Impersonator.UserID
+			entry.ImpersonatorLogin = actor.Impersonator.Login
+		}
+	}
+
+	return s.store.InTransaction(ctx, func(ctx context.Context) error {
+		if err := change(ctx); err != nil {
+			return err
+		}
+
+		entry.Changes = changes()
+		if len(entry.Changes) == 0 {
+			return nil
+		}
+		entry.Created = time.Now()
+		return s.store.InsertAuditEntry(ctx, entry)
+	})
+}
+
+// Impersonate returns a signed in user for a Grafana server admin to act as another user, until the impersonation
+// expires. The impersonation is recorded in the audit log of the user, and so are the changes made during it, with
+// both the user and the Grafana server admin
+func (s *Service) Impersonate(ctx context.Context, cmd *user.ImpersonateUserCommand) (*user.SignedInUser, error) {
+	if s.cfg.ImpersonationMaxDuration <= 0 {
+		return nil, user.ErrImpersonationDisabled
+	}
+	if cmd.ImpersonatorID == cmd.UserID {
+		return nil, user.ErrImpersonationForbidden
+	}
+
+	impersonator, err := s.store.GetByID(ctx, cmd.ImpersonatorID)
+	if err != nil {
+		return nil, err
+	}
+	if !impersonator.IsAdmin || impersonator.IsDisabled {
+		return nil, user.ErrImpersonationForbidden
+	}
+
+	// the signed in user is not cached, it is not shared with the user's own requests
+	signedInUser, err := s.GetSignedInUser(ctx, &user.GetSignedInUserQuery{UserID: cmd.UserID, OrgID: cmd.OrgID})
+	if err != nil {
+		return nil, err
+	}
+	if signedInUser.IsGrafanaAdmin || signedInUser.IsDisabled {
+		return nil, user.ErrImpersonationForbidden
+	}
+
+	duration := cmd.Duration
+	if duration <= 0 || duration > s.cfg.ImpersonationMaxDuration {
+		duration = s.cfg.ImpersonationMaxDuration
+	}
+	now := time.Now()
+	signedInUser.Impersonator = &user.Impersonator{UserID: impersonator.ID, Login: impersonator.Login, ExpiresAt: now.Add(duration)}
+
+	err = s.store.InsertAuditEntry(ctx, &user.AuditEntry{
+		UserID:     signedInUser.UserID,
+		ActorID:    impersonator.ID,
+		ActorLogin: impersonator.Login,
+		Action:     user.AuditActionImpersonate,
+		Changes:    []user.AuditChange{{Field: "impersonationExpiresAt", NewValue: signedInUser.Impersonator.ExpiresAt.UTC().Format(time.RFC3339)}},
+		Created:    now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.FromContext(ctx).Info("User impersonated", "userId", signedInUser.UserID, "orgId", signedInUser.OrgID, "impersonatorId", impersonator.ID, "impersonatorLogin", impersonator.Login, "expiresAt", signedInUser.Impersonator.ExpiresAt)
+	return signedInUser, nil
+}
+
+// auditFieldChanges returns the changes of the fields updated to a non-empty value, like the store does
+func auditFieldChanges(fields ...[3]string) []user.AuditChange {
+	changes := make([]user.AuditChange, 0, len(fields))
+	for _, f := range fields {
+		if f[2] != "" && f[2] != f[1] {
+			changes = append(changes, user.AuditChange{Field: f[0], OldValue: f[1], NewValue: f[2]})
+		}
+	}
+	return changes
+}
+
+func auditBoolChange(field string, oldValue, newValue bool) []user.AuditChange {
+	if oldValue == newValue {
+		return nil
+	}
+	return []user.AuditChange{{Field: field, OldValue: strconv.FormatBool(oldValue), NewValue: strconv.FormatBool(newValue)}}
+}
+
+func (s *Service) GetAuditEntries(ctx context.Context, query *user.GetAuditEntriesQuery) (*user.AuditEntriesResult, error) {
+	if query.Limit <= 0 {
+		query.Limit = 100
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	return s.store.GetAuditEntries(ctx, query)
+}
@@ -0,0 +1,54 @@
+package userimpl
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// autoAssignOrgRoles returns the roles the configured rules give a new user, by organization. The first rule matching
+// the user decides its role in an organization
+func (s *Service) autoAssignOrgRoles(usr *user.User) map[int64]org.RoleType {
+	roles := make(map[int64]org.RoleType)
+	if usr.IsServiceAccount {
+		return roles
+	}
+
+	emailDomain := ""
+	if at := strings.LastIndex(usr.Email, "@"); at >= 0 {
+		emailDomain = strings.ToLower(usr.Email[at+1:])
+	}
+	for _, rule := range s.cfg.AutoAssignOrgRules {
+		if _, ok := roles[rule.OrgID]; ok {
+			continue
+		}
+		if (rule.EmailDomain != "" && rule.EmailDomain == emailDomain) ||
+			(rule.Company != "" && strings.EqualFold(rule.Company, usr.Company)) {
+			roles[rule.OrgID] = org.RoleType(rule.Role)
+		}
+	}
+	return roles
+}
+
+// assignOrgsByRules adds a new user to the organizations of the rules matching it, other than its own organization.
+// Organizations the user can't be added to, such as deleted ones, are skipped so they don't fail the creation
+func (s *Service) assignOrgsByRules(ctx context.Context, usr *user.User, roles map[int64]org.RoleType) {
+	for orgID, role := range roles {
+		if orgID == usr.OrgID {
+			continue
+		}
+		_, err := s.orgService.InsertOrgUser(ctx, &org.OrgUser{
+			OrgID:   orgID,
+			UserID:  usr.ID,
+			Role:    role,
+			Created: time.Now(),
+			Updated: time.Now(),
+		})
+		if err != nil {
+			s.log.FromContext(ctx).Warn("Failed to assign new user to organization", "userId", usr.ID, "orgId", orgID, "err", err)
+		}
+	}
+}
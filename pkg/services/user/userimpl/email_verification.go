@@ -0,0 +1,75 @@
+package userimpl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// CreateEmailVerificationToken returns a token verifying the current email address of a user, valid for the configured
+// lifetime. Only the hash of the token is stored, the token itself is returned once, to be sent to the address
+func (s *Service) CreateEmailVerificationToken(ctx context.Context, cmd *user.CreateEmailVerificationTokenCommand) (string, error) {
+	usr, err := s.store.GetNotServiceAccount(ctx, cmd.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := util.GetRandomString(32)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	err = s.store.CreateEmailVerification(ctx, &emailVerification{
+		UserID:    usr.ID,
+		Email:     usr.Email,
+		TokenHash: hashEmailVerificationToken(token),
+		Created:   now,
+		Expires:   now.Add(s.cfg.EmailVerificationTokenLifetime),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// VerifyEmail marks the email address a token was issued for as verified and records it in the audit log of the user.
+// Expired tokens, and tokens of addresses the user no longer has, are refused with ErrEmailVerificationTokenInvalid
+func (s *Service) VerifyEmail(ctx context.Context, cmd *user.VerifyEmailCommand) error {
+	verification, err := s.store.GetEmailVerification(ctx, hashEmailVerificationToken(cmd.Token))
+	if err != nil {
+		return err
+	}
+	if time.Now().After(verification.Expires) {
+		return user.ErrEmailVerificationTokenInvalid
+	}
+
+	usr, err := s.store.GetByID(ctx, verification.UserID)
+	if err != nil {
+		return err
+	}
+
+	return s.audited(ctx, usr.ID, user.AuditActionVerifyEmail, func(ctx context.Context) error {
+		return s.store.SetEmailVerified(ctx, usr.ID, verification.Email)
+	}, func() []user.AuditChange {
+		return auditBoolChange("emailVerified", usr.EmailVerified, true)
+	})
+}
+
+// CheckEmailVerified returns ErrEmailNotVerified when email verification is required and the email address of the user
+// is not verified. Grafana server admins are never blocked, so they can't lock themselves out
+func (s *Service) CheckEmailVerified(ctx context.Context, usr *user.User) error {
+	if !s.cfg.EmailVerificationRequired || usr.EmailVerified || usr.IsAdmin || usr.IsServiceAccount {
+		return nil
+	}
+	return user.ErrEmailNotVerified
+}
+
+func hashEmailVerificationToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
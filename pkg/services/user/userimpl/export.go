@@ -0,0 +1,182 @@
+package userimpl
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util/pagination"
+)
+
+// exportPageSize is the number of users read from the store for each page of an export
+var exportPageSize int64 = 1000
+
+var exportColumns = []string{"id", "login", "email", "name", "isAdmin", "isDisabled", "lastSeenAt", "authModule"}
+
+type exportedUser struct {
+	ID         int64     `json:"id"`
+	Login      string    `json:"login"`
+	Email      string    `json:"email"`
+	Name       string    `json:"name"`
+	IsAdmin    bool      `json:"isAdmin"`
+	IsDisabled bool      `json:"isDisabled"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	AuthModule string    `json:"authModule"`
+}
+
+func newExportedUser(hit *user.UserSearchHitDTO) exportedUser {
+	exported := exportedUser{
+		ID:         hit.ID,
+		Login:      hit.Login,
+		Email:      hit.Email,
+		Name:       hit.Name,
+		IsAdmin:    hit.IsAdmin,
+		IsDisabled: hit.IsDisabled,
+		LastSeenAt: hit.LastSeenAt,
+	}
+	if len(hit.AuthModule) > 0 {
+		exported.AuthModule = hit.AuthModule[0]
+	}
+	return exported
+}
+
+func (u exportedUser) csvRecord() []string {
+	return []string{
+		strconv.FormatInt(u.ID, 10),
+		u.Login,
+		u.Email,
+		u.Name,
+		strconv.FormatBool(u.IsAdmin),
+		strconv.FormatBool(u.IsDisabled),
+		u.LastSeenAt.UTC().Format(time.RFC3339),
+		u.AuthModule,
+	}
+}
+
+// Export writes all the users matching the query to w, as CSV with a header row or as a JSON array. The users are
+// read page by page with key cursors, so exports hold a single page in memory, and only include the users the
+// signed in user of the query can read. The pagination of the query only sets the sort order
+func (s *Service) Export(ctx context.Context, query *user.SearchUsersQuery, format user.ExportFormat, w io.Writer) error {
+	var exporter userExporter
+	buffered := bufio.NewWriter(w)
+	switch format {
+	case user.ExportFormatCSV:
+		exporter = &csvUserExporter{w: csv.NewWriter(buffered)}
+	case user.ExportFormatJSON:
+		exporter = &jsonUserExporter{w: buffered}
+	default:
+		return user.ErrInvalidExport
+	}
+
+	pageQuery := *query
+	pageQuery.Pagination = pagination.Page{PerPage: exportPageSize, Order: query.Pagination.Order}
+	for {
+		result, err := s.store.Search(ctx, &pageQuery)
+		if err != nil {
+			return err
+		}
+
+		for _, hit := range result.Users {
+			if err := exporter.write(newExportedUser(hit)); err != nil {
+				return err
+			}
+		}
+		// every page is written once read, so the export starts streaming with the first page
+		if err := exporter.flush(); err != nil {
+			return err
+		}
+		if err := buffered.Flush(); err != nil {
+			return err
+		}
+
+		if result.NextCursor == "" {
+			break
+		}
+		pageQuery.Pagination.Cursor = result.NextCursor
+	}
+
+	if err := exporter.close(); err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
+
+type userExporter interface {
+	write(exportedUser) error
+	flush() error
+	close() error
+}
+
+type csvUserExporter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (e *csvUserExporter) writeHeader() error {
+	if e.headerWritten {
+		return nil
+	}
+	e.headerWritten = true
+	return e.w.Write(exportColumns)
+}
+
+func (e *csvUserExporter) write(u exportedUser) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	return e.w.Write(u.csvRecord())
+}
+
+func (e *csvUserExporter) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvUserExporter) close() error {
+	// exports without users still have the header row
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	return e.flush()
+}
+
+type jsonUserExporter struct {
+	w       *bufio.Writer
+	written int
+}
+
+func (e *jsonUserExporter) write(u exportedUser) error {
+	b, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+
+	separator := ","
+	if e.written == 0 {
+		separator = "["
+	}
+	if _, err := e.w.WriteString(separator); err != nil {
+		return err
+	}
+	e.written++
+	_, err = e.w.Write(b)
+	return err
+}
+
+func (e *jsonUserExporter) flush() error {
+	return nil
+}
+
+func (e *jsonUserExporter) close() error {
+	end := "]\n"
+	if e.written == 0 {
+		end = "[]\n"
+	}
+	_, err := e.w.WriteString(end)
+	return err
+}
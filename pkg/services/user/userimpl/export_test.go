@@ -0,0 +1,114 @@
+package userimpl
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/pagination"
+)
+
+func TestIntegrationUserExport(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ss := db.InitTestDB(t)
+	store := ProvideStore(ss, setting.NewCfg())
+	userService := &Service{store: &store, cfg: setting.NewCfg()}
+
+	users := createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+		return &user.CreateUserCommand{
+			Email:      fmt.Sprint("user", i, "@test.com"),
+			Name:       fmt.Sprint("user", i),
+			Login:      fmt.Sprint("loginuser", i),
+			IsDisabled: i%2 == 1,
+		}
+	})
+	reader := &user.SignedInUser{
+		OrgID:       1,
+		Permissions: map[int64]map[string][]string{1: {"users:read": {"global.users:*"}}},
+	}
+
+	// the users are read in several pages
+	exportPageSize = 2
+	t.Cleanup(func() { exportPageSize = 1000 })
+
+	t.Run("exports the users as CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := userService.Export(context.Background(), &user.SearchUsersQuery{SignedInUser: reader}, user.ExportFormatCSV, &buf)
+		require.NoError(t, err)
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 6)
+		assert.Equal(t, exportColumns, records[0])
+		for i, record := range records[1:] {
+			assert.Equal(t, []string{fmt.Sprint(users[i].ID), fmt.Sprint("loginuser", i), fmt.Sprint("user", i, "@test.com"), fmt.Sprint("user", i)}, record[:4])
+			assert.Equal(t, fmt.Sprint(i%2 == 1), record[5])
+		}
+	})
+
+	t.Run("exports the users matching the query as JSON", func(t *testing.T) {
+		disabled := false
+		query := &user.SearchUsersQuery{
+			SignedInUser: reader,
+			IsDisabled:   &disabled,
+			Pagination:   pagination.Page{Order: pagination.SortDescending},
+		}
+
+		var buf bytes.Buffer
+		err := userService.Export(context.Background(), query, user.ExportFormatJSON, &buf)
+		require.NoError(t, err)
+
+		var exported []exportedUser
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &exported))
+		logins := make([]string, 0, len(exported))
+		for _, u := range exported {
+			logins = append(logins, u.Login)
+		}
+		assert.Equal(t, []string{"loginuser4", "loginuser2", "loginuser0"}, logins)
+	})
+
+	t.Run("only exports the users the signed in user can read", func(t *testing.T) {
+		limited := &user.SignedInUser{
+			OrgID:       1,
+			Permissions: map[int64]map[string][]string{1: {"users:read": {fmt.Sprint("global.users:id:", users[1].ID)}}},
+		}
+
+		var buf bytes.Buffer
+		err := userService.Export(context.Background(), &user.SearchUsersQuery{SignedInUser: limited}, user.ExportFormatJSON, &buf)
+		require.NoError(t, err)
+
+		var exported []exportedUser
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &exported))
+		require.Len(t, exported, 1)
+		assert.Equal(t, users[1].ID, exported[0].ID)
+	})
+
+	t.Run("exports no users as an empty list", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := userService.Export(context.Background(), &user.SearchUsersQuery{SignedInUser: reader, Query: "nobody"}, user.ExportFormatJSON, &buf)
+		require.NoError(t, err)
+		assert.Equal(t, "[]\n", buf.String())
+
+		buf.Reset()
+		err = userService.Export(context.Background(), &user.SearchUsersQuery{SignedInUser: reader, Query: "nobody"}, user.ExportFormatCSV, &buf)
+		require.NoError(t, err)
+		assert.Equal(t, "id,login,email,name,isAdmin,isDisabled,lastSeenAt,authModule\n", buf.String())
+	})
+
+	t.Run("rejects unknown formats", func(t *testing.T) {
+		err := userService.Export(context.Background(), &user.SearchUsersQuery{SignedInUser: reader}, "xml", &bytes.Buffer{})
+		require.ErrorIs(t, err, user.ErrInvalidExport)
+	})
+}
@@ -0,0 +1,140 @@
+package userimpl
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// maxLastLoginUserAgentLength is the length of the last_login_user_agent column, longer user agents are truncated
+const maxLastLoginUserAgentLength = 255
+
+// lastSeenBatcher collects the users seen since the last flush, so their last seen times are written in a single
+// bulk update instead of a write per request
+type lastSeenBatcher struct {
+	mu      sync.Mutex
+	pending map[int64]struct{}
+	// written keeps when the last seen times of users were last written, so users whose cached signed in user still
+	// has an old last seen time are not written again within the minimum update interval
+	written map[int64]time.Time
+}
+
+func newLastSeenBatcher() *lastSeenBatcher {
+	return &lastSeenBatcher{
+		pending: make(map[int64]struct{}),
+		written: make(map[int64]time.Time),
+	}
+}
+
+// record keeps the user until the next flush, unless its last seen time was written within the interval
+func (b *lastSeenBatcher) record(userID int64, now time.Time, interval time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if writtenAt, ok := b.written[userID]; ok && now.Sub(writtenAt) < interval {
+		return
+	}
+	b.pending[userID] = struct{}{}
+}
+
+// take returns the users recorded since the last flush and forgets the writes older than the interval
+func (b *lastSeenBatcher) take(now time.Time, interval time.Duration) []int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for userID, writtenAt := range b.written {
+		if now.Sub(writtenAt) >= interval {
+			delete(b.written, userID)
+		}
+	}
+
+	userIDs := make([]int64, 0, len(b.pending))
+	for userID := range b.pending {
+		userIDs = append(userIDs, userID)
+		b.written[userID] = now
+	}
+	b.pending = make(map[int64]struct{})
+	return userIDs
+}
+
+// restore keeps users failing to be written for the next flush
+func (b *lastSeenBatcher) restore(userIDs []int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, userID := range userIDs {
+		b.pending[userID] = struct{}{}
+		delete(b.written, userID)
+	}
+}
+
+// IsDisabled the last seen times of users are written on every update when the flush interval is 0
+func (s *Service) IsDisabled() bool {
+	return s.cfg.UserLastSeenFlushInterval <= 0
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.UserLastSeenFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.flushLastSeenAndLog(ctx)
+		case <-ctx.Done():
+			// the context is done, the users seen since the last flush are written with a fresh one
+			s.flushLastSeenAndLog(context.Background())
+			return ctx.Err()
+		}
+	}
+}
+
+// flushLastSeen writes the last seen times of the users seen since the last flush. Users failing to be written are
+// kept for the next flush
+func (s *Service) flushLastSeen(ctx context.Context) error {
+	now := time.Now()
+	userIDs := s.lastSeen.take(now, s.cfg.UserLastSeenUpdateInterval)
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	if err := s.store.BatchUpdateLastSeenAt(ctx, userIDs, now); err != nil {
+		s.lastSeen.restore(userIDs)
+		return err
+	}
+	return nil
+}
+
+func (s *Service) flushLastSeenAndLog(ctx context.Context) {
+	if err := s.flushLastSeen(ctx); err != nil {
+		s.log.FromContext(ctx).Error("Failed to write the last seen time of users", "error", err)
+	}
+}
+
+// updateLastLogin records the client of the login of a user as configured by last_login_client_tracking. Hashed IP
+// addresses are keyed with the secret key, so they can't be reversed by hashing all the addresses
+func (s *Service) updateLastLogin(ctx context.Context, userID int64, client *user.LoginClient) error {
+	ip := client.IP
+	switch s.cfg.LastLoginClientTracking {
+	case "raw":
+	case "hashed":
+		if ip != "" {
+			mac := hmac.New(sha256.New, []byte(s.cfg.SecretKey))
+			mac.Write([]byte(ip))
+			ip = hex.EncodeToString(mac.Sum(nil))
+		}
+	default:
+		return nil
+	}
+
+	userAgent := []rune(client.UserAgent)
+	if len(userAgent) > maxLastLoginUserAgentLength {
+		userAgent = userAgent[:maxLastLoginUserAgentLength]
+	}
+	return s.store.UpdateLastLogin(ctx, userID, &user.LoginClient{IP: ip, UserAgent: string(userAgent)}, time.Now())
+}
@@ -0,0 +1,61 @@
+package userimpl
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// maxLoginHistoryLength is the length of the login column of the login history, longer attempted logins are truncated
+const maxLoginHistoryLength = 190
+
+// RecordLogin records a successful or failed login in the login history, unless the login history is disabled. Failed
+// logins are recorded with the user they were attempted with when it exists, so they can be counted per user
+func (s *Service) RecordLogin(ctx context.Context, cmd *user.RecordLoginCommand) error {
+	if s.cfg.LoginHistoryRetention == 0 {
+		return nil
+	}
+
+	entry := &user.LoginHistoryEntry{
+		UserID:     cmd.UserID,
+		Login:      cmd.Login,
+		AuthModule: cmd.AuthModule,
+		Result:     cmd.Result,
+		Created:    time.Now(),
+	}
+	if entry.UserID == 0 && entry.Login != "" {
+		usr, err := s.store.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: entry.Login})
+		switch {
+		case err == nil:
+			entry.UserID = usr.ID
+		case !errors.Is(err, user.ErrUserNotFound):
+			return err
+		}
+	}
+	if len(entry.Login) > maxLoginHistoryLength {
+		entry.Login = entry.Login[:maxLoginHistoryLength]
+	}
+
+	return s.store.InsertLoginHistory(ctx, entry)
+}
+
+func (s *Service) GetLoginHistory(ctx context.Context, query *user.GetLoginHistoryQuery) (*user.LoginHistoryResult, error) {
+	if query.Limit <= 0 {
+		query.Limit = 100
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	return s.store.GetLoginHistory(ctx, query)
+}
+
+func (s *Service) PurgeLoginHistory(ctx context.Context, cmd *user.PurgeLoginHistoryCommand) error {
+	purged, err := s.store.PurgeLoginHistory(ctx, cmd.OlderThan)
+	if err != nil {
+		return err
+	}
+	cmd.PurgedLogins = purged
+	return nil
+}
@@ -0,0 +1,144 @@
+package userimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func init() {
+	remotecache.Register(user.SignedInUser{})
+}
+
+// signedInUserCache caches signed in users in the memory of the instance, or in the remote cache so the replicas of
+// an instance share them. The cached users of a user are invalidated by bumping a generation of the user which is
+// part of their keys, so the users cached for all its organizations are invalidated at once, on every replica
+type signedInUserCache struct {
+	log    log.Logger
+	local  *localcache.CacheService
+	remote remotecache.CacheStorage
+	ttl    time.Duration
+}
+
+func newSignedInUserCache(cfg *setting.Cfg, local *localcache.CacheService, remote *remotecache.RemoteCache) *signedInUserCache {
+	c := &signedInUserCache{
+		log:   log.New("user.signedinusercache"),
+		local: local,
+		ttl:   cfg.SignedInUserCacheTTL,
+	}
+	if c.ttl <= 0 {
+		c.ttl = 5 * time.Second
+	}
+	if c.local == nil {
+		c.local = localcache.ProvideService()
+	}
+	if cfg.SignedInUserCacheBackend == "remote" && remote != nil {
+		c.remote = remote
+	}
+	return c
+}
+
+func (c *signedInUserCache) get(ctx context.Context, orgID, userID int64) (*user.SignedInUser, bool) {
+	key, ok := c.key(ctx, orgID, userID)
+	if !ok {
+		return nil, false
+	}
+
+	cached, found := c.read(ctx, key)
+	if !found {
+		return nil, false
+	}
+	signedInUser, ok := cached.(user.SignedInUser)
+	if !ok {
+		return nil, false
+	}
+	return &signedInUser, true
+}
+
+func (c *signedInUserCache) set(ctx context.Context, orgID int64, signedInUser *user.SignedInUser) {
+	if key, ok := c.key(ctx, orgID, signedInUser.UserID); ok {
+		c.write(ctx, key, *signedInUser, c.ttl)
+	}
+}
+
+// invalidate drops the cached signed in users of users in all their organizations
+func (c *signedInUserCache) invalidate(ctx context.Context, userIDs ...int64) {
+	generation := time.Now().UnixNano()
+	for _, userID := range userIDs {
+		// the generation outlives the users cached with the previous one
+		c.write(ctx, generationCacheKey(userID), generation, 2*c.ttl+time.Minute)
+	}
+}
+
+func (c *signedInUserCache) key(ctx context.Context, orgID, userID int64) (string, bool) {
+	var generation int64
+	if cached, found := c.read(ctx, generationCacheKey(userID)); found {
+		if generation, found = cached.(int64); !found {
+			return "", false
+		}
+	}
+	return fmt.Sprintf("signed-in-user-%d-%d-%d", userID, orgID, generation), true
+}
+
+func (c *signedInUserCache) read(ctx context.Context, key string) (interface{}, bool) {
+	if c.remote == nil {
+		return c.local.Get(key)
+	}
+
+	value, err := c.remote.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			c.log.FromContext(ctx).Warn("Failed to read signed in user cache", "key", key, "err", err)
+		}
+		return nil, false
+	}
+	return value, true
+}
+
+func (c *signedInUserCache) write(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	if c.remote == nil {
+		c.local.Set(key, value, ttl)
+		return
+	}
+
+	if err := c.remote.Set(ctx, key, value, ttl); err != nil {
+		c.log.FromContext(ctx).Warn("Failed to write signed in user cache", "key", key, "err", err)
+	}
+}
+
+func generationCacheKey(userID int64) string {
+	return fmt.Sprintf("signed-in-user-generation-%d", userID)
+}
+
+func (s *Service) handleUserUpdated(ctx context.Context, e *events.UserUpdated) error {
+	s.signedInUsers.invalidate(ctx, e.Id)
+	return nil
+}
+
+func (s *Service) handleUsersDisabled(ctx context.Context, e *events.UsersDisabled) error {
+	s.signedInUsers.invalidate(ctx, e.Ids...)
+	return nil
+}
+
+func (s *Service) handleUsersDeleted(ctx context.Context, e *events.UsersDeleted) error {
+	s.signedInUsers.invalidate(ctx, e.Ids...)
+	return nil
+}
+
+func (s *Service) handleUserDeleted(ctx context.Context, e *events.UserDeleted) error {
+	s.signedInUsers.invalidate(ctx, e.Id)
+	return nil
+}
+
+func (s *Service) handleOrgUserUpdated(ctx context.Context, e *events.OrgUserUpdated) error {
+	s.signedInUsers.invalidate(ctx, e.UserId)
+	return nil
+}
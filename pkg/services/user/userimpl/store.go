@@ -2,7 +2,9 @@ package userimpl
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -10,33 +12,181 @@ import (
 	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/models/roletype"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/pagination"
+	"xorm.io/xorm"
 )
 
 type store interface {
 	Insert(context.Context, *user.User) (int64, error)
 	Get(context.Context, *user.User) (*user.User, error)
 	GetByID(context.Context, int64) (*user.User, error)
+	GetByIDs(context.Context, []int64) (map[int64]*user.User, error)
 	GetNotServiceAccount(context.Context, int64) (*user.User, error)
 	Delete(context.Context, int64) error
+	TransferOwnership(context.Context, int64, int64) error
 	CaseInsensitiveLoginConflict(context.Context, string, string) error
+	GetConflicts(context.Context) ([]*user.UserConflict, error)
 	GetByLogin(context.Context, *user.GetUserByLoginQuery) (*user.User, error)
 	GetByEmail(context.Context, *user.GetUserByEmailQuery) (*user.User, error)
 	Update(context.Context, *user.UpdateUserCommand) error
 	ChangePassword(context.Context, *user.ChangeUserPasswordCommand) error
 	UpdateLastSeenAt(context.Context, *user.UpdateUserLastSeenAtCommand) error
+	BatchUpdateLastSeenAt(context.Context, []int64, time.Time) error
+	UpdateLastLogin(ctx context.Context, userID int64, client *user.LoginClient, loginAt time.Time) error
 	GetSignedInUser(context.Context, *user.GetSignedInUserQuery) (*user.SignedInUser, error)
 	UpdateUser(context.Context, *user.User) error
 	GetProfile(context.Context, *user.GetUserProfileQuery) (*user.UserProfileDTO, error)
+	GetQuotas(context.Context, int64, map[string]int64, bool) ([]*user.Quota, error)
+	SetQuota(context.Context, *user.SetQuotaCommand) error
 	SetHelpFlag(context.Context, *user.SetUserHelpFlagCommand) error
 	UpdatePermissions(context.Context, int64, bool) error
+	SetFieldLocks(context.Context, *user.SetUserFieldLocksCommand) error
 	BatchDisableUsers(context.Context, *user.BatchDisableUsersCommand) error
+	BatchDeleteUsers(context.Context, []int64) error
+	Restore(context.Context, int64) error
+	Anonymize(context.Context, int64) error
+	MergeUsers(context.Context, int64, int64) (*mergedUserRows, error)
+	SetAttribute(context.Context, *user.SetUserAttributeCommand) error
+	GetAttributes(context.Context, *user.GetUserAttributesQuery) (map[string]string, error)
+	SetLabels(context.Context, *user.SetUserLabelsCommand) error
+	GetLabels(context.Context, *user.GetUserLabelsQuery) ([]string, error)
+	GetEffectiveAccess(context.Context, int64) (*user.EffectiveAccessReport, error)
+	GetPasswordHistory(context.Context, int64, int) ([]string, error)
+	InsertAuditEntry(context.Context, *user.AuditEntry) error
+	GetAuditEntries(context.Context, *user.GetAuditEntriesQuery) (*user.AuditEntriesResult, error)
+	InsertLoginHistory(context.Context, *user.LoginHistoryEntry) error
+	GetLoginHistory(context.Context, *user.GetLoginHistoryQuery) (*user.LoginHistoryResult, error)
+	PurgeLoginHistory(context.Context, time.Time) (int64, error)
+	InTransaction(context.Context, func(context.Context) error) error
+	CreateEmailVerification(context.Context, *emailVerification) error
+	GetEmailVerification(context.Context, string) (*emailVerification, error)
+	SetEmailVerified(context.Context, int64, string) error
+	RecordFailedLogin(context.Context, int64, int, time.Duration) error
+	IsLocked(context.Context, int64) (bool, error)
+	ResetLockout(context.Context, int64) error
+	PurgeDeleted(context.Context, time.Time) (int64, error)
+	GetInactiveUsers(context.Context, time.Time) ([]*user.User, error)
 	Disable(context.Context, *user.DisableUserCommand) error
 	Search(context.Context, *user.SearchUsersQuery) (*user.SearchUserQueryResult, error)
+	Count(context.Context, *user.SearchUsersQuery) (*user.CountUsersResult, error)
+}
+
+type userAttribute struct {
+	ID      int64 `xorm:"pk autoincr 'id'"`
+	UserID  int64 `xorm:"user_id"`
+	Key     string
+	Value   string
+	Created time.Time
+	Updated time.Time
+}
+
+func (userAttribute) TableName() string {
+	return "user_attribute"
+}
+
+type userLabel struct {
+	ID      int64 `xorm:"pk autoincr 'id'"`
+	UserID  int64 `xorm:"user_id"`
+	Label   string
+	Created time.Time
+}
+
+func (userLabel) TableName() string {
+	return "user_label"
+}
+
+// effectiveAccessGrant is an access control list item granting a user a permission on a dashboard or folder
+type effectiveAccessGrant struct {
+	OrgID       int64  `xorm:"org_id"`
+	DashboardID int64  `xorm:"dashboard_id"`
+	UID         string `xorm:"uid"`
+	Title       string
+	IsFolder    bool
+	Permission  int
+	UserID      int64   `xorm:"user_id"`
+	Team        *string `xorm:"team"`
+	Role        *string `xorm:"role"`
+}
+
+// passwordHistoryEntry is a previous password of a user, encoded with the salt of the user
+type passwordHistoryEntry struct {
+	ID       int64 `xorm:"pk autoincr 'id'"`
+	UserID   int64 `xorm:"user_id"`
+	Password string
+	Created  time.Time
+}
+
+func (passwordHistoryEntry) TableName() string {
+	return "user_password_history"
+}
+
+// auditEntry is the row of an audit entry, its changes are stored as JSON
+type auditEntry struct {
+	ID                int64 `xorm:"pk autoincr 'id'"`
+	UserID            int64 `xorm:"user_id"`
+	ActorID           int64 `xorm:"actor_id"`
+	ActorLogin        string
+	ImpersonatorID    int64 `xorm:"impersonator_id"`
+	ImpersonatorLogin string
+	Action            string
+	Changes           string
+	Created           time.Time
+}
+
+func (auditEntry) TableName() string {
+	return "user_audit"
+}
+
+type loginHistoryEntry struct {
+	ID         int64 `xorm:"pk autoincr 'id'"`
+	UserID     int64 `xorm:"user_id"`
+	Login      string
+	AuthModule string
+	Result     string
+	Created    time.Time
+}
+
+func (loginHistoryEntry) TableName() string {
+	return "user_login_history"
+}
+
+// emailVerification is a token verifying an email address of a user, only the SHA-256 hash of the token is stored
+type emailVerification struct {
+	ID        int64 `xorm:"pk autoincr 'id'"`
+	UserID    int64 `xorm:"user_id"`
+	Email     string
+	TokenHash string
+	Created   time.Time
+	Expires   time.Time
+}
+
+func (emailVerification) TableName() string {
+	return "user_email_verification"
+}
+
+// alertRuleQuotaTarget is the quota target of alert rules, whose usage is only counted with unified alerting
+const alertRuleQuotaTarget = "alert_rule"
+
+// userQuota is the limit of a user overriding the default limit of a quota target
+type userQuota struct {
+	ID      int64 `xorm:"pk autoincr 'id'"`
+	OrgID   int64 `xorm:"org_id"`
+	UserID  int64 `xorm:"user_id"`
+	Target  string
+	Limit   int64
+	Created time.Time
+	Updated time.Time
+}
+
+func (userQuota) TableName() string {
+	return "quota"
 }
 
 type sqlStore struct {
@@ -81,7 +231,7 @@ func (ss *sqlStore) Insert(ctx context.Context, cmd *user.User) (int64, error) {
 
 func (ss *sqlStore) Get(ctx context.Context, usr *user.User) (*user.User, error) {
 	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
-		exists, err := sess.Where("email=? OR login=?", usr.Email, usr.Login).Get(usr)
+		exists, err := sess.Where(ss.notDeletedFilter()).Where("(email=? OR login=?)", usr.Email, usr.Login).Get(usr)
 		if !exists {
 			return user.ErrUserNotFound
 		}
@@ -96,22 +246,437 @@ func (ss *sqlStore) Get(ctx context.Context, usr *user.User) (*user.User, error)
 	return usr, nil
 }
 
+// Delete soft deletes the user: the user is hidden from queries but keeps its data, and can be restored until it is
+// purged. Its login and email are freed for new users, and kept in deleted_login and deleted_email to be restored
 func (ss *sqlStore) Delete(ctx context.Context, userID int64) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		now := time.Now()
+		// the original values are copied before login and email are overwritten, as MySQL assigns the columns in order
+		var rawSQL = "UPDATE " + ss.dialect.Quote("user") + " SET deleted_login = login, deleted_email = email, login = ?, email = ?, deleted_at = ?, updated = ? WHERE id = ? AND deleted_at IS NULL"
+		freed := deletedUserPlaceholder(userID)
+		res, err := sess.Exec(rawSQL, freed, freed, now, now, userID)
+		if err != nil {
+			return err
+		}
+		if affected, err := res.RowsAffected(); err != nil {
+			return err
+		} else if affected == 0 {
+			return user.ErrUserNotFound
+		}
+		sess.PublishAfterCommit(&events.UserDeleted{
+			Timestamp: now,
+			Id:        userID,
+		})
+		return nil
+	})
+}
+
+// deletedUserPlaceholder is the login and email of a soft deleted user, which are unique as they contain its ID
+func deletedUserPlaceholder(userID int64) string {
+	return fmt.Sprintf("deleted-user-%d", userID)
+}
+
+// ownedTables are the tables of resources recording the user who created them. Alert rules don't record their creator
+var ownedTables = []string{
+	"dashboard",
+	"dashboard_public",
+	"dashboard_public_playlist",
+	"dashboard_public_folder",
+	"library_element",
+	"data_source",
+	"api_key",
+}
+
+// TransferOwnership makes the successor the creator of the resources created by a user
+func (ss *sqlStore) TransferOwnership(ctx context.Context, userID, successorID int64) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for _, table := range ownedTables {
+			if _, err := sess.Exec("UPDATE "+table+" SET created_by = ? WHERE created_by = ?", successorID, userID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Restore restores a soft deleted user with its login and email, unless they have been taken by another user since
+// it was deleted
+func (ss *sqlStore) Restore(ctx context.Context, userID int64) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var deleted struct {
+			DeletedLogin *string
+			DeletedEmail *string
+		}
+		has, err := sess.Table("user").Where("id = ? AND deleted_at IS NOT NULL", userID).Where(ss.notServiceAccountFilter()).
+			Cols("deleted_login", "deleted_email").Get(&deleted)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return user.ErrUserNotFound
+		}
+		if deleted.DeletedLogin != nil && deleted.DeletedEmail != nil {
+			taken, err := sess.Table("user").Where("id <> ?", userID).
+				Where("(login = ? OR email = ?)", *deleted.DeletedLogin, *deleted.DeletedEmail).Exist()
+			if err != nil {
+				return err
+			}
+			if taken {
+				return user.ErrUserAlreadyExists
+			}
+		}
+
+		// users soft deleted before their login and email were freed have no deleted_login and deleted_email
+		var rawSQL = "UPDATE " + ss.dialect.Quote("user") + " SET login = COALESCE(deleted_login, login), email = COALESCE(deleted_email, email), deleted_login = NULL, deleted_email = NULL, deleted_at = NULL, updated = ? WHERE id = ? AND deleted_at IS NOT NULL AND " + ss.notServiceAccountFilter()
+		res, err := sess.Exec(rawSQL, time.Now(), userID)
+		if err != nil {
+			return err
+		}
+		if affected, err := res.RowsAffected(); err != nil {
+			return err
+		} else if affected == 0 {
+			return user.ErrUserNotFound
+		}
+		return nil
+	})
+}
+
+// RecordFailedLogin counts a failed login of a user, and locks the user for lockoutDuration once it reaches
+// maxAttempts consecutive failed logins
+func (ss *sqlStore) RecordFailedLogin(ctx context.Context, userID int64, maxAttempts int, lockoutDuration time.Duration) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("UPDATE "+ss.dialect.Quote("user")+" SET failed_login_attempts = failed_login_attempts + 1 WHERE id = ?", userID); err != nil {
+			return err
+		}
+
+		var attempts int
+		if _, err := sess.Table("user").Cols("failed_login_attempts").Where("id = ?", userID).Get(&attempts); err != nil {
+			return err
+		}
+		if attempts < maxAttempts {
+			return nil
+		}
+
+		_, err := sess.Exec("UPDATE "+ss.dialect.Quote("user")+" SET failed_login_attempts = 0, locked_until = ? WHERE id = ?", time.Now().Add(lockoutDuration), userID)
+		return err
+	})
+}
+
+func (ss *sqlStore) IsLocked(ctx context.Context, userID int64) (bool, error) {
+	var locked bool
 	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
-		var rawSQL = "DELETE FROM " + ss.dialect.Quote("user") + " WHERE id = ?"
-		_, err := sess.Exec(rawSQL, userID)
+		var err error
+		locked, err = sess.Table("user").Where("id = ? AND locked_until > ?", userID, time.Now()).Exist()
 		return err
 	})
+	return locked, err
+}
+
+// ResetLockout unlocks a user and resets its failed logins
+func (ss *sqlStore) ResetLockout(ctx context.Context, userID int64) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var rawSQL = "UPDATE " + ss.dialect.Quote("user") + " SET failed_login_attempts = 0, locked_until = NULL WHERE id = ? AND " + ss.notServiceAccountFilter()
+		res, err := sess.Exec(rawSQL, userID)
+		if err != nil {
+			return err
+		}
+		if affected, err := res.RowsAffected(); err != nil {
+			return err
+		} else if affected == 0 {
+			return user.ErrUserNotFound
+		}
+		return nil
+	})
+}
+
+// Anonymize replaces the personal data of a user with placeholders, disables it and deletes its auth info and
+// sessions. The user keeps its ID, so the dashboards, annotations and other resources referencing it stay consistent
+func (ss *sqlStore) Anonymize(ctx context.Context, userID int64) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var usr user.User
+		has, err := sess.ID(userID).Where(ss.notServiceAccountFilter()).Get(&usr)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return user.ErrUserNotFound
+		}
+
+		// logins and emails are unique, the placeholders include the ID of the user
+		placeholder := fmt.Sprintf("anonymized-%d", userID)
+		var rawSQL = "UPDATE " + ss.dialect.Quote("user") + ` SET login = ?, email = ?, name = ?, company = '', password = '',
+			email_verified = ?, is_disabled = ?, updated = ? WHERE id = ?`
+		if _, err := sess.Exec(rawSQL, placeholder, placeholder, "Anonymized user", ss.dialect.BooleanStr(false),
+			ss.dialect.BooleanStr(true), time.Now(), userID); err != nil {
+			return err
+		}
+
+		for _, sql := range []string{
+			"DELETE FROM user_auth WHERE user_id = ?",
+			"DELETE FROM user_auth_token WHERE user_id = ?",
+			"DELETE FROM user_password_history WHERE user_id = ?",
+			"DELETE FROM user_email_verification WHERE user_id = ?",
+		} {
+			if _, err := sess.Exec(sql, userID); err != nil {
+				return err
+			}
+		}
+		// pending invites hold the email of the user
+		_, err = sess.Exec("DELETE FROM temp_user WHERE email = ?", usr.Email)
+		return err
+	})
+}
+
+// SetAttribute sets an attribute of a user, or removes it when the value is empty
+func (ss *sqlStore) SetAttribute(ctx context.Context, cmd *user.SetUserAttributeCommand) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if has, err := sess.Table("user").Where("id = ?", cmd.UserID).Where(ss.notServiceAccountFilter()).Where(ss.notDeletedFilter()).Exist(); err != nil {
+			return err
+		} else if !has {
+			return user.ErrUserNotFound
+		}
+
+		if cmd.Value == "" {
+			_, err := sess.Delete(&userAttribute{UserID: cmd.UserID, Key: cmd.Key})
+			return err
+		}
+
+		attribute := userAttribute{UserID: cmd.UserID, Key: cmd.Key}
+		has, err := sess.Get(&attribute)
+		if err != nil {
+			return err
+		}
+
+		attribute.Value = cmd.Value
+		attribute.Updated = time.Now()
+		if has {
+			_, err = sess.ID(attribute.ID).Cols("value", "updated").Update(&attribute)
+			return err
+		}
+		attribute.Created = attribute.Updated
+		_, err = sess.Insert(&attribute)
+		return err
+	})
+}
+
+// GetAttributes returns the attributes of a user
+func (ss *sqlStore) GetAttributes(ctx context.Context, query *user.GetUserAttributesQuery) (map[string]string, error) {
+	attributes := make(map[string]string)
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		if has, err := sess.Table("user").Where("id = ?", query.UserID).Where(ss.notServiceAccountFilter()).Where(ss.notDeletedFilter()).Exist(); err != nil {
+			return err
+		} else if !has {
+			return user.ErrUserNotFound
+		}
+
+		var rows []userAttribute
+		if err := sess.Where("user_id = ?", query.UserID).Find(&rows); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			attributes[row.Key] = row.Value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attributes, nil
+}
+
+// GetEffectiveAccess returns the organizations and teams of a user, and its permissions on dashboards and folders
+// granted by access control lists directly, through its teams or through its role in their organization
+func (ss *sqlStore) GetEffectiveAccess(ctx context.Context, userID int64) (*user.EffectiveAccessReport, error) {
+	var report *user.EffectiveAccessReport
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		usr := user.User{}
+		if has, err := sess.ID(userID).Where(ss.notDeletedFilter()).Get(&usr); err != nil {
+			return err
+		} else if !has {
+			return user.ErrUserNotFound
+		}
+
+		var orgs []*struct {
+			OrgID int64 `xorm:"org_id"`
+			Name  string
+			Role  roletype.RoleType
+		}
+		if err := sess.SQL(`SELECT org_user.org_id, org.name, org_user.role FROM org_user
+			INNER JOIN org ON org.id = org_user.org_id
+			WHERE org_user.user_id = ? ORDER BY org.name ASC`, userID).Find(&orgs); err != nil {
+			return err
+		}
+
+		var teams []*struct {
+			OrgID      int64 `xorm:"org_id"`
+			TeamID     int64 `xorm:"team_id"`
+			Name       string
+			Permission int
+		}
+		if err := sess.SQL(`SELECT team_member.org_id, team_member.team_id, team.name, team_member.permission FROM team_member
+			INNER JOIN team ON team.id = team_member.team_id
+			WHERE team_member.user_id = ? ORDER BY team.name ASC`, userID).Find(&teams); err != nil {
+			return err
+		}
+
+		var grants []*effectiveAccessGrant
+		if err := sess.SQL(`SELECT da.org_id, da.dashboard_id, d.uid, d.title, d.is_folder, da.permission, da.user_id, team.name AS team, da.role
+			FROM dashboard_acl AS da
+			INNER JOIN dashboard AS d ON d.id = da.dashboard_id
+			LEFT JOIN team ON team.id = da.team_id
+			WHERE da.user_id = ?
+				OR da.team_id IN (SELECT team_id FROM team_member WHERE user_id = ?)
+				OR da.role IN (SELECT role FROM org_user WHERE org_user.user_id = ? AND org_user.org_id = da.org_id)
+			ORDER BY d.title ASC, d.id ASC, da.id ASC`, userID, userID, userID).Find(&grants); err != nil {
+			return err
+		}
+
+		report = &user.EffectiveAccessReport{
+			UserID:         usr.ID,
+			Login:          usr.Login,
+			IsGrafanaAdmin: usr.IsAdmin,
+			Orgs:           make([]*user.EffectiveOrgAccess, 0, len(orgs)),
+		}
+		byOrgID := make(map[int64]*user.EffectiveOrgAccess, len(orgs))
+		for _, o := range orgs {
+			access := &user.EffectiveOrgAccess{
+				OrgID:      o.OrgID,
+				Name:       o.Name,
+				Role:       o.Role,
+				Teams:      make([]*user.EffectiveTeamAccess, 0),
+				Dashboards: make([]*user.EffectiveDashboardAccess, 0),
+			}
+			report.Orgs = append(report.Orgs, access)
+			byOrgID[o.OrgID] = access
+		}
+		for _, t := range teams {
+			if access, ok := byOrgID[t.OrgID]; ok {
+				access.Teams = append(access.Teams, &user.EffectiveTeamAccess{TeamID: t.TeamID, Name: t.Name, Permission: t.Permission})
+			}
+		}
+
+		dashboards := make(map[int64]*user.EffectiveDashboardAccess)
+		for _, grant := range grants {
+			access, ok := byOrgID[grant.OrgID]
+			if !ok {
+				continue
+			}
+			dashboard, ok := dashboards[grant.DashboardID]
+			if !ok {
+				dashboard = &user.EffectiveDashboardAccess{
+					DashboardID: grant.DashboardID,
+					UID:         grant.UID,
+					Title:       grant.Title,
+					IsFolder:    grant.IsFolder,
+					Sources:     make([]string, 0, 1),
+				}
+				dashboards[grant.DashboardID] = dashboard
+				access.Dashboards = append(access.Dashboards, dashboard)
+			}
+			if grant.Permission > dashboard.Permission {
+				dashboard.Permission = grant.Permission
+				dashboard.PermissionName = models.PermissionType(grant.Permission).String()
+			}
+
+			switch {
+			case grant.UserID == userID:
+				dashboard.Sources = append(dashboard.Sources, "user")
+			case grant.Team != nil:
+				dashboard.Sources = append(dashboard.Sources, "team:"+*grant.Team)
+			case grant.Role != nil:
+				dashboard.Sources = append(dashboard.Sources, "role:"+*grant.Role)
+			}
+		}
+		return nil
+	})
 	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// SetLabels replaces the labels of a user
+func (ss *sqlStore) SetLabels(ctx context.Context, cmd *user.SetUserLabelsCommand) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if has, err := sess.Table("user").Where("id = ?", cmd.UserID).Where(ss.notServiceAccountFilter()).Where(ss.notDeletedFilter()).Exist(); err != nil {
+			return err
+		} else if !has {
+			return user.ErrUserNotFound
+		}
+
+		if _, err := sess.Exec("DELETE FROM user_label WHERE user_id = ?", cmd.UserID); err != nil {
+			return err
+		}
+		if len(cmd.Labels) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		labels := make([]*userLabel, 0, len(cmd.Labels))
+		for _, label := range cmd.Labels {
+			labels = append(labels, &userLabel{UserID: cmd.UserID, Label: label, Created: now})
+		}
+		_, err := sess.InsertMulti(labels)
 		return err
+	})
+}
+
+// GetLabels returns the labels of a user in alphabetical order
+func (ss *sqlStore) GetLabels(ctx context.Context, query *user.GetUserLabelsQuery) ([]string, error) {
+	labels := make([]string, 0)
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		if has, err := sess.Table("user").Where("id = ?", query.UserID).Where(ss.notServiceAccountFilter()).Where(ss.notDeletedFilter()).Exist(); err != nil {
+			return err
+		} else if !has {
+			return user.ErrUserNotFound
+		}
+
+		return sess.Table("user_label").Where("user_id = ?", query.UserID).Asc("label").Cols("label").Find(&labels)
+	})
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return labels, nil
+}
+
+// PurgeDeleted permanently deletes the users soft deleted before olderThan, and returns the number of purged users
+func (ss *sqlStore) PurgeDeleted(ctx context.Context, olderThan time.Time) (int64, error) {
+	var userIDs []int64
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("user").Where("deleted_at IS NOT NULL AND deleted_at < ?", olderThan).Cols("id").Find(&userIDs)
+	})
+	if err != nil || len(userIDs) == 0 {
+		return 0, err
+	}
+
+	if err := ss.BatchDeleteUsers(ctx, userIDs); err != nil {
+		return 0, err
+	}
+	return int64(len(userIDs)), nil
+}
+
+// GetInactiveUsers returns the enabled users created and last seen before inactiveSince, except Grafana server admins.
+// Only their ID, login and email are loaded
+func (ss *sqlStore) GetInactiveUsers(ctx context.Context, inactiveSince time.Time) ([]*user.User, error) {
+	users := make([]*user.User, 0)
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("user").Cols("id", "login", "email").
+			Where(ss.notServiceAccountFilter()).Where(ss.notDeletedFilter()).
+			Where("is_disabled = ? AND is_admin = ?", ss.dialect.BooleanStr(false), ss.dialect.BooleanStr(false)).
+			// users who never logged in have a last seen time in the past, they are inactive from their creation
+			Where("last_seen_at < ? AND created < ?", inactiveSince, inactiveSince).
+			OrderBy("id").Find(&users)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
 }
 
 func (ss *sqlStore) GetNotServiceAccount(ctx context.Context, userID int64) (*user.User, error) {
 	usr := user.User{ID: userID}
 	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
-		has, err := sess.Where(ss.notServiceAccountFilter()).Get(&usr)
+		has, err := sess.Where(ss.notServiceAccountFilter()).Where(ss.notDeletedFilter()).Get(&usr)
 		if err != nil {
 			return err
 		}
@@ -129,6 +694,7 @@ func (ss *sqlStore) GetByID(ctx context.Context, userID int64) (*user.User, erro
 	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
 		has, err := sess.ID(&userID).
 			Where(ss.notServiceAccountFilter()).
+			Where(ss.notDeletedFilter()).
 			Get(&usr)
 
 		if err != nil {
@@ -141,16 +707,48 @@ func (ss *sqlStore) GetByID(ctx context.Context, userID int64) (*user.User, erro
 	return &usr, err
 }
 
+// GetByIDs returns the users of a list of IDs keyed by their ID, loading them in queries of getByIDsChunkSize users.
+// Service accounts and users which don't exist or are deleted are left out
+func (ss *sqlStore) GetByIDs(ctx context.Context, userIDs []int64) (map[int64]*user.User, error) {
+	users := make(map[int64]*user.User, len(userIDs))
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		for start := 0; start < len(userIDs); start += getByIDsChunkSize {
+			end := start + getByIDsChunkSize
+			if end > len(userIDs) {
+				end = len(userIDs)
+			}
+
+			chunk := make([]*user.User, 0, end-start)
+			if err := sess.In("id", userIDs[start:end]).Where(ss.notServiceAccountFilter()).
+				Where(ss.notDeletedFilter()).Find(&chunk); err != nil {
+				return err
+			}
+			for _, usr := range chunk {
+				users[usr.ID] = usr
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 func (ss *sqlStore) notServiceAccountFilter() string {
 	return fmt.Sprintf("%s.is_service_account = %s",
 		ss.dialect.Quote("user"),
 		ss.dialect.BooleanStr(false))
 }
 
+func (ss *sqlStore) notDeletedFilter() string {
+	return fmt.Sprintf("%s.deleted_at IS NULL", ss.dialect.Quote("user"))
+}
+
 func (ss *sqlStore) CaseInsensitiveLoginConflict(ctx context.Context, login, email string) error {
 	users := make([]user.User, 0)
 	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
-		if err := sess.Where("LOWER(email)=LOWER(?) OR LOWER(login)=LOWER(?)",
+		if err := sess.Where(ss.notDeletedFilter()).Where("(LOWER(email)=LOWER(?) OR LOWER(login)=LOWER(?))",
 			email, login).Find(&users); err != nil {
 			return err
 		}
@@ -163,6 +761,65 @@ func (ss *sqlStore) CaseInsensitiveLoginConflict(ctx context.Context, login, ema
 	return err
 }
 
+// GetConflicts returns the pairs of users whose logins or emails only differ by their case, ordered by their IDs
+func (ss *sqlStore) GetConflicts(ctx context.Context) ([]*user.UserConflict, error) {
+	users := make([]user.ConflictingUserDTO, 0)
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		filter := ss.notServiceAccountFilter() + " AND " + ss.notDeletedFilter()
+		duplicates := func(col string) string {
+			return "SELECT LOWER(" + col + ") FROM " + ss.dialect.Quote("user") + " WHERE " + filter +
+				" GROUP BY LOWER(" + col + ") HAVING COUNT(*) > 1"
+		}
+		rawSQL := "SELECT id, login, email, is_disabled, last_seen_at FROM " + ss.dialect.Quote("user") +
+			" WHERE " + filter + " AND (LOWER(login) IN (" + duplicates("login") + ") OR LOWER(email) IN (" +
+			duplicates("email") + ")) ORDER BY id"
+		return sess.SQL(rawSQL).Find(&users)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// users are paired within the groups of users sharing a login or an email, a pair sharing both is a single conflict
+	conflicts := make([]*user.UserConflict, 0)
+	pairs := make(map[[2]int64]*user.UserConflict)
+	for _, byLogin := range []bool{true, false} {
+		key := func(u user.ConflictingUserDTO) string {
+			if byLogin {
+				return strings.ToLower(u.Login)
+			}
+			return strings.ToLower(u.Email)
+		}
+		groups := make(map[string][]user.ConflictingUserDTO)
+		for _, u := range users {
+			groups[key(u)] = append(groups[key(u)], u)
+		}
+		for _, u := range users {
+			for _, other := range groups[key(u)] {
+				if other.ID <= u.ID {
+					continue
+				}
+				conflict, ok := pairs[[2]int64{u.ID, other.ID}]
+				if !ok {
+					conflict = &user.UserConflict{Users: [2]user.ConflictingUserDTO{u, other}}
+					pairs[[2]int64{u.ID, other.ID}] = conflict
+					conflicts = append(conflicts, conflict)
+				}
+				if byLogin {
+					conflict.LoginConflict = true
+				} else {
+					conflict.EmailConflict = true
+				}
+			}
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		a, b := conflicts[i].Users, conflicts[j].Users
+		return a[0].ID < b[0].ID || (a[0].ID == b[0].ID && a[1].ID < b[1].ID)
+	})
+	return conflicts, nil
+}
+
 func (ss *sqlStore) GetByLogin(ctx context.Context, query *user.GetUserByLoginQuery) (*user.User, error) {
 	usr := &user.User{}
 	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
@@ -181,7 +838,7 @@ func (ss *sqlStore) GetByLogin(ctx context.Context, query *user.GetUserByLoginQu
 			if ss.cfg.CaseInsensitiveLogin {
 				where = "LOWER(email)=LOWER(?)"
 			}
-			has, err = sess.Where(ss.notServiceAccountFilter()).Where(where, query.LoginOrEmail).Get(usr)
+			has, err = sess.Where(ss.notServiceAccountFilter()).Where(ss.notDeletedFilter()).Where(where, query.LoginOrEmail).Get(usr)
 
 			if err != nil {
 				return err
@@ -194,7 +851,7 @@ func (ss *sqlStore) GetByLogin(ctx context.Context, query *user.GetUserByLoginQu
 			if ss.cfg.CaseInsensitiveLogin {
 				where = "LOWER(login)=LOWER(?)"
 			}
-			has, err = sess.Where(ss.notServiceAccountFilter()).Where(where, query.LoginOrEmail).Get(usr)
+			has, err = sess.Where(ss.notServiceAccountFilter()).Where(ss.notDeletedFilter()).Where(where, query.LoginOrEmail).Get(usr)
 		}
 
 		if err != nil {
@@ -225,7 +882,7 @@ func (ss *sqlStore) GetByEmail(ctx context.Context, query *user.GetUserByEmailQu
 			where = "LOWER(email)=LOWER(?)"
 		}
 
-		has, err := sess.Where(ss.notServiceAccountFilter()).Where(where, query.Email).Get(usr)
+		has, err := sess.Where(ss.notServiceAccountFilter()).Where(ss.notDeletedFilter()).Where(where, query.Email).Get(usr)
 
 		if err != nil {
 			return err
@@ -249,7 +906,7 @@ func (ss *sqlStore) GetByEmail(ctx context.Context, query *user.GetUserByEmailQu
 func (ss *sqlStore) userCaseInsensitiveLoginConflict(ctx context.Context, sess *db.Session, login, email string) error {
 	users := make([]user.User, 0)
 
-	if err := sess.Where("LOWER(email)=LOWER(?) OR LOWER(login)=LOWER(?)",
+	if err := sess.Where(ss.notDeletedFilter()).Where("(LOWER(email)=LOWER(?) OR LOWER(login)=LOWER(?))",
 		email, login).Find(&users); err != nil {
 		return err
 	}
@@ -268,7 +925,15 @@ func (ss *sqlStore) Update(ctx context.Context, cmd *user.UpdateUserCommand) err
 	}
 
 	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
-		user := user.User{
+		// verifications are of an email address, a new address has to be verified again
+		if cmd.Email != "" {
+			rawSQL := "UPDATE " + ss.dialect.Quote("user") + " SET email_verified = ? WHERE id = ? AND email <> ?"
+			if _, err := sess.Exec(rawSQL, ss.dialect.BooleanStr(false), cmd.UserID, cmd.Email); err != nil {
+				return err
+			}
+		}
+
+		usr := user.User{
 			Name:    cmd.Name,
 			Email:   cmd.Email,
 			Login:   cmd.Login,
@@ -276,22 +941,22 @@ func (ss *sqlStore) Update(ctx context.Context, cmd *user.UpdateUserCommand) err
 			Updated: time.Now(),
 		}
 
-		if _, err := sess.ID(cmd.UserID).Where(ss.notServiceAccountFilter()).Update(&user); err != nil {
+		if err := ss.updateVersioned(sess, cmd.UserID, cmd.Version, &usr, ss.notServiceAccountFilter()); err != nil {
 			return err
 		}
 
 		if ss.cfg.CaseInsensitiveLogin {
-			if err := ss.userCaseInsensitiveLoginConflict(ctx, sess, user.Login, user.Email); err != nil {
+			if err := ss.userCaseInsensitiveLoginConflict(ctx, sess, usr.Login, usr.Email); err != nil {
 				return err
 			}
 		}
 
 		sess.PublishAfterCommit(&events.UserUpdated{
-			Timestamp: user.Created,
-			Id:        user.ID,
-			Name:      user.Name,
-			Login:     user.Login,
-			Email:     user.Email,
+			Timestamp: usr.Updated,
+			Id:        cmd.UserID,
+			Name:      usr.Name,
+			Login:     usr.Login,
+			Email:     usr.Email,
 		})
 
 		return nil
@@ -305,7 +970,262 @@ func (ss *sqlStore) ChangePassword(ctx context.Context, cmd *user.ChangeUserPass
 			Updated:  time.Now(),
 		}
 
-		_, err := sess.ID(cmd.UserID).Where(ss.notServiceAccountFilter()).Update(&user)
+		affected, err := sess.ID(cmd.UserID).Where(ss.notServiceAccountFilter()).Update(&user)
+		if err != nil || affected == 0 {
+			return err
+		}
+
+		sess.PublishAfterCommit(&events.UserPasswordChanged{
+			Timestamp: user.Updated,
+			Id:        cmd.UserID,
+		})
+		if ss.cfg.PasswordHistoryCount <= 0 {
+			return nil
+		}
+
+		// keep the password history of the user to the configured number of passwords
+		if _, err := sess.Insert(&passwordHistoryEntry{UserID: cmd.UserID, Password: cmd.NewPassword, Created: user.Updated}); err != nil {
+			return err
+		}
+		var ids []int64
+		if err := sess.Table("user_password_history").Cols("id").Where("user_id = ?", cmd.UserID).Desc("id").Find(&ids); err != nil {
+			return err
+		}
+		if len(ids) > ss.cfg.PasswordHistoryCount {
+			_, err = sess.In("id", ids[ss.cfg.PasswordHistoryCount:]).Delete(&passwordHistoryEntry{})
+		}
+		return err
+	})
+}
+
+// GetPasswordHistory returns the last previous passwords of a user, most recent first
+func (ss *sqlStore) GetPasswordHistory(ctx context.Context, userID int64, limit int) ([]string, error) {
+	var entries []passwordHistoryEntry
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("user_id = ?", userID).Desc("id").Limit(limit).Find(&entries)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	passwords := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		passwords = append(passwords, entry.Password)
+	}
+	return passwords, nil
+}
+
+func (ss *sqlStore) InsertAuditEntry(ctx context.Context, entry *user.AuditEntry) error {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		row := auditEntry{
+			UserID:            entry.UserID,
+			ActorID:           entry.ActorID,
+			ActorLogin:        entry.ActorLogin,
+			ImpersonatorID:    entry.ImpersonatorID,
+			ImpersonatorLogin: entry.ImpersonatorLogin,
+			Action:            string(entry.Action),
+			Changes:           string(changes),
+			Created:           entry.Created,
+		}
+		if _, err := sess.Insert(&row); err != nil {
+			return err
+		}
+		entry.ID = row.ID
+		return nil
+	})
+}
+
+func (ss *sqlStore) GetAuditEntries(ctx context.Context, query *user.GetAuditEntriesQuery) (*user.AuditEntriesResult, error) {
+	result := &user.AuditEntriesResult{Entries: make([]*user.AuditEntry, 0), Page: query.Page, PerPage: query.Limit}
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		where, args := "user_id = ?", []interface{}{query.UserID}
+		if query.Action != "" {
+			where, args = where+" AND action = ?", append(args, string(query.Action))
+		}
+
+		count, err := sess.Table("user_audit").Where(where, args...).Count()
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+
+		var rows []auditEntry
+		if err := sess.Where(where, args...).Desc("id").Limit(query.Limit, (query.Page-1)*query.Limit).Find(&rows); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			entry := &user.AuditEntry{
+				ID:                row.ID,
+				UserID:            row.UserID,
+				ActorID:           row.ActorID,
+				ActorLogin:        row.ActorLogin,
+				ImpersonatorID:    row.ImpersonatorID,
+				ImpersonatorLogin: row.ImpersonatorLogin,
+				Action:            user.AuditAction(row.Action),
+				Created:           row.Created,
+			}
+			if err := json.Unmarshal([]byte(row.Changes), &entry.Changes); err != nil {
+				return err
+			}
+			result.Entries = append(result.Entries, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (ss *sqlStore) InsertLoginHistory(ctx context.Context, entry *user.LoginHistoryEntry) error {
+	return ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		row := loginHistoryEntry{
+			UserID:     entry.UserID,
+			Login:      entry.Login,
+			AuthModule: entry.AuthModule,
+			Result:     string(entry.Result),
+			Created:    entry.Created,
+		}
+		if _, err := sess.Insert(&row); err != nil {
+			return err
+		}
+		entry.ID = row.ID
+		return nil
+	})
+}
+
+func (ss *sqlStore) GetLoginHistory(ctx context.Context, query *user.GetLoginHistoryQuery) (*user.LoginHistoryResult, error) {
+	result := &user.LoginHistoryResult{Entries: make([]*user.LoginHistoryEntry, 0), Page: query.Page, PerPage: query.Limit}
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		whereConditions := make([]string, 0)
+		whereParams := make([]interface{}, 0)
+		if query.UserID != 0 {
+			whereConditions = append(whereConditions, "user_id = ?")
+			whereParams = append(whereParams, query.UserID)
+		}
+		if query.AuthModule != "" {
+			whereConditions = append(whereConditions, "auth_module = ?")
+			whereParams = append(whereParams, query.AuthModule)
+		}
+		if query.Result != "" {
+			whereConditions = append(whereConditions, "result = ?")
+			whereParams = append(whereParams, string(query.Result))
+		}
+		if !query.From.IsZero() {
+			whereConditions = append(whereConditions, "created >= ?")
+			whereParams = append(whereParams, query.From)
+		}
+		if !query.To.IsZero() {
+			whereConditions = append(whereConditions, "created < ?")
+			whereParams = append(whereParams, query.To)
+		}
+
+		countSess := sess.Table("user_login_history")
+		if len(whereConditions) > 0 {
+			countSess.Where(strings.Join(whereConditions, " AND "), whereParams...)
+		}
+		count, err := countSess.Count()
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+
+		if len(whereConditions) > 0 {
+			sess.Where(strings.Join(whereConditions, " AND "), whereParams...)
+		}
+		var rows []loginHistoryEntry
+		if err := sess.Desc("id").Limit(query.Limit, (query.Page-1)*query.Limit).Find(&rows); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			result.Entries = append(result.Entries, &user.LoginHistoryEntry{
+				ID:         row.ID,
+				UserID:     row.UserID,
+				Login:      row.Login,
+				AuthModule: row.AuthModule,
+				Result:     user.LoginResult(row.Result),
+				Created:    row.Created,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PurgeLoginHistory deletes the logins recorded before olderThan, and returns the number of deleted logins
+func (ss *sqlStore) PurgeLoginHistory(ctx context.Context, olderThan time.Time) (int64, error) {
+	var purged int64
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM user_login_history WHERE created < ?", olderThan)
+		if err != nil {
+			return err
+		}
+		purged, err = res.RowsAffected()
+		return err
+	})
+	return purged, err
+}
+
+// InTransaction runs fn in a transaction, which the store methods called with its context join
+func (ss *sqlStore) InTransaction(ctx context.Context, fn func(context.Context) error) error {
+	return ss.db.InTransaction(ctx, fn)
+}
+
+// CreateEmailVerification stores a verification token of a user, removing the previous tokens of the user
+func (ss *sqlStore) CreateEmailVerification(ctx context.Context, verification *emailVerification) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("DELETE FROM user_email_verification WHERE user_id = ?", verification.UserID); err != nil {
+			return err
+		}
+		_, err := sess.Insert(verification)
+		return err
+	})
+}
+
+func (ss *sqlStore) GetEmailVerification(ctx context.Context, tokenHash string) (*emailVerification, error) {
+	var verification emailVerification
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("token_hash = ?", tokenHash).Get(&verification)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return user.ErrEmailVerificationTokenInvalid
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
+
+// SetEmailVerified marks the email address of a user as verified and removes the verification tokens of the user. It
+// fails with ErrEmailVerificationTokenInvalid when the email address is no longer the address of the user
+func (ss *sqlStore) SetEmailVerified(ctx context.Context, userID int64, email string) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		rawSQL := "UPDATE " + ss.dialect.Quote("user") + " SET email_verified = ?, updated = ? WHERE id = ? AND email = ?"
+		res, err := sess.Exec(rawSQL, ss.dialect.BooleanStr(true), time.Now(), userID, email)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return user.ErrEmailVerificationTokenInvalid
+		}
+
+		_, err = sess.Exec("DELETE FROM user_email_verification WHERE user_id = ?", userID)
 		return err
 	})
 }
@@ -322,6 +1242,35 @@ func (ss *sqlStore) UpdateLastSeenAt(ctx context.Context, cmd *user.UpdateUserLa
 	})
 }
 
+// UpdateLastLogin sets the time and the client of the last login of a user
+func (ss *sqlStore) UpdateLastLogin(ctx context.Context, userID int64, client *user.LoginClient, loginAt time.Time) error {
+	return ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(userID).Cols("last_login_at", "last_login_ip", "last_login_user_agent").Update(&user.User{
+			LastLoginAt:        &loginAt,
+			LastLoginIP:        client.IP,
+			LastLoginUserAgent: client.UserAgent,
+		})
+		return err
+	})
+}
+
+// BatchUpdateLastSeenAt sets the last seen time of users with bulk updates of batchUpdateLastSeenChunkSize users
+func (ss *sqlStore) BatchUpdateLastSeenAt(ctx context.Context, userIDs []int64, lastSeenAt time.Time) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for start := 0; start < len(userIDs); start += batchUpdateLastSeenChunkSize {
+			end := start + batchUpdateLastSeenChunkSize
+			if end > len(userIDs) {
+				end = len(userIDs)
+			}
+
+			if _, err := sess.Table("user").In("id", userIDs[start:end]).Cols("last_seen_at").Update(&user.User{LastSeenAt: lastSeenAt}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (ss *sqlStore) GetSignedInUser(ctx context.Context, query *user.GetSignedInUserQuery) (*user.SignedInUser, error) {
 	var signedInUser user.SignedInUser
 	err := ss.db.WithDbSession(ctx, func(dbSess *db.Session) error {
@@ -348,24 +1297,25 @@ func (ss *sqlStore) GetSignedInUser(ctx context.Context, query *user.GetSignedIn
 		FROM ` + ss.dialect.Quote("user") + ` as u
 		LEFT OUTER JOIN user_auth on user_auth.user_id = u.id
 		LEFT OUTER JOIN org_user on org_user.org_id = ` + orgId + ` and org_user.user_id = u.id
-		LEFT OUTER JOIN org on org.id = org_user.org_id `
+		LEFT OUTER JOIN org on org.id = org_user.org_id
+		WHERE u.deleted_at IS NULL `
 
 		sess := dbSess.Table("user")
 		sess = sess.Context(ctx)
 		switch {
 		case query.UserID > 0:
-			sess.SQL(rawSQL+"WHERE u.id=?", query.UserID)
+			sess.SQL(rawSQL+"AND u.id=?", query.UserID)
 		case query.Login != "":
 			if ss.cfg.CaseInsensitiveLogin {
-				sess.SQL(rawSQL+"WHERE LOWER(u.login)=LOWER(?)", query.Login)
+				sess.SQL(rawSQL+"AND LOWER(u.login)=LOWER(?)", query.Login)
 			} else {
-				sess.SQL(rawSQL+"WHERE u.login=?", query.Login)
+				sess.SQL(rawSQL+"AND u.login=?", query.Login)
 			}
 		case query.Email != "":
 			if ss.cfg.CaseInsensitiveLogin {
-				sess.SQL(rawSQL+"WHERE LOWER(u.email)=LOWER(?)", query.Email)
+				sess.SQL(rawSQL+"AND LOWER(u.email)=LOWER(?)", query.Email)
 			} else {
-				sess.SQL(rawSQL+"WHERE u.email=?", query.Email)
+				sess.SQL(rawSQL+"AND u.email=?", query.Email)
 			}
 		}
 		has, err := sess.Get(&signedInUser)
@@ -388,18 +1338,51 @@ func (ss *sqlStore) GetSignedInUser(ctx context.Context, query *user.GetSignedIn
 	return &signedInUser, err
 }
 
-func (ss *sqlStore) UpdateUser(ctx context.Context, user *user.User) error {
+// UpdateUser updates the non-zero fields of a user. A user with a version is only updated if it still has the
+// version, its version is then incremented
+func (ss *sqlStore) UpdateUser(ctx context.Context, usr *user.User) error {
 	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
-		_, err := sess.ID(user.ID).Update(user)
-		return err
+		if err := ss.updateVersioned(sess, usr.ID, usr.Version, usr); err != nil {
+			return err
+		}
+		if usr.Version > 0 {
+			usr.Version++
+		}
+		return nil
 	})
 }
 
+// updateVersioned updates the non-zero fields of a user matching the conditions and increments its version. When
+// version is not 0, the user is only updated if it still has the version, and ErrUserVersionConflict is returned
+// when it has another one
+func (ss *sqlStore) updateVersioned(sess *db.Session, userID int64, version int, usr *user.User, conditions ...string) error {
+	update := sess.ID(userID).Omit("version").Incr("version")
+	for _, condition := range conditions {
+		update = update.And(condition)
+	}
+	if version > 0 {
+		update = update.And("version = ?", version)
+	}
+	affected, err := update.Update(usr)
+	if err != nil || affected > 0 || version == 0 {
+		return err
+	}
+
+	exists, err := sess.Table("user").Where("id = ?", userID).Exist()
+	if err != nil {
+		return err
+	}
+	if exists {
+		return user.ErrUserVersionConflict
+	}
+	return nil
+}
+
 func (ss *sqlStore) GetProfile(ctx context.Context, query *user.GetUserProfileQuery) (*user.UserProfileDTO, error) {
 	var usr user.User
 	var userProfile user.UserProfileDTO
 	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
-		has, err := sess.ID(query.UserID).Where(ss.notServiceAccountFilter()).Get(&usr)
+		has, err := sess.ID(query.UserID).Where(ss.notServiceAccountFilter()).Where(ss.notDeletedFilter()).Get(&usr)
 
 		if err != nil {
 			return err
@@ -409,6 +1392,7 @@ func (ss *sqlStore) GetProfile(ctx context.Context, query *user.GetUserProfileQu
 
 		userProfile = user.UserProfileDTO{
 			ID:             usr.ID,
+			Version:        usr.Version,
 			Name:           usr.Name,
 			Email:          usr.Email,
 			Login:          usr.Login,
@@ -419,12 +1403,96 @@ func (ss *sqlStore) GetProfile(ctx context.Context, query *user.GetUserProfileQu
 			UpdatedAt:      usr.Updated,
 			CreatedAt:      usr.Created,
 		}
+		if ss.cfg.LastLoginClientTracking == "hashed" || ss.cfg.LastLoginClientTracking == "raw" {
+			userProfile.LastLoginAt = usr.LastLoginAt
+			userProfile.LastLoginIP = usr.LastLoginIP
+			userProfile.LastLoginUserAgent = usr.LastLoginUserAgent
+		}
+		userProfile.LockedFields = usr.LockedFields()
 
 		return err
 	})
 	return &userProfile, err
 }
 
+// GetQuotas returns the quotas of a user for the targets of the default limits, with the limits set for the user
+// overriding them. Alert rules are only counted when unified alerting is enabled
+func (ss *sqlStore) GetQuotas(ctx context.Context, userID int64, defaultLimits map[string]int64, unifiedAlertingEnabled bool) ([]*user.Quota, error) {
+	quotas := make([]*user.Quota, 0, len(defaultLimits))
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		limits := make(map[string]int64, len(defaultLimits))
+		for target, limit := range defaultLimits {
+			limits[target] = limit
+		}
+
+		var overrides []userQuota
+		if err := sess.Where("user_id = ? AND org_id = 0", userID).Find(&overrides); err != nil {
+			return err
+		}
+		for _, override := range overrides {
+			if _, ok := limits[override.Target]; ok {
+				limits[override.Target] = override.Limit
+			}
+		}
+
+		for target, limit := range limits {
+			var used int64
+			if target != alertRuleQuotaTarget || unifiedAlertingEnabled {
+				var err error
+				if used, err = ss.countQuotaUsage(sess, userID, target); err != nil {
+					return err
+				}
+			}
+			quotas = append(quotas, &user.Quota{UserID: userID, Target: target, Limit: limit, Used: used})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(quotas, func(i, j int) bool { return quotas[i].Target < quotas[j].Target })
+	return quotas, nil
+}
+
+// countQuotaUsage counts the resources of a user counted against a quota target. Data sources and api keys created
+// before their creator was recorded are not counted
+func (ss *sqlStore) countQuotaUsage(sess *db.Session, userID int64, target string) (int64, error) {
+	switch target {
+	case "org_user":
+		return sess.Table("org_user").Where("user_id = ?", userID).Count()
+	case "dashboard":
+		return sess.Table("dashboard").Where("created_by = ? AND is_folder = ?", userID, ss.dialect.BooleanStr(false)).Count()
+	case "data_source":
+		return sess.Table("data_source").Where("created_by = ?", userID).Count()
+	case "api_key":
+		return sess.Table("api_key").Where("created_by = ? AND service_account_id IS NULL", userID).Count()
+	default:
+		return 0, user.ErrInvalidQuotaTarget
+	}
+}
+
+func (ss *sqlStore) SetQuota(ctx context.Context, cmd *user.SetQuotaCommand) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		quota := userQuota{UserID: cmd.UserID, Target: cmd.Target}
+		has, err := sess.Where("org_id = 0").Get(&quota)
+		if err != nil {
+			return err
+		}
+
+		quota.Limit = cmd.Limit
+		quota.Updated = time.Now()
+		if !has {
+			quota.Created = quota.Updated
+			_, err = sess.Insert(&quota)
+			return err
+		}
+		// a limit of 0 is a valid limit, which xorm skips unless told otherwise
+		_, err = sess.ID(quota.ID).MustCols("limit").Update(&quota)
+		return err
+	})
+}
+
 func (ss *sqlStore) SetHelpFlag(ctx context.Context, cmd *user.SetUserHelpFlagCommand) error {
 	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		user := user.User{
@@ -460,6 +1528,25 @@ func (ss *sqlStore) UpdatePermissions(ctx context.Context, userID int64, isAdmin
 	})
 }
 
+func (ss *sqlStore) SetFieldLocks(ctx context.Context, cmd *user.SetUserFieldLocksCommand) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Table("user").Where("id = ?", cmd.UserID).And(ss.notServiceAccountFilter()).And(ss.notDeletedFilter()).
+			Update(map[string]interface{}{
+				"name_locked":  cmd.Name,
+				"email_locked": cmd.Email,
+				"login_locked": cmd.Login,
+				"updated":      time.Now(),
+			})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return user.ErrUserNotFound
+		}
+		return nil
+	})
+}
+
 // validateOneAdminLeft validate that there is an admin user left
 func validateOneAdminLeft(ctx context.Context, sess *db.Session) error {
 	count, err := sess.Where("is_admin=?", true).Count(&user.User{})
@@ -474,25 +1561,250 @@ func validateOneAdminLeft(ctx context.Context, sess *db.Session) error {
 	return nil
 }
 
+// BatchDisableUsers disables or enables users in a single transaction, in statements of batchDisableUsersChunkSize
+// users. Only the users whose state changes are updated, a UsersDisabled event lists the disabled ones
 func (ss *sqlStore) BatchDisableUsers(ctx context.Context, cmd *user.BatchDisableUsersCommand) error {
-	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
-		userIds := cmd.UserIDs
+	cmd.UpdatedUserIDs = make([]int64, 0, len(cmd.UserIDs))
+	cmd.NotFoundUserIDs = make([]int64, 0)
+	if len(cmd.UserIDs) == 0 {
+		return nil
+	}
+
+	err := ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		found := make(map[int64]bool, len(cmd.UserIDs))
+		for start := 0; start < len(cmd.UserIDs); start += batchDisableUsersChunkSize {
+			end := start + batchDisableUsersChunkSize
+			if end > len(cmd.UserIDs) {
+				end = len(cmd.UserIDs)
+			}
+
+			var users []*user.User
+			if err := sess.Table("user").Cols("id", "is_disabled").In("id", cmd.UserIDs[start:end]).
+				Where(ss.notServiceAccountFilter()).Where(ss.notDeletedFilter()).Find(&users); err != nil {
+				return err
+			}
+			toUpdate := make([]int64, 0, len(users))
+			for _, usr := range users {
+				found[usr.ID] = true
+				if usr.IsDisabled != cmd.IsDisabled {
+					toUpdate = append(toUpdate, usr.ID)
+				}
+			}
+			if len(toUpdate) == 0 {
+				continue
+			}
+
+			if _, err := sess.Table("user").In("id", toUpdate).
+				Update(map[string]interface{}{"is_disabled": cmd.IsDisabled, "updated": time.Now()}); err != nil {
+				return err
+			}
+			cmd.UpdatedUserIDs = append(cmd.UpdatedUserIDs, toUpdate...)
+		}
+
+		for _, id := range cmd.UserIDs {
+			if !found[id] {
+				cmd.NotFoundUserIDs = append(cmd.NotFoundUserIDs, id)
+			}
+		}
+		if cmd.IsDisabled && len(cmd.UpdatedUserIDs) > 0 {
+			sess.PublishAfterCommit(&events.UsersDisabled{
+				Timestamp: time.Now(),
+				Ids:       cmd.UpdatedUserIDs,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		cmd.UpdatedUserIDs, cmd.NotFoundUserIDs = nil, nil
+	}
+	return err
+}
+
+const (
+	// batchDeleteUsersChunkSize is the number of users deleted in each transaction of a batch deletion
+	batchDeleteUsersChunkSize = 100
+	// batchUpdateLastSeenChunkSize is the number of users updated by each statement of a batch update of last seen times
+	batchUpdateLastSeenChunkSize = 500
+	// batchDisableUsersChunkSize is the number of users updated by each statement of a batch disabling
+	batchDisableUsersChunkSize = 500
+	// getByIDsChunkSize is the number of users loaded by each query of a lookup of multiple users
+	getByIDsChunkSize = 500
+)
+
+// BatchDeleteUsers deletes users and the rows referencing them in transactions of batchDeleteUsersChunkSize users, so
+// large batches don't hold locks for long. Service accounts are skipped. A single UsersDeleted event lists the deleted
+// users, it is published even when a later chunk fails so the users deleted by the previous chunks are reported
+func (ss *sqlStore) BatchDeleteUsers(ctx context.Context, userIDs []int64) error {
+	deletedIDs := make([]int64, 0, len(userIDs))
+	var err error
+	for start := 0; start < len(userIDs); start += batchDeleteUsersChunkSize {
+		end := start + batchDeleteUsersChunkSize
+		if end > len(userIDs) {
+			end = len(userIDs)
+		}
 
-		if len(userIds) == 0 {
+		var chunkIDs []int64
+		if chunkIDs, err = ss.deleteUsersChunk(ctx, userIDs[start:end]); err != nil {
+			break
+		}
+		deletedIDs = append(deletedIDs, chunkIDs...)
+	}
+
+	if len(deletedIDs) > 0 {
+		if publishErr := ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+			sess.PublishAfterCommit(&events.UsersDeleted{
+				Timestamp: time.Now(),
+				Ids:       deletedIDs,
+			})
 			return nil
+		}); publishErr != nil && err == nil {
+			err = publishErr
 		}
+	}
 
-		user_id_params := strings.Repeat(",?", len(userIds)-1)
-		disableSQL := "UPDATE " + ss.dialect.Quote("user") + " SET is_disabled=? WHERE Id IN (?" + user_id_params + ")"
+	return err
+}
 
-		disableParams := []interface{}{disableSQL, cmd.IsDisabled}
-		for _, v := range userIds {
-			disableParams = append(disableParams, v)
+func (ss *sqlStore) deleteUsersChunk(ctx context.Context, userIDs []int64) ([]int64, error) {
+	deletedIDs := make([]int64, 0, len(userIDs))
+	err := ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if err := sess.Table("user").Where(ss.notServiceAccountFilter()).In("id", userIDs).Cols("id").Find(&deletedIDs); err != nil {
+			return err
+		}
+		if len(deletedIDs) == 0 {
+			return nil
 		}
 
-		_, err := sess.Where(ss.notServiceAccountFilter()).Exec(disableParams...)
-		return err
+		return ss.deleteUserRows(sess, deletedIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deletedIDs, nil
+}
+
+// mergedUserRows counts the rows moved from the source to the target user of a merge
+type mergedUserRows struct {
+	OrgMemberships       int64
+	DashboardPermissions int64
+	Preferences          int64
+	TeamMemberships      int64
+	AuthIdentities       int64
+	Stars                int64
+}
+
+// MergeUsers moves the org memberships, dashboard permissions, preferences, team memberships, auth identities and
+// stars of the source user to the target user, then deletes the source user. Rows conflicting with the ones of the
+// target user, like memberships of an org both users belong to, are kept as they are for the target user
+func (ss *sqlStore) MergeUsers(ctx context.Context, targetID, sourceID int64) (*mergedUserRows, error) {
+	merged := &mergedUserRows{}
+	err := ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for _, id := range []int64{targetID, sourceID} {
+			if has, err := sess.Table("user").Where("id = ?", id).Where(ss.notServiceAccountFilter()).Exist(); err != nil {
+				return err
+			} else if !has {
+				return user.ErrUserNotFound
+			}
+		}
+
+		var err error
+		moves := []struct {
+			table    string
+			scopeCol string
+			moved    *int64
+		}{
+			{"org_user", "org_id", &merged.OrgMemberships},
+			{"dashboard_acl", "dashboard_id", &merged.DashboardPermissions},
+			{"preferences", "org_id", &merged.Preferences},
+			{"team_member", "team_id", &merged.TeamMemberships},
+			{"star", "dashboard_id", &merged.Stars},
+		}
+		for _, m := range moves {
+			if *m.moved, err = ss.moveUserRows(sess, m.table, m.scopeCol, targetID, sourceID); err != nil {
+				return err
+			}
+		}
+		if merged.AuthIdentities, err = sess.Table("user_auth").Where("user_id = ?", sourceID).
+			Update(map[string]interface{}{"user_id": targetID}); err != nil {
+			return err
+		}
+
+		if err := ss.deleteUserRows(sess, []int64{sourceID}); err != nil {
+			return err
+		}
+		sess.PublishAfterCommit(&events.UsersDeleted{
+			Timestamp: time.Now(),
+			Ids:       []int64{sourceID},
+		})
+		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// moveUserRows reassigns the rows of a table from the source to the target user, except the ones in a scope the
+// target user already has a row in, which would break the unique indexes of the table
+func (ss *sqlStore) moveUserRows(sess *db.Session, table, scopeCol string, targetID, sourceID int64) (int64, error) {
+	var targetScopes []int64
+	if err := sess.Table(table).Where("user_id = ?", targetID).Cols(scopeCol).Find(&targetScopes); err != nil {
+		return 0, err
+	}
+
+	move := sess.Table(table).Where("user_id = ?", sourceID)
+	if len(targetScopes) > 0 {
+		move = move.NotIn(scopeCol, targetScopes)
+	}
+	return move.Update(map[string]interface{}{"user_id": targetID})
+}
+
+// deleteUserRows deletes users along with their memberships, preferences, sessions and permissions
+func (ss *sqlStore) deleteUserRows(sess *db.Session, userIDs []int64) error {
+	params := make([]interface{}, 0, len(userIDs)+1)
+	params = append(params, nil)
+	for _, id := range userIDs {
+		params = append(params, id)
+	}
+	in := "(?" + strings.Repeat(",?", len(userIDs)-1) + ")"
+
+	deletes := []string{
+		"DELETE FROM star WHERE user_id IN " + in,
+		"DELETE FROM " + ss.dialect.Quote("user") + " WHERE id IN " + in,
+		"DELETE FROM org_user WHERE user_id IN " + in,
+		"DELETE FROM dashboard_acl WHERE user_id IN " + in,
+		"DELETE FROM preferences WHERE user_id IN " + in,
+		"DELETE FROM team_member WHERE user_id IN " + in,
+		"DELETE FROM user_auth WHERE user_id IN " + in,
+		"DELETE FROM user_auth_token WHERE user_id IN " + in,
+		"DELETE FROM quota WHERE user_id IN " + in,
+		"DELETE FROM user_role WHERE user_id IN " + in,
+		"DELETE FROM user_attribute WHERE user_id IN " + in,
+		"DELETE FROM user_password_history WHERE user_id IN " + in,
+		"DELETE FROM user_email_verification WHERE user_id IN " + in,
+		"DELETE FROM user_label WHERE user_id IN " + in,
+	}
+	for _, sql := range deletes {
+		params[0] = sql
+		if _, err := sess.Exec(params...); err != nil {
+			return err
+		}
+	}
+
+	// permissions scoped to the users and the permissions of their managed roles
+	scopeParams := []interface{}{"DELETE FROM permission WHERE scope IN " + in}
+	roleParams := []interface{}{"DELETE FROM permission WHERE role_id IN (SELECT id FROM role WHERE name IN " + in + ")"}
+	for _, id := range userIDs {
+		scopeParams = append(scopeParams, accesscontrol.Scope("users", "id", strconv.FormatInt(id, 10)))
+		roleParams = append(roleParams, accesscontrol.ManagedUserRoleName(id))
+	}
+	if _, err := sess.Exec(scopeParams...); err != nil {
+		return err
+	}
+	if _, err := sess.Exec(roleParams...); err != nil {
+		return err
+	}
+	return nil
 }
 
 func (ss *sqlStore) Disable(ctx context.Context, cmd *user.DisableUserCommand) error {
@@ -514,82 +1826,92 @@ func (ss *sqlStore) Disable(ctx context.Context, cmd *user.DisableUserCommand) e
 	})
 }
 
+// setSearchHitOrgs sets the organizations of the users of a search page, with a single query for the whole page
+func setSearchHitOrgs(sess *db.Session, users []*user.UserSearchHitDTO) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	userIDs := make([]int64, 0, len(users))
+	byID := make(map[int64]*user.UserSearchHitDTO, len(users))
+	for _, u := range users {
+		u.Orgs = make([]*user.UserSearchHitOrgDTO, 0)
+		userIDs = append(userIDs, u.ID)
+		byID[u.ID] = u
+	}
+
+	orgs := make([]*user.UserSearchHitOrgDTO, 0)
+	err := sess.Table("org_user").
+		Join("INNER", "org", "org_user.org_id = org.id").
+		In("org_user.user_id", userIDs).
+		Cols("org_user.user_id", "org_user.org_id", "org.name", "org_user.role").
+		OrderBy("org.name").
+		Find(&orgs)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range orgs {
+		if u, ok := byID[o.UserID]; ok {
+			u.Orgs = append(u.Orgs, o)
+		}
+	}
+	return nil
+}
+
 func (ss *sqlStore) Search(ctx context.Context, query *user.SearchUsersQuery) (*user.SearchUserQueryResult, error) {
 	result := user.SearchUserQueryResult{
 		Users: make([]*user.UserSearchHitDTO, 0),
 	}
 	err := ss.db.WithDbSession(ctx, func(dbSess *db.Session) error {
-		queryWithWildcards := "%" + query.Query + "%"
+		whereConditions, whereParams, err := ss.searchConditions(query)
+		if err != nil {
+			return err
+		}
 
-		whereConditions := make([]string, 0)
-		whereParams := make([]interface{}, 0)
 		sess := dbSess.Table("user").Alias("u")
-
-		whereConditions = append(whereConditions, "u.is_service_account = ?")
-		whereParams = append(whereParams, ss.dialect.BooleanStr(false))
-
-		// Join with only most recent auth module
-		joinCondition := `(
-		SELECT id from user_auth
-			WHERE user_auth.user_id = u.id
-			ORDER BY user_auth.created DESC `
-		joinCondition = "user_auth.id=" + joinCondition + ss.dialect.Limit(1) + ")"
+		joinCondition := ss.lastUserAuthJoinCondition()
 		sess.Join("LEFT", "user_auth", joinCondition)
-		if query.OrgID > 0 {
-			whereConditions = append(whereConditions, "org_id = ?")
-			whereParams = append(whereParams, query.OrgID)
-		}
 
-		// user only sees the users for which it has read permissions
-		if !accesscontrol.IsDisabled(ss.cfg) {
-			acFilter, err := accesscontrol.Filter(query.SignedInUser, "u.id", "global.users:id:", accesscontrol.ActionUsersRead)
-			if err != nil {
-				return err
-			}
-			whereConditions = append(whereConditions, acFilter.Where)
-			whereParams = append(whereParams, acFilter.Args...)
+		if len(whereConditions) > 0 {
+			sess.Where(strings.Join(whereConditions, " AND "), whereParams...)
 		}
 
-		if query.Query != "" {
-			whereConditions = append(whereConditions, "(email "+ss.dialect.LikeStr()+" ? OR name "+ss.dialect.LikeStr()+" ? OR login "+ss.dialect.LikeStr()+" ?)")
-			whereParams = append(whereParams, queryWithWildcards, queryWithWildcards, queryWithWildcards)
-		}
+		applySearchFilters(sess, query.Filters)
 
-		if query.IsDisabled != nil {
-			whereConditions = append(whereConditions, "is_disabled = ?")
-			whereParams = append(whereParams, query.IsDisabled)
-		}
+		order := query.Pagination.Order.OrDefault(pagination.SortAscending)
 
-		if query.AuthModule != "" {
-			whereConditions = append(whereConditions, `auth_module=?`)
-			whereParams = append(whereParams, query.AuthModule)
+		// logins are unique, pages selected by a key cursor seek to the users after the login of the cursor
+		// instead of skipping the users of the previous pages with an offset
+		if login, ok := query.Pagination.Cursor.Key(); ok {
+			if order == pagination.SortDescending {
+				sess.And("u.login < ?", login)
+			} else {
+				sess.And("u.login > ?", login)
+			}
 		}
 
-		if len(whereConditions) > 0 {
-			sess.Where(strings.Join(whereConditions, " AND "), whereParams...)
+		// one more user than the page size is fetched to know whether there is a following page
+		perPage := query.Pagination.PerPage
+		if perPage > 0 {
+			sess.Limit(int(perPage)+1, int(query.Pagination.Offset()))
 		}
 
-		for _, filter := range query.Filters {
-			if jc := filter.JoinCondition(); jc != nil {
-				sess.Join(jc.Operator, jc.Table, jc.Params)
-			}
-			if ic := filter.InCondition(); ic != nil {
-				sess.In(ic.Condition, ic.Params)
-			}
-			if wc := filter.WhereCondition(); wc != nil {
-				sess.Where(wc.Condition, wc.Params)
-			}
+		sess.Cols("u.id", "u.email", "u.name", "u.login", "u.is_admin", "u.is_disabled", "u.is_service_account", "u.last_seen_at", "user_auth.auth_module")
+		sess.OrderBy("u.login " + order.SQL() + ", u.email " + order.SQL())
+		if err := sess.Find(&result.Users); err != nil {
+			return err
 		}
 
-		if query.Limit > 0 {
-			offset := query.Limit * (query.Page - 1)
-			sess.Limit(query.Limit, offset)
+		hasNextPage := perPage > 0 && int64(len(result.Users)) > perPage
+		if hasNextPage {
+			result.Users = result.Users[:perPage]
 		}
 
-		sess.Cols("u.id", "u.email", "u.name", "u.login", "u.is_admin", "u.is_disabled", "u.last_seen_at", "user_auth.auth_module")
-		sess.Asc("u.login", "u.email")
-		if err := sess.Find(&result.Users); err != nil {
-			return err
+		if query.WithOrgs {
+			if err := setSearchHitOrgs(dbSess, result.Users); err != nil {
+				return err
+			}
 		}
 
 		// get total
@@ -605,20 +1927,14 @@ func (ss *sqlStore) Search(ctx context.Context, query *user.SearchUsersQuery) (*
 			countSess.Where(strings.Join(whereConditions, " AND "), whereParams...)
 		}
 
-		for _, filter := range query.Filters {
-			if jc := filter.JoinCondition(); jc != nil {
-				countSess.Join(jc.Operator, jc.Table, jc.Params)
-			}
-			if ic := filter.InCondition(); ic != nil {
-				countSess.In(ic.Condition, ic.Params)
-			}
-			if wc := filter.WhereCondition(); wc != nil {
-				countSess.Where(wc.Condition, wc.Params)
-			}
-		}
+		applySearchFilters(countSess, query.Filters)
 
 		count, err := countSess.Count(&user)
-		result.TotalCount = count
+		result.Result = pagination.NewResult(query.Pagination, len(result.Users), count)
+		result.NextCursor = ""
+		if hasNextPage {
+			result.NextCursor = pagination.NewKeyCursor(result.Users[len(result.Users)-1].Login)
+		}
 
 		for _, user := range result.Users {
 			user.LastSeenAtAge = util.GetAgeString(user.LastSeenAt)
@@ -628,3 +1944,159 @@ func (ss *sqlStore) Search(ctx context.Context, query *user.SearchUsersQuery) (*
 	})
 	return &result, err
 }
+
+// activeUserWindow is the time users are counted as active for after they were last seen
+const activeUserWindow = 30 * 24 * time.Hour
+
+// Count counts the users matching a search with COUNT queries, without loading them. The pagination of the query is
+// ignored
+func (ss *sqlStore) Count(ctx context.Context, query *user.SearchUsersQuery) (*user.CountUsersResult, error) {
+	result := &user.CountUsersResult{ByAuthModule: make(map[string]int64)}
+	err := ss.db.WithDbSession(ctx, func(dbSess *db.Session) error {
+		whereConditions, whereParams, err := ss.searchConditions(query)
+		if err != nil {
+			return err
+		}
+		joinCondition := ss.lastUserAuthJoinCondition()
+
+		search := func() *xorm.Session {
+			sess := dbSess.Table("user").Alias("u").Join("LEFT", "user_auth", joinCondition)
+			sess.Where(strings.Join(whereConditions, " AND "), whereParams...)
+			applySearchFilters(sess, query.Filters)
+			return sess
+		}
+
+		for _, count := range []struct {
+			total     *int64
+			condition string
+			args      []interface{}
+		}{
+			{total: &result.Total},
+			{total: &result.Active, condition: "u.last_seen_at >= ?", args: []interface{}{time.Now().Add(-activeUserWindow)}},
+			{total: &result.Disabled, condition: "u.is_disabled = ?", args: []interface{}{ss.dialect.BooleanStr(true)}},
+			{total: &result.Admins, condition: "u.is_admin = ?", args: []interface{}{ss.dialect.BooleanStr(true)}},
+		} {
+			sess := search()
+			if count.condition != "" {
+				sess.And(count.condition, count.args...)
+			}
+			if *count.total, err = sess.Count(&user.User{}); err != nil {
+				return err
+			}
+		}
+
+		var authModules []struct {
+			AuthModule string
+			Users      int64
+		}
+		err = search().And("user_auth.auth_module IS NOT NULL").
+			Select("user_auth.auth_module AS auth_module, COUNT(*) AS users").
+			GroupBy("user_auth.auth_module").Find(&authModules)
+		if err != nil {
+			return err
+		}
+		for _, authModule := range authModules {
+			result.ByAuthModule[authModule.AuthModule] = authModule.Users
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// lastUserAuthJoinCondition joins users with their most recent auth module
+func (ss *sqlStore) lastUserAuthJoinCondition() string {
+	joinCondition := `(
+		SELECT id from user_auth
+			WHERE user_auth.user_id = u.id
+			ORDER BY user_auth.created DESC `
+	return "user_auth.id=" + joinCondition + ss.dialect.Limit(1) + ")"
+}
+
+// searchConditions returns the conditions of the users matching a search, on the user table aliased as u joined with
+// the most recent auth module of the users. The filters of the query are applied separately
+func (ss *sqlStore) searchConditions(query *user.SearchUsersQuery) ([]string, []interface{}, error) {
+	queryWithWildcards := "%" + query.Query + "%"
+
+	whereConditions := make([]string, 0)
+	whereParams := make([]interface{}, 0)
+
+	whereConditions = append(whereConditions, "u.deleted_at IS NULL")
+
+	if query.OnlyServiceAccounts {
+		whereConditions = append(whereConditions, "u.is_service_account = ?")
+		whereParams = append(whereParams, ss.dialect.BooleanStr(true))
+	} else if !query.IncludeServiceAccounts {
+		whereConditions = append(whereConditions, "u.is_service_account = ?")
+		whereParams = append(whereParams, ss.dialect.BooleanStr(false))
+	}
+
+	if query.OrgID > 0 {
+		whereConditions = append(whereConditions, "org_id = ?")
+		whereParams = append(whereParams, query.OrgID)
+	}
+
+	// user only sees the users for which it has read permissions
+	if !accesscontrol.IsDisabled(ss.cfg) {
+		acFilter, err := accesscontrol.Filter(query.SignedInUser, "u.id", "global.users:id:", accesscontrol.ActionUsersRead)
+		if err != nil {
+			return nil, nil, err
+		}
+		whereConditions = append(whereConditions, acFilter.Where)
+		whereParams = append(whereParams, acFilter.Args...)
+	}
+
+	if query.Query != "" {
+		whereConditions = append(whereConditions, "(email "+ss.dialect.LikeStr()+" ? OR name "+ss.dialect.LikeStr()+" ? OR login "+ss.dialect.LikeStr()+" ?)")
+		whereParams = append(whereParams, queryWithWildcards, queryWithWildcards, queryWithWildcards)
+	}
+
+	if query.IsDisabled != nil {
+		whereConditions = append(whereConditions, "is_disabled = ?")
+		whereParams = append(whereParams, query.IsDisabled)
+	}
+
+	if query.AuthModule != "" {
+		whereConditions = append(whereConditions, `auth_module=?`)
+		whereParams = append(whereParams, query.AuthModule)
+	}
+
+	if query.LastSeenBefore != nil {
+		whereConditions = append(whereConditions, "u.last_seen_at < ?")
+		whereParams = append(whereParams, *query.LastSeenBefore)
+	}
+
+	if query.LastSeenAfter != nil {
+		whereConditions = append(whereConditions, "u.last_seen_at >= ?")
+		whereParams = append(whereParams, *query.LastSeenAfter)
+	}
+
+	for key, value := range query.Attributes {
+		whereConditions = append(whereConditions, "EXISTS (SELECT 1 FROM user_attribute WHERE user_attribute.user_id = u.id AND user_attribute."+ss.dialect.Quote("key")+" = ? AND user_attribute.value = ?)")
+		whereParams = append(whereParams, key, value)
+	}
+
+	if query.Label != "" {
+		whereConditions = append(whereConditions, "EXISTS (SELECT 1 FROM user_label WHERE user_label.user_id = u.id AND user_label.label = ?)")
+		whereParams = append(whereParams, query.Label)
+	}
+
+	return whereConditions, whereParams, nil
+}
+
+// applySearchFilters applies the filters of a search to a session
+func applySearchFilters(sess *xorm.Session, filters []user.Filter) {
+	for _, filter := range filters {
+		if jc := filter.JoinCondition(); jc != nil {
+			sess.Join(jc.Operator, jc.Table, jc.Params)
+		}
+		if ic := filter.InCondition(); ic != nil {
+			sess.In(ic.Condition, ic.Params)
+		}
+		if wc := filter.WhereCondition(); wc != nil {
+			sess.Where(wc.Condition, wc.Params)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package userimpl
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -9,14 +10,21 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/services/team/teamtest"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 func TestIntegrationUserDataAccess(t *testing.T) {
@@ -468,7 +476,7 @@ func TestIntegrationUserDataAccess(t *testing.T) {
 		require.NotNil(t, query4.Result)
 		require.Equal(t, query4.Result.OrgID, users[0].OrgID)
 
-		cacheKey := newSignedInUserCacheKey(query4.Result.OrgID, query4.UserId)
+		cacheKey := fmt.Sprintf("signed-in-user-%d-%d", query4.UserId, query4.Result.OrgID)
 		_, found := ss.CacheService.Get(cacheKey)
 		require.True(t, found)
 
@@ -627,28 +635,1222 @@ func TestIntegrationUserDataAccess(t *testing.T) {
 		}
 	})
 
+	t.Run("Testing DB - batch disable users returns the updated users and publishes an event", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		users := createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+			return &user.CreateUserCommand{
+				Email:      fmt.Sprint("user", i, "@test.com"),
+				Login:      fmt.Sprint("loginuser", i),
+				IsDisabled: i == 2,
+			}
+		})
+		serviceAccount, err := ss.CreateUser(context.Background(), user.CreateUserCommand{Login: "sa", IsServiceAccount: true})
+		require.NoError(t, err)
+
+		var published []*events.UsersDisabled
+		ss.Bus().AddEventListener(func(ctx context.Context, e *events.UsersDisabled) error {
+			published = append(published, e)
+			return nil
+		})
+
+		// the third user is already disabled
+		disableCmd := user.BatchDisableUsersCommand{
+			UserIDs:    []int64{users[0].ID, users[1].ID, users[2].ID, serviceAccount.ID, 1000},
+			IsDisabled: true,
+		}
+		require.NoError(t, userStore.BatchDisableUsers(context.Background(), &disableCmd))
+		assert.Equal(t, []int64{users[0].ID, users[1].ID}, disableCmd.UpdatedUserIDs)
+		assert.Equal(t, []int64{serviceAccount.ID, 1000}, disableCmd.NotFoundUserIDs)
+		require.Len(t, published, 1)
+		assert.Equal(t, []int64{users[0].ID, users[1].ID}, published[0].Ids)
+
+		err = ss.WithDbSession(context.Background(), func(sess *db.Session) error {
+			var sa user.User
+			_, err := sess.ID(serviceAccount.ID).Get(&sa)
+			assert.False(t, sa.IsDisabled)
+			return err
+		})
+		require.NoError(t, err)
+
+		// enabling users publishes no event
+		enableCmd := user.BatchDisableUsersCommand{UserIDs: []int64{users[0].ID, users[3].ID}, IsDisabled: false}
+		require.NoError(t, userStore.BatchDisableUsers(context.Background(), &enableCmd))
+		assert.Equal(t, []int64{users[0].ID}, enableCmd.UpdatedUserIDs)
+		assert.Empty(t, enableCmd.NotFoundUserIDs)
+		require.Len(t, published, 1)
+	})
+
 	ss = db.InitTestDB(t)
 
-	t.Run("Testing DB - search users", func(t *testing.T) {
-		// Since previous tests were destructive
-		createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+	t.Run("Testing DB - search users", func(t *testing.T) {
+		// Since previous tests were destructive
+		createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+			return &user.CreateUserCommand{
+				Email:      fmt.Sprint("user", i, "@test.com"),
+				Name:       fmt.Sprint("user", i),
+				Login:      fmt.Sprint("loginuser", i),
+				IsDisabled: false,
+			}
+		})
+	})
+
+	t.Run("Disable user", func(t *testing.T) {
+		id, err := userStore.Insert(context.Background(), &user.User{
+			Name:    "user111",
+			Created: time.Now(),
+			Updated: time.Now(),
+		})
+		require.NoError(t, err)
+		err = userStore.Disable(context.Background(), &user.DisableUserCommand{UserID: id})
+		require.NoError(t, err)
+	})
+
+	t.Run("Delete user publishes UserDeleted", func(t *testing.T) {
+		id, err := userStore.Insert(context.Background(), &user.User{
+			Name:    "user112",
+			Login:   "user112",
+			Email:   "user112@test.com",
+			Created: time.Now(),
+			Updated: time.Now(),
+		})
+		require.NoError(t, err)
+
+		var deletedUserID int64
+		ss.Bus().AddEventListener(func(ctx context.Context, e *events.UserDeleted) error {
+			deletedUserID = e.Id
+			return nil
+		})
+
+		err = userStore.Delete(context.Background(), id)
+		require.NoError(t, err)
+		require.Equal(t, id, deletedUserID)
+	})
+
+	t.Run("Soft delete, restore and purge user", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		id, err := userStore.Insert(context.Background(), &user.User{
+			Name:    "user112",
+			Login:   "user112",
+			Email:   "user112@test.com",
+			Created: time.Now(),
+			Updated: time.Now(),
+		})
+		require.NoError(t, err)
+
+		var published []*events.UsersDeleted
+		ss.Bus().AddEventListener(func(ctx context.Context, e *events.UsersDeleted) error {
+			published = append(published, e)
+			return nil
+		})
+
+		err = userStore.Delete(context.Background(), id)
+		require.NoError(t, err)
+		require.ErrorIs(t, userStore.Delete(context.Background(), id), user.ErrUserNotFound)
+
+		// soft deleted users are hidden
+		_, err = userStore.GetByID(context.Background(), id)
+		require.ErrorIs(t, err, user.ErrUserNotFound)
+		_, err = userStore.GetByLogin(context.Background(), &user.GetUserByLoginQuery{LoginOrEmail: "user112"})
+		require.ErrorIs(t, err, user.ErrUserNotFound)
+		_, err = userStore.GetSignedInUser(context.Background(), &user.GetSignedInUserQuery{UserID: id})
+		require.ErrorIs(t, err, user.ErrUserNotFound)
+		searchResult, err := userStore.Search(context.Background(), &user.SearchUsersQuery{Query: "user112", SignedInUser: usr})
+		require.NoError(t, err)
+		require.Empty(t, searchResult.Users)
+		require.Zero(t, searchResult.TotalCount)
+
+		err = userStore.Restore(context.Background(), id)
+		require.NoError(t, err)
+		require.ErrorIs(t, userStore.Restore(context.Background(), id), user.ErrUserNotFound)
+		restored, err := userStore.GetByID(context.Background(), id)
+		require.NoError(t, err)
+		require.Nil(t, restored.DeletedAt)
+		require.Equal(t, "user112", restored.Login)
+		require.Equal(t, "user112@test.com", restored.Email)
+
+		// the login and email of a soft deleted user can be taken by a new user, which prevents its restore
+		err = userStore.Delete(context.Background(), id)
+		require.NoError(t, err)
+		newUser := &user.User{
+			Name:    "user112",
+			Login:   "user112",
+			Email:   "user112@test.com",
+			Created: time.Now(),
+			Updated: time.Now(),
+		}
+		_, err = userStore.Insert(context.Background(), newUser)
+		require.NoError(t, err)
+		require.ErrorIs(t, userStore.Restore(context.Background(), id), user.ErrUserAlreadyExists)
+		err = userStore.Delete(context.Background(), newUser.ID)
+		require.NoError(t, err)
+		err = userStore.Restore(context.Background(), id)
+		require.NoError(t, err)
+
+		// only users deleted before the retention are purged
+		err = userStore.Delete(context.Background(), id)
+		require.NoError(t, err)
+		purged, err := userStore.PurgeDeleted(context.Background(), time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		require.Zero(t, purged)
+		require.Empty(t, published)
+
+		purged, err = userStore.PurgeDeleted(context.Background(), time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.EqualValues(t, 2, purged)
+		require.Len(t, published, 1)
+		require.ElementsMatch(t, []int64{id, newUser.ID}, published[0].Ids)
+		require.ErrorIs(t, userStore.Restore(context.Background(), id), user.ErrUserNotFound)
+	})
+
+	t.Run("Testing DB - anonymize user", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		users := createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+			return &user.CreateUserCommand{
+				Email:   fmt.Sprint("user", i, "@test.com"),
+				Name:    fmt.Sprint("user", i),
+				Login:   fmt.Sprint("loginuser", i),
+				Company: "Grafana Labs",
+			}
+		})
+		err := ss.WithDbSession(context.Background(), func(sess *db.Session) error {
+			_, err := sess.Insert(&models.UserAuth{UserId: users[0].ID, AuthModule: "oauth_okta", AuthId: "0", Created: time.Now()})
+			return err
+		})
+		require.NoError(t, err)
+
+		err = userStore.Anonymize(context.Background(), users[0].ID)
+		require.NoError(t, err)
+
+		anonymized, err := userStore.GetByID(context.Background(), users[0].ID)
+		require.NoError(t, err)
+		placeholder := fmt.Sprint("anonymized-", users[0].ID)
+		assert.Equal(t, placeholder, anonymized.Login)
+		assert.Equal(t, placeholder, anonymized.Email)
+		assert.Equal(t, "Anonymized user", anonymized.Name)
+		assert.Empty(t, anonymized.Company)
+		assert.Empty(t, anonymized.Password)
+		assert.True(t, anonymized.IsDisabled)
+
+		err = ss.WithDbSession(context.Background(), func(sess *db.Session) error {
+			count, err := sess.Table("user_auth").Where("user_id = ?", users[0].ID).Count()
+			require.Zero(t, count)
+			return err
+		})
+		require.NoError(t, err)
+
+		// other users are left untouched
+		other, err := userStore.GetByID(context.Background(), users[1].ID)
+		require.NoError(t, err)
+		assert.Equal(t, "loginuser1", other.Login)
+		assert.Equal(t, "Grafana Labs", other.Company)
+
+		serviceAccount, err := ss.CreateUser(context.Background(), user.CreateUserCommand{Login: "sa", IsServiceAccount: true})
+		require.NoError(t, err)
+		require.ErrorIs(t, userStore.Anonymize(context.Background(), serviceAccount.ID), user.ErrUserNotFound)
+		require.ErrorIs(t, userStore.Anonymize(context.Background(), 1000), user.ErrUserNotFound)
+	})
+
+	t.Run("Testing DB - search users with their orgs", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		users := createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+			return &user.CreateUserCommand{
+				Email: fmt.Sprint("user", i, "@test.com"),
+				Name:  fmt.Sprint("user", i),
+				Login: fmt.Sprint("loginuser", i),
+			}
+		})
+		err := ss.AddOrgUser(context.Background(), &models.AddOrgUserCommand{
+			LoginOrEmail: users[1].Login, Role: org.RoleViewer,
+			OrgId: users[0].OrgID, UserId: users[1].ID,
+		})
+		require.NoError(t, err)
+
+		queryResult, err := userStore.Search(context.Background(), &user.SearchUsersQuery{Query: "loginuser", WithOrgs: true, SignedInUser: usr})
+		require.NoError(t, err)
+		require.Len(t, queryResult.Users, 5)
+
+		orgRoles := func(hit *user.UserSearchHitDTO) map[int64]org.RoleType {
+			roles := make(map[int64]org.RoleType)
+			for _, o := range hit.Orgs {
+				roles[o.OrgID] = o.Role
+			}
+			return roles
+		}
+		assert.Equal(t, map[int64]org.RoleType{users[0].OrgID: org.RoleAdmin}, orgRoles(queryResult.Users[0]))
+		assert.Equal(t, map[int64]org.RoleType{users[0].OrgID: org.RoleViewer, users[1].OrgID: org.RoleAdmin}, orgRoles(queryResult.Users[1]))
+		assert.NotEmpty(t, queryResult.Users[1].Orgs[0].Name)
+
+		// the orgs are only returned when asked for
+		queryResult, err = userStore.Search(context.Background(), &user.SearchUsersQuery{Query: "loginuser", SignedInUser: usr})
+		require.NoError(t, err)
+		require.Len(t, queryResult.Users, 5)
+		assert.Nil(t, queryResult.Users[1].Orgs)
+	})
+
+	t.Run("Testing DB - user quotas", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		users := createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+			return &user.CreateUserCommand{
+				Email: fmt.Sprint("user", i, "@test.com"),
+				Login: fmt.Sprint("loginuser", i),
+			}
+		})
+		creator := users[0]
+		serviceAccountID := int64(100)
+		err := ss.WithDbSession(context.Background(), func(sess *db.Session) error {
+			dashboard := models.NewDashboard("dashboard")
+			dashboard.OrgId, dashboard.CreatedBy, dashboard.Uid = creator.OrgID, creator.ID, "dashboard"
+			folder := models.NewDashboardFolder("folder")
+			folder.OrgId, folder.CreatedBy, folder.Uid = creator.OrgID, creator.ID, "folder"
+			dataSource := &datasources.DataSource{OrgId: creator.OrgID, Name: "ds", Type: "test", Access: datasources.DS_ACCESS_PROXY, Uid: "ds", CreatedBy: creator.ID, Created: time.Now(), Updated: time.Now()}
+			for i, key := range []*apikey.APIKey{
+				{OrgId: creator.OrgID, Name: "key", Key: "key", Role: org.RoleViewer, CreatedBy: creator.ID},
+				{OrgId: creator.OrgID, Name: "sa key", Key: "sa key", Role: org.RoleViewer, CreatedBy: creator.ID, ServiceAccountId: &serviceAccountID},
+				{OrgId: creator.OrgID, Name: "other key", Key: "other key", Role: org.RoleViewer, CreatedBy: users[1].ID},
+			} {
+				key.Created, key.Updated = time.Now(), time.Now()
+				if _, err := sess.Insert(key); err != nil {
+					return fmt.Errorf("inserting key %d: %w", i, err)
+				}
+			}
+			_, err := sess.Insert(dashboard, folder, dataSource)
+			return err
+		})
+		require.NoError(t, err)
+
+		defaults := map[string]int64{"org_user": 10, "dashboard": -1, "data_source": 5, "api_key": 5}
+		require.NoError(t, userStore.SetQuota(context.Background(), &user.SetQuotaCommand{UserID: creator.ID, Target: "api_key", Limit: 3}))
+		// setting a limit again replaces it
+		require.NoError(t, userStore.SetQuota(context.Background(), &user.SetQuotaCommand{UserID: creator.ID, Target: "data_source", Limit: 2}))
+		require.NoError(t, userStore.SetQuota(context.Background(), &user.SetQuotaCommand{UserID: creator.ID, Target: "data_source", Limit: 0}))
+
+		quotas, err := userStore.GetQuotas(context.Background(), creator.ID, defaults, false)
+		require.NoError(t, err)
+		require.Equal(t, []*user.Quota{
+			{UserID: creator.ID, Target: "api_key", Limit: 3, Used: 1},
+			{UserID: creator.ID, Target: "dashboard", Limit: -1, Used: 1},
+			{UserID: creator.ID, Target: "data_source", Limit: 0, Used: 1},
+			{UserID: creator.ID, Target: "org_user", Limit: 10, Used: 1},
+		}, quotas)
+
+		quotas, err = userStore.GetQuotas(context.Background(), users[2].ID, map[string]int64{"api_key": 5}, false)
+		require.NoError(t, err)
+		require.Equal(t, []*user.Quota{{UserID: users[2].ID, Target: "api_key", Limit: 5, Used: 0}}, quotas)
+	})
+
+	t.Run("Testing DB - users are not created when their org user link fails", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore := ProvideStore(ss, setting.NewCfg())
+		orgService := &failingOrgUserService{FakeOrgService: orgtest.NewOrgServiceFake()}
+		orgService.ExpectedOrgUserID = 1
+		userService := &Service{store: &userStore, orgService: orgService, cfg: setting.NewCfg()}
+
+		_, err := userService.Create(context.Background(), &user.CreateUserCommand{Login: "half-created", Email: "half-created@test.com"})
+		require.ErrorIs(t, err, errOrgUserLink)
+
+		// the user row is rolled back, no soft deleted user is left to be restored
+		err = ss.WithDbSession(context.Background(), func(sess *db.Session) error {
+			count, err := sess.Table("user").Count()
+			require.NoError(t, err)
+			require.Zero(t, count)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Testing DB - user quotas by target", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		cfg := setting.NewCfg()
+		cfg.Quota = setting.QuotaSettings{Enabled: true, User: &setting.UserQuota{Org: 11, Dashboard: -1, DataSource: -1, ApiKey: -1}}
+		userStore := ProvideStore(ss, cfg)
+		userService := &Service{store: &userStore, cfg: cfg}
+
+		users := createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+			return &user.CreateUserCommand{
+				Email: fmt.Sprint("user", i, "@test.com"),
+				Login: fmt.Sprint("loginuser", i),
+			}
+		})
+		userID := users[0].ID
+		unknownUserID := int64(9999)
+		getOrgUserQuota := func(t *testing.T, userID int64) *user.Quota {
+			t.Helper()
+			quotas, err := userService.GetQuotas(context.Background(), &user.GetQuotasQuery{UserID: userID, Targets: []string{"org_user"}})
+			require.NoError(t, err)
+			require.Len(t, quotas, 1)
+			return quotas[0]
+		}
+
+		err := userService.SetQuota(context.Background(), &user.SetQuotaCommand{UserID: userID, Target: "org_user", Limit: 10})
+		require.NoError(t, err)
+
+		t.Run("Should be able to get saved quota by user id and target", func(t *testing.T) {
+			require.Equal(t, int64(10), getOrgUserQuota(t, userID).Limit)
+		})
+
+		t.Run("Should be able to get default quota by user id and target", func(t *testing.T) {
+			require.Equal(t, int64(11), getOrgUserQuota(t, unknownUserID).Limit)
+		})
+
+		t.Run("Should be able to get used user quota when rows exist", func(t *testing.T) {
+			require.Equal(t, int64(1), getOrgUserQuota(t, userID).Used)
+		})
+
+		t.Run("Should be able to get used user quota when no rows exist", func(t *testing.T) {
+			require.Equal(t, int64(0), getOrgUserQuota(t, unknownUserID).Used)
+		})
+
+		t.Run("Should be able to quota list for user", func(t *testing.T) {
+			quotas, err := userService.GetQuotas(context.Background(), &user.GetQuotasQuery{UserID: userID})
+			require.NoError(t, err)
+			require.Len(t, quotas, 4)
+			require.Contains(t, quotas, &user.Quota{UserID: userID, Target: "org_user", Limit: 10, Used: 1})
+		})
+
+		// related: https://github.com/grafana/grafana/issues/14342
+		t.Run("Should user quota updating is successful even if it called multiple time", func(t *testing.T) {
+			err := userService.SetQuota(context.Background(), &user.SetQuotaCommand{UserID: userID, Target: "org_user", Limit: 5})
+			require.NoError(t, err)
+			require.Equal(t, int64(5), getOrgUserQuota(t, userID).Limit)
+
+			// XXX: resolution of `Updated` column is 1sec, so this makes delay
+			time.Sleep(1 * time.Second)
+
+			err = userService.SetQuota(context.Background(), &user.SetQuotaCommand{UserID: userID, Target: "org_user", Limit: 10})
+			require.NoError(t, err)
+			require.Equal(t, int64(10), getOrgUserQuota(t, userID).Limit)
+		})
+	})
+
+	t.Run("Testing DB - user attributes", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		users := createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+			return &user.CreateUserCommand{
+				Email: fmt.Sprint("user", i, "@test.com"),
+				Name:  fmt.Sprint("user", i),
+				Login: fmt.Sprint("loginuser", i),
+			}
+		})
+
+		for i, u := range users[:3] {
+			err := userStore.SetAttribute(context.Background(), &user.SetUserAttributeCommand{UserID: u.ID, Key: "cost-center", Value: fmt.Sprint("cc", i%2)})
+			require.NoError(t, err)
+		}
+		err := userStore.SetAttribute(context.Background(), &user.SetUserAttributeCommand{UserID: users[0].ID, Key: "team", Value: "platform"})
+		require.NoError(t, err)
+
+		// setting an attribute again replaces its value
+		err = userStore.SetAttribute(context.Background(), &user.SetUserAttributeCommand{UserID: users[1].ID, Key: "cost-center", Value: "cc0"})
+		require.NoError(t, err)
+
+		attributes, err := userStore.GetAttributes(context.Background(), &user.GetUserAttributesQuery{UserID: users[0].ID})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"cost-center": "cc0", "team": "platform"}, attributes)
+
+		attributes, err = userStore.GetAttributes(context.Background(), &user.GetUserAttributesQuery{UserID: users[3].ID})
+		require.NoError(t, err)
+		require.Empty(t, attributes)
+
+		queryResult, err := userStore.Search(context.Background(), &user.SearchUsersQuery{Attributes: map[string]string{"cost-center": "cc0"}, SignedInUser: usr})
+		require.NoError(t, err)
+		require.EqualValues(t, 3, queryResult.TotalCount)
+		require.Equal(t, []string{"loginuser0", "loginuser1", "loginuser2"}, searchHitLogins(queryResult.Users))
+
+		queryResult, err = userStore.Search(context.Background(), &user.SearchUsersQuery{Attributes: map[string]string{"cost-center": "cc0", "team": "platform"}, SignedInUser: usr})
+		require.NoError(t, err)
+		require.Equal(t, []string{"loginuser0"}, searchHitLogins(queryResult.Users))
+
+		// an empty value removes the attribute
+		err = userStore.SetAttribute(context.Background(), &user.SetUserAttributeCommand{UserID: users[0].ID, Key: "team"})
+		require.NoError(t, err)
+		attributes, err = userStore.GetAttributes(context.Background(), &user.GetUserAttributesQuery{UserID: users[0].ID})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"cost-center": "cc0"}, attributes)
+
+		err = userStore.SetAttribute(context.Background(), &user.SetUserAttributeCommand{UserID: 1000, Key: "team", Value: "platform"})
+		require.ErrorIs(t, err, user.ErrUserNotFound)
+		_, err = userStore.GetAttributes(context.Background(), &user.GetUserAttributesQuery{UserID: 1000})
+		require.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+
+	t.Run("Testing DB - search users and service accounts", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+			return &user.CreateUserCommand{
+				Email:            fmt.Sprint("user", i, "@test.com"),
+				Name:             fmt.Sprint("user", i),
+				Login:            fmt.Sprint("loginuser", i),
+				IsServiceAccount: i >= 3,
+			}
+		})
+
+		queryResult, err := userStore.Search(context.Background(), &user.SearchUsersQuery{SignedInUser: usr})
+		require.NoError(t, err)
+		require.Equal(t, []string{"loginuser0", "loginuser1", "loginuser2"}, searchHitLogins(queryResult.Users))
+
+		queryResult, err = userStore.Search(context.Background(), &user.SearchUsersQuery{IncludeServiceAccounts: true, SignedInUser: usr})
+		require.NoError(t, err)
+		require.EqualValues(t, 5, queryResult.TotalCount)
+		require.False(t, queryResult.Users[0].IsServiceAccount)
+		require.True(t, queryResult.Users[4].IsServiceAccount)
+
+		queryResult, err = userStore.Search(context.Background(), &user.SearchUsersQuery{OnlyServiceAccounts: true, SignedInUser: usr})
+		require.NoError(t, err)
+		require.Equal(t, []string{"loginuser3", "loginuser4"}, searchHitLogins(queryResult.Users))
+
+		count, err := userStore.Count(context.Background(), &user.SearchUsersQuery{OnlyServiceAccounts: true, SignedInUser: usr})
+		require.NoError(t, err)
+		require.EqualValues(t, 2, count.Total)
+	})
+
+	t.Run("Testing DB - effective access of a user", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		users := createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+			return &user.CreateUserCommand{
+				Email: fmt.Sprint("user", i, "@test.com"),
+				Login: fmt.Sprint("loginuser", i),
+			}
+		})
+		reviewed, other := users[1].ID, users[2].ID
+		now := time.Now()
+		dashboard, shared, folder := models.NewDashboard("dashboard"), models.NewDashboard("shared"), models.NewDashboardFolder("folder")
+		err := ss.WithDbSession(context.Background(), func(sess *db.Session) error {
+			for _, d := range []*models.Dashboard{dashboard, shared, folder} {
+				d.OrgId, d.Uid = 100, d.Title
+			}
+			if _, err := sess.Insert(dashboard, shared, folder); err != nil {
+				return err
+			}
+
+			editor, viewer := "Editor", "Viewer"
+			for _, args := range [][]interface{}{
+				{"INSERT INTO org (id, version, name, created, updated) VALUES (?, ?, ?, ?, ?)", 100, 0, "reviewed org", now, now},
+				{"INSERT INTO org_user (org_id, user_id, role, created, updated) VALUES (?, ?, ?, ?, ?)", 100, reviewed, "Editor", now, now},
+				{"INSERT INTO team (id, org_id, name, created, updated) VALUES (?, ?, ?, ?, ?)", 5, 100, "platform", now, now},
+				{"INSERT INTO team_member (org_id, team_id, user_id, permission, created, updated) VALUES (?, ?, ?, ?, ?, ?)", 100, 5, reviewed, 4, now, now},
+				{"INSERT INTO dashboard_acl (org_id, dashboard_id, user_id, permission, created, updated) VALUES (?, ?, ?, ?, ?, ?)", 100, dashboard.Id, reviewed, 1, now, now},
+				{"INSERT INTO dashboard_acl (org_id, dashboard_id, team_id, permission, created, updated) VALUES (?, ?, ?, ?, ?, ?)", 100, dashboard.Id, 5, 2, now, now},
+				{"INSERT INTO dashboard_acl (org_id, dashboard_id, role, permission, created, updated) VALUES (?, ?, ?, ?, ?, ?)", 100, folder.Id, editor, 1, now, now},
+				// grants to other users and roles are not part of the access of the user
+				{"INSERT INTO dashboard_acl (org_id, dashboard_id, role, permission, created, updated) VALUES (?, ?, ?, ?, ?, ?)", 100, shared.Id, viewer, 1, now, now},
+				{"INSERT INTO dashboard_acl (org_id, dashboard_id, user_id, permission, created, updated) VALUES (?, ?, ?, ?, ?, ?)", 100, shared.Id, other, 4, now, now},
+			} {
+				if _, err := sess.Exec(args...); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		report, err := userStore.GetEffectiveAccess(context.Background(), reviewed)
+		require.NoError(t, err)
+		assert.Equal(t, "loginuser1", report.Login)
+		var access *user.EffectiveOrgAccess
+		for _, o := range report.Orgs {
+			if o.OrgID == 100 {
+				access = o
+			}
+		}
+		require.NotNil(t, access)
+		assert.Equal(t, "reviewed org", access.Name)
+		assert.Equal(t, org.RoleEditor, access.Role)
+		assert.Equal(t, []*user.EffectiveTeamAccess{{TeamID: 5, Name: "platform", Permission: 4}}, access.Teams)
+		assert.Equal(t, []*user.EffectiveDashboardAccess{
+			{DashboardID: dashboard.Id, UID: "dashboard", Title: "dashboard", Permission: 2, PermissionName: "Edit", Sources: []string{"user", "team:platform"}},
+			{DashboardID: folder.Id, UID: "folder", Title: "folder", IsFolder: true, Permission: 1, PermissionName: "View", Sources: []string{"role:Editor"}},
+		}, access.Dashboards)
+
+		_, err = userStore.GetEffectiveAccess(context.Background(), 1000)
+		require.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+
+	t.Run("Testing DB - last login of a user", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		cfg := setting.NewCfg()
+		userStore = ProvideStore(ss, cfg)
+
+		usr, err := ss.CreateUser(context.Background(), user.CreateUserCommand{Login: "login", Email: "login@test.com"})
+		require.NoError(t, err)
+
+		loginAt := time.Now().Truncate(time.Second)
+		err = userStore.UpdateLastLogin(context.Background(), usr.ID, &user.LoginClient{IP: "192.168.0.1", UserAgent: "curl"}, loginAt)
+		require.NoError(t, err)
+
+		// the last login is only part of the profile when it is tracked
+		profile, err := userStore.GetProfile(context.Background(), &user.GetUserProfileQuery{UserID: usr.ID})
+		require.NoError(t, err)
+		assert.Nil(t, profile.LastLoginAt)
+		assert.Empty(t, profile.LastLoginIP)
+
+		cfg.LastLoginClientTracking = "raw"
+		profile, err = userStore.GetProfile(context.Background(), &user.GetUserProfileQuery{UserID: usr.ID})
+		require.NoError(t, err)
+		require.NotNil(t, profile.LastLoginAt)
+		assert.True(t, loginAt.Equal(*profile.LastLoginAt))
+		assert.Equal(t, "192.168.0.1", profile.LastLoginIP)
+		assert.Equal(t, "curl", profile.LastLoginUserAgent)
+	})
+
+	t.Run("Testing DB - user labels", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		users := createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+			return &user.CreateUserCommand{
+				Email: fmt.Sprint("user", i, "@test.com"),
+				Name:  fmt.Sprint("user", i),
+				Login: fmt.Sprint("loginuser", i),
+			}
+		})
+
+		err := userStore.SetLabels(context.Background(), &user.SetUserLabelsCommand{UserID: users[0].ID, Labels: []string{"contractor", "break-glass"}})
+		require.NoError(t, err)
+		err = userStore.SetLabels(context.Background(), &user.SetUserLabelsCommand{UserID: users[2].ID, Labels: []string{"contractor"}})
+		require.NoError(t, err)
+
+		labels, err := userStore.GetLabels(context.Background(), &user.GetUserLabelsQuery{UserID: users[0].ID})
+		require.NoError(t, err)
+		require.Equal(t, []string{"break-glass", "contractor"}, labels)
+
+		labels, err = userStore.GetLabels(context.Background(), &user.GetUserLabelsQuery{UserID: users[1].ID})
+		require.NoError(t, err)
+		require.Empty(t, labels)
+
+		queryResult, err := userStore.Search(context.Background(), &user.SearchUsersQuery{Label: "contractor", SignedInUser: usr})
+		require.NoError(t, err)
+		require.EqualValues(t, 2, queryResult.TotalCount)
+		require.Equal(t, []string{"loginuser0", "loginuser2"}, searchHitLogins(queryResult.Users))
+
+		// setting the labels again replaces them
+		err = userStore.SetLabels(context.Background(), &user.SetUserLabelsCommand{UserID: users[0].ID, Labels: []string{"break-glass"}})
+		require.NoError(t, err)
+		queryResult, err = userStore.Search(context.Background(), &user.SearchUsersQuery{Label: "contractor", SignedInUser: usr})
+		require.NoError(t, err)
+		require.Equal(t, []string{"loginuser2"}, searchHitLogins(queryResult.Users))
+
+		err = userStore.SetLabels(context.Background(), &user.SetUserLabelsCommand{UserID: users[0].ID})
+		require.NoError(t, err)
+		labels, err = userStore.GetLabels(context.Background(), &user.GetUserLabelsQuery{UserID: users[0].ID})
+		require.NoError(t, err)
+		require.Empty(t, labels)
+
+		err = userStore.SetLabels(context.Background(), &user.SetUserLabelsCommand{UserID: 1000, Labels: []string{"contractor"}})
+		require.ErrorIs(t, err, user.ErrUserNotFound)
+		_, err = userStore.GetLabels(context.Background(), &user.GetUserLabelsQuery{UserID: 1000})
+		require.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+
+	t.Run("Testing DB - password history", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		cfg := setting.NewCfg()
+		cfg.PasswordHistoryCount = 2
+		userStore = ProvideStore(ss, cfg)
+
+		usr, err := ss.CreateUser(context.Background(), user.CreateUserCommand{Login: "user", Email: "user@test.com"})
+		require.NoError(t, err)
+
+		for _, password := range []string{"first", "second", "third"} {
+			err := userStore.ChangePassword(context.Background(), &user.ChangeUserPasswordCommand{UserID: usr.ID, NewPassword: password})
+			require.NoError(t, err)
+		}
+
+		// only the configured number of passwords is kept
+		history, err := userStore.GetPasswordHistory(context.Background(), usr.ID, 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"third", "second"}, history)
+
+		history, err = userStore.GetPasswordHistory(context.Background(), usr.ID, 1)
+		require.NoError(t, err)
+		require.Equal(t, []string{"third"}, history)
+
+		err = userStore.BatchDeleteUsers(context.Background(), []int64{usr.ID})
+		require.NoError(t, err)
+		history, err = userStore.GetPasswordHistory(context.Background(), usr.ID, 10)
+		require.NoError(t, err)
+		require.Empty(t, history)
+	})
+
+	t.Run("Testing DB - batch update last seen at", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		users := createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+			return &user.CreateUserCommand{
+				Email: fmt.Sprint("user", i, "@test.com"),
+				Name:  fmt.Sprint("user", i),
+				Login: fmt.Sprint("loginuser", i),
+			}
+		})
+
+		lastSeenAt := time.Now().Truncate(time.Second)
+		err := userStore.BatchUpdateLastSeenAt(context.Background(), []int64{users[0].ID, users[2].ID}, lastSeenAt)
+		require.NoError(t, err)
+
+		for i, u := range users {
+			stored, err := userStore.GetByID(context.Background(), u.ID)
+			require.NoError(t, err)
+			if i == 0 || i == 2 {
+				require.True(t, lastSeenAt.Equal(stored.LastSeenAt), "user %d", i)
+			} else {
+				require.True(t, stored.LastSeenAt.Before(lastSeenAt.AddDate(-1, 0, 0)), "user %d", i)
+			}
+		}
+	})
+
+	t.Run("Testing DB - account lockout", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		usr, err := ss.CreateUser(context.Background(), user.CreateUserCommand{Login: "user", Email: "user@test.com"})
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			require.NoError(t, userStore.RecordFailedLogin(context.Background(), usr.ID, 3, time.Hour))
+		}
+		locked, err := userStore.IsLocked(context.Background(), usr.ID)
+		require.NoError(t, err)
+		require.False(t, locked)
+		stored, err := userStore.GetByID(context.Background(), usr.ID)
+		require.NoError(t, err)
+		require.Equal(t, 2, stored.FailedLoginAttempts)
+
+		require.NoError(t, userStore.RecordFailedLogin(context.Background(), usr.ID, 3, time.Hour))
+		locked, err = userStore.IsLocked(context.Background(), usr.ID)
+		require.NoError(t, err)
+		require.True(t, locked)
+
+		require.NoError(t, userStore.ResetLockout(context.Background(), usr.ID))
+		locked, err = userStore.IsLocked(context.Background(), usr.ID)
+		require.NoError(t, err)
+		require.False(t, locked)
+		stored, err = userStore.GetByID(context.Background(), usr.ID)
+		require.NoError(t, err)
+		require.Zero(t, stored.FailedLoginAttempts)
+		require.Nil(t, stored.LockedUntil)
+
+		// the lockout expires
+		require.NoError(t, userStore.RecordFailedLogin(context.Background(), usr.ID, 1, -time.Minute))
+		locked, err = userStore.IsLocked(context.Background(), usr.ID)
+		require.NoError(t, err)
+		require.False(t, locked)
+
+		require.ErrorIs(t, userStore.ResetLockout(context.Background(), 1000), user.ErrUserNotFound)
+	})
+
+	t.Run("Testing DB - get users by IDs", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		users := createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+			return &user.CreateUserCommand{
+				Email: fmt.Sprint("user", i, "@test.com"),
+				Login: fmt.Sprint("loginuser", i),
+			}
+		})
+		serviceAccount, err := ss.CreateUser(context.Background(), user.CreateUserCommand{Login: "sa", IsServiceAccount: true})
+		require.NoError(t, err)
+
+		// more IDs than a chunk, most of them don't exist
+		userIDs := []int64{users[0].ID, users[2].ID, serviceAccount.ID}
+		for i := int64(1000); len(userIDs) < getByIDsChunkSize+10; i++ {
+			userIDs = append(userIDs, i)
+		}
+		userIDs = append(userIDs, users[4].ID)
+
+		found, err := userStore.GetByIDs(context.Background(), userIDs)
+		require.NoError(t, err)
+		require.Len(t, found, 3)
+		for _, i := range []int{0, 2, 4} {
+			require.Contains(t, found, users[i].ID)
+			assert.Equal(t, fmt.Sprint("loginuser", i), found[users[i].ID].Login)
+		}
+
+		found, err = userStore.GetByIDs(context.Background(), nil)
+		require.NoError(t, err)
+		require.Empty(t, found)
+	})
+
+	t.Run("Testing DB - audit entries", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		for i, action := range []user.AuditAction{user.AuditActionUpdate, user.AuditActionDisable, user.AuditActionUpdate} {
+			err := userStore.InsertAuditEntry(context.Background(), &user.AuditEntry{
+				UserID:     1,
+				ActorID:    2,
+				ActorLogin: "admin",
+				Action:     action,
+				Changes:    []user.AuditChange{{Field: "name", OldValue: fmt.Sprint("name", i), NewValue: fmt.Sprint("name", i+1)}},
+				Created:    time.Now(),
+			})
+			require.NoError(t, err)
+		}
+		require.NoError(t, userStore.InsertAuditEntry(context.Background(), &user.AuditEntry{UserID: 3, Action: user.AuditActionEnable, Created: time.Now()}))
+		require.NoError(t, userStore.InsertAuditEntry(context.Background(), &user.AuditEntry{UserID: 4, ActorID: 4, Action: user.AuditActionUpdate, ImpersonatorID: 2, ImpersonatorLogin: "admin", Created: time.Now()}))
+
+		result, err := userStore.GetAuditEntries(context.Background(), &user.GetAuditEntriesQuery{UserID: 1, Page: 1, Limit: 2})
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, result.TotalCount)
+		require.Len(t, result.Entries, 2)
+		// the latest entries come first
+		assert.Equal(t, []user.AuditChange{{Field: "name", OldValue: "name2", NewValue: "name3"}}, result.Entries[0].Changes)
+		assert.Equal(t, "admin", result.Entries[0].ActorLogin)
+
+		result, err = userStore.GetAuditEntries(context.Background(), &user.GetAuditEntriesQuery{UserID: 1, Action: user.AuditActionUpdate, Page: 2, Limit: 1})
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, result.TotalCount)
+		require.Len(t, result.Entries, 1)
+		assert.Equal(t, "name0", result.Entries[0].Changes[0].OldValue)
+
+		result, err = userStore.GetAuditEntries(context.Background(), &user.GetAuditEntriesQuery{UserID: 4, Page: 1, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, result.Entries, 1)
+		assert.Equal(t, int64(2), result.Entries[0].ImpersonatorID)
+		assert.Equal(t, "admin", result.Entries[0].ImpersonatorLogin)
+	})
+
+	t.Run("Testing DB - audit entries are rolled back with the audited change", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		err := userStore.InTransaction(context.Background(), func(ctx context.Context) error {
+			if err := userStore.InsertAuditEntry(ctx, &user.AuditEntry{UserID: 1, Action: user.AuditActionUpdate, Created: time.Now()}); err != nil {
+				return err
+			}
+			return errors.New("failed change")
+		})
+		require.Error(t, err)
+
+		result, err := userStore.GetAuditEntries(context.Background(), &user.GetAuditEntriesQuery{UserID: 1, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.Zero(t, result.TotalCount)
+	})
+
+	t.Run("Testing DB - updates of users are rejected when the user changed since it was read", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+		usr, err := ss.CreateUser(context.Background(), user.CreateUserCommand{Login: "user", Email: "user@example.com"})
+		require.NoError(t, err)
+		require.Equal(t, 1, usr.Version)
+
+		require.NoError(t, userStore.Update(context.Background(), &user.UpdateUserCommand{UserID: usr.ID, Name: "first", Version: 1}))
+		err = userStore.Update(context.Background(), &user.UpdateUserCommand{UserID: usr.ID, Name: "second", Version: 1})
+		require.ErrorIs(t, err, user.ErrUserVersionConflict)
+		// updates without a version are not checked
+		require.NoError(t, userStore.Update(context.Background(), &user.UpdateUserCommand{UserID: usr.ID, Name: "third"}))
+
+		updated, err := userStore.GetByID(context.Background(), usr.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "third", updated.Name)
+		assert.Equal(t, 3, updated.Version)
+
+		require.NoError(t, userStore.UpdateUser(context.Background(), &user.User{ID: usr.ID, Theme: "dark", Version: 3}))
+		require.ErrorIs(t, userStore.UpdateUser(context.Background(), &user.User{ID: usr.ID, Theme: "light", Version: 3}), user.ErrUserVersionConflict)
+		updated, err = userStore.GetByID(context.Background(), usr.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "dark", updated.Theme)
+		assert.Equal(t, 4, updated.Version)
+
+		// updates of missing users are no-ops
+		require.NoError(t, userStore.Update(context.Background(), &user.UpdateUserCommand{UserID: usr.ID + 1, Name: "missing", Version: 1}))
+	})
+
+	t.Run("Testing DB - updated users are dropped from the signed in user cache", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+		userService := ProvideService(ss, orgtest.NewOrgServiceFake(), ss.Cfg, teamtest.NewFakeService(), localcache.ProvideService(), nil, ss.Bus())
+		usr, err := ss.CreateUser(context.Background(), user.CreateUserCommand{Login: "user", Email: "user@example.com"})
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		userService.signedInUsers.set(ctx, usr.OrgID, &user.SignedInUser{UserID: usr.ID, OrgID: usr.OrgID, Name: usr.Name})
+		_, found := userService.signedInUsers.get(ctx, usr.OrgID, usr.ID)
+		require.True(t, found)
+
+		require.NoError(t, userStore.Update(ctx, &user.UpdateUserCommand{UserID: usr.ID, Name: "renamed"}))
+		_, found = userService.signedInUsers.get(ctx, usr.OrgID, usr.ID)
+		assert.False(t, found)
+	})
+
+	t.Run("Testing DB - email verifications", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+		usr, err := ss.CreateUser(context.Background(), user.CreateUserCommand{Login: "user", Email: "user@example.com"})
+		require.NoError(t, err)
+
+		for _, tokenHash := range []string{"old", "new"} {
+			err := userStore.CreateEmailVerification(context.Background(), &emailVerification{UserID: usr.ID, Email: usr.Email, TokenHash: tokenHash, Created: time.Now(), Expires: time.Now().Add(time.Hour)})
+			require.NoError(t, err)
+		}
+		// issuing a token revokes the previous ones
+		_, err = userStore.GetEmailVerification(context.Background(), "old")
+		require.ErrorIs(t, err, user.ErrEmailVerificationTokenInvalid)
+		verification, err := userStore.GetEmailVerification(context.Background(), "new")
+		require.NoError(t, err)
+		assert.Equal(t, usr.ID, verification.UserID)
+
+		require.ErrorIs(t, userStore.SetEmailVerified(context.Background(), usr.ID, "other@example.com"), user.ErrEmailVerificationTokenInvalid)
+		require.NoError(t, userStore.SetEmailVerified(context.Background(), usr.ID, verification.Email))
+		verified, err := userStore.GetByID(context.Background(), usr.ID)
+		require.NoError(t, err)
+		assert.True(t, verified.EmailVerified)
+		_, err = userStore.GetEmailVerification(context.Background(), "new")
+		require.ErrorIs(t, err, user.ErrEmailVerificationTokenInvalid)
+
+		// a new email address has to be verified again
+		require.NoError(t, userStore.Update(context.Background(), &user.UpdateUserCommand{UserID: usr.ID, Name: "user"}))
+		updated, err := userStore.GetByID(context.Background(), usr.ID)
+		require.NoError(t, err)
+		assert.True(t, updated.EmailVerified)
+		require.NoError(t, userStore.Update(context.Background(), &user.UpdateUserCommand{UserID: usr.ID, Email: "other@example.com"}))
+		updated, err = userStore.GetByID(context.Background(), usr.ID)
+		require.NoError(t, err)
+		assert.False(t, updated.EmailVerified)
+	})
+
+	t.Run("Testing DB - get inactive users", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		users := createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
 			return &user.CreateUserCommand{
 				Email:      fmt.Sprint("user", i, "@test.com"),
-				Name:       fmt.Sprint("user", i),
 				Login:      fmt.Sprint("loginuser", i),
-				IsDisabled: false,
+				IsAdmin:    i == 1,
+				IsDisabled: i == 3,
+			}
+		})
+		_, err := ss.CreateUser(context.Background(), user.CreateUserCommand{Login: "sa", IsServiceAccount: true})
+		require.NoError(t, err)
+
+		longAgo := time.Now().AddDate(0, 0, -200)
+		err = ss.WithDbSession(context.Background(), func(sess *db.Session) error {
+			// the third user never logged in since its recent creation
+			_, err := sess.Exec("UPDATE "+ss.Dialect.Quote("user")+" SET last_seen_at = ?, created = ? WHERE id IN (?, ?, ?)", longAgo, longAgo, users[0].ID, users[1].ID, users[3].ID)
+			if err != nil {
+				return err
 			}
+			_, err = sess.Exec("UPDATE "+ss.Dialect.Quote("user")+" SET last_seen_at = ? WHERE id = ?", longAgo, users[2].ID)
+			return err
 		})
+		require.NoError(t, err)
+
+		inactive, err := userStore.GetInactiveUsers(context.Background(), time.Now().AddDate(0, 0, -90))
+		require.NoError(t, err)
+		require.Len(t, inactive, 1)
+		assert.Equal(t, users[0].ID, inactive[0].ID)
+		assert.Equal(t, "loginuser0", inactive[0].Login)
+		assert.Equal(t, "user0@test.com", inactive[0].Email)
 	})
 
-	t.Run("Disable user", func(t *testing.T) {
-		id, err := userStore.Insert(context.Background(), &user.User{
-			Name:    "user111",
-			Created: time.Now(),
-			Updated: time.Now(),
+	t.Run("Testing DB - get user conflicts", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		// users are created before enabling case insensitive logins, like on older instances
+		users := createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+			return &user.CreateUserCommand{
+				Email: []string{"user@test.com", "USER@test.com", "other@test.com", "another@test.com", "unique@test.com"}[i],
+				Login: []string{"user", "User", "other", "USER", "unique"}[i],
+			}
 		})
+		_, err := ss.CreateUser(context.Background(), user.CreateUserCommand{Login: "sa-User", Email: "User", IsServiceAccount: true})
 		require.NoError(t, err)
-		err = userStore.Disable(context.Background(), &user.DisableUserCommand{UserID: id})
+
+		conflicts, err := userStore.GetConflicts(context.Background())
+		require.NoError(t, err)
+		require.Len(t, conflicts, 3)
+
+		pairs := make([][2]int64, 0, len(conflicts))
+		for _, c := range conflicts {
+			pairs = append(pairs, [2]int64{c.Users[0].ID, c.Users[1].ID})
+		}
+		assert.Equal(t, [][2]int64{{users[0].ID, users[1].ID}, {users[0].ID, users[3].ID}, {users[1].ID, users[3].ID}}, pairs)
+		assert.True(t, conflicts[0].LoginConflict)
+		assert.True(t, conflicts[0].EmailConflict)
+		assert.True(t, conflicts[1].LoginConflict)
+		assert.False(t, conflicts[1].EmailConflict)
+		assert.Equal(t, "User", conflicts[2].Users[0].Login)
+	})
+
+	t.Run("Testing DB - merge users", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		users := createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+			return &user.CreateUserCommand{
+				Email: fmt.Sprint("user", i, "@test.com"),
+				Name:  fmt.Sprint("user", i),
+				Login: fmt.Sprint("loginuser", i),
+			}
+		})
+		target, source := users[0].ID, users[1].ID
+
+		var published []*events.UsersDeleted
+		ss.Bus().AddEventListener(func(ctx context.Context, e *events.UsersDeleted) error {
+			published = append(published, e)
+			return nil
+		})
+
+		now := time.Now()
+		err := ss.WithDbSession(context.Background(), func(sess *db.Session) error {
+			for _, args := range [][]interface{}{
+				// both users belong to their own org and to a shared one
+				{"INSERT INTO org_user (org_id, user_id, role, created, updated) VALUES (?, ?, ?, ?, ?)", 100, target, "Viewer", now, now},
+				{"INSERT INTO org_user (org_id, user_id, role, created, updated) VALUES (?, ?, ?, ?, ?)", 100, source, "Editor", now, now},
+				{"INSERT INTO dashboard_acl (org_id, dashboard_id, user_id, permission, created, updated) VALUES (?, ?, ?, ?, ?, ?)", 1, 10, target, 1, now, now},
+				{"INSERT INTO dashboard_acl (org_id, dashboard_id, user_id, permission, created, updated) VALUES (?, ?, ?, ?, ?, ?)", 1, 10, source, 2, now, now},
+				{"INSERT INTO dashboard_acl (org_id, dashboard_id, user_id, permission, created, updated) VALUES (?, ?, ?, ?, ?, ?)", 1, 11, source, 2, now, now},
+				{"INSERT INTO preferences (org_id, user_id, team_id, version, home_dashboard_id, timezone, theme, created, updated) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)", 2, source, 0, 0, 0, "utc", "dark", now, now},
+				{"INSERT INTO team_member (org_id, team_id, user_id, created, updated) VALUES (?, ?, ?, ?, ?)", 1, 5, source, now, now},
+				{"INSERT INTO star (user_id, dashboard_id) VALUES (?, ?)", source, 10},
+			} {
+				if _, err := sess.Exec(args...); err != nil {
+					return err
+				}
+			}
+			_, err := sess.Insert(&models.UserAuth{UserId: source, AuthModule: "oauth_okta", AuthId: "1", Created: now})
+			return err
+		})
+		require.NoError(t, err)
+
+		merged, err := userStore.MergeUsers(context.Background(), target, source)
+		require.NoError(t, err)
+		assert.Equal(t, &mergedUserRows{
+			OrgMemberships:       1,
+			DashboardPermissions: 1,
+			Preferences:          1,
+			TeamMemberships:      1,
+			AuthIdentities:       1,
+			Stars:                1,
+		}, merged)
+
+		_, err = userStore.GetByID(context.Background(), source)
+		require.ErrorIs(t, err, user.ErrUserNotFound)
+		require.Len(t, published, 1)
+		require.Equal(t, []int64{source}, published[0].Ids)
+
+		err = ss.WithDbSession(context.Background(), func(sess *db.Session) error {
+			for table, expected := range map[string]int64{"org_user": 3, "dashboard_acl": 2, "preferences": 1, "team_member": 1, "user_auth": 1, "star": 1} {
+				count, err := sess.Table(table).Where("user_id = ?", target).Count()
+				if err != nil {
+					return err
+				}
+				assert.Equal(t, expected, count, table)
+
+				count, err = sess.Table(table).Where("user_id = ?", source).Count()
+				if err != nil {
+					return err
+				}
+				assert.Zero(t, count, table)
+			}
+
+			// the target user keeps its own permission on the dashboard both users had one on
+			var permission int
+			_, err := sess.SQL("SELECT permission FROM dashboard_acl WHERE dashboard_id = ? AND user_id = ?", 10, target).Get(&permission)
+			assert.Equal(t, 1, permission)
+			return err
+		})
+		require.NoError(t, err)
+
+		require.ErrorIs(t, func() error {
+			_, err := userStore.MergeUsers(context.Background(), target, 1000)
+			return err
+		}(), user.ErrUserNotFound)
+	})
+
+	t.Run("Testing DB - transfer ownership", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		users := createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+			return &user.CreateUserCommand{
+				Email: fmt.Sprint("user", i, "@test.com"),
+				Login: fmt.Sprint("loginuser", i),
+			}
+		})
+		deleted, successor, other := users[0], users[1], users[2]
+		err := ss.WithDbSession(context.Background(), func(sess *db.Session) error {
+			dashboard := models.NewDashboard("dashboard")
+			dashboard.OrgId, dashboard.CreatedBy, dashboard.Uid = deleted.OrgID, deleted.ID, "dashboard"
+			folder := models.NewDashboardFolder("folder")
+			folder.OrgId, folder.CreatedBy, folder.Uid = deleted.OrgID, deleted.ID, "folder"
+			otherDashboard := models.NewDashboard("other dashboard")
+			otherDashboard.OrgId, otherDashboard.CreatedBy, otherDashboard.Uid = other.OrgID, other.ID, "other"
+			dataSource := &datasources.DataSource{OrgId: deleted.OrgID, Name: "ds", Type: "test", Access: datasources.DS_ACCESS_PROXY, Uid: "ds", CreatedBy: deleted.ID, Created: time.Now(), Updated: time.Now()}
+			key := &apikey.APIKey{OrgId: deleted.OrgID, Name: "key", Key: "key", Role: org.RoleViewer, CreatedBy: deleted.ID, Created: time.Now(), Updated: time.Now()}
+			_, err := sess.Insert(dashboard, folder, otherDashboard, dataSource, key)
+			return err
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, userStore.TransferOwnership(context.Background(), deleted.ID, successor.ID))
+
+		err = ss.WithDbSession(context.Background(), func(sess *db.Session) error {
+			for table, expected := range map[string]int64{"dashboard": 2, "data_source": 1, "api_key": 1} {
+				count, err := sess.Table(table).Where("created_by = ?", successor.ID).Count()
+				if err != nil {
+					return err
+				}
+				assert.Equal(t, expected, count, table)
+
+				count, err = sess.Table(table).Where("created_by = ?", deleted.ID).Count()
+				if err != nil {
+					return err
+				}
+				assert.Zero(t, count, table)
+			}
+
+			// resources of other users are left alone
+			count, err := sess.Table("dashboard").Where("created_by = ?", other.ID).Count()
+			assert.Equal(t, int64(1), count)
+			return err
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Testing DB - set field locks", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		users := createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+			return &user.CreateUserCommand{
+				Email: fmt.Sprint("user", i, "@test.com"),
+				Login: fmt.Sprint("loginuser", i),
+			}
+		})
+
+		err := userStore.SetFieldLocks(context.Background(), &user.SetUserFieldLocksCommand{UserID: users[0].ID, Email: true, Login: true})
+		require.NoError(t, err)
+
+		usr, err := userStore.GetByID(context.Background(), users[0].ID)
+		require.NoError(t, err)
+		assert.False(t, usr.NameLocked)
+		assert.True(t, usr.EmailLocked)
+		assert.True(t, usr.LoginLocked)
+
+		profile, err := userStore.GetProfile(context.Background(), &user.GetUserProfileQuery{UserID: users[0].ID})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"email", "login"}, profile.LockedFields)
+
+		// other users are left alone
+		usr, err = userStore.GetByID(context.Background(), users[1].ID)
+		require.NoError(t, err)
+		assert.Empty(t, usr.LockedFields())
+
+		err = userStore.SetFieldLocks(context.Background(), &user.SetUserFieldLocksCommand{UserID: 1000})
+		require.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+
+	t.Run("Testing DB - login history", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		now := time.Now()
+		for _, entry := range []*user.LoginHistoryEntry{
+			{UserID: 1, Login: "user1", AuthModule: "grafana", Result: user.LoginResultFailure, Created: now.Add(-48 * time.Hour)},
+			{UserID: 1, Login: "user1", AuthModule: "grafana", Result: user.LoginResultSuccess, Created: now.Add(-time.Hour)},
+			{UserID: 2, Login: "user2", AuthModule: "oauth_github", Result: user.LoginResultSuccess, Created: now.Add(-time.Hour)},
+			{Login: "unknown", AuthModule: "grafana", Result: user.LoginResultFailure, Created: now},
+		} {
+			require.NoError(t, userStore.InsertLoginHistory(context.Background(), entry))
+			require.NotZero(t, entry.ID)
+		}
+
+		result, err := userStore.GetLoginHistory(context.Background(), &user.GetLoginHistoryQuery{Page: 1, Limit: 2})
+		require.NoError(t, err)
+		require.Equal(t, int64(4), result.TotalCount)
+		require.Len(t, result.Entries, 2)
+		assert.Equal(t, "unknown", result.Entries[0].Login)
+		assert.Equal(t, "user2", result.Entries[1].Login)
+
+		result, err = userStore.GetLoginHistory(context.Background(), &user.GetLoginHistoryQuery{UserID: 1, Result: user.LoginResultFailure, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.TotalCount)
+		assert.Equal(t, user.LoginResultFailure, result.Entries[0].Result)
+
+		result, err = userStore.GetLoginHistory(context.Background(), &user.GetLoginHistoryQuery{AuthModule: "grafana", From: now.Add(-2 * time.Hour), To: now.Add(-time.Minute), Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.TotalCount)
+		assert.Equal(t, user.LoginResultSuccess, result.Entries[0].Result)
+
+		purged, err := userStore.PurgeLoginHistory(context.Background(), now.Add(-24*time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), purged)
+
+		result, err = userStore.GetLoginHistory(context.Background(), &user.GetLoginHistoryQuery{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), result.TotalCount)
+	})
+
+	t.Run("Testing DB - batch delete users", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		users := createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+			return &user.CreateUserCommand{
+				Email: fmt.Sprint("user", i, "@test.com"),
+				Name:  fmt.Sprint("user", i),
+				Login: fmt.Sprint("loginuser", i),
+			}
+		})
+		serviceAccount, err := ss.CreateUser(context.Background(), user.CreateUserCommand{Login: "sa", IsServiceAccount: true})
+		require.NoError(t, err)
+
+		var published []*events.UsersDeleted
+		ss.Bus().AddEventListener(func(ctx context.Context, e *events.UsersDeleted) error {
+			published = append(published, e)
+			return nil
+		})
+
+		// more users than a chunk, most of them don't exist
+		userIDs := []int64{users[0].ID, users[1].ID, users[2].ID, serviceAccount.ID}
+		for i := int64(1000); len(userIDs) < batchDeleteUsersChunkSize+10; i++ {
+			userIDs = append(userIDs, i)
+		}
+		err = userStore.BatchDeleteUsers(context.Background(), userIDs)
+		require.NoError(t, err)
+
+		require.Len(t, published, 1)
+		require.ElementsMatch(t, []int64{users[0].ID, users[1].ID, users[2].ID}, published[0].Ids)
+
+		for i, u := range users {
+			_, err := userStore.GetByID(context.Background(), u.ID)
+			if i < 3 {
+				require.ErrorIs(t, err, user.ErrUserNotFound)
+			} else {
+				require.NoError(t, err)
+			}
+		}
+		err = ss.WithDbSession(context.Background(), func(sess *db.Session) error {
+			count, err := sess.Table("org_user").In("user_id", users[0].ID, users[1].ID, users[2].ID).Count()
+			require.Zero(t, count)
+			return err
+		})
+		require.NoError(t, err)
+
+		err = ss.WithDbSession(context.Background(), func(sess *db.Session) error {
+			has, err := sess.Table("user").Where("id = ?", serviceAccount.ID).Exist()
+			require.True(t, has)
+			return err
+		})
 		require.NoError(t, err)
 	})
 
@@ -665,7 +1867,7 @@ func TestIntegrationUserDataAccess(t *testing.T) {
 		})
 
 		// Return the first page of users and a total count
-		query := user.SearchUsersQuery{Query: "", Page: 1, Limit: 3, SignedInUser: usr}
+		query := user.SearchUsersQuery{Query: "", Pagination: pagination.Page{Page: 1, PerPage: 3}, SignedInUser: usr}
 		queryResult, err := userStore.Search(context.Background(), &query)
 
 		require.Nil(t, err)
@@ -673,36 +1875,80 @@ func TestIntegrationUserDataAccess(t *testing.T) {
 		require.EqualValues(t, queryResult.TotalCount, 5)
 
 		// Return the second page of users and a total count
-		query = user.SearchUsersQuery{Query: "", Page: 2, Limit: 3, SignedInUser: usr}
+		query = user.SearchUsersQuery{Query: "", Pagination: pagination.Page{Page: 2, PerPage: 3}, SignedInUser: usr}
 		queryResult, err = userStore.Search(context.Background(), &query)
 
 		require.Nil(t, err)
 		require.Len(t, queryResult.Users, 2)
 		require.EqualValues(t, queryResult.TotalCount, 5)
 
+		// Return the first page of users in descending order, then the following page from a cursor
+		query = user.SearchUsersQuery{Query: "", Pagination: pagination.Page{PerPage: 3, Order: pagination.SortDescending}, SignedInUser: usr}
+		queryResult, err = userStore.Search(context.Background(), &query)
+
+		require.Nil(t, err)
+		require.Len(t, queryResult.Users, 3)
+		require.Equal(t, "loginuser4", queryResult.Users[0].Login)
+
+		query = user.SearchUsersQuery{Query: "", Pagination: pagination.Page{PerPage: 3, Cursor: pagination.NewCursor(3), Order: pagination.SortDescending}, SignedInUser: usr}
+		queryResult, err = userStore.Search(context.Background(), &query)
+
+		require.Nil(t, err)
+		require.Len(t, queryResult.Users, 2)
+		require.Equal(t, "loginuser1", queryResult.Users[0].Login)
+
+		// Page through users from the key cursors returned with each page
+		query = user.SearchUsersQuery{Query: "", Pagination: pagination.Page{Page: 1, PerPage: 2}, SignedInUser: usr}
+		queryResult, err = userStore.Search(context.Background(), &query)
+
+		require.Nil(t, err)
+		require.Equal(t, []string{"loginuser0", "loginuser1"}, searchHitLogins(queryResult.Users))
+		require.Equal(t, pagination.Result{TotalCount: 5, Page: 1, PerPage: 2, NextCursor: pagination.NewKeyCursor("loginuser1")}, queryResult.Result)
+
+		query = user.SearchUsersQuery{Query: "", Pagination: pagination.Page{PerPage: 2, Cursor: queryResult.NextCursor}, SignedInUser: usr}
+		queryResult, err = userStore.Search(context.Background(), &query)
+
+		require.Nil(t, err)
+		require.Equal(t, []string{"loginuser2", "loginuser3"}, searchHitLogins(queryResult.Users))
+		require.Equal(t, pagination.NewKeyCursor("loginuser3"), queryResult.NextCursor)
+
+		query = user.SearchUsersQuery{Query: "", Pagination: pagination.Page{PerPage: 2, Cursor: queryResult.NextCursor}, SignedInUser: usr}
+		queryResult, err = userStore.Search(context.Background(), &query)
+
+		require.Nil(t, err)
+		require.Equal(t, []string{"loginuser4"}, searchHitLogins(queryResult.Users))
+		require.Empty(t, queryResult.NextCursor)
+		require.EqualValues(t, 5, queryResult.TotalCount)
+
+		query = user.SearchUsersQuery{Query: "", Pagination: pagination.Page{PerPage: 2, Cursor: pagination.NewKeyCursor("loginuser3"), Order: pagination.SortDescending}, SignedInUser: usr}
+		queryResult, err = userStore.Search(context.Background(), &query)
+
+		require.Nil(t, err)
+		require.Equal(t, []string{"loginuser2", "loginuser1"}, searchHitLogins(queryResult.Users))
+
 		// Return list of users matching query on user name
-		query = user.SearchUsersQuery{Query: "use", Page: 1, Limit: 3, SignedInUser: usr}
+		query = user.SearchUsersQuery{Query: "use", Pagination: pagination.Page{Page: 1, PerPage: 3}, SignedInUser: usr}
 		queryResult, err = userStore.Search(context.Background(), &query)
 
 		require.Nil(t, err)
 		require.Len(t, queryResult.Users, 3)
 		require.EqualValues(t, queryResult.TotalCount, 5)
 
-		query = user.SearchUsersQuery{Query: "ser1", Page: 1, Limit: 3, SignedInUser: usr}
+		query = user.SearchUsersQuery{Query: "ser1", Pagination: pagination.Page{Page: 1, PerPage: 3}, SignedInUser: usr}
 		queryResult, err = userStore.Search(context.Background(), &query)
 
 		require.Nil(t, err)
 		require.Len(t, queryResult.Users, 1)
 		require.EqualValues(t, queryResult.TotalCount, 1)
 
-		query = user.SearchUsersQuery{Query: "USER1", Page: 1, Limit: 3, SignedInUser: usr}
+		query = user.SearchUsersQuery{Query: "USER1", Pagination: pagination.Page{Page: 1, PerPage: 3}, SignedInUser: usr}
 		queryResult, err = userStore.Search(context.Background(), &query)
 
 		require.Nil(t, err)
 		require.Len(t, queryResult.Users, 1)
 		require.EqualValues(t, queryResult.TotalCount, 1)
 
-		query = user.SearchUsersQuery{Query: "idontexist", Page: 1, Limit: 3, SignedInUser: usr}
+		query = user.SearchUsersQuery{Query: "idontexist", Pagination: pagination.Page{Page: 1, PerPage: 3}, SignedInUser: usr}
 		queryResult, err = userStore.Search(context.Background(), &query)
 
 		require.Nil(t, err)
@@ -710,7 +1956,7 @@ func TestIntegrationUserDataAccess(t *testing.T) {
 		require.EqualValues(t, queryResult.TotalCount, 0)
 
 		// Return list of users matching query on email
-		query = user.SearchUsersQuery{Query: "ser1@test.com", Page: 1, Limit: 3, SignedInUser: usr}
+		query = user.SearchUsersQuery{Query: "ser1@test.com", Pagination: pagination.Page{Page: 1, PerPage: 3}, SignedInUser: usr}
 		queryResult, err = userStore.Search(context.Background(), &query)
 
 		require.Nil(t, err)
@@ -718,13 +1964,136 @@ func TestIntegrationUserDataAccess(t *testing.T) {
 		require.EqualValues(t, queryResult.TotalCount, 1)
 
 		// Return list of users matching query on login name
-		query = user.SearchUsersQuery{Query: "loginuser1", Page: 1, Limit: 3, SignedInUser: usr}
+		query = user.SearchUsersQuery{Query: "loginuser1", Pagination: pagination.Page{Page: 1, PerPage: 3}, SignedInUser: usr}
 		queryResult, err = userStore.Search(context.Background(), &query)
 
 		require.Nil(t, err)
 		require.Len(t, queryResult.Users, 1)
 		require.EqualValues(t, queryResult.TotalCount, 1)
 	})
+
+	t.Run("Testing DB - search users by last seen and auth module", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		users := createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+			return &user.CreateUserCommand{
+				Email: fmt.Sprint("user", i, "@test.com"),
+				Name:  fmt.Sprint("user", i),
+				Login: fmt.Sprint("loginuser", i),
+			}
+		})
+
+		now := time.Now()
+		err := ss.WithDbSession(context.Background(), func(sess *db.Session) error {
+			// users were last seen 0 to 4 days ago
+			for i, u := range users {
+				if _, err := sess.Exec("UPDATE "+ss.Dialect.Quote("user")+" SET last_seen_at = ? WHERE id = ?", now.AddDate(0, 0, -i), u.ID); err != nil {
+					return err
+				}
+			}
+			// users 0 and 1 logged in through okta, then user 1 through github
+			authInfos := []*models.UserAuth{
+				{UserId: users[0].ID, AuthModule: "oauth_okta", AuthId: "0", Created: now.Add(-time.Hour)},
+				{UserId: users[1].ID, AuthModule: "oauth_okta", AuthId: "1", Created: now.Add(-time.Hour)},
+				{UserId: users[1].ID, AuthModule: "oauth_github", AuthId: "1", Created: now},
+			}
+			for _, authInfo := range authInfos {
+				if _, err := sess.Insert(authInfo); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		lastSeenBefore := now.AddDate(0, 0, -2).Add(time.Minute)
+		query := user.SearchUsersQuery{LastSeenBefore: &lastSeenBefore, SignedInUser: usr}
+		queryResult, err := userStore.Search(context.Background(), &query)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, queryResult.TotalCount)
+		require.Equal(t, []string{"loginuser2", "loginuser3", "loginuser4"}, searchHitLogins(queryResult.Users))
+
+		lastSeenAfter := now.AddDate(0, 0, -3).Add(-time.Minute)
+		query = user.SearchUsersQuery{LastSeenBefore: &lastSeenBefore, LastSeenAfter: &lastSeenAfter, SignedInUser: usr}
+		queryResult, err = userStore.Search(context.Background(), &query)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, queryResult.TotalCount)
+		require.Equal(t, []string{"loginuser2", "loginuser3"}, searchHitLogins(queryResult.Users))
+
+		// only the most recent auth module of users is matched
+		query = user.SearchUsersQuery{AuthModule: "oauth_okta", SignedInUser: usr}
+		queryResult, err = userStore.Search(context.Background(), &query)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, queryResult.TotalCount)
+		require.Equal(t, []string{"loginuser0"}, searchHitLogins(queryResult.Users))
+
+		query = user.SearchUsersQuery{AuthModule: "oauth_github", LastSeenBefore: &lastSeenBefore, SignedInUser: usr}
+		queryResult, err = userStore.Search(context.Background(), &query)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, queryResult.TotalCount)
+		require.Empty(t, queryResult.Users)
+	})
+
+	t.Run("Testing DB - count users", func(t *testing.T) {
+		ss = db.InitTestDB(t)
+		userStore = ProvideStore(ss, setting.NewCfg())
+
+		users := createFiveTestUsers(t, ss, func(i int) *user.CreateUserCommand {
+			return &user.CreateUserCommand{
+				Email:      fmt.Sprint("user", i, "@test.com"),
+				Name:       fmt.Sprint("user", i),
+				Login:      fmt.Sprint("loginuser", i),
+				IsDisabled: i == 4,
+				IsAdmin:    i == 3,
+			}
+		})
+
+		now := time.Now()
+		err := ss.WithDbSession(context.Background(), func(sess *db.Session) error {
+			// users were last seen 0 to 80 days ago
+			for i, u := range users {
+				if _, err := sess.Exec("UPDATE "+ss.Dialect.Quote("user")+" SET last_seen_at = ? WHERE id = ?", now.AddDate(0, 0, -20*i), u.ID); err != nil {
+					return err
+				}
+			}
+			authInfos := []*models.UserAuth{
+				{UserId: users[0].ID, AuthModule: "oauth_okta", AuthId: "0", Created: now.Add(-time.Hour)},
+				{UserId: users[1].ID, AuthModule: "oauth_okta", AuthId: "1", Created: now.Add(-time.Hour)},
+				{UserId: users[1].ID, AuthModule: "oauth_github", AuthId: "1", Created: now},
+			}
+			for _, authInfo := range authInfos {
+				if _, err := sess.Insert(authInfo); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		result, err := userStore.Count(context.Background(), &user.SearchUsersQuery{SignedInUser: usr})
+		require.NoError(t, err)
+		assert.Equal(t, &user.CountUsersResult{
+			Total:        5,
+			Active:       2,
+			Disabled:     1,
+			Admins:       1,
+			ByAuthModule: map[string]int64{"oauth_okta": 1, "oauth_github": 1},
+		}, result)
+
+		// the counts are of the users matching the search
+		result, err = userStore.Count(context.Background(), &user.SearchUsersQuery{Query: "user1", SignedInUser: usr})
+		require.NoError(t, err)
+		assert.Equal(t, &user.CountUsersResult{Total: 1, Active: 1, ByAuthModule: map[string]int64{"oauth_github": 1}}, result)
+	})
+}
+
+func searchHitLogins(hits []*user.UserSearchHitDTO) []string {
+	logins := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		logins = append(logins, hit.Login)
+	}
+	return logins
 }
 
 func TestIntegrationUserUpdate(t *testing.T) {
@@ -943,3 +2312,14 @@ func (ss *sqlStore) getDashboardACLInfoList(query *models.GetDashboardACLInfoLis
 
 	return nil
 }
+
+var errOrgUserLink = errors.New("org user link failed")
+
+// failingOrgUserService fails to link users to their org
+type failingOrgUserService struct {
+	*orgtest.FakeOrgService
+}
+
+func (s *failingOrgUserService) InsertOrgUser(ctx context.Context, cmd *org.OrgUser) (int64, error) {
+	return 0, errOrgUserLink
+}
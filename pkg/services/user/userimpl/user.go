@@ -2,12 +2,20 @@ package userimpl
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/appcontext"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/models"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/org"
@@ -18,11 +26,13 @@ import (
 )
 
 type Service struct {
-	store        store
-	orgService   org.Service
-	teamService  team.Service
-	cacheService *localcache.CacheService
-	cfg          *setting.Cfg
+	log           log.Logger
+	store         store
+	orgService    org.Service
+	teamService   team.Service
+	signedInUsers *signedInUserCache
+	cfg           *setting.Cfg
+	lastSeen      *lastSeenBatcher
 }
 
 func ProvideService(
@@ -31,18 +41,38 @@ func ProvideService(
 	cfg *setting.Cfg,
 	teamService team.Service,
 	cacheService *localcache.CacheService,
-) user.Service {
+	remoteCache *remotecache.RemoteCache,
+	bus bus.Bus,
+) *Service {
 	store := ProvideStore(db, cfg)
-	return &Service{
-		store:        &store,
-		orgService:   orgService,
-		cfg:          cfg,
-		teamService:  teamService,
-		cacheService: cacheService,
+	s := &Service{
+		log:           log.New("user.service"),
+		store:         &store,
+		orgService:    orgService,
+		cfg:           cfg,
+		teamService:   teamService,
+		signedInUsers: newSignedInUserCache(cfg, cacheService, remoteCache),
+		lastSeen:      newLastSeenBatcher(),
 	}
+
+	// changes of the role or status of users are seen right away, even when their signed in users are cached
+	if bus != nil {
+		bus.AddEventListener(s.handleUserUpdated)
+		bus.AddEventListener(s.handleUsersDisabled)
+		bus.AddEventListener(s.handleUserDeleted)
+		bus.AddEventListener(s.handleUsersDeleted)
+		bus.AddEventListener(s.handleOrgUserUpdated)
+	}
+	return s
 }
 
 func (s *Service) Create(ctx context.Context, cmd *user.CreateUserCommand) (*user.User, error) {
+	if len(cmd.Password) > 0 {
+		if err := user.ValidatePassword(cmd.Password, s.cfg); err != nil {
+			return nil, err
+		}
+	}
+
 	cmdOrg := org.GetOrgIDForNewUserCommand{
 		Email:        cmd.Email,
 		Login:        cmd.Login,
@@ -82,6 +112,7 @@ func (s *Service) Create(ctx context.Context, cmd *user.CreateUserCommand) (*use
 		Updated:          time.Now(),
 		LastSeenAt:       time.Now().AddDate(-10, 0, 0),
 		IsServiceAccount: cmd.IsServiceAccount,
+		Version:          1,
 	}
 
 	salt, err := util.GetRandomString(10)
@@ -103,13 +134,18 @@ func (s *Service) Create(ctx context.Context, cmd *user.CreateUserCommand) (*use
 		usr.Password = encodedPassword
 	}
 
-	userID, err := s.store.Insert(ctx, usr)
-	if err != nil {
-		return nil, err
-	}
+	// the user and its org user link are inserted in a transaction, so no user is left without its org when the
+	// link fails
+	var ruleRoles map[int64]org.RoleType
+	err = s.store.InTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.store.Insert(ctx, usr); err != nil {
+			return err
+		}
 
-	// create org user link
-	if !cmd.SkipOrgSetup {
+		// create org user link
+		if cmd.SkipOrgSetup {
+			return nil
+		}
 		orgUser := org.OrgUser{
 			OrgID:   orgID,
 			UserID:  usr.ID,
@@ -118,30 +154,64 @@ func (s *Service) Create(ctx context.Context, cmd *user.CreateUserCommand) (*use
 			Updated: time.Now(),
 		}
 
+		ruleRoles = s.autoAssignOrgRoles(usr)
 		if setting.AutoAssignOrg && !usr.IsAdmin {
 			if len(cmd.DefaultOrgRole) > 0 {
 				orgUser.Role = org.RoleType(cmd.DefaultOrgRole)
+			} else if role, ok := ruleRoles[orgID]; ok {
+				orgUser.Role = role
 			} else {
 				orgUser.Role = org.RoleType(setting.AutoAssignOrgRole)
 			}
 		}
-		_, err = s.orgService.InsertOrgUser(ctx, &orgUser)
-		if err != nil {
-			err := s.store.Delete(ctx, userID)
-			return usr, err
-		}
+		_, err := s.orgService.InsertOrgUser(ctx, &orgUser)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !cmd.SkipOrgSetup {
+		s.assignOrgsByRules(ctx, usr, ruleRoles)
 	}
 
 	return usr, nil
 }
 
+// Delete soft deletes a user. When a successor is given, the dashboards, folders, public dashboards, library elements,
+// data sources and api keys created by the user are reassigned to the successor in the same transaction
 func (s *Service) Delete(ctx context.Context, cmd *user.DeleteUserCommand) error {
 	_, err := s.store.GetNotServiceAccount(ctx, cmd.UserID)
 	if err != nil {
 		return err
 	}
-	// delete from all the stores
-	return s.store.Delete(ctx, cmd.UserID)
+	if cmd.SuccessorID == 0 {
+		// soft delete, the user is removed from all the stores when purged
+		return s.store.Delete(ctx, cmd.UserID)
+	}
+
+	if cmd.SuccessorID == cmd.UserID {
+		return user.ErrInvalidSuccessor
+	}
+	if _, err := s.store.GetNotServiceAccount(ctx, cmd.SuccessorID); err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return user.ErrInvalidSuccessor
+		}
+		return err
+	}
+
+	return s.store.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.store.TransferOwnership(ctx, cmd.UserID, cmd.SuccessorID); err != nil {
+			return err
+		}
+		return s.store.Delete(ctx, cmd.UserID)
+	})
+}
+
+// GetByIDs returns the users of a list of IDs keyed by their ID, users which are not found are left out. Use it rather
+// than GetByID to load the users referenced by a list of resources, such as their creators
+func (s *Service) GetByIDs(ctx context.Context, userIDs []int64) (map[int64]*user.User, error) {
+	return s.store.GetByIDs(ctx, userIDs)
 }
 
 func (s *Service) GetByID(ctx context.Context, query *user.GetUserByIDQuery) (*user.User, error) {
@@ -165,16 +235,129 @@ func (s *Service) GetByEmail(ctx context.Context, query *user.GetUserByEmailQuer
 	return s.store.GetByEmail(ctx, query)
 }
 
+// Update updates the profile of a user and records the changed fields in the audit log
 func (s *Service) Update(ctx context.Context, cmd *user.UpdateUserCommand) error {
-	return s.store.Update(ctx, cmd)
+	usr, err := s.store.GetByID(ctx, cmd.UserID)
+	if err != nil {
+		return err
+	}
+	if cmd.UserInitiated {
+		if err := s.checkLockedFields(usr, cmd); err != nil {
+			return err
+		}
+	}
+
+	return s.audited(ctx, cmd.UserID, user.AuditActionUpdate, func(ctx context.Context) error {
+		return s.store.Update(ctx, cmd)
+	}, func() []user.AuditChange {
+		// the store lowercases logins and emails of case insensitive instances, the command holds the stored values
+		return auditFieldChanges(
+			[3]string{"name", usr.Name, cmd.Name},
+			[3]string{"email", usr.Email, cmd.Email},
+			[3]string{"login", usr.Login, cmd.Login},
+			[3]string{"theme", usr.Theme, cmd.Theme},
+		)
+	})
+}
+
+// checkLockedFields returns ErrFieldLocked when an update changes a locked field of the user
+func (s *Service) checkLockedFields(usr *user.User, cmd *user.UpdateUserCommand) error {
+	sameLogin := func(a, b string) bool {
+		if s.cfg.CaseInsensitiveLogin {
+			return strings.EqualFold(a, b)
+		}
+		return a == b
+	}
+
+	switch {
+	case usr.NameLocked && cmd.Name != usr.Name:
+		return fmt.Errorf("%w: name", user.ErrFieldLocked)
+	case usr.EmailLocked && !sameLogin(cmd.Email, usr.Email):
+		return fmt.Errorf("%w: email", user.ErrFieldLocked)
+	case usr.LoginLocked && !sameLogin(cmd.Login, usr.Login):
+		return fmt.Errorf("%w: login", user.ErrFieldLocked)
+	}
+	return nil
+}
+
+// SetFieldLocks locks or unlocks the name, email and login of a user, and records the change in the audit log
+func (s *Service) SetFieldLocks(ctx context.Context, cmd *user.SetUserFieldLocksCommand) error {
+	usr, err := s.store.GetNotServiceAccount(ctx, cmd.UserID)
+	if err != nil {
+		return err
+	}
+
+	return s.audited(ctx, usr.ID, user.AuditActionLockFields, func(ctx context.Context) error {
+		return s.store.SetFieldLocks(ctx, cmd)
+	}, func() []user.AuditChange {
+		changes := auditBoolChange("nameLocked", usr.NameLocked, cmd.Name)
+		changes = append(changes, auditBoolChange("emailLocked", usr.EmailLocked, cmd.Email)...)
+		return append(changes, auditBoolChange("loginLocked", usr.LoginLocked, cmd.Login)...)
+	})
 }
 
 func (s *Service) ChangePassword(ctx context.Context, cmd *user.ChangeUserPasswordCommand) error {
-	return s.store.ChangePassword(ctx, cmd)
+	usr, err := s.store.GetByID(ctx, cmd.UserID)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.IsReset && usr.Password != "" {
+		oldPassword, err := util.EncodePassword(cmd.OldPassword, usr.Salt)
+		if err != nil {
+			return err
+		}
+		if subtle.ConstantTimeCompare([]byte(oldPassword), []byte(usr.Password)) != 1 {
+			return user.ErrInvalidOldPassword
+		}
+	}
+
+	if err := user.ValidatePassword(cmd.NewPassword, s.cfg); err != nil {
+		return err
+	}
+
+	encodedPassword, err := util.EncodePassword(cmd.NewPassword, usr.Salt)
+	if err != nil {
+		return err
+	}
+
+	if s.cfg.PasswordHistoryCount > 0 {
+		// the current password counts as one of the passwords that cannot be reused
+		previous, err := s.store.GetPasswordHistory(ctx, cmd.UserID, s.cfg.PasswordHistoryCount)
+		if err != nil {
+			return err
+		}
+		for _, password := range append(previous, usr.Password) {
+			if password == encodedPassword {
+				return &user.PasswordPolicyError{FailedRules: []user.PasswordRule{user.PasswordRuleHistory}}
+			}
+		}
+	}
+
+	return s.audited(ctx, cmd.UserID, user.AuditActionChangePassword, func(ctx context.Context) error {
+		return s.store.ChangePassword(ctx, &user.ChangeUserPasswordCommand{
+			UserID:      cmd.UserID,
+			NewPassword: encodedPassword,
+		})
+	}, func() []user.AuditChange {
+		return []user.AuditChange{{Field: "password"}}
+	})
 }
 
+// UpdateLastSeenAt updates the last seen time of a user, in the next batch when batching is enabled. The client of
+// logins is written right away
 func (s *Service) UpdateLastSeenAt(ctx context.Context, cmd *user.UpdateUserLastSeenAtCommand) error {
-	return s.store.UpdateLastSeenAt(ctx, cmd)
+	if cmd.Login != nil {
+		if err := s.updateLastLogin(ctx, cmd.UserID, cmd.Login); err != nil {
+			return err
+		}
+	}
+
+	if s.IsDisabled() {
+		return s.store.UpdateLastSeenAt(ctx, cmd)
+	}
+	s.lastSeen.record(cmd.UserID, time.Now(), s.cfg.UserLastSeenUpdateInterval)
+	return nil
 }
 
 func (s *Service) SetUsingOrg(ctx context.Context, cmd *user.SetUsingOrgCommand) error {
@@ -193,19 +376,16 @@ func (s *Service) SetUsingOrg(ctx context.Context, cmd *user.SetUsingOrgCommand)
 	if !valid {
 		return fmt.Errorf("user does not belong to org")
 	}
-	return s.store.UpdateUser(ctx, &user.User{
-		ID:    cmd.UserID,
-		OrgID: cmd.OrgID,
-	})
+	if err := s.store.UpdateUser(ctx, &user.User{ID: cmd.UserID, OrgID: cmd.OrgID}); err != nil {
+		return err
+	}
+	s.signedInUsers.invalidate(ctx, cmd.UserID)
+	return nil
 }
 
 func (s *Service) GetSignedInUserWithCacheCtx(ctx context.Context, query *user.GetSignedInUserQuery) (*user.SignedInUser, error) {
-	var signedInUser *user.SignedInUser
-	cacheKey := newSignedInUserCacheKey(query.OrgID, query.UserID)
-	if cached, found := s.cacheService.Get(cacheKey); found {
-		cachedUser := cached.(user.SignedInUser)
-		signedInUser = &cachedUser
-		return signedInUser, nil
+	if cached, found := s.signedInUsers.get(ctx, query.OrgID, query.UserID); found {
+		return cached, nil
 	}
 
 	result, err := s.GetSignedInUser(ctx, query)
@@ -213,15 +393,10 @@ func (s *Service) GetSignedInUserWithCacheCtx(ctx context.Context, query *user.G
 		return nil, err
 	}
 
-	cacheKey = newSignedInUserCacheKey(result.OrgID, query.UserID)
-	s.cacheService.Set(cacheKey, *result, time.Second*5)
+	s.signedInUsers.set(ctx, result.OrgID, result)
 	return result, nil
 }
 
-func newSignedInUserCacheKey(orgID, userID int64) string {
-	return fmt.Sprintf("signed-in-user-%d-%d", userID, orgID)
-}
-
 func (s *Service) GetSignedInUser(ctx context.Context, query *user.GetSignedInUserQuery) (*user.SignedInUser, error) {
 	signedInUser, err := s.store.GetSignedInUser(ctx, query)
 	if err != nil {
@@ -258,23 +433,354 @@ func (s *Service) Search(ctx context.Context, query *user.SearchUsersQuery) (*us
 	return s.store.Search(ctx, query)
 }
 
+// Count returns the number of users matching a search, in total and by status, for usage stats and overviews which
+// don't need the users themselves
+func (s *Service) Count(ctx context.Context, query *user.SearchUsersQuery) (*user.CountUsersResult, error) {
+	return s.store.Count(ctx, query)
+}
+
+// Disable disables or enables a user and records it in the audit log
 func (s *Service) Disable(ctx context.Context, cmd *user.DisableUserCommand) error {
-	return s.store.Disable(ctx, cmd)
+	var usr *user.User
+	var err error
+	if cmd.UserID == 0 {
+		usr, err = s.store.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: cmd.LoginOrEmail})
+	} else {
+		usr, err = s.store.GetByID(ctx, cmd.UserID)
+	}
+	if err != nil {
+		return err
+	}
+	cmd.UserID = usr.ID
+
+	action := user.AuditActionDisable
+	if !cmd.IsDisabled {
+		action = user.AuditActionEnable
+	}
+	err = s.audited(ctx, cmd.UserID, action, func(ctx context.Context) error {
+		return s.store.Disable(ctx, cmd)
+	}, func() []user.AuditChange {
+		return auditBoolChange("isDisabled", usr.IsDisabled, cmd.IsDisabled)
+	})
+	if err != nil {
+		return err
+	}
+	s.signedInUsers.invalidate(ctx, cmd.UserID)
+	return nil
 }
 
 func (s *Service) BatchDisableUsers(ctx context.Context, cmd *user.BatchDisableUsersCommand) error {
 	return s.store.BatchDisableUsers(ctx, cmd)
 }
 
+func (s *Service) BatchDeleteUsers(ctx context.Context, userIDs []int64) error {
+	return s.store.BatchDeleteUsers(ctx, userIDs)
+}
+
+func (s *Service) Restore(ctx context.Context, cmd *user.RestoreUserCommand) error {
+	return s.store.Restore(ctx, cmd.UserID)
+}
+
+// AnonymizeUser scrubs the personal data of a user to honor erasure requests, while keeping its ID so the resources
+// referencing it stay consistent. The anonymization is logged with the user requesting it as an audit record
+func (s *Service) AnonymizeUser(ctx context.Context, userID int64) error {
+	if err := s.store.Anonymize(ctx, userID); err != nil {
+		return err
+	}
+
+	logger := s.log.FromContext(ctx)
+	if actor, err := appcontext.User(ctx); err == nil {
+		logger.Info("User anonymized", "userId", userID, "actorId", actor.UserID, "actorLogin", actor.Login)
+	} else {
+		logger.Info("User anonymized", "userId", userID)
+	}
+	return nil
+}
+
+// MergeUsers merges a duplicate user into the user to keep, moving its org memberships, dashboard permissions,
+// preferences, team memberships and auth identities before deleting it. The merge is logged with the rows moved and
+// the user requesting it as an audit record
+func (s *Service) MergeUsers(ctx context.Context, targetID, sourceID int64) error {
+	if targetID == sourceID {
+		return user.ErrMergeSameUser
+	}
+
+	source, err := s.store.GetByID(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+	merged, err := s.store.MergeUsers(ctx, targetID, sourceID)
+	if err != nil {
+		return err
+	}
+
+	logCtx := []interface{}{"targetUserId", targetID, "sourceUserId", sourceID, "sourceLogin", source.Login,
+		"sourceEmail", source.Email, "orgMemberships", merged.OrgMemberships, "dashboardPermissions",
+		merged.DashboardPermissions, "preferences", merged.Preferences, "teamMemberships", merged.TeamMemberships,
+		"authIdentities", merged.AuthIdentities, "stars", merged.Stars}
+	if actor, err := appcontext.User(ctx); err == nil {
+		logCtx = append(logCtx, "actorId", actor.UserID, "actorLogin", actor.Login)
+	}
+	s.log.FromContext(ctx).Info("Users merged", logCtx...)
+	return nil
+}
+
+func (s *Service) GetConflicts(ctx context.Context) ([]*user.UserConflict, error) {
+	return s.store.GetConflicts(ctx)
+}
+
+// ResolveConflict resolves the conflict between two users whose logins or emails only differ by their case, by
+// merging the conflicting user into the canonical one or by disabling it
+func (s *Service) ResolveConflict(ctx context.Context, cmd *user.ResolveUserConflictCommand) error {
+	if cmd.Resolution != user.ConflictResolutionMerge && cmd.Resolution != user.ConflictResolutionDisable {
+		return user.ErrInvalidResolution
+	}
+
+	canonical, err := s.store.GetNotServiceAccount(ctx, cmd.CanonicalUserID)
+	if err != nil {
+		return err
+	}
+	conflicting, err := s.store.GetNotServiceAccount(ctx, cmd.ConflictingUserID)
+	if err != nil {
+		return err
+	}
+	if canonical.ID == conflicting.ID ||
+		(!strings.EqualFold(canonical.Login, conflicting.Login) && !strings.EqualFold(canonical.Email, conflicting.Email)) {
+		return user.ErrNoUserConflict
+	}
+
+	if cmd.Resolution == user.ConflictResolutionMerge {
+		return s.MergeUsers(ctx, canonical.ID, conflicting.ID)
+	}
+
+	if err := s.store.Disable(ctx, &user.DisableUserCommand{UserID: conflicting.ID, IsDisabled: true}); err != nil {
+		return err
+	}
+	logCtx := []interface{}{"canonicalUserId", canonical.ID, "conflictingUserId", conflicting.ID, "conflictingLogin",
+		conflicting.Login, "conflictingEmail", conflicting.Email}
+	if actor, err := appcontext.User(ctx); err == nil {
+		logCtx = append(logCtx, "actorId", actor.UserID, "actorLogin", actor.Login)
+	}
+	s.log.FromContext(ctx).Info("User conflict resolved by disabling the conflicting user", logCtx...)
+	return nil
+}
+
+func (s *Service) SetAttribute(ctx context.Context, cmd *user.SetUserAttributeCommand) error {
+	if cmd.Key == "" || utf8.RuneCountInString(cmd.Key) > 190 || utf8.RuneCountInString(cmd.Value) > 255 {
+		return user.ErrInvalidAttribute
+	}
+	return s.store.SetAttribute(ctx, cmd)
+}
+
+func (s *Service) GetAttributes(ctx context.Context, query *user.GetUserAttributesQuery) (map[string]string, error) {
+	return s.store.GetAttributes(ctx, query)
+}
+
+// SetLabels replaces the labels of a user. Labels are trimmed, and set only once however many times they are given
+func (s *Service) SetLabels(ctx context.Context, cmd *user.SetUserLabelsCommand) error {
+	labels := make([]string, 0, len(cmd.Labels))
+	seen := make(map[string]bool, len(cmd.Labels))
+	for _, label := range cmd.Labels {
+		label = strings.TrimSpace(label)
+		if label == "" || utf8.RuneCountInString(label) > 190 {
+			return user.ErrInvalidLabel
+		}
+		if !seen[label] {
+			seen[label] = true
+			labels = append(labels, label)
+		}
+	}
+	return s.store.SetLabels(ctx, &user.SetUserLabelsCommand{UserID: cmd.UserID, Labels: labels})
+}
+
+func (s *Service) GetLabels(ctx context.Context, query *user.GetUserLabelsQuery) ([]string, error) {
+	return s.store.GetLabels(ctx, query)
+}
+
+// GetEffectiveAccess returns the access of a user to organizations, teams, dashboards and folders, for access reviews
+func (s *Service) GetEffectiveAccess(ctx context.Context, query *user.GetEffectiveAccessQuery) (*user.EffectiveAccessReport, error) {
+	return s.store.GetEffectiveAccess(ctx, query.UserID)
+}
+
+func (s *Service) PurgeDeletedUsers(ctx context.Context, cmd *user.PurgeDeletedUsersCommand) error {
+	purged, err := s.store.PurgeDeleted(ctx, cmd.OlderThan)
+	if err != nil {
+		return err
+	}
+	cmd.PurgedUsers = purged
+	return nil
+}
+
+// DeactivateInactiveUsers disables the users inactive since cmd.InactiveSince, except the excluded ones. The users
+// are only logged in dry run mode
+func (s *Service) DeactivateInactiveUsers(ctx context.Context, cmd *user.DeactivateInactiveUsersCommand) error {
+	inactive, err := s.store.GetInactiveUsers(ctx, cmd.InactiveSince)
+	if err != nil {
+		return err
+	}
+
+	userIDs := make([]int64, 0, len(inactive))
+	logins := make([]string, 0, len(inactive))
+	for _, usr := range inactive {
+		if isExcluded(usr, cmd.Exclusions) {
+			continue
+		}
+		userIDs = append(userIDs, usr.ID)
+		logins = append(logins, usr.Login)
+	}
+	cmd.DeactivatedUsers = userIDs
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	logger := s.log.FromContext(ctx)
+	if cmd.DryRun {
+		logger.Info("Inactive users would be disabled", "inactiveSince", cmd.InactiveSince, "users", len(userIDs), "logins", logins)
+		return nil
+	}
+
+	disableCmd := user.BatchDisableUsersCommand{UserIDs: userIDs, IsDisabled: true}
+	if err := s.BatchDisableUsers(ctx, &disableCmd); err != nil {
+		cmd.DeactivatedUsers = nil
+		return err
+	}
+	// users disabled or deleted meanwhile are not counted
+	cmd.DeactivatedUsers = disableCmd.UpdatedUserIDs
+	metrics.MInactiveUsersDeactivated.Add(float64(len(disableCmd.UpdatedUserIDs)))
+	logger.Info("Disabled inactive users", "inactiveSince", cmd.InactiveSince, "users", len(disableCmd.UpdatedUserIDs), "logins", logins)
+	return nil
+}
+
+func isExcluded(usr *user.User, exclusions []string) bool {
+	for _, excluded := range exclusions {
+		if strings.EqualFold(usr.Login, excluded) || strings.EqualFold(usr.Email, excluded) {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordFailedLogin counts a failed login of a user, locking it once it reaches the configured maximum of consecutive
+// failed logins. It does nothing when the account lockout is disabled
+func (s *Service) RecordFailedLogin(ctx context.Context, userID int64) error {
+	if s.cfg.UserLockoutMaxAttempts <= 0 {
+		return nil
+	}
+	return s.store.RecordFailedLogin(ctx, userID, s.cfg.UserLockoutMaxAttempts, s.cfg.UserLockoutDuration)
+}
+
+func (s *Service) IsLocked(ctx context.Context, userID int64) (bool, error) {
+	if s.cfg.UserLockoutMaxAttempts <= 0 {
+		return false, nil
+	}
+	return s.store.IsLocked(ctx, userID)
+}
+
+func (s *Service) ResetLockout(ctx context.Context, userID int64) error {
+	return s.store.ResetLockout(ctx, userID)
+}
+
+// UpdatePermissions grants or revokes the Grafana server admin permission of a user and records it in the audit log
 func (s *Service) UpdatePermissions(ctx context.Context, userID int64, isAdmin bool) error {
-	return s.store.UpdatePermissions(ctx, userID, isAdmin)
+	usr, err := s.store.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.audited(ctx, userID, user.AuditActionUpdatePermissions, func(ctx context.Context) error {
+		return s.store.UpdatePermissions(ctx, userID, isAdmin)
+	}, func() []user.AuditChange {
+		return auditBoolChange("isGrafanaAdmin", usr.IsAdmin, isAdmin)
+	})
+	if err != nil {
+		return err
+	}
+	s.signedInUsers.invalidate(ctx, userID)
+	return nil
+}
+
+// TransferGrafanaAdmin grants the Grafana server admin permission of a user to another user and revokes it from the
+// user in one transaction, so the last Grafana server admin can hand over the permission
+func (s *Service) TransferGrafanaAdmin(ctx context.Context, fromUserID, toUserID int64) error {
+	if fromUserID == toUserID {
+		return user.ErrInvalidGrafanaAdminTransfer
+	}
+
+	from, err := s.store.GetByID(ctx, fromUserID)
+	if err != nil {
+		return err
+	}
+	if !from.IsAdmin {
+		return user.ErrInvalidGrafanaAdminTransfer
+	}
+
+	to, err := s.store.GetNotServiceAccount(ctx, toUserID)
+	if err != nil {
+		return err
+	}
+	if to.IsDisabled {
+		return user.ErrInvalidGrafanaAdminTransfer
+	}
+
+	// the target is promoted first, the source is never the last Grafana server admin when it is demoted
+	return s.store.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.UpdatePermissions(ctx, to.ID, true); err != nil {
+			return err
+		}
+		return s.UpdatePermissions(ctx, from.ID, false)
+	})
 }
 
 func (s *Service) SetUserHelpFlag(ctx context.Context, cmd *user.SetUserHelpFlagCommand) error {
 	return s.store.SetHelpFlag(ctx, cmd)
 }
 
+// GetProfile returns the profile of a user, with the usage of its quotas when quotas are enabled
 func (s *Service) GetProfile(ctx context.Context, query *user.GetUserProfileQuery) (*user.UserProfileDTO, error) {
 	result, err := s.store.GetProfile(ctx, query)
-	return result, err
+	if err != nil || !s.cfg.Quota.Enabled {
+		return result, err
+	}
+
+	result.Quotas, err = s.GetQuotas(ctx, &user.GetQuotasQuery{UserID: query.UserID, UnifiedAlertingEnabled: s.cfg.UnifiedAlerting.IsEnabled()})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetQuotas returns the quotas of a user and its usage of them. Users are limited by the default limits of the quota
+// settings unless they have limits of their own
+func (s *Service) GetQuotas(ctx context.Context, query *user.GetQuotasQuery) ([]*user.Quota, error) {
+	limits := s.defaultQuotaLimits()
+	if len(query.Targets) > 0 {
+		targetLimits := make(map[string]int64, len(query.Targets))
+		for _, target := range query.Targets {
+			limit, ok := limits[target]
+			if !ok {
+				return nil, user.ErrInvalidQuotaTarget
+			}
+			targetLimits[target] = limit
+		}
+		limits = targetLimits
+	}
+
+	return s.store.GetQuotas(ctx, query.UserID, limits, query.UnifiedAlertingEnabled)
+}
+
+// SetQuota sets the limit of a user for a quota target, overriding the default limit
+func (s *Service) SetQuota(ctx context.Context, cmd *user.SetQuotaCommand) error {
+	if _, ok := s.defaultQuotaLimits()[cmd.Target]; !ok {
+		return user.ErrInvalidQuotaTarget
+	}
+	return s.store.SetQuota(ctx, cmd)
+}
+
+// defaultQuotaLimits returns the default limits of users by quota target. Users are not limited when no per user
+// limits are set, but can still be given limits of their own
+func (s *Service) defaultQuotaLimits() map[string]int64 {
+	if s.cfg.Quota.User == nil {
+		return setting.UnlimitedUserQuota().ToMap()
+	}
+	return s.cfg.Quota.User.ToMap()
 }
@@ -3,14 +3,20 @@ package userimpl
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/appcontext"
 	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/org/orgtest"
 	"github.com/grafana/grafana/pkg/services/team/teamtest"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -20,9 +26,11 @@ func TestUserService(t *testing.T) {
 	userStore := newUserStoreFake()
 	orgService := orgtest.NewOrgServiceFake()
 	userService := Service{
-		store:        userStore,
-		orgService:   orgService,
-		cacheService: localcache.ProvideService(),
+		log:           log.New("test.logger"),
+		store:         userStore,
+		orgService:    orgService,
+		cfg:           setting.NewCfg(),
+		signedInUsers: newSignedInUserCache(setting.NewCfg(), localcache.ProvideService(), nil),
 	}
 
 	t.Run("create user", func(t *testing.T) {
@@ -34,6 +42,37 @@ func TestUserService(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("create user assigns organizations by rules", func(t *testing.T) {
+		userService.cfg = setting.NewCfg()
+		userService.cfg.AutoAssignOrgRules = []setting.AutoAssignOrgRule{
+			{EmailDomain: "example.com", OrgID: 2, Role: "Editor"},
+			{Company: "acme", OrgID: 3, Role: "Viewer"},
+			// only the first rule matching the user decides its role in an organization
+			{Company: "Acme", OrgID: 2, Role: "Admin"},
+			{EmailDomain: "other.com", OrgID: 4, Role: "Admin"},
+		}
+		orgService.InsertedOrgUsers = nil
+		t.Cleanup(func() {
+			userService.cfg = setting.NewCfg()
+			orgService.InsertedOrgUsers = nil
+		})
+
+		_, err := userService.Create(context.Background(), &user.CreateUserCommand{
+			Email:   "user@Example.com",
+			Login:   "user",
+			Company: "Acme",
+		})
+		require.NoError(t, err)
+
+		roles := map[int64]org.RoleType{}
+		for _, orgUser := range orgService.InsertedOrgUsers {
+			roles[orgUser.OrgID] = orgUser.Role
+		}
+		require.Len(t, orgService.InsertedOrgUsers, 3)
+		assert.Equal(t, org.RoleEditor, roles[2])
+		assert.Equal(t, org.RoleViewer, roles[3])
+	})
+
 	t.Run("get user by ID", func(t *testing.T) {
 		userService.cfg = setting.NewCfg()
 		userService.cfg.CaseInsensitiveLogin = false
@@ -85,6 +124,539 @@ func TestUserService(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("delete user with a successor", func(t *testing.T) {
+		userStore.OwnershipTransfers = nil
+		t.Cleanup(func() {
+			userStore.OwnershipTransfers = nil
+		})
+
+		err := userService.Delete(context.Background(), &user.DeleteUserCommand{UserID: 1, SuccessorID: 1})
+		require.ErrorIs(t, err, user.ErrInvalidSuccessor)
+		require.Empty(t, userStore.OwnershipTransfers)
+
+		err = userService.Delete(context.Background(), &user.DeleteUserCommand{UserID: 1, SuccessorID: 2})
+		require.NoError(t, err)
+		require.Equal(t, map[int64]int64{1: 2}, userStore.OwnershipTransfers)
+	})
+
+	t.Run("record logins in the login history", func(t *testing.T) {
+		userService.cfg = setting.NewCfg()
+		userService.cfg.LoginHistoryRetention = 90 * 24 * time.Hour
+		userStore.LoginHistory = nil
+		expectedUser := userStore.ExpectedUser
+		t.Cleanup(func() {
+			userStore.ExpectedUser = expectedUser
+			userStore.ExpectedError = nil
+			userStore.LoginHistory = nil
+		})
+
+		// failed logins are recorded with the user they were attempted with
+		userStore.ExpectedUser = &user.User{ID: 3, Login: "user"}
+		require.NoError(t, userService.RecordLogin(context.Background(), &user.RecordLoginCommand{Login: "user", AuthModule: "grafana", Result: user.LoginResultFailure}))
+		userStore.ExpectedUser, userStore.ExpectedError = nil, user.ErrUserNotFound
+		require.NoError(t, userService.RecordLogin(context.Background(), &user.RecordLoginCommand{Login: strings.Repeat("x", 300), Result: user.LoginResultFailure}))
+		userStore.ExpectedError = nil
+		require.NoError(t, userService.RecordLogin(context.Background(), &user.RecordLoginCommand{UserID: 3, Login: "user", AuthModule: "oauth_github", Result: user.LoginResultSuccess}))
+
+		require.Len(t, userStore.LoginHistory, 3)
+		assert.Equal(t, int64(3), userStore.LoginHistory[0].UserID)
+		assert.Equal(t, user.LoginResultFailure, userStore.LoginHistory[0].Result)
+		assert.Zero(t, userStore.LoginHistory[1].UserID)
+		assert.Len(t, userStore.LoginHistory[1].Login, 190)
+		assert.Equal(t, "oauth_github", userStore.LoginHistory[2].AuthModule)
+
+		// nothing is recorded when the login history is disabled
+		userService.cfg.LoginHistoryRetention = 0
+		require.NoError(t, userService.RecordLogin(context.Background(), &user.RecordLoginCommand{UserID: 3, Result: user.LoginResultSuccess}))
+		require.Len(t, userStore.LoginHistory, 3)
+	})
+
+	t.Run("anonymize user", func(t *testing.T) {
+		ctx := appcontext.WithUser(context.Background(), &user.SignedInUser{UserID: 2, Login: "admin"})
+		require.NoError(t, userService.AnonymizeUser(ctx, 1))
+
+		userStore.ExpectedError = user.ErrUserNotFound
+		t.Cleanup(func() {
+			userStore.ExpectedError = nil
+		})
+		require.ErrorIs(t, userService.AnonymizeUser(context.Background(), 1), user.ErrUserNotFound)
+	})
+
+	t.Run("merge users", func(t *testing.T) {
+		ctx := appcontext.WithUser(context.Background(), &user.SignedInUser{UserID: 2, Login: "admin"})
+		require.NoError(t, userService.MergeUsers(ctx, 1, 3))
+		require.ErrorIs(t, userService.MergeUsers(ctx, 1, 1), user.ErrMergeSameUser)
+
+		userStore.ExpectedError = user.ErrUserNotFound
+		t.Cleanup(func() {
+			userStore.ExpectedError = nil
+		})
+		require.ErrorIs(t, userService.MergeUsers(ctx, 1, 3), user.ErrUserNotFound)
+	})
+
+	t.Run("resolve user conflict", func(t *testing.T) {
+		userStore.ExpectedUsersByID = map[int64]*user.User{
+			1: {ID: 1, Login: "User", Email: "user@test.com"},
+			2: {ID: 2, Login: "user", Email: "other@test.com"},
+			3: {ID: 3, Login: "other", Email: "another@test.com"},
+		}
+		t.Cleanup(func() {
+			userStore.ExpectedUsersByID = nil
+		})
+
+		for _, resolution := range []user.ConflictResolution{user.ConflictResolutionMerge, user.ConflictResolutionDisable} {
+			err := userService.ResolveConflict(context.Background(), &user.ResolveUserConflictCommand{CanonicalUserID: 1, ConflictingUserID: 2, Resolution: resolution})
+			require.NoError(t, err)
+		}
+
+		err := userService.ResolveConflict(context.Background(), &user.ResolveUserConflictCommand{CanonicalUserID: 1, ConflictingUserID: 2, Resolution: "delete"})
+		require.ErrorIs(t, err, user.ErrInvalidResolution)
+		err = userService.ResolveConflict(context.Background(), &user.ResolveUserConflictCommand{CanonicalUserID: 1, ConflictingUserID: 3, Resolution: user.ConflictResolutionMerge})
+		require.ErrorIs(t, err, user.ErrNoUserConflict)
+		err = userService.ResolveConflict(context.Background(), &user.ResolveUserConflictCommand{CanonicalUserID: 1, ConflictingUserID: 1, Resolution: user.ConflictResolutionMerge})
+		require.ErrorIs(t, err, user.ErrNoUserConflict)
+	})
+
+	t.Run("deactivate inactive users skips the excluded users", func(t *testing.T) {
+		userStore.ExpectedInactiveUsers = []*user.User{
+			{ID: 1, Login: "alice", Email: "alice@test.com"},
+			{ID: 2, Login: "emergency", Email: "emergency@test.com"},
+			{ID: 3, Login: "shared", Email: "Shared@test.com"},
+		}
+		t.Cleanup(func() {
+			userStore.ExpectedInactiveUsers = nil
+		})
+
+		for _, dryRun := range []bool{false, true} {
+			cmd := user.DeactivateInactiveUsersCommand{InactiveSince: time.Now(), Exclusions: []string{"emergency", "shared@test.com"}, DryRun: dryRun}
+			require.NoError(t, userService.DeactivateInactiveUsers(context.Background(), &cmd))
+			require.Equal(t, []int64{1}, cmd.DeactivatedUsers)
+		}
+
+		userStore.ExpectedError = errors.New("disable failed")
+		t.Cleanup(func() {
+			userStore.ExpectedError = nil
+		})
+		require.Error(t, userService.DeactivateInactiveUsers(context.Background(), &user.DeactivateInactiveUsersCommand{InactiveSince: time.Now()}))
+	})
+
+	t.Run("set user attribute validates the attribute", func(t *testing.T) {
+		require.NoError(t, userService.SetAttribute(context.Background(), &user.SetUserAttributeCommand{UserID: 1, Key: "team", Value: "platform"}))
+
+		for _, cmd := range []*user.SetUserAttributeCommand{
+			{UserID: 1, Value: "platform"},
+			{UserID: 1, Key: strings.Repeat("k", 191), Value: "platform"},
+			{UserID: 1, Key: "team", Value: strings.Repeat("v", 256)},
+		} {
+			require.ErrorIs(t, userService.SetAttribute(context.Background(), cmd), user.ErrInvalidAttribute)
+		}
+	})
+
+	t.Run("set user labels validates and deduplicates the labels", func(t *testing.T) {
+		require.NoError(t, userService.SetLabels(context.Background(), &user.SetUserLabelsCommand{UserID: 1, Labels: []string{"contractor", " break-glass ", "contractor"}}))
+		assert.Equal(t, []string{"contractor", "break-glass"}, userStore.Labels)
+
+		for _, labels := range [][]string{{"contractor", " "}, {strings.Repeat("l", 191)}} {
+			require.ErrorIs(t, userService.SetLabels(context.Background(), &user.SetUserLabelsCommand{UserID: 1, Labels: labels}), user.ErrInvalidLabel)
+		}
+	})
+
+	t.Run("change password enforces the password policy", func(t *testing.T) {
+		userService.cfg = setting.NewCfg()
+		userService.cfg.PasswordMinLength = 12
+		userService.cfg.PasswordRequireUppercase = true
+		userService.cfg.PasswordRequireDigit = true
+		userService.cfg.PasswordRequireSymbol = true
+		userStore.ExpectedUser = &user.User{ID: 1, Salt: "salt"}
+		userStore.ExpectedError = nil
+
+		err := userService.ChangePassword(context.Background(), &user.ChangeUserPasswordCommand{UserID: 1, NewPassword: "password"})
+		require.ErrorIs(t, err, user.ErrPasswordPolicy)
+		var policyErr *user.PasswordPolicyError
+		require.ErrorAs(t, err, &policyErr)
+		assert.Equal(t, []user.PasswordRule{user.PasswordRuleMinLength, user.PasswordRuleUppercase, user.PasswordRuleDigit, user.PasswordRuleSymbol}, policyErr.FailedRules)
+
+		require.NoError(t, userService.ChangePassword(context.Background(), &user.ChangeUserPasswordCommand{UserID: 1, NewPassword: "Correct-horse-1"}))
+	})
+
+	t.Run("update last seen at batches the updates", func(t *testing.T) {
+		userService.cfg = setting.NewCfg()
+		userService.cfg.UserLastSeenFlushInterval = time.Minute
+		userService.cfg.UserLastSeenUpdateInterval = time.Hour
+		userService.lastSeen = newLastSeenBatcher()
+		userStore.ExpectedError = nil
+		userStore.BatchUpdatedLastSeenAt = nil
+
+		for _, userID := range []int64{1, 2, 1} {
+			require.NoError(t, userService.UpdateLastSeenAt(context.Background(), &user.UpdateUserLastSeenAtCommand{UserID: userID}))
+		}
+		require.Empty(t, userStore.BatchUpdatedLastSeenAt)
+
+		require.NoError(t, userService.flushLastSeen(context.Background()))
+		assert.ElementsMatch(t, []int64{1, 2}, userStore.BatchUpdatedLastSeenAt)
+
+		// users written within the update interval are not written again
+		userStore.BatchUpdatedLastSeenAt = nil
+		require.NoError(t, userService.UpdateLastSeenAt(context.Background(), &user.UpdateUserLastSeenAtCommand{UserID: 1}))
+		require.NoError(t, userService.flushLastSeen(context.Background()))
+		require.Empty(t, userStore.BatchUpdatedLastSeenAt)
+	})
+
+	t.Run("update last seen at keeps the users failing to be written", func(t *testing.T) {
+		userService.cfg = setting.NewCfg()
+		userService.cfg.UserLastSeenFlushInterval = time.Minute
+		userService.cfg.UserLastSeenUpdateInterval = time.Hour
+		userService.lastSeen = newLastSeenBatcher()
+		userStore.BatchUpdatedLastSeenAt = nil
+
+		require.NoError(t, userService.UpdateLastSeenAt(context.Background(), &user.UpdateUserLastSeenAtCommand{UserID: 1}))
+		userStore.ExpectedError = errors.New("db error")
+		require.Error(t, userService.flushLastSeen(context.Background()))
+
+		userStore.ExpectedError = nil
+		userStore.BatchUpdatedLastSeenAt = nil
+		require.NoError(t, userService.flushLastSeen(context.Background()))
+		assert.Equal(t, []int64{1}, userStore.BatchUpdatedLastSeenAt)
+	})
+
+	t.Run("update last seen at records the client of logins as configured", func(t *testing.T) {
+		userService.cfg = setting.NewCfg()
+		userService.cfg.SecretKey = "secret"
+		userService.lastSeen = newLastSeenBatcher()
+		userStore.ExpectedError = nil
+		userStore.LastLogins = nil
+		login := &user.LoginClient{IP: "192.168.0.1", UserAgent: strings.Repeat("a", 300)}
+
+		for _, tracking := range []string{"off", "raw", "hashed"} {
+			userService.cfg.LastLoginClientTracking = tracking
+			require.NoError(t, userService.UpdateLastSeenAt(context.Background(), &user.UpdateUserLastSeenAtCommand{UserID: 1, Login: login}))
+		}
+		require.Len(t, userStore.LastLogins, 2)
+		assert.Equal(t, "192.168.0.1", userStore.LastLogins[0].IP)
+		assert.Len(t, userStore.LastLogins[0].UserAgent, 255)
+		assert.Len(t, userStore.LastLogins[1].IP, 64)
+		assert.NotContains(t, userStore.LastLogins[1].IP, "192.168")
+
+		// the same address is always hashed the same, logins from it can be recognized
+		require.NoError(t, userService.UpdateLastSeenAt(context.Background(), &user.UpdateUserLastSeenAtCommand{UserID: 1, Login: login}))
+		assert.Equal(t, userStore.LastLogins[1].IP, userStore.LastLogins[2].IP)
+	})
+
+	t.Run("change password rejects recent passwords", func(t *testing.T) {
+		userService.cfg = setting.NewCfg()
+		userService.cfg.PasswordHistoryCount = 3
+		current, err := util.EncodePassword("current", "salt")
+		require.NoError(t, err)
+		previous, err := util.EncodePassword("previous", "salt")
+		require.NoError(t, err)
+		userStore.ExpectedUser = &user.User{ID: 1, Salt: "salt", Password: current}
+		userStore.ExpectedPasswordHistory = []string{previous}
+		userStore.ExpectedError = nil
+
+		for _, password := range []string{"current", "previous"} {
+			err := userService.ChangePassword(context.Background(), &user.ChangeUserPasswordCommand{UserID: 1, OldPassword: "current", NewPassword: password})
+			var policyErr *user.PasswordPolicyError
+			require.ErrorAs(t, err, &policyErr)
+			assert.Equal(t, []user.PasswordRule{user.PasswordRuleHistory}, policyErr.FailedRules)
+		}
+		require.NoError(t, userService.ChangePassword(context.Background(), &user.ChangeUserPasswordCommand{UserID: 1, OldPassword: "current", NewPassword: "new password"}))
+	})
+
+	t.Run("change password verifies the old password unless it is reset", func(t *testing.T) {
+		userService.cfg = setting.NewCfg()
+		current, err := util.EncodePassword("current", "salt")
+		require.NoError(t, err)
+		userStore.ExpectedUser = &user.User{ID: 1, Salt: "salt", Password: current}
+		userStore.ExpectedError = nil
+
+		for _, oldPassword := range []string{"", "wrong"} {
+			err := userService.ChangePassword(context.Background(), &user.ChangeUserPasswordCommand{UserID: 1, OldPassword: oldPassword, NewPassword: "new password"})
+			require.ErrorIs(t, err, user.ErrInvalidOldPassword)
+		}
+		require.NoError(t, userService.ChangePassword(context.Background(), &user.ChangeUserPasswordCommand{UserID: 1, OldPassword: "current", NewPassword: "new password"}))
+		require.NoError(t, userService.ChangePassword(context.Background(), &user.ChangeUserPasswordCommand{UserID: 1, NewPassword: "new password", IsReset: true}))
+
+		// users without a password, such as users signing in with OAuth, set their first password
+		userStore.ExpectedUser = &user.User{ID: 1, Salt: "salt"}
+		require.NoError(t, userService.ChangePassword(context.Background(), &user.ChangeUserPasswordCommand{UserID: 1, NewPassword: "new password"}))
+	})
+
+	t.Run("create user enforces the password policy", func(t *testing.T) {
+		userService.cfg = setting.NewCfg()
+		userService.cfg.PasswordMinLength = 8
+
+		_, err := userService.Create(context.Background(), &user.CreateUserCommand{Login: "login", Password: "short"})
+		var policyErr *user.PasswordPolicyError
+		require.ErrorAs(t, err, &policyErr)
+		assert.Equal(t, []user.PasswordRule{user.PasswordRuleMinLength}, policyErr.FailedRules)
+	})
+
+	t.Run("audits the changed fields of users", func(t *testing.T) {
+		userService.cfg = setting.NewCfg()
+		userStore.ExpectedError = nil
+		userStore.ExpectedUsersByID = nil
+		userStore.ExpectedUser = &user.User{ID: 1, Login: "login", Email: "email", Name: "name", Salt: "salt"}
+		userStore.AuditEntries = nil
+		ctx := appcontext.WithUser(context.Background(), &user.SignedInUser{UserID: 2, Login: "admin"})
+
+		require.NoError(t, userService.Update(ctx, &user.UpdateUserCommand{UserID: 1, Login: "login", Email: "new email", Name: "name"}))
+		require.NoError(t, userService.UpdatePermissions(ctx, 1, true))
+		require.NoError(t, userService.Disable(ctx, &user.DisableUserCommand{UserID: 1, IsDisabled: true}))
+		require.NoError(t, userService.ChangePassword(ctx, &user.ChangeUserPasswordCommand{UserID: 1, NewPassword: "new password"}))
+		// changes leaving the fields as they were are not recorded
+		require.NoError(t, userService.Update(ctx, &user.UpdateUserCommand{UserID: 1, Login: "login"}))
+		require.NoError(t, userService.Disable(ctx, &user.DisableUserCommand{UserID: 1, IsDisabled: false}))
+
+		require.Len(t, userStore.AuditEntries, 4)
+		assert.Equal(t, user.AuditActionUpdate, userStore.AuditEntries[0].Action)
+		assert.Equal(t, []user.AuditChange{{Field: "email", OldValue: "email", NewValue: "new email"}}, userStore.AuditEntries[0].Changes)
+		assert.Equal(t, []user.AuditChange{{Field: "isGrafanaAdmin", OldValue: "false", NewValue: "true"}}, userStore.AuditEntries[1].Changes)
+		assert.Equal(t, user.AuditActionDisable, userStore.AuditEntries[2].Action)
+		assert.Equal(t, []user.AuditChange{{Field: "password"}}, userStore.AuditEntries[3].Changes)
+		for _, entry := range userStore.AuditEntries {
+			assert.Equal(t, int64(1), entry.UserID)
+			assert.Equal(t, int64(2), entry.ActorID)
+			assert.Equal(t, "admin", entry.ActorLogin)
+		}
+	})
+
+	t.Run("disable users found by their login or email", func(t *testing.T) {
+		userService.cfg = setting.NewCfg()
+		userStore.ExpectedError = nil
+		userStore.ExpectedUsersByID = nil
+		userStore.ExpectedUser = &user.User{ID: 5, Login: "login", Email: "email"}
+		userStore.AuditEntries = nil
+
+		cmd := &user.DisableUserCommand{LoginOrEmail: "LOGIN", IsDisabled: true}
+		require.NoError(t, userService.Disable(context.Background(), cmd))
+		assert.Equal(t, int64(5), cmd.UserID)
+		require.Len(t, userStore.AuditEntries, 1)
+		assert.Equal(t, int64(5), userStore.AuditEntries[0].UserID)
+
+		userStore.ExpectedError = user.ErrUserNotFound
+		t.Cleanup(func() {
+			userStore.ExpectedError = nil
+		})
+		require.ErrorIs(t, userService.Disable(context.Background(), &user.DisableUserCommand{LoginOrEmail: "unknown", IsDisabled: true}), user.ErrUserNotFound)
+	})
+
+	t.Run("transfer the Grafana server admin permission", func(t *testing.T) {
+		userService.cfg = setting.NewCfg()
+		userStore.ExpectedError = nil
+		userStore.ExpectedUsersByID = map[int64]*user.User{
+			1: {ID: 1, Login: "admin", IsAdmin: true},
+			2: {ID: 2, Login: "user"},
+			3: {ID: 3, Login: "disabled", IsDisabled: true},
+		}
+		userStore.AuditEntries = nil
+		t.Cleanup(func() {
+			userStore.ExpectedUsersByID = nil
+		})
+
+		require.NoError(t, userService.TransferGrafanaAdmin(context.Background(), 1, 2))
+		require.Len(t, userStore.AuditEntries, 2)
+		assert.Equal(t, int64(2), userStore.AuditEntries[0].UserID)
+		assert.Equal(t, []user.AuditChange{{Field: "isGrafanaAdmin", OldValue: "false", NewValue: "true"}}, userStore.AuditEntries[0].Changes)
+		assert.Equal(t, int64(1), userStore.AuditEntries[1].UserID)
+		assert.Equal(t, []user.AuditChange{{Field: "isGrafanaAdmin", OldValue: "true", NewValue: "false"}}, userStore.AuditEntries[1].Changes)
+
+		for _, ids := range [][2]int64{{2, 1}, {1, 1}, {1, 3}} {
+			require.ErrorIs(t, userService.TransferGrafanaAdmin(context.Background(), ids[0], ids[1]), user.ErrInvalidGrafanaAdminTransfer)
+		}
+	})
+
+	t.Run("signed in users are dropped from the cache of all their organizations when they change", func(t *testing.T) {
+		userStore.ExpectedError = nil
+		userStore.ExpectedUsersByID = map[int64]*user.User{2: {ID: 2, Login: "user"}}
+		t.Cleanup(func() {
+			userStore.ExpectedUsersByID = nil
+		})
+
+		ctx := context.Background()
+		userService.signedInUsers.set(ctx, 1, &user.SignedInUser{UserID: 2, OrgID: 1})
+		userService.signedInUsers.set(ctx, 3, &user.SignedInUser{UserID: 2, OrgID: 3})
+		userService.signedInUsers.set(ctx, 1, &user.SignedInUser{UserID: 4, OrgID: 1})
+
+		cached, found := userService.signedInUsers.get(ctx, 3, 2)
+		require.True(t, found)
+		assert.Equal(t, int64(3), cached.OrgID)
+
+		require.NoError(t, userService.UpdatePermissions(ctx, 2, true))
+		_, found = userService.signedInUsers.get(ctx, 1, 2)
+		assert.False(t, found)
+		_, found = userService.signedInUsers.get(ctx, 3, 2)
+		assert.False(t, found)
+		_, found = userService.signedInUsers.get(ctx, 1, 4)
+		assert.True(t, found)
+
+		userService.signedInUsers.set(ctx, 1, &user.SignedInUser{UserID: 2, OrgID: 1})
+		require.NoError(t, userService.handleOrgUserUpdated(ctx, &events.OrgUserUpdated{OrgId: 1, UserId: 2}))
+		_, found = userService.signedInUsers.get(ctx, 1, 2)
+		assert.False(t, found)
+	})
+
+	t.Run("Grafana server admins can impersonate users for a limited time", func(t *testing.T) {
+		userService.cfg = setting.NewCfg()
+		userService.cfg.ImpersonationMaxDuration = time.Hour
+		userService.teamService = teamtest.NewFakeService()
+		userStore.ExpectedError = nil
+		userStore.ExpectedUser = &user.User{ID: 2, Login: "admin", IsAdmin: true}
+		userStore.ExpectedSignedInUser = &user.SignedInUser{UserID: 1, OrgID: 1, Login: "login"}
+		userStore.AuditEntries = nil
+
+		impersonated, err := userService.Impersonate(context.Background(), &user.ImpersonateUserCommand{ImpersonatorID: 2, UserID: 1, OrgID: 1, Duration: 2 * time.Hour})
+		require.NoError(t, err)
+		require.NotNil(t, impersonated.Impersonator)
+		assert.Equal(t, int64(2), impersonated.Impersonator.UserID)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), impersonated.Impersonator.ExpiresAt, time.Minute)
+		require.Len(t, userStore.AuditEntries, 1)
+		assert.Equal(t, user.AuditActionImpersonate, userStore.AuditEntries[0].Action)
+		assert.Equal(t, int64(1), userStore.AuditEntries[0].UserID)
+		assert.Equal(t, int64(2), userStore.AuditEntries[0].ActorID)
+
+		// changes made while impersonating are recorded with the impersonator
+		userStore.ExpectedUser = &user.User{ID: 1, Login: "login", Name: "name"}
+		ctx := appcontext.WithUser(context.Background(), impersonated)
+		require.NoError(t, userService.Update(ctx, &user.UpdateUserCommand{UserID: 1, Name: "new name"}))
+		require.Len(t, userStore.AuditEntries, 2)
+		assert.Equal(t, int64(1), userStore.AuditEntries[1].ActorID)
+		assert.Equal(t, int64(2), userStore.AuditEntries[1].ImpersonatorID)
+		assert.Equal(t, "admin", userStore.AuditEntries[1].ImpersonatorLogin)
+
+		impersonated.Impersonator.ExpiresAt = time.Now().Add(-time.Minute)
+		require.ErrorIs(t, userService.Update(ctx, &user.UpdateUserCommand{UserID: 1, Name: "other name"}), user.ErrImpersonationExpired)
+		require.Len(t, userStore.AuditEntries, 2)
+	})
+
+	t.Run("impersonation is refused to users who are not Grafana server admins", func(t *testing.T) {
+		userService.cfg = setting.NewCfg()
+		userService.cfg.ImpersonationMaxDuration = time.Hour
+		userService.teamService = teamtest.NewFakeService()
+		userStore.ExpectedError = nil
+		userStore.ExpectedUser = &user.User{ID: 2, Login: "editor"}
+		userStore.ExpectedSignedInUser = &user.SignedInUser{UserID: 1, OrgID: 1}
+
+		_, err := userService.Impersonate(context.Background(), &user.ImpersonateUserCommand{ImpersonatorID: 2, UserID: 1, OrgID: 1})
+		require.ErrorIs(t, err, user.ErrImpersonationForbidden)
+
+		userStore.ExpectedUser = &user.User{ID: 2, Login: "admin", IsAdmin: true}
+		userStore.ExpectedSignedInUser = &user.SignedInUser{UserID: 1, OrgID: 1, IsGrafanaAdmin: true}
+		_, err = userService.Impersonate(context.Background(), &user.ImpersonateUserCommand{ImpersonatorID: 2, UserID: 1, OrgID: 1})
+		require.ErrorIs(t, err, user.ErrImpersonationForbidden)
+
+		_, err = userService.Impersonate(context.Background(), &user.ImpersonateUserCommand{ImpersonatorID: 2, UserID: 2, OrgID: 1})
+		require.ErrorIs(t, err, user.ErrImpersonationForbidden)
+
+		userService.cfg.ImpersonationMaxDuration = 0
+		_, err = userService.Impersonate(context.Background(), &user.ImpersonateUserCommand{ImpersonatorID: 2, UserID: 1, OrgID: 1})
+		require.ErrorIs(t, err, user.ErrImpersonationDisabled)
+	})
+
+	t.Run("email addresses are verified with the tokens issued for them", func(t *testing.T) {
+		userService.cfg = setting.NewCfg()
+		userService.cfg.EmailVerificationTokenLifetime = time.Hour
+		userStore.ExpectedError = nil
+		userStore.ExpectedUser = &user.User{ID: 1, Login: "login", Email: "user@example.com"}
+		userStore.AuditEntries = nil
+		userStore.EmailVerifications = nil
+
+		token, err := userService.CreateEmailVerificationToken(context.Background(), &user.CreateEmailVerificationTokenCommand{UserID: 1})
+		require.NoError(t, err)
+		require.Len(t, userStore.EmailVerifications, 1)
+		assert.NotEqual(t, token, userStore.EmailVerifications[0].TokenHash)
+		assert.Equal(t, "user@example.com", userStore.EmailVerifications[0].Email)
+
+		require.ErrorIs(t, userService.VerifyEmail(context.Background(), &user.VerifyEmailCommand{Token: "unknown"}), user.ErrEmailVerificationTokenInvalid)
+		require.NoError(t, userService.VerifyEmail(context.Background(), &user.VerifyEmailCommand{Token: token}))
+		require.Len(t, userStore.AuditEntries, 1)
+		assert.Equal(t, user.AuditActionVerifyEmail, userStore.AuditEntries[0].Action)
+
+		// tokens of addresses the user no longer has are refused
+		userStore.ExpectedUser = &user.User{ID: 1, Login: "login", Email: "other@example.com"}
+		require.ErrorIs(t, userService.VerifyEmail(context.Background(), &user.VerifyEmailCommand{Token: token}), user.ErrEmailVerificationTokenInvalid)
+
+		userStore.EmailVerifications[0].Expires = time.Now().Add(-time.Minute)
+		userStore.EmailVerifications[0].Email = "other@example.com"
+		require.ErrorIs(t, userService.VerifyEmail(context.Background(), &user.VerifyEmailCommand{Token: token}), user.ErrEmailVerificationTokenInvalid)
+	})
+
+	t.Run("unverified email addresses are refused when verification is required", func(t *testing.T) {
+		userService.cfg = setting.NewCfg()
+		require.NoError(t, userService.CheckEmailVerified(context.Background(), &user.User{ID: 1}))
+
+		userService.cfg.EmailVerificationRequired = true
+		require.ErrorIs(t, userService.CheckEmailVerified(context.Background(), &user.User{ID: 1}), user.ErrEmailNotVerified)
+		require.NoError(t, userService.CheckEmailVerified(context.Background(), &user.User{ID: 1, EmailVerified: true}))
+		require.NoError(t, userService.CheckEmailVerified(context.Background(), &user.User{ID: 1, IsAdmin: true}))
+	})
+
+	t.Run("users can't change their locked profile fields", func(t *testing.T) {
+		expectedUser := userStore.ExpectedUser
+		t.Cleanup(func() { userStore.ExpectedUser = expectedUser })
+		userService.cfg = setting.NewCfg()
+		userService.cfg.CaseInsensitiveLogin = true
+		userStore.ExpectedError = nil
+		userStore.ExpectedUser = &user.User{ID: 1, Login: "login", Email: "user@example.com", Name: "name", EmailLocked: true, LoginLocked: true}
+
+		err := userService.Update(context.Background(), &user.UpdateUserCommand{UserID: 1, Login: "login", Email: "other@example.com", Name: "name", UserInitiated: true})
+		require.ErrorIs(t, err, user.ErrFieldLocked)
+		err = userService.Update(context.Background(), &user.UpdateUserCommand{UserID: 1, Login: "other", Email: "user@example.com", Name: "name", UserInitiated: true})
+		require.ErrorIs(t, err, user.ErrFieldLocked)
+		// unlocked fields, and locked fields left as they are, can be changed
+		require.NoError(t, userService.Update(context.Background(), &user.UpdateUserCommand{UserID: 1, Login: "LOGIN", Email: "user@example.com", Name: "other", UserInitiated: true}))
+		// admins can change locked fields
+		require.NoError(t, userService.Update(context.Background(), &user.UpdateUserCommand{UserID: 1, Login: "other", Email: "other@example.com", Name: "name"}))
+	})
+
+	t.Run("locking profile fields is audited", func(t *testing.T) {
+		expectedUser := userStore.ExpectedUser
+		t.Cleanup(func() { userStore.ExpectedUser = expectedUser })
+		userService.cfg = setting.NewCfg()
+		userStore.ExpectedError = nil
+		userStore.ExpectedUser = &user.User{ID: 1, Login: "login", NameLocked: true}
+		userStore.AuditEntries = nil
+
+		require.NoError(t, userService.SetFieldLocks(context.Background(), &user.SetUserFieldLocksCommand{UserID: 1, Email: true, Login: true}))
+		require.Len(t, userStore.AuditEntries, 1)
+		assert.Equal(t, user.AuditActionLockFields, userStore.AuditEntries[0].Action)
+		assert.Equal(t, []user.AuditChange{
+			{Field: "nameLocked", OldValue: "true", NewValue: "false"},
+			{Field: "emailLocked", OldValue: "false", NewValue: "true"},
+			{Field: "loginLocked", OldValue: "false", NewValue: "true"},
+		}, userStore.AuditEntries[0].Changes)
+	})
+
+	t.Run("quotas of users are limited to the user quota targets", func(t *testing.T) {
+		userService.cfg = setting.NewCfg()
+		userService.cfg.Quota = setting.QuotaSettings{Enabled: true, User: &setting.UserQuota{Org: 10, Dashboard: -1, DataSource: 5, ApiKey: 5}}
+		userStore.ExpectedError = nil
+
+		quotas, err := userService.GetQuotas(context.Background(), &user.GetQuotasQuery{UserID: 1, Targets: []string{"dashboard"}})
+		require.NoError(t, err)
+		require.Equal(t, []*user.Quota{{UserID: 1, Target: "dashboard", Limit: -1}}, quotas)
+
+		_, err = userService.GetQuotas(context.Background(), &user.GetQuotasQuery{UserID: 1, Targets: []string{"alert_rule"}})
+		require.ErrorIs(t, err, user.ErrInvalidQuotaTarget)
+		require.ErrorIs(t, userService.SetQuota(context.Background(), &user.SetQuotaCommand{UserID: 1, Target: "alert_rule", Limit: 1}), user.ErrInvalidQuotaTarget)
+		require.NoError(t, userService.SetQuota(context.Background(), &user.SetQuotaCommand{UserID: 1, Target: "api_key", Limit: 1}))
+
+		userStore.ExpectedUserProfile = &user.UserProfileDTO{ID: 1}
+		profile, err := userService.GetProfile(context.Background(), &user.GetUserProfileQuery{UserID: 1})
+		require.NoError(t, err)
+		require.Len(t, profile.Quotas, 4)
+	})
+
+	t.Run("users are not limited without per user quota settings", func(t *testing.T) {
+		userService.cfg = setting.NewCfg()
+		userService.cfg.Quota = setting.QuotaSettings{Enabled: true}
+		userStore.ExpectedError = nil
+
+		quotas, err := userService.GetQuotas(context.Background(), &user.GetQuotasQuery{UserID: 1, Targets: []string{"dashboard"}})
+		require.NoError(t, err)
+		require.Equal(t, []*user.Quota{{UserID: 1, Target: "dashboard", Limit: -1}}, quotas)
+		require.NoError(t, userService.SetQuota(context.Background(), &user.SetQuotaCommand{UserID: 1, Target: "dashboard", Limit: 1}))
+	})
+
 	t.Run("GetByID - email conflict", func(t *testing.T) {
 		userService.cfg.CaseInsensitiveLogin = true
 		userStore.ExpectedError = errors.New("email conflict")
@@ -97,10 +669,10 @@ func TestUserService(t *testing.T) {
 		userStore := newUserStoreFake()
 		orgService := orgtest.NewOrgServiceFake()
 		userService := Service{
-			store:        userStore,
-			orgService:   orgService,
-			cacheService: localcache.ProvideService(),
-			teamService:  teamtest.NewFakeService(),
+			store:         userStore,
+			orgService:    orgService,
+			signedInUsers: newSignedInUserCache(setting.NewCfg(), localcache.ProvideService(), nil),
+			teamService:   teamtest.NewFakeService(),
 		}
 		usr := &user.SignedInUser{
 			OrgID:       1,
@@ -135,6 +707,20 @@ type FakeUserStore struct {
 	ExpectedSearchUserQueryResult *user.SearchUserQueryResult
 	ExpectedError                 error
 	ExpectedDeleteUserError       error
+	ExpectedPasswordHistory       []string
+	ExpectedConflicts             []*user.UserConflict
+	ExpectedInactiveUsers         []*user.User
+	// ExpectedUsersByID overrides ExpectedUser for the users it holds
+	ExpectedUsersByID map[int64]*user.User
+
+	BatchUpdatedLastSeenAt []int64
+	AuditEntries           []*user.AuditEntry
+	EmailVerifications     []*emailVerification
+	Labels                 []string
+	LastLogins             []*user.LoginClient
+	// OwnershipTransfers maps the users whose resources were transferred to their successor
+	OwnershipTransfers map[int64]int64
+	LoginHistory       []*user.LoginHistoryEntry
 }
 
 func newUserStoreFake() *FakeUserStore {
@@ -153,11 +739,29 @@ func (f *FakeUserStore) Delete(ctx context.Context, userID int64) error {
 	return f.ExpectedDeleteUserError
 }
 
+func (f *FakeUserStore) TransferOwnership(ctx context.Context, userID, successorID int64) error {
+	if f.OwnershipTransfers == nil {
+		f.OwnershipTransfers = map[int64]int64{}
+	}
+	f.OwnershipTransfers[userID] = successorID
+	return f.ExpectedError
+}
+
+func (f *FakeUserStore) GetByIDs(ctx context.Context, userIDs []int64) (map[int64]*user.User, error) {
+	return f.ExpectedUsersByID, f.ExpectedError
+}
+
 func (f *FakeUserStore) GetNotServiceAccount(ctx context.Context, userID int64) (*user.User, error) {
+	if usr, ok := f.ExpectedUsersByID[userID]; ok {
+		return usr, f.ExpectedError
+	}
 	return f.ExpectedUser, f.ExpectedError
 }
 
-func (f *FakeUserStore) GetByID(context.Context, int64) (*user.User, error) {
+func (f *FakeUserStore) GetByID(ctx context.Context, userID int64) (*user.User, error) {
+	if usr, ok := f.ExpectedUsersByID[userID]; ok {
+		return usr, f.ExpectedError
+	}
 	return f.ExpectedUser, f.ExpectedError
 }
 
@@ -206,9 +810,150 @@ func (f *FakeUserStore) UpdatePermissions(ctx context.Context, userID int64, isA
 }
 
 func (f *FakeUserStore) BatchDisableUsers(ctx context.Context, cmd *user.BatchDisableUsersCommand) error {
+	if f.ExpectedError == nil {
+		cmd.UpdatedUserIDs = cmd.UserIDs
+	}
+	return f.ExpectedError
+}
+
+func (f *FakeUserStore) BatchDeleteUsers(ctx context.Context, userIDs []int64) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserStore) Restore(ctx context.Context, userID int64) error {
 	return f.ExpectedError
 }
 
+func (f *FakeUserStore) Anonymize(ctx context.Context, userID int64) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserStore) MergeUsers(ctx context.Context, targetID, sourceID int64) (*mergedUserRows, error) {
+	return &mergedUserRows{}, f.ExpectedError
+}
+
+func (f *FakeUserStore) GetConflicts(ctx context.Context) ([]*user.UserConflict, error) {
+	return f.ExpectedConflicts, f.ExpectedError
+}
+
+func (f *FakeUserStore) GetInactiveUsers(ctx context.Context, inactiveSince time.Time) ([]*user.User, error) {
+	return f.ExpectedInactiveUsers, f.ExpectedError
+}
+
+func (f *FakeUserStore) InsertAuditEntry(ctx context.Context, entry *user.AuditEntry) error {
+	f.AuditEntries = append(f.AuditEntries, entry)
+	return nil
+}
+
+func (f *FakeUserStore) GetAuditEntries(ctx context.Context, query *user.GetAuditEntriesQuery) (*user.AuditEntriesResult, error) {
+	return &user.AuditEntriesResult{Entries: f.AuditEntries, Page: query.Page, PerPage: query.Limit}, f.ExpectedError
+}
+
+func (f *FakeUserStore) SetFieldLocks(ctx context.Context, cmd *user.SetUserFieldLocksCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserStore) InsertLoginHistory(ctx context.Context, entry *user.LoginHistoryEntry) error {
+	f.LoginHistory = append(f.LoginHistory, entry)
+	return nil
+}
+
+func (f *FakeUserStore) GetLoginHistory(ctx context.Context, query *user.GetLoginHistoryQuery) (*user.LoginHistoryResult, error) {
+	return &user.LoginHistoryResult{Entries: f.LoginHistory, Page: query.Page, PerPage: query.Limit}, f.ExpectedError
+}
+
+func (f *FakeUserStore) PurgeLoginHistory(ctx context.Context, olderThan time.Time) (int64, error) {
+	return 0, f.ExpectedError
+}
+
+func (f *FakeUserStore) InTransaction(ctx context.Context, fn func(context.Context) error) error {
+	return fn(ctx)
+}
+
+func (f *FakeUserStore) CreateEmailVerification(ctx context.Context, verification *emailVerification) error {
+	f.EmailVerifications = append(f.EmailVerifications, verification)
+	return f.ExpectedError
+}
+
+func (f *FakeUserStore) GetEmailVerification(ctx context.Context, tokenHash string) (*emailVerification, error) {
+	for _, verification := range f.EmailVerifications {
+		if verification.TokenHash == tokenHash {
+			return verification, nil
+		}
+	}
+	return nil, user.ErrEmailVerificationTokenInvalid
+}
+
+func (f *FakeUserStore) SetEmailVerified(ctx context.Context, userID int64, email string) error {
+	if f.ExpectedUser == nil || f.ExpectedUser.Email != email {
+		return user.ErrEmailVerificationTokenInvalid
+	}
+	return f.ExpectedError
+}
+
+func (f *FakeUserStore) GetQuotas(ctx context.Context, userID int64, limits map[string]int64, unifiedAlertingEnabled bool) ([]*user.Quota, error) {
+	quotas := make([]*user.Quota, 0, len(limits))
+	for target, limit := range limits {
+		quotas = append(quotas, &user.Quota{UserID: userID, Target: target, Limit: limit})
+	}
+	return quotas, f.ExpectedError
+}
+
+func (f *FakeUserStore) SetQuota(ctx context.Context, cmd *user.SetQuotaCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserStore) SetAttribute(ctx context.Context, cmd *user.SetUserAttributeCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserStore) GetAttributes(ctx context.Context, query *user.GetUserAttributesQuery) (map[string]string, error) {
+	return nil, f.ExpectedError
+}
+
+func (f *FakeUserStore) SetLabels(ctx context.Context, cmd *user.SetUserLabelsCommand) error {
+	f.Labels = cmd.Labels
+	return f.ExpectedError
+}
+
+func (f *FakeUserStore) GetLabels(ctx context.Context, query *user.GetUserLabelsQuery) ([]string, error) {
+	return f.Labels, f.ExpectedError
+}
+
+func (f *FakeUserStore) GetEffectiveAccess(ctx context.Context, userID int64) (*user.EffectiveAccessReport, error) {
+	return nil, f.ExpectedError
+}
+
+func (f *FakeUserStore) UpdateLastLogin(ctx context.Context, userID int64, client *user.LoginClient, loginAt time.Time) error {
+	f.LastLogins = append(f.LastLogins, client)
+	return f.ExpectedError
+}
+
+func (f *FakeUserStore) BatchUpdateLastSeenAt(ctx context.Context, userIDs []int64, lastSeenAt time.Time) error {
+	f.BatchUpdatedLastSeenAt = append(f.BatchUpdatedLastSeenAt, userIDs...)
+	return f.ExpectedError
+}
+
+func (f *FakeUserStore) RecordFailedLogin(ctx context.Context, userID int64, maxAttempts int, lockoutDuration time.Duration) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserStore) IsLocked(ctx context.Context, userID int64) (bool, error) {
+	return false, f.ExpectedError
+}
+
+func (f *FakeUserStore) ResetLockout(ctx context.Context, userID int64) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserStore) GetPasswordHistory(ctx context.Context, userID int64, limit int) ([]string, error) {
+	return f.ExpectedPasswordHistory, f.ExpectedError
+}
+
+func (f *FakeUserStore) PurgeDeleted(ctx context.Context, olderThan time.Time) (int64, error) {
+	return 0, f.ExpectedError
+}
+
 func (f *FakeUserStore) Disable(ctx context.Context, cmd *user.DisableUserCommand) error {
 	return f.ExpectedError
 }
@@ -216,3 +961,7 @@ func (f *FakeUserStore) Disable(ctx context.Context, cmd *user.DisableUserComman
 func (f *FakeUserStore) Search(ctx context.Context, query *user.SearchUsersQuery) (*user.SearchUserQueryResult, error) {
 	return f.ExpectedSearchUserQueryResult, f.ExpectedError
 }
+
+func (f *FakeUserStore) Count(ctx context.Context, query *user.SearchUsersQuery) (*user.CountUsersResult, error) {
+	return &user.CountUsersResult{}, f.ExpectedError
+}
@@ -2,17 +2,35 @@ package usertest
 
 import (
 	"context"
+	"io"
 
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
 type FakeUserService struct {
 	ExpectedUser             *user.User
+	ExpectedUsersByID        map[int64]*user.User
 	ExpectedSignedInUser     *user.SignedInUser
 	ExpectedError            error
 	ExpectedSetUsingOrgError error
 	ExpectedSearchUsers      user.SearchUserQueryResult
+	ExpectedCountUsers       *user.CountUsersResult
 	ExpectedUserProfileDTO   *user.UserProfileDTO
+	ExpectedAttributes       map[string]string
+	ExpectedLabels           []string
+	ExpectedEffectiveAccess  *user.EffectiveAccessReport
+	ExpectedLocked           bool
+	ExpectedEmailNotVerified bool
+	ExpectedToken            string
+	ExpectedConflicts        []*user.UserConflict
+	ExpectedAuditEntries     *user.AuditEntriesResult
+	ExpectedQuotas           []*user.Quota
+	ExpectedLoginHistory     *user.LoginHistoryResult
+
+	// RecordedLogins are the logins recorded with RecordLogin
+	RecordedLogins []*user.RecordLoginCommand
+	// FieldLocks are the field locks set with SetFieldLocks
+	FieldLocks []*user.SetUserFieldLocksCommand
 
 	GetSignedInUserFn func(ctx context.Context, query *user.GetSignedInUserQuery) (*user.SignedInUser, error)
 }
@@ -29,6 +47,10 @@ func (f *FakeUserService) Delete(ctx context.Context, cmd *user.DeleteUserComman
 	return f.ExpectedError
 }
 
+func (f *FakeUserService) GetByIDs(ctx context.Context, userIDs []int64) (map[int64]*user.User, error) {
+	return f.ExpectedUsersByID, f.ExpectedError
+}
+
 func (f *FakeUserService) GetByID(ctx context.Context, query *user.GetUserByIDQuery) (*user.User, error) {
 	return f.ExpectedUser, f.ExpectedError
 }
@@ -75,6 +97,10 @@ func (f *FakeUserService) Search(ctx context.Context, query *user.SearchUsersQue
 	return &f.ExpectedSearchUsers, f.ExpectedError
 }
 
+func (f *FakeUserService) Count(ctx context.Context, query *user.SearchUsersQuery) (*user.CountUsersResult, error) {
+	return f.ExpectedCountUsers, f.ExpectedError
+}
+
 func (f *FakeUserService) Disable(ctx context.Context, cmd *user.DisableUserCommand) error {
 	return f.ExpectedError
 }
@@ -83,10 +109,131 @@ func (f *FakeUserService) BatchDisableUsers(ctx context.Context, cmd *user.Batch
 	return f.ExpectedError
 }
 
+func (f *FakeUserService) BatchDeleteUsers(ctx context.Context, userIDs []int64) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserService) Restore(ctx context.Context, cmd *user.RestoreUserCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserService) AnonymizeUser(ctx context.Context, userID int64) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserService) MergeUsers(ctx context.Context, targetID, sourceID int64) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserService) GetConflicts(ctx context.Context) ([]*user.UserConflict, error) {
+	return f.ExpectedConflicts, f.ExpectedError
+}
+
+func (f *FakeUserService) ResolveConflict(ctx context.Context, cmd *user.ResolveUserConflictCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserService) DeactivateInactiveUsers(ctx context.Context, cmd *user.DeactivateInactiveUsersCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserService) Impersonate(ctx context.Context, cmd *user.ImpersonateUserCommand) (*user.SignedInUser, error) {
+	return f.ExpectedSignedInUser, f.ExpectedError
+}
+
+func (f *FakeUserService) GetAuditEntries(ctx context.Context, query *user.GetAuditEntriesQuery) (*user.AuditEntriesResult, error) {
+	return f.ExpectedAuditEntries, f.ExpectedError
+}
+
+func (f *FakeUserService) SetFieldLocks(ctx context.Context, cmd *user.SetUserFieldLocksCommand) error {
+	f.FieldLocks = append(f.FieldLocks, cmd)
+	return f.ExpectedError
+}
+
+func (f *FakeUserService) RecordLogin(ctx context.Context, cmd *user.RecordLoginCommand) error {
+	f.RecordedLogins = append(f.RecordedLogins, cmd)
+	return f.ExpectedError
+}
+
+func (f *FakeUserService) GetLoginHistory(ctx context.Context, query *user.GetLoginHistoryQuery) (*user.LoginHistoryResult, error) {
+	return f.ExpectedLoginHistory, f.ExpectedError
+}
+
+func (f *FakeUserService) PurgeLoginHistory(ctx context.Context, cmd *user.PurgeLoginHistoryCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserService) GetQuotas(ctx context.Context, query *user.GetQuotasQuery) ([]*user.Quota, error) {
+	return f.ExpectedQuotas, f.ExpectedError
+}
+
+func (f *FakeUserService) SetQuota(ctx context.Context, cmd *user.SetQuotaCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserService) SetAttribute(ctx context.Context, cmd *user.SetUserAttributeCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserService) GetAttributes(ctx context.Context, query *user.GetUserAttributesQuery) (map[string]string, error) {
+	return f.ExpectedAttributes, f.ExpectedError
+}
+
+func (f *FakeUserService) SetLabels(ctx context.Context, cmd *user.SetUserLabelsCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserService) GetLabels(ctx context.Context, query *user.GetUserLabelsQuery) ([]string, error) {
+	return f.ExpectedLabels, f.ExpectedError
+}
+
+func (f *FakeUserService) GetEffectiveAccess(ctx context.Context, query *user.GetEffectiveAccessQuery) (*user.EffectiveAccessReport, error) {
+	return f.ExpectedEffectiveAccess, f.ExpectedError
+}
+
+func (f *FakeUserService) Export(ctx context.Context, query *user.SearchUsersQuery, format user.ExportFormat, w io.Writer) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserService) RecordFailedLogin(ctx context.Context, userID int64) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserService) CreateEmailVerificationToken(ctx context.Context, cmd *user.CreateEmailVerificationTokenCommand) (string, error) {
+	return f.ExpectedToken, f.ExpectedError
+}
+
+func (f *FakeUserService) VerifyEmail(ctx context.Context, cmd *user.VerifyEmailCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserService) CheckEmailVerified(ctx context.Context, usr *user.User) error {
+	if f.ExpectedEmailNotVerified {
+		return user.ErrEmailNotVerified
+	}
+	return nil
+}
+
+func (f *FakeUserService) IsLocked(ctx context.Context, userID int64) (bool, error) {
+	return f.ExpectedLocked, f.ExpectedError
+}
+
+func (f *FakeUserService) ResetLockout(ctx context.Context, userID int64) error {
+	return f.ExpectedError
+}
+
+func (f *FakeUserService) PurgeDeletedUsers(ctx context.Context, cmd *user.PurgeDeletedUsersCommand) error {
+	return f.ExpectedError
+}
+
 func (f *FakeUserService) UpdatePermissions(ctx context.Context, userID int64, isAdmin bool) error {
 	return f.ExpectedError
 }
 
+func (f *FakeUserService) TransferGrafanaAdmin(ctx context.Context, fromUserID, toUserID int64) error {
+	return f.ExpectedError
+}
+
 func (f *FakeUserService) SetUserHelpFlag(ctx context.Context, cmd *user.SetUserHelpFlagCommand) error {
 	return f.ExpectedError
 }
@@ -249,6 +249,8 @@ type Cfg struct {
 	// Security
 	DisableInitAdminCreation          bool
 	DisableBruteForceLoginProtection  bool
+	UserLockoutMaxAttempts            int
+	UserLockoutDuration               time.Duration
 	CookieSecure                      bool
 	CookieSameSiteDisabled            bool
 	CookieSameSiteMode                http.SameSite
@@ -299,6 +301,12 @@ type Cfg struct {
 	SigV4VerboseLogging          bool
 	AzureAuthEnabled             bool
 	BasicAuthEnabled             bool
+	PasswordMinLength            int
+	PasswordRequireUppercase     bool
+	PasswordRequireLowercase     bool
+	PasswordRequireDigit         bool
+	PasswordRequireSymbol        bool
+	PasswordHistoryCount         int
 	AdminUser                    string
 	AdminPassword                string
 	DisableLogin                 bool
@@ -309,6 +317,7 @@ type Cfg struct {
 	AWSAllowedAuthProviders []string
 	AWSAssumeRoleEnabled    bool
 	AWSListMetricsPageLimit int
+	AWSListMetricsCacheTTL  time.Duration
 
 	// Azure Cloud settings
 	Azure *azsettings.AzureSettings
@@ -377,8 +386,33 @@ type Cfg struct {
 
 	// User
 	UserInviteMaxLifetime time.Duration
+	DeletedUserRetention  time.Duration // How long deleted users can be restored before they are purged
 	HiddenUsers           map[string]struct{}
 	CaseInsensitiveLogin  bool // Login and Email will be considered case insensitive
+	// Minimum interval between two updates of the last seen time of a user
+	UserLastSeenUpdateInterval time.Duration
+	// How often the last seen times of users are written in a single batch, 0 writes them on every update
+	UserLastSeenFlushInterval time.Duration
+	// Record the IP address and user agent of the last login of users: "off", "hashed" IP addresses or "raw"
+	LastLoginClientTracking string
+	// How long successful and failed logins are kept in the login history, 0 disables the login history
+	LoginHistoryRetention time.Duration
+	// How long users can go unseen before being disabled, 0 never disables them
+	InactiveUserDeactivationThreshold time.Duration
+	// Only log the inactive users which would be disabled
+	InactiveUserDeactivationDryRun bool
+	// Logins and emails of the users never disabled for inactivity
+	InactiveUserDeactivationExclusions []string
+	// Longest time Grafana server admins can impersonate a user for, 0 disables impersonation
+	ImpersonationMaxDuration time.Duration
+	// Require users to verify their email address before logging in with Grafana's own login
+	EmailVerificationRequired bool
+	// Time email verification tokens are valid for
+	EmailVerificationTokenLifetime time.Duration
+	// Cache of the signed in users, "local" to the instance or shared by its replicas in the "remote" cache
+	SignedInUserCacheBackend string
+	// Time signed in users are cached for
+	SignedInUserCacheTTL time.Duration
 
 	// Annotations
 	AnnotationCleanupJobBatchSize      int64
@@ -428,7 +462,9 @@ type Cfg struct {
 	AutoAssignOrg              bool
 	AutoAssignOrgId            int
 	AutoAssignOrgRole          string
+	AutoAssignOrgRules         []AutoAssignOrgRule
 	OAuthSkipOrgRoleUpdateSync bool
+	LockSyncedProfileFields    bool
 
 	// ExpressionsEnabled specifies whether expressions are enabled.
 	ExpressionsEnabled bool
@@ -467,6 +503,9 @@ type Cfg struct {
 
 	Search SearchSettings
 
+	// Public dashboards
+	PublicDashboards PublicDashboardsSettings
+
 	// Access Control
 	RBACEnabled         bool
 	RBACPermissionCache bool
@@ -663,6 +702,52 @@ type AnnotationCleanupSettings struct {
 	MaxCount int64
 }
 
+// AutoAssignOrgRule assigns the new users with an email domain or a company to an organization with a role
+type AutoAssignOrgRule struct {
+	EmailDomain string
+	Company     string
+	OrgID       int64
+	Role        string
+}
+
+// parseAutoAssignOrgRules parses a comma-separated list of email_domain:<domain>:<org id>:<role> and
+// company:<company>:<org id>:<role> rules
+func parseAutoAssignOrgRules(value string) ([]AutoAssignOrgRule, error) {
+	var rules []AutoAssignOrgRule
+	for _, rawRule := range strings.Split(value, ",") {
+		rawRule = strings.TrimSpace(rawRule)
+		if rawRule == "" {
+			continue
+		}
+
+		parts := strings.Split(rawRule, ":")
+		if len(parts) != 4 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid `auto_assign_org_rules` rule %q, rules must be <email_domain|company>:<value>:<org id>:<role>", rawRule)
+		}
+		rule := AutoAssignOrgRule{Role: parts[3]}
+		switch parts[0] {
+		case "email_domain":
+			rule.EmailDomain = strings.ToLower(parts[1])
+		case "company":
+			rule.Company = parts[1]
+		default:
+			return nil, fmt.Errorf("invalid `auto_assign_org_rules` rule %q, rules match an email_domain or a company", rawRule)
+		}
+		orgID, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil || orgID <= 0 {
+			return nil, fmt.Errorf("invalid `auto_assign_org_rules` rule %q, the org id must be a positive integer", rawRule)
+		}
+		rule.OrgID = orgID
+		switch rule.Role {
+		case "Viewer", "Editor", "Admin":
+		default:
+			return nil, fmt.Errorf("invalid `auto_assign_org_rules` rule %q, the role must be Viewer, Editor or Admin", rawRule)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
 func EnvKey(sectionName string, keyName string) string {
 	sN := strings.ToUpper(strings.ReplaceAll(sectionName, ".", "_"))
 	sN = strings.ReplaceAll(sN, "-", "_")
@@ -1065,6 +1150,7 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	cfg.DashboardPreviews = readDashboardPreviewsSettings(iniFile)
 	cfg.Storage = readStorageSettings(iniFile)
 	cfg.Search = readSearchSettings(iniFile)
+	cfg.PublicDashboards = readPublicDashboardsSettings(iniFile)
 
 	if VerifyEmailEnabled && !cfg.Smtp.Enabled {
 		cfg.Logger.Warn("require_email_validation is enabled but smtp is disabled")
@@ -1152,6 +1238,7 @@ func (cfg *Cfg) handleAWSConfig() {
 		}
 	}
 	cfg.AWSListMetricsPageLimit = awsPluginSec.Key("list_metrics_page_limit").MustInt(500)
+	cfg.AWSListMetricsCacheTTL = awsPluginSec.Key("list_metrics_cache_ttl").MustDuration(5 * time.Minute)
 	// Also set environment variables that can be used by core plugins
 	err := os.Setenv(awsds.AssumeRoleEnabledEnvVarKeyName, strconv.FormatBool(cfg.AWSAssumeRoleEnabled))
 	if err != nil {
@@ -1249,6 +1336,8 @@ func readSecuritySettings(iniFile *ini.File, cfg *Cfg) error {
 	cfg.SecretKey = SecretKey
 	DisableGravatar = security.Key("disable_gravatar").MustBool(true)
 	cfg.DisableBruteForceLoginProtection = security.Key("disable_brute_force_login_protection").MustBool(false)
+	cfg.UserLockoutMaxAttempts = security.Key("user_lockout_max_attempts").MustInt(0)
+	cfg.UserLockoutDuration = security.Key("user_lockout_duration").MustDuration(15 * time.Minute)
 
 	CookieSecure = security.Key("cookie_secure").MustBool(false)
 	cfg.CookieSecure = CookieSecure
@@ -1337,6 +1426,7 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 	cfg.OAuthCookieMaxAge = auth.Key("oauth_state_cookie_max_age").MustInt(600)
 	SignoutRedirectUrl = valueAsString(auth, "signout_redirect_url", "")
 	cfg.OAuthSkipOrgRoleUpdateSync = auth.Key("oauth_skip_org_role_update_sync").MustBool(false)
+	cfg.LockSyncedProfileFields = auth.Key("lock_synced_profile_fields").MustBool(false)
 
 	cfg.DisableLogin = auth.Key("disable_login").MustBool(false)
 
@@ -1360,6 +1450,12 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 	authBasic := iniFile.Section("auth.basic")
 	BasicAuthEnabled = authBasic.Key("enabled").MustBool(true)
 	cfg.BasicAuthEnabled = BasicAuthEnabled
+	cfg.PasswordMinLength = authBasic.Key("password_min_length").MustInt(4)
+	cfg.PasswordRequireUppercase = authBasic.Key("password_require_uppercase").MustBool(false)
+	cfg.PasswordRequireLowercase = authBasic.Key("password_require_lowercase").MustBool(false)
+	cfg.PasswordRequireDigit = authBasic.Key("password_require_digit").MustBool(false)
+	cfg.PasswordRequireSymbol = authBasic.Key("password_require_symbol").MustBool(false)
+	cfg.PasswordHistoryCount = authBasic.Key("password_history_count").MustInt(0)
 
 	// JWT auth
 	authJWT := iniFile.Section("auth.jwt")
@@ -1424,6 +1520,11 @@ func readUserSettings(iniFile *ini.File, cfg *Cfg) error {
 	AutoAssignOrgId = cfg.AutoAssignOrgId
 	cfg.AutoAssignOrgRole = users.Key("auto_assign_org_role").In("Editor", []string{"Editor", "Admin", "Viewer"})
 	AutoAssignOrgRole = cfg.AutoAssignOrgRole
+	autoAssignOrgRules, err := parseAutoAssignOrgRules(valueAsString(users, "auto_assign_org_rules", ""))
+	if err != nil {
+		return err
+	}
+	cfg.AutoAssignOrgRules = autoAssignOrgRules
 	VerifyEmailEnabled = users.Key("verify_email_enabled").MustBool(false)
 
 	cfg.CaseInsensitiveLogin = users.Key("case_insensitive_login").MustBool(false)
@@ -1451,6 +1552,70 @@ func readUserSettings(iniFile *ini.File, cfg *Cfg) error {
 		return errors.New("the minimum supported value for the `user_invite_max_lifetime_duration` configuration is 15m (15 minutes)")
 	}
 
+	deletedUserRetentionVal := valueAsString(users, "deleted_user_retention_duration", "30d")
+	deletedUserRetention, err := gtime.ParseDuration(deletedUserRetentionVal)
+	if err != nil {
+		return err
+	}
+	if deletedUserRetention < 0 {
+		return errors.New("the `deleted_user_retention_duration` configuration cannot be negative")
+	}
+	cfg.DeletedUserRetention = deletedUserRetention
+
+	cfg.UserLastSeenUpdateInterval = users.Key("last_seen_update_interval").MustDuration(5 * time.Minute)
+	cfg.UserLastSeenFlushInterval = users.Key("last_seen_flush_interval").MustDuration(10 * time.Second)
+	if cfg.UserLastSeenUpdateInterval < 0 || cfg.UserLastSeenFlushInterval < 0 {
+		return errors.New("the `last_seen_update_interval` and `last_seen_flush_interval` configurations cannot be negative")
+	}
+
+	cfg.LastLoginClientTracking = valueAsString(users, "last_login_client_tracking", "off")
+	switch cfg.LastLoginClientTracking {
+	case "off", "hashed", "raw":
+	default:
+		return fmt.Errorf("the `last_login_client_tracking` configuration must be off, hashed or raw, got %q", cfg.LastLoginClientTracking)
+	}
+
+	loginHistoryRetention, err := gtime.ParseDuration(valueAsString(users, "login_history_retention", "90d"))
+	if err != nil {
+		return err
+	}
+	if loginHistoryRetention < 0 {
+		return errors.New("the `login_history_retention` configuration cannot be negative")
+	}
+	cfg.LoginHistoryRetention = loginHistoryRetention
+
+	inactiveUserDeactivationVal := valueAsString(users, "inactive_user_deactivation_threshold", "0")
+	inactiveUserDeactivationThreshold, err := gtime.ParseDuration(inactiveUserDeactivationVal)
+	if err != nil {
+		return err
+	}
+	if inactiveUserDeactivationThreshold < 0 {
+		return errors.New("the `inactive_user_deactivation_threshold` configuration cannot be negative")
+	}
+	cfg.InactiveUserDeactivationThreshold = inactiveUserDeactivationThreshold
+	cfg.InactiveUserDeactivationDryRun = users.Key("inactive_user_deactivation_dry_run").MustBool(false)
+	cfg.InactiveUserDeactivationExclusions = util.SplitString(users.Key("inactive_user_deactivation_exclusions").MustString(""))
+
+	cfg.ImpersonationMaxDuration = users.Key("impersonation_max_duration").MustDuration(time.Hour)
+	if cfg.ImpersonationMaxDuration < 0 {
+		return errors.New("the `impersonation_max_duration` configuration cannot be negative")
+	}
+
+	cfg.EmailVerificationRequired = users.Key("email_verification_required").MustBool(false)
+	cfg.EmailVerificationTokenLifetime = users.Key("email_verification_token_lifetime").MustDuration(24 * time.Hour)
+	if cfg.EmailVerificationTokenLifetime <= 0 {
+		return errors.New("the `email_verification_token_lifetime` configuration must be positive")
+	}
+
+	cfg.SignedInUserCacheBackend = valueAsString(users, "signed_in_user_cache", "local")
+	if cfg.SignedInUserCacheBackend != "local" && cfg.SignedInUserCacheBackend != "remote" {
+		return fmt.Errorf("the `signed_in_user_cache` configuration must be local or remote, got %q", cfg.SignedInUserCacheBackend)
+	}
+	cfg.SignedInUserCacheTTL = users.Key("signed_in_user_cache_ttl").MustDuration(5 * time.Second)
+	if cfg.SignedInUserCacheTTL <= 0 {
+		return errors.New("the `signed_in_user_cache_ttl` configuration must be positive")
+	}
+
 	cfg.HiddenUsers = make(map[string]struct{})
 	hiddenUsers := users.Key("hidden_users").MustString("")
 	for _, user := range strings.Split(hiddenUsers, ",") {
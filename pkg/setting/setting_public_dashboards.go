@@ -0,0 +1,57 @@
+package setting
+
+import (
+	"strconv"
+	"time"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type PublicDashboardsSettings struct {
+	// ContinueOnQueryError returns partial results for panels querying multiple data sources
+	// when one of them fails, instead of failing the whole panel
+	ContinueOnQueryError bool
+	// MaxConcurrentQueries is the maximum number of in-flight queries per public dashboard access token.
+	// 0 means no limit
+	MaxConcurrentQueries int
+	// EmailMagicLinkLifetime is how long the magic link emailed to viewers of email-gated public dashboards is valid
+	EmailMagicLinkLifetime time.Duration
+	// EmailSessionLifetime is how long a viewer of an email-gated public dashboard stays signed in after opening
+	// a magic link
+	EmailSessionLifetime time.Duration
+	// GeoIPCIDRFile is the path of the CSV file mapping networks to countries, used to resolve the country of
+	// viewers of public dashboards restricted by country
+	GeoIPCIDRFile string
+	// GeoIPTrustedProxies are the networks of the reverse proxies whose X-Forwarded-For header is honoured when
+	// resolving the IP address of viewers of public dashboards
+	GeoIPTrustedProxies []string
+	// DeletedCreatorPolicy is what happens to the public dashboards of deleted users: flag, reassign, disable or delete
+	DeletedCreatorPolicy string
+	// DeletedCreatorOrgPolicies overrides the deleted creator policy of some organizations, keyed by org id
+	DeletedCreatorOrgPolicies map[int64]string
+}
+
+func readPublicDashboardsSettings(iniFile *ini.File) PublicDashboardsSettings {
+	s := PublicDashboardsSettings{}
+
+	publicDashboardsSection := iniFile.Section("public_dashboards")
+	s.ContinueOnQueryError = publicDashboardsSection.Key("continue_on_query_error").MustBool(false)
+	s.MaxConcurrentQueries = publicDashboardsSection.Key("max_concurrent_queries").MustInt(0)
+	s.EmailMagicLinkLifetime = publicDashboardsSection.Key("email_magic_link_lifetime").MustDuration(15 * time.Minute)
+	s.EmailSessionLifetime = publicDashboardsSection.Key("email_session_lifetime").MustDuration(24 * time.Hour)
+	s.GeoIPCIDRFile = publicDashboardsSection.Key("geoip_cidr_file").MustString("")
+	s.GeoIPTrustedProxies = util.SplitString(publicDashboardsSection.Key("geoip_trusted_proxies").MustString(""))
+	s.DeletedCreatorPolicy = publicDashboardsSection.Key("deleted_creator_policy").MustString("flag")
+
+	s.DeletedCreatorOrgPolicies = map[int64]string{}
+	for _, key := range iniFile.Section("public_dashboards.deleted_creator_org_policies").Keys() {
+		orgId, err := strconv.ParseInt(key.Name(), 10, 64)
+		if err != nil {
+			continue
+		}
+		s.DeletedCreatorOrgPolicies[orgId] = key.MustString("")
+	}
+	return s
+}
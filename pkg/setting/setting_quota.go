@@ -13,7 +13,15 @@ type OrgQuota struct {
 }
 
 type UserQuota struct {
-	Org int64 `target:"org_user"`
+	Org        int64 `target:"org_user"`
+	Dashboard  int64 `target:"dashboard"`
+	DataSource int64 `target:"data_source"`
+	ApiKey     int64 `target:"api_key"`
+}
+
+// UnlimitedUserQuota returns per user limits that do not limit users, for when no per user limits are set
+func UnlimitedUserQuota() *UserQuota {
+	return &UserQuota{Org: -1, Dashboard: -1, DataSource: -1, ApiKey: -1}
 }
 
 type GlobalQuota struct {
@@ -84,7 +92,10 @@ func (cfg *Cfg) readQuotaSettings() {
 
 	// per User limits
 	Quota.User = &UserQuota{
-		Org: quota.Key("user_org").MustInt64(10),
+		Org:        quota.Key("user_org").MustInt64(10),
+		Dashboard:  quota.Key("user_dashboard").MustInt64(-1),
+		DataSource: quota.Key("user_data_source").MustInt64(-1),
+		ApiKey:     quota.Key("user_api_key").MustInt64(-1),
 	}
 
 	// Global Limits
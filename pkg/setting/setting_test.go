@@ -352,6 +352,29 @@ func TestAuthDurationSettings(t *testing.T) {
 	require.Equal(t, maxLifetimeDurationTest, cfg.LoginMaxLifetime)
 }
 
+func TestAutoAssignOrgRulesSettings(t *testing.T) {
+	f := ini.Empty()
+	cfg := NewCfg()
+	sec, err := f.NewSection("users")
+	require.NoError(t, err)
+	_, err = sec.NewKey("auto_assign_org_rules", "email_domain:Example.com:2:Editor, company:Acme Corp:3:Viewer")
+	require.NoError(t, err)
+	require.NoError(t, readUserSettings(f, cfg))
+	require.Equal(t, []AutoAssignOrgRule{
+		{EmailDomain: "example.com", OrgID: 2, Role: "Editor"},
+		{Company: "Acme Corp", OrgID: 3, Role: "Viewer"},
+	}, cfg.AutoAssignOrgRules)
+
+	for _, rules := range []string{"email_domain:example.com:2", "login:admin:2:Editor", "company:Acme:org:Editor", "company:Acme:2:Owner"} {
+		f := ini.Empty()
+		sec, err := f.NewSection("users")
+		require.NoError(t, err)
+		_, err = sec.NewKey("auto_assign_org_rules", rules)
+		require.NoError(t, err)
+		require.Error(t, readUserSettings(f, NewCfg()), rules)
+	}
+}
+
 func TestGetCDNPath(t *testing.T) {
 	var err error
 	cfg := NewCfg()
@@ -0,0 +1,147 @@
+package clients
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"golang.org/x/sync/singleflight"
+)
+
+// ListMetricsCache caches the metrics listed by the ListMetrics API for a TTL, by organization, data source, region and
+// namespace. It's shared by the requests of all the data sources, so the template variables of a dashboard listing
+// the same metrics call the API once. Data source UIDs are only unique within an organization.
+type ListMetricsCache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	calls   singleflight.Group
+	mu      sync.Mutex
+	entries map[listMetricsCacheScope]map[string]listMetricsCacheEntry
+}
+
+type listMetricsCacheScope struct {
+	orgID      int64
+	datasource string
+	region     string
+	namespace  string
+}
+
+type listMetricsCacheEntry struct {
+	metrics []*cloudwatch.Metric
+	expires time.Time
+}
+
+// NewListMetricsCache returns a cache keeping the listed metrics for the TTL, which disables the cache when it isn't
+// positive
+func NewListMetricsCache(ttl time.Duration) *ListMetricsCache {
+	return &ListMetricsCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[listMetricsCacheScope]map[string]listMetricsCacheEntry{},
+	}
+}
+
+func (c *ListMetricsCache) enabled() bool {
+	return c != nil && c.ttl > 0
+}
+
+func (c *ListMetricsCache) get(scope listMetricsCacheScope, key string) ([]*cloudwatch.Metric, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[scope][key]
+	if !ok || !c.now().Before(entry.expires) {
+		return nil, false
+	}
+	return entry.metrics, true
+}
+
+func (c *ListMetricsCache) set(scope listMetricsCacheScope, key string, metrics []*cloudwatch.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	// the expired entries of every scope are removed when one is refreshed, so that the cache doesn't grow unbounded
+	// with the scopes that are no longer listed
+	for s, entries := range c.entries {
+		for k, entry := range entries {
+			if !now.Before(entry.expires) {
+				delete(entries, k)
+			}
+		}
+		if len(entries) == 0 {
+			delete(c.entries, s)
+		}
+	}
+
+	entries, ok := c.entries[scope]
+	if !ok {
+		entries = map[string]listMetricsCacheEntry{}
+		c.entries[scope] = entries
+	}
+	entries[key] = listMetricsCacheEntry{metrics: metrics, expires: now.Add(c.ttl)}
+}
+
+// Invalidate removes the metrics of a data source of an organization cached for a region and a namespace, which are all
+// the namespaces of the region when empty, and returns the number of removed entries
+func (c *ListMetricsCache) Invalidate(orgID int64, datasource string, region string, namespace string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for scope, entries := range c.entries {
+		if scope.orgID != orgID || scope.datasource != datasource || scope.region != region || (namespace != "" && scope.namespace != namespace) {
+			continue
+		}
+		removed += len(entries)
+		delete(c.entries, scope)
+	}
+	return removed
+}
+
+type cachedMetricsClient struct {
+	models.MetricsClientProvider
+	cache      *ListMetricsCache
+	orgID      int64
+	datasource string
+	region     string
+}
+
+// NewCachedMetricsClient returns a metrics client listing the metrics of a data source of an organization in a region
+// through the cache
+func NewCachedMetricsClient(client models.MetricsClientProvider, cache *ListMetricsCache, orgID int64, datasource string, region string) *cachedMetricsClient {
+	return &cachedMetricsClient{MetricsClientProvider: client, cache: cache, orgID: orgID, datasource: datasource, region: region}
+}
+
+func (c *cachedMetricsClient) ListMetricsWithPageLimit(params *cloudwatch.ListMetricsInput) ([]*cloudwatch.Metric, error) {
+	if !c.cache.enabled() {
+		return c.MetricsClientProvider.ListMetricsWithPageLimit(params)
+	}
+
+	scope := listMetricsCacheScope{orgID: c.orgID, datasource: c.datasource, region: c.region, namespace: aws.StringValue(params.Namespace)}
+	key := params.String()
+	if metrics, ok := c.cache.get(scope, key); ok {
+		return metrics, nil
+	}
+
+	// concurrent requests for the same metrics share the call to the API
+	metrics, err, _ := c.cache.calls.Do(fmt.Sprintf("%d\x00%s\x00%s\x00%s", c.orgID, c.datasource, c.region, key), func() (interface{}, error) {
+		metrics, err := c.MetricsClientProvider.ListMetricsWithPageLimit(params)
+		if err != nil {
+			return nil, err
+		}
+		c.cache.set(scope, key, metrics)
+		return metrics, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return metrics.([]*cloudwatch.Metric), nil
+}
+
+func (c *cachedMetricsClient) InvalidateListMetrics(namespace string) int {
+	return c.cache.Invalidate(c.orgID, c.datasource, c.region, namespace)
+}
@@ -0,0 +1,134 @@
+package clients
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedMetricsClient(t *testing.T) {
+	ec2Input := &cloudwatch.ListMetricsInput{Namespace: aws.String("AWS/EC2")}
+	ec2Metrics := []*cloudwatch.Metric{{MetricName: aws.String("CPUUtilization"), Namespace: aws.String("AWS/EC2")}}
+	lambdaInput := &cloudwatch.ListMetricsInput{Namespace: aws.String("AWS/Lambda")}
+	lambdaMetrics := []*cloudwatch.Metric{{MetricName: aws.String("Invocations"), Namespace: aws.String("AWS/Lambda")}}
+
+	newFakeMetricsClient := func() *mocks.FakeMetricsClient {
+		fakeClient := &mocks.FakeMetricsClient{}
+		fakeClient.On("ListMetricsWithPageLimit", ec2Input).Return(ec2Metrics, nil)
+		fakeClient.On("ListMetricsWithPageLimit", lambdaInput).Return(lambdaMetrics, nil)
+		return fakeClient
+	}
+
+	t.Run("lists the metrics once until they expire", func(t *testing.T) {
+		now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+		cache := NewListMetricsCache(time.Minute)
+		cache.now = func() time.Time { return now }
+		fakeClient := newFakeMetricsClient()
+		client := NewCachedMetricsClient(fakeClient, cache, 1, "ds", "us-east-1")
+
+		for i := 0; i < 3; i++ {
+			metrics, err := client.ListMetricsWithPageLimit(ec2Input)
+			require.NoError(t, err)
+			assert.Equal(t, ec2Metrics, metrics)
+		}
+		fakeClient.AssertNumberOfCalls(t, "ListMetricsWithPageLimit", 1)
+
+		now = now.Add(time.Minute)
+		_, err := client.ListMetricsWithPageLimit(ec2Input)
+		require.NoError(t, err)
+		fakeClient.AssertNumberOfCalls(t, "ListMetricsWithPageLimit", 2)
+	})
+
+	t.Run("caches the metrics by organization, data source and region", func(t *testing.T) {
+		cache := NewListMetricsCache(time.Minute)
+		fakeClient := newFakeMetricsClient()
+
+		// data source UIDs are only unique within an organization
+		for _, client := range []*cachedMetricsClient{
+			NewCachedMetricsClient(fakeClient, cache, 1, "ds", "us-east-1"),
+			NewCachedMetricsClient(fakeClient, cache, 1, "ds", "eu-west-1"),
+			NewCachedMetricsClient(fakeClient, cache, 1, "other-ds", "us-east-1"),
+			NewCachedMetricsClient(fakeClient, cache, 2, "ds", "us-east-1"),
+		} {
+			_, err := client.ListMetricsWithPageLimit(ec2Input)
+			require.NoError(t, err)
+		}
+
+		fakeClient.AssertNumberOfCalls(t, "ListMetricsWithPageLimit", 4)
+		assert.Zero(t, NewCachedMetricsClient(fakeClient, cache, 3, "ds", "us-east-1").InvalidateListMetrics(""))
+	})
+
+	t.Run("removes the expired entries of every scope", func(t *testing.T) {
+		now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+		cache := NewListMetricsCache(time.Minute)
+		cache.now = func() time.Time { return now }
+		fakeClient := newFakeMetricsClient()
+
+		_, err := NewCachedMetricsClient(fakeClient, cache, 1, "ds", "us-east-1").ListMetricsWithPageLimit(ec2Input)
+		require.NoError(t, err)
+
+		now = now.Add(time.Minute)
+		_, err = NewCachedMetricsClient(fakeClient, cache, 1, "other-ds", "eu-west-1").ListMetricsWithPageLimit(lambdaInput)
+		require.NoError(t, err)
+
+		assert.Len(t, cache.entries, 1)
+		assert.Contains(t, cache.entries, listMetricsCacheScope{orgID: 1, datasource: "other-ds", region: "eu-west-1", namespace: "AWS/Lambda"})
+	})
+
+	t.Run("invalidates the metrics of a namespace or of all the namespaces of the region", func(t *testing.T) {
+		cache := NewListMetricsCache(time.Minute)
+		fakeClient := newFakeMetricsClient()
+		client := NewCachedMetricsClient(fakeClient, cache, 1, "ds", "us-east-1")
+		otherRegionClient := NewCachedMetricsClient(fakeClient, cache, 1, "ds", "eu-west-1")
+		listAll := func() {
+			for _, c := range []*cachedMetricsClient{client, otherRegionClient} {
+				for _, input := range []*cloudwatch.ListMetricsInput{ec2Input, lambdaInput} {
+					_, err := c.ListMetricsWithPageLimit(input)
+					require.NoError(t, err)
+				}
+			}
+		}
+
+		listAll()
+		fakeClient.AssertNumberOfCalls(t, "ListMetricsWithPageLimit", 4)
+
+		assert.Equal(t, 1, client.InvalidateListMetrics("AWS/EC2"))
+		listAll()
+		fakeClient.AssertNumberOfCalls(t, "ListMetricsWithPageLimit", 5)
+
+		assert.Equal(t, 2, client.InvalidateListMetrics(""))
+		listAll()
+		fakeClient.AssertNumberOfCalls(t, "ListMetricsWithPageLimit", 7)
+	})
+
+	t.Run("doesn't cache errors", func(t *testing.T) {
+		cache := NewListMetricsCache(time.Minute)
+		fakeClient := &mocks.FakeMetricsClient{}
+		fakeClient.On("ListMetricsWithPageLimit", mock.Anything).Return([]*cloudwatch.Metric{}, errors.New("throttled"))
+		client := NewCachedMetricsClient(fakeClient, cache, 1, "ds", "us-east-1")
+
+		for i := 0; i < 2; i++ {
+			_, err := client.ListMetricsWithPageLimit(ec2Input)
+			assert.EqualError(t, err, "throttled")
+		}
+		fakeClient.AssertNumberOfCalls(t, "ListMetricsWithPageLimit", 2)
+	})
+
+	t.Run("doesn't cache the metrics when the TTL isn't positive", func(t *testing.T) {
+		fakeClient := newFakeMetricsClient()
+		client := NewCachedMetricsClient(fakeClient, NewListMetricsCache(0), 1, "ds", "us-east-1")
+
+		for i := 0; i < 2; i++ {
+			_, err := client.ListMetricsWithPageLimit(ec2Input)
+			require.NoError(t, err)
+		}
+		fakeClient.AssertNumberOfCalls(t, "ListMetricsWithPageLimit", 2)
+	})
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/clients"
 	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/cwlog"
 	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/oam"
 )
 
 type DataQueryJson struct {
@@ -95,10 +96,11 @@ type SessionCache interface {
 
 func newExecutor(im instancemgmt.InstanceManager, cfg *setting.Cfg, sessions SessionCache, features featuremgmt.FeatureToggles) *cloudWatchExecutor {
 	e := &cloudWatchExecutor{
-		im:       im,
-		cfg:      cfg,
-		sessions: sessions,
-		features: features,
+		im:               im,
+		cfg:              cfg,
+		sessions:         sessions,
+		features:         features,
+		listMetricsCache: clients.NewListMetricsCache(cfg.AWSListMetricsCacheTTL),
 	}
 
 	e.resourceHandler = httpadapter.New(e.newResourceMux())
@@ -119,9 +121,19 @@ func (e *cloudWatchExecutor) getRequestContext(pluginCtx backend.PluginContext,
 	if err != nil {
 		return models.RequestContext{}, err
 	}
+	datasourceUID := ""
+	if pluginCtx.DataSourceInstanceSettings != nil {
+		datasourceUID = pluginCtx.DataSourceInstanceSettings.UID
+	}
+	metricsClient := clients.NewCachedMetricsClient(clients.NewMetricsClient(NewMetricsAPI(sess), e.cfg), e.listMetricsCache, pluginCtx.OrgID, datasourceUID, r)
 	return models.RequestContext{
-		MetricsClientProvider: clients.NewMetricsClient(NewMetricsAPI(sess), e.cfg),
-		Settings:              instance.Settings,
+		MetricsClientProvider:            metricsClient,
+		ListMetricsCacheProvider:         metricsClient,
+		AlarmsAPIProvider:                NewCWClient(sess),
+		LogsAPIProvider:                  NewCWLogsClient(sess),
+		OAMAPIProvider:                   NewOAMClient(sess),
+		ResourceGroupsTaggingAPIProvider: newRGTAClient(sess),
+		Settings:                         instance.Settings,
 	}, nil
 }
 
@@ -146,10 +158,11 @@ func NewInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 
 // cloudWatchExecutor executes CloudWatch requests.
 type cloudWatchExecutor struct {
-	im       instancemgmt.InstanceManager
-	cfg      *setting.Cfg
-	sessions SessionCache
-	features featuremgmt.FeatureToggles
+	im               instancemgmt.InstanceManager
+	cfg              *setting.Cfg
+	sessions         SessionCache
+	features         featuremgmt.FeatureToggles
+	listMetricsCache *clients.ListMetricsCache
 
 	resourceHandler backend.CallResourceHandler
 }
@@ -390,6 +403,10 @@ func (e *cloudWatchExecutor) executeLogAlertQuery(ctx context.Context, req *back
 			frames = data.Frames{dataframe}
 		}
 
+		deepLink := models.BuildLogsInsightsDeepLink(model.Region, model.LogGroupNames, model.QueryString,
+			q.TimeRange.From, q.TimeRange.To, time.Now())
+		addDataLinksToLogFrames(frames, deepLink)
+
 		respD := resp.Responses["A"]
 		respD.Frames = frames
 		resp.Responses["A"] = respD
@@ -398,6 +415,22 @@ func (e *cloudWatchExecutor) executeLogAlertQuery(ctx context.Context, req *back
 	return resp, nil
 }
 
+// addDataLinksToLogFrames links the fields of the results of a Logs Insights query, except their timestamps, to the
+// query in the console
+func addDataLinksToLogFrames(frames data.Frames, deepLink string) {
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			if field.Type().Time() {
+				continue
+			}
+			if field.Config == nil {
+				field.Config = &data.FieldConfig{}
+			}
+			field.Config.Links = append(field.Config.Links, createDataLinks(deepLink)...)
+		}
+	}
+}
+
 func (e *cloudWatchExecutor) getInstance(pluginCtx backend.PluginContext) (*DataSource, error) {
 	i, err := e.im.Get(pluginCtx)
 	if err != nil {
@@ -434,6 +467,13 @@ var NewCWLogsClient = func(sess *session.Session) cloudwatchlogsiface.CloudWatch
 	return cloudwatchlogs.New(sess)
 }
 
+// NewOAMClient is a CloudWatch Observability Access Manager client factory.
+//
+// Stubbable by tests.
+var NewOAMClient = func(sess *session.Session) models.OAMAPIProvider {
+	return oam.New(sess)
+}
+
 // EC2 client factory.
 //
 // Stubbable by tests.
@@ -9,10 +9,13 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
 	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/grafana-aws-sdk/pkg/awsds"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -251,6 +254,49 @@ func Test_executeLogAlertQuery(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, []string{"instance manager's region"}, sess.calledRegions)
 	})
+
+	t.Run("links the fields except the timestamps to the query in the console", func(t *testing.T) {
+		cli = fakeCWLogsClient{queryResults: cloudwatchlogs.GetQueryResultsOutput{
+			Status: aws.String("Complete"),
+			Results: [][]*cloudwatchlogs.ResultField{{
+				{Field: aws.String("@timestamp"), Value: aws.String("2020-03-20 10:37:23.000")},
+				{Field: aws.String("@message"), Value: aws.String("error")},
+			}},
+		}}
+		im := datasource.NewInstanceManager(func(s backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+			return DataSource{Settings: &models.CloudWatchSettings{}}, nil
+		})
+
+		executor := newExecutor(im, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures())
+		resp, err := executor.QueryData(context.Background(), &backend.QueryDataRequest{
+			Headers:       map[string]string{"FromAlert": "some value"},
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}},
+			Queries: []backend.DataQuery{
+				{
+					TimeRange: backend.TimeRange{From: time.Unix(0, 0), To: time.Unix(60, 0)},
+					JSON: json.RawMessage(`{
+						"queryMode":     "Logs",
+						"region":        "us-east-1",
+						"logGroupNames": ["group_a"],
+						"expression":    "fields @message"
+					}`),
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		frames := resp.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		timeField, _ := frames[0].FieldByName("@timestamp")
+		require.NotNil(t, timeField)
+		assert.Empty(t, timeField.Config.Links)
+		messageField, _ := frames[0].FieldByName("@message")
+		require.NotNil(t, messageField)
+		require.Len(t, messageField.Config.Links, 1)
+		assert.Equal(t, "https://us-east-1.console.aws.amazon.com/cloudwatch/home?region=us-east-1#logs-insights:queryDetail="+
+			"~(end~'1970-01-01T00*3a01*3a00.000Z~start~'1970-01-01T00*3a00*3a00.000Z~timeType~'ABSOLUTE~tz~'UTC"+
+			"~editorString~'fields*20*40message~isLiveTail~false~source~(~'group_a))", messageField.Config.Links[0].URL)
+	})
 }
 
 func TestQuery_ResourceRequest_DescribeAllLogGroups(t *testing.T) {
@@ -471,13 +517,33 @@ func TestQuery_ResourceRequest_DescribeLogGroups(t *testing.T) {
 func Test_CloudWatch_CallResource_Integration_Test(t *testing.T) {
 	sender := &mockedCallResourceResponseSenderForOauth{}
 	origNewMetricsAPI := NewMetricsAPI
+	origNewCWClient := NewCWClient
+	origNewCWLogsClient := NewCWLogsClient
+	origNewOAMClient := NewOAMClient
+	origNewRGTAClient := newRGTAClient
 	t.Cleanup(func() {
 		NewMetricsAPI = origNewMetricsAPI
+		NewCWClient = origNewCWClient
+		NewCWLogsClient = origNewCWLogsClient
+		NewOAMClient = origNewOAMClient
+		newRGTAClient = origNewRGTAClient
 	})
 	var api mocks.FakeMetricsAPI
 	NewMetricsAPI = func(sess *session.Session) models.CloudWatchMetricsAPIProvider {
 		return &api
 	}
+	NewCWClient = func(sess *session.Session) cloudwatchiface.CloudWatchAPI {
+		return &api
+	}
+	NewCWLogsClient = func(sess *session.Session) cloudwatchlogsiface.CloudWatchLogsAPI {
+		return &fakeCWLogsClient{}
+	}
+	NewOAMClient = func(sess *session.Session) models.OAMAPIProvider {
+		return &mocks.FakeOAMAPI{}
+	}
+	newRGTAClient = func(client.ConfigProvider) resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI {
+		return &fakeRGTAClient{}
+	}
 	im := datasource.NewInstanceManager(func(s backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
 		return DataSource{Settings: &models.CloudWatchSettings{}}, nil
 	})
@@ -0,0 +1,175 @@
+package cloudwatch
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+)
+
+const (
+	// maxMetricDataQueriesPerRequest is the maximum number of metric data queries of a GetMetricData request
+	maxMetricDataQueriesPerRequest = 500
+	// maxDatapointsPerRequest is the maximum number of datapoints a GetMetricData request can return
+	maxDatapointsPerRequest = 100800
+)
+
+type timeWindow struct {
+	start time.Time
+	end   time.Time
+}
+
+// executeSplitRequests runs the queries of a region in as many GetMetricData requests as the service limits require,
+// and returns the outputs of the requests as if the queries had been run by a single request
+func (e *cloudWatchExecutor) executeSplitRequests(ctx context.Context, client cloudwatchiface.CloudWatchAPI,
+	startTime time.Time, endTime time.Time, queries []*models.CloudWatchQuery) ([]*cloudwatch.GetMetricDataOutput, error) {
+	mdo := make([]*cloudwatch.GetMetricDataOutput, 0)
+	for _, batch := range splitQueriesInBatches(queries) {
+		windowOutputs := [][]*cloudwatch.GetMetricDataOutput{}
+		for _, window := range splitTimeRange(startTime, endTime, batch) {
+			metricDataInput, err := e.buildMetricDataInput(window.start, window.end, batch)
+			if err != nil {
+				return nil, err
+			}
+
+			outputs, err := e.executeRequest(ctx, client, metricDataInput)
+			if err != nil {
+				return nil, err
+			}
+			windowOutputs = append(windowOutputs, outputs)
+		}
+		mdo = append(mdo, mergeTimeWindows(windowOutputs)...)
+	}
+
+	return mdo, nil
+}
+
+// metricDataQueryCount is the number of metric data queries a query is sent as
+func metricDataQueryCount(query *models.CloudWatchQuery) int {
+	if query.GetGMDAPIMode() == models.GMDApiModeAnomalyDetectionBand {
+		return 2
+	}
+	return 1
+}
+
+// splitQueriesInBatches splits the queries in batches which don't exceed the number of metric data queries of a
+// request. Math expressions can reference any other query of their region, so the queries aren't split when there's one.
+func splitQueriesInBatches(queries []*models.CloudWatchQuery) [][]*models.CloudWatchQuery {
+	for _, query := range queries {
+		if query.IsMathExpression() {
+			return [][]*models.CloudWatchQuery{queries}
+		}
+	}
+
+	batches := [][]*models.CloudWatchQuery{}
+	batch := []*models.CloudWatchQuery{}
+	batchSize := 0
+	for _, query := range queries {
+		count := metricDataQueryCount(query)
+		if batchSize+count > maxMetricDataQueriesPerRequest && len(batch) > 0 {
+			batches = append(batches, batch)
+			batch = []*models.CloudWatchQuery{}
+			batchSize = 0
+		}
+		batch = append(batch, query)
+		batchSize += count
+	}
+
+	return append(batches, batch)
+}
+
+// splitTimeRange splits the time range in consecutive windows whose datapoints don't exceed the ones a request can
+// return. The windows last a multiple of the periods of all the queries, so their datapoints are aligned like the ones
+// of the whole time range. Search expressions are counted as a single time series, as the number of metrics they match
+// isn't known before they're run.
+func splitTimeRange(startTime time.Time, endTime time.Time, queries []*models.CloudWatchQuery) []timeWindow {
+	duration := int64(endTime.Sub(startTime).Seconds())
+	commonPeriod := int64(1)
+	for _, query := range queries {
+		commonPeriod = lcm(commonPeriod, queryPeriod(query))
+		if commonPeriod >= duration {
+			return []timeWindow{{start: startTime, end: endTime}}
+		}
+	}
+
+	datapointsPerCommonPeriod := int64(0)
+	for _, query := range queries {
+		series := int64(1)
+		if query.GetGMDAPIMode() == models.GMDApiModeAnomalyDetectionBand {
+			// the metric, and the upper and lower bounds of its band
+			series = 3
+		}
+		datapointsPerCommonPeriod += series * commonPeriod / queryPeriod(query)
+	}
+	if datapointsPerCommonPeriod*duration/commonPeriod <= maxDatapointsPerRequest {
+		return []timeWindow{{start: startTime, end: endTime}}
+	}
+
+	commonPeriodsPerWindow := maxDatapointsPerRequest / datapointsPerCommonPeriod
+	if commonPeriodsPerWindow == 0 {
+		commonPeriodsPerWindow = 1
+	}
+	windowDuration := time.Duration(commonPeriodsPerWindow*commonPeriod) * time.Second
+
+	windows := []timeWindow{}
+	for start := startTime; start.Before(endTime); start = start.Add(windowDuration) {
+		end := start.Add(windowDuration)
+		if end.After(endTime) {
+			end = endTime
+		}
+		windows = append(windows, timeWindow{start: start, end: end})
+	}
+	return windows
+}
+
+func queryPeriod(query *models.CloudWatchQuery) int64 {
+	if query.Period <= 0 {
+		return 1
+	}
+	return int64(query.Period)
+}
+
+func lcm(a int64, b int64) int64 {
+	x, y := a, b
+	for y != 0 {
+		x, y = y, x%y
+	}
+	return a / x * b
+}
+
+// mergeTimeWindows merges the outputs of the requests of consecutive time windows in a single output, where the
+// datapoints of each time series of a query are concatenated in the order of the windows
+func mergeTimeWindows(windowOutputs [][]*cloudwatch.GetMetricDataOutput) []*cloudwatch.GetMetricDataOutput {
+	if len(windowOutputs) == 1 {
+		return windowOutputs[0]
+	}
+
+	type seriesKey struct {
+		id    string
+		label string
+	}
+	merged := &cloudwatch.GetMetricDataOutput{}
+	resultsBySeries := map[seriesKey]*cloudwatch.MetricDataResult{}
+	for _, outputs := range windowOutputs {
+		for _, output := range outputs {
+			merged.Messages = append(merged.Messages, output.Messages...)
+			for _, result := range output.MetricDataResults {
+				key := seriesKey{id: *result.Id, label: *result.Label}
+				mergedResult, ok := resultsBySeries[key]
+				if !ok {
+					mergedResult = &cloudwatch.MetricDataResult{Id: result.Id, Label: result.Label}
+					resultsBySeries[key] = mergedResult
+					merged.MetricDataResults = append(merged.MetricDataResults, mergedResult)
+				}
+				mergedResult.Timestamps = append(mergedResult.Timestamps, result.Timestamps...)
+				mergedResult.Values = append(mergedResult.Values, result.Values...)
+				mergedResult.Messages = append(mergedResult.Messages, result.Messages...)
+				mergedResult.StatusCode = result.StatusCode
+			}
+		}
+	}
+
+	return []*cloudwatch.GetMetricDataOutput{merged}
+}
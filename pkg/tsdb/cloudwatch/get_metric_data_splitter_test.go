@@ -0,0 +1,180 @@
+package cloudwatch
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/mocks"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMetricStatQueries(count int, period int) []*models.CloudWatchQuery {
+	queries := make([]*models.CloudWatchQuery, 0, count)
+	for i := 0; i < count; i++ {
+		queries = append(queries, &models.CloudWatchQuery{
+			RefId:            fmt.Sprintf("q%d", i),
+			Id:               fmt.Sprintf("q%d", i),
+			Region:           "us-east-1",
+			Namespace:        "AWS/EC2",
+			MetricName:       "CPUUtilization",
+			Dimensions:       map[string][]string{"InstanceId": {fmt.Sprintf("i-%d", i)}},
+			Statistic:        "Average",
+			Period:           period,
+			MatchExact:       true,
+			MetricQueryType:  models.MetricQueryTypeSearch,
+			MetricEditorMode: models.MetricEditorModeBuilder,
+		})
+	}
+	return queries
+}
+
+func TestSplitQueriesInBatches(t *testing.T) {
+	t.Run("splits the queries in batches of at most 500 metric data queries", func(t *testing.T) {
+		batches := splitQueriesInBatches(newMetricStatQueries(1001, 60))
+
+		require.Len(t, batches, 3)
+		assert.Len(t, batches[0], 500)
+		assert.Len(t, batches[1], 500)
+		assert.Len(t, batches[2], 1)
+	})
+
+	t.Run("keeps a query and the query of its anomaly detection band in the same batch", func(t *testing.T) {
+		queries := newMetricStatQueries(500, 60)
+		queries[0].AnomalyDetectionBand = &models.AnomalyDetectionBand{StandardDeviations: 2}
+
+		batches := splitQueriesInBatches(queries)
+
+		require.Len(t, batches, 2)
+		assert.Len(t, batches[0], 499)
+		assert.Len(t, batches[1], 1)
+	})
+
+	t.Run("doesn't split the queries when one is a math expression", func(t *testing.T) {
+		queries := newMetricStatQueries(600, 60)
+		queries = append(queries, &models.CloudWatchQuery{
+			Id:               "total",
+			Expression:       "SUM(METRICS())",
+			MetricQueryType:  models.MetricQueryTypeSearch,
+			MetricEditorMode: models.MetricEditorModeRaw,
+		})
+
+		batches := splitQueriesInBatches(queries)
+
+		require.Len(t, batches, 1)
+		assert.Len(t, batches[0], 601)
+	})
+}
+
+func TestSplitTimeRange(t *testing.T) {
+	startTime := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("doesn't split a time range within the datapoints limit", func(t *testing.T) {
+		endTime := startTime.Add(7 * 24 * time.Hour)
+
+		windows := splitTimeRange(startTime, endTime, newMetricStatQueries(10, 60))
+
+		assert.Equal(t, []timeWindow{{start: startTime, end: endTime}}, windows)
+	})
+
+	t.Run("splits a time range exceeding the datapoints limit in windows aligned to the periods", func(t *testing.T) {
+		endTime := startTime.Add(7 * 24 * time.Hour)
+		// 10 queries of 10080 datapoints and 10 queries of 2016 datapoints, that is 120960 datapoints
+		queries := append(newMetricStatQueries(10, 60), newMetricStatQueries(10, 300)...)
+
+		windows := splitTimeRange(startTime, endTime, queries)
+
+		// 300s windows have 60 datapoints, so the windows last 1680 of them
+		windowDuration := 1680 * 300 * time.Second
+		assert.Equal(t, []timeWindow{
+			{start: startTime, end: startTime.Add(windowDuration)},
+			{start: startTime.Add(windowDuration), end: endTime},
+		}, windows)
+	})
+
+	t.Run("counts the bounds of anomaly detection bands", func(t *testing.T) {
+		endTime := startTime.Add(7 * 24 * time.Hour)
+		// 10 time series of 10080 datapoints are within the limit
+		queries := newMetricStatQueries(10, 60)
+		require.Len(t, splitTimeRange(startTime, endTime, queries), 1)
+
+		queries[0].AnomalyDetectionBand = &models.AnomalyDetectionBand{StandardDeviations: 2}
+
+		assert.Len(t, splitTimeRange(startTime, endTime, queries), 2)
+	})
+
+	t.Run("doesn't split a time range shorter than the periods", func(t *testing.T) {
+		endTime := startTime.Add(time.Hour)
+
+		windows := splitTimeRange(startTime, endTime, newMetricStatQueries(2000, 3600))
+
+		assert.Equal(t, []timeWindow{{start: startTime, end: endTime}}, windows)
+	})
+}
+
+func TestMergeTimeWindows(t *testing.T) {
+	t1 := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+	t3 := t2.Add(time.Minute)
+	result := func(id string, label string, status string, timestamps ...time.Time) *cloudwatch.MetricDataResult {
+		r := &cloudwatch.MetricDataResult{Id: aws.String(id), Label: aws.String(label), StatusCode: aws.String(status)}
+		for i := range timestamps {
+			r.Timestamps = append(r.Timestamps, &timestamps[i])
+			r.Values = append(r.Values, aws.Float64(float64(timestamps[i].Unix())))
+		}
+		return r
+	}
+
+	t.Run("returns the outputs of a single window unchanged", func(t *testing.T) {
+		outputs := []*cloudwatch.GetMetricDataOutput{{}, {}}
+
+		assert.Equal(t, outputs, mergeTimeWindows([][]*cloudwatch.GetMetricDataOutput{outputs}))
+	})
+
+	t.Run("concatenates the datapoints of the time series of the windows", func(t *testing.T) {
+		merged := mergeTimeWindows([][]*cloudwatch.GetMetricDataOutput{
+			{
+				{MetricDataResults: []*cloudwatch.MetricDataResult{result("a", "i-1", "PartialData", t1)}},
+				{MetricDataResults: []*cloudwatch.MetricDataResult{result("a", "i-1", "Complete", t2), result("a", "i-2", "Complete", t1)}},
+			},
+			{
+				{
+					MetricDataResults: []*cloudwatch.MetricDataResult{result("a", "i-2", "Complete", t3), result("a", "i-1", "Complete", t3)},
+					Messages:          []*cloudwatch.MessageData{{Code: aws.String(maxQueryResultsExceeded)}},
+				},
+			},
+		})
+
+		require.Len(t, merged, 1)
+		assert.Equal(t, []*cloudwatch.MessageData{{Code: aws.String(maxQueryResultsExceeded)}}, merged[0].Messages)
+		assert.Equal(t, []*cloudwatch.MetricDataResult{
+			result("a", "i-1", "Complete", t1, t2, t3),
+			result("a", "i-2", "Complete", t1, t3),
+		}, merged[0].MetricDataResults)
+	})
+}
+
+func TestExecuteSplitRequests(t *testing.T) {
+	api := &mocks.FakeMetricsAPI{}
+	executor := newExecutor(nil, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures())
+	startTime := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	endTime := startTime.Add(7 * 24 * time.Hour)
+
+	_, err := executor.executeSplitRequests(context.Background(), api, startTime, endTime, newMetricStatQueries(510, 60))
+	require.NoError(t, err)
+
+	// a batch of 500 queries over 51 windows of 201 minutes, and a batch of 10 queries over 1 window
+	require.Len(t, api.CallsGetMetricDataWithContext, 52)
+	assert.Len(t, api.CallsGetMetricDataWithContext[0].MetricDataQueries, 500)
+	assert.Equal(t, startTime, *api.CallsGetMetricDataWithContext[0].StartTime)
+	assert.Equal(t, startTime.Add(201*time.Minute), *api.CallsGetMetricDataWithContext[0].EndTime)
+	assert.Len(t, api.CallsGetMetricDataWithContext[51].MetricDataQueries, 10)
+	assert.Equal(t, startTime, *api.CallsGetMetricDataWithContext[51].StartTime)
+	assert.Equal(t, endTime, *api.CallsGetMetricDataWithContext[51].EndTime)
+}
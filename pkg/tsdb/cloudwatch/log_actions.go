@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -15,6 +16,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/cwlog"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -130,7 +132,11 @@ func (e *cloudWatchExecutor) executeLogAction(ctx context.Context, model LogQuer
 	case "GetLogGroupFields":
 		data, err = e.handleGetLogGroupFields(ctx, logsClient, model, query.RefID)
 	case "StartQuery":
-		data, err = e.handleStartQuery(ctx, logsClient, model, query.TimeRange, query.RefID)
+		consoleRegion := region
+		if consoleRegion == defaultRegion {
+			consoleRegion = instance.Settings.Region
+		}
+		data, err = e.handleStartQuery(ctx, logsClient, model, query.TimeRange, query.RefID, consoleRegion)
 	case "StopQuery":
 		data, err = e.handleStopQuery(ctx, logsClient, model)
 	case "GetQueryResults":
@@ -235,8 +241,10 @@ func (e *cloudWatchExecutor) executeStartQuery(ctx context.Context, logsClient c
 	return logsClient.StartQueryWithContext(ctx, startQueryInput)
 }
 
+// handleStartQuery starts a Logs Insights query, and returns its ID with the link to the query in the console of the
+// region, if it's known
 func (e *cloudWatchExecutor) handleStartQuery(ctx context.Context, logsClient cloudwatchlogsiface.CloudWatchLogsAPI,
-	model LogQueryJson, timeRange backend.TimeRange, refID string) (*data.Frame, error) {
+	model LogQueryJson, timeRange backend.TimeRange, refID string, consoleRegion string) (*data.Frame, error) {
 	startQueryResponse, err := e.executeStartQuery(ctx, logsClient, model, timeRange)
 	if err != nil {
 		var awsErr awserr.Error
@@ -260,6 +268,10 @@ func (e *cloudWatchExecutor) handleStartQuery(ctx context.Context, logsClient cl
 			"Region": region,
 		},
 	}
+	if consoleRegion != "" {
+		dataFrame.Meta.Custom.(map[string]interface{})["DeepLink"] = models.BuildLogsInsightsDeepLink(consoleRegion,
+			model.LogGroupNames, model.QueryString, timeRange.From, timeRange.To, time.Now())
+	}
 
 	return dataFrame, nil
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/grafana/grafana-aws-sdk/pkg/awsds"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
@@ -316,6 +317,50 @@ func TestQuery_StartQuery(t *testing.T) {
 		},
 		}, resp)
 	})
+
+	t.Run("links the query to the console of the region of the data source", func(t *testing.T) {
+		const refID = "A"
+		cli = fakeCWLogsClient{}
+
+		timeRange := backend.TimeRange{
+			From: time.Date(2020, 3, 20, 10, 0, 0, 0, time.UTC),
+			To:   time.Date(2020, 3, 20, 11, 0, 0, 0, time.UTC),
+		}
+
+		im := datasource.NewInstanceManager(func(s backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+			return DataSource{Settings: &models.CloudWatchSettings{
+				AWSDatasourceSettings: awsds.AWSDatasourceSettings{Region: "us-east-2"},
+			}}, nil
+		})
+
+		executor := newExecutor(im, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures())
+		resp, err := executor.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{},
+			},
+			Queries: []backend.DataQuery{
+				{
+					RefID:     refID,
+					TimeRange: timeRange,
+					JSON: json.RawMessage(`{
+						"type":          "logAction",
+						"subtype":       "StartQuery",
+						"region":        "default",
+						"logGroupNames": ["group_a"],
+						"queryString":   "fields @message"
+					}`),
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, resp.Responses[refID].Frames, 1)
+		custom := resp.Responses[refID].Frames[0].Meta.Custom.(map[string]interface{})
+		assert.Equal(t, "default", custom["Region"])
+		assert.Equal(t, "https://us-east-2.console.aws.amazon.com/cloudwatch/home?region=us-east-2#logs-insights:queryDetail="+
+			"~(end~'2020-03-20T11*3a00*3a00.000Z~start~'2020-03-20T10*3a00*3a00.000Z~timeType~'ABSOLUTE~tz~'UTC"+
+			"~editorString~'fields*20*40message~isLiveTail~false~source~(~'group_a))", custom["DeepLink"])
+	})
 }
 
 func Test_executeStartQuery(t *testing.T) {
@@ -32,6 +32,9 @@ func (e *cloudWatchExecutor) buildMetricDataInput(startTime time.Time, endTime t
 			return nil, &models.QueryError{Err: err, RefID: query.RefId}
 		}
 		metricDataInput.MetricDataQueries = append(metricDataInput.MetricDataQueries, metricDataQuery)
+		if query.GetGMDAPIMode() == models.GMDApiModeAnomalyDetectionBand {
+			metricDataInput.MetricDataQueries = append(metricDataInput.MetricDataQueries, buildAnomalyDetectionBandQuery(query))
+		}
 	}
 
 	return metricDataInput, nil
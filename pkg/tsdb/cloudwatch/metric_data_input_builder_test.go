@@ -44,3 +44,25 @@ func TestMetricDataInputBuilder(t *testing.T) {
 		})
 	}
 }
+
+func TestMetricDataInputBuilder_AnomalyDetectionBand(t *testing.T) {
+	executor := newExecutor(nil, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures())
+	query := getBaseQuery()
+	query.Id = "m1"
+	query.ReturnData = false
+	query.AnomalyDetectionBand = &models.AnomalyDetectionBand{StandardDeviations: 2.5}
+
+	mdi, err := executor.buildMetricDataInput(time.Now().Add(-time.Hour), time.Now(), []*models.CloudWatchQuery{query})
+
+	require.NoError(t, err)
+	require.Len(t, mdi.MetricDataQueries, 2)
+	metric, band := mdi.MetricDataQueries[0], mdi.MetricDataQueries[1]
+	assert.Equal(t, "m1", *metric.Id)
+	require.NotNil(t, metric.MetricStat)
+	assert.False(t, *metric.ReturnData)
+	assert.Equal(t, "m1_band", *band.Id)
+	assert.Equal(t, "ANOMALY_DETECTION_BAND(m1, 2.5)", *band.Expression)
+	assert.Equal(t, int64(300), *band.Period)
+	assert.True(t, *band.ReturnData)
+	assert.Equal(t, "ANOMALY_DETECTION_BAND(m1, 2.5)", query.UsedExpression)
+}
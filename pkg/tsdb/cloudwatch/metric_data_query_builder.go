@@ -31,7 +31,11 @@ func (e *cloudWatchExecutor) buildMetricDataQuery(query *models.CloudWatchQuery)
 		mdq.Expression = aws.String(query.SqlExpression)
 	case models.GMDApiModeInferredSearchExpression:
 		mdq.Expression = aws.String(buildSearchExpression(query, query.Statistic))
-	case models.GMDApiModeMetricStat:
+		properties := searchLabelProperties(query, e.features.IsEnabled(featuremgmt.FlagCloudWatchDynamicLabels))
+		if len(properties) > 0 {
+			mdq.Label = aws.String(withLabelProperties(aws.StringValue(mdq.Label), properties))
+		}
+	case models.GMDApiModeMetricStat, models.GMDApiModeAnomalyDetectionBand:
 		mdq.MetricStat = &cloudwatch.MetricStat{
 			Metric: &cloudwatch.Metric{
 				Namespace:  aws.String(query.Namespace),
@@ -48,6 +52,9 @@ func (e *cloudWatchExecutor) buildMetricDataQuery(query *models.CloudWatchQuery)
 				})
 		}
 		mdq.MetricStat.Stat = aws.String(query.Statistic)
+		if query.IsSingleAccountQuery() {
+			mdq.AccountId = aws.String(query.AccountId)
+		}
 	}
 
 	if mdq.Expression != nil {
@@ -59,6 +66,19 @@ func (e *cloudWatchExecutor) buildMetricDataQuery(query *models.CloudWatchQuery)
 	return mdq, nil
 }
 
+// buildAnomalyDetectionBandQuery returns the query of the anomaly detection band of the metric of a query, which
+// returns its upper and lower bounds. The band is returned even when the metric is hidden
+func buildAnomalyDetectionBandQuery(query *models.CloudWatchQuery) *cloudwatch.MetricDataQuery {
+	expression := query.AnomalyDetectionBandExpression()
+	query.UsedExpression = expression
+	return &cloudwatch.MetricDataQuery{
+		Id:         aws.String(query.AnomalyDetectionBandId()),
+		Expression: aws.String(expression),
+		Period:     aws.Int64(int64(query.Period)),
+		ReturnData: aws.Bool(true),
+	}
+}
+
 func buildSearchExpression(query *models.CloudWatchQuery, stat string) string {
 	knownDimensions := make(map[string][]string)
 	dimensionNames := []string{}
@@ -96,20 +116,88 @@ func buildSearchExpression(query *models.CloudWatchQuery, stat string) string {
 		searchTerm = appendSearch(searchTerm, keyFilter)
 	}
 
+	if query.IsSingleAccountQuery() {
+		searchTerm = appendSearch(searchTerm, fmt.Sprintf(`:aws.AccountId="%s"`, query.AccountId))
+	}
+
 	if query.MatchExact {
 		schema := fmt.Sprintf("%q", query.Namespace)
 		if len(dimensionNames) > 0 {
 			sort.Strings(dimensionNames)
 			schema += fmt.Sprintf(",%s", join(dimensionNames, ",", `"`, `"`))
 		}
+		searchTerm = appendSearch(searchTerm, buildExclusionSearchTerm(query))
 		return fmt.Sprintf("REMOVE_EMPTY(SEARCH('{%s} %s', '%s', %s))", schema, searchTerm, stat, strconv.Itoa(query.Period))
 	}
 
 	sort.Strings(dimensionNamesWithoutKnownValues)
 	searchTerm = appendSearch(searchTerm, join(dimensionNamesWithoutKnownValues, " ", `"`, `"`))
+	searchTerm = appendSearch(searchTerm, buildExclusionSearchTerm(query))
 	return fmt.Sprintf(`REMOVE_EMPTY(SEARCH('Namespace="%s" %s', '%s', %s))`, query.Namespace, searchTerm, stat, strconv.Itoa(query.Period))
 }
 
+// buildExclusionSearchTerm returns the search term excluding the excluded values of the wildcard dimensions of a query
+func buildExclusionSearchTerm(query *models.CloudWatchQuery) string {
+	keys := make([]string, 0, len(query.ExcludedDimensionValues))
+	for key := range query.ExcludedDimensionValues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	searchTerm := ""
+	for _, key := range keys {
+		values := escapeDoubleQuotes(query.ExcludedDimensionValues[key])
+		valueExpression := join(values, " OR ", `"`, `"`)
+		if len(values) > 1 {
+			valueExpression = fmt.Sprintf(`(%s)`, valueExpression)
+		}
+		searchTerm = appendSearch(searchTerm, fmt.Sprintf(`NOT "%s"=%s`, key, valueExpression))
+	}
+	return searchTerm
+}
+
+// labelProperty is a property of the series of an inferred search expression, which is added to the labels of their
+// frames under labelKey
+type labelProperty struct {
+	labelKey string
+	prop     string
+}
+
+// searchLabelProperties returns the properties the labels of the series of an inferred search expression are suffixed
+// with: the dimensions matching several values when dynamic labels are enabled, as the labels of the series no longer
+// contain their values, and the account of the series of a query of all the linked accounts
+func searchLabelProperties(query *models.CloudWatchQuery, dynamicLabelsEnabled bool) []labelProperty {
+	properties := []labelProperty{}
+	if dynamicLabelsEnabled {
+		keys := make([]string, 0, len(query.Dimensions))
+		for key, values := range query.Dimensions {
+			if len(values) != 1 || values[0] == "*" {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			properties = append(properties, labelProperty{labelKey: key, prop: "Dim." + key})
+		}
+	}
+	if query.AccountId == models.AllLinkedAccounts {
+		properties = append(properties, labelProperty{labelKey: accountIdLabel, prop: "AccountId"})
+	}
+	return properties
+}
+
+// withLabelProperties returns the label of a query suffixed with the properties of the series, which are removed from
+// the labels of the returned series by splitLabelProperties. The default label is used when empty
+func withLabelProperties(label string, properties []labelProperty) string {
+	if label == "" {
+		label = "${LABEL}"
+	}
+	for _, property := range properties {
+		label += labelPropertySeparator + "${PROP('" + property.prop + "')}"
+	}
+	return label
+}
+
 func escapeDoubleQuotes(arr []string) []string {
 	result := []string{}
 	for _, value := range arr {
@@ -111,6 +111,96 @@ func TestMetricDataQueryBuilder(t *testing.T) {
 				assert.Nil(t, mdq.Label)
 			})
 		}
+
+		t.Run("should set the account of a metric stat of a linked account", func(t *testing.T) {
+			executor := newExecutor(nil, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures())
+			query := getBaseQuery()
+			query.AccountId = "123456789012"
+
+			mdq, err := executor.buildMetricDataQuery(query)
+
+			require.NoError(t, err)
+			require.NotNil(t, mdq.MetricStat)
+			assert.Equal(t, "123456789012", *mdq.AccountId)
+		})
+
+		t.Run("should not set the account of a metric stat of all the linked accounts", func(t *testing.T) {
+			executor := newExecutor(nil, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures())
+			query := getBaseQuery()
+			query.AccountId = models.AllLinkedAccounts
+
+			mdq, err := executor.buildMetricDataQuery(query)
+
+			require.NoError(t, err)
+			assert.Nil(t, mdq.AccountId)
+		})
+
+		t.Run("should filter the search expression of a linked account by its account", func(t *testing.T) {
+			executor := newExecutor(nil, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures())
+			query := getBaseQuery()
+			query.MatchExact = false
+			query.AccountId = "123456789012"
+
+			mdq, err := executor.buildMetricDataQuery(query)
+
+			require.NoError(t, err)
+			assert.Equal(t, `REMOVE_EMPTY(SEARCH('Namespace="AWS/EC2" MetricName="CPUUtilization" "LoadBalancer"="lb1" :aws.AccountId="123456789012"', '', 300))`, *mdq.Expression)
+			assert.Nil(t, mdq.Label)
+		})
+
+		t.Run("should label the series of the search expression of all the linked accounts with their account", func(t *testing.T) {
+			executor := newExecutor(nil, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures(featuremgmt.FlagCloudWatchDynamicLabels))
+			query := getBaseQuery()
+			query.MatchExact = false
+			query.AccountId = models.AllLinkedAccounts
+
+			mdq, err := executor.buildMetricDataQuery(query)
+			require.NoError(t, err)
+			assert.Equal(t, `REMOVE_EMPTY(SEARCH('Namespace="AWS/EC2" MetricName="CPUUtilization" "LoadBalancer"="lb1"', '', 300))`, *mdq.Expression)
+			assert.Equal(t, "${LABEL}|&|${PROP('AccountId')}", *mdq.Label)
+
+			query.Label = "${PROP('Dim.LoadBalancer')}"
+			mdq, err = executor.buildMetricDataQuery(query)
+			require.NoError(t, err)
+			assert.Equal(t, "${PROP('Dim.LoadBalancer')}|&|${PROP('AccountId')}", *mdq.Label)
+		})
+
+		t.Run("should suffix the dynamic label of an inferred search with the dimensions matching several values", func(t *testing.T) {
+			executor := newExecutor(nil, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures(featuremgmt.FlagCloudWatchDynamicLabels))
+			query := getBaseQuery()
+			query.Dimensions = map[string][]string{
+				"LoadBalancer":     {"*"},
+				"AvailabilityZone": {"eu-west-1a", "eu-west-1b"},
+				"TargetGroup":      {"tg"},
+			}
+			query.Label = "${PROP('Dim.LoadBalancer')}"
+
+			mdq, err := executor.buildMetricDataQuery(query)
+			require.NoError(t, err)
+			assert.Equal(t, "${PROP('Dim.LoadBalancer')}|&|${PROP('Dim.AvailabilityZone')}|&|${PROP('Dim.LoadBalancer')}", *mdq.Label)
+		})
+
+		t.Run("should not suffix the label of an inferred search with its dimensions when dynamic labels are disabled", func(t *testing.T) {
+			executor := newExecutor(nil, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures())
+			query := getBaseQuery()
+			query.Dimensions = map[string][]string{"LoadBalancer": {"*"}}
+
+			mdq, err := executor.buildMetricDataQuery(query)
+			require.NoError(t, err)
+			assert.Nil(t, mdq.Label)
+		})
+
+		t.Run("should set the dynamic label of a math expression", func(t *testing.T) {
+			executor := newExecutor(nil, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures(featuremgmt.FlagCloudWatchDynamicLabels))
+			query := getBaseQuery()
+			query.MetricEditorMode = models.MetricEditorModeRaw
+			query.Expression = `SUM([a,b])`
+			query.Label = "total ${LABEL}"
+
+			mdq, err := executor.buildMetricDataQuery(query)
+			require.NoError(t, err)
+			assert.Equal(t, "total ${LABEL}", *mdq.Label)
+		})
 	})
 
 	t.Run("Query should be matched exact", func(t *testing.T) {
@@ -181,6 +271,25 @@ func TestMetricDataQueryBuilder(t *testing.T) {
 			assert.Equal(t, `REMOVE_EMPTY(SEARCH('{"AWS/EC2","LoadBalancer"} MetricName="CPUUtilization"', 'Average', 300))`, res)
 		})
 
+		t.Run("Query has a wildcard dimension with excluded values", func(t *testing.T) {
+			query := &models.CloudWatchQuery{
+				Namespace:  "AWS/EC2",
+				MetricName: "CPUUtilization",
+				Dimensions: map[string][]string{
+					"InstanceId":   {"*"},
+					"InstanceType": {"t3.small"},
+				},
+				ExcludedDimensionValues: map[string][]string{
+					"InstanceId": {"i-abc", "i-def"},
+				},
+				Period:     300,
+				MatchExact: matchExact,
+			}
+
+			res := buildSearchExpression(query, "Average")
+			assert.Equal(t, `REMOVE_EMPTY(SEARCH('{"AWS/EC2","InstanceId","InstanceType"} MetricName="CPUUtilization" "InstanceType"="t3.small" NOT "InstanceId"=("i-abc" OR "i-def")', 'Average', 300))`, res)
+		})
+
 		t.Run("Query has three dimension values for two given dimension keys, and one value is a star", func(t *testing.T) {
 			query := &models.CloudWatchQuery{
 				Namespace:  "AWS/EC2",
@@ -284,6 +393,24 @@ func TestMetricDataQueryBuilder(t *testing.T) {
 			assert.Equal(t, `REMOVE_EMPTY(SEARCH('Namespace="AWS/EC2" MetricName="CPUUtilization" "LoadBalancer"', 'Average', 300))`, res)
 		})
 
+		t.Run("Query has a wildcard dimension with an excluded value", func(t *testing.T) {
+			query := &models.CloudWatchQuery{
+				Namespace:  "AWS/EC2",
+				MetricName: "CPUUtilization",
+				Dimensions: map[string][]string{
+					"InstanceId": {"*"},
+				},
+				ExcludedDimensionValues: map[string][]string{
+					"InstanceId": {"i-abc"},
+				},
+				Period:     300,
+				MatchExact: matchExact,
+			}
+
+			res := buildSearchExpression(query, "Average")
+			assert.Equal(t, `REMOVE_EMPTY(SEARCH('Namespace="AWS/EC2" MetricName="CPUUtilization" "InstanceId" NOT "InstanceId"="i-abc"', 'Average', 300))`, res)
+		})
+
 		t.Run("query has three dimension values for two given dimension keys, and one value is a star", func(t *testing.T) {
 			query := &models.CloudWatchQuery{
 				Namespace:  "AWS/EC2",
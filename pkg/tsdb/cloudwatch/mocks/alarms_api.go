@@ -0,0 +1,22 @@
+package mocks
+
+import (
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/stretchr/testify/mock"
+)
+
+type FakeAlarmsAPI struct {
+	mock.Mock
+}
+
+func (a *FakeAlarmsAPI) DescribeAlarmsPages(input *cloudwatch.DescribeAlarmsInput, fn func(*cloudwatch.DescribeAlarmsOutput, bool) bool) error {
+	args := a.Called(input)
+	pages := args.Get(0).([]*cloudwatch.DescribeAlarmsOutput)
+	for i, page := range pages {
+		if !fn(page, i+1 == len(pages)) {
+			break
+		}
+	}
+
+	return args.Error(1)
+}
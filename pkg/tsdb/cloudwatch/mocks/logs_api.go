@@ -0,0 +1,22 @@
+package mocks
+
+import (
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/mock"
+)
+
+type FakeLogsAPI struct {
+	mock.Mock
+}
+
+func (l *FakeLogsAPI) DescribeMetricFiltersPages(input *cloudwatchlogs.DescribeMetricFiltersInput, fn func(*cloudwatchlogs.DescribeMetricFiltersOutput, bool) bool) error {
+	args := l.Called(input)
+	pages := args.Get(0).([]*cloudwatchlogs.DescribeMetricFiltersOutput)
+	for i, page := range pages {
+		if !fn(page, i+1 == len(pages)) {
+			break
+		}
+	}
+
+	return args.Error(1)
+}
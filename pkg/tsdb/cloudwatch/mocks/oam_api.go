@@ -0,0 +1,34 @@
+package mocks
+
+import (
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/oam"
+	"github.com/stretchr/testify/mock"
+)
+
+type FakeOAMAPI struct {
+	mock.Mock
+}
+
+func (a *FakeOAMAPI) ListSinksPages(input *oam.ListSinksInput, fn func(*oam.ListSinksOutput, bool) bool) error {
+	args := a.Called(input)
+	pages := args.Get(0).([]*oam.ListSinksOutput)
+	for i, page := range pages {
+		if !fn(page, i+1 == len(pages)) {
+			break
+		}
+	}
+
+	return args.Error(1)
+}
+
+func (a *FakeOAMAPI) ListAttachedLinksPages(input *oam.ListAttachedLinksInput, fn func(*oam.ListAttachedLinksOutput, bool) bool) error {
+	args := a.Called(input)
+	pages := args.Get(0).([]*oam.ListAttachedLinksOutput)
+	for i, page := range pages {
+		if !fn(page, i+1 == len(pages)) {
+			break
+		}
+	}
+
+	return args.Error(1)
+}
@@ -0,0 +1,22 @@
+package mocks
+
+import (
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/stretchr/testify/mock"
+)
+
+type FakeResourceGroupsTaggingAPI struct {
+	mock.Mock
+}
+
+func (a *FakeResourceGroupsTaggingAPI) GetResourcesPages(input *resourcegroupstaggingapi.GetResourcesInput, fn func(*resourcegroupstaggingapi.GetResourcesOutput, bool) bool) error {
+	args := a.Called(input)
+	pages := args.Get(0).([]*resourcegroupstaggingapi.GetResourcesOutput)
+	for i, page := range pages {
+		if !fn(page, i+1 == len(pages)) {
+			break
+		}
+	}
+
+	return args.Error(1)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -31,27 +32,53 @@ const (
 	GMDApiModeInferredSearchExpression
 	GMDApiModeMathExpression
 	GMDApiModeSQLExpression
+	GMDApiModeAnomalyDetectionBand
 )
 
+// anomalyDetectionBandIdSuffix is appended to the ID of a query to get the ID of its anomaly detection band
+const anomalyDetectionBandIdSuffix = "_band"
+
+// defaultStandardDeviations is the width of an anomaly detection band when not set, as in the CloudWatch console
+const defaultStandardDeviations = 2
+
+// AnomalyDetectionBand is the band of the expected values of the metric of a query, drawn by the
+// ANOMALY_DETECTION_BAND function of CloudWatch from its anomaly detection model
+type AnomalyDetectionBand struct {
+	// StandardDeviations is the width of the band, in standard deviations
+	StandardDeviations float64
+}
+
+// AllLinkedAccounts is the account of the queries of a monitoring account which query the metrics of all the accounts
+// linked to it
+const AllLinkedAccounts = "all"
+
 type CloudWatchQuery struct {
-	RefId             string
-	Region            string
-	Id                string
-	Namespace         string
-	MetricName        string
-	Statistic         string
-	Expression        string
-	SqlExpression     string
-	ReturnData        bool
-	Dimensions        map[string][]string
-	Period            int
-	Alias             string
-	Label             string
-	MatchExact        bool
-	UsedExpression    string
-	TimezoneUTCOffset string
-	MetricQueryType   MetricQueryType
-	MetricEditorMode  MetricEditorMode
+	RefId         string
+	Region        string
+	Id            string
+	Namespace     string
+	MetricName    string
+	Statistic     string
+	Expression    string
+	SqlExpression string
+	ReturnData    bool
+	Dimensions    map[string][]string
+	// ExcludedDimensionValues are the values excluded from the matches of the wildcard dimensions, like i-abc and i-def
+	// for the dimension value * except (i-abc, i-def)
+	ExcludedDimensionValues map[string][]string
+	Period                  int
+	Alias                   string
+	Label                   string
+	MatchExact              bool
+	UsedExpression          string
+	TimezoneUTCOffset       string
+	MetricQueryType         MetricQueryType
+	MetricEditorMode        MetricEditorMode
+	// AccountId is the account whose metrics are queried from a monitoring account, or AllLinkedAccounts. The metrics
+	// of the account of the data source are queried when empty
+	AccountId string
+	// AnomalyDetectionBand is the band drawn along the metric of a metric stat query, nil when it isn't drawn
+	AnomalyDetectionBand *AnomalyDetectionBand
 }
 
 func (q *CloudWatchQuery) GetGMDAPIMode() GMDApiMode {
@@ -59,6 +86,9 @@ func (q *CloudWatchQuery) GetGMDAPIMode() GMDApiMode {
 		if q.IsInferredSearchExpression() {
 			return GMDApiModeInferredSearchExpression
 		}
+		if q.AnomalyDetectionBand != nil {
+			return GMDApiModeAnomalyDetectionBand
+		}
 		return GMDApiModeMetricStat
 	} else if q.MetricQueryType == MetricQueryTypeSearch && q.MetricEditorMode == MetricEditorModeRaw {
 		return GMDApiModeMathExpression
@@ -70,6 +100,22 @@ func (q *CloudWatchQuery) GetGMDAPIMode() GMDApiMode {
 	return GMDApiModeMetricStat
 }
 
+// AnomalyDetectionBandId returns the ID of the GetMetricData query of the anomaly detection band of the query
+func (q *CloudWatchQuery) AnomalyDetectionBandId() string {
+	return q.Id + anomalyDetectionBandIdSuffix
+}
+
+// AnomalyDetectionBandExpression returns the expression of the anomaly detection band of the query
+func (q *CloudWatchQuery) AnomalyDetectionBandExpression() string {
+	standardDeviations := strconv.FormatFloat(q.AnomalyDetectionBand.StandardDeviations, 'f', -1, 64)
+	return fmt.Sprintf("ANOMALY_DETECTION_BAND(%s, %s)", q.Id, standardDeviations)
+}
+
+// IsSingleAccountQuery returns whether the query is for the metrics of a single account linked to a monitoring account
+func (q *CloudWatchQuery) IsSingleAccountQuery() bool {
+	return q.AccountId != "" && q.AccountId != AllLinkedAccounts
+}
+
 func (q *CloudWatchQuery) IsMathExpression() bool {
 	return q.MetricQueryType == MetricQueryTypeSearch && q.MetricEditorMode == MetricEditorModeRaw && !q.IsUserDefinedSearchExpression()
 }
@@ -156,11 +202,23 @@ func (q *CloudWatchQuery) BuildDeepLink(startTime time.Time, endTime time.Time,
 			Stat:   q.Statistic,
 			Period: q.Period,
 		}
+		if q.IsSingleAccountQuery() {
+			metricStatMeta.AccountId = q.AccountId
+		}
 		if dynamicLabelEnabled {
 			metricStatMeta.Label = q.Label
 		}
 		metricStat = append(metricStat, metricStatMeta)
 		link.Metrics = []interface{}{metricStat}
+
+		if q.AnomalyDetectionBand != nil {
+			// the console draws the band of the metric it references by ID
+			metricStatMeta.Id = q.Id
+			link.Metrics = append(link.Metrics, &metricExpression{
+				Expression: q.AnomalyDetectionBandExpression(),
+				Id:         q.AnomalyDetectionBandId(),
+			})
+		}
 	}
 
 	linkProps, err := json.Marshal(link)
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"encoding/json"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -81,6 +84,64 @@ func TestCloudWatchQuery(t *testing.T) {
 			assert.NotContains(t, deepLink, "label")
 		})
 
+		t.Run("includes the account of a metric stat query of a linked account", func(t *testing.T) {
+			startTime := time.Now()
+			endTime := startTime.Add(2 * time.Hour)
+			query := &CloudWatchQuery{
+				RefId:      "A",
+				Region:     "us-east-1",
+				Statistic:  "Average",
+				Period:     300,
+				Id:         "id1",
+				MatchExact: true,
+				Dimensions: map[string][]string{
+					"InstanceId": {"i-12345678"},
+				},
+				MetricQueryType:  MetricQueryTypeSearch,
+				MetricEditorMode: MetricEditorModeBuilder,
+				AccountId:        "123456789012",
+			}
+
+			deepLink, err := query.BuildDeepLink(startTime, endTime, false)
+			require.NoError(t, err)
+			assert.Contains(t, deepLink, url.QueryEscape(`"accountId":"123456789012"`))
+		})
+
+		t.Run("includes the anomaly detection band of a metric stat query", func(t *testing.T) {
+			startTime := time.Now()
+			endTime := startTime.Add(2 * time.Hour)
+			query := &CloudWatchQuery{
+				RefId:      "A",
+				Region:     "us-east-1",
+				Namespace:  "AWS/EC2",
+				MetricName: "CPUUtilization",
+				Statistic:  "Average",
+				Period:     300,
+				Id:         "m1",
+				MatchExact: true,
+				Dimensions: map[string][]string{
+					"InstanceId": {"i-12345678"},
+				},
+				MetricQueryType:      MetricQueryTypeSearch,
+				MetricEditorMode:     MetricEditorModeBuilder,
+				AnomalyDetectionBand: &AnomalyDetectionBand{StandardDeviations: 2},
+			}
+
+			deepLink, err := query.BuildDeepLink(startTime, endTime, false)
+			require.NoError(t, err)
+			parsed, err := url.Parse(deepLink)
+			require.NoError(t, err)
+			fragment, err := url.ParseQuery(strings.TrimPrefix(parsed.Fragment, "metricsV2:"))
+			require.NoError(t, err)
+			var link struct {
+				Metrics []json.RawMessage `json:"metrics"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(fragment.Get("graph")), &link))
+			require.Len(t, link.Metrics, 2)
+			assert.JSONEq(t, `["AWS/EC2","CPUUtilization","InstanceId","i-12345678",{"stat":"Average","period":300,"id":"m1"}]`, string(link.Metrics[0]))
+			assert.JSONEq(t, `{"expression":"ANOMALY_DETECTION_BAND(m1, 2)","id":"m1_band"}`, string(link.Metrics[1]))
+		})
+
 		t.Run("includes label in case dynamic label is enabled and it's a math expression query", func(t *testing.T) {
 			startTime := time.Now()
 			endTime := startTime.Add(2 * time.Hour)
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// relativeTimeRangeTolerance is how close to now the end of a time range has to be for the range to be linked as
+// relative, as the end of a Grafana time range like now-1h to now is resolved slightly before the query runs
+const relativeTimeRangeTolerance = time.Minute
+
+// jsurlProperty is a property of a JSURL object, which are kept in a slice as the console expects them in order
+type jsurlProperty struct {
+	key   string
+	value interface{}
+}
+
+// BuildLogsInsightsDeepLink returns the link to the Logs Insights page of the CloudWatch console running the query
+// in the log groups. The time range is linked as relative to the moment the link is opened when it ends now, and as
+// absolute otherwise.
+func BuildLogsInsightsDeepLink(region string, logGroupNames []string, queryString string, startTime time.Time, endTime time.Time, now time.Time) string {
+	var properties []jsurlProperty
+	if sinceEnd := now.Sub(endTime); sinceEnd > -relativeTimeRangeTolerance && sinceEnd < relativeTimeRangeTolerance {
+		properties = []jsurlProperty{
+			{"end", int64(0)},
+			{"start", -int64(endTime.Sub(startTime).Seconds())},
+			{"timeType", "RELATIVE"},
+			{"unit", "seconds"},
+		}
+	} else {
+		properties = []jsurlProperty{
+			{"end", endTime.UTC().Format("2006-01-02T15:04:05.000Z")},
+			{"start", startTime.UTC().Format("2006-01-02T15:04:05.000Z")},
+			{"timeType", "ABSOLUTE"},
+			{"tz", "UTC"},
+		}
+	}
+	properties = append(properties,
+		jsurlProperty{"editorString", queryString},
+		jsurlProperty{"isLiveTail", false},
+		jsurlProperty{"source", logGroupNames},
+	)
+
+	return fmt.Sprintf(`https://%s.console.aws.amazon.com/cloudwatch/home?region=%s#logs-insights:queryDetail=%s`,
+		region, url.QueryEscape(region), jsurlStringify(properties))
+}
+
+// jsurlStringify encodes a value in JSURL, the compact URL-safe JSON notation of the console links
+func jsurlStringify(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return "~'" + jsurlEncode(value)
+	case bool:
+		return "~" + strconv.FormatBool(value)
+	case int64:
+		return "~" + strconv.FormatInt(value, 10)
+	case []string:
+		if len(value) == 0 {
+			return "~(~)"
+		}
+		var sb strings.Builder
+		sb.WriteString("~(")
+		for _, item := range value {
+			sb.WriteString(jsurlStringify(item))
+		}
+		sb.WriteString(")")
+		return sb.String()
+	case []jsurlProperty:
+		items := make([]string, 0, len(value))
+		for _, property := range value {
+			items = append(items, jsurlEncode(property.key)+jsurlStringify(property.value))
+		}
+		return "~(" + strings.Join(items, "~") + ")"
+	}
+	return "~null"
+}
+
+// jsurlEncode escapes the characters of a JSURL string that aren't letters, digits, _, - or .
+func jsurlEncode(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+			sb.WriteRune(r)
+		case r == '$':
+			sb.WriteString("!")
+		case r < 0x100:
+			fmt.Fprintf(&sb, "*%02x", r)
+		default:
+			for _, unit := range utf16Units(r) {
+				fmt.Fprintf(&sb, "**%04x", unit)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// utf16Units returns the UTF-16 code units of a rune, as JSURL escapes the characters of JavaScript strings
+func utf16Units(r rune) []rune {
+	if r < 0x10000 {
+		return []rune{r}
+	}
+	r -= 0x10000
+	return []rune{0xd800 + (r>>10)&0x3ff, 0xdc00 + r&0x3ff}
+}
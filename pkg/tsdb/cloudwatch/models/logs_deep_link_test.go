@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildLogsInsightsDeepLink(t *testing.T) {
+	t.Run("links an absolute time range", func(t *testing.T) {
+		startTime := time.Date(2016, 12, 31, 15, 0, 0, 0, time.UTC)
+		endTime := startTime.Add(time.Hour)
+
+		deepLink := BuildLogsInsightsDeepLink("us-east-1", []string{"fake-log-group-one", "fake-log-group-two"},
+			"stats count(@message) by bin(1h)", startTime, endTime, endTime.Add(24*time.Hour))
+
+		assert.Equal(t, "https://us-east-1.console.aws.amazon.com/cloudwatch/home?region=us-east-1#logs-insights:queryDetail="+
+			"~(end~'2016-12-31T16*3a00*3a00.000Z~start~'2016-12-31T15*3a00*3a00.000Z~timeType~'ABSOLUTE~tz~'UTC"+
+			"~editorString~'stats*20count*28*40message*29*20by*20bin*281h*29~isLiveTail~false"+
+			"~source~(~'fake-log-group-one~'fake-log-group-two))", deepLink)
+	})
+
+	t.Run("links a time range ending now as relative", func(t *testing.T) {
+		now := time.Now()
+
+		deepLink := BuildLogsInsightsDeepLink("eu-west-1", []string{"group"}, "fields @message", now.Add(-3*time.Hour),
+			now.Add(-time.Second), now)
+
+		assert.Equal(t, "https://eu-west-1.console.aws.amazon.com/cloudwatch/home?region=eu-west-1#logs-insights:queryDetail="+
+			"~(end~0~start~-10799~timeType~'RELATIVE~unit~'seconds~editorString~'fields*20*40message~isLiveTail~false"+
+			"~source~(~'group))", deepLink)
+	})
+
+	t.Run("escapes the characters JSURL reserves", func(t *testing.T) {
+		assert.Equal(t, "~'!foo*27s*20log*e9", jsurlStringify("$foo's logé"))
+		assert.Equal(t, "~'**d83d**de00", jsurlStringify("😀"))
+		assert.Equal(t, "~(~)", jsurlStringify([]string{}))
+	})
+}
@@ -2,7 +2,10 @@ package models
 
 import (
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
 	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models/request"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/oam"
 )
 
 type ListMetricsProvider interface {
@@ -16,6 +19,28 @@ type MetricsClientProvider interface {
 	ListMetricsWithPageLimit(params *cloudwatch.ListMetricsInput) ([]*cloudwatch.Metric, error)
 }
 
+// ListMetricsCacheProvider invalidates the metrics a data source listed in a region and cached
+type ListMetricsCacheProvider interface {
+	InvalidateListMetrics(namespace string) int
+}
+
 type CloudWatchMetricsAPIProvider interface {
 	ListMetricsPages(*cloudwatch.ListMetricsInput, func(*cloudwatch.ListMetricsOutput, bool) bool) error
 }
+
+type CloudWatchAlarmsAPIProvider interface {
+	DescribeAlarmsPages(*cloudwatch.DescribeAlarmsInput, func(*cloudwatch.DescribeAlarmsOutput, bool) bool) error
+}
+
+type CloudWatchLogsAPIProvider interface {
+	DescribeMetricFiltersPages(*cloudwatchlogs.DescribeMetricFiltersInput, func(*cloudwatchlogs.DescribeMetricFiltersOutput, bool) bool) error
+}
+
+type OAMAPIProvider interface {
+	ListSinksPages(*oam.ListSinksInput, func(*oam.ListSinksOutput, bool) bool) error
+	ListAttachedLinksPages(*oam.ListAttachedLinksInput, func(*oam.ListAttachedLinksOutput, bool) bool) error
+}
+
+type ResourceGroupsTaggingAPIProvider interface {
+	GetResourcesPages(*resourcegroupstaggingapi.GetResourcesInput, func(*resourcegroupstaggingapi.GetResourcesOutput, bool) bool) error
+}
@@ -0,0 +1,20 @@
+package request
+
+import (
+	"net/url"
+)
+
+type AccountsRequest struct {
+	*ResourceRequest
+}
+
+func GetAccountsRequest(parameters url.Values) (*AccountsRequest, error) {
+	resourceRequest, err := getResourceRequest(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountsRequest{
+		ResourceRequest: resourceRequest,
+	}, nil
+}
@@ -0,0 +1,22 @@
+package request
+
+import (
+	"net/url"
+)
+
+type CompositeAlarmsRequest struct {
+	*ResourceRequest
+	AlarmNamePrefix string
+}
+
+func GetCompositeAlarmsRequest(parameters url.Values) (*CompositeAlarmsRequest, error) {
+	resourceRequest, err := getResourceRequest(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CompositeAlarmsRequest{
+		ResourceRequest: resourceRequest,
+		AlarmNamePrefix: parameters.Get("alarmNamePrefix"),
+	}, nil
+}
@@ -0,0 +1,22 @@
+package request
+
+import (
+	"net/url"
+)
+
+type ListMetricsCacheRequest struct {
+	*ResourceRequest
+	Namespace string
+}
+
+func GetListMetricsCacheRequest(parameters url.Values) (*ListMetricsCacheRequest, error) {
+	resourceRequest, err := getResourceRequest(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListMetricsCacheRequest{
+		ResourceRequest: resourceRequest,
+		Namespace:       parameters.Get("namespace"),
+	}, nil
+}
@@ -0,0 +1,32 @@
+package request
+
+import (
+	"fmt"
+	"net/url"
+)
+
+type MetricFiltersRequest struct {
+	*ResourceRequest
+	LogGroupName     string
+	FilterNamePrefix string
+}
+
+func GetMetricFiltersRequest(parameters url.Values) (*MetricFiltersRequest, error) {
+	resourceRequest, err := getResourceRequest(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &MetricFiltersRequest{
+		ResourceRequest:  resourceRequest,
+		LogGroupName:     parameters.Get("logGroupName"),
+		FilterNamePrefix: parameters.Get("filterNamePrefix"),
+	}
+
+	// DescribeMetricFilters only accepts a filter name prefix together with a log group name
+	if request.FilterNamePrefix != "" && request.LogGroupName == "" {
+		return nil, fmt.Errorf("logGroupName is required with filterNamePrefix")
+	}
+
+	return request, nil
+}
@@ -0,0 +1,39 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricFiltersRequest(t *testing.T) {
+	t.Run("Should parse parameters with a filter name prefix", func(t *testing.T) {
+		request, err := GetMetricFiltersRequest(map[string][]string{
+			"region":           {"us-east-1"},
+			"logGroupName":     {"/aws/lambda/fn"},
+			"filterNamePrefix": {"err"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "us-east-1", request.Region)
+		assert.Equal(t, "/aws/lambda/fn", request.LogGroupName)
+		assert.Equal(t, "err", request.FilterNamePrefix)
+	})
+
+	t.Run("Should parse parameters without log group", func(t *testing.T) {
+		request, err := GetMetricFiltersRequest(map[string][]string{
+			"region": {"us-east-1"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "", request.LogGroupName)
+		assert.Equal(t, "", request.FilterNamePrefix)
+	})
+
+	t.Run("Should return an error when a filter name prefix is given without log group", func(t *testing.T) {
+		_, err := GetMetricFiltersRequest(map[string][]string{
+			"region":           {"us-east-1"},
+			"filterNamePrefix": {"err"},
+		})
+		require.EqualError(t, err, "logGroupName is required with filterNamePrefix")
+	})
+}
@@ -0,0 +1,25 @@
+package request
+
+import (
+	"net/url"
+)
+
+type MetricsInsightsSchemaRequest struct {
+	*ResourceRequest
+	SqlExpression string
+	// Namespace is the namespace whose dimension keys are returned when the query doesn't select one
+	Namespace string
+}
+
+func GetMetricsInsightsSchemaRequest(parameters url.Values) (*MetricsInsightsSchemaRequest, error) {
+	resourceRequest, err := getResourceRequest(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MetricsInsightsSchemaRequest{
+		ResourceRequest: resourceRequest,
+		SqlExpression:   parameters.Get("sqlExpression"),
+		Namespace:       parameters.Get("namespace"),
+	}, nil
+}
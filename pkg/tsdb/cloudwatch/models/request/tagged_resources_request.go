@@ -0,0 +1,79 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+type TaggedResourcesRequest struct {
+	*ResourceRequest
+	ResourceTypes []string
+	TagFilters    []*TagFilter
+}
+
+// TagFilter matches the resources having a tag, with any of its values when there are some
+type TagFilter struct {
+	Key    string
+	Values []string
+}
+
+func GetTaggedResourcesRequest(parameters url.Values) (*TaggedResourcesRequest, error) {
+	resourceRequest, err := getResourceRequest(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &TaggedResourcesRequest{
+		ResourceRequest: resourceRequest,
+		ResourceTypes:   []string{},
+	}
+	for _, resourceType := range parameters["resourceType"] {
+		if resourceType != "" {
+			request.ResourceTypes = append(request.ResourceTypes, resourceType)
+		}
+	}
+
+	tagFilters, err := parseTagFilters(parameters.Get("tags"))
+	if err != nil {
+		return nil, err
+	}
+	request.TagFilters = tagFilters
+
+	return request, nil
+}
+
+func parseTagFilters(tags string) ([]*TagFilter, error) {
+	tagsMap := map[string]interface{}{}
+	if tags != "" {
+		if err := json.Unmarshal([]byte(tags), &tagsMap); err != nil {
+			return nil, fmt.Errorf("error unmarshaling tags: %v", err)
+		}
+	}
+
+	tagFilters := []*TagFilter{}
+	for key, value := range tagsMap {
+		tagFilter := &TagFilter{Key: key, Values: []string{}}
+		// like the dimension filters, a value can be a string, a string slice or nil
+		switch v := value.(type) {
+		case string:
+			if v != "" && v != "*" {
+				tagFilter.Values = append(tagFilter.Values, v)
+			}
+		case []interface{}:
+			for _, item := range v {
+				if s, ok := item.(string); ok && s != "" && s != "*" {
+					tagFilter.Values = append(tagFilter.Values, s)
+				}
+			}
+		case nil:
+		default:
+			return nil, fmt.Errorf("invalid value of tag %q", key)
+		}
+		tagFilters = append(tagFilters, tagFilter)
+	}
+	sort.Slice(tagFilters, func(i, j int) bool { return tagFilters[i].Key < tagFilters[j].Key })
+
+	return tagFilters, nil
+}
@@ -0,0 +1,49 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaggedResourcesRequest(t *testing.T) {
+	t.Run("Should parse parameters without tags", func(t *testing.T) {
+		request, err := GetTaggedResourcesRequest(map[string][]string{
+			"region":       {"us-east-1"},
+			"resourceType": {"ec2:instance", "rds:db"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "us-east-1", request.Region)
+		assert.Equal(t, []string{"ec2:instance", "rds:db"}, request.ResourceTypes)
+		assert.Empty(t, request.TagFilters)
+	})
+
+	t.Run("Should parse single valued, multi-valued and wildcard tags", func(t *testing.T) {
+		request, err := GetTaggedResourcesRequest(map[string][]string{
+			"region": {"us-east-1"},
+			"tags":   {`{"env": "prod", "team": ["a", "b"], "service": "*", "owner": null}`},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, request.ResourceTypes)
+		assert.Equal(t, []*TagFilter{
+			{Key: "env", Values: []string{"prod"}},
+			{Key: "owner", Values: []string{}},
+			{Key: "service", Values: []string{}},
+			{Key: "team", Values: []string{"a", "b"}},
+		}, request.TagFilters)
+	})
+
+	t.Run("Should return an error if the tags are invalid", func(t *testing.T) {
+		_, err := GetTaggedResourcesRequest(map[string][]string{
+			"region": {"us-east-1"},
+			"tags":   {`{"env": 1}`},
+		})
+		assert.EqualError(t, err, `invalid value of tag "env"`)
+	})
+
+	t.Run("Should return an error if the region is missing", func(t *testing.T) {
+		_, err := GetTaggedResourcesRequest(map[string][]string{})
+		assert.EqualError(t, err, "region is required")
+	})
+}
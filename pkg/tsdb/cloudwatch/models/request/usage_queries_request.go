@@ -0,0 +1,20 @@
+package request
+
+import (
+	"net/url"
+)
+
+type UsageQueriesRequest struct {
+	*ResourceRequest
+}
+
+func GetUsageQueriesRequest(parameters url.Values) (*UsageQueriesRequest, error) {
+	resourceRequest, err := getResourceRequest(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UsageQueriesRequest{
+		ResourceRequest: resourceRequest,
+	}, nil
+}
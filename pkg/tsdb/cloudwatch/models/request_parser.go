@@ -22,27 +22,33 @@ const timeSeriesQuery = "timeSeriesQuery"
 var validMetricDataID = regexp.MustCompile(`^[a-z][a-zA-Z0-9_]*$`)
 
 type metricsDataQuery struct {
-	Datasource        map[string]string      `json:"datasource,omitempty"`
-	Dimensions        map[string]interface{} `json:"dimensions,omitempty"`
-	Expression        string                 `json:"expression,omitempty"`
-	Id                string                 `json:"id,omitempty"`
-	Label             *string                `json:"label,omitempty"`
-	MatchExact        *bool                  `json:"matchExact,omitempty"`
-	MaxDataPoints     int                    `json:"maxDataPoints,omitempty"`
-	MetricEditorMode  *int                   `json:"metricEditorMode,omitempty"`
-	MetricName        string                 `json:"metricName,omitempty"`
-	MetricQueryType   MetricQueryType        `json:"metricQueryType,omitempty"`
-	Namespace         string                 `json:"namespace,omitempty"`
-	Period            string                 `json:"period,omitempty"`
-	RefId             string                 `json:"refId,omitempty"`
-	Region            string                 `json:"region,omitempty"`
-	SqlExpression     string                 `json:"sqlExpression,omitempty"`
-	Statistic         *string                `json:"statistic,omitempty"`
-	Statistics        []*string              `json:"statistics,omitempty"`
-	TimezoneUTCOffset string                 `json:"timezoneUTCOffset,omitempty"`
-	QueryType         string                 `json:"type,omitempty"`
-	Hide              *bool                  `json:"hide,omitempty"`
-	Alias             string                 `json:"alias,omitempty"`
+	AccountId            *string                `json:"accountId,omitempty"`
+	AnomalyDetectionBand *anomalyDetectionBand  `json:"anomalyDetectionBand,omitempty"`
+	Datasource           map[string]string      `json:"datasource,omitempty"`
+	Dimensions           map[string]interface{} `json:"dimensions,omitempty"`
+	Expression           string                 `json:"expression,omitempty"`
+	Id                   string                 `json:"id,omitempty"`
+	Label                *string                `json:"label,omitempty"`
+	MatchExact           *bool                  `json:"matchExact,omitempty"`
+	MaxDataPoints        int                    `json:"maxDataPoints,omitempty"`
+	MetricEditorMode     *int                   `json:"metricEditorMode,omitempty"`
+	MetricName           string                 `json:"metricName,omitempty"`
+	MetricQueryType      MetricQueryType        `json:"metricQueryType,omitempty"`
+	Namespace            string                 `json:"namespace,omitempty"`
+	Period               string                 `json:"period,omitempty"`
+	RefId                string                 `json:"refId,omitempty"`
+	Region               string                 `json:"region,omitempty"`
+	SqlExpression        string                 `json:"sqlExpression,omitempty"`
+	Statistic            *string                `json:"statistic,omitempty"`
+	Statistics           []*string              `json:"statistics,omitempty"`
+	TimezoneUTCOffset    string                 `json:"timezoneUTCOffset,omitempty"`
+	QueryType            string                 `json:"type,omitempty"`
+	Hide                 *bool                  `json:"hide,omitempty"`
+	Alias                string                 `json:"alias,omitempty"`
+}
+
+type anomalyDetectionBand struct {
+	StandardDeviations float64 `json:"standardDeviations,omitempty"`
 }
 
 // ParseMetricDataQueries decodes the metric data queries json, validates, sets default values and returns an array of CloudWatchQueries.
@@ -139,6 +145,9 @@ var aliasPatterns = map[string]string{
 
 var legacyAliasRegexp = regexp.MustCompile(`{{\s*(.+?)\s*}}`)
 
+// dimensionExclusionRegexp matches the dimension values matching any value but a list, like * except (i-abc, i-def)
+var dimensionExclusionRegexp = regexp.MustCompile(`(?i)^\*\s+except\s*\((.*)\)$`)
+
 func migrateAliasToDynamicLabel(queryJson *metricsDataQuery) {
 	fullAliasField := queryJson.Alias
 
@@ -182,7 +191,12 @@ func parseRequestQuery(dataQuery metricsDataQuery, refId string, startTime time.
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse dimensions: %v", err)
 	}
+	excludedDimensionValues, err := parseDimensionExclusions(dimensions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dimensions: %v", err)
+	}
 	result.Dimensions = dimensions
+	result.ExcludedDimensionValues = excludedDimensionValues
 
 	p := dataQuery.Period
 	var period int
@@ -259,6 +273,24 @@ func parseRequestQuery(dataQuery metricsDataQuery, refId string, startTime time.
 		result.Label = *dataQuery.Label
 	}
 
+	if dataQuery.AccountId != nil {
+		result.AccountId = *dataQuery.AccountId
+	}
+
+	if dataQuery.AnomalyDetectionBand != nil {
+		standardDeviations := dataQuery.AnomalyDetectionBand.StandardDeviations
+		if standardDeviations == 0 {
+			standardDeviations = defaultStandardDeviations
+		}
+		if standardDeviations < 0 {
+			return nil, fmt.Errorf("the width of an anomaly detection band must be positive, got %v", standardDeviations)
+		}
+		result.AnomalyDetectionBand = &AnomalyDetectionBand{StandardDeviations: standardDeviations}
+		if result.GetGMDAPIMode() != GMDApiModeAnomalyDetectionBand {
+			return nil, fmt.Errorf("anomaly detection bands can only be drawn for a single metric of the query builder")
+		}
+	}
+
 	return &result, nil
 }
 
@@ -294,6 +326,38 @@ func parseDimensions(dimensions map[string]interface{}) (map[string][]string, er
 	return sortedDimensions, nil
 }
 
+// parseDimensionExclusions replaces the dimension values excluding a list of values by a wildcard, and returns the
+// excluded values of each dimension
+func parseDimensionExclusions(dimensions map[string][]string) (map[string][]string, error) {
+	excludedValues := map[string][]string{}
+	for key, values := range dimensions {
+		for _, value := range values {
+			matches := dimensionExclusionRegexp.FindStringSubmatch(strings.TrimSpace(value))
+			if matches == nil {
+				continue
+			}
+			if len(values) > 1 {
+				return nil, fmt.Errorf("the excluded values of dimension %s can't be combined with other values", key)
+			}
+
+			excluded := []string{}
+			for _, excludedValue := range strings.Split(matches[1], ",") {
+				excludedValue = strings.Trim(strings.TrimSpace(excludedValue), `"`)
+				if excludedValue != "" {
+					excluded = append(excluded, excludedValue)
+				}
+			}
+			if len(excluded) == 0 {
+				return nil, fmt.Errorf("no excluded values for dimension %s", key)
+			}
+			dimensions[key] = []string{"*"}
+			excludedValues[key] = excluded
+		}
+	}
+
+	return excludedValues, nil
+}
+
 func sortDimensions(dimensions map[string][]string) map[string][]string {
 	sortedDimensions := make(map[string][]string)
 	var keys []string
@@ -155,6 +155,44 @@ func TestRequestParser(t *testing.T) {
 
 		assert.Equal(t, `error parsing query "", failed to parse dimensions: unknown type as dimension value`, err.Error())
 	})
+
+	t.Run("Wildcard dimension value with excluded values", func(t *testing.T) {
+		query := []backend.DataQuery{
+			{
+				RefID: "ref1",
+				JSON: json.RawMessage(`{
+				   "refId":"ref1",
+				   "region":"us-east-1",
+				   "namespace":"AWS/EC2",
+				   "metricName":"CPUUtilization",
+				   "dimensions":{
+					  "InstanceId":["* except (i-abc, \"i-def\")"],
+					  "InstanceType":["t3.small"]
+				   },
+				   "statistic":"Average",
+				   "period":"600"
+				}`),
+			},
+		}
+
+		results, err := ParseMetricDataQueries(query, time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour), false)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+
+		assert.Equal(t, map[string][]string{"InstanceId": {"*"}, "InstanceType": {"t3.small"}}, results[0].Dimensions)
+		assert.Equal(t, map[string][]string{"InstanceId": {"i-abc", "i-def"}}, results[0].ExcludedDimensionValues)
+		assert.Equal(t, GMDApiModeInferredSearchExpression, results[0].GetGMDAPIMode())
+	})
+
+	t.Run("parseDimensionExclusions returns error for excluded values combined with other values", func(t *testing.T) {
+		_, err := parseDimensionExclusions(map[string][]string{"InstanceId": {"* except (i-abc)", "i-def"}})
+		assert.EqualError(t, err, "the excluded values of dimension InstanceId can't be combined with other values")
+	})
+
+	t.Run("parseDimensionExclusions returns error for an empty list of excluded values", func(t *testing.T) {
+		_, err := parseDimensionExclusions(map[string][]string{"InstanceId": {"* EXCEPT ( )"}})
+		assert.EqualError(t, err, "no excluded values for dimension InstanceId")
+	})
 }
 
 func Test_ParseMetricDataQueries_periods(t *testing.T) {
@@ -620,6 +658,78 @@ func Test_ParseMetricDataQueries_sets_label_when_label_is_present_in_json_query(
 	assert.Equal(t, "some label", res[0].Label)
 }
 
+func Test_ParseMetricDataQueries_sets_account_id_when_present_in_json_query(t *testing.T) {
+	query := []backend.DataQuery{
+		{
+			JSON: json.RawMessage(`{
+				   "refId":"A",
+				   "region":"us-east-1",
+				   "namespace":"ec2",
+				   "metricName":"CPUUtilization",
+				   "accountId":"123456789012",
+				   "dimensions":{"InstanceId":["test"]},
+				   "statistic":"Average",
+				   "period":"600"
+				}`),
+		},
+	}
+
+	res, err := ParseMetricDataQueries(query, time.Now(), time.Now(), false)
+	assert.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, "123456789012", res[0].AccountId)
+	assert.True(t, res[0].IsSingleAccountQuery())
+}
+
+func Test_ParseMetricDataQueries_anomaly_detection_band(t *testing.T) {
+	queryJSON := func(band string, dimensions string) []backend.DataQuery {
+		return []backend.DataQuery{
+			{
+				RefID: "A",
+				JSON: json.RawMessage(`{
+				   "refId":"A",
+				   "region":"us-east-1",
+				   "namespace":"ec2",
+				   "metricName":"CPUUtilization",
+				   "id":"m1",
+				   "anomalyDetectionBand":` + band + `,
+				   "dimensions":` + dimensions + `,
+				   "statistic":"Average",
+				   "period":"600",
+				   "metricEditorMode":0,
+				   "metricQueryType":0
+				}`),
+			},
+		}
+	}
+
+	t.Run("draws a band of 2 standard deviations by default", func(t *testing.T) {
+		res, err := ParseMetricDataQueries(queryJSON(`{}`, `{"InstanceId":["test"]}`), time.Now(), time.Now(), false)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.NotNil(t, res[0].AnomalyDetectionBand)
+		assert.Equal(t, float64(2), res[0].AnomalyDetectionBand.StandardDeviations)
+		assert.Equal(t, GMDApiModeAnomalyDetectionBand, res[0].GetGMDAPIMode())
+		assert.Equal(t, "ANOMALY_DETECTION_BAND(m1, 2)", res[0].AnomalyDetectionBandExpression())
+	})
+
+	t.Run("draws a band of the standard deviations of the query", func(t *testing.T) {
+		res, err := ParseMetricDataQueries(queryJSON(`{"standardDeviations":1.5}`, `{"InstanceId":["test"]}`), time.Now(), time.Now(), false)
+		require.NoError(t, err)
+		assert.Equal(t, 1.5, res[0].AnomalyDetectionBand.StandardDeviations)
+	})
+
+	t.Run("returns an error for a band of a search expression", func(t *testing.T) {
+		_, err := ParseMetricDataQueries(queryJSON(`{}`, `{"InstanceId":["*"]}`), time.Now(), time.Now(), false)
+		assert.Error(t, err)
+	})
+
+	t.Run("returns an error for a negative width", func(t *testing.T) {
+		_, err := ParseMetricDataQueries(queryJSON(`{"standardDeviations":-1}`, `{"InstanceId":["test"]}`), time.Now(), time.Now(), false)
+		assert.Error(t, err)
+	})
+}
+
 func Test_migrateAliasToDynamicLabel_single_query_preserves_old_alias_and_creates_new_label(t *testing.T) {
 	testCases := map[string]struct {
 		inputAlias    string
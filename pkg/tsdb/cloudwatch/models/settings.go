@@ -8,9 +8,28 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 )
 
+// FrameNamingMode controls where dimension values of a metric series are exposed
+// on the data frames returned by the time series query.
+type FrameNamingMode string
+
+const (
+	// FrameNamingDefault sets the frame name, the value field labels and displayNameFromDS.
+	FrameNamingDefault FrameNamingMode = ""
+	// FrameNamingFrameName only sets the frame name.
+	FrameNamingFrameName FrameNamingMode = "frameName"
+	// FrameNamingLabels only sets the value field labels.
+	FrameNamingLabels FrameNamingMode = "labels"
+	// FrameNamingDisplayName only sets displayNameFromDS on the value field.
+	FrameNamingDisplayName FrameNamingMode = "displayName"
+	// FrameNamingLegacy sets the frame name and labels but not displayNameFromDS, the way
+	// frames were built before displayNameFromDS was introduced.
+	FrameNamingLegacy FrameNamingMode = "legacy"
+)
+
 type CloudWatchSettings struct {
 	awsds.AWSDatasourceSettings
-	Namespace string `json:"customMetricsNamespaces"`
+	Namespace   string          `json:"customMetricsNamespaces"`
+	FrameNaming FrameNamingMode `json:"frameNaming"`
 }
 
 func LoadCloudWatchSettings(config backend.DataSourceInstanceSettings) (*CloudWatchSettings, error) {
@@ -29,6 +48,12 @@ func LoadCloudWatchSettings(config backend.DataSourceInstanceSettings) (*CloudWa
 		instance.Profile = config.Database
 	}
 
+	switch instance.FrameNaming {
+	case FrameNamingDefault, FrameNamingFrameName, FrameNamingLabels, FrameNamingDisplayName, FrameNamingLegacy:
+	default:
+		return nil, fmt.Errorf("invalid frameNaming %q", instance.FrameNaming)
+	}
+
 	instance.AccessKey = config.DecryptedSecureJSONData["accessKey"]
 	instance.SecretKey = config.DecryptedSecureJSONData["secretKey"]
 
@@ -7,8 +7,13 @@ import (
 )
 
 type RequestContext struct {
-	MetricsClientProvider MetricsClientProvider
-	Settings              *CloudWatchSettings
+	MetricsClientProvider            MetricsClientProvider
+	ListMetricsCacheProvider         ListMetricsCacheProvider
+	AlarmsAPIProvider                CloudWatchAlarmsAPIProvider
+	LogsAPIProvider                  CloudWatchLogsAPIProvider
+	OAMAPIProvider                   OAMAPIProvider
+	ResourceGroupsTaggingAPIProvider ResourceGroupsTaggingAPIProvider
+	Settings                         *CloudWatchSettings
 }
 
 type RequestContextFactoryFunc func(pluginCtx backend.PluginContext, region string) (reqCtx RequestContext, err error)
@@ -28,15 +33,95 @@ type cloudWatchLink struct {
 type metricExpression struct {
 	Expression string `json:"expression"`
 	Label      string `json:"label,omitempty"`
+	Id         string `json:"id,omitempty"`
 }
 
 type metricStatMeta struct {
-	Stat   string `json:"stat"`
-	Period int    `json:"period"`
-	Label  string `json:"label,omitempty"`
+	Stat      string `json:"stat"`
+	Period    int    `json:"period"`
+	Label     string `json:"label,omitempty"`
+	AccountId string `json:"accountId,omitempty"`
+	Id        string `json:"id,omitempty"`
 }
 
 type Metric struct {
 	Name      string `json:"name"`
 	Namespace string `json:"namespace"`
 }
+
+type CompositeAlarm struct {
+	Name       string `json:"name"`
+	Arn        string `json:"arn"`
+	Rule       string `json:"rule"`
+	StateValue string `json:"stateValue"`
+}
+
+// Account is an account whose metrics can be queried from a monitoring account, which can be the monitoring account
+// itself
+type Account struct {
+	Id                  string `json:"id"`
+	Arn                 string `json:"arn"`
+	Label               string `json:"label"`
+	IsMonitoringAccount bool   `json:"isMonitoringAccount"`
+}
+
+// SQLExpressionError is a syntax error of a Metrics Insights query, at a position of the query starting at line 1,
+// column 1
+type SQLExpressionError struct {
+	Message string `json:"message"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+}
+
+// MetricsInsightsSchema is the result of the validation of a Metrics Insights query, with the names the query editor
+// completes it with
+type MetricsInsightsSchema struct {
+	Valid      bool                 `json:"valid"`
+	Errors     []SQLExpressionError `json:"errors"`
+	Namespaces []string             `json:"namespaces"`
+	Statistics []string             `json:"statistics"`
+	// Namespace is the namespace of the query, or the one of the request when the query is empty or invalid
+	Namespace string `json:"namespace,omitempty"`
+	// Dimensions are the dimension keys of Namespace
+	Dimensions []string `json:"dimensions"`
+}
+
+// TaggedResource is a resource listed by the Resource Groups Tagging API. ResourceId is the identifier of the resource
+// in its ARN, which is the value of the dimension of the resource in the metrics of most services, such as the
+// instance ID of an EC2 instance or the name of a Lambda function.
+type TaggedResource struct {
+	Arn          string            `json:"arn"`
+	ResourceType string            `json:"resourceType"`
+	ResourceId   string            `json:"resourceId"`
+	Tags         map[string]string `json:"tags"`
+}
+
+type MetricFilter struct {
+	Name         string               `json:"name"`
+	LogGroupName string               `json:"logGroupName"`
+	Pattern      string               `json:"pattern"`
+	Metrics      []MetricFilterMetric `json:"metrics"`
+}
+
+type MetricFilterMetric struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// UsageQuery is a prebuilt metrics query in the same shape as the queries sent by the frontend
+type UsageQuery struct {
+	RefId            string              `json:"refId"`
+	Id               string              `json:"id"`
+	Region           string              `json:"region"`
+	Namespace        string              `json:"namespace,omitempty"`
+	MetricName       string              `json:"metricName,omitempty"`
+	Dimensions       map[string][]string `json:"dimensions,omitempty"`
+	Statistic        string              `json:"statistic,omitempty"`
+	Period           string              `json:"period"`
+	Expression       string              `json:"expression,omitempty"`
+	Label            string              `json:"label"`
+	MetricQueryType  MetricQueryType     `json:"metricQueryType"`
+	MetricEditorMode MetricEditorMode    `json:"metricEditorMode"`
+	MatchExact       bool                `json:"matchExact"`
+	Hide             bool                `json:"hide"`
+}
@@ -0,0 +1,195 @@
+// Package oam is a client of CloudWatch Observability Access Manager, which links source accounts to a monitoring
+// account sharing their telemetry. The AWS SDK used by Grafana doesn't have a client of the service yet, so this one
+// only has the operations listing the accounts linked to a monitoring account.
+package oam
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/aws/aws-sdk-go/private/protocol"
+	"github.com/aws/aws-sdk-go/private/protocol/restjson"
+)
+
+const (
+	ServiceName = "oam"
+	ServiceID   = "OAM"
+	EndpointsID = ServiceName
+)
+
+// OAM is a client of CloudWatch Observability Access Manager
+type OAM struct {
+	*client.Client
+}
+
+func New(p client.ConfigProvider, cfgs ...*aws.Config) *OAM {
+	c := p.ClientConfig(EndpointsID, cfgs...)
+	if c.SigningNameDerived || len(c.SigningName) == 0 {
+		c.SigningName = EndpointsID
+	}
+	svc := &OAM{
+		Client: client.New(
+			*c.Config,
+			metadata.ClientInfo{
+				ServiceName:    ServiceName,
+				ServiceID:      ServiceID,
+				SigningName:    c.SigningName,
+				SigningRegion:  c.SigningRegion,
+				PartitionID:    c.PartitionID,
+				Endpoint:       c.Endpoint,
+				APIVersion:     "2022-06-10",
+				ResolvedRegion: c.ResolvedRegion,
+			},
+			c.Handlers,
+		),
+	}
+
+	svc.Handlers.Sign.PushBackNamed(v4.SignRequestHandler)
+	svc.Handlers.Build.PushBackNamed(restjson.BuildHandler)
+	svc.Handlers.Unmarshal.PushBackNamed(restjson.UnmarshalHandler)
+	svc.Handlers.UnmarshalMeta.PushBackNamed(restjson.UnmarshalMetaHandler)
+	svc.Handlers.UnmarshalError.PushBackNamed(
+		protocol.NewUnmarshalErrorHandler(restjson.NewUnmarshalTypedError(map[string]func(protocol.ResponseMetadata) error{})).NamedHandler(),
+	)
+
+	return svc
+}
+
+type ListSinksInput struct {
+	_ struct{} `type:"structure"`
+
+	MaxResults *int64  `min:"1" type:"integer"`
+	NextToken  *string `type:"string"`
+}
+
+type ListSinksOutput struct {
+	_ struct{} `type:"structure"`
+
+	Items     []*ListSinksItem `type:"list" required:"true"`
+	NextToken *string          `type:"string"`
+}
+
+// ListSinksItem is a sink of the account, which makes it a monitoring account
+type ListSinksItem struct {
+	_ struct{} `type:"structure"`
+
+	Arn  *string `type:"string"`
+	Id   *string `type:"string"`
+	Name *string `type:"string"`
+}
+
+type ListAttachedLinksInput struct {
+	_ struct{} `type:"structure"`
+
+	MaxResults     *int64  `min:"1" type:"integer"`
+	NextToken      *string `type:"string"`
+	SinkIdentifier *string `type:"string" required:"true"`
+}
+
+type ListAttachedLinksOutput struct {
+	_ struct{} `type:"structure"`
+
+	Items     []*ListAttachedLinksItem `type:"list" required:"true"`
+	NextToken *string                  `type:"string"`
+}
+
+// ListAttachedLinksItem is the link of a source account to a sink
+type ListAttachedLinksItem struct {
+	_ struct{} `type:"structure"`
+
+	Label         *string   `type:"string"`
+	LinkArn       *string   `type:"string"`
+	ResourceTypes []*string `type:"list"`
+}
+
+func (c *OAM) ListSinksPages(input *ListSinksInput, fn func(*ListSinksOutput, bool) bool) error {
+	p := request.Pagination{
+		NewRequest: func() (*request.Request, error) {
+			var inCpy *ListSinksInput
+			if input != nil {
+				tmp := *input
+				inCpy = &tmp
+			}
+			req, _ := c.listSinksRequest(inCpy)
+			return req, nil
+		},
+	}
+
+	for p.Next() {
+		if !fn(p.Page().(*ListSinksOutput), !p.HasNextPage()) {
+			break
+		}
+	}
+
+	return p.Err()
+}
+
+func (c *OAM) listSinksRequest(input *ListSinksInput) (*request.Request, *ListSinksOutput) {
+	op := &request.Operation{
+		Name:       "ListSinks",
+		HTTPMethod: "POST",
+		HTTPPath:   "/ListSinks",
+		Paginator: &request.Paginator{
+			InputTokens:  []string{"NextToken"},
+			OutputTokens: []string{"NextToken"},
+			LimitToken:   "MaxResults",
+		},
+	}
+	if input == nil {
+		input = &ListSinksInput{}
+	}
+	output := &ListSinksOutput{}
+	return c.NewRequest(op, input, output), output
+}
+
+func (c *OAM) ListAttachedLinksPages(input *ListAttachedLinksInput, fn func(*ListAttachedLinksOutput, bool) bool) error {
+	p := request.Pagination{
+		NewRequest: func() (*request.Request, error) {
+			var inCpy *ListAttachedLinksInput
+			if input != nil {
+				tmp := *input
+				inCpy = &tmp
+			}
+			req, _ := c.listAttachedLinksRequest(inCpy)
+			return req, nil
+		},
+	}
+
+	for p.Next() {
+		if !fn(p.Page().(*ListAttachedLinksOutput), !p.HasNextPage()) {
+			break
+		}
+	}
+
+	return p.Err()
+}
+
+func (c *OAM) listAttachedLinksRequest(input *ListAttachedLinksInput) (*request.Request, *ListAttachedLinksOutput) {
+	op := &request.Operation{
+		Name:       "ListAttachedLinks",
+		HTTPMethod: "POST",
+		HTTPPath:   "/ListAttachedLinks",
+		Paginator: &request.Paginator{
+			InputTokens:  []string{"NextToken"},
+			OutputTokens: []string{"NextToken"},
+			LimitToken:   "MaxResults",
+		},
+	}
+	if input == nil {
+		input = &ListAttachedLinksInput{}
+	}
+	output := &ListAttachedLinksOutput{}
+	return c.NewRequest(op, input, output), output
+}
+
+// AccountID returns the account of a sink or link ARN, such as the one of a source account for the ARN of its link
+func AccountID(resourceArn string) (string, error) {
+	parsed, err := arn.Parse(resourceArn)
+	if err != nil {
+		return "", err
+	}
+	return parsed.AccountID, nil
+}
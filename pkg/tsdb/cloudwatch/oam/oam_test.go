@@ -0,0 +1,63 @@
+package oam
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAM_ListAttachedLinksPages(t *testing.T) {
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "POST", req.Method)
+		assert.Equal(t, "/ListAttachedLinks", req.URL.Path)
+		assert.Contains(t, req.Header.Get("Authorization"), "/us-east-1/oam/aws4_request")
+
+		body := map[string]interface{}{}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		requests = append(requests, body)
+
+		if body["NextToken"] == nil {
+			_, _ = rw.Write([]byte(`{"Items":[{"Label":"dev","LinkArn":"arn:aws:oam:us-east-1:111111111111:link/abc"}],"NextToken":"next"}`))
+			return
+		}
+		_, _ = rw.Write([]byte(`{"Items":[{"Label":"prod","LinkArn":"arn:aws:oam:us-east-1:222222222222:link/def"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	sess := session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Endpoint:    aws.String(server.URL),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+	}))
+
+	var labels []string
+	err := New(sess).ListAttachedLinksPages(&ListAttachedLinksInput{SinkIdentifier: aws.String("sink-arn")}, func(page *ListAttachedLinksOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			labels = append(labels, aws.StringValue(item.Label))
+		}
+		return !lastPage
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"dev", "prod"}, labels)
+	require.Len(t, requests, 2)
+	assert.Equal(t, "sink-arn", requests[0]["SinkIdentifier"])
+	assert.Equal(t, "next", requests[1]["NextToken"])
+}
+
+func TestAccountID(t *testing.T) {
+	id, err := AccountID("arn:aws:oam:us-east-1:111111111111:link/abc")
+	require.NoError(t, err)
+	assert.Equal(t, "111111111111", id)
+
+	_, err = AccountID("not-an-arn")
+	assert.Error(t, err)
+}
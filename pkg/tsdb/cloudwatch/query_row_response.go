@@ -10,6 +10,8 @@ type queryRowResponse struct {
 	ArithmeticErrorMessage string
 	Metrics                []*cloudwatch.MetricDataResult
 	StatusCode             string
+	// AnomalyDetectionBand is the upper and lower bounds of the anomaly detection band of the metric
+	AnomalyDetectionBand []*cloudwatch.MetricDataResult
 }
 
 func newQueryRowResponse() queryRowResponse {
@@ -24,6 +24,13 @@ func (e *cloudWatchExecutor) newResourceMux() *http.ServeMux {
 	mux.HandleFunc("/dimension-values", routes.ResourceRequestMiddleware(routes.DimensionValuesHandler, e.getRequestContext))
 	mux.HandleFunc("/dimension-keys", routes.ResourceRequestMiddleware(routes.DimensionKeysHandler, e.getRequestContext))
 	mux.HandleFunc("/namespaces", routes.ResourceRequestMiddleware(routes.NamespacesHandler, e.getRequestContext))
+	mux.HandleFunc("/composite-alarms", routes.ResourceRequestMiddleware(routes.CompositeAlarmsHandler, e.getRequestContext))
+	mux.HandleFunc("/metric-filters", routes.ResourceRequestMiddleware(routes.MetricFiltersHandler, e.getRequestContext))
+	mux.HandleFunc("/accounts", routes.ResourceRequestMiddleware(routes.AccountsHandler, e.getRequestContext))
+	mux.HandleFunc("/metrics-insights-schema", routes.ResourceRequestMiddleware(routes.MetricsInsightsSchemaHandler, e.getRequestContext))
+	mux.HandleFunc("/usage-queries", routes.ResourceRequestMiddleware(routes.UsageQueriesHandler, e.getRequestContext))
+	mux.HandleFunc("/tagged-resources", routes.ResourceRequestMiddleware(routes.TaggedResourcesHandler, e.getRequestContext))
+	mux.HandleFunc("/list-metrics-cache", routes.ResourcePostRequestMiddleware(routes.ListMetricsCacheInvalidationHandler, e.getRequestContext))
 	return mux
 }
 
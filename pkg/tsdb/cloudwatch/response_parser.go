@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -14,12 +15,36 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
 )
 
+const (
+	// accountIdLabel is the label of the series of the queries of linked accounts holding their account
+	accountIdLabel = "AccountId"
+	// labelPropertySeparator separates the label of the series of an inferred search expression from their properties,
+	// see withLabelProperties
+	labelPropertySeparator = "|&|"
+
+	// the fields of the bounds of an anomaly detection band
+	anomalyDetectionBandUpperField = "Upper"
+	anomalyDetectionBandLowerField = "Lower"
+)
+
 func (e *cloudWatchExecutor) parseResponse(startTime time.Time, endTime time.Time, metricDataOutputs []*cloudwatch.GetMetricDataOutput,
-	queries []*models.CloudWatchQuery) ([]*responseWrapper, error) {
+	queries []*models.CloudWatchQuery, frameNaming models.FrameNamingMode) ([]*responseWrapper, error) {
 	aggregatedResponse := aggregateResponse(metricDataOutputs)
 	queriesById := map[string]*models.CloudWatchQuery{}
 	for _, query := range queries {
 		queriesById[query.Id] = query
+
+		// the band is returned in the response of its metric, which is missing when the metric is hidden
+		if band, ok := aggregatedResponse[query.AnomalyDetectionBandId()]; ok && query.AnomalyDetectionBand != nil {
+			response, ok := aggregatedResponse[query.Id]
+			if !ok {
+				response = newQueryRowResponse()
+				response.StatusCode = band.StatusCode
+			}
+			response.AnomalyDetectionBand = band.Metrics
+			aggregatedResponse[query.Id] = response
+			delete(aggregatedResponse, query.AnomalyDetectionBandId())
+		}
 	}
 
 	results := []*responseWrapper{}
@@ -36,6 +61,7 @@ func (e *cloudWatchExecutor) parseResponse(startTime time.Time, endTime time.Tim
 		if err != nil {
 			return nil, err
 		}
+		applyFrameNaming(dataRes.Frames, frameNaming)
 
 		results = append(results, &responseWrapper{
 			DataResponse: &dataRes,
@@ -46,6 +72,35 @@ func (e *cloudWatchExecutor) parseResponse(startTime time.Time, endTime time.Tim
 	return results, nil
 }
 
+// applyFrameNaming strips the series naming information that is not wanted for the given mode.
+// Frames are built with the frame name, value field labels and displayNameFromDS all set.
+func applyFrameNaming(frames data.Frames, mode models.FrameNamingMode) {
+	if mode == models.FrameNamingDefault {
+		return
+	}
+
+	keepName := mode == models.FrameNamingFrameName || mode == models.FrameNamingLegacy
+	keepLabels := mode == models.FrameNamingLabels || mode == models.FrameNamingLegacy
+	keepDisplayName := mode == models.FrameNamingDisplayName
+
+	for _, frame := range frames {
+		if !keepName {
+			frame.Name = ""
+		}
+		for _, field := range frame.Fields {
+			if field.Name != data.TimeSeriesValueFieldName {
+				continue
+			}
+			if !keepLabels {
+				field.Labels = nil
+			}
+			if !keepDisplayName && field.Config != nil {
+				field.Config.DisplayNameFromDS = ""
+			}
+		}
+	}
+}
+
 func aggregateResponse(getMetricDataOutputs []*cloudwatch.GetMetricDataOutput) map[string]queryRowResponse {
 	responseByID := make(map[string]queryRowResponse)
 	errorCodes := map[string]bool{
@@ -112,11 +167,31 @@ func getLabels(cloudwatchLabel string, query *models.CloudWatchQuery) data.Label
 	return labels
 }
 
+// splitLabelProperties returns the label of a series without the properties suffixed by withLabelProperties, and the
+// labels of the series holding the properties
+func splitLabelProperties(label string, properties []labelProperty) (string, data.Labels) {
+	labels := data.Labels{}
+	parts := strings.Split(label, labelPropertySeparator)
+	if len(properties) == 0 || len(parts) <= len(properties) {
+		return label, labels
+	}
+
+	values := parts[len(parts)-len(properties):]
+	for i, property := range properties {
+		labels[property.labelKey] = values[i]
+	}
+	return strings.Join(parts[:len(parts)-len(properties)], labelPropertySeparator), labels
+}
+
 func buildDataFrames(startTime time.Time, endTime time.Time, aggregatedResponse queryRowResponse,
 	query *models.CloudWatchQuery, dynamicLabelEnabled bool) (data.Frames, error) {
 	frames := data.Frames{}
+	var properties []labelProperty
+	if query.GetGMDAPIMode() == models.GMDApiModeInferredSearchExpression {
+		properties = searchLabelProperties(query, dynamicLabelEnabled)
+	}
 	for _, metric := range aggregatedResponse.Metrics {
-		label := *metric.Label
+		label, propertyLabels := splitLabelProperties(*metric.Label, properties)
 
 		deepLink, err := query.BuildDeepLink(startTime, endTime, dynamicLabelEnabled)
 		if err != nil {
@@ -137,6 +212,9 @@ func buildDataFrames(startTime time.Time, endTime time.Time, aggregatedResponse
 
 			for _, value := range query.Dimensions[multiValuedDimension] {
 				labels := map[string]string{multiValuedDimension: value}
+				if query.IsSingleAccountQuery() {
+					labels[accountIdLabel] = query.AccountId
+				}
 				for key, values := range query.Dimensions {
 					if key != multiValuedDimension && len(values) > 0 {
 						labels[key] = values[0]
@@ -167,6 +245,12 @@ func buildDataFrames(startTime time.Time, endTime time.Time, aggregatedResponse
 		}
 
 		labels := getLabels(label, query)
+		for key, value := range propertyLabels {
+			labels[key] = value
+		}
+		if query.IsSingleAccountQuery() {
+			labels[accountIdLabel] = query.AccountId
+		}
 		timestamps := []*time.Time{}
 		points := []*float64{}
 		for j, t := range metric.Timestamps {
@@ -219,9 +303,79 @@ func buildDataFrames(startTime time.Time, endTime time.Time, aggregatedResponse
 		frames = append(frames, &frame)
 	}
 
+	if bandFrame := buildAnomalyDetectionBandFrame(aggregatedResponse.AnomalyDetectionBand, query, dynamicLabelEnabled); bandFrame != nil {
+		frames = append(frames, bandFrame)
+	}
+
 	return frames, nil
 }
 
+// buildAnomalyDetectionBandFrame returns the frame of the anomaly detection band of a query, with its upper and lower
+// bounds in dedicated fields, or nil when the band wasn't returned
+func buildAnomalyDetectionBandFrame(band []*cloudwatch.MetricDataResult, query *models.CloudWatchQuery, dynamicLabelEnabled bool) *data.Frame {
+	if len(band) != 2 {
+		return nil
+	}
+	upper, lower := band[0], band[1]
+	if isLowerBound(upper, lower) {
+		upper, lower = lower, upper
+	}
+
+	label := aws.StringValue(upper.Label)
+	labels := getLabels(label, query)
+	if query.IsSingleAccountQuery() {
+		labels[accountIdLabel] = query.AccountId
+	}
+	frameName := label
+	if !dynamicLabelEnabled {
+		frameName = formatAlias(query, query.Statistic, labels, label)
+	}
+
+	// the bounds are aligned on the timestamps of the upper one, as they are computed at the same times
+	lowerValues := make(map[time.Time]*float64, len(lower.Timestamps))
+	for i, t := range lower.Timestamps {
+		lowerValues[*t] = lower.Values[i]
+	}
+	timestamps := make([]*time.Time, 0, len(upper.Timestamps))
+	upperPoints := make([]*float64, 0, len(upper.Timestamps))
+	lowerPoints := make([]*float64, 0, len(upper.Timestamps))
+	for i, t := range upper.Timestamps {
+		timestamps = append(timestamps, t)
+		upperPoints = append(upperPoints, upper.Values[i])
+		lowerPoints = append(lowerPoints, lowerValues[*t])
+	}
+
+	upperField := data.NewField(anomalyDetectionBandUpperField, labels, upperPoints)
+	upperField.SetConfig(&data.FieldConfig{DisplayNameFromDS: frameName + " (upper)"})
+	lowerField := data.NewField(anomalyDetectionBandLowerField, labels.Copy(), lowerPoints)
+	lowerField.SetConfig(&data.FieldConfig{DisplayNameFromDS: frameName + " (lower)"})
+
+	return &data.Frame{
+		Name: frameName,
+		Fields: []*data.Field{
+			data.NewField(data.TimeSeriesTimeFieldName, nil, timestamps),
+			upperField,
+			lowerField,
+		},
+		RefID: query.RefId,
+		Meta:  createMeta(query),
+	}
+}
+
+// isLowerBound returns whether a bound of an anomaly detection band is below the other one, as CloudWatch doesn't tell
+// them apart
+func isLowerBound(bound, other *cloudwatch.MetricDataResult) bool {
+	for i, value := range bound.Values {
+		if i >= len(other.Values) || value == nil || other.Values[i] == nil {
+			continue
+		}
+		if *value != *other.Values[i] {
+			return *value < *other.Values[i]
+		}
+	}
+	return false
+}
+
 func formatAlias(query *models.CloudWatchQuery, stat string, dimensions map[string]string, label string) string {
 	region := query.Region
 	namespace := query.Namespace
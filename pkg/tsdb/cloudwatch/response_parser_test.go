@@ -10,6 +10,8 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -492,4 +494,224 @@ func TestCloudWatchResponseParser(t *testing.T) {
 		require.Len(t, frames, 1)
 		assert.Equal(t, "some response label", frames[0].Name)
 	})
+	t.Run("applyFrameNaming should only keep the naming information of the configured mode", func(t *testing.T) {
+		response := &queryRowResponse{
+			Metrics: []*cloudwatch.MetricDataResult{
+				{
+					Label:      aws.String("lb"),
+					Timestamps: []*time.Time{aws.Time(time.Unix(0, 0))},
+					Values:     []*float64{aws.Float64(10)},
+					StatusCode: aws.String("Complete"),
+				},
+			},
+		}
+		query := &models.CloudWatchQuery{
+			RefId:      "refId1",
+			Region:     "us-east-1",
+			Namespace:  "AWS/ApplicationELB",
+			MetricName: "TargetResponseTime",
+			Dimensions: map[string][]string{
+				"LoadBalancer": {"lb"},
+			},
+			Statistic:        "Average",
+			Period:           60,
+			Alias:            "{{LoadBalancer}}",
+			MetricQueryType:  models.MetricQueryTypeSearch,
+			MetricEditorMode: models.MetricEditorModeBuilder,
+		}
+
+		testCases := map[models.FrameNamingMode]struct {
+			name        string
+			labels      bool
+			displayName string
+		}{
+			models.FrameNamingDefault:     {name: "lb", labels: true, displayName: "lb"},
+			models.FrameNamingFrameName:   {name: "lb"},
+			models.FrameNamingLabels:      {labels: true},
+			models.FrameNamingDisplayName: {displayName: "lb"},
+			models.FrameNamingLegacy:      {name: "lb", labels: true},
+		}
+		for mode, expected := range testCases {
+			frames, err := buildDataFrames(startTime, endTime, *response, query, false)
+			require.NoError(t, err)
+			applyFrameNaming(frames, mode)
+
+			require.Len(t, frames, 1)
+			assert.Equal(t, expected.name, frames[0].Name, "mode %q", mode)
+			assert.Equal(t, "Time", frames[0].Fields[0].Name)
+			if expected.labels {
+				assert.Equal(t, "lb", frames[0].Fields[1].Labels["LoadBalancer"], "mode %q", mode)
+			} else {
+				assert.Empty(t, frames[0].Fields[1].Labels, "mode %q", mode)
+			}
+			assert.Equal(t, expected.displayName, frames[0].Fields[1].Config.DisplayNameFromDS, "mode %q", mode)
+		}
+	})
+	t.Run("buildDataFrames should label the series of a linked account with its account", func(t *testing.T) {
+		response := &queryRowResponse{
+			Metrics: []*cloudwatch.MetricDataResult{
+				{
+					Label:      aws.String("lb"),
+					Timestamps: []*time.Time{aws.Time(time.Unix(0, 0))},
+					Values:     []*float64{aws.Float64(10)},
+					StatusCode: aws.String("Complete"),
+				},
+			},
+		}
+		query := &models.CloudWatchQuery{
+			Namespace:  "AWS/ApplicationELB",
+			MetricName: "TargetResponseTime",
+			Dimensions: map[string][]string{
+				"LoadBalancer": {"lb"},
+			},
+			AccountId: "123456789012",
+		}
+
+		frames, err := buildDataFrames(startTime, endTime, *response, query, true)
+
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		assert.Equal(t, "lb", frames[0].Name)
+		assert.Equal(t, data.Labels{"LoadBalancer": "lb", "AccountId": "123456789012"}, frames[0].Fields[1].Labels)
+	})
+	t.Run("buildDataFrames should label the series of all the linked accounts with the account of their label", func(t *testing.T) {
+		response := &queryRowResponse{
+			Metrics: []*cloudwatch.MetricDataResult{
+				{
+					Label:      aws.String("lb|&|111111111111"),
+					Timestamps: []*time.Time{aws.Time(time.Unix(0, 0))},
+					Values:     []*float64{aws.Float64(10)},
+					StatusCode: aws.String("Complete"),
+				},
+				{
+					Label:      aws.String("lb|&|222222222222"),
+					Timestamps: []*time.Time{aws.Time(time.Unix(0, 0))},
+					Values:     []*float64{aws.Float64(20)},
+					StatusCode: aws.String("Complete"),
+				},
+			},
+		}
+		query := &models.CloudWatchQuery{
+			Namespace:  "AWS/ApplicationELB",
+			MetricName: "TargetResponseTime",
+			Dimensions: map[string][]string{
+				"LoadBalancer": {"*"},
+			},
+			AccountId:        models.AllLinkedAccounts,
+			MetricQueryType:  models.MetricQueryTypeSearch,
+			MetricEditorMode: models.MetricEditorModeBuilder,
+		}
+
+		frames, err := buildDataFrames(startTime, endTime, *response, query, false)
+
+		require.NoError(t, err)
+		require.Len(t, frames, 2)
+		assert.Equal(t, "lb", frames[0].Name)
+		assert.Equal(t, data.Labels{"LoadBalancer": "lb", "AccountId": "111111111111"}, frames[0].Fields[1].Labels)
+		assert.Equal(t, "lb", frames[1].Name)
+		assert.Equal(t, data.Labels{"LoadBalancer": "lb", "AccountId": "222222222222"}, frames[1].Fields[1].Labels)
+	})
+	t.Run("buildDataFrames should label the series of an inferred search with the dimensions suffixed to their dynamic label", func(t *testing.T) {
+		response := &queryRowResponse{
+			Metrics: []*cloudwatch.MetricDataResult{
+				{
+					Label:      aws.String("slow lb-1 in eu-west-1a|&|eu-west-1a|&|lb-1|&|111111111111"),
+					Timestamps: []*time.Time{aws.Time(time.Unix(0, 0))},
+					Values:     []*float64{aws.Float64(10)},
+					StatusCode: aws.String("Complete"),
+				},
+			},
+		}
+		query := &models.CloudWatchQuery{
+			Namespace:  "AWS/ApplicationELB",
+			MetricName: "TargetResponseTime",
+			Dimensions: map[string][]string{
+				"LoadBalancer":     {"*"},
+				"AvailabilityZone": {"eu-west-1a", "eu-west-1b"},
+				"TargetGroup":      {"tg"},
+			},
+			Label:            "slow ${PROP('Dim.LoadBalancer')} in ${PROP('Dim.AvailabilityZone')}",
+			AccountId:        models.AllLinkedAccounts,
+			MetricQueryType:  models.MetricQueryTypeSearch,
+			MetricEditorMode: models.MetricEditorModeBuilder,
+		}
+
+		frames, err := buildDataFrames(startTime, endTime, *response, query, true)
+
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		assert.Equal(t, "slow lb-1 in eu-west-1a", frames[0].Name)
+		assert.Equal(t, "slow lb-1 in eu-west-1a", frames[0].Fields[1].Config.DisplayNameFromDS)
+		assert.Equal(t, data.Labels{
+			"LoadBalancer":     "lb-1",
+			"AvailabilityZone": "eu-west-1a",
+			"TargetGroup":      "tg",
+			"AccountId":        "111111111111",
+		}, frames[0].Fields[1].Labels)
+	})
+	t.Run("buildDataFrames should name the series of a math expression with their dynamic label", func(t *testing.T) {
+		response := &queryRowResponse{
+			Metrics: []*cloudwatch.MetricDataResult{
+				{
+					Label:      aws.String("total|&|of lb"),
+					Timestamps: []*time.Time{aws.Time(time.Unix(0, 0))},
+					Values:     []*float64{aws.Float64(10)},
+					StatusCode: aws.String("Complete"),
+				},
+			},
+		}
+		query := &models.CloudWatchQuery{
+			Expression:       "SUM([m1, m2])",
+			Label:            "total|&|of ${LABEL}",
+			MetricQueryType:  models.MetricQueryTypeSearch,
+			MetricEditorMode: models.MetricEditorModeRaw,
+		}
+
+		frames, err := buildDataFrames(startTime, endTime, *response, query, true)
+
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		assert.Equal(t, "total|&|of lb", frames[0].Name)
+		assert.Empty(t, frames[0].Fields[1].Labels)
+	})
+	t.Run("parseResponse should map the bounds of an anomaly detection band to dedicated fields", func(t *testing.T) {
+		executor := newExecutor(nil, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures())
+		timestamps := []*time.Time{aws.Time(time.Unix(0, 0)), aws.Time(time.Unix(300, 0))}
+		outputs := []*cloudwatch.GetMetricDataOutput{
+			{
+				MetricDataResults: []*cloudwatch.MetricDataResult{
+					{Id: aws.String("m1_band"), Label: aws.String("lb"), Timestamps: timestamps, Values: []*float64{aws.Float64(5), aws.Float64(6)}, StatusCode: aws.String("Complete")},
+					{Id: aws.String("m1_band"), Label: aws.String("lb"), Timestamps: timestamps, Values: []*float64{aws.Float64(15), aws.Float64(16)}, StatusCode: aws.String("Complete")},
+				},
+			},
+		}
+		query := &models.CloudWatchQuery{
+			RefId:      "A",
+			Id:         "m1",
+			Namespace:  "AWS/ApplicationELB",
+			MetricName: "TargetResponseTime",
+			Dimensions: map[string][]string{
+				"LoadBalancer": {"lb"},
+			},
+			Statistic:            "Average",
+			Period:               300,
+			MatchExact:           true,
+			AnomalyDetectionBand: &models.AnomalyDetectionBand{StandardDeviations: 2},
+		}
+
+		res, err := executor.parseResponse(startTime, endTime, outputs, []*models.CloudWatchQuery{query}, models.FrameNamingDefault)
+
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		assert.Equal(t, "A", res[0].RefId)
+		frames := res[0].DataResponse.Frames
+		require.Len(t, frames, 1)
+		require.Len(t, frames[0].Fields, 3)
+		upper, lower := frames[0].Fields[1], frames[0].Fields[2]
+		assert.Equal(t, "Upper", upper.Name)
+		assert.Equal(t, []*float64{aws.Float64(15), aws.Float64(16)}, []*float64{upper.At(0).(*float64), upper.At(1).(*float64)})
+		assert.Equal(t, "Lower", lower.Name)
+		assert.Equal(t, []*float64{aws.Float64(5), aws.Float64(6)}, []*float64{lower.At(0).(*float64), lower.At(1).(*float64)})
+		assert.Equal(t, data.Labels{"LoadBalancer": "lb"}, upper.Labels)
+	})
 }
@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models/request"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/services"
+)
+
+func AccountsHandler(pluginCtx backend.PluginContext, reqCtxFactory models.RequestContextFactoryFunc, parameters url.Values) ([]byte, *models.HttpError) {
+	accountsRequest, err := request.GetAccountsRequest(parameters)
+	if err != nil {
+		return nil, models.NewHttpError("error in AccountsHandler", http.StatusBadRequest, err)
+	}
+
+	reqCtx, err := reqCtxFactory(pluginCtx, accountsRequest.Region)
+	if err != nil {
+		return nil, models.NewHttpError("error in AccountsHandler", http.StatusInternalServerError, err)
+	}
+
+	accounts, err := services.GetAccounts(reqCtx.OAMAPIProvider)
+	if err != nil {
+		return nil, models.NewHttpError("error in AccountsHandler", http.StatusInternalServerError, err)
+	}
+
+	accountsResponse, err := json.Marshal(accounts)
+	if err != nil {
+		return nil, models.NewHttpError("error in AccountsHandler", http.StatusInternalServerError, err)
+	}
+
+	return accountsResponse, nil
+}
@@ -0,0 +1,48 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/mocks"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/oam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Accounts_Route(t *testing.T) {
+	fakeOAMAPI := &mocks.FakeOAMAPI{}
+	fakeOAMAPI.On("ListSinksPages", mock.Anything).Return([]*oam.ListSinksOutput{
+		{Items: []*oam.ListSinksItem{{Arn: aws.String("arn:aws:oam:us-east-1:123456789012:sink/id")}}},
+	}, nil)
+	fakeOAMAPI.On("ListAttachedLinksPages", mock.Anything).Return([]*oam.ListAttachedLinksOutput{
+		{Items: []*oam.ListAttachedLinksItem{{Label: aws.String("dev"), LinkArn: aws.String("arn:aws:oam:us-east-1:111111111111:link/id")}}},
+	}, nil)
+	factoryFunc := func(pluginCtx backend.PluginContext, region string) (reqCtx models.RequestContext, err error) {
+		return models.RequestContext{OAMAPIProvider: fakeOAMAPI}, nil
+	}
+
+	t.Run("returns the monitoring and linked accounts", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/accounts?region=us-east-1", nil)
+		handler := http.HandlerFunc(ResourceRequestMiddleware(AccountsHandler, factoryFunc))
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `[
+			{"id":"123456789012","arn":"arn:aws:oam:us-east-1:123456789012:sink/id","label":"123456789012","isMonitoringAccount":true},
+			{"id":"111111111111","arn":"arn:aws:oam:us-east-1:111111111111:link/id","label":"dev","isMonitoringAccount":false}
+		]`, rr.Body.String())
+	})
+
+	t.Run("returns 400 if region is missing", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/accounts", nil)
+		handler := http.HandlerFunc(ResourceRequestMiddleware(AccountsHandler, factoryFunc))
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
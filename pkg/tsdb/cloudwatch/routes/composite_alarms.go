@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models/request"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/services"
+)
+
+func CompositeAlarmsHandler(pluginCtx backend.PluginContext, reqCtxFactory models.RequestContextFactoryFunc, parameters url.Values) ([]byte, *models.HttpError) {
+	compositeAlarmsRequest, err := request.GetCompositeAlarmsRequest(parameters)
+	if err != nil {
+		return nil, models.NewHttpError("error in CompositeAlarmsHandler", http.StatusBadRequest, err)
+	}
+
+	reqCtx, err := reqCtxFactory(pluginCtx, compositeAlarmsRequest.Region)
+	if err != nil {
+		return nil, models.NewHttpError("error in CompositeAlarmsHandler", http.StatusInternalServerError, err)
+	}
+
+	alarms, err := services.GetCompositeAlarms(reqCtx.AlarmsAPIProvider, compositeAlarmsRequest)
+	if err != nil {
+		return nil, models.NewHttpError("error in CompositeAlarmsHandler", http.StatusInternalServerError, err)
+	}
+
+	compositeAlarmsResponse, err := json.Marshal(alarms)
+	if err != nil {
+		return nil, models.NewHttpError("error in CompositeAlarmsHandler", http.StatusInternalServerError, err)
+	}
+
+	return compositeAlarmsResponse, nil
+}
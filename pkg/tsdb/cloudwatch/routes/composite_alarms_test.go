@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/mocks"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_CompositeAlarms_Route(t *testing.T) {
+	fakeAlarmsAPI := &mocks.FakeAlarmsAPI{}
+	fakeAlarmsAPI.On("DescribeAlarmsPages", mock.Anything).Return([]*cloudwatch.DescribeAlarmsOutput{
+		{CompositeAlarms: []*cloudwatch.CompositeAlarm{{AlarmName: aws.String("alarm-1")}}},
+	}, nil)
+	factoryFunc := func(pluginCtx backend.PluginContext, region string) (reqCtx models.RequestContext, err error) {
+		return models.RequestContext{AlarmsAPIProvider: fakeAlarmsAPI}, nil
+	}
+
+	t.Run("returns the composite alarms", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/composite-alarms?region=us-east-1", nil)
+		handler := http.HandlerFunc(ResourceRequestMiddleware(CompositeAlarmsHandler, factoryFunc))
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `[{"name":"alarm-1","arn":"","rule":"","stateValue":""}]`, rr.Body.String())
+	})
+
+	t.Run("returns 400 if region is missing", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/composite-alarms", nil)
+		handler := http.HandlerFunc(ResourceRequestMiddleware(CompositeAlarmsHandler, factoryFunc))
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models/request"
+)
+
+// ListMetricsCacheInvalidationHandler invalidates the metrics the data source listed and cached in the region of the
+// request, for its namespace or all the namespaces, so that the following requests list the metrics again
+func ListMetricsCacheInvalidationHandler(pluginCtx backend.PluginContext, reqCtxFactory models.RequestContextFactoryFunc, parameters url.Values) ([]byte, *models.HttpError) {
+	cacheRequest, err := request.GetListMetricsCacheRequest(parameters)
+	if err != nil {
+		return nil, models.NewHttpError("error in ListMetricsCacheInvalidationHandler", http.StatusBadRequest, err)
+	}
+
+	reqCtx, err := reqCtxFactory(pluginCtx, cacheRequest.Region)
+	if err != nil {
+		return nil, models.NewHttpError("error in ListMetricsCacheInvalidationHandler", http.StatusInternalServerError, err)
+	}
+
+	invalidated := reqCtx.ListMetricsCacheProvider.InvalidateListMetrics(cacheRequest.Namespace)
+
+	response, err := json.Marshal(map[string]int{"invalidated": invalidated})
+	if err != nil {
+		return nil, models.NewHttpError("error in ListMetricsCacheInvalidationHandler", http.StatusInternalServerError, err)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,57 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeListMetricsCache struct {
+	invalidatedNamespaces []string
+}
+
+func (c *fakeListMetricsCache) InvalidateListMetrics(namespace string) int {
+	c.invalidatedNamespaces = append(c.invalidatedNamespaces, namespace)
+	return 2
+}
+
+func Test_ListMetricsCache_Route(t *testing.T) {
+	t.Run("invalidates the metrics of the namespace", func(t *testing.T) {
+		cache := &fakeListMetricsCache{}
+		var region string
+		factoryFunc := func(pluginCtx backend.PluginContext, r string) (reqCtx models.RequestContext, err error) {
+			region = r
+			return models.RequestContext{ListMetricsCacheProvider: cache}, nil
+		}
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/list-metrics-cache?region=us-east-1&namespace=Custom", nil)
+		handler := http.HandlerFunc(ResourcePostRequestMiddleware(ListMetricsCacheInvalidationHandler, factoryFunc))
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"invalidated":2}`, rr.Body.String())
+		assert.Equal(t, "us-east-1", region)
+		assert.Equal(t, []string{"Custom"}, cache.invalidatedNamespaces)
+	})
+
+	t.Run("rejects GET method", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/list-metrics-cache?region=us-east-1", nil)
+		handler := http.HandlerFunc(ResourcePostRequestMiddleware(ListMetricsCacheInvalidationHandler, nil))
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	})
+
+	t.Run("returns 400 if region is missing", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/list-metrics-cache", nil)
+		handler := http.HandlerFunc(ResourcePostRequestMiddleware(ListMetricsCacheInvalidationHandler, nil))
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
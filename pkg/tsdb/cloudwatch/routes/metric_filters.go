@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models/request"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/services"
+)
+
+func MetricFiltersHandler(pluginCtx backend.PluginContext, reqCtxFactory models.RequestContextFactoryFunc, parameters url.Values) ([]byte, *models.HttpError) {
+	metricFiltersRequest, err := request.GetMetricFiltersRequest(parameters)
+	if err != nil {
+		return nil, models.NewHttpError("error in MetricFiltersHandler", http.StatusBadRequest, err)
+	}
+
+	reqCtx, err := reqCtxFactory(pluginCtx, metricFiltersRequest.Region)
+	if err != nil {
+		return nil, models.NewHttpError("error in MetricFiltersHandler", http.StatusInternalServerError, err)
+	}
+
+	filters, err := services.GetMetricFilters(reqCtx.LogsAPIProvider, metricFiltersRequest)
+	if err != nil {
+		return nil, models.NewHttpError("error in MetricFiltersHandler", http.StatusInternalServerError, err)
+	}
+
+	metricFiltersResponse, err := json.Marshal(filters)
+	if err != nil {
+		return nil, models.NewHttpError("error in MetricFiltersHandler", http.StatusInternalServerError, err)
+	}
+
+	return metricFiltersResponse, nil
+}
@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/mocks"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_MetricFilters_Route(t *testing.T) {
+	t.Run("returns 500 if DescribeMetricFiltersPages returns an error", func(t *testing.T) {
+		fakeLogsAPI := &mocks.FakeLogsAPI{}
+		fakeLogsAPI.On("DescribeMetricFiltersPages", mock.Anything).Return([]*cloudwatchlogs.DescribeMetricFiltersOutput{}, fmt.Errorf("some error"))
+		factoryFunc := func(pluginCtx backend.PluginContext, region string) (reqCtx models.RequestContext, err error) {
+			return models.RequestContext{LogsAPIProvider: fakeLogsAPI}, nil
+		}
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/metric-filters?region=us-east-1&logGroupName=my-group", nil)
+		handler := http.HandlerFunc(ResourceRequestMiddleware(MetricFiltersHandler, factoryFunc))
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, `{"Message":"error in MetricFiltersHandler: unable to call AWS API: some error","Error":"unable to call AWS API: some error","StatusCode":500}`, rr.Body.String())
+	})
+
+	t.Run("returns 400 if a filter name prefix is given without log group", func(t *testing.T) {
+		fakeLogsAPI := &mocks.FakeLogsAPI{}
+		factoryFunc := func(pluginCtx backend.PluginContext, region string) (reqCtx models.RequestContext, err error) {
+			return models.RequestContext{LogsAPIProvider: fakeLogsAPI}, nil
+		}
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/metric-filters?region=us-east-1&filterNamePrefix=err", nil)
+		handler := http.HandlerFunc(ResourceRequestMiddleware(MetricFiltersHandler, factoryFunc))
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		fakeLogsAPI.AssertNotCalled(t, "DescribeMetricFiltersPages", mock.Anything)
+	})
+}
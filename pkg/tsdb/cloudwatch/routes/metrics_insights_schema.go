@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models/request"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/services"
+)
+
+func MetricsInsightsSchemaHandler(pluginCtx backend.PluginContext, reqCtxFactory models.RequestContextFactoryFunc, parameters url.Values) ([]byte, *models.HttpError) {
+	schemaRequest, err := request.GetMetricsInsightsSchemaRequest(parameters)
+	if err != nil {
+		return nil, models.NewHttpError("error in MetricsInsightsSchemaHandler", http.StatusBadRequest, err)
+	}
+
+	reqCtx, err := reqCtxFactory(pluginCtx, schemaRequest.Region)
+	if err != nil {
+		return nil, models.NewHttpError("error in MetricsInsightsSchemaHandler", http.StatusInternalServerError, err)
+	}
+
+	listMetricsService := services.NewListMetricsService(reqCtx.MetricsClientProvider)
+	schema, err := services.GetMetricsInsightsSchema(listMetricsService, reqCtx.Settings.Namespace, schemaRequest)
+	if err != nil {
+		return nil, models.NewHttpError("error in MetricsInsightsSchemaHandler", http.StatusInternalServerError, err)
+	}
+
+	schemaResponse, err := json.Marshal(schema)
+	if err != nil {
+		return nil, models.NewHttpError("error in MetricsInsightsSchemaHandler", http.StatusInternalServerError, err)
+	}
+
+	return schemaResponse, nil
+}
@@ -0,0 +1,51 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/mocks"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MetricsInsightsSchema_Route(t *testing.T) {
+	factoryFunc := func(pluginCtx backend.PluginContext, region string) (reqCtx models.RequestContext, err error) {
+		return models.RequestContext{
+			MetricsClientProvider: &mocks.FakeMetricsClient{},
+			Settings:              &models.CloudWatchSettings{Namespace: "Custom"},
+		}, nil
+	}
+
+	t.Run("returns the validation errors and the schema of the query", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", `/metrics-insights-schema?region=us-east-1&sqlExpression=SELECT+MEDIAN(CPUUtilization)+FROM+"AWS/EC2"&namespace=AWS/EC2`, nil)
+		handler := http.HandlerFunc(ResourceRequestMiddleware(MetricsInsightsSchemaHandler, factoryFunc))
+		handler.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		schema := models.MetricsInsightsSchema{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &schema))
+		assert.False(t, schema.Valid)
+		assert.Equal(t, []models.SQLExpressionError{{
+			Message: `expected one of the functions AVG, COUNT, MAX, MIN, SUM but found "MEDIAN"`,
+			Line:    1,
+			Column:  8,
+		}}, schema.Errors)
+		assert.Equal(t, "AWS/EC2", schema.Namespace)
+		assert.Contains(t, schema.Dimensions, "InstanceId")
+		assert.Contains(t, schema.Namespaces, "Custom")
+	})
+
+	t.Run("returns 400 if region is missing", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/metrics-insights-schema", nil)
+		handler := http.HandlerFunc(ResourceRequestMiddleware(MetricsInsightsSchemaHandler, factoryFunc))
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -9,8 +9,18 @@ import (
 )
 
 func ResourceRequestMiddleware(handleFunc models.RouteHandlerFunc, reqCtxFactory models.RequestContextFactoryFunc) func(rw http.ResponseWriter, req *http.Request) {
+	return resourceRequestMiddleware("GET", handleFunc, reqCtxFactory)
+}
+
+// ResourcePostRequestMiddleware is the middleware of the resource requests changing the state of the data source,
+// which take their parameters from the query string like the others
+func ResourcePostRequestMiddleware(handleFunc models.RouteHandlerFunc, reqCtxFactory models.RequestContextFactoryFunc) func(rw http.ResponseWriter, req *http.Request) {
+	return resourceRequestMiddleware("POST", handleFunc, reqCtxFactory)
+}
+
+func resourceRequestMiddleware(method string, handleFunc models.RouteHandlerFunc, reqCtxFactory models.RequestContextFactoryFunc) func(rw http.ResponseWriter, req *http.Request) {
 	return func(rw http.ResponseWriter, req *http.Request) {
-		if req.Method != "GET" {
+		if req.Method != method {
 			respondWithError(rw, models.NewHttpError("Invalid method", http.StatusMethodNotAllowed, nil))
 			return
 		}
@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models/request"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/services"
+)
+
+func TaggedResourcesHandler(pluginCtx backend.PluginContext, reqCtxFactory models.RequestContextFactoryFunc, parameters url.Values) ([]byte, *models.HttpError) {
+	taggedResourcesRequest, err := request.GetTaggedResourcesRequest(parameters)
+	if err != nil {
+		return nil, models.NewHttpError("error in TaggedResourcesHandler", http.StatusBadRequest, err)
+	}
+
+	reqCtx, err := reqCtxFactory(pluginCtx, taggedResourcesRequest.Region)
+	if err != nil {
+		return nil, models.NewHttpError("error in TaggedResourcesHandler", http.StatusInternalServerError, err)
+	}
+
+	resources, err := services.GetTaggedResources(reqCtx.ResourceGroupsTaggingAPIProvider, taggedResourcesRequest)
+	if err != nil {
+		return nil, models.NewHttpError("error in TaggedResourcesHandler", http.StatusInternalServerError, err)
+	}
+
+	taggedResourcesResponse, err := json.Marshal(resources)
+	if err != nil {
+		return nil, models.NewHttpError("error in TaggedResourcesHandler", http.StatusInternalServerError, err)
+	}
+
+	return taggedResourcesResponse, nil
+}
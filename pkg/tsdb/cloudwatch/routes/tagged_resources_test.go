@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/mocks"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_TaggedResources_Route(t *testing.T) {
+	fakeTaggingAPI := &mocks.FakeResourceGroupsTaggingAPI{}
+	fakeTaggingAPI.On("GetResourcesPages", mock.Anything).Return([]*resourcegroupstaggingapi.GetResourcesOutput{
+		{ResourceTagMappingList: []*resourcegroupstaggingapi.ResourceTagMapping{{
+			ResourceARN: aws.String("arn:aws:ec2:us-east-1:123456789012:instance/i-123"),
+			Tags:        []*resourcegroupstaggingapi.Tag{{Key: aws.String("env"), Value: aws.String("prod")}},
+		}}},
+	}, nil)
+	factoryFunc := func(pluginCtx backend.PluginContext, region string) (reqCtx models.RequestContext, err error) {
+		return models.RequestContext{ResourceGroupsTaggingAPIProvider: fakeTaggingAPI}, nil
+	}
+
+	t.Run("returns the tagged resources", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", `/tagged-resources?region=us-east-1&resourceType=ec2:instance&tags={"env":["prod"]}`, nil)
+		handler := http.HandlerFunc(ResourceRequestMiddleware(TaggedResourcesHandler, factoryFunc))
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `[{"arn":"arn:aws:ec2:us-east-1:123456789012:instance/i-123","resourceType":"ec2:instance","resourceId":"i-123","tags":{"env":"prod"}}]`, rr.Body.String())
+	})
+
+	t.Run("returns 400 if region is missing", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/tagged-resources", nil)
+		handler := http.HandlerFunc(ResourceRequestMiddleware(TaggedResourcesHandler, factoryFunc))
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
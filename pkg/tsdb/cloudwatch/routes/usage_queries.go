@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models/request"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/services"
+)
+
+func UsageQueriesHandler(pluginCtx backend.PluginContext, reqCtxFactory models.RequestContextFactoryFunc, parameters url.Values) ([]byte, *models.HttpError) {
+	usageQueriesRequest, err := request.GetUsageQueriesRequest(parameters)
+	if err != nil {
+		return nil, models.NewHttpError("error in UsageQueriesHandler", http.StatusBadRequest, err)
+	}
+
+	region := usageQueriesRequest.Region
+	if region == "default" {
+		reqCtx, err := reqCtxFactory(pluginCtx, region)
+		if err != nil {
+			return nil, models.NewHttpError("error in UsageQueriesHandler", http.StatusInternalServerError, err)
+		}
+		region = reqCtx.Settings.Region
+	}
+
+	usageQueriesResponse, err := json.Marshal(services.GetUsageQueries(region))
+	if err != nil {
+		return nil, models.NewHttpError("error in UsageQueriesHandler", http.StatusInternalServerError, err)
+	}
+
+	return usageQueriesResponse, nil
+}
@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-aws-sdk/pkg/awsds"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_UsageQueries_Route(t *testing.T) {
+	factoryFunc := func(pluginCtx backend.PluginContext, region string) (reqCtx models.RequestContext, err error) {
+		return models.RequestContext{
+			Settings: &models.CloudWatchSettings{AWSDatasourceSettings: awsds.AWSDatasourceSettings{Region: "eu-west-1"}},
+		}, nil
+	}
+
+	t.Run("resolves the default region from the datasource settings", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/usage-queries?region=default", nil)
+		handler := http.HandlerFunc(ResourceRequestMiddleware(UsageQueriesHandler, factoryFunc))
+		handler.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var queries []models.UsageQuery
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &queries))
+		require.NotEmpty(t, queries)
+		for _, q := range queries {
+			assert.Equal(t, "eu-west-1", q.Region)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/oam"
+)
+
+// GetAccounts lists the accounts whose metrics can be queried in the region of the request: the monitoring account,
+// followed by the source accounts linked to its sinks. It's empty when the account of the data source isn't a
+// monitoring account.
+func GetAccounts(api models.OAMAPIProvider) ([]models.Account, error) {
+	sinks := []*oam.ListSinksItem{}
+	err := api.ListSinksPages(&oam.ListSinksInput{}, func(page *oam.ListSinksOutput, lastPage bool) bool {
+		sinks = append(sinks, page.Items...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "unable to list the sinks", err)
+	}
+
+	accounts := []models.Account{}
+	if len(sinks) == 0 {
+		return accounts, nil
+	}
+
+	monitoringAccountId, err := oam.AccountID(aws.StringValue(sinks[0].Arn))
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "unable to parse the sink ARN", err)
+	}
+	accounts = append(accounts, models.Account{
+		Id:                  monitoringAccountId,
+		Arn:                 aws.StringValue(sinks[0].Arn),
+		Label:               monitoringAccountId,
+		IsMonitoringAccount: true,
+	})
+
+	seen := map[string]bool{monitoringAccountId: true}
+	for _, sink := range sinks {
+		input := &oam.ListAttachedLinksInput{SinkIdentifier: sink.Arn}
+		var linkErr error
+		err := api.ListAttachedLinksPages(input, func(page *oam.ListAttachedLinksOutput, lastPage bool) bool {
+			for _, link := range page.Items {
+				accountId, err := oam.AccountID(aws.StringValue(link.LinkArn))
+				if err != nil {
+					linkErr = fmt.Errorf("%v: %w", "unable to parse the link ARN", err)
+					return false
+				}
+				if seen[accountId] {
+					continue
+				}
+				seen[accountId] = true
+				accounts = append(accounts, models.Account{
+					Id:    accountId,
+					Arn:   aws.StringValue(link.LinkArn),
+					Label: aws.StringValue(link.Label),
+				})
+			}
+			return !lastPage
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", "unable to list the attached links", err)
+		}
+		if linkErr != nil {
+			return nil, linkErr
+		}
+	}
+
+	return accounts, nil
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/mocks"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/oam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const sinkArn = "arn:aws:oam:us-east-1:123456789012:sink/sink-id"
+
+func TestGetAccounts(t *testing.T) {
+	t.Run("Should return the monitoring account and the linked accounts of all pages", func(t *testing.T) {
+		fakeOAMAPI := &mocks.FakeOAMAPI{}
+		fakeOAMAPI.On("ListSinksPages", mock.Anything).Return([]*oam.ListSinksOutput{
+			{Items: []*oam.ListSinksItem{{Arn: aws.String(sinkArn)}}},
+		}, nil)
+		fakeOAMAPI.On("ListAttachedLinksPages", mock.Anything).Return([]*oam.ListAttachedLinksOutput{
+			{Items: []*oam.ListAttachedLinksItem{{Label: aws.String("dev"), LinkArn: aws.String("arn:aws:oam:us-east-1:111111111111:link/a")}}},
+			{Items: []*oam.ListAttachedLinksItem{{Label: aws.String("prod"), LinkArn: aws.String("arn:aws:oam:us-east-1:222222222222:link/b")}}},
+		}, nil)
+
+		accounts, err := GetAccounts(fakeOAMAPI)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.Account{
+			{Id: "123456789012", Arn: sinkArn, Label: "123456789012", IsMonitoringAccount: true},
+			{Id: "111111111111", Arn: "arn:aws:oam:us-east-1:111111111111:link/a", Label: "dev"},
+			{Id: "222222222222", Arn: "arn:aws:oam:us-east-1:222222222222:link/b", Label: "prod"},
+		}, accounts)
+		input := fakeOAMAPI.Calls[1].Arguments.Get(0).(*oam.ListAttachedLinksInput)
+		assert.Equal(t, sinkArn, *input.SinkIdentifier)
+	})
+
+	t.Run("Should return no accounts when the account isn't a monitoring account", func(t *testing.T) {
+		fakeOAMAPI := &mocks.FakeOAMAPI{}
+		fakeOAMAPI.On("ListSinksPages", mock.Anything).Return([]*oam.ListSinksOutput{{}}, nil)
+
+		accounts, err := GetAccounts(fakeOAMAPI)
+
+		require.NoError(t, err)
+		assert.Empty(t, accounts)
+		fakeOAMAPI.AssertNotCalled(t, "ListAttachedLinksPages", mock.Anything)
+	})
+
+	t.Run("Should return an error when the sinks can't be listed", func(t *testing.T) {
+		fakeOAMAPI := &mocks.FakeOAMAPI{}
+		fakeOAMAPI.On("ListSinksPages", mock.Anything).Return([]*oam.ListSinksOutput{}, fmt.Errorf("access denied"))
+
+		_, err := GetAccounts(fakeOAMAPI)
+
+		assert.ErrorContains(t, err, "access denied")
+	})
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models/request"
+)
+
+// GetCompositeAlarms lists all composite alarms in the region of the request, optionally filtered by name prefix
+func GetCompositeAlarms(api models.CloudWatchAlarmsAPIProvider, r *request.CompositeAlarmsRequest) ([]models.CompositeAlarm, error) {
+	input := &cloudwatch.DescribeAlarmsInput{
+		AlarmTypes: aws.StringSlice([]string{cloudwatch.AlarmTypeCompositeAlarm}),
+	}
+	if r.AlarmNamePrefix != "" {
+		input.AlarmNamePrefix = aws.String(r.AlarmNamePrefix)
+	}
+
+	alarms := []models.CompositeAlarm{}
+	err := api.DescribeAlarmsPages(input, func(page *cloudwatch.DescribeAlarmsOutput, lastPage bool) bool {
+		for _, alarm := range page.CompositeAlarms {
+			alarms = append(alarms, models.CompositeAlarm{
+				Name:       aws.StringValue(alarm.AlarmName),
+				Arn:        aws.StringValue(alarm.AlarmArn),
+				Rule:       aws.StringValue(alarm.AlarmRule),
+				StateValue: aws.StringValue(alarm.StateValue),
+			})
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "unable to call AWS API", err)
+	}
+
+	return alarms, nil
+}
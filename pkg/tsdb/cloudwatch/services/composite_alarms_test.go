@@ -0,0 +1,53 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/mocks"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCompositeAlarms(t *testing.T) {
+	t.Run("Should only request composite alarms and collect all pages", func(t *testing.T) {
+		fakeAlarmsAPI := &mocks.FakeAlarmsAPI{}
+		fakeAlarmsAPI.On("DescribeAlarmsPages", mock.Anything).Return([]*cloudwatch.DescribeAlarmsOutput{
+			{CompositeAlarms: []*cloudwatch.CompositeAlarm{
+				{AlarmName: aws.String("alarm-1"), AlarmArn: aws.String("arn-1"), AlarmRule: aws.String("ALARM(a)"), StateValue: aws.String("OK")},
+			}},
+			{CompositeAlarms: []*cloudwatch.CompositeAlarm{
+				{AlarmName: aws.String("alarm-2"), AlarmArn: aws.String("arn-2"), AlarmRule: aws.String("ALARM(b)"), StateValue: aws.String("ALARM")},
+			}},
+		}, nil)
+
+		resp, err := GetCompositeAlarms(fakeAlarmsAPI, &request.CompositeAlarmsRequest{
+			ResourceRequest: &request.ResourceRequest{Region: "us-east-1"},
+			AlarmNamePrefix: "alarm",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.CompositeAlarm{
+			{Name: "alarm-1", Arn: "arn-1", Rule: "ALARM(a)", StateValue: "OK"},
+			{Name: "alarm-2", Arn: "arn-2", Rule: "ALARM(b)", StateValue: "ALARM"},
+		}, resp)
+
+		input := fakeAlarmsAPI.Calls[0].Arguments.Get(0).(*cloudwatch.DescribeAlarmsInput)
+		assert.Equal(t, []*string{aws.String(cloudwatch.AlarmTypeCompositeAlarm)}, input.AlarmTypes)
+		assert.Equal(t, "alarm", *input.AlarmNamePrefix)
+	})
+
+	t.Run("Should return an error if the AWS API call fails", func(t *testing.T) {
+		fakeAlarmsAPI := &mocks.FakeAlarmsAPI{}
+		fakeAlarmsAPI.On("DescribeAlarmsPages", mock.Anything).Return([]*cloudwatch.DescribeAlarmsOutput{}, fmt.Errorf("some error"))
+
+		_, err := GetCompositeAlarms(fakeAlarmsAPI, &request.CompositeAlarmsRequest{ResourceRequest: &request.ResourceRequest{Region: "us-east-1"}})
+
+		assert.EqualError(t, err, "unable to call AWS API: some error")
+	})
+}
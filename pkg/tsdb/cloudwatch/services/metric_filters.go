@@ -0,0 +1,49 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models/request"
+)
+
+// GetMetricFilters lists the metric filters in the region of the request, together with the log group and
+// filter pattern they are defined on. The list can be narrowed down by log group, and by filter name prefix within a
+// log group.
+func GetMetricFilters(api models.CloudWatchLogsAPIProvider, r *request.MetricFiltersRequest) ([]models.MetricFilter, error) {
+	input := &cloudwatchlogs.DescribeMetricFiltersInput{}
+	if r.LogGroupName != "" {
+		input.LogGroupName = aws.String(r.LogGroupName)
+	}
+	if r.FilterNamePrefix != "" {
+		input.FilterNamePrefix = aws.String(r.FilterNamePrefix)
+	}
+
+	filters := []models.MetricFilter{}
+	err := api.DescribeMetricFiltersPages(input, func(page *cloudwatchlogs.DescribeMetricFiltersOutput, lastPage bool) bool {
+		for _, filter := range page.MetricFilters {
+			metrics := []models.MetricFilterMetric{}
+			for _, transformation := range filter.MetricTransformations {
+				metrics = append(metrics, models.MetricFilterMetric{
+					Name:      aws.StringValue(transformation.MetricName),
+					Namespace: aws.StringValue(transformation.MetricNamespace),
+				})
+			}
+
+			filters = append(filters, models.MetricFilter{
+				Name:         aws.StringValue(filter.FilterName),
+				LogGroupName: aws.StringValue(filter.LogGroupName),
+				Pattern:      aws.StringValue(filter.FilterPattern),
+				Metrics:      metrics,
+			})
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "unable to call AWS API", err)
+	}
+
+	return filters, nil
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/mocks"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMetricFilters(t *testing.T) {
+	t.Run("Should return filters with their log group, pattern and metrics", func(t *testing.T) {
+		fakeLogsAPI := &mocks.FakeLogsAPI{}
+		fakeLogsAPI.On("DescribeMetricFiltersPages", mock.Anything).Return([]*cloudwatchlogs.DescribeMetricFiltersOutput{
+			{MetricFilters: []*cloudwatchlogs.MetricFilter{
+				{
+					FilterName:    aws.String("errors"),
+					LogGroupName:  aws.String("/aws/lambda/fn"),
+					FilterPattern: aws.String("ERROR"),
+					MetricTransformations: []*cloudwatchlogs.MetricTransformation{
+						{MetricName: aws.String("ErrorCount"), MetricNamespace: aws.String("Custom/Lambda")},
+					},
+				},
+			}},
+		}, nil)
+
+		resp, err := GetMetricFilters(fakeLogsAPI, &request.MetricFiltersRequest{
+			ResourceRequest: &request.ResourceRequest{Region: "us-east-1"},
+			LogGroupName:    "/aws/lambda/fn",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.MetricFilter{{
+			Name:         "errors",
+			LogGroupName: "/aws/lambda/fn",
+			Pattern:      "ERROR",
+			Metrics:      []models.MetricFilterMetric{{Name: "ErrorCount", Namespace: "Custom/Lambda"}},
+		}}, resp)
+
+		input := fakeLogsAPI.Calls[0].Arguments.Get(0).(*cloudwatchlogs.DescribeMetricFiltersInput)
+		assert.Equal(t, "/aws/lambda/fn", *input.LogGroupName)
+		assert.Nil(t, input.FilterNamePrefix)
+	})
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/constants"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models/request"
+)
+
+// GetMetricsInsightsSchema validates the Metrics Insights query of the request, and returns the namespaces, the
+// statistics and the dimension keys of the namespace of the query the query editor completes it with. The dimension
+// keys of the custom namespaces are listed from their metrics.
+func GetMetricsInsightsSchema(listMetricsProvider models.ListMetricsProvider, customNamespaces string, r *request.MetricsInsightsSchemaRequest) (*models.MetricsInsightsSchema, error) {
+	namespace, errors := ValidateSQLExpression(r.SqlExpression)
+	if namespace == "" {
+		namespace = r.Namespace
+	}
+
+	namespaces := GetHardCodedNamespaces()
+	if customNamespaces != "" {
+		namespaces = append(namespaces, strings.Split(customNamespaces, ",")...)
+	}
+	sort.Strings(namespaces)
+
+	schema := &models.MetricsInsightsSchema{
+		Valid:      len(errors) == 0,
+		Errors:     errors,
+		Namespaces: namespaces,
+		Statistics: MetricsInsightsStatistics,
+		Namespace:  namespace,
+		Dimensions: []string{},
+	}
+
+	// the namespaces of template variables are only known by the frontend
+	if namespace == "" || strings.HasPrefix(namespace, "$") {
+		return schema, nil
+	}
+
+	if _, ok := constants.NamespaceDimensionKeysMap[namespace]; ok {
+		dimensions, err := GetHardCodedDimensionKeysByNamespace(namespace)
+		if err != nil {
+			return nil, err
+		}
+		schema.Dimensions = dimensions
+		return schema, nil
+	}
+
+	dimensions, err := listMetricsProvider.GetDimensionKeysByNamespace(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "unable to list the dimension keys", err)
+	}
+	if dimensions != nil {
+		schema.Dimensions = dimensions
+	}
+	return schema, nil
+}
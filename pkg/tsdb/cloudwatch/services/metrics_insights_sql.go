@@ -0,0 +1,369 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+)
+
+// MetricsInsightsStatistics are the functions aggregating the metrics of a Metrics Insights query
+var MetricsInsightsStatistics = []string{"AVG", "COUNT", "MAX", "MIN", "SUM"}
+
+var metricsInsightsKeywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "GROUP": true, "ORDER": true, "BY": true, "DESC": true, "ASC": true,
+	"LIMIT": true, "WITH": true, "SCHEMA": true, "AND": true,
+}
+
+type sqlTokenKind int
+
+const (
+	sqlTokenEOF sqlTokenKind = iota
+	sqlTokenWord
+	sqlTokenQuotedIdentifier
+	sqlTokenString
+	sqlTokenNumber
+	sqlTokenVariable
+	sqlTokenSymbol
+)
+
+type sqlToken struct {
+	kind   sqlTokenKind
+	text   string
+	line   int
+	column int
+}
+
+func (t sqlToken) String() string {
+	if t.kind == sqlTokenEOF {
+		return "the end of the query"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// isKeyword returns whether the token is a keyword, which is case insensitive
+func (t sqlToken) isKeyword(keyword string) bool {
+	return t.kind == sqlTokenWord && strings.EqualFold(t.text, keyword)
+}
+
+type sqlSyntaxError struct {
+	token   sqlToken
+	message string
+}
+
+// tokenizeSQL splits a Metrics Insights query in tokens, skipping the whitespaces and the comments
+func tokenizeSQL(sql string) ([]sqlToken, *sqlSyntaxError) {
+	tokens := []sqlToken{}
+	runes := []rune(sql)
+	line, column := 1, 1
+	advance := func(n int) {
+		for i := 0; i < n; i++ {
+			if runes[i] == '\n' {
+				line++
+				column = 1
+			} else {
+				column++
+			}
+		}
+		runes = runes[n:]
+	}
+
+	for len(runes) > 0 {
+		r := runes[0]
+		start := sqlToken{line: line, column: column}
+		switch {
+		case unicode.IsSpace(r):
+			advance(1)
+			continue
+		case r == '-' && len(runes) > 1 && runes[1] == '-':
+			end := 0
+			for end < len(runes) && runes[end] != '\n' {
+				end++
+			}
+			advance(end)
+			continue
+		case r == '\'' || r == '"':
+			end := 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, &sqlSyntaxError{token: start, message: fmt.Sprintf("unterminated %c", r)}
+			}
+			start.kind = sqlTokenString
+			if r == '"' {
+				start.kind = sqlTokenQuotedIdentifier
+			}
+			start.text = string(runes[1:end])
+			advance(end + 1)
+		case r == '$':
+			end := 1
+			if end < len(runes) && runes[end] == '{' {
+				for end < len(runes) && runes[end] != '}' {
+					end++
+				}
+				end++
+			} else {
+				for end < len(runes) && isSQLWordRune(runes[end]) {
+					end++
+				}
+			}
+			if end > len(runes) || end == 1 {
+				return nil, &sqlSyntaxError{token: start, message: "invalid template variable"}
+			}
+			start.kind = sqlTokenVariable
+			start.text = string(runes[:end])
+			advance(end)
+		case unicode.IsDigit(r):
+			end := 0
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			start.kind = sqlTokenNumber
+			start.text = string(runes[:end])
+			advance(end)
+		case isSQLWordRune(r):
+			end := 0
+			for end < len(runes) && isSQLWordRune(runes[end]) {
+				end++
+			}
+			start.kind = sqlTokenWord
+			start.text = string(runes[:end])
+			advance(end)
+		case r == '!' && len(runes) > 1 && runes[1] == '=':
+			start.kind = sqlTokenSymbol
+			start.text = "!="
+			advance(2)
+		case strings.ContainsRune("(),=", r):
+			start.kind = sqlTokenSymbol
+			start.text = string(r)
+			advance(1)
+		default:
+			return nil, &sqlSyntaxError{token: start, message: fmt.Sprintf("unexpected character %q", r)}
+		}
+		tokens = append(tokens, start)
+	}
+
+	return append(tokens, sqlToken{kind: sqlTokenEOF, line: line, column: column}), nil
+}
+
+func isSQLWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// sqlParser checks the syntax of a Metrics Insights query, which is:
+//
+//	SELECT FUNCTION(metricName)
+//	FROM namespace | SCHEMA(namespace[, labelKey [, ...] ])
+//	[ WHERE labelKey OPERATOR labelValue [AND ... ] ]
+//	[ GROUP BY labelKey [ , ... ] ]
+//	[ ORDER BY FUNCTION() [ DESC | ASC ] ]
+//	[ LIMIT number ]
+type sqlParser struct {
+	tokens    []sqlToken
+	pos       int
+	namespace string
+}
+
+func (p *sqlParser) peek() sqlToken {
+	return p.tokens[p.pos]
+}
+
+func (p *sqlParser) next() sqlToken {
+	t := p.tokens[p.pos]
+	if t.kind != sqlTokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *sqlParser) errorf(t sqlToken, format string, args ...interface{}) *sqlSyntaxError {
+	return &sqlSyntaxError{token: t, message: fmt.Sprintf(format, args...)}
+}
+
+func (p *sqlParser) expectKeyword(keyword string) *sqlSyntaxError {
+	if t := p.next(); !t.isKeyword(keyword) {
+		return p.errorf(t, "expected %s but found %s", keyword, t)
+	}
+	return nil
+}
+
+func (p *sqlParser) expectSymbol(symbol string) *sqlSyntaxError {
+	if t := p.next(); t.kind != sqlTokenSymbol || t.text != symbol {
+		return p.errorf(t, "expected %q but found %s", symbol, t)
+	}
+	return nil
+}
+
+// name parses a metric name, a namespace or a label key, which are quoted when they aren't plain words
+func (p *sqlParser) name(what string) (string, *sqlSyntaxError) {
+	t := p.next()
+	switch {
+	case t.kind == sqlTokenQuotedIdentifier, t.kind == sqlTokenVariable:
+		return t.text, nil
+	case t.kind == sqlTokenWord && !metricsInsightsKeywords[strings.ToUpper(t.text)]:
+		return t.text, nil
+	}
+	return "", p.errorf(t, "expected %s but found %s", what, t)
+}
+
+func (p *sqlParser) function() *sqlSyntaxError {
+	t := p.next()
+	if t.kind == sqlTokenVariable {
+		return nil
+	}
+	if t.kind == sqlTokenWord {
+		for _, statistic := range MetricsInsightsStatistics {
+			if strings.EqualFold(t.text, statistic) {
+				return nil
+			}
+		}
+	}
+	return p.errorf(t, "expected one of the functions %s but found %s", strings.Join(MetricsInsightsStatistics, ", "), t)
+}
+
+func (p *sqlParser) parse() *sqlSyntaxError {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return err
+	}
+	if err := p.function(); err != nil {
+		return err
+	}
+	if err := p.expectSymbol("("); err != nil {
+		return err
+	}
+	if _, err := p.name("a metric name"); err != nil {
+		return err
+	}
+	if err := p.expectSymbol(")"); err != nil {
+		return err
+	}
+
+	if err := p.expectKeyword("FROM"); err != nil {
+		return err
+	}
+	if err := p.from(); err != nil {
+		return err
+	}
+
+	if p.peek().isKeyword("WHERE") {
+		p.next()
+		if err := p.where(); err != nil {
+			return err
+		}
+	}
+
+	if p.peek().isKeyword("GROUP") {
+		p.next()
+		if err := p.expectKeyword("BY"); err != nil {
+			return err
+		}
+		if err := p.labelKeys(); err != nil {
+			return err
+		}
+	}
+
+	if p.peek().isKeyword("ORDER") {
+		p.next()
+		if err := p.expectKeyword("BY"); err != nil {
+			return err
+		}
+		if err := p.function(); err != nil {
+			return err
+		}
+		if err := p.expectSymbol("("); err != nil {
+			return err
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return err
+		}
+		if t := p.peek(); t.isKeyword("ASC") || t.isKeyword("DESC") {
+			p.next()
+		}
+	}
+
+	if p.peek().isKeyword("LIMIT") {
+		p.next()
+		if t := p.next(); t.kind != sqlTokenNumber && t.kind != sqlTokenVariable {
+			return p.errorf(t, "expected a number but found %s", t)
+		}
+	}
+
+	if t := p.next(); t.kind != sqlTokenEOF {
+		return p.errorf(t, "unexpected %s", t)
+	}
+	return nil
+}
+
+func (p *sqlParser) from() *sqlSyntaxError {
+	if !p.peek().isKeyword("SCHEMA") {
+		namespace, err := p.name("a namespace")
+		p.namespace = namespace
+		return err
+	}
+
+	p.next()
+	if err := p.expectSymbol("("); err != nil {
+		return err
+	}
+	namespace, err := p.name("a namespace")
+	if err != nil {
+		return err
+	}
+	p.namespace = namespace
+	for p.peek().kind == sqlTokenSymbol && p.peek().text == "," {
+		p.next()
+		if _, err := p.name("a label key"); err != nil {
+			return err
+		}
+	}
+	return p.expectSymbol(")")
+}
+
+func (p *sqlParser) where() *sqlSyntaxError {
+	for {
+		if _, err := p.name("a label key"); err != nil {
+			return err
+		}
+		if t := p.next(); t.kind != sqlTokenSymbol || (t.text != "=" && t.text != "!=") {
+			return p.errorf(t, "expected %q or %q but found %s", "=", "!=", t)
+		}
+		if t := p.next(); t.kind != sqlTokenString && t.kind != sqlTokenVariable {
+			return p.errorf(t, "expected a quoted label value but found %s", t)
+		}
+		if !p.peek().isKeyword("AND") {
+			return nil
+		}
+		p.next()
+	}
+}
+
+func (p *sqlParser) labelKeys() *sqlSyntaxError {
+	for {
+		if _, err := p.name("a label key"); err != nil {
+			return err
+		}
+		if t := p.peek(); t.kind != sqlTokenSymbol || t.text != "," {
+			return nil
+		}
+		p.next()
+	}
+}
+
+// ValidateSQLExpression checks the syntax of a Metrics Insights query, and returns the namespace it queries, which is
+// empty when the syntax is invalid, and the syntax errors
+func ValidateSQLExpression(sql string) (string, []models.SQLExpressionError) {
+	tokens, err := tokenizeSQL(sql)
+	if err == nil {
+		p := &sqlParser{tokens: tokens}
+		if err = p.parse(); err == nil {
+			return p.namespace, []models.SQLExpressionError{}
+		}
+	}
+	return "", []models.SQLExpressionError{{
+		Message: err.message,
+		Line:    err.token.line,
+		Column:  err.token.column,
+	}}
+}
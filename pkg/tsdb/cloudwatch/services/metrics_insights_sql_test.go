@@ -0,0 +1,45 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSQLExpression(t *testing.T) {
+	t.Run("Should accept valid queries and return their namespace", func(t *testing.T) {
+		testCases := map[string]string{
+			`SELECT AVG(CPUUtilization) FROM "AWS/EC2"`:                                                                                            "AWS/EC2",
+			`select avg(CPUUtilization) from "AWS/EC2"`:                                                                                            "AWS/EC2",
+			`SELECT MAX(CPUUtilization) FROM SCHEMA("AWS/EC2", InstanceId)`:                                                                        "AWS/EC2",
+			`SELECT SUM(Invocations) FROM SCHEMA("AWS/Lambda") WHERE FunctionName = 'a' AND Resource != 'b'`:                                       "AWS/Lambda",
+			"SELECT COUNT(\"Requests\") FROM Custom\n-- per load balancer\nGROUP BY LoadBalancer, AvailabilityZone ORDER BY COUNT() DESC LIMIT 10": "Custom",
+			`SELECT $stat(CPUUtilization) FROM $namespace WHERE InstanceId = $instance LIMIT $limit`:                                               "$namespace",
+		}
+		for sql, namespace := range testCases {
+			actualNamespace, errors := ValidateSQLExpression(sql)
+			assert.Empty(t, errors, sql)
+			assert.Equal(t, namespace, actualNamespace, sql)
+		}
+	})
+
+	t.Run("Should return the position of the syntax error of invalid queries", func(t *testing.T) {
+		testCases := map[string]models.SQLExpressionError{
+			``: {Message: "expected SELECT but found the end of the query", Line: 1, Column: 1},
+			`SELECT MEDIAN(CPUUtilization) FROM "AWS/EC2"`:                         {Message: "expected one of the functions AVG, COUNT, MAX, MIN, SUM but found \"MEDIAN\"", Line: 1, Column: 8},
+			`SELECT AVG(CPUUtilization) FROM AWS/EC2`:                              {Message: "unexpected character '/'", Line: 1, Column: 36},
+			"SELECT AVG(CPUUtilization)\nFROM \"AWS/EC2\"\nWHERE InstanceId > 'a'": {Message: "unexpected character '>'", Line: 3, Column: 18},
+			`SELECT AVG(CPUUtilization) FROM "AWS/EC2" WHERE InstanceId = 123`:     {Message: `expected a quoted label value but found "123"`, Line: 1, Column: 62},
+			`SELECT AVG(CPUUtilization) FROM "AWS/EC2" GROUP InstanceId`:           {Message: `expected BY but found "InstanceId"`, Line: 1, Column: 49},
+			`SELECT AVG(CPUUtilization) FROM "AWS/EC2" LIMIT 10 ORDER BY AVG()`:    {Message: `unexpected "ORDER"`, Line: 1, Column: 52},
+			`SELECT AVG(CPUUtilization) FROM "AWS/EC2`:                             {Message: `unterminated "`, Line: 1, Column: 33},
+			`SELECT AVG(CPUUtilization) FROM SCHEMA("AWS/EC2", InstanceId`:         {Message: `expected ")" but found the end of the query`, Line: 1, Column: 61},
+		}
+		for sql, expected := range testCases {
+			namespace, errors := ValidateSQLExpression(sql)
+			assert.Equal(t, []models.SQLExpressionError{expected}, errors, sql)
+			assert.Empty(t, namespace, sql)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/mocks"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMetricsInsightsSchema(t *testing.T) {
+	t.Run("Should return the hardcoded dimension keys of the namespace of the query", func(t *testing.T) {
+		listMetricsProvider := &mocks.ListMetricsServiceMock{}
+
+		schema, err := GetMetricsInsightsSchema(listMetricsProvider, "", &request.MetricsInsightsSchemaRequest{
+			SqlExpression: `SELECT AVG(CPUUtilization) FROM SCHEMA("AWS/EC2", InstanceId)`,
+		})
+		require.NoError(t, err)
+
+		assert.True(t, schema.Valid)
+		assert.Empty(t, schema.Errors)
+		assert.Equal(t, "AWS/EC2", schema.Namespace)
+		assert.Equal(t, []string{"AutoScalingGroupName", "ImageId", "InstanceId", "InstanceType"}, schema.Dimensions)
+		assert.Equal(t, MetricsInsightsStatistics, schema.Statistics)
+		assert.Contains(t, schema.Namespaces, "AWS/EC2")
+		listMetricsProvider.AssertNotCalled(t, "GetDimensionKeysByNamespace")
+	})
+
+	t.Run("Should list the dimension keys of the custom namespace of the request when the query is invalid", func(t *testing.T) {
+		listMetricsProvider := &mocks.ListMetricsServiceMock{}
+		listMetricsProvider.On("GetDimensionKeysByNamespace").Return([]string{"Service"}, nil)
+
+		schema, err := GetMetricsInsightsSchema(listMetricsProvider, "MyApp,Other", &request.MetricsInsightsSchemaRequest{
+			SqlExpression: `SELECT AVG(Latency) FROM`,
+			Namespace:     "MyApp",
+		})
+		require.NoError(t, err)
+
+		assert.False(t, schema.Valid)
+		require.Len(t, schema.Errors, 1)
+		assert.Equal(t, 1, schema.Errors[0].Line)
+		assert.Equal(t, 25, schema.Errors[0].Column)
+		assert.Equal(t, "MyApp", schema.Namespace)
+		assert.Equal(t, []string{"Service"}, schema.Dimensions)
+		assert.Contains(t, schema.Namespaces, "MyApp")
+		assert.Contains(t, schema.Namespaces, "Other")
+	})
+
+	t.Run("Should not return dimension keys when the namespace is a template variable", func(t *testing.T) {
+		listMetricsProvider := &mocks.ListMetricsServiceMock{}
+
+		schema, err := GetMetricsInsightsSchema(listMetricsProvider, "", &request.MetricsInsightsSchemaRequest{
+			SqlExpression: `SELECT AVG(CPUUtilization) FROM $namespace`,
+		})
+		require.NoError(t, err)
+
+		assert.True(t, schema.Valid)
+		assert.Equal(t, "$namespace", schema.Namespace)
+		assert.Empty(t, schema.Dimensions)
+		listMetricsProvider.AssertNotCalled(t, "GetDimensionKeysByNamespace")
+	})
+}
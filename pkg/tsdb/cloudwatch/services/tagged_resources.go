@@ -0,0 +1,68 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models/request"
+)
+
+// GetTaggedResources lists the resources of the region of the request having the tags of the request, optionally
+// filtered by resource type
+func GetTaggedResources(api models.ResourceGroupsTaggingAPIProvider, r *request.TaggedResourcesRequest) ([]models.TaggedResource, error) {
+	input := &resourcegroupstaggingapi.GetResourcesInput{}
+	if len(r.ResourceTypes) > 0 {
+		input.ResourceTypeFilters = aws.StringSlice(r.ResourceTypes)
+	}
+	for _, tagFilter := range r.TagFilters {
+		filter := &resourcegroupstaggingapi.TagFilter{Key: aws.String(tagFilter.Key)}
+		if len(tagFilter.Values) > 0 {
+			filter.Values = aws.StringSlice(tagFilter.Values)
+		}
+		input.TagFilters = append(input.TagFilters, filter)
+	}
+
+	resources := []models.TaggedResource{}
+	err := api.GetResourcesPages(input, func(page *resourcegroupstaggingapi.GetResourcesOutput, lastPage bool) bool {
+		for _, mapping := range page.ResourceTagMappingList {
+			resourceArn := aws.StringValue(mapping.ResourceARN)
+			resourceType, resourceId := parseResourceArn(resourceArn)
+			tags := make(map[string]string, len(mapping.Tags))
+			for _, tag := range mapping.Tags {
+				tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+			}
+			resources = append(resources, models.TaggedResource{
+				Arn:          resourceArn,
+				ResourceType: resourceType,
+				ResourceId:   resourceId,
+				Tags:         tags,
+			})
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "unable to call AWS API", err)
+	}
+
+	return resources, nil
+}
+
+// parseResourceArn returns the type of a resource, in the service:type form of the resource type filters, and its
+// identifier. The resource part of an ARN is either the identifier alone, or a type followed by the identifier and
+// separated from it by the first / or :, so that the identifier of an application load balancer is app/name/id like
+// its LoadBalancer dimension.
+func parseResourceArn(resourceArn string) (string, string) {
+	parsed, err := arn.Parse(resourceArn)
+	if err != nil {
+		return "", resourceArn
+	}
+
+	if i := strings.IndexAny(parsed.Resource, "/:"); i >= 0 {
+		return parsed.Service + ":" + parsed.Resource[:i], parsed.Resource[i+1:]
+	}
+	return parsed.Service, parsed.Resource
+}
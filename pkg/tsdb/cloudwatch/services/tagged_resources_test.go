@@ -0,0 +1,90 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/mocks"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTaggedResources(t *testing.T) {
+	t.Run("Should filter the resources by tag and type and collect all pages", func(t *testing.T) {
+		fakeTaggingAPI := &mocks.FakeResourceGroupsTaggingAPI{}
+		fakeTaggingAPI.On("GetResourcesPages", mock.Anything).Return([]*resourcegroupstaggingapi.GetResourcesOutput{
+			{ResourceTagMappingList: []*resourcegroupstaggingapi.ResourceTagMapping{{
+				ResourceARN: aws.String("arn:aws:ec2:us-east-1:123456789012:instance/i-123"),
+				Tags:        []*resourcegroupstaggingapi.Tag{{Key: aws.String("env"), Value: aws.String("prod")}},
+			}}},
+			{ResourceTagMappingList: []*resourcegroupstaggingapi.ResourceTagMapping{{
+				ResourceARN: aws.String("arn:aws:lambda:us-east-1:123456789012:function:my-function"),
+				Tags:        []*resourcegroupstaggingapi.Tag{{Key: aws.String("env"), Value: aws.String("prod")}},
+			}}},
+		}, nil)
+
+		resp, err := GetTaggedResources(fakeTaggingAPI, &request.TaggedResourcesRequest{
+			ResourceRequest: &request.ResourceRequest{Region: "us-east-1"},
+			ResourceTypes:   []string{"ec2:instance", "lambda:function"},
+			TagFilters:      []*request.TagFilter{{Key: "env", Values: []string{"prod"}}, {Key: "team", Values: []string{}}},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.TaggedResource{
+			{
+				Arn:          "arn:aws:ec2:us-east-1:123456789012:instance/i-123",
+				ResourceType: "ec2:instance",
+				ResourceId:   "i-123",
+				Tags:         map[string]string{"env": "prod"},
+			},
+			{
+				Arn:          "arn:aws:lambda:us-east-1:123456789012:function:my-function",
+				ResourceType: "lambda:function",
+				ResourceId:   "my-function",
+				Tags:         map[string]string{"env": "prod"},
+			},
+		}, resp)
+
+		input := fakeTaggingAPI.Calls[0].Arguments.Get(0).(*resourcegroupstaggingapi.GetResourcesInput)
+		assert.Equal(t, aws.StringSlice([]string{"ec2:instance", "lambda:function"}), input.ResourceTypeFilters)
+		assert.Equal(t, []*resourcegroupstaggingapi.TagFilter{
+			{Key: aws.String("env"), Values: aws.StringSlice([]string{"prod"})},
+			{Key: aws.String("team")},
+		}, input.TagFilters)
+	})
+
+	t.Run("Should return an error if the AWS API call fails", func(t *testing.T) {
+		fakeTaggingAPI := &mocks.FakeResourceGroupsTaggingAPI{}
+		fakeTaggingAPI.On("GetResourcesPages", mock.Anything).Return([]*resourcegroupstaggingapi.GetResourcesOutput{}, fmt.Errorf("some error"))
+
+		_, err := GetTaggedResources(fakeTaggingAPI, &request.TaggedResourcesRequest{ResourceRequest: &request.ResourceRequest{Region: "us-east-1"}})
+
+		assert.EqualError(t, err, "unable to call AWS API: some error")
+	})
+}
+
+func TestParseResourceArn(t *testing.T) {
+	testCases := map[string]struct {
+		arn          string
+		resourceType string
+		resourceId   string
+	}{
+		"type and id separated by a slash": {"arn:aws:ec2:us-east-1:123456789012:instance/i-123", "ec2:instance", "i-123"},
+		"type and id separated by a colon": {"arn:aws:rds:us-east-1:123456789012:db:my-db", "rds:db", "my-db"},
+		"id with slashes":                  {"arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-lb/50dc6c495c0c9188", "elasticloadbalancing:loadbalancer", "app/my-lb/50dc6c495c0c9188"},
+		"id only":                          {"arn:aws:sqs:us-east-1:123456789012:my-queue", "sqs", "my-queue"},
+		"invalid arn":                      {"not-an-arn", "", "not-an-arn"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			resourceType, resourceId := parseResourceArn(tc.arn)
+			assert.Equal(t, tc.resourceType, resourceType)
+			assert.Equal(t, tc.resourceId, resourceId)
+		})
+	}
+}
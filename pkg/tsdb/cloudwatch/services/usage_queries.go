@@ -0,0 +1,75 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+)
+
+const usageNamespace = "AWS/Usage"
+
+// usageAPIs are the AWS APIs called by the datasource, grouped by the AWS/Usage Service dimension
+var usageAPIs = map[string][]string{
+	"CloudWatch": {"DescribeAlarmHistory", "DescribeAlarms", "GetMetricData", "ListMetrics"},
+	"Logs":       {"DescribeLogGroups", "GetLogGroupFields", "GetQueryResults", "StartQuery"},
+}
+
+var usageServices = []string{"CloudWatch", "Logs"}
+
+// GetUsageQueries returns prebuilt queries comparing the number of calls made to the APIs used by the datasource
+// with the applied service quota. For every API a hidden CallCount query and two math expressions are returned:
+// one for the calls per second and one for the quota, so that both can be plotted on the same panel.
+func GetUsageQueries(region string) []models.UsageQuery {
+	queries := []models.UsageQuery{}
+	for _, service := range usageServices {
+		for _, api := range usageAPIs[service] {
+			id := fmt.Sprintf("%s_%s", strings.ToLower(service), strings.ToLower(api))
+
+			queries = append(queries,
+				models.UsageQuery{
+					RefId:      id,
+					Id:         id,
+					Region:     region,
+					Namespace:  usageNamespace,
+					MetricName: "CallCount",
+					Dimensions: map[string][]string{
+						"Service":  {service},
+						"Type":     {"API"},
+						"Resource": {api},
+						"Class":    {"None"},
+					},
+					Statistic:        "Sum",
+					Period:           "60",
+					Label:            fmt.Sprintf("%s %s call count", service, api),
+					MetricQueryType:  models.MetricQueryTypeSearch,
+					MetricEditorMode: models.MetricEditorModeBuilder,
+					MatchExact:       true,
+					Hide:             true,
+				},
+				models.UsageQuery{
+					RefId:            id + "_rate",
+					Id:               id + "_rate",
+					Region:           region,
+					Period:           "60",
+					Expression:       fmt.Sprintf("%s/PERIOD(%s)", id, id),
+					Label:            fmt.Sprintf("%s %s calls per second", service, api),
+					MetricQueryType:  models.MetricQueryTypeSearch,
+					MetricEditorMode: models.MetricEditorModeRaw,
+				},
+				models.UsageQuery{
+					RefId:            id + "_quota",
+					Id:               id + "_quota",
+					Region:           region,
+					Period:           "60",
+					Expression:       fmt.Sprintf("SERVICE_QUOTA(%s)", id),
+					Label:            fmt.Sprintf("%s %s quota", service, api),
+					MetricQueryType:  models.MetricQueryTypeSearch,
+					MetricEditorMode: models.MetricEditorModeRaw,
+				},
+			)
+		}
+	}
+
+	return queries
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUsageQueries(t *testing.T) {
+	t.Run("Should return a call count, rate and quota query per API", func(t *testing.T) {
+		queries := GetUsageQueries("us-east-1")
+
+		require.Len(t, queries, 3*(len(usageAPIs["CloudWatch"])+len(usageAPIs["Logs"])))
+
+		callCount, rate, quota := queries[0], queries[1], queries[2]
+		assert.Equal(t, "cloudwatch_describealarmhistory", callCount.Id)
+		assert.Equal(t, "us-east-1", callCount.Region)
+		assert.Equal(t, "AWS/Usage", callCount.Namespace)
+		assert.Equal(t, "CallCount", callCount.MetricName)
+		assert.Equal(t, map[string][]string{
+			"Service":  {"CloudWatch"},
+			"Type":     {"API"},
+			"Resource": {"DescribeAlarmHistory"},
+			"Class":    {"None"},
+		}, callCount.Dimensions)
+		assert.True(t, callCount.Hide)
+
+		assert.Equal(t, "cloudwatch_describealarmhistory/PERIOD(cloudwatch_describealarmhistory)", rate.Expression)
+		assert.Equal(t, "SERVICE_QUOTA(cloudwatch_describealarmhistory)", quota.Expression)
+	})
+}
@@ -40,6 +40,11 @@ func (e *cloudWatchExecutor) executeTimeSeriesQuery(ctx context.Context, req *ba
 		return backend.NewQueryDataResponse(), nil
 	}
 
+	instance, err := e.getInstance(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+
 	requestQueriesByRegion := make(map[string][]*models.CloudWatchQuery)
 	for _, query := range requestQueries {
 		if _, exist := requestQueriesByRegion[query.Region]; !exist {
@@ -72,17 +77,12 @@ func (e *cloudWatchExecutor) executeTimeSeriesQuery(ctx context.Context, req *ba
 				return err
 			}
 
-			metricDataInput, err := e.buildMetricDataInput(startTime, endTime, requestQueries)
-			if err != nil {
-				return err
-			}
-
-			mdo, err := e.executeRequest(ectx, client, metricDataInput)
+			mdo, err := e.executeSplitRequests(ectx, client, startTime, endTime, requestQueries)
 			if err != nil {
 				return err
 			}
 
-			res, err := e.parseResponse(startTime, endTime, mdo, requestQueries)
+			res, err := e.parseResponse(startTime, endTime, mdo, requestQueries, instance.Settings.FrameNaming)
 			if err != nil {
 				return err
 			}
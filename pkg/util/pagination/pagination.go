@@ -0,0 +1,215 @@
+// Package pagination holds the types stores and API layers share to page through results, so clients handle
+// pagination the same way whatever they list.
+//
+// Requests select a page with a Page: a page number and size, or a Cursor returned with a previous page, and a
+// SortOrder. Responses describe the page they return with a Result.
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidSortOrder = errors.New("sort order must be asc or desc")
+	ErrInvalidCursor    = errors.New("invalid pagination cursor")
+)
+
+// SortOrder is the direction results are sorted in
+type SortOrder string
+
+const (
+	SortAscending  SortOrder = "asc"
+	SortDescending SortOrder = "desc"
+)
+
+// ParseSortOrder parses a sort order case insensitively. An empty order is valid and leaves the store to use its
+// default order
+func ParseSortOrder(order string) (SortOrder, error) {
+	switch SortOrder(strings.ToLower(strings.TrimSpace(order))) {
+	case "":
+		return "", nil
+	case SortAscending:
+		return SortAscending, nil
+	case SortDescending:
+		return SortDescending, nil
+	default:
+		return "", ErrInvalidSortOrder
+	}
+}
+
+// OrDefault returns the sort order, or the default one when no order was requested
+func (o SortOrder) OrDefault(defaultOrder SortOrder) SortOrder {
+	if o == "" {
+		return defaultOrder
+	}
+	return o
+}
+
+// SQL returns the sort order as a SQL keyword, ASC unless the order is descending
+func (o SortOrder) SQL() string {
+	if o == SortDescending {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+// Cursor is an opaque position in a list of results, returned to clients so they can request the following page.
+// Offset cursors hold the number of results before the page. Key cursors hold the sort key of the last result of the
+// previous page, so stores can seek to the page with keyset pagination instead of scanning the skipped results
+type Cursor string
+
+const (
+	cursorOffsetPrefix = "o:"
+	cursorKeyPrefix    = "k:"
+)
+
+// NewCursor returns the cursor of the result at the offset
+func NewCursor(offset int64) Cursor {
+	return Cursor(base64.RawURLEncoding.EncodeToString([]byte(cursorOffsetPrefix + strconv.FormatInt(offset, 10))))
+}
+
+// NewKeyCursor returns the cursor of the results sorted after the sort key
+func NewKeyCursor(key string) Cursor {
+	return Cursor(base64.RawURLEncoding.EncodeToString([]byte(cursorKeyPrefix + key)))
+}
+
+func (c Cursor) decode() (string, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(string(c))
+	if err != nil || !(strings.HasPrefix(string(decoded), cursorOffsetPrefix) || strings.HasPrefix(string(decoded), cursorKeyPrefix)) {
+		return "", ErrInvalidCursor
+	}
+	return string(decoded), nil
+}
+
+// Offset returns the offset of the result the cursor points to. Key cursors point to the first result after their
+// key, their offset is 0
+func (c Cursor) Offset() (int64, error) {
+	decoded, err := c.decode()
+	if err != nil {
+		return 0, err
+	}
+	if strings.HasPrefix(decoded, cursorKeyPrefix) {
+		return 0, nil
+	}
+
+	offset, err := strconv.ParseInt(strings.TrimPrefix(decoded, cursorOffsetPrefix), 10, 64)
+	if err != nil || offset < 0 {
+		return 0, ErrInvalidCursor
+	}
+	return offset, nil
+}
+
+// Key returns the sort key of the cursor, and false when it is not a key cursor
+func (c Cursor) Key() (string, bool) {
+	decoded, err := c.decode()
+	if err != nil || !strings.HasPrefix(decoded, cursorKeyPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(decoded, cursorKeyPrefix), true
+}
+
+// Page selects a page of results. Pages are numbered from 1, and a cursor takes precedence over the page number. A
+// page without a size selects all the results
+type Page struct {
+	Page    int64     `json:"page"`
+	PerPage int64     `json:"perPage"`
+	Cursor  Cursor    `json:"cursor,omitempty"`
+	Order   SortOrder `json:"order,omitempty"`
+}
+
+// NewPage builds a page from request parameters, validating the cursor and sort order
+func NewPage(page int64, perPage int64, cursor string, order string) (Page, error) {
+	p := Page{Page: page, PerPage: perPage, Cursor: Cursor(cursor)}
+
+	if p.Cursor != "" {
+		if _, err := p.Cursor.Offset(); err != nil {
+			return Page{}, err
+		}
+	}
+
+	var err error
+	if p.Order, err = ParseSortOrder(order); err != nil {
+		return Page{}, err
+	}
+
+	return p, nil
+}
+
+// WithDefaults returns the page with the first page selected when none is, and its size set to the default size
+// when not set and capped to the max size. A max size of 0 does not cap the size
+func (p Page) WithDefaults(defaultPerPage int64, maxPerPage int64) Page {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PerPage < 1 {
+		p.PerPage = defaultPerPage
+	}
+	if maxPerPage > 0 && p.PerPage > maxPerPage {
+		p.PerPage = maxPerPage
+	}
+	return p
+}
+
+// Offset returns the number of results before the page
+func (p Page) Offset() int64 {
+	if p.Cursor != "" {
+		if offset, err := p.Cursor.Offset(); err == nil {
+			return offset
+		}
+	}
+	if p.Page < 1 || p.PerPage < 1 {
+		return 0
+	}
+	return (p.Page - 1) * p.PerPage
+}
+
+// Slice returns the bounds of the page in a list of length results, to page through results filtered in memory
+func (p Page) Slice(length int) (int, int) {
+	start := p.Offset()
+	if start > int64(length) {
+		start = int64(length)
+	}
+	end := int64(length)
+	if p.PerPage > 0 && start+p.PerPage < end {
+		end = start + p.PerPage
+	}
+	return int(start), int(end)
+}
+
+// Result describes the page of results returned to clients. The page number of pages selected by a key cursor is not
+// known, it is 0
+type Result struct {
+	TotalCount int64  `json:"totalCount"`
+	Page       int64  `json:"page"`
+	PerPage    int64  `json:"perPage"`
+	NextCursor Cursor `json:"nextCursor,omitempty"`
+}
+
+// NewResult describes the page holding count results out of totalCount. The next cursor is only set when there
+// are results after the page
+func NewResult(page Page, count int, totalCount int64) Result {
+	result := Result{
+		TotalCount: totalCount,
+		Page:       page.Page,
+		PerPage:    page.PerPage,
+	}
+
+	// the position of pages selected by key is not known, stores paging by key set their next cursor
+	if _, ok := page.Cursor.Key(); ok {
+		result.Page = 0
+		return result
+	}
+
+	offset := page.Offset()
+	if page.PerPage > 0 {
+		result.Page = offset/page.PerPage + 1
+	}
+	if next := offset + int64(count); count > 0 && next < totalCount {
+		result.NextCursor = NewCursor(next)
+	}
+
+	return result
+}
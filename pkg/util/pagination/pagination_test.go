@@ -0,0 +1,101 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSortOrder(t *testing.T) {
+	for input, expected := range map[string]SortOrder{"": "", "asc": SortAscending, " DESC ": SortDescending} {
+		order, err := ParseSortOrder(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, order, input)
+	}
+
+	_, err := ParseSortOrder("up")
+	require.ErrorIs(t, err, ErrInvalidSortOrder)
+
+	assert.Equal(t, SortDescending, SortOrder("").OrDefault(SortDescending))
+	assert.Equal(t, "DESC", SortDescending.SQL())
+	assert.Equal(t, "ASC", SortOrder("").SQL())
+}
+
+func TestCursor(t *testing.T) {
+	offset, err := NewCursor(42).Offset()
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), offset)
+
+	for _, cursor := range []Cursor{"", "not a cursor", Cursor("bzotMQ")} {
+		_, err := cursor.Offset()
+		require.ErrorIs(t, err, ErrInvalidCursor, cursor)
+	}
+
+	_, ok := NewCursor(42).Key()
+	assert.False(t, ok)
+
+	cursor := NewKeyCursor("loginuser2")
+	key, ok := cursor.Key()
+	require.True(t, ok)
+	assert.Equal(t, "loginuser2", key)
+	offset, err = cursor.Offset()
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), offset)
+}
+
+func TestNewPage(t *testing.T) {
+	page, err := NewPage(2, 10, "", "desc")
+	require.NoError(t, err)
+	assert.Equal(t, Page{Page: 2, PerPage: 10, Order: SortDescending}, page)
+
+	_, err = NewPage(1, 10, "not a cursor", "")
+	require.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = NewPage(1, 10, "", "up")
+	require.ErrorIs(t, err, ErrInvalidSortOrder)
+}
+
+func TestPage(t *testing.T) {
+	t.Run("sets defaults and caps the page size", func(t *testing.T) {
+		assert.Equal(t, Page{Page: 1, PerPage: 100}, Page{}.WithDefaults(100, 1000))
+		assert.Equal(t, Page{Page: 3, PerPage: 1000}, Page{Page: 3, PerPage: 5000}.WithDefaults(100, 1000))
+		assert.Equal(t, Page{Page: 1, PerPage: 5000}, Page{PerPage: 5000}.WithDefaults(100, 0))
+	})
+
+	t.Run("computes the offset from the page number or the cursor", func(t *testing.T) {
+		assert.Equal(t, int64(0), Page{}.Offset())
+		assert.Equal(t, int64(20), Page{Page: 3, PerPage: 10}.Offset())
+		assert.Equal(t, int64(7), Page{Page: 3, PerPage: 10, Cursor: NewCursor(7)}.Offset())
+	})
+
+	t.Run("slices results filtered in memory", func(t *testing.T) {
+		start, end := Page{Page: 2, PerPage: 3}.Slice(7)
+		assert.Equal(t, []int{3, 6}, []int{start, end})
+
+		start, end = Page{Page: 3, PerPage: 3}.Slice(7)
+		assert.Equal(t, []int{6, 7}, []int{start, end})
+
+		start, end = Page{Page: 4, PerPage: 3}.Slice(7)
+		assert.Equal(t, []int{7, 7}, []int{start, end})
+
+		start, end = Page{}.Slice(7)
+		assert.Equal(t, []int{0, 7}, []int{start, end})
+	})
+}
+
+func TestNewResult(t *testing.T) {
+	page := Page{Page: 1, PerPage: 2}
+
+	result := NewResult(page, 2, 5)
+	assert.Equal(t, Result{TotalCount: 5, Page: 1, PerPage: 2, NextCursor: NewCursor(2)}, result)
+
+	result = NewResult(Page{PerPage: 2, Cursor: result.NextCursor}, 2, 5)
+	assert.Equal(t, Result{TotalCount: 5, Page: 2, PerPage: 2, NextCursor: NewCursor(4)}, result)
+
+	result = NewResult(Page{PerPage: 2, Cursor: result.NextCursor}, 1, 5)
+	assert.Equal(t, Result{TotalCount: 5, Page: 3, PerPage: 2}, result)
+
+	result = NewResult(Page{PerPage: 2, Cursor: NewKeyCursor("b")}, 2, 5)
+	assert.Equal(t, Result{TotalCount: 5, PerPage: 2}, result)
+}
@@ -2,7 +2,7 @@ import { css } from '@emotion/css';
 import React, { useState } from 'react';
 import useAsync from 'react-use/lib/useAsync';
 
-import { GrafanaTheme2 } from '@grafana/data';
+import { dateTimeFormatTimeAgo, GrafanaTheme2 } from '@grafana/data';
 import { getBackendSrv } from '@grafana/runtime';
 import { Link, ButtonGroup, LinkButton, Icon, Tag, useStyles2 } from '@grafana/ui';
 import { getConfig } from 'app/core/config';
@@ -13,11 +13,21 @@ export interface ListPublicDashboardResponse {
   dashboardUid: string;
   title: string;
   isEnabled: boolean;
+  lastUsedAt?: string;
+}
+
+export interface ListPublicDashboardResponseWithPagination {
+  publicDashboards: ListPublicDashboardResponse[];
+  totalCount: number;
+  page: number;
+  perPage: number;
+  nextCursor?: string;
 }
 
 export const LIST_PUBLIC_DASHBOARD_URL = `/api/dashboards/public`;
 export const getPublicDashboards = async (): Promise<ListPublicDashboardResponse[]> => {
-  return getBackendSrv().get(LIST_PUBLIC_DASHBOARD_URL);
+  const response: ListPublicDashboardResponseWithPagination = await getBackendSrv().get(LIST_PUBLIC_DASHBOARD_URL);
+  return response.publicDashboards;
 };
 
 export const viewPublicDashboardUrl = (accessToken: string): string => {
@@ -40,6 +50,7 @@ export const ListPublicDashboardTable = () => {
           <tr>
             <th>Name</th>
             <th>Status</th>
+            <th>Last used</th>
             <th>Public URL</th>
             <th>Configuration</th>
             <th></th>
@@ -56,6 +67,7 @@ export const ListPublicDashboardTable = () => {
               <td>
                 <Tag name={pd.isEnabled ? 'enabled' : 'disabled'} colorIndex={pd.isEnabled ? 20 : 15} />
               </td>
+              <td title={pd.lastUsedAt}>{pd.lastUsedAt ? dateTimeFormatTimeAgo(pd.lastUsedAt) : 'Never'}</td>
               <td>
                 <ButtonGroup>
                   <LinkButton
@@ -13,6 +13,7 @@ import {
   GetMetricsRequest,
   MetricResponse,
   MultiFilters,
+  TaggedResource,
 } from './types';
 
 export interface SelectableResourceValue extends SelectableValue<string> {
@@ -135,4 +136,12 @@ export class CloudWatchAPI extends CloudWatchRequest {
       tags: JSON.stringify(this.convertMultiFilterFormat(tags, 'tag name')),
     });
   }
+
+  getTaggedResources(region: string, resourceType: string, tags: MultiFilters) {
+    return this.memoizedGetRequest<TaggedResource[]>('tagged-resources', {
+      region: this.templateSrv.replace(this.getActualRegion(region)),
+      resourceType: this.templateSrv.replace(resourceType),
+      tags: JSON.stringify(this.convertMultiFilterFormat(tags, 'tag name')),
+    });
+  }
 }
@@ -24,6 +24,7 @@ const queryTypes: Array<{ value: string; label: string }> = [
   { value: VariableQueryType.EBSVolumeIDs, label: 'EBS Volume IDs' },
   { value: VariableQueryType.EC2InstanceAttributes, label: 'EC2 Instance Attributes' },
   { value: VariableQueryType.ResourceArns, label: 'Resource ARNs' },
+  { value: VariableQueryType.ResourceIds, label: 'Resource IDs' },
   { value: VariableQueryType.Statistics, label: 'Statistics' },
   { value: VariableQueryType.LogGroups, label: 'Log Groups' },
 ];
@@ -89,6 +90,7 @@ export const VariableQueryEditor = ({ query, datasource, onChange }: Props) => {
     VariableQueryType.EBSVolumeIDs,
     VariableQueryType.EC2InstanceAttributes,
     VariableQueryType.ResourceArns,
+    VariableQueryType.ResourceIds,
     VariableQueryType.LogGroups,
   ].includes(parsedQuery.queryType);
   const hasNamespaceField = [
@@ -210,7 +212,7 @@ export const VariableQueryEditor = ({ query, datasource, onChange }: Props) => {
           </InlineField>
         </>
       )}
-      {parsedQuery.queryType === VariableQueryType.ResourceArns && (
+      {[VariableQueryType.ResourceArns, VariableQueryType.ResourceIds].includes(parsedQuery.queryType) && (
         <>
           <VariableTextField
             value={parsedQuery.resourceType}
@@ -533,6 +533,33 @@ describe('CloudWatchMetricsQueryRunner', () => {
         });
       });
 
+      it('should expand the multi-valued template variables of excluded dimension values', async () => {
+        const { runner, fetchMock, request } = setupMockedMetricsQueryRunner({ variables: [var1, var2, var3, var4] });
+        const queries: CloudWatchMetricsQuery[] = [
+          {
+            id: '',
+            metricQueryType: MetricQueryType.Search,
+            metricEditorMode: MetricEditorMode.Builder,
+            queryMode: 'Metrics',
+            refId: 'A',
+            region: 'us-east-1',
+            namespace: 'TestNamespace',
+            metricName: 'TestMetricName',
+            dimensions: {
+              dim3: '* except ($var3)',
+            },
+            statistic: 'Average',
+            period: '300',
+          },
+        ];
+
+        await expect(runner.handleMetricQueries(queries, request)).toEmitValuesWith(() => {
+          expect(fetchMock.mock.calls[0][0].data.queries[0].dimensions['dim3']).toStrictEqual([
+            '* except (var3-foo,var3-baz)',
+          ]);
+        });
+      });
+
       it('should generate the correct query for multilple template variables, lack scopedVars', async () => {
         const { runner, fetchMock, request } = setupMockedMetricsQueryRunner({ variables: [var1, var2, var3, var4] });
         const queries: CloudWatchMetricsQuery[] = [
@@ -11,6 +11,9 @@ import { AppNotificationTimeout } from 'app/types';
 import memoizedDebounce from '../memoizedDebounce';
 import { CloudWatchJsonData, Dimensions, MetricRequest, MultiFilters, TSDBResponse } from '../types';
 
+// matches the dimension values matching any value but a list, like * except (i-abc, i-def)
+const dimensionExclusionRegex = /^\s*\*\s+except\s*\(.*\)\s*$/i;
+
 export abstract class CloudWatchRequest {
   templateSrv: TemplateSrv;
   ref: DataSourceRef;
@@ -50,6 +53,11 @@ export abstract class CloudWatchRequest {
         return { ...result, [key]: null };
       }
 
+      // the variables of the excluded values are expanded to the list of their values
+      if (dimensionExclusionRegex.test(value)) {
+        return { ...result, [key]: [this.templateSrv.replace(value, scopedVars, 'csv')] };
+      }
+
       const newValues = this.expandVariableToArray(value, scopedVars);
       return { ...result, [key]: newValues };
     }, {});
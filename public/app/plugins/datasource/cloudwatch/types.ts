@@ -69,6 +69,55 @@ export interface MetricStat {
    * @deprecated use statistic
    */
   statistics?: string[];
+  /**
+   * Account queried from a monitoring account, or 'all' for all the linked accounts
+   */
+  accountId?: string;
+  /**
+   * Band of the expected values of the metric, drawn from its anomaly detection model
+   */
+  anomalyDetectionBand?: AnomalyDetectionBand;
+}
+
+export interface AnomalyDetectionBand {
+  /**
+   * Width of the band in standard deviations, 2 by default
+   */
+  standardDeviations?: number;
+}
+
+export interface SQLExpressionError {
+  message: string;
+  line: number;
+  column: number;
+}
+
+export interface TaggedResource {
+  arn: string;
+  /**
+   * Type of the resource in the service:type form of the resource type filters, for example ec2:instance
+   */
+  resourceType: string;
+  /**
+   * Identifier of the resource in its ARN, which is the dimension value of the resource for most services
+   */
+  resourceId: string;
+  tags: Record<string, string>;
+}
+
+export interface MetricsInsightsSchema {
+  valid: boolean;
+  errors: SQLExpressionError[];
+  namespaces: string[];
+  statistics: string[];
+  /**
+   * Namespace of the query, or the one of the request when the query is empty or invalid
+   */
+  namespace?: string;
+  /**
+   * Dimension keys of the namespace
+   */
+  dimensions: string[];
 }
 
 export interface CloudWatchMathExpressionQuery extends DataQuery {
@@ -123,6 +172,8 @@ export interface CloudWatchJsonData extends AwsAuthDataSourceJsonData {
   // Used to create links if logs contain traceId.
   tracingDatasourceUid?: string;
   defaultLogGroups?: string[];
+  // Controls whether dimension values are emitted as frame name, labels or displayNameFromDS.
+  frameNaming?: '' | 'frameName' | 'labels' | 'displayName' | 'legacy';
 }
 
 export interface CloudWatchSecureJsonData extends AwsAuthDataSourceSecureJsonData {
@@ -388,6 +439,7 @@ export enum VariableQueryType {
   EBSVolumeIDs = 'ebsVolumeIDs',
   EC2InstanceAttributes = 'ec2InstanceAttributes',
   ResourceArns = 'resourceARNs',
+  ResourceIds = 'resourceIDs',
   Statistics = 'statistics',
   LogGroups = 'logGroups',
 }
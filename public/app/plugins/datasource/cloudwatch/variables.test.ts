@@ -26,6 +26,14 @@ const getDimensionValues = jest.fn().mockResolvedValue([{ label: 'e', value: 'e'
 const getEbsVolumeIds = jest.fn().mockResolvedValue([{ label: 'f', value: 'f' }]);
 const getEc2InstanceAttribute = jest.fn().mockResolvedValue([{ label: 'g', value: 'g' }]);
 const getResourceARNs = jest.fn().mockResolvedValue([{ label: 'h', value: 'h' }]);
+const getTaggedResources = jest.fn().mockResolvedValue([
+  {
+    arn: 'arn:aws:ec2:us-east-1:123456789012:instance/i-123',
+    resourceType: 'ec2:instance',
+    resourceId: 'i-123',
+    tags: {},
+  },
+]);
 
 const variables = new CloudWatchVariableSupport(mock.datasource.api);
 
@@ -160,6 +168,25 @@ describe('variables', () => {
     });
   });
 
+  describe('resource ids', () => {
+    const query = {
+      ...defaultQuery,
+      queryType: VariableQueryType.ResourceIds,
+      resourceType: 'ec2:instance',
+      tags: { env: ['prod'] },
+    };
+    beforeEach(() => {
+      mock.datasource.api.getTaggedResources = getTaggedResources;
+      getTaggedResources.mockClear();
+    });
+
+    it('should return the ids of the tagged resources', async () => {
+      const result = await variables.execute(query);
+      expect(getTaggedResources).toBeCalledWith(query.region, query.resourceType, { env: ['prod'] });
+      expect(result).toEqual([{ text: 'i-123', value: 'i-123', expandable: true }]);
+    });
+  });
+
   it('should run statistics', async () => {
     const result = await variables.execute({ ...defaultQuery, queryType: VariableQueryType.Statistics });
     expect(result).toEqual([
@@ -42,6 +42,8 @@ export class CloudWatchVariableSupport extends CustomVariableSupport<CloudWatchD
           return this.handleEc2InstanceAttributeQuery(query);
         case VariableQueryType.ResourceArns:
           return this.handleResourceARNsQuery(query);
+        case VariableQueryType.ResourceIds:
+          return this.handleResourceIdsQuery(query);
         case VariableQueryType.Statistics:
           return this.handleStatisticsQuery();
         case VariableQueryType.LogGroups:
@@ -155,6 +157,15 @@ export class CloudWatchVariableSupport extends CustomVariableSupport<CloudWatchD
     }));
   }
 
+  async handleResourceIdsQuery({ region, resourceType, tags }: VariableQuery) {
+    const resources = await this.api.getTaggedResources(region, resourceType, tags ?? {});
+    return resources.map((r) => ({
+      text: r.resourceId,
+      value: r.resourceId,
+      expandable: true,
+    }));
+  }
+
   async handleStatisticsQuery() {
     return standardStatistics.map((s: string) => ({
       text: s,